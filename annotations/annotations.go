@@ -0,0 +1,142 @@
+// Package annotations loads reviewer comments from a sidecar file and renders
+// them inline beneath the timeline blocks they reference.
+//
+// A sidecar file is a JSON object keyed by event UUID. Each value is either a
+// single comment or a list of comments:
+//
+//	{
+//	  "0b6d3a52-1c9e-4f0a-9d2b-7e5f8c1a2b3c": "Why not reuse the helper?",
+//	  "evt-5e2a...": ["Good catch", "Needs a test"]
+//	}
+//
+// Keys may carry the "evt-" prefix used by export anchors so links copied
+// from an exported document work as-is.
+package annotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Kind is the timeline entry kind used for rendered annotations.
+const Kind = "annotation"
+
+// anchorPrefix matches export.AnchorID so exported anchors can be used as keys.
+const anchorPrefix = "evt-"
+
+// Set maps event UUIDs to reviewer comments. A nil Set has no annotations.
+type Set map[string][]string
+
+// Load reads a sidecar annotations file.
+func Load(path string) (Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading annotations: %w", err)
+	}
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing annotations %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Parse decodes sidecar annotations from JSON.
+func Parse(data []byte) (Set, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	set := make(Set, len(raw))
+	for key, value := range raw {
+		id := strings.TrimPrefix(strings.TrimSpace(key), anchorPrefix)
+		var comments []string
+		var single string
+		if err := json.Unmarshal(value, &single); err == nil {
+			comments = []string{single}
+		} else if err := json.Unmarshal(value, &comments); err != nil {
+			return nil, fmt.Errorf("annotation %q: expected a string or list of strings", key)
+		}
+		for _, c := range comments {
+			if c = strings.TrimSpace(c); c != "" {
+				set[id] = append(set[id], c)
+			}
+		}
+	}
+	return set, nil
+}
+
+// For returns the comments attached to id.
+func (s Set) For(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return s[id]
+}
+
+// Interleave returns entries with an annotation entry inserted after the last
+// entry carrying each annotated ID. Entries are returned unchanged when the
+// set is empty.
+func (s Set) Interleave(entries []timeline.Entry) []timeline.Entry {
+	if len(s) == 0 {
+		return entries
+	}
+	last := make(map[string]int)
+	for i, entry := range entries {
+		if len(s.For(entry.ID)) > 0 {
+			last[entry.ID] = i
+		}
+	}
+	if len(last) == 0 {
+		return entries
+	}
+
+	out := make([]timeline.Entry, 0, len(entries)+len(last))
+	for i, entry := range entries {
+		out = append(out, entry)
+		if at, ok := last[entry.ID]; ok && at == i {
+			out = append(out, s.Entry(entry.ID))
+		}
+	}
+	return out
+}
+
+// Entry returns the rendered annotation entry for id. Lines holds the raw
+// comments so exporters can format them natively.
+func (s Set) Entry(id string) timeline.Entry {
+	comments := s.For(id)
+	return timeline.Entry{
+		ParentID: id,
+		Kind:     Kind,
+		Lines:    append([]string(nil), comments...),
+		Body:     Render(comments),
+	}
+}
+
+// Render returns the terminal text for a block's comments: each comment is
+// drawn beneath a gutter bar so it reads as an aside to the block above.
+func Render(comments []string) string {
+	if len(comments) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	gutter := style.AccentText("  ┃ ")
+	for _, comment := range comments {
+		for i, line := range strings.Split(comment, "\n") {
+			sb.WriteString(gutter)
+			if i == 0 {
+				sb.WriteString(style.AccentText("✎ "))
+			} else {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(style.MutedText(line))
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package annotations
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func TestParse(t *testing.T) {
+	set, err := Parse([]byte(`{
+		"uuid-1": "single comment",
+		"evt-uuid-2": ["first", "  ", "second"]
+	}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := set.For("uuid-1"); !reflect.DeepEqual(got, []string{"single comment"}) {
+		t.Errorf("For(uuid-1) = %q", got)
+	}
+	if got := set.For("uuid-2"); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("For(uuid-2) = %q, want anchor prefix stripped and blanks dropped", got)
+	}
+	if got := set.For(""); got != nil {
+		t.Errorf("For(\"\") = %q, want nil", got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{`[]`, `{"a": 1}`, `not json`} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%s) expected error", input)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	if err := os.WriteFile(path, []byte(`{"a": "b"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	set, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(set.For("a")) != 1 {
+		t.Errorf("expected one comment for a, got %v", set)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	set := Set{"b": {"looks wrong"}}
+	entries := []timeline.Entry{
+		{ID: "a", Body: "a\n"},
+		{ID: "b", Body: "b1\n"},
+		{ID: "b", Body: "b2\n"},
+		{ID: "c", Body: "c\n"},
+	}
+
+	got := set.Interleave(entries)
+	if len(got) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(got))
+	}
+	if got[3].Kind != Kind || got[3].ParentID != "b" {
+		t.Errorf("expected annotation after last 'b' entry, got %+v", got[3])
+	}
+	if got[3].ID != "" {
+		t.Errorf("annotation ID = %q, want empty so anchors stay unique", got[3].ID)
+	}
+	if !strings.Contains(ansi.Strip(got[3].Text()), "looks wrong") {
+		t.Errorf("annotation text = %q", got[3].Text())
+	}
+}
+
+func TestInterleave_NoMatches(t *testing.T) {
+	entries := []timeline.Entry{{ID: "a"}}
+	if got := Set(nil).Interleave(entries); len(got) != 1 {
+		t.Errorf("nil set changed entries: %v", got)
+	}
+	if got := (Set{"z": {"x"}}).Interleave(entries); len(got) != 1 {
+		t.Errorf("unmatched set changed entries: %v", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	if Render(nil) != "" {
+		t.Error("expected empty render for no comments")
+	}
+	out := ansi.Strip(Render([]string{"line one\nline two"}))
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "  ┃ ") {
+			t.Errorf("expected gutter prefix on %q", line)
+		}
+	}
+}
@@ -108,16 +108,34 @@ func (r *Renderer) renderTo(out *render.Output, event Event, inTextBlock, inTool
 		fmt.Fprintf(out, "%s%s\n", style.OutputPrefix, style.ErrorText(event.Error))
 	}
 
+	// Adjacent text blocks are rendered as one markdown document (see
+	// JoinText).
+	var text string
+	flush := func() {
+		// Empty text would only add a blank line.
+		if strings.TrimSpace(text) != "" {
+			rendered := r.markdownRenderer.Render(text)
+			fmt.Fprint(out, rendered)
+			if !strings.HasSuffix(rendered, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+		text = ""
+	}
 	for _, block := range event.Message.Content {
+		if block.Type == "text" {
+			// Only render if we weren't streaming (text would already be shown).
+			if !inTextBlock {
+				text = JoinText(text, block.Text)
+			}
+			continue
+		}
+		flush()
 		switch block.Type {
-		case "text":
-			// Only render if we weren't streaming (text would already be shown)
+		case "thinking", "redacted_thinking":
+			// Streamed thinking is reported as a text block
 			if !inTextBlock {
-				rendered := r.markdownRenderer.Render(block.Text)
-				fmt.Fprint(out, rendered)
-				if !strings.HasSuffix(rendered, "\n") {
-					fmt.Fprintln(out)
-				}
+				render.WriteThinking(out, block, r.config.IsVerbose())
 			}
 		case "tool_use":
 			// Only render if we weren't streaming
@@ -126,6 +144,7 @@ func (r *Renderer) renderTo(out *render.Output, event Event, inTextBlock, inTool
 			}
 		}
 	}
+	flush()
 }
 
 // Render outputs the assistant event to the terminal
@@ -142,6 +161,27 @@ func (r *Renderer) RenderToString(event Event, inTextBlock, inToolUseBlock bool)
 	return out.String()
 }
 
+// RenderNestedToString renders a sub-agent's assistant event to a string,
+// with every line behind the nested pipe so it reads as part of the parent
+// Task block.
+func (r *Renderer) RenderNestedToString(event Event, inTextBlock, inToolUseBlock bool) string {
+	rendered := r.RenderToString(event, inTextBlock, inToolUseBlock)
+	if rendered == "" {
+		return ""
+	}
+	blank := strings.TrimRight(style.NestedPrefix, " ")
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(rendered, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			sb.WriteString(blank)
+		} else {
+			sb.WriteString(style.NestedPrefix + line)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
 // SetWidth updates the word-wrap width of the markdown renderer.
 // This is called when the viewport resizes.
 func (r *Renderer) SetWidth(width int) {
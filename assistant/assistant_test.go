@@ -7,6 +7,7 @@ import (
 
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/testutil"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
 
@@ -173,6 +174,25 @@ func TestRenderer_Render_TextBlock_NotStreaming(t *testing.T) {
 	}
 }
 
+func TestRenderer_RenderNestedToString(t *testing.T) {
+	markdown := &mockMarkdownRenderer{returnValue: "first\n\nsecond\n"}
+	r := NewRenderer(WithMarkdownRenderer(markdown))
+
+	event := Event{
+		Message: Message{
+			Content: []types.ContentBlock{
+				{Type: "text", Text: "first\n\nsecond"},
+			},
+		},
+	}
+
+	got := r.RenderNestedToString(event, false, true)
+	want := style.NestedPrefix + "first\n  │\n" + style.NestedPrefix + "second\n"
+	if got != want {
+		t.Errorf("RenderNestedToString() = %q, want %q", got, want)
+	}
+}
+
 func TestRenderer_Render_TextBlock_AlreadyStreaming(t *testing.T) {
 	output := &bytes.Buffer{}
 	markdown := &mockMarkdownRenderer{}
@@ -349,6 +369,81 @@ func TestRenderer_Render_MultipleBlocks(t *testing.T) {
 	}
 }
 
+func TestRenderer_Render_AdjacentTextBlocks(t *testing.T) {
+	output := &bytes.Buffer{}
+	markdown := &mockMarkdownRenderer{}
+
+	r := NewRenderer(
+		WithOutput(output),
+		WithMarkdownRenderer(markdown),
+	)
+
+	event := Event{
+		Message: Message{
+			Content: []types.ContentBlock{
+				{Type: "text", Text: "Steps:\n\n1. one"},
+				{Type: "text", Text: "2. two"},
+			},
+		},
+	}
+
+	r.Render(event, false, false)
+
+	if len(markdown.renderCalls) != 1 {
+		t.Fatalf("expected adjacent text blocks to render once, got %d calls", len(markdown.renderCalls))
+	}
+	if want := "Steps:\n\n1. one\n2. two"; markdown.renderCalls[0] != want {
+		t.Errorf("rendered %q, want %q", markdown.renderCalls[0], want)
+	}
+}
+
+func TestRenderer_Render_ThinkingBlocks(t *testing.T) {
+	event := Event{
+		Message: Message{
+			Content: []types.ContentBlock{
+				{Type: "thinking", Thinking: "Check the config first.", Signature: "EqQB"},
+				{Type: "redacted_thinking", Data: "EmwKAhgB"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		verbose     int
+		inTextBlock bool
+		want        string
+	}{
+		{
+			name: "collapsed",
+			want: "✻ Thinking… (6 tokens)\n✻ Thinking… (redacted)\n",
+		},
+		{
+			name:    "verbose",
+			verbose: 1,
+			want: "✻ Thinking… (6 tokens)\n" +
+				style.OutputPrefix + "Check the config first.\n" +
+				"✻ Thinking… (redacted)\n",
+		},
+		{
+			name:        "already streamed",
+			inTextBlock: true,
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer(
+				WithMarkdownRenderer(&mockMarkdownRenderer{}),
+				WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: tt.verbose}),
+			)
+			if got := r.RenderToString(event, tt.inTextBlock, false); got != tt.want {
+				t.Errorf("RenderToString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderer_Render_UnknownBlockType(t *testing.T) {
 	output := &bytes.Buffer{}
 	markdown := &mockMarkdownRenderer{}
@@ -402,6 +497,30 @@ func TestRenderer_Render_EmptyContent(t *testing.T) {
 	}
 }
 
+func TestRenderer_Render_BlankTextBlock(t *testing.T) {
+	output := &bytes.Buffer{}
+	markdown := &mockMarkdownRenderer{}
+
+	r := NewRenderer(
+		WithOutput(output),
+		WithMarkdownRenderer(markdown),
+	)
+
+	event := Event{
+		Message: Message{
+			Content: []types.ContentBlock{{Type: "text", Text: ""}, {Type: "text", Text: " \n "}},
+		},
+	}
+	r.Render(event, false, false)
+
+	if output.String() != "" {
+		t.Errorf("expected no output for blank text blocks, got %q", output.String())
+	}
+	if len(markdown.renderCalls) != 0 {
+		t.Error("expected markdown.Render() not to be called for blank text")
+	}
+}
+
 func TestRenderer_Render_ErrorWithContent(t *testing.T) {
 	output := &bytes.Buffer{}
 	markdown := &mockMarkdownRenderer{returnValue: "text\n"}
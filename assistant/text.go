@@ -0,0 +1,61 @@
+package assistant
+
+import (
+	"strings"
+	"unicode"
+)
+
+// JoinText joins the text of adjacent text blocks into one markdown
+// document, so a list, table or code fence split between the blocks renders
+// as one structure instead of being closed at the boundary. Inside a code
+// fence, and between list items or table rows, the boundary becomes a line
+// break; elsewhere it is a paragraph break, as separate blocks were shown
+// before.
+func JoinText(a, b string) string {
+	if strings.TrimSpace(a) == "" {
+		return b
+	}
+	if strings.TrimSpace(b) == "" {
+		return a
+	}
+	if inCodeFence(a) {
+		if strings.HasSuffix(a, "\n") || strings.HasPrefix(b, "\n") {
+			return a + b
+		}
+		return a + "\n" + b
+	}
+	a = strings.TrimRight(a, "\n")
+	b = strings.TrimLeft(b, "\n")
+	last := a[strings.LastIndexByte(a, '\n')+1:]
+	first, _, _ := strings.Cut(b, "\n")
+	if continuesBlock(last) && continuesBlock(first) {
+		return a + "\n" + b
+	}
+	return a + "\n\n" + b
+}
+
+// inCodeFence reports whether text ends inside an open ``` or ~~~ fence.
+func inCodeFence(text string) bool {
+	open := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) <= 3 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")) {
+			open = !open
+		}
+	}
+	return open
+}
+
+// continuesBlock reports whether line is a list item or table row, which a
+// following list item or row continues.
+func continuesBlock(line string) bool {
+	line = strings.TrimLeft(line, " \t")
+	switch {
+	case strings.HasPrefix(line, "|"):
+		return true
+	case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "), strings.HasPrefix(line, "+ "):
+		return true
+	}
+	digits := strings.TrimLeftFunc(line, unicode.IsDigit)
+	return len(digits) < len(line) && (strings.HasPrefix(digits, ". ") || strings.HasPrefix(digits, ") "))
+}
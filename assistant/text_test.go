@@ -0,0 +1,29 @@
+package assistant
+
+import "testing"
+
+func TestJoinText(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "paragraphs", a: "First.", b: "Second.", want: "First.\n\nSecond."},
+		{name: "paragraphs with breaks", a: "First.\n", b: "\nSecond.", want: "First.\n\nSecond."},
+		{name: "list items", a: "Steps:\n\n1. one", b: "2. two", want: "Steps:\n\n1. one\n2. two"},
+		{name: "bullets", a: "- one\n", b: "- two", want: "- one\n- two"},
+		{name: "table rows", a: "| a | b |\n|---|---|", b: "| 1 | 2 |", want: "| a | b |\n|---|---|\n| 1 | 2 |"},
+		{name: "open code fence", a: "```go\nfunc f() {", b: "}\n```", want: "```go\nfunc f() {\n}\n```"},
+		{name: "open code fence keeps blank lines", a: "```\nx\n", b: "\ny\n```", want: "```\nx\n\ny\n```"},
+		{name: "closed code fence", a: "```\nx\n```", b: "After.", want: "```\nx\n```\n\nAfter."},
+		{name: "empty first", a: "", b: "Only.", want: "Only."},
+		{name: "blank second", a: "Only.", b: "  ", want: "Only."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinText(tt.a, tt.b); got != tt.want {
+				t.Errorf("JoinText(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
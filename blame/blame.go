@@ -0,0 +1,300 @@
+// Package blame records which turn of a session last changed each line of
+// the files the agent edited, so a change can be traced back to the turn
+// that made it ("turn 9: Edit").
+//
+// Changes are taken from the structured patches of Claude Code's Edit,
+// MultiEdit and Write results. Line numbers are those of the file at the end
+// of the session: a later edit shifts or replaces the regions of earlier ones.
+package blame
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// Change identifies the tool call that wrote a region.
+type Change struct {
+	Turn int
+	Tool string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("turn %d: %s", c.Turn, c.Tool)
+}
+
+// Region is a range of lines, 1-based and inclusive, last written by Change.
+type Region struct {
+	Start, End int
+	Change
+}
+
+// Hunk is one hunk of a unified diff. Lines carry a " ", "-" or "+" prefix.
+type Hunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"`
+}
+
+// Tracker accumulates the regions changed in each file.
+type Tracker struct {
+	files map[string][]Region
+
+	// turn, message, answered and tools follow the event stream for
+	// Observe.
+	turn     int
+	message  string // ID of the current turn's message
+	answered bool   // whether the main agent had a tool result this turn
+	tools    map[string]string
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		files: make(map[string][]Region),
+		tools: make(map[string]string),
+	}
+}
+
+// Create records that change wrote the whole of path, lines long.
+func (t *Tracker) Create(path string, lines int, change Change) {
+	if lines <= 0 {
+		t.files[path] = nil
+		return
+	}
+	t.files[path] = []Region{{Start: 1, End: lines, Change: change}}
+}
+
+// Patch records the lines that hunks added to path. Regions of earlier
+// changes are shifted by the lines added or removed above them, and lose the
+// lines the hunks replaced.
+func (t *Tracker) Patch(path string, hunks []Hunk, change Change) {
+	edits := splitHunks(hunks)
+	regions := t.files[path]
+	// Apply from the bottom up so each edit's old line numbers still hold.
+	for i := len(edits) - 1; i >= 0; i-- {
+		regions = edits[i].apply(regions, change)
+	}
+	t.files[path] = regions
+}
+
+// Files returns the changed files, sorted.
+func (t *Tracker) Files() []string {
+	files := make([]string, 0, len(t.files))
+	for path := range t.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Regions returns the changed regions of path in line order, with adjacent
+// regions written by the same change merged.
+func (t *Tracker) Regions(path string) []Region {
+	var out []Region
+	for _, r := range t.files[path] {
+		if n := len(out); n > 0 && out[n-1].Change == r.Change && out[n-1].End+1 == r.Start {
+			out[n-1].End = r.End
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// WriteReport writes each changed file followed by its regions:
+//
+//	internal/parser/parser.go
+//	  12-18  turn 3: Edit
+//	  40     turn 9: Edit
+func (t *Tracker) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, path := range t.Files() {
+		fmt.Fprintln(tw, path)
+		regions := t.Regions(path)
+		if len(regions) == 0 {
+			fmt.Fprintln(tw, "  (emptied)\t")
+		}
+		for _, r := range regions {
+			lines := fmt.Sprint(r.Start)
+			if r.End != r.Start {
+				lines = fmt.Sprintf("%d-%d", r.Start, r.End)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", lines, r.Change)
+		}
+	}
+	return tw.Flush()
+}
+
+// edit replaces del lines at old line at with ins new lines.
+type edit struct {
+	at, del, ins int
+}
+
+// splitHunks breaks hunks into runs of removed and added lines, dropping
+// the context between them.
+func splitHunks(hunks []Hunk) []edit {
+	var edits []edit
+	for _, h := range hunks {
+		line := h.OldStart
+		if h.OldLines == 0 {
+			// A hunk that only adds lines starts after OldStart.
+			line++
+		}
+		var cur *edit
+		for _, l := range h.Lines {
+			if l == "" || l[0] == '\\' {
+				continue
+			}
+			switch l[0] {
+			case '-':
+				if cur == nil {
+					edits = append(edits, edit{at: line})
+					cur = &edits[len(edits)-1]
+				}
+				cur.del++
+				line++
+			case '+':
+				if cur == nil {
+					edits = append(edits, edit{at: line})
+					cur = &edits[len(edits)-1]
+				}
+				cur.ins++
+			default:
+				cur = nil
+				line++
+			}
+		}
+	}
+	return edits
+}
+
+// apply updates regions for the edit and adds a region for its new lines.
+func (e edit) apply(regions []Region, change Change) []Region {
+	end := e.at + e.del // first old line after the removed ones
+	shift := e.ins - e.del
+	out := make([]Region, 0, len(regions)+2)
+	for _, r := range regions {
+		switch {
+		case r.End < e.at:
+			out = append(out, r)
+		case r.Start >= end:
+			r.Start += shift
+			r.End += shift
+			out = append(out, r)
+		default:
+			if r.Start < e.at {
+				before := r
+				before.End = e.at - 1
+				out = append(out, before)
+			}
+			if r.End >= end {
+				after := r
+				after.Start = end + shift
+				after.End += shift
+				out = append(out, after)
+			}
+		}
+	}
+	if e.ins > 0 {
+		out = append(out, Region{Start: e.at, End: e.at + e.ins - 1, Change: change})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out
+}
+
+// fileResult is the tool_use_result of Edit, MultiEdit and Write.
+type fileResult struct {
+	Type            string `json:"type"`
+	FilePath        string `json:"filePath"`
+	Content         string `json:"content"`
+	StructuredPatch []Hunk `json:"structuredPatch"`
+}
+
+// Observe reads one raw stream-json event. Turns are counted as in the
+// session's turn count: one per main-agent message, which Claude Code
+// streams as an assistant event per content block. File changes in tool
+// results, a sub-agent's included, are attributed to the current turn and
+// the tool that made them.
+func (t *Tracker) Observe(raw []byte) {
+	ev, ok := types.ParseLine(raw)
+	if !ok {
+		return
+	}
+	blocks := ev.Blocks()
+
+	switch ev.Type {
+	case "assistant":
+		if !ev.IsSubAgent() {
+			t.beginTurn(ev.Message.ID)
+		}
+		for _, b := range blocks {
+			if b.Type == "tool_use" && b.ID != "" {
+				t.tools[b.ID] = b.Name
+			}
+		}
+	case "user":
+		if !ev.IsSubAgent() {
+			t.answered = true
+		}
+		if len(ev.ToolUseResult) == 0 {
+			return
+		}
+		var res fileResult
+		if json.Unmarshal(ev.ToolUseResult, &res) != nil || res.FilePath == "" {
+			return
+		}
+		change := Change{Turn: t.turn, Tool: t.toolFor(blocks)}
+		switch {
+		case res.Type == "create":
+			t.Create(res.FilePath, countLines(res.Content), change)
+		case len(res.StructuredPatch) > 0:
+			t.Patch(res.FilePath, res.StructuredPatch, change)
+		}
+	}
+}
+
+// beginTurn starts a turn for a main-agent message with ID id, unless it
+// continues the current turn's message. Without message IDs, a turn starts
+// with the first message after a tool result.
+func (t *Tracker) beginTurn(id string) {
+	switch {
+	case id != "" && id == t.message:
+		return
+	case id == "" && t.turn > 0 && !t.answered:
+		return
+	}
+	t.turn++
+	t.message = id
+	t.answered = false
+}
+
+// toolFor returns the name of the tool whose result blocks carry.
+func (t *Tracker) toolFor(blocks []types.LineBlock) string {
+	for _, b := range blocks {
+		if b.Type != "tool_result" {
+			continue
+		}
+		if name, ok := t.tools[b.ToolUseID]; ok {
+			delete(t.tools, b.ToolUseID)
+			return name
+		}
+	}
+	return "Edit"
+}
+
+// countLines returns the number of lines in content, not counting an empty
+// line after a final newline.
+func countLines(content string) int {
+	if content == "" {
+		return 0
+	}
+	return strings.Count(strings.TrimSuffix(content, "\n"), "\n") + 1
+}
@@ -0,0 +1,112 @@
+package blame
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// regions formats the regions of path as "start-end turn N: Tool; ...".
+func regions(tr *Tracker, path string) string {
+	var parts []string
+	for _, r := range tr.Regions(path) {
+		parts = append(parts, fmt.Sprintf("%d-%d %s", r.Start, r.End, r))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func TestTracker_Patch(t *testing.T) {
+	tr := NewTracker()
+	tr.Create("a.go", 10, Change{Turn: 1, Tool: "Write"})
+
+	// Replace line 3 with two lines.
+	tr.Patch("a.go", []Hunk{{
+		OldStart: 2, OldLines: 3, NewStart: 2, NewLines: 4,
+		Lines: []string{" two", "-three", "+three a", "+three b", " four"},
+	}}, Change{Turn: 2, Tool: "Edit"})
+	if got, want := regions(tr, "a.go"), "1-2 turn 1: Write; 3-4 turn 2: Edit; 5-11 turn 1: Write"; got != want {
+		t.Fatalf("after edit: %s, want %s", got, want)
+	}
+
+	// Delete line 1 and insert after line 5: regions below shift.
+	tr.Patch("a.go", []Hunk{
+		{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 1, Lines: []string{"-one", " two"}},
+		{OldStart: 5, OldLines: 0, NewStart: 5, NewLines: 1, Lines: []string{"+new"}},
+	}, Change{Turn: 4, Tool: "MultiEdit"})
+	want := "1-1 turn 1: Write; 2-3 turn 2: Edit; 4-4 turn 1: Write; 5-5 turn 4: MultiEdit; 6-11 turn 1: Write"
+	if got := regions(tr, "a.go"); got != want {
+		t.Errorf("after multi edit: %s, want %s", got, want)
+	}
+}
+
+func TestTracker_PatchMergesSameChange(t *testing.T) {
+	tr := NewTracker()
+	tr.Patch("b.go", []Hunk{{
+		OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+		Lines: []string{"-a", "+A", "-b", "+B", " c"},
+	}}, Change{Turn: 3, Tool: "Edit"})
+	if got, want := regions(tr, "b.go"), "1-2 turn 3: Edit"; got != want {
+		t.Errorf("regions = %s, want %s", got, want)
+	}
+}
+
+func TestTracker_Observe(t *testing.T) {
+	tr := NewTracker()
+	for _, line := range []string{
+		`{"type":"user","message":{"content":"add a main"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"w1","name":"Write"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"w1"}]},"tool_use_result":{"type":"create","filePath":"main.go","content":"package main\n\nfunc main() {\n}\n"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"Now the body"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"e1","name":"Edit"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"e1"}]},"tool_use_result":{"filePath":"main.go","structuredPatch":[{"oldStart":3,"oldLines":2,"newStart":3,"newLines":3,"lines":[" func main() {","+\tprintln(\"hi\")"," }"]}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"r1"}]},"tool_use_result":{"type":"text","file":{"filePath":"main.go"}}}`,
+		`not json`,
+	} {
+		tr.Observe([]byte(line))
+	}
+
+	var b strings.Builder
+	if err := tr.WriteReport(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "main.go\n" +
+		"  1-3  turn 1: Write\n" +
+		"  4    turn 2: Edit\n" +
+		"  5    turn 1: Write\n"
+	if b.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestTracker_ObserveTurns(t *testing.T) {
+	tr := NewTracker()
+	for _, line := range []string{
+		// Turn 1 streams one message as a block per event.
+		`{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Writing it"}]}}`,
+		`{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"w1","name":"Write"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"w1"}]},"tool_use_result":{"type":"create","filePath":"a.go","content":"package a\n"}}`,
+		// Turn 2 runs a sub-agent, whose own messages are not turns.
+		`{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","id":"task1","name":"Task"}]}}`,
+		`{"type":"assistant","parent_tool_use_id":"task1","message":{"id":"s1","content":[{"type":"text","text":"Looking"}]}}`,
+		`{"type":"assistant","parent_tool_use_id":"task1","message":{"id":"s2","content":[{"type":"tool_use","id":"e1","name":"Edit"}]}}`,
+		`{"type":"user","parent_tool_use_id":"task1","message":{"content":[{"type":"tool_result","tool_use_id":"e1"}]},"tool_use_result":{"filePath":"a.go","structuredPatch":[{"oldStart":1,"oldLines":1,"newStart":1,"newLines":2,"lines":[" package a","+var x = 1"]}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"task1"}]}}`,
+		// Turn 3.
+		`{"type":"assistant","message":{"id":"m3","content":[{"type":"tool_use","id":"e2","name":"Edit"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"e2"}]},"tool_use_result":{"filePath":"a.go","structuredPatch":[{"oldStart":2,"oldLines":1,"newStart":2,"newLines":2,"lines":[" var x = 1","+var y = 2"]}]}}`,
+	} {
+		tr.Observe([]byte(line))
+	}
+
+	var b strings.Builder
+	if err := tr.WriteReport(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "a.go\n" +
+		"  1  turn 1: Write\n" +
+		"  2  turn 2: Edit\n" +
+		"  3  turn 3: Edit\n"
+	if b.String() != want {
+		t.Errorf("report =\n%s\nwant\n%s", b.String(), want)
+	}
+}
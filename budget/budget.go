@@ -0,0 +1,75 @@
+// Package budget checks what a session has cost against the -budget
+// spending limit. Claude reports the cost of a session on its result event,
+// so spending is known once a session finishes; a stream holding several
+// sessions adds their costs up. Codex reports no cost and never reaches a
+// budget.
+package budget
+
+import (
+	"fmt"
+
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+)
+
+// WarnFraction is the share of the limit at which spending is flagged.
+const WarnFraction = 0.8
+
+// Level is how much of a budget has been spent.
+type Level int
+
+const (
+	// Under is below WarnFraction of the limit, or any spending without one.
+	Under Level = iota
+	// Warning is at least WarnFraction of the limit.
+	Warning
+	// Exceeded is over the limit.
+	Exceeded
+)
+
+// Budget is a spending limit in USD.
+type Budget struct {
+	// Limit is the most a run may spend; zero means no limit.
+	Limit float64
+	// Stop ends rendering, and the run, once the limit is exceeded.
+	Stop bool
+}
+
+// Level returns how much of the budget spent USD uses.
+func (b Budget) Level(spent float64) Level {
+	switch {
+	case b.Limit <= 0:
+		return Under
+	case spent > b.Limit:
+		return Exceeded
+	case spent >= b.Limit*WarnFraction:
+		return Warning
+	}
+	return Under
+}
+
+// Message describes spending at its level, e.g. "Budget: $1.23 of $1.50
+// spent (82%)", or "" while it is Under.
+func (b Budget) Message(spent float64) string {
+	f := numfmt.Default()
+	amounts := fmt.Sprintf("%s of %s spent (%.0f%%)", f.Cost(spent, 2), f.Cost(b.Limit, 2), spent/b.Limit*100)
+	switch b.Level(spent) {
+	case Warning:
+		return "Budget: " + amounts
+	case Exceeded:
+		if b.Stop {
+			return "Budget exceeded: " + amounts + ", rendering stopped"
+		}
+		return "Budget exceeded: " + amounts
+	}
+	return ""
+}
+
+// ExceededError reports a run stopped for going over its budget.
+type ExceededError struct {
+	Spent, Limit float64
+}
+
+func (e *ExceededError) Error() string {
+	f := numfmt.Default()
+	return fmt.Sprintf("budget exceeded: spent %s of %s", f.Cost(e.Spent, 2), f.Cost(e.Limit, 2))
+}
@@ -0,0 +1,47 @@
+package budget
+
+import "testing"
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		b     Budget
+		spent float64
+		want  Level
+	}{
+		{Budget{}, 100, Under},
+		{Budget{Limit: 1}, 0.79, Under},
+		{Budget{Limit: 1}, 0.8, Warning},
+		{Budget{Limit: 1}, 1, Warning},
+		{Budget{Limit: 1}, 1.01, Exceeded},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Level(tt.spent); got != tt.want {
+			t.Errorf("Budget{%g}.Level(%g) = %v, want %v", tt.b.Limit, tt.spent, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		b     Budget
+		spent float64
+		want  string
+	}{
+		{Budget{Limit: 1.5}, 0.5, ""},
+		{Budget{Limit: 1.5}, 1.23, "Budget: $1.23 of $1.50 spent (82%)"},
+		{Budget{Limit: 1.5}, 2, "Budget exceeded: $2.00 of $1.50 spent (133%)"},
+		{Budget{Limit: 1.5, Stop: true}, 2, "Budget exceeded: $2.00 of $1.50 spent (133%), rendering stopped"},
+	}
+	for _, tt := range tests {
+		if got := tt.b.Message(tt.spent); got != tt.want {
+			t.Errorf("Message(%g) = %q, want %q", tt.spent, got, tt.want)
+		}
+	}
+}
+
+func TestExceededError(t *testing.T) {
+	err := &ExceededError{Spent: 2, Limit: 1.5}
+	if got, want := err.Error(), "budget exceeded: spent $2.00 of $1.50"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -7,20 +7,14 @@ import (
 
 	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/redact"
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/terminal"
 	"github.com/johnnyfreeman/viewscreen/textutil"
 )
 
-const (
-	// commandOutputLinesVeryVerbose mirrors Claude read-output expansion at -vv.
-	commandOutputLinesVeryVerbose = 5
-
-	// commandOutputLinesMaxVerbose mirrors Claude read-output expansion at -vvv.
-	commandOutputLinesMaxVerbose = 10
-)
-
 // argWidth is the maximum width of a header argument (command/path) before it
 // is truncated. Matches the tools header renderer.
 const argWidth = 80
@@ -130,9 +124,10 @@ func (r *Renderer) renderTurnCompleted(event Event) string {
 	fmt.Fprintln(out, style.BulletSuccessHeader("Turn Complete"))
 	if event.Usage != nil && r.config.ShowUsage() {
 		u := event.Usage
-		fmt.Fprintf(out, "%s%s in=%d out=%d (cached=%d reasoning=%d)\n",
+		nf := numfmt.Default()
+		fmt.Fprintf(out, "%s%s in=%s out=%s (cached=%s reasoning=%s)\n",
 			style.OutputPrefix, style.MutedText("Tokens:"),
-			u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.ReasoningOutputTokens)
+			nf.Int(u.InputTokens), nf.Int(u.OutputTokens), nf.Int(u.CachedInputTokens), nf.Int(u.ReasoningOutputTokens))
 	}
 	return out.String()
 }
@@ -242,7 +237,11 @@ func (r *Renderer) writeCommandOutput(out *render.Output, item *Item) {
 func (r *Renderer) renderFileChange(phase string, item *Item) string {
 	out := render.StringOutput()
 	if r.once(item.ID) {
-		fmt.Fprint(out, header("Edit", FileChangeSummary(item.Changes)))
+		summary := FileChangeSummary(item.Changes)
+		if len(item.Changes) == 1 {
+			summary = textutil.ShortenPath(summary, argWidth)
+		}
+		fmt.Fprint(out, header("Edit", summary))
 	}
 	if phase == "updated" || phase == "completed" {
 		r.writeFileChangeDetails(out, item)
@@ -283,34 +282,43 @@ func (r *Renderer) writeStructuredPatch(out *render.Output, path string, patch [
 	}
 	numWidth := len(fmt.Sprintf("%d", maxLine))
 	pw := textutil.NewPrefixedWriter(out, style.OutputPrefix, style.OutputContinue)
+	filename := path
+	if r.config.NoDiffHighlight() {
+		filename = ""
+	}
 	for _, hunk := range patch {
 		oldLine := hunk.OldStart
 		newLine := hunk.NewStart
-		for _, line := range hunk.Lines {
+		styled := render.HighlightHunk(r.code, hunk.Lines, filename, func(i int) render.LineStyle {
+			switch hunk.Lines[i][0] {
+			case '+':
+				return render.LineStyle{Bg: style.DiffAddBg}
+			case '-':
+				return render.LineStyle{Bg: style.DiffRemoveBg}
+			}
+			return render.LineStyle{}
+		})
+		for i, line := range hunk.Lines {
 			if line == "" {
 				continue
 			}
 			prefix := line[0]
-			content := line[1:]
 			lineNum, op := "", " "
 			switch prefix {
 			case '+':
 				lineNum = fmt.Sprintf("%*d", numWidth, newLine)
 				op = style.SuccessText("+")
-				content = r.code.HighlightFileWithBg(content, path, style.DiffAddBg)
 				newLine++
 			case '-':
 				lineNum = fmt.Sprintf("%*d", numWidth, oldLine)
 				op = style.ErrorText("-")
-				content = r.code.HighlightFileWithBg(content, path, style.DiffRemoveBg)
 				oldLine++
 			default:
 				lineNum = fmt.Sprintf("%*d", numWidth, newLine)
-				content = r.code.HighlightFile(content, path)
 				oldLine++
 				newLine++
 			}
-			pw.WriteLinef("%s %s %s %s", style.LineNumberText(lineNum), style.LineNumberSepText("│"), op, content)
+			pw.WriteLinef("%s %s %s %s", style.LineNumberText(lineNum), style.LineNumberSepText("│"), op, styled[i])
 		}
 	}
 }
@@ -388,28 +396,13 @@ func (r *Renderer) writeUnknownItemDetails(out *render.Output, item *Item, heade
 	}
 }
 
-// commandOutputLines applies the same read-output expansion policy as Claude:
-// default and -v show a summary, -vv shows 5 lines, and -vvv shows 10.
+// commandOutputLines applies the same tool output policy as Claude: a
+// summary by default, and all of the output from -v up.
 func (r *Renderer) commandOutputLines(lines []string) []string {
-	level := r.config.GetVerboseLevel()
-	maxLines := 0
-	switch {
-	case level >= 3:
-		maxLines = commandOutputLinesMaxVerbose
-	case level >= 2:
-		maxLines = commandOutputLinesVeryVerbose
-	}
-
-	if maxLines == 0 {
+	if !r.config.IsVerbose() {
 		return []string{style.MutedText(fmt.Sprintf("%d lines", len(lines)))}
 	}
-	if len(lines) <= maxLines {
-		return lines
-	}
-
-	hidden := len(lines) - maxLines
-	result := append([]string(nil), lines[:maxLines]...)
-	return append(result, style.MutedText(textutil.TruncationIndicator(hidden)))
+	return lines
 }
 
 func (r *Renderer) truncateCommandOutputLine(line string) string {
@@ -446,16 +439,18 @@ func header(label, arg string) string {
 }
 
 // ShellCommand extracts the inner command from codex's shell invocation,
-// which wraps commands as "<shell> -lc <script>". It is exported so callers
-// that need the human-readable command (e.g. the live spinner label) share the
-// same unwrapping the renderer uses for command headers.
+// which wraps commands as "<shell> -lc <script>", with sensitive KEY=value
+// assignments masked. It is exported so callers that need the
+// human-readable command (e.g. the live spinner label) share the same
+// unwrapping the renderer uses for command headers.
 func ShellCommand(cmd string) string {
 	for _, sep := range []string{" -lc ", " -c "} {
 		if i := strings.Index(cmd, sep); i != -1 {
-			return unquote(strings.TrimSpace(cmd[i+len(sep):]))
+			cmd = unquote(strings.TrimSpace(cmd[i+len(sep):]))
+			break
 		}
 	}
-	return cmd
+	return redact.EnvAssignments(cmd, config.Get().EnvSecretKeys())
 }
 
 func unquote(s string) string {
@@ -126,18 +126,6 @@ func TestRender_CommandCompletedOnly(t *testing.T) {
 	}
 }
 
-func TestRender_CommandOutputVerboseStillSummarizes(t *testing.T) {
-	r := newTestRendererWithLevel(t, true, 1)
-	item := Item{ID: "c1", Type: ItemCommandExecution, Command: "/bin/sh -lc cat", AggregatedOutput: "one\ntwo\n", ExitCode: intPtr(0), Status: "completed"}
-	out := r.Render(itemEvent(TypeItemCompleted, item))
-	if !strings.Contains(out, "2 lines") {
-		t.Errorf("expected line summary at -v, got %q", out)
-	}
-	if strings.Contains(out, "one") || strings.Contains(out, "two") {
-		t.Errorf("-v should not expand command output, got %q", out)
-	}
-}
-
 func TestRender_CommandNonZeroExit(t *testing.T) {
 	r := newTestRenderer(t, true, false)
 	item := Item{ID: "c1", Type: ItemCommandExecution, Command: "/bin/sh -lc false", AggregatedOutput: "", ExitCode: intPtr(2), Status: "completed"}
@@ -156,9 +144,9 @@ func TestRender_CommandNoOutput(t *testing.T) {
 	}
 }
 
-func TestRender_CommandOutputTruncation(t *testing.T) {
+func TestRender_CommandOutputVerbosity(t *testing.T) {
 	var lines []string
-	for i := range commandOutputLinesMaxVerbose + 3 {
+	for i := range 13 {
 		lines = append(lines, "line "+string(rune('a'+i)))
 	}
 	output := strings.Join(lines, "\n") + "\n"
@@ -175,31 +163,14 @@ func TestRender_CommandOutputTruncation(t *testing.T) {
 		}
 	})
 
-	t.Run("-vv shows five lines", func(t *testing.T) {
-		r := newTestRendererWithLevel(t, true, 2)
-		out := r.Render(itemEvent(TypeItemCompleted, item))
-		if !strings.Contains(out, "line a") || !strings.Contains(out, "line e") {
-			t.Errorf("-vv should include first five lines, got %q", out)
-		}
-		if strings.Contains(out, "line f") {
-			t.Errorf("-vv should hide the sixth line, got %q", out)
-		}
-		if !strings.Contains(out, "8 more lines") {
-			t.Errorf("expected truncation note, got %q", out)
-		}
-	})
-
-	t.Run("-vvv shows ten lines", func(t *testing.T) {
-		r := newTestRendererWithLevel(t, true, 3)
+	t.Run("-v shows all lines", func(t *testing.T) {
+		r := newTestRendererWithLevel(t, true, 1)
 		out := r.Render(itemEvent(TypeItemCompleted, item))
-		if !strings.Contains(out, "line a") || !strings.Contains(out, "line j") {
-			t.Errorf("-vvv should include first ten lines, got %q", out)
-		}
-		if strings.Contains(out, "line k") {
-			t.Errorf("-vvv should hide the eleventh line, got %q", out)
+		if !strings.Contains(out, "line a") || !strings.Contains(out, "line m") {
+			t.Errorf("-v should include every line, got %q", out)
 		}
-		if !strings.Contains(out, "3 more lines") {
-			t.Errorf("expected truncation note, got %q", out)
+		if strings.Contains(out, "more lines") {
+			t.Errorf("-v should not truncate, got %q", out)
 		}
 	})
 }
@@ -1,12 +1,29 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/ingest"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/redact"
+	"github.com/johnnyfreeman/viewscreen/sessions"
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/terminal"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
 )
 
 // Agent names selectable via the -agent flag. They identify which CLI
@@ -16,13 +33,64 @@ const (
 	AgentCodex  = "codex"
 )
 
+// Result summary styles selectable via the -summary flag.
+const (
+	SummaryCard  = "card"
+	SummaryPlain = "plain"
+)
+
+// Diff layouts selectable via the -diff-layout flag.
+const (
+	DiffLayoutUnified    = "unified"
+	DiffLayoutSideBySide = "side-by-side"
+)
+
+// Turn dividers selectable via the -turn-divider flag: none, a rule, or a
+// rule labeled with the turn that ended and its tokens.
+const (
+	TurnDividerNone    = "none"
+	TurnDividerThin    = "thin"
+	TurnDividerLabeled = "labeled"
+)
+
+// Subcommand names. A subcommand is the first argument after any global
+// flags; everything after it is parsed as flags and subcommand arguments.
+// After "--", the arguments are a prompt even when the first one names a
+// subcommand: viewscreen -- report on the failing tests.
+const (
+	CommandReplay   = "replay"
+	CommandUpdate   = "update"
+	CommandDoctor   = "doctor"
+	CommandBench    = "bench"
+	CommandStats    = "stats"
+	CommandSessions = "sessions"
+	CommandReport   = "report"
+)
+
+// DefaultSmoothGap is the longest wait between events a -smooth replay keeps.
+const DefaultSmoothGap = 2 * time.Second
+
+// commands lists the recognized subcommands.
+var commands = map[string]bool{
+	CommandReplay:   true,
+	CommandUpdate:   true,
+	CommandDoctor:   true,
+	CommandBench:    true,
+	CommandStats:    true,
+	CommandSessions: true,
+	CommandReport:   true,
+}
+
 // Provider abstracts config access for testability.
 type Provider interface {
 	IsVerbose() bool
 	IsVeryVerbose() bool
-	GetVerboseLevel() int
+	VerbosityLevel() int
 	NoColor() bool
 	ShowUsage() bool
+	DiffLayout() string
+	NoDiffHighlight() bool
+	ShowWhitespace() bool
 }
 
 // StyleInitializer is an interface for initializing styles
@@ -42,15 +110,224 @@ func (DefaultStyleInitializer) Init(disableColor bool) {
 // It implements the Provider interface directly, so it can be passed
 // anywhere a Provider is needed without an adapter.
 type Config struct {
-	VerboseLevel int
-	DisableColor bool
-	DisplayUsage bool
-	NoTUI        bool
-	AutoExit     bool
-	Dump         bool
-	PromptMode   bool
-	Prompt       string
-	Agent        string
+	VerboseLevel   int
+	DisableColor   bool
+	DisplayUsage   bool
+	NoTUI          bool
+	AutoExit       bool
+	Dump           bool
+	PromptMode     bool
+	Prompt         string
+	Agent          string
+	DiffLayoutMode string
+	// DisableDiffHighlight keeps diff backgrounds but skips syntax
+	// highlighting of diff lines, for very large edits.
+	DisableDiffHighlight bool
+	// MarkWhitespace shows tabs, trailing spaces and carriage returns in
+	// diff lines as visible markers.
+	MarkWhitespace bool
+	// PageOnExit, with -no-tui on a terminal, shows the transcript in
+	// $PAGER when it is taller than PageThreshold lines.
+	PageOnExit bool
+	// PageThreshold is how many lines -page-on-exit shows without a pager;
+	// 0 means the terminal's height.
+	PageThreshold int
+	// LowMemory renders strictly streaming, without the TUI's transcript
+	// or any other per-session buffer, for hosts with tight memory limits.
+	LowMemory bool
+	// PassNoise shows input lines that are not JSON, such as warnings of a
+	// wrapper script, as muted output instead of a warning for each.
+	PassNoise bool
+	// Width, when positive, is the width output is wrapped and truncated
+	// to instead of the terminal's (see terminal.SetWidth).
+	Width int
+	// TurnDivider separates the main agent's turns in the output: one of
+	// TurnDividerNone, TurnDividerThin or TurnDividerLabeled.
+	TurnDivider string
+	// StatusColumn marks each block's kind in a column left of the TUI
+	// transcript.
+	StatusColumn bool
+	// SummaryStyle is the layout of the session summary: SummaryCard or
+	// SummaryPlain. Empty (a config that was never parsed) means plain.
+	SummaryStyle string
+	// MCPHeaderFields maps an MCP server name to the input field shown in
+	// the headers of that server's tools.
+	MCPHeaderFields map[string]string
+	// ToolDefsPath is a JSON file of extra tool definitions to register.
+	ToolDefsPath string
+
+	// InputPath is a transcript file to read instead of stdin ("-" selects
+	// stdin explicitly). Follow keeps reading as the file grows.
+	InputPath string
+	Follow    bool
+	// Format is the input format: ingest.FormatAuto to detect it, or the
+	// name of an ingest adapter (see ingest.Formats).
+	Format string
+
+	// RecordPath, when set, tees every raw input line to a session file.
+	RecordPath string
+	// AutoRecord records live sessions without -record to a new file in
+	// the project's directory under SessionsDir (see package sessions),
+	// pruned by SessionsPolicy first.
+	AutoRecord bool
+	// SessionsDir is the root of the managed recordings, or empty for
+	// sessions.DefaultRoot.
+	SessionsDir    string
+	SessionsPolicy sessions.Policy
+
+	// TeePath and TeeFD, when set, receive a byte-for-byte copy of the raw
+	// input as it is rendered.
+	TeePath string
+	TeeFD   int
+
+	// KeyLogPath, when set, records the TUI's key presses and resizes with
+	// timestamps, for replay by the tui.Harness.
+	KeyLogPath string
+
+	// CaptureFixturesDir, when set, writes a sanitized fixture file for each
+	// new combination of event type and tool name seen in the input.
+	CaptureFixturesDir string
+	// Strict checks input events against the shapes viewscreen expects and
+	// writes a JSON diagnostic for each unknown field, type mismatch or
+	// unknown tool, to StrictLogPath when set and to stderr otherwise.
+	Strict        bool
+	StrictLogPath string
+	// DebugEventsPath, when set, logs how each input line was classified
+	// and rendered to this file as JSON lines.
+	DebugEventsPath string
+	// Speed is the playback speed multiplier for replay.
+	Speed float64
+	// Smooth caps the recorded wait between replayed events at SmoothGap,
+	// marking the idle periods it skips.
+	Smooth    bool
+	SmoothGap time.Duration
+	// Step starts replay paused in step mode, applying events only as the
+	// user steps through them in the TUI.
+	Step bool
+	// ExportPath, when set, writes the rendered session to a Markdown or
+	// HTML document (chosen by extension) when viewscreen exits.
+	ExportPath string
+	// ExportSplit, when positive, splits the export into chapter files of
+	// that many turns each, with an index page at ExportPath.
+	ExportSplit int
+	// TextLogPath, when set, writes a plain-text log of the rendered
+	// session as it streams. EmitJSONPath re-emits the input events as JSONL.
+	TextLogPath  string
+	EmitJSONPath string
+	// BlamePath, when set, writes a report of the turn that last changed
+	// each region of every file the agent edited when viewscreen exits.
+	BlamePath string
+	// SummaryJSONPath, when set, writes a JSON summary of the session for
+	// scripts when viewscreen exits.
+	SummaryJSONPath string
+	// Notify sends a desktop notification when the session finishes or
+	// fails, or when the agent waits on a question or a permission prompt.
+	Notify bool
+	// Budget is a spending limit in USD: a banner warns at 80% of it and
+	// again once it is exceeded. BudgetStop then also stops rendering and
+	// exits non-zero.
+	Budget     float64
+	BudgetStop bool
+	// Tags are -tag key=value metadata attached to the session through a
+	// preamble event (see package metadata).
+	Tags map[string]string
+	// ExitOnError makes the non-TUI runner exit non-zero when the final
+	// session failed or had tool calls denied permission.
+	ExitOnError bool
+	// Watch alerts on tool calls that touch the paths watchlist.DefaultPatterns
+	// and WatchPaths match; WatchFail then also makes the non-TUI runner exit
+	// non-zero when any did (see package watchlist).
+	Watch      bool
+	WatchPaths []string
+	WatchFail  bool
+	// ContextWarn and ContextCritical are the percentages of the context
+	// window at which the context gauge turns the warning and error colors.
+	ContextWarn     int
+	ContextCritical int
+	// PartialToolErrors and PartialDenials are the most tool errors and
+	// permission denials a finished session may have and still count as a
+	// success rather than partial (see package outcome).
+	PartialToolErrors int
+	PartialDenials    int
+	// FailOn makes the non-TUI runner exit non-zero when the session ends
+	// with one of these outcomes.
+	FailOn []outcome.Outcome
+	// Theme names the built-in color theme (see style.ThemeNames).
+	Theme string
+	// Background is the terminal background the theme's variant is picked
+	// for: "auto" (detected), "dark" or "light" (see style.ParseBackground).
+	Background string
+	// ColorOverrides replaces theme colors by semantic role (e.g.
+	// "success"), validated with style.ParseColorOverride.
+	ColorOverrides map[string]style.Color
+	// Only and Hide select the event and content block types to render
+	// (e.g. "assistant", "tool_result", "thinking"); see package filter.
+	Only []string
+	Hide []string
+	// MuteTools hides the calls and results of the named tools, and
+	// FocusTools those of every other tool.
+	MuteTools  []string
+	FocusTools []string
+	// Redact masks secrets in the input before it is rendered or exported;
+	// RedactPatterns are extra regular expressions to mask (see package
+	// redact).
+	Redact         bool
+	RedactPatterns []string
+	// NoRedactEnv shows the values of sensitive KEY=value assignments in
+	// shell commands, which are masked by default; RedactEnvKeys adds words
+	// that mark a key as sensitive (see redact.EnvAssignments).
+	NoRedactEnv   bool
+	RedactEnvKeys []string
+	// ResolveTitles looks up the titles of pages fetched by WebFetch and
+	// shows them in the tool headers. It makes requests of its own, so it
+	// is off unless asked for.
+	ResolveTitles bool
+	// MetricsListen, when set, serves Prometheus metrics about the rendered
+	// sessions on this address (e.g. ":9090").
+	MetricsListen string
+	// ServeAddr, when set, serves a web page on this address (e.g. ":8080")
+	// showing the session live as it is rendered.
+	ServeAddr string
+	// AnnotationsPath, when set, loads reviewer comments keyed by event UUID
+	// and renders them beneath the referenced blocks.
+	AnnotationsPath string
+
+	// Locale selects number separators (e.g. "de_DE"); empty uses the
+	// environment. Currency and CurrencyRate convert USD costs for display,
+	// and SpellOut shows token counts in full instead of compact.
+	Locale       string
+	Currency     string
+	CurrencyRate float64
+	SpellOut     bool
+
+	// LogLevel is the minimum severity of viewscreen's own diagnostics
+	// (debug, info, warn, error or off) printed to stderr.
+	LogLevel string
+
+	// CheckOnly makes the update subcommand only report whether a newer
+	// release exists, without installing it.
+	CheckOnly bool
+
+	// Runs is the number of times the bench subcommand renders its input.
+	Runs int
+
+	// StatsJSON makes the stats subcommand print JSON instead of a table.
+	StatsJSON bool
+	// ReportOut is the file report digest writes its Markdown to, or empty
+	// to print it rendered.
+	ReportOut string
+
+	// ConfigPath is the -config file flags were also read from. The TUI
+	// applies changes to its Live settings as they are made.
+	ConfigPath string
+	// cmdlineFlags are the flags given on the command line, which take
+	// precedence over the -config file's.
+	cmdlineFlags map[string]bool
+
+	// Command is the subcommand being run (e.g. "replay"), or empty for the
+	// default render/prompt mode. CommandArgs holds its positional arguments.
+	Command     string
+	CommandArgs []string
 }
 
 // IsVerbose implements Provider. True at -v or higher.
@@ -59,8 +336,8 @@ func (c *Config) IsVerbose() bool { return c.VerboseLevel >= 1 }
 // IsVeryVerbose implements Provider. True at -vv or higher.
 func (c *Config) IsVeryVerbose() bool { return c.VerboseLevel >= 2 }
 
-// GetVerboseLevel implements Provider.
-func (c *Config) GetVerboseLevel() int { return c.VerboseLevel }
+// VerbosityLevel implements Provider.
+func (c *Config) VerbosityLevel() int { return c.VerboseLevel }
 
 // NoColor implements Provider.
 func (c *Config) NoColor() bool { return c.DisableColor }
@@ -68,6 +345,86 @@ func (c *Config) NoColor() bool { return c.DisableColor }
 // ShowUsage implements Provider.
 func (c *Config) ShowUsage() bool { return c.DisplayUsage }
 
+// DiffLayout implements Provider.
+func (c *Config) DiffLayout() string {
+	if c.DiffLayoutMode == "" {
+		return DiffLayoutUnified
+	}
+	return c.DiffLayoutMode
+}
+
+// NoDiffHighlight implements Provider.
+func (c *Config) NoDiffHighlight() bool { return c.DisableDiffHighlight }
+
+// ShowWhitespace implements Provider.
+func (c *Config) ShowWhitespace() bool { return c.MarkWhitespace }
+
+// EnvSecretKeys returns the words that mark a
+// KEY=value assignment in a shell command as sensitive, or nil with
+// -no-redact-env.
+func (c *Config) EnvSecretKeys() []string {
+	if c.NoRedactEnv {
+		return nil
+	}
+	return append(slices.Clone(redact.DefaultEnvKeys), c.RedactEnvKeys...)
+}
+
+// MaxVerboseLevel is the highest verbosity the renderers distinguish (-vvv).
+const MaxVerboseLevel = 3
+
+// verbosityFlag is the -v flag. Each -v raises the level by one, so -v -v
+// is -vv, and -v=N sets it.
+type verbosityFlag struct{ level *int }
+
+func (f verbosityFlag) String() string {
+	if f.level == nil {
+		return "0"
+	}
+	return strconv.Itoa(*f.level)
+}
+
+func (f verbosityFlag) Set(s string) error {
+	switch s {
+	case "true":
+		*f.level++
+		return nil
+	case "false":
+		*f.level = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > MaxVerboseLevel {
+		return fmt.Errorf("want a level from 0 to %d", MaxVerboseLevel)
+	}
+	*f.level = n
+	return nil
+}
+
+// IsBoolFlag lets -v be given without a value.
+func (verbosityFlag) IsBoolFlag() bool { return true }
+
+// verbosityLevel combines the -v level with -vv and -vvv; the highest wins.
+func verbosityLevel(verbose int, veryVerbose, maxVerbose bool) int {
+	switch {
+	case maxVerbose:
+		return MaxVerboseLevel
+	case veryVerbose:
+		return max(min(verbose, MaxVerboseLevel), 2)
+	}
+	return min(verbose, MaxVerboseLevel)
+}
+
+// fullDetail is a Provider that reports the highest verbosity.
+type fullDetail struct{ Provider }
+
+func (fullDetail) IsVerbose() bool     { return true }
+func (fullDetail) IsVeryVerbose() bool { return true }
+func (fullDetail) VerbosityLevel() int { return MaxVerboseLevel }
+
+// FullDetail wraps p so that output is rendered at the highest verbosity,
+// whatever -v level p reports. Its other settings are kept.
+func FullDetail(p Provider) Provider { return fullDetail{p} }
+
 // cfg is the package-level config set by Parse().
 // Accessed via Get(). This replaces the old scattered global variables.
 var cfg = &Config{DisplayUsage: true}
@@ -83,6 +440,7 @@ type configParser struct {
 	args             []string
 	styleInitializer StyleInitializer
 	errOutput        io.Writer
+	getenv           func(string) string
 }
 
 // WithArgs sets the command line arguments to parse
@@ -113,11 +471,19 @@ func WithErrOutput(w io.Writer) Option {
 	}
 }
 
+// WithGetenv sets how environment variables such as NO_COLOR are read
+func WithGetenv(getenv func(string) string) Option {
+	return func(p *configParser) {
+		p.getenv = getenv
+	}
+}
+
 // Parse parses the provided arguments and returns a Config.
 // It also sets the package-level config accessible via Get().
 func Parse(opts ...Option) (*Config, error) {
 	p := &configParser{
 		styleInitializer: DefaultStyleInitializer{},
+		getenv:           os.Getenv,
 	}
 
 	for _, opt := range opts {
@@ -134,41 +500,336 @@ func Parse(opts ...Option) (*Config, error) {
 	}
 
 	c := &Config{
-		DisplayUsage: true, // Default value
+		DisplayUsage:   true, // Default value
+		SessionsPolicy: sessions.DefaultPolicy,
 	}
 
-	var verbose, veryVerbose, maxVerbose bool
-	p.flagSet.BoolVar(&verbose, "v", false, "Verbose output (expand writes, truncated)")
-	p.flagSet.BoolVar(&veryVerbose, "vv", false, "Very verbose (expand reads truncated, writes untruncated)")
-	p.flagSet.BoolVar(&maxVerbose, "vvv", false, "Max verbose (expand reads with more lines)")
-	p.flagSet.BoolVar(&c.DisableColor, "no-color", false, "Disable colored output")
+	var verbose int
+	var veryVerbose, maxVerbose bool
+	p.flagSet.Var(verbosityFlag{&verbose}, "v", "Verbosity level 1: full tool results (repeat, or -v=N, for higher levels)")
+	p.flagSet.BoolVar(&veryVerbose, "vv", false, "Verbosity level 2: also raw tool inputs and each turn's usage")
+	p.flagSet.BoolVar(&maxVerbose, "vvv", false, "Verbosity level 3: also the raw JSON of each event")
+	p.flagSet.BoolVar(&c.DisableColor, "no-color", false, "Disable colored output (default true when NO_COLOR is set, or TERM=dumb without CLICOLOR_FORCE)")
 	p.flagSet.BoolVar(&c.DisplayUsage, "usage", true, "Show token usage in result")
+	p.flagSet.StringVar(&c.SummaryStyle, "summary", SummaryCard, "Session summary style (card or plain)")
 	p.flagSet.BoolVar(&c.NoTUI, "no-tui", false, "Disable TUI mode (use legacy streaming output)")
+	p.flagSet.BoolVar(&c.PageOnExit, "page-on-exit", false, "With -no-tui on a terminal, show the transcript in $PAGER when it is taller than -page-threshold")
+	p.flagSet.IntVar(&c.PageThreshold, "page-threshold", 0, "Lines -page-on-exit shows without a pager (0 for the terminal's height)")
+	p.flagSet.BoolVar(&c.LowMemory, "low-memory", false, "Render strictly streaming with bounded memory (implies -no-tui)")
+	p.flagSet.IntVar(&c.Width, "width", 0, "Wrap and truncate -no-tui output to N columns instead of the terminal's width (0 follows the terminal, e.g. for piping to less -R)")
+	p.flagSet.StringVar(&c.TurnDivider, "turn-divider", TurnDividerNone, "Separate the agent's turns with a rule: none, thin, or labeled with the turn and its tokens")
+	p.flagSet.BoolVar(&c.PassNoise, "pass-noise", false, "Show input lines that are not JSON (e.g. npm warnings from a wrapper script) as muted output instead of warning about each")
+	p.flagSet.BoolVar(&c.StatusColumn, "status-column", false, "Mark each block's kind (assistant, tool, error, diff) in a column left of the TUI transcript")
 	p.flagSet.BoolVar(&c.AutoExit, "auto-exit", false, "Auto-exit after stream ends (useful in loops)")
 	p.flagSet.BoolVar(&c.Dump, "dump", false, "Print content to stdout on TUI exit (preserves output in scrollback)")
 	p.flagSet.BoolVar(&c.PromptMode, "p", false, "Treat stdin as a prompt (not a JSON stream)")
 	p.flagSet.StringVar(&c.Agent, "agent", AgentClaude, "Agent to spawn in prompt mode (claude or codex)")
+	p.flagSet.StringVar(&c.DiffLayoutMode, "diff-layout", DiffLayoutUnified, "Edit diff layout (unified or side-by-side)")
+	p.flagSet.BoolVar(&c.DisableDiffHighlight, "no-diff-highlight", false, "Skip syntax highlighting in diffs (keeps added/removed backgrounds)")
+	p.flagSet.BoolVar(&c.MarkWhitespace, "show-whitespace", false, "Mark tabs (⇥), trailing spaces (·) and carriage returns (␍) in diffs")
+	p.flagSet.StringVar(&c.Theme, "theme", "default", "Color theme ("+strings.Join(style.ThemeNames(), " or ")+")")
+	p.flagSet.StringVar(&c.Background, "background", "auto", "Terminal background to pick the theme's variant for (auto, dark or light)")
+	p.flagSet.Func("color", "Override a theme color by role, as role=#rrggbb (repeatable, e.g. success=#00aa55)", func(s string) error {
+		role, color, err := style.ParseColorOverride(s)
+		if err != nil {
+			return err
+		}
+		if c.ColorOverrides == nil {
+			c.ColorOverrides = map[string]style.Color{}
+		}
+		c.ColorOverrides[role] = color
+		return nil
+	})
+	p.flagSet.Func("mcp-header", "Input field to show in headers of an MCP server's tools, as server=field (repeatable)", func(s string) error {
+		server, field, ok := strings.Cut(s, "=")
+		if !ok || server == "" || field == "" {
+			return fmt.Errorf("invalid -mcp-header %q (want server=field)", s)
+		}
+		if c.MCPHeaderFields == nil {
+			c.MCPHeaderFields = map[string]string{}
+		}
+		c.MCPHeaderFields[server] = field
+		return nil
+	})
+	p.flagSet.StringVar(&c.ToolDefsPath, "tool-def", "", "Register tool definitions (header, count and file path fields) from a JSON file")
+	p.flagSet.BoolVar(&c.Follow, "follow", false, "Keep reading a transcript file as it grows (like tail -f)")
+	p.flagSet.StringVar(&c.Format, "format", ingest.FormatAuto, "Input format: auto (detect it), codex, gemini, opencode, openai (chat.completion.chunk streams) or claude-json (a session saved as one JSON document)")
+	p.flagSet.StringVar(&c.RecordPath, "record", "", "Record raw input lines with timestamps to a .viewscreen session file")
+	p.flagSet.BoolVar(&c.AutoRecord, "auto-record", false, "Record live sessions to the project's directory under -sessions-dir, pruning old recordings first")
+	p.flagSet.StringVar(&c.SessionsDir, "sessions-dir", "", "Directory of -auto-record recordings, one subdirectory per project (default $XDG_DATA_HOME/viewscreen or ~/.local/share/viewscreen)")
+	p.flagSet.IntVar(&c.SessionsPolicy.MaxCount, "sessions-max-count", sessions.DefaultPolicy.MaxCount, "Keep at most this many recordings per project (0 for no limit)")
+	p.flagSet.DurationVar(&c.SessionsPolicy.MaxAge, "sessions-max-age", sessions.DefaultPolicy.MaxAge, "Remove recordings older than this (0 for no limit)")
+	p.flagSet.Func("sessions-max-mb", fmt.Sprintf("Keep at most this many megabytes of recordings per project (0 for no limit) (default %d)", sessions.DefaultPolicy.MaxSize>>20), func(s string) error {
+		mb, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		c.SessionsPolicy.MaxSize = mb << 20
+		return nil
+	})
+	p.flagSet.StringVar(&c.TeePath, "tee", "", "Copy the raw input, unmodified, to this file while rendering")
+	p.flagSet.IntVar(&c.TeeFD, "tee-fd", 0, "Copy the raw input, unmodified, to this inherited file descriptor (e.g. 3) while rendering")
+	p.flagSet.StringVar(&c.KeyLogPath, "record-keys", "", "Record TUI key presses with timestamps to this file, for replay in UI tests")
+	p.flagSet.StringVar(&c.CaptureFixturesDir, "capture-fixtures", "", "Write one sanitized fixture per new event type/tool combination to this directory")
+	p.flagSet.BoolVar(&c.Strict, "strict", false, "Check events for unknown fields, type mismatches and unknown tools, writing JSON diagnostics to stderr")
+	p.flagSet.StringVar(&c.StrictLogPath, "strict-log", "", "With -strict: write the diagnostics to this file instead of stderr")
+	p.flagSet.StringVar(&c.DebugEventsPath, "debug-events", "", "Log each event's classification, renderer, rendered entries and parse errors to this file as JSON lines")
+	p.flagSet.Float64Var(&c.Speed, "speed", 1, "Playback speed multiplier for replay (0 disables pacing)")
+	p.flagSet.BoolVar(&c.Smooth, "smooth", false, "With replay: cap the wait between events at -smooth-gap and mark the idle time skipped")
+	p.flagSet.DurationVar(&c.SmoothGap, "smooth-gap", DefaultSmoothGap, "With -smooth: longest recorded wait between replayed events")
+	p.flagSet.BoolVar(&c.Step, "step", false, "With replay: step through events one at a time in the TUI")
+	p.flagSet.StringVar(&c.ExportPath, "export", "", "Write the session to a Markdown or HTML (.html) file on exit")
+	p.flagSet.IntVar(&c.ExportSplit, "export-split", 0, "With -export: split the session into chapter files of N turns with an index page (0 writes one file)")
+	p.flagSet.StringVar(&c.TextLogPath, "text-log", "", "Write a plain-text log of the rendered session to this file")
+	p.flagSet.StringVar(&c.EmitJSONPath, "emit-json", "", "Re-emit the input events as JSONL to this file")
+	p.flagSet.StringVar(&c.BlamePath, "blame", "", "Write a report of which turn last changed each edited file region to this file")
+	p.flagSet.StringVar(&c.SummaryJSONPath, "summary-json", "", "Write a JSON summary of cost, tokens, duration, files, errors, denials and tool counts to this file")
+	p.flagSet.BoolVar(&c.Notify, "notify", false, "Send a desktop notification when the session ends or waits on a question or permission prompt")
+	p.flagSet.Float64Var(&c.Budget, "budget", 0, "Warn when the session's cost reaches 80% of this many USD, and again when it exceeds it (0 disables)")
+	p.flagSet.BoolVar(&c.BudgetStop, "budget-stop", false, "Stop rendering and exit non-zero once -budget is exceeded")
+	p.flagSet.Func("tag", "Attach key=value metadata to the session, its recording, exports and reports (repeatable)", func(s string) error {
+		key, value, err := metadata.ParseTag(s)
+		if err != nil {
+			return err
+		}
+		if c.Tags == nil {
+			c.Tags = map[string]string{}
+		}
+		c.Tags[key] = value
+		return nil
+	})
+	p.flagSet.BoolVar(&c.ExitOnError, "exit-on-error", false, "Without the TUI: exit non-zero when the final result is an error or has permission denials")
+	p.flagSet.BoolVar(&c.Watch, "watch", false, "Alert when a tool reads or writes a sensitive path (.env, keys, ~/.ssh/**, cloud credentials)")
+	p.flagSet.Func("watch-path", "Also alert on paths matching this glob, e.g. 'config/secrets/**' (repeatable; implies -watch)", func(s string) error {
+		if err := watchlist.Validate(s); err != nil {
+			return err
+		}
+		c.Watch = true
+		c.WatchPaths = append(c.WatchPaths, s)
+		return nil
+	})
+	p.flagSet.BoolVar(&c.WatchFail, "watch-fail", false, "Without the TUI: exit non-zero when a tool touched a watched path (implies -watch)")
+	p.flagSet.IntVar(&c.ContextWarn, "context-warn", ctxwindow.DefaultThresholds.Warn, "Color the context window gauge as a warning from this percentage full")
+	p.flagSet.IntVar(&c.ContextCritical, "context-critical", ctxwindow.DefaultThresholds.Critical, "Color the context window gauge as an error from this percentage full")
+	p.flagSet.IntVar(&c.PartialToolErrors, "partial-tool-errors", outcome.DefaultRules.MaxToolErrors, "Count a session with more failed tool calls than this as partial rather than success")
+	p.flagSet.IntVar(&c.PartialDenials, "partial-denials", outcome.DefaultRules.MaxDenials, "Count a session with more permission denials than this as partial rather than success")
+	p.flagSet.Func("fail-on", "Without the TUI: exit non-zero when the session outcome is one of these comma-separated outcomes (success, partial, failed, interrupted)", func(s string) error {
+		for _, name := range splitList(s) {
+			o, err := outcome.Parse(name)
+			if err != nil {
+				return err
+			}
+			c.FailOn = append(c.FailOn, o)
+		}
+		return nil
+	})
+	p.flagSet.Func("only", "Render only these comma-separated event or block types (e.g. assistant,result)", func(s string) error {
+		c.Only = append(c.Only, splitList(s)...)
+		return nil
+	})
+	p.flagSet.Func("hide", "Skip these comma-separated event or block types (e.g. tool_result,thinking)", func(s string) error {
+		c.Hide = append(c.Hide, splitList(s)...)
+		return nil
+	})
+	p.flagSet.Func("mute-tool", "Hide the calls and results of these comma-separated tools (e.g. Read,Glob)", func(s string) error {
+		c.MuteTools = append(c.MuteTools, splitList(s)...)
+		return nil
+	})
+	p.flagSet.Func("focus-tool", "Show only the calls and results of these comma-separated tools (e.g. Edit,Bash)", func(s string) error {
+		c.FocusTools = append(c.FocusTools, splitList(s)...)
+		return nil
+	})
+	p.flagSet.BoolVar(&c.Redact, "redact", false, "Mask secrets (AWS keys, GitHub tokens, JWTs, Authorization headers) before rendering or exporting")
+	p.flagSet.Func("redact-pattern", "Also mask matches of this regular expression (repeatable; implies -redact)", func(s string) error {
+		if _, err := regexp.Compile(s); err != nil {
+			return err
+		}
+		c.Redact = true
+		c.RedactPatterns = append(c.RedactPatterns, s)
+		return nil
+	})
+	p.flagSet.StringVar(&c.ServeAddr, "serve", "", "Serve a live view of the session to browsers on this address, e.g. :8080")
+	p.flagSet.StringVar(&c.MetricsListen, "metrics-listen", "", "Serve Prometheus metrics (events, tool calls and durations, tokens, cost) at /metrics, and a JSON session snapshot at /status, on this address, e.g. :9090 (loopback only without a host)")
+	p.flagSet.BoolVar(&c.ResolveTitles, "resolve-titles", false, "Fetch the titles of pages the agent fetched and show them next to the URL (makes network requests)")
+	p.flagSet.BoolVar(&c.NoRedactEnv, "no-redact-env", false, "Show the values of TOKEN, SECRET and PASSWORD-like KEY=value assignments in shell commands")
+	p.flagSet.Func("redact-env-key", "Also mask KEY=value assignments whose key contains these comma-separated words", func(s string) error {
+		c.RedactEnvKeys = append(c.RedactEnvKeys, splitList(s)...)
+		return nil
+	})
+	p.flagSet.StringVar(&c.AnnotationsPath, "annotations", "", "Show reviewer comments from a JSON file mapping event UUIDs to comments")
+	p.flagSet.StringVar(&c.Locale, "locale", "", "Locale for number separators (default from LC_ALL, LC_NUMERIC or LANG)")
+	p.flagSet.StringVar(&c.Currency, "currency", "USD", "Currency to show costs in (ISO 4217 code)")
+	p.flagSet.Float64Var(&c.CurrencyRate, "currency-rate", 1, "Conversion rate from USD to -currency")
+	p.flagSet.BoolVar(&c.SpellOut, "spell-out", false, "Show token counts in full (12,345) instead of compact (12.3k)")
+	p.flagSet.StringVar(&c.LogLevel, "log-level", "warn", "Minimum severity of viewscreen diagnostics on stderr (debug, info, warn, error, off)")
+	p.flagSet.BoolVar(&c.CheckOnly, "check-only", false, "With update: only report whether a newer release exists")
+	p.flagSet.IntVar(&c.Runs, "runs", 5, "With bench: number of times to render the input")
+	p.flagSet.BoolVar(&c.StatsJSON, "json", false, "With stats: print JSON instead of a table")
+	p.flagSet.StringVar(&c.ReportOut, "out", "", "With report digest: write the Markdown digest to this file instead of printing it")
+	p.flagSet.StringVar(&c.ConfigPath, "config", "", "Also read flags, one per line, from this file; the command line takes precedence, and the TUI applies changes to its -theme, -color, -only, -hide, -mute-tool and -focus-tool live")
+
+	// The command may follow global flags: "-speed 2 replay run.viewscreen".
+	args := p.args
+	forcedPrompt := false
+	if len(args) == 0 || !commands[args[0]] {
+		if err := p.flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		args = p.flagSet.Args()
+		consumed := len(p.args) - len(args)
+		forcedPrompt = consumed > 0 && p.args[consumed-1] == "--"
+	}
+	if len(args) > 0 && commands[args[0]] && !forcedPrompt {
+		c.Command = args[0]
+		rest, err := parseInterleaved(p.flagSet, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		c.CommandArgs = rest
+	}
 
-	if err := p.flagSet.Parse(p.args); err != nil {
-		return nil, err
+	c.cmdlineFlags = setFlags(p.flagSet)
+	if c.ConfigPath != "" {
+		if err := applyConfigFile(p.flagSet, c.ConfigPath, c.cmdlineFlags); err != nil {
+			return nil, err
+		}
 	}
 
 	if c.Agent != AgentClaude && c.Agent != AgentCodex {
 		return nil, fmt.Errorf("unknown agent %q (want %q or %q)", c.Agent, AgentClaude, AgentCodex)
 	}
 
-	// Compute verbose level from flags (highest wins)
-	if maxVerbose {
-		c.VerboseLevel = 3
-	} else if veryVerbose {
-		c.VerboseLevel = 2
-	} else if verbose {
-		c.VerboseLevel = 1
+	if c.SummaryStyle != SummaryCard && c.SummaryStyle != SummaryPlain {
+		return nil, fmt.Errorf("unknown summary style %q (want %q or %q)", c.SummaryStyle, SummaryCard, SummaryPlain)
+	}
+
+	if c.Budget < 0 {
+		return nil, fmt.Errorf("-budget must not be negative, got %g", c.Budget)
+	}
+	if c.BudgetStop && c.Budget == 0 {
+		return nil, errors.New("-budget-stop requires -budget")
+	}
+	if c.StrictLogPath != "" && !c.Strict {
+		return nil, errors.New("-strict-log requires -strict")
+	}
+
+	contextThresholds := ctxwindow.Thresholds{Warn: c.ContextWarn, Critical: c.ContextCritical}
+	if err := contextThresholds.Validate(); err != nil {
+		return nil, fmt.Errorf("-context-warn and -context-critical: %w", err)
+	}
+
+	outcomeRules := outcome.Rules{MaxToolErrors: c.PartialToolErrors, MaxDenials: c.PartialDenials}
+	if err := outcomeRules.Validate(); err != nil {
+		return nil, fmt.Errorf("-partial-tool-errors and -partial-denials: %w", err)
+	}
+
+	theme, err := style.LookupTheme(c.Theme)
+	if err != nil {
+		return nil, err
+	}
+	background, err := style.ParseBackground(c.Background)
+	if err != nil {
+		return nil, err
+	}
+
+	var watched *watchlist.Watchlist
+	if c.Watch || c.WatchFail {
+		c.Watch = true
+		watched, err = watchlist.New(append(slices.Clone(watchlist.DefaultPatterns), c.WatchPaths...))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if formats := ingest.Formats(); !slices.Contains(formats, c.Format) {
+		return nil, fmt.Errorf("unknown input format %q (want one of %s)", c.Format, strings.Join(formats, ", "))
+	}
+
+	if c.DiffLayoutMode != DiffLayoutUnified && c.DiffLayoutMode != DiffLayoutSideBySide {
+		return nil, fmt.Errorf("unknown diff layout %q (want %q or %q)", c.DiffLayoutMode, DiffLayoutUnified, DiffLayoutSideBySide)
+	}
+
+	switch c.TurnDivider {
+	case TurnDividerNone, TurnDividerThin, TurnDividerLabeled:
+	default:
+		return nil, fmt.Errorf("unknown turn divider %q (want %q, %q or %q)", c.TurnDivider, TurnDividerNone, TurnDividerThin, TurnDividerLabeled)
+	}
+
+	numbers, err := c.numberFormatter()
+	if err != nil {
+		return nil, err
+	}
+	logLevel, err := diag.ParseLevel(c.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Speed < 0 {
+		return nil, fmt.Errorf("invalid speed %v (must be >= 0)", c.Speed)
+	}
+	if c.Width < 0 {
+		return nil, fmt.Errorf("invalid width %d (must be >= 0)", c.Width)
+	}
+	if c.ExportSplit < 0 {
+		return nil, fmt.Errorf("invalid export split %d (must be >= 0)", c.ExportSplit)
+	}
+	if c.Command == CommandReplay && len(c.CommandArgs) != 1 {
+		return nil, errors.New("usage: viewscreen replay [flags] <file.viewscreen>")
+	}
+	if c.TeeFD < 0 || c.TeeFD == 1 {
+		return nil, fmt.Errorf("-tee-fd %d: want an open descriptor other than stdout", c.TeeFD)
+	}
+	if c.Smooth && (c.Command != CommandReplay || c.SmoothGap <= 0) {
+		return nil, errors.New("-smooth requires replay and a positive -smooth-gap")
+	}
+	if c.Step && (c.Command != CommandReplay || c.NoTUI) {
+		return nil, errors.New("-step requires replay in the TUI")
+	}
+	if c.LowMemory {
+		if c.ExportPath != "" || c.BlamePath != "" || c.PageOnExit || c.ResolveTitles || c.Step || c.ServeAddr != "" {
+			return nil, errors.New("-low-memory cannot be combined with -export, -blame, -page-on-exit, -resolve-titles, -step or -serve, which keep the whole session")
+		}
+		c.NoTUI = true
+	}
+	if c.KeyLogPath != "" && c.NoTUI {
+		return nil, errors.New("-record-keys requires the TUI")
+	}
+	if c.Command == CommandUpdate && len(c.CommandArgs) != 0 {
+		return nil, errors.New("usage: viewscreen update [-check-only]")
+	}
+	if c.Command == CommandDoctor && len(c.CommandArgs) != 0 {
+		return nil, errors.New("usage: viewscreen doctor [flags]")
+	}
+	if c.Command == CommandBench && (len(c.CommandArgs) != 1 || c.Runs < 1) {
+		return nil, errors.New("usage: viewscreen bench [-runs N] <transcript or .viewscreen file>")
+	}
+	if c.Command == CommandStats && len(c.CommandArgs) != 1 {
+		return nil, errors.New("usage: viewscreen stats [-json] <transcript or .viewscreen file>")
+	}
+	if c.Command == CommandSessions && !slices.Equal(c.CommandArgs, []string{"prune"}) {
+		return nil, errors.New("usage: viewscreen sessions prune [-sessions-dir dir] [-sessions-max-count n] [-sessions-max-age d] [-sessions-max-mb n]")
+	}
+	if c.Command == CommandReport && !slices.Equal(c.CommandArgs, []string{"digest"}) {
+		return nil, errors.New("usage: viewscreen report digest [-out file] [-sessions-dir dir]")
+	}
+	if err := c.SessionsPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("-sessions-max-count, -sessions-max-age and -sessions-max-mb: %w", err)
 	}
 
-	// Capture positional args as prompt text
-	if args := p.flagSet.Args(); len(args) > 0 {
-		c.Prompt = strings.Join(args, " ")
+	c.VerboseLevel = verbosityLevel(verbose, veryVerbose, maxVerbose)
+
+	// Capture positional args as prompt text. A single argument that names a
+	// transcript, or any with -follow, is read instead, unless -p or "--"
+	// says it is a prompt.
+	if args := p.flagSet.Args(); c.Command == "" && len(args) > 0 {
+		if len(args) == 1 && !c.PromptMode && !forcedPrompt && (c.Follow || isInputPath(args[0])) {
+			c.InputPath = args[0]
+		} else {
+			c.Prompt = strings.Join(args, " ")
+		}
+	}
+	if c.Follow && (c.InputPath == "" || c.InputPath == "-") {
+		return nil, errors.New("-follow requires a transcript file argument")
 	}
 
 	// Auto-enable dump when auto-exit is set (loop-friendly default)
@@ -176,7 +837,29 @@ func Parse(opts ...Option) (*Config, error) {
 		c.Dump = true
 	}
 
+	// NO_COLOR, CLICOLOR_FORCE and TERM=dumb decide color unless -no-color
+	// is given either way.
+	colorEnv := style.DetectColorEnv(p.getenv)
+	if colorEnv.NoColor && !isFlagSet(p.flagSet, "no-color") {
+		c.DisableColor = true
+	}
+	style.SetProfile(colorEnv.Profile)
+	style.SetASCII(colorEnv.Dumb)
+	style.SetBaseTheme(theme)
+	style.SetBackground(background)
+	// Only runs that render a session ask the terminal for its background.
+	style.SetQueryTerminal(c.Command == "" || c.Command == CommandReplay)
+	style.SetOverrides(c.ColorOverrides)
 	p.styleInitializer.Init(c.DisableColor)
+	terminal.SetWidth(c.Width)
+	numfmt.SetDefault(numbers)
+	ctxwindow.SetDefault(contextThresholds)
+	outcome.SetDefault(outcomeRules)
+	watchlist.SetDefault(watched)
+	diag.SetDefault(diag.New(os.Stderr, logLevel))
+	if !c.DisableColor {
+		warnLowContrast(style.CurrentTheme, c.ColorOverrides)
+	}
 
 	// Set the package-level config
 	cfg = c
@@ -184,6 +867,102 @@ func Parse(opts ...Option) (*Config, error) {
 	return c, nil
 }
 
+// warnLowContrast warns about the pairs of colors in t that the -color
+// overrides leave below their WCAG contrast ratio, with a color to try
+// instead for each.
+func warnLowContrast(t style.Theme, overrides map[string]style.Color) {
+	if len(overrides) == 0 {
+		return
+	}
+	roles := make([]string, 0, len(overrides))
+	for role := range overrides {
+		roles = append(roles, role)
+	}
+	issues := t.CheckContrast(roles...)
+	if len(issues) == 0 {
+		return
+	}
+	descriptions := make([]string, len(issues))
+	for i, issue := range issues {
+		descriptions[i] = issue.String()
+	}
+	diag.Default().Warnf("low-contrast theme colors: %s", strings.Join(descriptions, "; "))
+}
+
+// splitList splits a comma-separated flag value into names, dropping blanks.
+func splitList(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// numberFormatter builds the formatter for the locale and currency flags. An
+// explicit -locale must be known; a locale from the environment that is not
+// falls back to English separators.
+func (c *Config) numberFormatter() (*numfmt.Formatter, error) {
+	locale := numfmt.English
+	if c.Locale != "" {
+		l, err := numfmt.ParseLocale(c.Locale)
+		if err != nil {
+			return nil, err
+		}
+		locale = l
+	} else if l, err := numfmt.ParseLocale(numfmt.EnvLocale()); err == nil {
+		locale = l
+	}
+
+	currency, err := numfmt.ParseCurrency(c.Currency, c.CurrencyRate)
+	if err != nil {
+		return nil, err
+	}
+	return numfmt.New(
+		numfmt.WithLocale(locale),
+		numfmt.WithCurrency(currency),
+		numfmt.WithSpellOut(c.SpellOut),
+	), nil
+}
+
+// parseInterleaved parses flags that may appear before or after positional
+// arguments (e.g. "replay session.viewscreen -speed 2") and returns the
+// positional arguments in order.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// A "--" terminator makes everything after it positional.
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// isInputPath reports whether arg names a transcript to read: "-" for stdin
+// or a .jsonl or .json file. Other files are read only with -follow, so a
+// one-word prompt that happens to name a file, such as README.md, is still
+// a prompt.
+func isInputPath(arg string) bool {
+	if arg == "-" {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(arg)) {
+	case ".jsonl", ".json":
+		return true
+	}
+	return false
+}
+
 // isFlagSet checks if a flag was explicitly set on the command line.
 func isFlagSet(fs *flag.FlagSet, name string) bool {
 	found := false
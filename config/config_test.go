@@ -4,7 +4,23 @@ import (
 	"bytes"
 	"flag"
 	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/ingest"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/sessions"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/terminal"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
 )
 
 // MockStyleInitializer is a mock implementation of StyleInitializer for testing
@@ -95,6 +111,27 @@ func TestParse_VerboseFlag(t *testing.T) {
 			args:      []string{"-v=false"},
 			wantLevel: 0,
 		},
+		{
+			name:            "repeated -v -v",
+			args:            []string{"-v", "-v"},
+			wantLevel:       2,
+			wantVerbose:     true,
+			wantVeryVerbose: true,
+		},
+		{
+			name:            "explicit level",
+			args:            []string{"-v=3"},
+			wantLevel:       3,
+			wantVerbose:     true,
+			wantVeryVerbose: true,
+		},
+		{
+			name:            "repeated beyond max",
+			args:            []string{"-v", "-v", "-v", "-v"},
+			wantLevel:       3,
+			wantVerbose:     true,
+			wantVeryVerbose: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +159,14 @@ func TestParse_VerboseFlag(t *testing.T) {
 	}
 }
 
+func TestParse_VerboseFlagInvalid(t *testing.T) {
+	for _, arg := range []string{"-v=4", "-v=-1", "-v=lots"} {
+		if _, err := Parse(WithArgs([]string{arg}), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(%s): expected an error", arg)
+		}
+	}
+}
+
 func TestParse_NoColorFlag(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -653,188 +698,1263 @@ func TestParse_AgentFlag(t *testing.T) {
 	}
 }
 
-func TestParse_DumpFlag(t *testing.T) {
+func TestParse_DiffLayoutFlag(t *testing.T) {
 	tests := []struct {
-		name     string
-		args     []string
-		expected bool
+		name      string
+		args      []string
+		want      string
+		wantError bool
 	}{
-		{
-			name:     "not set",
-			args:     []string{},
-			expected: false,
-		},
-		{
-			name:     "flag set",
-			args:     []string{"-dump"},
-			expected: true,
-		},
-		{
-			name:     "explicit true",
-			args:     []string{"-dump=true"},
-			expected: true,
-		},
-		{
-			name:     "explicit false",
-			args:     []string{"-dump=false"},
-			expected: false,
-		},
+		{name: "default is unified", args: []string{}, want: DiffLayoutUnified},
+		{name: "side-by-side", args: []string{"--diff-layout", "side-by-side"}, want: DiffLayoutSideBySide},
+		{name: "unknown rejected", args: []string{"-diff-layout", "split"}, wantError: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mock := &MockStyleInitializer{}
 			cfg, err := Parse(
 				WithArgs(tt.args),
-				WithStyleInitializer(mock),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
 			)
 
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v, got nil", tt.args)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-
-			if cfg.Dump != tt.expected {
-				t.Errorf("Dump: got %v, want %v", cfg.Dump, tt.expected)
+			if got := cfg.DiffLayout(); got != tt.want {
+				t.Errorf("DiffLayout: got %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestParse_AutoExitImpliesDump(t *testing.T) {
+func TestParse_TurnDividerFlag(t *testing.T) {
 	tests := []struct {
-		name     string
-		args     []string
-		wantDump bool
+		name      string
+		args      []string
+		want      string
+		wantError bool
 	}{
-		{
-			name:     "auto-exit alone enables dump",
-			args:     []string{"-auto-exit"},
-			wantDump: true,
-		},
-		{
-			name:     "auto-exit with explicit dump=false",
-			args:     []string{"-auto-exit", "-dump=false"},
-			wantDump: false,
-		},
-		{
-			name:     "auto-exit with explicit dump=true",
-			args:     []string{"-auto-exit", "-dump=true"},
-			wantDump: true,
-		},
-		{
-			name:     "no auto-exit no dump",
-			args:     []string{},
-			wantDump: false,
-		},
+		{name: "default is none", args: []string{}, want: TurnDividerNone},
+		{name: "labeled", args: []string{"-turn-divider", "labeled"}, want: TurnDividerLabeled},
+		{name: "unknown rejected", args: []string{"-turn-divider", "thick"}, wantError: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mock := &MockStyleInitializer{}
 			cfg, err := Parse(
 				WithArgs(tt.args),
-				WithStyleInitializer(mock),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
 			)
 
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v, got nil", tt.args)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-
-			if cfg.Dump != tt.wantDump {
-				t.Errorf("Dump: got %v, want %v", cfg.Dump, tt.wantDump)
+			if cfg.TurnDivider != tt.want {
+				t.Errorf("TurnDivider: got %q, want %q", cfg.TurnDivider, tt.want)
 			}
 		})
 	}
 }
 
-func TestParse_FlagOrderIndependent(t *testing.T) {
+func TestParse_SummaryFlag(t *testing.T) {
 	tests := []struct {
-		name string
-		args []string
+		name      string
+		args      []string
+		want      string
+		wantError bool
 	}{
-		{
-			name: "v before no-color",
-			args: []string{"-v", "-no-color"},
-		},
-		{
-			name: "no-color before v",
-			args: []string{"-no-color", "-v"},
-		},
-		{
-			name: "usage in middle",
-			args: []string{"-v", "-usage=false", "-no-color"},
-		},
+		{name: "default is card", args: []string{}, want: SummaryCard},
+		{name: "plain", args: []string{"--summary", "plain"}, want: SummaryPlain},
+		{name: "unknown rejected", args: []string{"-summary", "table"}, wantError: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mock := &MockStyleInitializer{}
 			cfg, err := Parse(
 				WithArgs(tt.args),
-				WithStyleInitializer(mock),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
 			)
 
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v, got nil", tt.args)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-
-			if !cfg.IsVerbose() {
-				t.Error("expected Verbose to be true")
-			}
-
-			if !cfg.DisableColor {
-				t.Error("expected DisableColor to be true")
+			if cfg.SummaryStyle != tt.want {
+				t.Errorf("SummaryStyle: got %q, want %q", cfg.SummaryStyle, tt.want)
 			}
 		})
 	}
 }
 
-func TestConfig_ImplementsProvider(t *testing.T) {
-	cfg := &Config{
-		VerboseLevel: 1,
-		DisableColor: true,
-		DisplayUsage: false,
+func TestParse_NoDiffHighlightFlag(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.NoDiffHighlight() {
+		t.Error("NoDiffHighlight should default to false")
 	}
 
-	// Verify Config implements Provider interface
-	var p Provider = cfg
-	if !p.IsVerbose() {
-		t.Error("expected IsVerbose() to be true")
+	cfg, err = Parse(WithArgs([]string{"--no-diff-highlight"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if p.IsVeryVerbose() {
-		t.Error("expected IsVeryVerbose() to be false at level 1")
+	if !cfg.NoDiffHighlight() {
+		t.Error("NoDiffHighlight should be true with --no-diff-highlight")
 	}
-	if !p.NoColor() {
-		t.Error("expected NoColor() to be true")
+}
+
+func TestParse_ShowWhitespaceFlag(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if p.ShowUsage() {
-		t.Error("expected ShowUsage() to be false")
+	if cfg.ShowWhitespace() {
+		t.Error("ShowWhitespace should default to false")
+	}
+
+	cfg, err = Parse(WithArgs([]string{"-show-whitespace"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ShowWhitespace() {
+		t.Error("ShowWhitespace should be true with -show-whitespace")
 	}
 }
 
-func TestGet_ReturnsNonNil(t *testing.T) {
-	cfg := Get()
-	if cfg == nil {
-		t.Fatal("expected Get() to return non-nil config")
+func TestParse_RedactEnvFlags(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{"-redact-env-key", "pin,otp"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := cfg.EnvSecretKeys()
+	if !slices.Contains(keys, "TOKEN") || !slices.Contains(keys, "pin") || !slices.Contains(keys, "otp") {
+		t.Errorf("EnvSecretKeys() = %v, want the defaults and the extra keys", keys)
+	}
+
+	cfg, err = Parse(WithArgs([]string{"-no-redact-env"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := cfg.EnvSecretKeys(); keys != nil {
+		t.Errorf("EnvSecretKeys() = %v, want nil with -no-redact-env", keys)
 	}
 }
 
-func TestParse_SetsGlobalConfig(t *testing.T) {
-	mock := &MockStyleInitializer{}
+func TestParse_MCPHeaderFlag(t *testing.T) {
 	cfg, err := Parse(
-		WithArgs([]string{"-v"}),
-		WithStyleInitializer(mock),
+		WithArgs([]string{"-mcp-header", "github=query", "-mcp-header", "docs=topic"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"github": "query", "docs": "topic"}
+	if len(cfg.MCPHeaderFields) != len(want) {
+		t.Fatalf("MCPHeaderFields = %v, want %v", cfg.MCPHeaderFields, want)
+	}
+	for server, field := range want {
+		if cfg.MCPHeaderFields[server] != field {
+			t.Errorf("MCPHeaderFields[%q] = %q, want %q", server, cfg.MCPHeaderFields[server], field)
+		}
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-mcp-header", "github"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil {
+		t.Error("expected error for -mcp-header without a field")
+	}
+}
+
+func TestParse_ColorFlag(t *testing.T) {
+	defer style.SetOverrides(nil)
+	cfg, err := Parse(
+		WithArgs([]string{"-color", "success=#00aa55", "-color", "error=#ff5555"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ColorOverrides["success"] != "#00aa55" || cfg.ColorOverrides["error"] != "#ff5555" {
+		t.Errorf("ColorOverrides = %v", cfg.ColorOverrides)
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-color", "sucess=#00aa55"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
 	)
+	if err == nil || !strings.Contains(err.Error(), "unknown color role") {
+		t.Errorf("expected unknown role error, got %v", err)
+	}
+}
 
+func TestParse_PageOnExitFlag(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{"-no-tui", "-page-on-exit", "-page-threshold", "40"}), WithStyleInitializer(&MockStyleInitializer{}))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if !cfg.PageOnExit || cfg.PageThreshold != 40 {
+		t.Errorf("PageOnExit, PageThreshold = %v, %d, want true, 40", cfg.PageOnExit, cfg.PageThreshold)
+	}
+}
 
-	got := Get()
-	if got != cfg {
-		t.Error("expected Get() to return the config set by Parse()")
+func TestParse_BudgetFlags(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{"-budget", "1.50", "-budget-stop"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if !got.IsVerbose() {
-		t.Error("expected global config to have IsVerbose()=true")
+	if cfg.Budget != 1.5 || !cfg.BudgetStop {
+		t.Errorf("Budget = %g, BudgetStop = %v", cfg.Budget, cfg.BudgetStop)
+	}
+
+	for _, args := range [][]string{{"-budget", "-1"}, {"-budget-stop"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("Parse(%v): expected an error", args)
+		}
+	}
+}
+
+func TestParse_ContextThresholdFlags(t *testing.T) {
+	defer ctxwindow.SetDefault(ctxwindow.DefaultThresholds)
+	cfg, err := Parse(WithArgs([]string{"-context-warn", "50", "-context-critical", "80"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ContextWarn != 50 || cfg.ContextCritical != 80 {
+		t.Errorf("ContextWarn = %d, ContextCritical = %d", cfg.ContextWarn, cfg.ContextCritical)
+	}
+	if got := ctxwindow.Default(); got != (ctxwindow.Thresholds{Warn: 50, Critical: 80}) {
+		t.Errorf("ctxwindow.Default() = %v, want the flags' thresholds", got)
+	}
+
+	for _, args := range [][]string{{"-context-warn", "95"}, {"-context-critical", "120"}, {"-context-warn", "0"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("Parse(%v): expected an error", args)
+		}
+	}
+}
+
+func TestParse_OutcomeFlags(t *testing.T) {
+	defer outcome.SetDefault(outcome.DefaultRules)
+	cfg, err := Parse(WithArgs([]string{"-partial-tool-errors", "5", "-partial-denials", "1", "-fail-on", "partial, failed", "-fail-on", "interrupted"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []outcome.Outcome{outcome.Partial, outcome.Failed, outcome.Interrupted}; !slices.Equal(cfg.FailOn, want) {
+		t.Errorf("FailOn = %v, want %v", cfg.FailOn, want)
+	}
+	if got := outcome.Default(); got != (outcome.Rules{MaxToolErrors: 5, MaxDenials: 1}) {
+		t.Errorf("outcome.Default() = %v, want the flags' rules", got)
+	}
+
+	for _, args := range [][]string{{"-partial-tool-errors", "-1"}, {"-partial-denials", "-2"}, {"-fail-on", "crashed"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("Parse(%v): expected an error", args)
+		}
+	}
+}
+
+func TestParse_NotifyFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-notify"}, true},
+	} {
+		cfg, err := Parse(WithArgs(tt.args), WithStyleInitializer(&MockStyleInitializer{}))
+		if err != nil {
+			t.Fatalf("Parse(%v): unexpected error: %v", tt.args, err)
+		}
+		if cfg.Notify != tt.want {
+			t.Errorf("Parse(%v): Notify = %v, want %v", tt.args, cfg.Notify, tt.want)
+		}
+	}
+}
+
+func TestParse_TagFlag(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{"-tag", "repo=viewscreen", "-tag", "ticket=ENG-12", "-tag", "repo=other"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Tags) != 2 || cfg.Tags["repo"] != "other" || cfg.Tags["ticket"] != "ENG-12" {
+		t.Errorf("Tags = %v, want the last repo and the ticket", cfg.Tags)
+	}
+	if _, err := Parse(WithArgs([]string{"-tag", "novalue"}), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+		t.Error("expected an error for a tag without =")
+	}
+}
+
+func TestParse_ExitOnErrorFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-exit-on-error"}, true},
+	} {
+		cfg, err := Parse(WithArgs(tt.args), WithStyleInitializer(&MockStyleInitializer{}))
+		if err != nil {
+			t.Fatalf("Parse(%v): unexpected error: %v", tt.args, err)
+		}
+		if cfg.ExitOnError != tt.want {
+			t.Errorf("Parse(%v): ExitOnError = %v, want %v", tt.args, cfg.ExitOnError, tt.want)
+		}
+	}
+}
+
+func TestParse_FormatFlag(t *testing.T) {
+	cfg, err := Parse(WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Format != ingest.FormatAuto {
+		t.Errorf("default Format = %q, want %q", cfg.Format, ingest.FormatAuto)
+	}
+
+	cfg, err = Parse(WithArgs([]string{"-format", "openai"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Format != "openai" {
+		t.Errorf("Format = %q, want %q", cfg.Format, "openai")
+	}
+
+	_, err = Parse(WithArgs([]string{"-format", "aider"}), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard))
+	if err == nil || !strings.Contains(err.Error(), "unknown input format") {
+		t.Errorf("expected unknown input format error, got %v", err)
+	}
+}
+
+func TestParse_ThemeFlag(t *testing.T) {
+	defer style.SetBaseTheme(style.DefaultTheme)
+	cfg, err := Parse(
+		WithArgs([]string{"-theme", "high-contrast"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Theme != "high-contrast" {
+		t.Errorf("Theme = %q", cfg.Theme)
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-theme", "neon"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil || !strings.Contains(err.Error(), "unknown theme") {
+		t.Errorf("expected unknown theme error, got %v", err)
+	}
+}
+
+func TestParse_BackgroundFlag(t *testing.T) {
+	defer style.SetBackground(style.BackgroundDark)
+	cfg, err := Parse(
+		WithArgs([]string{"-background", "light"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Background != "light" {
+		t.Errorf("Background = %q", cfg.Background)
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-background", "sepia"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil || !strings.Contains(err.Error(), "unknown background") {
+		t.Errorf("expected unknown background error, got %v", err)
+	}
+}
+
+func TestParse_ColorEnv(t *testing.T) {
+	defer style.SetProfile(colorprofile.TrueColor)
+	defer style.SetASCII(false)
+	tests := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		wantNoColor bool
+		wantProfile colorprofile.Profile
+	}{
+		{"no env", nil, nil, false, colorprofile.TrueColor},
+		{"NO_COLOR", nil, map[string]string{"NO_COLOR": "1"}, true, colorprofile.TrueColor},
+		{"NO_COLOR overridden by flag", []string{"-no-color=false"}, map[string]string{"NO_COLOR": "1"}, false, colorprofile.TrueColor},
+		{"dumb", nil, map[string]string{"TERM": "dumb"}, true, colorprofile.ANSI},
+		{"dumb forced", nil, map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "1"}, false, colorprofile.ANSI},
+		{"NO_COLOR wins over CLICOLOR_FORCE", nil, map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, true, colorprofile.TrueColor},
+		{"256 colors", nil, map[string]string{"TERM": "xterm-256color"}, false, colorprofile.ANSI256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockStyleInitializer{}
+			_, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(mock),
+				WithGetenv(func(k string) string { return tt.env[k] }),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mock.DisableColor != tt.wantNoColor {
+				t.Errorf("DisableColor = %v, want %v", mock.DisableColor, tt.wantNoColor)
+			}
+			if got := style.Profile(); got != tt.wantProfile {
+				t.Errorf("Profile = %v, want %v", got, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestParse_WidthFlag(t *testing.T) {
+	defer terminal.SetWidth(0)
+	cfg, err := Parse(
+		WithArgs([]string{"-width", "100"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Width != 100 || terminal.Width() != 100 {
+		t.Errorf("Width = %d, terminal.Width() = %d; want 100", cfg.Width, terminal.Width())
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-width", "-1"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid width") {
+		t.Errorf("expected invalid width error, got %v", err)
+	}
+}
+
+func TestParse_WatchFlags(t *testing.T) {
+	defer watchlist.SetDefault(nil)
+	cfg, err := Parse(
+		WithArgs([]string{"-watch-path", "config/secrets/**", "-watch-fail"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Watch || !cfg.WatchFail || len(cfg.WatchPaths) != 1 {
+		t.Errorf("Watch = %v, WatchFail = %v, WatchPaths = %q", cfg.Watch, cfg.WatchFail, cfg.WatchPaths)
+	}
+	w := watchlist.Default()
+	if _, ok := w.Match("/repo/config/secrets/db.yaml"); !ok {
+		t.Error("expected the -watch-path pattern to be watched")
+	}
+	if _, ok := w.Match("/repo/.env"); !ok {
+		t.Error("expected the default patterns to be watched")
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-watch-path", "[a-"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid watch pattern") {
+		t.Errorf("expected invalid watch pattern error, got %v", err)
+	}
+}
+
+func TestWarnLowContrast(t *testing.T) {
+	defer diag.SetDefault(diag.Default())
+	var buf bytes.Buffer
+	diag.SetDefault(diag.New(&buf, diag.LevelWarn))
+
+	overrides := map[string]style.Color{"info": "#22D3EE"}
+	warnLowContrast(style.DefaultTheme.Apply(overrides), overrides)
+	if buf.Len() != 0 {
+		t.Errorf("unexpected warning for a readable override: %q", buf.String())
+	}
+
+	overrides = map[string]style.Color{"fg": "#333333", "warning": "#3F3F46"}
+	warnLowContrast(style.DefaultTheme.Apply(overrides), overrides)
+	got := buf.String()
+	for _, want := range []string{"low-contrast theme colors", "fg on bg", "warning on bg-overlay", "try -color fg=#"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("warning %q missing %q", got, want)
+		}
+	}
+	if strings.Count(got, "\n") != 1 {
+		t.Errorf("expected one warning line, got %q", got)
+	}
+}
+
+func TestParse_FilterFlags(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-hide", "tool_result,thinking", "-hide", "system", "-only", "assistant, user"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(cfg.Hide, ","); got != "tool_result,thinking,system" {
+		t.Errorf("Hide = %v", cfg.Hide)
+	}
+	if got := strings.Join(cfg.Only, ","); got != "assistant,user" {
+		t.Errorf("Only = %v", cfg.Only)
+	}
+
+	cfg, err = Parse(
+		WithArgs([]string{"-mute-tool", "Read,Glob", "-focus-tool", "Edit"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cfg.MuteTools, ",") != "Read,Glob" || strings.Join(cfg.FocusTools, ",") != "Edit" {
+		t.Errorf("MuteTools = %v, FocusTools = %v", cfg.MuteTools, cfg.FocusTools)
+	}
+}
+
+func TestParse_RedactFlags(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-redact-pattern", `sk-[a-z0-9]+`, "-redact-pattern", `password=(\S+)`}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Redact || len(cfg.RedactPatterns) != 2 {
+		t.Errorf("Redact = %v, RedactPatterns = %v, want -redact-pattern to imply -redact", cfg.Redact, cfg.RedactPatterns)
+	}
+
+	if _, err := Parse(
+		WithArgs([]string{"-redact-pattern", "("}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestParse_NumberFormatFlags(t *testing.T) {
+	orig := numfmt.Default()
+	t.Cleanup(func() { numfmt.SetDefault(orig) })
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantTokens string
+		wantCost   string
+		wantError  bool
+	}{
+		{name: "locale from environment", args: []string{}, wantTokens: "1,5k", wantCost: "1,0000\u00a0$"},
+		{name: "explicit locale", args: []string{"-locale", "en_US"}, wantTokens: "1.5k", wantCost: "$1.0000"},
+		{name: "currency conversion", args: []string{"-locale", "en", "-currency", "eur", "-currency-rate", "0.5"}, wantTokens: "1.5k", wantCost: "€0.5000"},
+		{name: "spell out", args: []string{"-locale", "en", "-spell-out"}, wantTokens: "1,500", wantCost: "$1.0000"},
+		{name: "unknown locale rejected", args: []string{"-locale", "xx"}, wantError: true},
+		{name: "bad currency rejected", args: []string{"-currency", "dollars"}, wantError: true},
+		{name: "bad rate rejected", args: []string{"-currency-rate", "0"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
+			)
+
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v, got nil", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			f := numfmt.Default()
+			if got := f.Tokens(1500); got != tt.wantTokens {
+				t.Errorf("Tokens: got %q, want %q", got, tt.wantTokens)
+			}
+			if got := f.Cost(1, 4); got != tt.wantCost {
+				t.Errorf("Cost: got %q, want %q", got, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestParse_LogLevelFlag(t *testing.T) {
+	orig := diag.Default()
+	t.Cleanup(func() { diag.SetDefault(orig) })
+
+	tests := []struct {
+		name      string
+		args      []string
+		want      diag.Level
+		wantError bool
+	}{
+		{name: "default is warn", args: []string{}, want: diag.LevelWarn},
+		{name: "debug", args: []string{"-log-level", "debug"}, want: diag.LevelDebug},
+		{name: "off", args: []string{"--log-level=off"}, want: diag.LevelOff},
+		{name: "unknown rejected", args: []string{"-log-level", "loud"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
+			)
+
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v, got nil", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := diag.Default().Level(); got != tt.want {
+				t.Errorf("log level: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_DumpFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{
+			name:     "not set",
+			args:     []string{},
+			expected: false,
+		},
+		{
+			name:     "flag set",
+			args:     []string{"-dump"},
+			expected: true,
+		},
+		{
+			name:     "explicit true",
+			args:     []string{"-dump=true"},
+			expected: true,
+		},
+		{
+			name:     "explicit false",
+			args:     []string{"-dump=false"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockStyleInitializer{}
+			cfg, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(mock),
+			)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Dump != tt.expected {
+				t.Errorf("Dump: got %v, want %v", cfg.Dump, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse_AutoExitImpliesDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantDump bool
+	}{
+		{
+			name:     "auto-exit alone enables dump",
+			args:     []string{"-auto-exit"},
+			wantDump: true,
+		},
+		{
+			name:     "auto-exit with explicit dump=false",
+			args:     []string{"-auto-exit", "-dump=false"},
+			wantDump: false,
+		},
+		{
+			name:     "auto-exit with explicit dump=true",
+			args:     []string{"-auto-exit", "-dump=true"},
+			wantDump: true,
+		},
+		{
+			name:     "no auto-exit no dump",
+			args:     []string{},
+			wantDump: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockStyleInitializer{}
+			cfg, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(mock),
+			)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cfg.Dump != tt.wantDump {
+				t.Errorf("Dump: got %v, want %v", cfg.Dump, tt.wantDump)
+			}
+		})
+	}
+}
+
+func TestParse_FlagOrderIndependent(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "v before no-color",
+			args: []string{"-v", "-no-color"},
+		},
+		{
+			name: "no-color before v",
+			args: []string{"-no-color", "-v"},
+		},
+		{
+			name: "usage in middle",
+			args: []string{"-v", "-usage=false", "-no-color"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockStyleInitializer{}
+			cfg, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(mock),
+			)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !cfg.IsVerbose() {
+				t.Error("expected Verbose to be true")
+			}
+
+			if !cfg.DisableColor {
+				t.Error("expected DisableColor to be true")
+			}
+		})
+	}
+}
+
+func TestConfig_ImplementsProvider(t *testing.T) {
+	cfg := &Config{
+		VerboseLevel: 1,
+		DisableColor: true,
+		DisplayUsage: false,
+	}
+
+	// Verify Config implements Provider interface
+	var p Provider = cfg
+	if !p.IsVerbose() {
+		t.Error("expected IsVerbose() to be true")
+	}
+	if p.IsVeryVerbose() {
+		t.Error("expected IsVeryVerbose() to be false at level 1")
+	}
+	if !p.NoColor() {
+		t.Error("expected NoColor() to be true")
+	}
+	if p.ShowUsage() {
+		t.Error("expected ShowUsage() to be false")
+	}
+}
+
+func TestGet_ReturnsNonNil(t *testing.T) {
+	cfg := Get()
+	if cfg == nil {
+		t.Fatal("expected Get() to return non-nil config")
+	}
+}
+
+func TestParse_SetsGlobalConfig(t *testing.T) {
+	mock := &MockStyleInitializer{}
+	cfg, err := Parse(
+		WithArgs([]string{"-v"}),
+		WithStyleInitializer(mock),
+	)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := Get()
+	if got != cfg {
+		t.Error("expected Get() to return the config set by Parse()")
+	}
+	if !got.IsVerbose() {
+		t.Error("expected global config to have IsVerbose()=true")
+	}
+}
+
+func TestParse_ReplayCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantArgs  []string
+		wantSpeed float64
+		wantError bool
+	}{
+		{name: "file only", args: []string{"replay", "s.viewscreen"}, wantArgs: []string{"s.viewscreen"}, wantSpeed: 1},
+		{name: "flags before file", args: []string{"replay", "-speed", "4", "s.viewscreen"}, wantArgs: []string{"s.viewscreen"}, wantSpeed: 4},
+		{name: "flags after file", args: []string{"replay", "s.viewscreen", "--speed=0.5"}, wantArgs: []string{"s.viewscreen"}, wantSpeed: 0.5},
+		{name: "global flags before command", args: []string{"-speed", "2", "replay", "s.viewscreen"}, wantArgs: []string{"s.viewscreen"}, wantSpeed: 2},
+		{name: "missing file", args: []string{"replay"}, wantError: true},
+		{name: "too many files", args: []string{"replay", "a", "b"}, wantError: true},
+		{name: "negative speed", args: []string{"replay", "-speed", "-1", "s.viewscreen"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
+			)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Command != CommandReplay {
+				t.Errorf("Command = %q, want %q", cfg.Command, CommandReplay)
+			}
+			if strings.Join(cfg.CommandArgs, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("CommandArgs = %v, want %v", cfg.CommandArgs, tt.wantArgs)
+			}
+			if cfg.Speed != tt.wantSpeed {
+				t.Errorf("Speed = %v, want %v", cfg.Speed, tt.wantSpeed)
+			}
+			if cfg.Prompt != "" {
+				t.Errorf("Prompt = %q, want empty for a subcommand", cfg.Prompt)
+			}
+		})
+	}
+}
+
+func TestParse_UpdateCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"update", "--check-only"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandUpdate || !cfg.CheckOnly {
+		t.Errorf("Command = %q, CheckOnly = %v, want update with check-only", cfg.Command, cfg.CheckOnly)
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"update", "v2.0.0"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+		WithErrOutput(io.Discard),
+	)
+	if err == nil {
+		t.Error("expected error for positional args to update")
+	}
+}
+
+func TestParse_DoctorCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"doctor", "-annotations", "review.json"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandDoctor || cfg.AnnotationsPath != "review.json" {
+		t.Errorf("Command = %q, AnnotationsPath = %q", cfg.Command, cfg.AnnotationsPath)
+	}
+}
+
+func TestParse_BenchCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"bench", "-runs", "3", "session.jsonl"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandBench || cfg.Runs != 3 {
+		t.Errorf("Command = %q, Runs = %d", cfg.Command, cfg.Runs)
+	}
+	if len(cfg.CommandArgs) != 1 || cfg.CommandArgs[0] != "session.jsonl" {
+		t.Errorf("CommandArgs = %v", cfg.CommandArgs)
+	}
+
+	for _, args := range [][]string{{"bench"}, {"bench", "-runs", "0", "session.jsonl"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("expected usage error for %v", args)
+		}
+	}
+}
+
+func TestParse_StatsCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"stats", "-json", "session.jsonl"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandStats || !cfg.StatsJSON {
+		t.Errorf("Command = %q, StatsJSON = %v", cfg.Command, cfg.StatsJSON)
+	}
+	if len(cfg.CommandArgs) != 1 || cfg.CommandArgs[0] != "session.jsonl" {
+		t.Errorf("CommandArgs = %v", cfg.CommandArgs)
+	}
+
+	if _, err := Parse(WithArgs([]string{"stats"}), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+		t.Error("expected usage error without a file")
+	}
+}
+
+func TestParse_ReportCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"report", "digest", "-out", "digest.md"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandReport || cfg.ReportOut != "digest.md" {
+		t.Errorf("Command = %q, ReportOut = %q", cfg.Command, cfg.ReportOut)
+	}
+
+	for _, args := range [][]string{{"report"}, {"report", "monthly"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("Parse(%v): expected a usage error", args)
+		}
+	}
+}
+
+func TestParse_PromptNamingCommand(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-agent", "codex", "--", "report", "on", "the", "failing", "tests"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != "" || cfg.Prompt != "report on the failing tests" {
+		t.Errorf("Command = %q, Prompt = %q, want the prompt after --", cfg.Command, cfg.Prompt)
+	}
+}
+
+func TestParse_StrictFlags(t *testing.T) {
+	cfg, err := Parse(WithArgs([]string{"-strict", "-strict-log", "/tmp/strict.jsonl"}), WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Strict || cfg.StrictLogPath != "/tmp/strict.jsonl" {
+		t.Errorf("Strict = %v, StrictLogPath = %q", cfg.Strict, cfg.StrictLogPath)
+	}
+
+	if _, err := Parse(WithArgs([]string{"-strict-log", "/tmp/strict.jsonl"}), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+		t.Error("expected -strict-log without -strict to fail")
+	}
+}
+
+func TestParse_SessionsFlags(t *testing.T) {
+	cfg, err := Parse(WithStyleInitializer(&MockStyleInitializer{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AutoRecord || cfg.SessionsPolicy != sessions.DefaultPolicy {
+		t.Errorf("AutoRecord = %v, SessionsPolicy = %+v, want off and the default policy", cfg.AutoRecord, cfg.SessionsPolicy)
+	}
+
+	cfg, err = Parse(
+		WithArgs([]string{"sessions", "prune", "-sessions-dir", "/srv/rec", "-sessions-max-count", "5", "-sessions-max-age", "48h", "-sessions-max-mb", "10"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Command != CommandSessions || cfg.SessionsDir != "/srv/rec" {
+		t.Errorf("Command = %q, SessionsDir = %q", cfg.Command, cfg.SessionsDir)
+	}
+	if want := (sessions.Policy{MaxCount: 5, MaxAge: 48 * time.Hour, MaxSize: 10 << 20}); cfg.SessionsPolicy != want {
+		t.Errorf("SessionsPolicy = %+v, want %+v", cfg.SessionsPolicy, want)
+	}
+
+	for _, args := range [][]string{{"sessions"}, {"sessions", "list"}, {"-sessions-max-count", "-1"}, {"-sessions-max-mb", "lots"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{}), WithErrOutput(io.Discard)); err == nil {
+			t.Errorf("Parse(%v): expected an error", args)
+		}
+	}
+}
+
+func TestParse_RecordFlag(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-record", "out.viewscreen"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RecordPath != "out.viewscreen" {
+		t.Errorf("RecordPath = %q", cfg.RecordPath)
+	}
+	if cfg.Command != "" {
+		t.Errorf("Command = %q, want empty", cfg.Command)
+	}
+}
+
+func TestParse_ExportAndAnnotationsFlags(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-export", "session.html", "replay", "run.viewscreen", "-annotations", "review.json"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportPath != "session.html" {
+		t.Errorf("ExportPath = %q", cfg.ExportPath)
+	}
+	if cfg.AnnotationsPath != "review.json" {
+		t.Errorf("AnnotationsPath = %q", cfg.AnnotationsPath)
+	}
+	if cfg.Command != CommandReplay {
+		t.Errorf("Command = %q, want %q", cfg.Command, CommandReplay)
+	}
+}
+
+func TestParse_ExportSplit(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-export", "session.html", "-export-split", "50"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ExportSplit != 50 {
+		t.Errorf("ExportSplit = %d, want 50", cfg.ExportSplit)
+	}
+
+	_, err = Parse(
+		WithArgs([]string{"-export-split", "-1"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err == nil {
+		t.Error("expected an error for a negative -export-split")
+	}
+}
+
+func TestParse_ReplayStep(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"replay", "-step", "run.viewscreen"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Step {
+		t.Error("Step = false, want true")
+	}
+
+	for _, args := range [][]string{{"-step"}, {"replay", "-step", "-no-tui", "run.viewscreen"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(%v) should fail", args)
+		}
+	}
+}
+
+func TestParse_ReplaySmooth(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"replay", "-smooth", "-smooth-gap", "500ms", "run.viewscreen"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Smooth || cfg.SmoothGap != 500*time.Millisecond {
+		t.Errorf("Smooth = %v, SmoothGap = %v", cfg.Smooth, cfg.SmoothGap)
+	}
+
+	for _, args := range [][]string{{"-smooth"}, {"replay", "-smooth", "-smooth-gap", "0s", "run.viewscreen"}} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(%v) should fail", args)
+		}
+	}
+}
+
+func TestParse_Tee(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-tee", "raw.jsonl", "-tee-fd", "3"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TeePath != "raw.jsonl" || cfg.TeeFD != 3 {
+		t.Errorf("TeePath = %q, TeeFD = %d", cfg.TeePath, cfg.TeeFD)
+	}
+
+	for _, fd := range []string{"1", "-2"} {
+		if _, err := Parse(WithArgs([]string{"-tee-fd", fd}), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(-tee-fd %s) should fail", fd)
+		}
+	}
+}
+
+func TestParse_LowMemory(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-low-memory"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.LowMemory || !cfg.NoTUI {
+		t.Errorf("LowMemory = %v, NoTUI = %v, want both set", cfg.LowMemory, cfg.NoTUI)
+	}
+
+	for _, args := range [][]string{
+		{"-low-memory", "-export", "out.html"},
+		{"-low-memory", "-blame", "blame.txt"},
+		{"-low-memory", "-page-on-exit"},
+		{"-low-memory", "-resolve-titles"},
+		{"replay", "-low-memory", "-step", "run.viewscreen"},
+		{"-low-memory", "-serve", ":8080"},
+	} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(%v) should fail", args)
+		}
+	}
+}
+
+func TestParse_InputPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	other := filepath.Join(t.TempDir(), "README.md")
+	for _, p := range []string{path, other} {
+		if err := os.WriteFile(p, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantInput  string
+		wantPrompt string
+		wantFollow bool
+		wantError  bool
+	}{
+		{name: "existing file", args: []string{path}, wantInput: path},
+		{name: "stdin dash", args: []string{"-"}, wantInput: "-"},
+		{name: "follow file", args: []string{"-follow", path}, wantInput: path, wantFollow: true},
+		{name: "follow any file", args: []string{"-follow", other}, wantInput: other, wantFollow: true},
+		{name: "other file is a prompt", args: []string{other}, wantPrompt: other},
+		{name: "prompt mode wins", args: []string{"-p", path}, wantPrompt: path},
+		{name: "after -- is a prompt", args: []string{"--", path}, wantPrompt: path},
+		{name: "prompt text", args: []string{"explain", "this"}, wantPrompt: "explain this"},
+		{name: "file among words is a prompt", args: []string{"summarize", path}, wantPrompt: "summarize " + path},
+		{name: "follow without file", args: []string{"-follow"}, wantError: true},
+		{name: "follow stdin", args: []string{"-follow", "-"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse(
+				WithArgs(tt.args),
+				WithStyleInitializer(&MockStyleInitializer{}),
+				WithErrOutput(io.Discard),
+			)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("expected error for args %v", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.InputPath != tt.wantInput {
+				t.Errorf("InputPath = %q, want %q", cfg.InputPath, tt.wantInput)
+			}
+			if cfg.Prompt != tt.wantPrompt {
+				t.Errorf("Prompt = %q, want %q", cfg.Prompt, tt.wantPrompt)
+			}
+			if cfg.Follow != tt.wantFollow {
+				t.Errorf("Follow = %v, want %v", cfg.Follow, tt.wantFollow)
+			}
+		})
+	}
+}
+
+func TestParse_RecordKeys(t *testing.T) {
+	cfg, err := Parse(
+		WithArgs([]string{"-record-keys", "keys.jsonl"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.KeyLogPath != "keys.jsonl" {
+		t.Errorf("KeyLogPath = %q, want keys.jsonl", cfg.KeyLogPath)
+	}
+
+	for _, args := range [][]string{
+		{"-record-keys", "keys.jsonl", "-no-tui"},
+		{"-record-keys", "keys.jsonl", "-low-memory"},
+	} {
+		if _, err := Parse(WithArgs(args), WithStyleInitializer(&MockStyleInitializer{})); err == nil {
+			t.Errorf("Parse(%v) should fail", args)
+		}
 	}
 }
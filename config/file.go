@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// liveFlags are the -config file flags a running TUI applies when the file
+// changes. The others are read once, at startup.
+var liveFlags = map[string]bool{
+	"theme":      true,
+	"color":      true,
+	"v":          true,
+	"vv":         true,
+	"vvv":        true,
+	"only":       true,
+	"hide":       true,
+	"mute-tool":  true,
+	"focus-tool": true,
+}
+
+// fileFlag is one line of a -config file.
+type fileFlag struct {
+	line     int
+	name     string
+	value    string
+	hasValue bool
+}
+
+// readConfigFile reads the flags of the -config file at path: one per line,
+// as "-name value" or "-name=value", with blank lines and lines starting
+// with "#" skipped. A value runs to the end of its line, spaces included.
+func readConfigFile(path string) ([]fileFlag, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var flags []fileFlag
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "-") {
+			return nil, fmt.Errorf("%s:%d: want a flag, got %q", path, n, line)
+		}
+		line = strings.TrimLeft(line, "-")
+		ff := fileFlag{line: n, name: line}
+		if i := strings.IndexAny(line, "= \t"); i >= 0 {
+			ff.name, ff.value, ff.hasValue = line[:i], strings.TrimSpace(line[i+1:]), true
+		}
+		flags = append(flags, ff)
+	}
+	return flags, scanner.Err()
+}
+
+// applyConfigFile sets the flags of fs that the -config file at path names,
+// except those in skip: the flags given on the command line, which take
+// precedence.
+func applyConfigFile(fs *flag.FlagSet, path string, skip map[string]bool) error {
+	flags, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, ff := range flags {
+		f := fs.Lookup(ff.name)
+		switch {
+		case f == nil:
+			return fmt.Errorf("%s:%d: unknown flag -%s", path, ff.line, ff.name)
+		case ff.name == "config":
+			return fmt.Errorf("%s:%d: -config cannot be set in a config file", path, ff.line)
+		case skip[ff.name]:
+			continue
+		}
+		value := ff.value
+		if !ff.hasValue {
+			if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				return fmt.Errorf("%s:%d: flag -%s needs a value", path, ff.line, ff.name)
+			}
+			value = "true"
+		}
+		if err := fs.Set(ff.name, value); err != nil {
+			return fmt.Errorf("%s:%d: -%s: %w", path, ff.line, ff.name, err)
+		}
+	}
+	return nil
+}
+
+// setFlags returns the names of the flags of fs that have been set. -v, -vv
+// and -vvv make up one setting, so setting any of them counts as all three.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["v"] || set["vv"] || set["vvv"] {
+		set["v"], set["vv"], set["vvv"] = true, true, true
+	}
+	return set
+}
+
+// Live holds the settings of a -config file that a running TUI applies when
+// the file changes: its colors, verbosity (which sets how much of each tool
+// result is shown) and filters. Themes are built in, chosen with -theme, so
+// there are no theme files to watch besides the -config file.
+type Live struct {
+	Theme          style.Theme
+	ColorOverrides map[string]style.Color
+	VerboseLevel   int
+	// Only, Hide, MuteTools and FocusTools are as in Config.
+	Only       []string
+	Hide       []string
+	MuteTools  []string
+	FocusTools []string
+}
+
+// ReloadLive reads the Live settings of the -config file again. Those given
+// on the command line keep their command-line values, and the file's other
+// flags are ignored until the next start.
+func (c *Config) ReloadLive() (Live, error) {
+	if c.ConfigPath == "" {
+		return Live{}, errors.New("no -config file")
+	}
+	themeName := "default"
+	var live Live
+	if c.cmdlineFlags["theme"] {
+		themeName = c.Theme
+	}
+	if c.cmdlineFlags["color"] {
+		live.ColorOverrides = c.ColorOverrides
+	}
+	if c.cmdlineFlags["only"] {
+		live.Only = c.Only
+	}
+	if c.cmdlineFlags["hide"] {
+		live.Hide = c.Hide
+	}
+	if c.cmdlineFlags["mute-tool"] {
+		live.MuteTools = c.MuteTools
+	}
+	if c.cmdlineFlags["focus-tool"] {
+		live.FocusTools = c.FocusTools
+	}
+	var verbose int
+	var veryVerbose, maxVerbose bool
+	verbosity := flag.NewFlagSet("verbosity", flag.ContinueOnError)
+	verbosity.Var(verbosityFlag{&verbose}, "v", "")
+	verbosity.BoolVar(&veryVerbose, "vv", false, "")
+	verbosity.BoolVar(&maxVerbose, "vvv", false, "")
+
+	flags, err := readConfigFile(c.ConfigPath)
+	if err != nil {
+		return Live{}, err
+	}
+	for _, ff := range flags {
+		if !liveFlags[ff.name] || c.cmdlineFlags[ff.name] {
+			continue
+		}
+		if verbosity.Lookup(ff.name) != nil {
+			value := ff.value
+			if !ff.hasValue {
+				value = "true"
+			}
+			if err := verbosity.Set(ff.name, value); err != nil {
+				return Live{}, fmt.Errorf("%s:%d: -%s: %w", c.ConfigPath, ff.line, ff.name, err)
+			}
+			continue
+		}
+		if !ff.hasValue {
+			return Live{}, fmt.Errorf("%s:%d: flag -%s needs a value", c.ConfigPath, ff.line, ff.name)
+		}
+		switch ff.name {
+		case "theme":
+			themeName = ff.value
+		case "color":
+			role, color, err := style.ParseColorOverride(ff.value)
+			if err != nil {
+				return Live{}, fmt.Errorf("%s:%d: -color: %w", c.ConfigPath, ff.line, err)
+			}
+			if live.ColorOverrides == nil {
+				live.ColorOverrides = map[string]style.Color{}
+			}
+			live.ColorOverrides[role] = color
+		case "only":
+			live.Only = append(live.Only, splitList(ff.value)...)
+		case "hide":
+			live.Hide = append(live.Hide, splitList(ff.value)...)
+		case "mute-tool":
+			live.MuteTools = append(live.MuteTools, splitList(ff.value)...)
+		case "focus-tool":
+			live.FocusTools = append(live.FocusTools, splitList(ff.value)...)
+		}
+	}
+	if live.Theme, err = style.LookupTheme(themeName); err != nil {
+		return Live{}, fmt.Errorf("%s: %w", c.ConfigPath, err)
+	}
+	live.VerboseLevel = verbosityLevel(verbose, veryVerbose, maxVerbose)
+	if c.cmdlineFlags["v"] {
+		live.VerboseLevel = c.VerboseLevel
+	}
+	return live, nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// writeConfigFile writes content to a -config file in a temporary directory.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "viewscreen.conf")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParse_ConfigFile(t *testing.T) {
+	defer style.SetBaseTheme(style.DefaultTheme)
+	path := writeConfigFile(t, `
+# comments and blank lines are skipped
+-no-tui
+-theme high-contrast
+-only=assistant,result
+-color success=#00aa55
+-hide thinking
+`)
+	cfg, err := Parse(
+		WithArgs([]string{"-config", path, "-hide", "tool_result"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.NoTUI || cfg.Theme != "high-contrast" || cfg.ColorOverrides["success"] != "#00aa55" {
+		t.Errorf("file flags not applied: NoTUI %v, Theme %q, colors %v", cfg.NoTUI, cfg.Theme, cfg.ColorOverrides)
+	}
+	if !slices.Equal(cfg.Only, []string{"assistant", "result"}) {
+		t.Errorf("Only = %v", cfg.Only)
+	}
+	if !slices.Equal(cfg.Hide, []string{"tool_result"}) {
+		t.Errorf("Hide = %v, want the command line's value only", cfg.Hide)
+	}
+}
+
+func TestParse_ConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"-no-such-flag\n", ":1: unknown flag -no-such-flag"},
+		{"\n-theme\n", ":2: flag -theme needs a value"},
+		{"assistant\n", "want a flag"},
+		{"-config other.conf\n", "cannot be set in a config file"},
+		{"-budget lots\n", ":1: -budget:"},
+	}
+	for _, tt := range tests {
+		path := writeConfigFile(t, tt.content)
+		_, err := Parse(
+			WithArgs([]string{"-config", path}),
+			WithStyleInitializer(&MockStyleInitializer{}),
+			WithErrOutput(io.Discard),
+		)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error = %v, want %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+func TestConfig_ReloadLive_CommandLineVerbosity(t *testing.T) {
+	path := writeConfigFile(t, "-vvv\n")
+	cfg, err := Parse(
+		WithArgs([]string{"-config", path, "-v"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	live, err := cfg.ReloadLive()
+	if err != nil {
+		t.Fatalf("ReloadLive() error = %v", err)
+	}
+	if live.VerboseLevel != 1 {
+		t.Errorf("VerboseLevel = %d, want the command line's 1", live.VerboseLevel)
+	}
+}
+
+func TestConfig_ReloadLive(t *testing.T) {
+	defer style.SetBaseTheme(style.DefaultTheme)
+	path := writeConfigFile(t, "-only assistant\n-width 100\n")
+	cfg, err := Parse(
+		WithArgs([]string{"-config", path, "-mute-tool", "Read"}),
+		WithStyleInitializer(&MockStyleInitializer{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("-theme high-contrast\n-color error=#ff0000\n-hide thinking\n-mute-tool Glob\n-width 60\n-vv\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	live, err := cfg.ReloadLive()
+	if err != nil {
+		t.Fatalf("ReloadLive() error = %v", err)
+	}
+	if live.Theme != style.HighContrastTheme || live.ColorOverrides["error"] != "#ff0000" {
+		t.Errorf("colors not reloaded: %+v", live)
+	}
+	if live.Only != nil || !slices.Equal(live.Hide, []string{"thinking"}) {
+		t.Errorf("filters not reloaded: Only %v, Hide %v", live.Only, live.Hide)
+	}
+	if !slices.Equal(live.MuteTools, []string{"Read"}) {
+		t.Errorf("MuteTools = %v, want the command line's value", live.MuteTools)
+	}
+	if live.VerboseLevel != 2 {
+		t.Errorf("VerboseLevel = %d, want 2", live.VerboseLevel)
+	}
+
+	if err := os.WriteFile(path, []byte("-v=9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.ReloadLive(); err == nil || !strings.Contains(err.Error(), ":1: -v:") {
+		t.Errorf("ReloadLive() error = %v, want a bad level error", err)
+	}
+
+	if err := os.WriteFile(path, []byte("-theme sepia\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.ReloadLive(); err == nil || !strings.Contains(err.Error(), "unknown theme") {
+		t.Errorf("ReloadLive() error = %v, want an unknown theme error", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package ctxwindow measures how full a model's context window is, so the
+// TUI and session summary can warn before the agent compacts its
+// conversation. The tokens in use are those of the latest request: its
+// input, cache writes and reads, and output.
+package ctxwindow
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+)
+
+const (
+	// DefaultWindow is the context window of Claude models, used until a
+	// result event reports the session's.
+	DefaultWindow = 200_000
+	// ExtendedWindow is the window of models run with the 1M context
+	// option, whose names end in "[1m]".
+	ExtendedWindow = 1_000_000
+)
+
+// Level is how full a context window is, by the configured thresholds.
+type Level int
+
+const (
+	Normal Level = iota
+	Warning
+	Critical
+)
+
+// Thresholds are the percentages of the window at which usage is a
+// Warning and Critical.
+type Thresholds struct {
+	Warn, Critical int
+}
+
+// DefaultThresholds warn at 70% and are critical at 90%, when compaction is
+// near.
+var DefaultThresholds = Thresholds{Warn: 70, Critical: 90}
+
+// Validate checks that the thresholds are percentages with Warn below
+// Critical.
+func (t Thresholds) Validate() error {
+	if t.Warn < 1 || t.Critical > 100 || t.Warn >= t.Critical {
+		return fmt.Errorf("context thresholds must satisfy 0 < warn < critical <= 100, got %d and %d", t.Warn, t.Critical)
+	}
+	return nil
+}
+
+// Level returns the level of a usage percentage.
+func (t Thresholds) Level(percent int) Level {
+	switch {
+	case percent >= t.Critical:
+		return Critical
+	case percent >= t.Warn:
+		return Warning
+	}
+	return Normal
+}
+
+var (
+	mu       sync.RWMutex
+	defaults = DefaultThresholds
+)
+
+// Default returns the process-wide thresholds configured from flags.
+func Default() Thresholds {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaults
+}
+
+// SetDefault replaces the process-wide thresholds.
+func SetDefault(t Thresholds) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaults = t
+}
+
+// WindowFor returns the context window of a Claude model by name.
+func WindowFor(model string) int {
+	if strings.HasSuffix(strings.ToLower(model), "[1m]") {
+		return ExtendedWindow
+	}
+	return DefaultWindow
+}
+
+// Percent returns the share of window that used tokens fill, rounded down
+// and capped at 100.
+func Percent(used, window int) int {
+	if window <= 0 {
+		return 0
+	}
+	return min(used*100/window, 100)
+}
+
+// Format describes usage compactly, e.g. "62% of 200.0k".
+func Format(used, window int) string {
+	return fmt.Sprintf("%d%% of %s", Percent(used, window), numfmt.Default().Tokens(window))
+}
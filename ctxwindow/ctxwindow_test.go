@@ -0,0 +1,55 @@
+package ctxwindow
+
+import "testing"
+
+func TestWindowFor(t *testing.T) {
+	for model, want := range map[string]int{
+		"claude-sonnet-4-5":     DefaultWindow,
+		"claude-sonnet-4-5[1m]": ExtendedWindow,
+		"":                      DefaultWindow,
+	} {
+		if got := WindowFor(model); got != want {
+			t.Errorf("WindowFor(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		used, window, want int
+	}{
+		{124_000, 200_000, 62},
+		{0, 200_000, 0},
+		{250_000, 200_000, 100},
+		{10, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := Percent(tt.used, tt.window); got != tt.want {
+			t.Errorf("Percent(%d, %d) = %d, want %d", tt.used, tt.window, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got, want := Format(124_000, 200_000), "62% of 200.0k"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	th := Thresholds{Warn: 70, Critical: 90}
+	for percent, want := range map[int]Level{0: Normal, 69: Normal, 70: Warning, 89: Warning, 90: Critical, 100: Critical} {
+		if got := th.Level(percent); got != want {
+			t.Errorf("Level(%d) = %d, want %d", percent, got, want)
+		}
+	}
+
+	if err := th.Validate(); err != nil {
+		t.Errorf("Validate(%v): %v", th, err)
+	}
+	for _, bad := range []Thresholds{{0, 90}, {90, 90}, {80, 70}, {50, 101}} {
+		if bad.Validate() == nil {
+			t.Errorf("Validate(%v): expected an error", bad)
+		}
+	}
+}
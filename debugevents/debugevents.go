@@ -0,0 +1,187 @@
+// Package debugevents writes the -debug-events log: a JSON line per input
+// line recording how it was classified, which renderer handled it, what it
+// rendered and any error parsing it, so "why didn't this render" can be
+// answered from the log instead of a rebuild with print statements.
+package debugevents
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Classes of input line a Record reports, besides the event types named by
+// events.TypeName.
+const (
+	ClassSubagentSystem = "subagent_system"
+	ClassIgnored        = "ignored"
+	ClassParseError     = "parse_error"
+	// ClassNoise is a line that is not JSON, passed through by -pass-noise.
+	ClassNoise = "noise"
+	// ClassFiltered is a line dropped by -only/-hide before it was parsed.
+	ClassFiltered = "filtered"
+)
+
+// Renderers a Record reports.
+const (
+	// RendererBuiltin is the events.EventProcessor.
+	RendererBuiltin = "builtin"
+	// RendererRegistered is a renderer registered for the event's type with
+	// parser.RegisterRenderer or parser.WithRenderer.
+	RendererRegistered = "registered"
+)
+
+// Record is the log line of one input line.
+type Record struct {
+	// Line counts the input lines logged, from 1; blank lines are not
+	// counted.
+	Line int `json:"line"`
+	// Type is the line's "type" field.
+	Type  string `json:"type,omitempty"`
+	Class string `json:"class"`
+	// Renderer is what rendered the event, "" when nothing did.
+	Renderer string `json:"renderer,omitempty"`
+	// Entries are the timeline entries the event rendered; none means it
+	// rendered nothing.
+	Entries []Entry `json:"entries,omitempty"`
+	Error   string  `json:"error,omitempty"`
+	// Event is the line as received, or Raw when it is not valid JSON.
+	Event json.RawMessage `json:"event,omitempty"`
+	Raw   string          `json:"raw,omitempty"`
+}
+
+// Entry summarizes a rendered timeline entry.
+type Entry struct {
+	Kind   string `json:"kind"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status,omitempty"`
+	// Bytes is the length of the entry's rendered text.
+	Bytes int `json:"bytes"`
+}
+
+// Log writes Records as JSON lines. A nil *Log writes nothing, so callers
+// need not check whether -debug-events is set.
+type Log struct {
+	mu     sync.Mutex
+	closer io.Closer
+	enc    *json.Encoder
+	line   int
+	err    error
+}
+
+// New creates a Log writing to w.
+func New(w io.Writer) *Log {
+	return &Log{enc: json.NewEncoder(w)}
+}
+
+// Create creates a Log writing to the file at path, or returns nil when
+// path is "".
+func Create(path string) (*Log, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	l := New(f)
+	l.closer = f
+	return l, nil
+}
+
+// Filtered logs the next input line as dropped by the filter.
+func (l *Log) Filtered(line string) {
+	l.write(line, Record{Class: ClassFiltered})
+}
+
+// Registered logs the next input line as handed, unparsed, to the renderer
+// registered for its type, with the error it returned. Its class is its
+// type.
+func (l *Log) Registered(line string, err error) {
+	rec := Record{Renderer: RendererRegistered}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	l.write(line, rec)
+}
+
+// Event logs the next input line, parsed as event, with the entries the
+// builtin renderer made of it. A nil event is a line that was not rendered.
+func (l *Log) Event(line string, event events.Event, entries []timeline.Entry) {
+	rec := Record{Class: Class(event), Renderer: RendererBuiltin}
+	switch e := event.(type) {
+	case events.ParseError:
+		rec.Renderer = ""
+		if e.Err != nil {
+			rec.Error = e.Err.Error()
+		} else {
+			rec.Error = e.Line
+		}
+	case events.IgnoredEvent:
+		rec.Renderer = ""
+	}
+	for _, entry := range entries {
+		rec.Entries = append(rec.Entries, Entry{Kind: entry.Kind, Title: entry.Title, Status: entry.Status, Bytes: len(entry.Text())})
+	}
+	l.write(line, rec)
+}
+
+// Class names how the parser classified event: its events.TypeName, or one
+// of the Class constants for events that have none.
+func Class(event events.Event) string {
+	switch event.(type) {
+	case events.SubAgentSystemEvent:
+		return ClassSubagentSystem
+	case events.IgnoredEvent:
+		return ClassIgnored
+	case events.ParseError:
+		return ClassParseError
+	case events.NoiseEvent:
+		return ClassNoise
+	}
+	return events.TypeName(event)
+}
+
+func (l *Log) write(line string, rec Record) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.line++
+	rec.Line = l.line
+	var base struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal([]byte(line), &base) == nil {
+		rec.Type = base.Type
+		rec.Event = json.RawMessage(line)
+	} else {
+		rec.Raw = line
+	}
+	if rec.Class == "" {
+		rec.Class = rec.Type
+	}
+	if err := l.enc.Encode(rec); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// Close closes the log's file, returning the first error writing it.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var closeErr error
+	if l.closer != nil {
+		closeErr = l.closer.Close()
+	}
+	return errors.Join(l.err, closeErr)
+}
@@ -0,0 +1,87 @@
+package debugevents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func decode(t *testing.T, data []byte) []Record {
+	t.Helper()
+	var recs []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf)
+
+	assistant := `{"type":"assistant","message":{"content":[]}}`
+	l.Event(assistant, events.Parse(assistant), []timeline.Entry{{Kind: "assistant", Body: "hi\n"}})
+	l.Event("not json", events.Parse("not json"), nil)
+	l.Event(`{"type":"hologram"}`, events.Parse(`{"type":"hologram"}`), nil)
+	l.Event(`{"type":"rate_limit_event"}`, events.Parse(`{"type":"rate_limit_event"}`), nil)
+	l.Filtered(`{"type":"user"}`)
+	l.Registered(`{"type":"custom"}`, errors.New("boom"))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	recs := decode(t, buf.Bytes())
+	if len(recs) != 6 {
+		t.Fatalf("got %d records, want 6", len(recs))
+	}
+	want := []struct {
+		class, renderer, err string
+	}{
+		{"assistant", RendererBuiltin, ""},
+		{ClassParseError, "", "invalid character 'o' in literal null (expecting 'u')"},
+		{ClassParseError, "", "Unknown event type: hologram"},
+		{ClassIgnored, "", ""},
+		{ClassFiltered, "", ""},
+		{"custom", RendererRegistered, "boom"},
+	}
+	for i, w := range want {
+		rec := recs[i]
+		if rec.Line != i+1 || rec.Class != w.class || rec.Renderer != w.renderer || rec.Error != w.err {
+			t.Errorf("record %d = %+v, want line %d class %q renderer %q error %q", i, rec, i+1, w.class, w.renderer, w.err)
+		}
+	}
+	if e := recs[0].Entries; len(e) != 1 || e[0].Kind != "assistant" || e[0].Bytes != 3 {
+		t.Errorf("entries = %+v, want one 3-byte assistant entry", e)
+	}
+	if recs[0].Type != "assistant" || string(recs[0].Event) != assistant {
+		t.Errorf("record 0 type %q event %s, want the assistant line", recs[0].Type, recs[0].Event)
+	}
+	if recs[1].Raw != "not json" || recs[1].Event != nil {
+		t.Errorf("record 1 = %+v, want the line kept raw", recs[1])
+	}
+}
+
+func TestCreate(t *testing.T) {
+	if l, err := Create(""); l != nil || err != nil {
+		t.Errorf("Create(\"\") = %v, %v; want nil, nil", l, err)
+	}
+	var nilLog *Log
+	nilLog.Filtered("{}")
+	if err := nilLog.Close(); err != nil {
+		t.Errorf("nil Close() = %v", err)
+	}
+
+	if _, err := Create(filepath.Join(t.TempDir(), "missing", "events.jsonl")); err == nil {
+		t.Error("expected an error creating a file in a missing directory")
+	}
+}
@@ -0,0 +1,154 @@
+// Package diag reports viewscreen's own diagnostics (malformed input, unknown
+// events, skipped lines) on stderr, separate from the agent output it
+// renders. Messages are tagged with a severity and filtered by -log-level,
+// and repeats of the same message are collapsed so a noisy stream cannot
+// flood the terminal.
+package diag
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of a diagnostic.
+type Level int
+
+// Severity levels, from most to least verbose. LevelOff silences everything.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelOff
+)
+
+var levelNames = [...]string{"debug", "info", "warn", "error", "off"}
+
+// String returns the level name used by -log-level.
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelOff {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a -log-level value. "warning" is accepted for "warn".
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "warning" {
+		name = "warn"
+	}
+	for i, n := range levelNames {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (want debug, info, warn, error or off)", s)
+}
+
+// RepeatLimit is how many times the same message is printed before further
+// occurrences are counted instead.
+const RepeatLimit = 3
+
+// Logger writes leveled diagnostics to a writer.
+type Logger struct {
+	mu         sync.Mutex
+	out        io.Writer
+	level      Level
+	repeats    map[string]int
+	suppressed int
+	// worst is the highest severity among suppressed messages.
+	worst Level
+}
+
+// New creates a Logger that writes messages at or above level to w.
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{out: w, level: level, repeats: make(map[string]int)}
+}
+
+// WithOutput returns a new Logger at the same level writing to w.
+func (l *Logger) WithOutput(w io.Writer) *Logger {
+	return New(w, l.Level())
+}
+
+// Level returns the minimum severity the Logger prints.
+func (l *Logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// Enabled reports whether messages at level are printed.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level() && level < LevelOff
+}
+
+// Debugf logs a debug message.
+func (l *Logger) Debugf(format string, args ...any) { l.logf(LevelDebug, format, args...) }
+
+// Infof logs an informational message.
+func (l *Logger) Infof(format string, args ...any) { l.logf(LevelInfo, format, args...) }
+
+// Warnf logs a warning.
+func (l *Logger) Warnf(format string, args ...any) { l.logf(LevelWarn, format, args...) }
+
+// Errorf logs an error.
+func (l *Logger) Errorf(format string, args ...any) { l.logf(LevelError, format, args...) }
+
+// logf prints a message unless it is below the level or the same message
+// has already been printed RepeatLimit times.
+func (l *Logger) logf(level Level, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level || l.level == LevelOff {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	l.repeats[msg]++
+	switch n := l.repeats[msg]; {
+	case n > RepeatLimit:
+		l.suppressed++
+		l.worst = max(l.worst, level)
+		return
+	case n == RepeatLimit:
+		fmt.Fprintf(l.out, "%s: %s (further repeats suppressed)\n", level, msg)
+	default:
+		fmt.Fprintf(l.out, "%s: %s\n", level, msg)
+	}
+}
+
+// Flush reports how many repeated messages were suppressed, if any, and
+// resets the repeat counts.
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.suppressed > 0 {
+		fmt.Fprintf(l.out, "%s: suppressed %d repeated diagnostics\n", l.worst, l.suppressed)
+	}
+	l.suppressed = 0
+	l.worst = LevelDebug
+	l.repeats = make(map[string]int)
+}
+
+var (
+	mu         sync.RWMutex
+	defaultLog = New(os.Stderr, LevelWarn)
+)
+
+// Default returns the process-wide Logger configured from -log-level.
+func Default() *Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultLog
+}
+
+// SetDefault replaces the process-wide Logger.
+func SetDefault(l *Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultLog = l
+}
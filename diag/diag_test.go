@@ -0,0 +1,116 @@
+package diag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+	}{
+		{"debug", LevelDebug},
+		{"INFO", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{"error", LevelError},
+		{"off", LevelOff},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestLogger_FiltersByLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelWarn)
+
+	l.Debugf("debug %d", 1)
+	l.Infof("info %d", 2)
+	l.Warnf("warn %d", 3)
+	l.Errorf("error %d", 4)
+
+	want := "warn: warn 3\nerror: error 4\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if l.Enabled(LevelInfo) || !l.Enabled(LevelError) {
+		t.Error("Enabled disagrees with level")
+	}
+}
+
+func TestLogger_Off(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelOff)
+	l.Errorf("nope")
+	l.Flush()
+	if buf.Len() != 0 {
+		t.Errorf("expected no output at off, got %q", buf.String())
+	}
+	if l.Enabled(LevelOff) {
+		t.Error("expected LevelOff never to be enabled")
+	}
+}
+
+func TestLogger_SuppressesRepeats(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelDebug)
+
+	for i := 0; i < 10; i++ {
+		l.Warnf("bad line %d", 7)
+	}
+	l.Errorf("different")
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != RepeatLimit+1 {
+		t.Fatalf("expected %d lines, got %q", RepeatLimit+1, buf.String())
+	}
+	if !strings.HasSuffix(lines[RepeatLimit-1], "(further repeats suppressed)") {
+		t.Errorf("expected suppression notice on last repeat, got %q", lines[RepeatLimit-1])
+	}
+
+	buf.Reset()
+	l.Flush()
+	if got := buf.String(); got != "warn: suppressed 7 repeated diagnostics\n" {
+		t.Errorf("Flush() wrote %q", got)
+	}
+
+	buf.Reset()
+	l.Warnf("bad line %d", 7)
+	if buf.Len() == 0 {
+		t.Error("expected Flush to reset repeat counts")
+	}
+}
+
+func TestLogger_DistinctMessagesSharingAFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelDebug)
+
+	for i := 0; i < 10; i++ {
+		l.Warnf("bad line %d", i)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 10 {
+		t.Errorf("expected all 10 distinct messages, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "suppressed") {
+		t.Errorf("expected no suppression of distinct messages, got %q", buf.String())
+	}
+}
+
+func TestLogger_WithOutput(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&bytes.Buffer{}, LevelError).WithOutput(&buf)
+	l.Warnf("hidden")
+	l.Errorf("shown")
+	if buf.String() != "error: shown\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
@@ -0,0 +1,417 @@
+// Package doctor implements "viewscreen doctor": a set of environment checks
+// that explain why output might look wrong (no colors, mojibake, plain-text
+// links) and what to change to fix it.
+//
+// Terminal features cannot be queried reliably without taking over the
+// terminal, so detection is based on the variables terminals and
+// multiplexers export (COLORTERM, TERM, TERM_PROGRAM, KITTY_WINDOW_ID, TMUX,
+// ...). Results for unrecognized terminals are reported as unknown rather
+// than unsupported.
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+)
+
+// Status is the outcome of a check.
+type Status int
+
+// Check outcomes, from best to worst.
+const (
+	StatusOK Status = iota
+	StatusWarn
+	StatusFail
+)
+
+// String returns the marker printed before a check.
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusWarn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+// Check is the result of one diagnostic. Fix is empty when nothing needs to
+// change.
+type Check struct {
+	Name   string
+	Status Status
+	Detail string
+	Fix    string
+}
+
+// Doctor runs environment checks.
+type Doctor struct {
+	getenv          func(string) string
+	lookPath        func(string) (string, error)
+	stat            func(string) (os.FileInfo, error)
+	isTerminal      bool
+	agent           string
+	annotationsPath string
+	outputPaths     []string
+}
+
+// Option is a functional option for configuring a Doctor.
+type Option func(*Doctor)
+
+// WithEnv sets the environment lookup (default: os.Getenv).
+func WithEnv(getenv func(string) string) Option {
+	return func(d *Doctor) {
+		d.getenv = getenv
+	}
+}
+
+// WithLookPath sets the executable lookup (default: exec.LookPath).
+func WithLookPath(lookPath func(string) (string, error)) Option {
+	return func(d *Doctor) {
+		d.lookPath = lookPath
+	}
+}
+
+// WithTerminal sets whether stdout is a terminal.
+func WithTerminal(isTerminal bool) Option {
+	return func(d *Doctor) {
+		d.isTerminal = isTerminal
+	}
+}
+
+// WithAgent sets the agent CLI prompt mode would spawn.
+func WithAgent(name string) Option {
+	return func(d *Doctor) {
+		d.agent = name
+	}
+}
+
+// WithAnnotations sets the -annotations file to validate.
+func WithAnnotations(path string) Option {
+	return func(d *Doctor) {
+		d.annotationsPath = path
+	}
+}
+
+// WithOutputPaths sets files viewscreen will write (-record, -export) so
+// their directories can be checked.
+func WithOutputPaths(paths ...string) Option {
+	return func(d *Doctor) {
+		d.outputPaths = paths
+	}
+}
+
+// New creates a Doctor with the given options.
+func New(opts ...Option) *Doctor {
+	d := &Doctor{
+		getenv:   os.Getenv,
+		lookPath: exec.LookPath,
+		stat:     os.Stat,
+		agent:    "claude",
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Checks runs every check in display order.
+func (d *Doctor) Checks() []Check {
+	checks := []Check{
+		d.checkTerminal(),
+		d.checkColor(),
+		d.checkHyperlinks(),
+		d.checkClipboard(),
+		d.checkImages(),
+		d.checkUnicode(),
+		d.checkNumberLocale(),
+		d.checkAgent(),
+	}
+	return append(checks, d.checkFiles()...)
+}
+
+// Report writes checks to w, one per line with its fix indented beneath, and
+// returns the worst status.
+func Report(w io.Writer, checks []Check) Status {
+	worst := StatusOK
+	for _, c := range checks {
+		fmt.Fprintf(w, "%-4s  %-12s %s\n", c.Status, c.Name, c.Detail)
+		if c.Fix != "" {
+			fmt.Fprintf(w, "      %-12s fix: %s\n", "", c.Fix)
+		}
+		worst = max(worst, c.Status)
+	}
+	return worst
+}
+
+func (d *Doctor) checkTerminal() Check {
+	c := Check{Name: "terminal"}
+	if !d.isTerminal {
+		c.Status = StatusWarn
+		c.Detail = "stdout is not a terminal; the TUI is disabled and output streams as plain lines"
+		c.Fix = "run viewscreen directly in a terminal, or pass -no-tui to silence this"
+		return c
+	}
+	switch term := d.getenv("TERM"); term {
+	case "":
+		c.Status = StatusWarn
+		c.Detail = "TERM is not set"
+		c.Fix = "export TERM=xterm-256color (or your terminal's own value)"
+	case "dumb":
+		c.Status = StatusFail
+		c.Detail = "TERM=dumb; the terminal cannot draw the TUI"
+		c.Fix = "use a full terminal emulator, or pass -no-tui -no-color"
+	default:
+		c.Detail = "TERM=" + term
+		if prog := d.terminalProgram(); prog != "" {
+			c.Detail += ", " + prog
+		}
+		if d.inTmux() {
+			c.Detail += " (inside tmux)"
+		}
+	}
+	return c
+}
+
+func (d *Doctor) checkColor() Check {
+	c := Check{Name: "color"}
+	if d.getenv("NO_COLOR") != "" {
+		c.Status = StatusWarn
+		c.Detail = "NO_COLOR is set; output is uncolored"
+		c.Fix = "unset NO_COLOR"
+		return c
+	}
+	colorterm := strings.ToLower(d.getenv("COLORTERM"))
+	term := d.getenv("TERM")
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit":
+		c.Detail = "truecolor (COLORTERM=" + colorterm + ")"
+	case d.knownTruecolor():
+		c.Detail = "truecolor (" + d.terminalProgram() + ")"
+	case strings.Contains(term, "256color"):
+		c.Status = StatusWarn
+		c.Detail = "256 colors; theme gradients are approximated"
+		c.Fix = "export COLORTERM=truecolor if your terminal supports 24-bit color"
+		if d.inTmux() {
+			c.Fix += `; in tmux also add: set -as terminal-features ",*:RGB"`
+		}
+	default:
+		c.Status = StatusWarn
+		c.Detail = "basic colors only; theme colors look washed out"
+		c.Fix = "export TERM=xterm-256color COLORTERM=truecolor if your terminal supports it"
+	}
+	return c
+}
+
+func (d *Doctor) checkHyperlinks() Check {
+	c := Check{Name: "hyperlinks"}
+	if d.supportsOSC8() {
+		c.Detail = "OSC 8 links supported"
+		if d.inTmux() {
+			c.Detail += "; tmux 3.4+ needed to pass them through"
+		}
+		return c
+	}
+	c.Status = StatusWarn
+	c.Detail = "OSC 8 support unknown; file paths may not be clickable"
+	c.Fix = "use a terminal with hyperlink support (kitty, iTerm2, WezTerm, Windows Terminal, GNOME Terminal)"
+	return c
+}
+
+func (d *Doctor) checkClipboard() Check {
+	c := Check{Name: "clipboard"}
+	var found []string
+	if d.supportsOSC52() {
+		found = append(found, "OSC 52")
+	}
+	for _, tool := range []string{"pbcopy", "wl-copy", "xclip", "xsel", "clip.exe"} {
+		if _, err := d.lookPath(tool); err == nil {
+			found = append(found, tool)
+		}
+	}
+	if len(found) > 0 {
+		c.Detail = strings.Join(found, ", ")
+		if d.inTmux() {
+			c.Detail += "; tmux needs \"set -g set-clipboard on\" for OSC 52"
+		}
+		return c
+	}
+	c.Status = StatusWarn
+	c.Detail = "no clipboard tool or OSC 52 terminal detected"
+	c.Fix = "install wl-clipboard (Wayland) or xclip (X11)"
+	return c
+}
+
+func (d *Doctor) checkImages() Check {
+	c := Check{Name: "images"}
+	switch {
+	case d.isKitty() || d.termProgram() == "ghostty":
+		c.Detail = "kitty graphics protocol"
+	case d.termProgram() == "iTerm.app" || d.termProgram() == "WezTerm":
+		c.Detail = "iTerm2 inline images"
+	default:
+		c.Detail = "no image protocol detected; image results are shown as text"
+	}
+	return c
+}
+
+func (d *Doctor) checkUnicode() Check {
+	c := Check{Name: "unicode"}
+	tag := d.firstEnv("LC_ALL", "LC_CTYPE", "LANG")
+	charset := ""
+	if _, after, ok := strings.Cut(tag, "."); ok {
+		charset, _, _ = strings.Cut(after, "@")
+	}
+	switch norm := strings.ToLower(strings.ReplaceAll(charset, "-", "")); {
+	case norm == "utf8":
+		c.Detail = tag
+	case tag == "":
+		c.Status = StatusWarn
+		c.Detail = "no locale set; box drawing and symbols may render as ?"
+		c.Fix = "export LANG=en_US.UTF-8 (or another UTF-8 locale)"
+	default:
+		c.Status = StatusWarn
+		c.Detail = tag + " is not a UTF-8 locale; box drawing and symbols may render as ?"
+		c.Fix = "switch to a UTF-8 locale, e.g. export LANG=en_US.UTF-8"
+	}
+	return c
+}
+
+func (d *Doctor) checkNumberLocale() Check {
+	c := Check{Name: "numbers"}
+	tag := d.firstEnv("LC_ALL", "LC_NUMERIC", "LANG")
+	base, _, _ := strings.Cut(tag, ".")
+	if tag == "" || base == "C" || base == "POSIX" {
+		c.Detail = "English separators (1,234.5)"
+		return c
+	}
+	l, err := numfmt.ParseLocale(tag)
+	if err != nil {
+		c.Status = StatusWarn
+		c.Detail = fmt.Sprintf("%s has no known separators; using English (1,234.5)", tag)
+		c.Fix = "pass -locale with a supported tag such as de_DE or fr_FR"
+		return c
+	}
+	c.Detail = fmt.Sprintf("%s (1%s234%s5)", tag, l.Group, l.Decimal)
+	return c
+}
+
+func (d *Doctor) checkAgent() Check {
+	c := Check{Name: "agent"}
+	path, err := d.lookPath(d.agent)
+	if err != nil {
+		c.Status = StatusWarn
+		c.Detail = d.agent + " not found on PATH; prompt mode will fail (piping a stream still works)"
+		c.Fix = "install the " + d.agent + " CLI or add it to PATH"
+		return c
+	}
+	c.Detail = path
+	return c
+}
+
+// checkFiles validates files named on the command line.
+func (d *Doctor) checkFiles() []Check {
+	var checks []Check
+	if d.annotationsPath != "" {
+		c := Check{Name: "annotations"}
+		if set, err := annotations.Load(d.annotationsPath); err != nil {
+			c.Status = StatusFail
+			c.Detail = err.Error()
+			c.Fix = "the file must be a JSON object mapping event UUIDs to a comment or list of comments"
+		} else {
+			c.Detail = fmt.Sprintf("%s: %d annotated events", d.annotationsPath, len(set))
+		}
+		checks = append(checks, c)
+	}
+	for _, path := range d.outputPaths {
+		if path == "" {
+			continue
+		}
+		c := Check{Name: "output", Detail: path}
+		dir := filepath.Dir(path)
+		if info, err := d.stat(dir); err != nil || !info.IsDir() {
+			c.Status = StatusFail
+			c.Detail = fmt.Sprintf("%s: directory %s does not exist", path, dir)
+			c.Fix = "create it with mkdir -p " + strconv.Quote(dir)
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func (d *Doctor) firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := d.getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (d *Doctor) termProgram() string {
+	return d.getenv("TERM_PROGRAM")
+}
+
+// terminalProgram names the terminal emulator, if it identifies itself.
+func (d *Doctor) terminalProgram() string {
+	switch {
+	case d.isKitty():
+		return "kitty"
+	case d.getenv("WT_SESSION") != "":
+		return "Windows Terminal"
+	case d.termProgram() != "" && d.termProgram() != "tmux":
+		return d.termProgram()
+	case d.getenv("VTE_VERSION") != "":
+		return "VTE " + d.getenv("VTE_VERSION")
+	case d.getenv("ALACRITTY_WINDOW_ID") != "" || d.getenv("ALACRITTY_SOCKET") != "":
+		return "Alacritty"
+	}
+	return ""
+}
+
+func (d *Doctor) isKitty() bool {
+	return d.getenv("KITTY_WINDOW_ID") != "" || d.getenv("TERM") == "xterm-kitty"
+}
+
+func (d *Doctor) inTmux() bool {
+	return d.getenv("TMUX") != "" || d.termProgram() == "tmux"
+}
+
+// knownTruecolor reports terminals that support 24-bit color but may not
+// export COLORTERM (notably over ssh, which drops it).
+func (d *Doctor) knownTruecolor() bool {
+	switch d.termProgram() {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	return d.isKitty() || d.getenv("WT_SESSION") != "" || d.getenv("ALACRITTY_WINDOW_ID") != ""
+}
+
+func (d *Doctor) supportsOSC8() bool {
+	switch d.termProgram() {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	if vte, err := strconv.Atoi(d.getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return d.isKitty() || d.getenv("WT_SESSION") != "" || d.getenv("ALACRITTY_WINDOW_ID") != ""
+}
+
+func (d *Doctor) supportsOSC52() bool {
+	switch d.termProgram() {
+	case "iTerm.app", "WezTerm", "ghostty":
+		return true
+	}
+	return d.isKitty() || d.getenv("WT_SESSION") != "" || d.getenv("ALACRITTY_WINDOW_ID") != "" ||
+		strings.HasPrefix(d.getenv("TERM"), "foot")
+}
@@ -0,0 +1,143 @@
+package doctor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) Option {
+	return WithEnv(func(key string) string { return vars[key] })
+}
+
+func lookPath(found ...string) Option {
+	return WithLookPath(func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	})
+}
+
+func find(t *testing.T, checks []Check, name string) Check {
+	t.Helper()
+	for _, c := range checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, checks)
+	return Check{}
+}
+
+func TestChecks_ModernTerminal(t *testing.T) {
+	d := New(
+		env(map[string]string{"TERM": "xterm-kitty", "KITTY_WINDOW_ID": "1", "LANG": "de_DE.UTF-8"}),
+		lookPath("claude", "wl-copy"),
+		WithTerminal(true),
+	)
+	checks := d.Checks()
+
+	for _, c := range checks {
+		if c.Status != StatusOK {
+			t.Errorf("%s: status %v (%s), want ok", c.Name, c.Status, c.Detail)
+		}
+	}
+	if got := find(t, checks, "color").Detail; !strings.Contains(got, "truecolor") {
+		t.Errorf("color detail = %q", got)
+	}
+	if got := find(t, checks, "clipboard").Detail; got != "OSC 52, wl-copy" {
+		t.Errorf("clipboard detail = %q", got)
+	}
+	if got := find(t, checks, "numbers").Detail; got != "de_DE.UTF-8 (1.234,5)" {
+		t.Errorf("numbers detail = %q", got)
+	}
+}
+
+func TestChecks_Problems(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		check   string
+		status  Status
+		fixText string
+	}{
+		{"no color", map[string]string{"TERM": "xterm", "NO_COLOR": "1"}, "color", StatusWarn, "unset NO_COLOR"},
+		{"256 in tmux", map[string]string{"TERM": "tmux-256color", "TMUX": "/tmp/s"}, "color", StatusWarn, "terminal-features"},
+		{"dumb", map[string]string{"TERM": "dumb"}, "terminal", StatusFail, "-no-tui"},
+		{"latin1", map[string]string{"TERM": "xterm", "LANG": "en_US.ISO-8859-1"}, "unicode", StatusWarn, "UTF-8"},
+		{"no locale", map[string]string{"TERM": "xterm"}, "unicode", StatusWarn, "LANG="},
+		{"unknown number locale", map[string]string{"TERM": "xterm", "LC_NUMERIC": "xx_YY.UTF-8"}, "numbers", StatusWarn, "-locale"},
+		{"no links", map[string]string{"TERM": "xterm"}, "hyperlinks", StatusWarn, "hyperlink support"},
+		{"no clipboard", map[string]string{"TERM": "xterm"}, "clipboard", StatusWarn, "xclip"},
+		{"no agent", map[string]string{"TERM": "xterm"}, "agent", StatusWarn, "PATH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := find(t, New(env(tt.vars), lookPath(), WithTerminal(true)).Checks(), tt.check)
+			if c.Status != tt.status {
+				t.Errorf("status = %v, want %v (%s)", c.Status, tt.status, c.Detail)
+			}
+			if !strings.Contains(c.Fix, tt.fixText) {
+				t.Errorf("fix = %q, want it to mention %q", c.Fix, tt.fixText)
+			}
+		})
+	}
+}
+
+func TestChecks_NotATerminal(t *testing.T) {
+	c := find(t, New(env(nil), lookPath()).Checks(), "terminal")
+	if c.Status != StatusWarn || !strings.Contains(c.Detail, "not a terminal") {
+		t.Errorf("terminal check = %+v", c)
+	}
+}
+
+func TestChecks_Files(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(good, []byte(`{"evt-1": "looks fine", "2": ["a", "b"]}`), 0o644)
+	os.WriteFile(bad, []byte(`{"1": 42}`), 0o644)
+
+	c := find(t, New(env(nil), lookPath(), WithAnnotations(good)).Checks(), "annotations")
+	if c.Status != StatusOK || !strings.Contains(c.Detail, "2 annotated events") {
+		t.Errorf("good annotations = %+v", c)
+	}
+	c = find(t, New(env(nil), lookPath(), WithAnnotations(bad)).Checks(), "annotations")
+	if c.Status != StatusFail || c.Fix == "" {
+		t.Errorf("bad annotations = %+v", c)
+	}
+
+	checks := New(env(nil), lookPath(), WithOutputPaths(filepath.Join(dir, "out.html"), filepath.Join(dir, "missing", "s.viewscreen"))).Checks()
+	var outputs []Check
+	for _, c := range checks {
+		if c.Name == "output" {
+			outputs = append(outputs, c)
+		}
+	}
+	if len(outputs) != 2 || outputs[0].Status != StatusOK || outputs[1].Status != StatusFail {
+		t.Errorf("output checks = %+v", outputs)
+	}
+}
+
+func TestReport(t *testing.T) {
+	var buf bytes.Buffer
+	worst := Report(&buf, []Check{
+		{Name: "color", Detail: "truecolor"},
+		{Name: "unicode", Status: StatusWarn, Detail: "C locale", Fix: "export LANG=en_US.UTF-8"},
+	})
+	if worst != StatusWarn {
+		t.Errorf("worst = %v, want warn", worst)
+	}
+	want := "ok    color        truecolor\n" +
+		"warn  unicode      C locale\n" +
+		"                   fix: export LANG=en_US.UTF-8\n"
+	if buf.String() != want {
+		t.Errorf("Report output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -3,9 +3,14 @@ package events
 
 import (
 	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
 
 	"github.com/johnnyfreeman/viewscreen/assistant"
 	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/record"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/stream"
 	"github.com/johnnyfreeman/viewscreen/system"
@@ -65,6 +70,18 @@ type CodexEvent struct{ Data codex.Event }
 
 func (CodexEvent) eventMarker() {}
 
+// MetadataEvent wraps the -tag metadata preamble viewscreen puts at the head
+// of a stream (see package metadata).
+type MetadataEvent struct{ Data metadata.Event }
+
+func (MetadataEvent) eventMarker() {}
+
+// SkipEvent marks an idle period a smoothed replay shortened (see
+// record.WithMaxGap).
+type SkipEvent struct{ Data record.Skip }
+
+func (SkipEvent) eventMarker() {}
+
 // IgnoredEvent wraps an event type that is recognized but intentionally not processed.
 // Examples: rate_limit_event.
 type IgnoredEvent struct{ Type string }
@@ -79,6 +96,29 @@ type ParseError struct {
 
 func (ParseError) eventMarker() {}
 
+// NoiseEvent is an input line that is not JSON at all, such as a warning a
+// wrapper script printed to the same stream (npm WARN ...). With -pass-noise
+// it is shown as muted raw output instead of being reported as a ParseError.
+type NoiseEvent struct{ Line string }
+
+func (NoiseEvent) eventMarker() {}
+
+// IsNoise reports whether line is noise rather than a broken event: without
+// color codes and surrounding whitespace, it does not start with "{".
+// Truncated or malformed JSON is still a ParseError.
+func IsNoise(line string) bool {
+	return !strings.HasPrefix(strings.TrimSpace(ansi.Strip(line)), "{")
+}
+
+// PassNoise returns a NoiseEvent in place of event when event is the
+// ParseError of a noise line, and event otherwise.
+func PassNoise(event Event) Event {
+	if e, ok := event.(ParseError); ok && e.Err != nil && IsNoise(e.Line) {
+		return NoiseEvent{Line: e.Line}
+	}
+	return event
+}
+
 // Parse parses a JSON line into a typed Event.
 // Returns nil for empty lines.
 func Parse(line string) Event {
@@ -133,6 +173,20 @@ func Parse(line string) Event {
 	case "rate_limit_event":
 		return IgnoredEvent{Type: base.Type}
 
+	case record.SkipType:
+		var event record.Skip
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return ParseError{Err: err, Line: line}
+		}
+		return SkipEvent{Data: event}
+
+	case metadata.Type:
+		event, ok := metadata.Parse([]byte(line))
+		if !ok {
+			return ParseError{Err: nil, Line: "Invalid metadata event"}
+		}
+		return MetadataEvent{Data: event}
+
 	default:
 		if codex.IsEventType(base.Type) {
 			event, err := codex.ParseEvent([]byte(line))
@@ -144,3 +198,30 @@ func Parse(line string) Event {
 		return ParseError{Err: nil, Line: "Unknown event type: " + base.Type}
 	}
 }
+
+// TypeName returns the stream type name of a rendered event ("system",
+// "assistant", "user", "stream_event", "result", "codex",
+// "viewscreen_metadata" or "viewscreen_skip"), or "" for ignored events and
+// parse errors.
+func TypeName(event Event) string {
+	switch event.(type) {
+	case SystemEvent, SubAgentSystemEvent:
+		return "system"
+	case AssistantEvent:
+		return "assistant"
+	case UserEvent:
+		return "user"
+	case StreamEvent:
+		return "stream_event"
+	case ResultEvent:
+		return "result"
+	case CodexEvent:
+		return "codex"
+	case MetadataEvent:
+		return metadata.Type
+	case SkipEvent:
+		return record.SkipType
+	default:
+		return ""
+	}
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestParse_EmptyLine(t *testing.T) {
@@ -26,6 +27,24 @@ func TestParse_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestPassNoise(t *testing.T) {
+	tests := []struct {
+		line  string
+		noise bool
+	}{
+		{"npm WARN deprecated glob@7.2.3", true},
+		{"\x1b[33mwarning\x1b[0m: something", true},
+		{`  {"type":"assistant",`, false},
+		{`{"type":"mystery"}`, false},
+	}
+	for _, tt := range tests {
+		_, got := PassNoise(Parse(tt.line)).(NoiseEvent)
+		if got != tt.noise {
+			t.Errorf("PassNoise(Parse(%q)) is noise = %v, want %v", tt.line, got, tt.noise)
+		}
+	}
+}
+
 func TestParse_UnknownEventType(t *testing.T) {
 	result := Parse(`{"type":"unknown"}`)
 	parseErr, ok := result.(ParseError)
@@ -232,6 +251,31 @@ func TestParse_RateLimitEvent(t *testing.T) {
 	}
 }
 
+func TestParse_MetadataEvent(t *testing.T) {
+	result := Parse(`{"type":"viewscreen_metadata","tags":{"repo":"viewscreen"}}`)
+	meta, ok := result.(MetadataEvent)
+	if !ok {
+		t.Fatalf("Parse should return MetadataEvent, got %T", result)
+	}
+	if meta.Data.Tags["repo"] != "viewscreen" {
+		t.Errorf("Tags = %v, want repo=viewscreen", meta.Data.Tags)
+	}
+	if TypeName(meta) != "viewscreen_metadata" {
+		t.Errorf("TypeName = %q", TypeName(meta))
+	}
+}
+
+func TestParse_SkipEvent(t *testing.T) {
+	result := Parse(`{"type":"viewscreen_skip","skipped_ms":240000}`)
+	skip, ok := result.(SkipEvent)
+	if !ok {
+		t.Fatalf("Parse should return SkipEvent, got %T", result)
+	}
+	if skip.Data.Skipped() != 4*time.Minute {
+		t.Errorf("Skipped() = %v, want 4m", skip.Data.Skipped())
+	}
+}
+
 func TestParse_SystemEvent_NewFields(t *testing.T) {
 	event := map[string]any{
 		"type":                "system",
@@ -351,3 +395,25 @@ func TestParse_CodexInvalidJSON(t *testing.T) {
 		t.Fatalf("Parse should return ParseError for malformed codex item, got %T", result)
 	}
 }
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{SystemEvent{}, "system"},
+		{SubAgentSystemEvent{}, "system"},
+		{AssistantEvent{}, "assistant"},
+		{UserEvent{}, "user"},
+		{StreamEvent{}, "stream_event"},
+		{ResultEvent{}, "result"},
+		{CodexEvent{}, "codex"},
+		{IgnoredEvent{Type: "rate_limit_event"}, ""},
+		{ParseError{}, ""},
+	}
+	for _, tt := range tests {
+		if got := TypeName(tt.event); got != tt.want {
+			t.Errorf("TypeName(%T) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/types"
+	"github.com/johnnyfreeman/viewscreen/user"
+)
+
+// startLanes allocates a concurrency lane to every tool_use block in
+// content, made by the sub-agent of parentID when it is not empty.
+func (p *EventProcessor) startLanes(content []types.ContentBlock, parentID string) {
+	lanes := p.renderers.Lanes
+	if lanes == nil {
+		return
+	}
+	for _, block := range content {
+		if block.Type == "tool_use" {
+			lanes.Start(block.ID, block.Name, activityInput(block), parentID)
+		}
+	}
+}
+
+// finishLanes frees the lanes of the tool calls whose results content
+// carries.
+func (p *EventProcessor) finishLanes(content []user.ToolResultContent) {
+	lanes := p.renderers.Lanes
+	if lanes == nil {
+		return
+	}
+	for _, c := range content {
+		if c.Type == "tool_result" {
+			lanes.Finish(c.ToolUseID)
+		}
+	}
+}
+
+// laneRule returns the rule marking the output of the tool call id, or of
+// the sub-agent it started, when the call runs alongside another, and ""
+// otherwise.
+func (p *EventProcessor) laneRule(id string) string {
+	lanes := p.renderers.Lanes
+	if lanes == nil {
+		return ""
+	}
+	if lane, concurrent, ok := lanes.Lane(id); ok && concurrent {
+		return style.LaneRule(lane)
+	}
+	return ""
+}
+
+// withLaneRule starts every line of rendered with rule.
+func withLaneRule(rendered, rule string) string {
+	if rule == "" || rendered == "" {
+		return rendered
+	}
+	trailing := strings.HasSuffix(rendered, "\n")
+	lines := strings.Split(strings.TrimSuffix(rendered, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = rule + line
+	}
+	out := strings.Join(lines, "\n")
+	if trailing {
+		out += "\n"
+	}
+	return out
+}
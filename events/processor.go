@@ -2,18 +2,27 @@ package events
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/assistant"
 	"github.com/johnnyfreeman/viewscreen/codex"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/pagetitle"
+	"github.com/johnnyfreeman/viewscreen/perf"
+	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/state"
 	"github.com/johnnyfreeman/viewscreen/stream"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/system"
+	"github.com/johnnyfreeman/viewscreen/textutil"
 	"github.com/johnnyfreeman/viewscreen/timeline"
 	"github.com/johnnyfreeman/viewscreen/tools"
+	"github.com/johnnyfreeman/viewscreen/types"
 	"github.com/johnnyfreeman/viewscreen/user"
 )
 
@@ -25,6 +34,14 @@ type ProcessResult struct {
 	Batch timeline.Batch
 	// HasPendingTools indicates whether there are pending tools waiting for results
 	HasPendingTools bool
+	// TitleLookups are the page title lookups (see -resolve-titles) that
+	// had not finished when the WebFetch headers of the result's entry
+	// were rendered. Each channel is closed when its lookup finishes.
+	TitleLookups []<-chan struct{}
+	// Retitle re-renders the result's entry with the titles looked up so
+	// far in its headers. Call it on the goroutine that renders, once
+	// TitleLookups are closed.
+	Retitle func() timeline.Entry
 }
 
 // EventProcessor processes parsed events, updates state, and produces rendered output.
@@ -38,34 +55,61 @@ type EventProcessor struct {
 	codexSnapshots   *codex.FileSnapshotTracker
 	activities       map[string]timeline.Activity
 	activityOrder    []string
+	reads            *readChunks             // the file being read in chunks, if any
+	texts            *textRun                // the text blocks of a message shown last, if any
+	failed           map[string]*failedCall  // the last failed call of each tool, by agent
+	subAgents        map[string]*subAgentRun // the running sub-agents, by Task call
+	textObserved     bool                    // the event being processed continued or began texts
+	lowMemory        bool                    // keep no file snapshots or Read chunks
+	turns            turnSegmenter           // the main agent's turns in the session
+	permissionMode   string                  // the session's permission mode, until ExitPlanMode leaves it
+	turnDivider      string                  // the config.TurnDivider* between turns
+	width            int                     // the SetWidth width, for dividers; 0 for the terminal's
 }
 
 type codexActiveTool struct {
-	name  string
-	input string
+	name   string
+	input  string
+	output string
 }
 
 // NewEventProcessor creates a new EventProcessor with the given state.
 func NewEventProcessor(s *state.State) *EventProcessor {
-	return &EventProcessor{
-		renderers:        NewRendererSet(),
-		state:            s,
-		codexActiveTools: make(map[string]codexActiveTool),
-		codexSnapshots:   codex.NewFileSnapshotTracker(),
-		activities:       make(map[string]timeline.Activity),
-	}
+	return NewEventProcessorWithRenderers(s, NewRendererSet())
 }
 
 // NewEventProcessorWithRenderers creates a new EventProcessor with custom renderers.
 // This allows reusing an existing RendererSet, useful for testing or when
-// renderers need specific configuration.
+// renderers need specific configuration. The state shares the renderers'
+// tool timer and session stats, for the TUI's details modal, and their tool
+// lanes, for its sidebar.
 func NewEventProcessorWithRenderers(s *state.State, rs *RendererSet) *EventProcessor {
+	if s != nil {
+		s.ToolTimes = rs.ToolTimes
+		s.Stats = rs.Stats
+		s.Lanes = rs.Lanes
+	}
 	return &EventProcessor{
 		renderers:        rs,
 		state:            s,
 		codexActiveTools: make(map[string]codexActiveTool),
 		codexSnapshots:   codex.NewFileSnapshotTracker(),
 		activities:       make(map[string]timeline.Activity),
+		failed:           make(map[string]*failedCall),
+		subAgents:        make(map[string]*subAgentRun),
+	}
+}
+
+// SetLowMemory stops the processor keeping anything beyond the event being
+// processed: Codex file changes are shown without the patches built from
+// snapshots of the files, and consecutive Read chunks of a file and text
+// blocks of a message are shown one by one instead of merged.
+func (p *EventProcessor) SetLowMemory(on bool) {
+	p.lowMemory = on
+	if on {
+		p.codexSnapshots = codex.NewFileSnapshotTracker()
+		p.reads = nil
+		p.texts = nil
 	}
 }
 
@@ -74,14 +118,122 @@ func (p *EventProcessor) Renderers() *RendererSet {
 	return p.renderers
 }
 
-// SetWidth updates the word-wrap width for all markdown renderers.
+// SetWidth updates the word-wrap width for all markdown renderers and the
+// width tool headers fit their argument into.
 // This is called when the viewport resizes.
 func (p *EventProcessor) SetWidth(width int) {
+	p.width = width
 	p.renderers.SetWidth(width)
+	render.SetHeaderWidth(width)
+}
+
+// SetLineBudget sets the per-result line budget used to scale truncation.
+func (p *EventProcessor) SetLineBudget(lines int) {
+	p.renderers.SetLineBudget(lines)
 }
 
 // Process handles a parsed event and returns the rendered result.
+// Entries without their own ID are stamped with the event's UUID so they
+// can be referenced (e.g. as anchors in exports), and every entry with the
+// turn it belongs to and the Task call whose sub-agent it shows, if any.
+// The output of an event that ends a turn of the main agent is preceded by
+// the turn's usage (with -usage or at -vv) and, when it begins the next,
+// the SetTurnDivider divider.
+// The time taken is recorded in the state's RenderTimings under the event's
+// TypeName.
 func (p *EventProcessor) Process(event Event) ProcessResult {
+	start := time.Now()
+	p.textObserved = false
+	ended, begins := p.turns.observe(event)
+	res := p.process(event)
+	if _, ok := event.(StreamEvent); !ok {
+		dropBlank(&res)
+	}
+	if !p.textObserved && len(res.Batch.Entries) > 0 {
+		p.texts = nil
+	}
+	if t := p.state.RenderTimings; t != nil {
+		if kind := TypeName(event); kind != "" {
+			t.Record(kind, time.Since(start))
+		}
+	}
+	if id := eventID(event); id != "" {
+		for i := range res.Batch.Entries {
+			if res.Batch.Entries[i].ID == "" {
+				res.Batch.Entries[i].ID = id
+			}
+		}
+	}
+	// Streamed text arrives before the assistant message that counts its turn.
+	turn := p.state.TurnCount
+	if _, ok := event.(StreamEvent); ok {
+		turn++
+	}
+	taskID := parentToolUseID(event)
+	for i := range res.Batch.Entries {
+		res.Batch.Entries[i].Turn = turn
+		res.Batch.Entries[i].TaskID = taskID
+	}
+	if ended.turn > 0 {
+		p.addTurnEnd(&res, ended, begins)
+	}
+	return res
+}
+
+// dropBlank clears a rendering that holds nothing but whitespace and styling,
+// such as an empty assistant message, so it doesn't leave blank lines in the
+// transcript. It is not applied to stream deltas, where a lone newline is
+// part of the text's layout.
+func dropBlank(res *ProcessResult) {
+	if isBlank(res.Rendered) {
+		res.Rendered = ""
+	}
+	entries := res.Batch.Entries[:0]
+	for _, entry := range res.Batch.Entries {
+		if !isBlank(entry.Text()) {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		entries = nil
+	}
+	res.Batch.Entries = entries
+}
+
+// isBlank reports whether rendered output is empty once styling is removed.
+func isBlank(rendered string) bool {
+	return strings.TrimSpace(ansi.Strip(rendered)) == ""
+}
+
+// Timings returns the render timings collected so far, or nil if the state
+// does not collect them.
+func (p *EventProcessor) Timings() *perf.Timings {
+	return p.state.RenderTimings
+}
+
+// eventID returns the stable identifier carried by an event: the uuid field
+// for Claude events, or the item id for Codex item events.
+func eventID(event Event) string {
+	switch e := event.(type) {
+	case SystemEvent:
+		return e.Data.UUID
+	case AssistantEvent:
+		return e.Data.UUID
+	case UserEvent:
+		return e.Data.UUID
+	case StreamEvent:
+		return e.Data.UUID
+	case ResultEvent:
+		return e.Data.UUID
+	case CodexEvent:
+		if e.Data.Item != nil {
+			return e.Data.Item.ID
+		}
+	}
+	return ""
+}
+
+func (p *EventProcessor) process(event Event) ProcessResult {
 	p.detectAgent(event)
 	switch e := event.(type) {
 	case SystemEvent:
@@ -98,6 +250,12 @@ func (p *EventProcessor) Process(event Event) ProcessResult {
 		return p.processResult(e.Data)
 	case CodexEvent:
 		return p.processCodex(e.Data)
+	case MetadataEvent:
+		return processResultFromRendered(metadata.Render(e.Data.Tags), metadata.Kind)
+	case SkipEvent:
+		return processResultFromRendered(renderSkip(e.Data.Skipped()), "skip")
+	case NoiseEvent:
+		return processResultFromRendered(renderNoise(e.Line), "noise")
 	case IgnoredEvent:
 		return ProcessResult{}
 	default:
@@ -105,6 +263,29 @@ func (p *EventProcessor) Process(event Event) ProcessResult {
 	}
 }
 
+// renderSkip renders the marker for idle time a smoothed replay skipped,
+// e.g. "⏩ skipped 4m idle".
+func renderSkip(skipped time.Duration) string {
+	skipped = skipped.Round(time.Second)
+	var d string
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if n := skipped / unit.size; n > 0 {
+			d += fmt.Sprintf("%d%s", n, unit.name)
+			skipped -= n * unit.size
+		}
+	}
+	return style.MutedText("⏩ skipped "+d+" idle") + "\n\n"
+}
+
+// renderNoise renders a noise line as muted raw output, without the colors
+// the program that printed it used.
+func renderNoise(line string) string {
+	return style.MutedText(strings.TrimRight(ansi.Strip(line), " \t\r")) + "\n"
+}
+
 func processResultFromRendered(rendered, kind string) ProcessResult {
 	return processResultFromBatch(rendered, kind, timeline.StatePatch{})
 }
@@ -152,6 +333,7 @@ func resultPatch(event result.Event) timeline.StatePatch {
 		ClearActivity: true,
 		TurnCount:     timeline.IntPtr(event.NumTurns),
 		TotalCost:     timeline.FloatPtr(event.TotalCostUSD),
+		AddCost:       event.TotalCostUSD,
 		IsError:       timeline.BoolPtr(event.IsError),
 		DurationMS:    timeline.IntPtr(event.DurationMS),
 		DurationAPIMS: timeline.IntPtr(event.DurationAPIMS),
@@ -159,6 +341,7 @@ func resultPatch(event result.Event) timeline.StatePatch {
 		OutputTokens:  timeline.IntPtr(event.Usage.OutputTokens),
 		CacheCreated:  timeline.IntPtr(event.Usage.CacheCreationInputTokens),
 		CacheRead:     timeline.IntPtr(event.Usage.CacheReadInputTokens),
+		ContextWindow: timeline.IntPtr(event.ContextWindow()),
 	}
 }
 
@@ -286,6 +469,9 @@ func (p *EventProcessor) processSystem(event system.Event) ProcessResult {
 
 	patch := systemPatch(event)
 	p.state.ApplyPatch(patch)
+	if event.ParentToolUseID == nil {
+		p.permissionMode = event.PermissionMode
+	}
 
 	// Skip rendering for system events with no meaningful data.
 	// These are typically subagent events that lack parent_tool_use_id.
@@ -293,8 +479,34 @@ func (p *EventProcessor) processSystem(event system.Event) ProcessResult {
 		return ProcessResult{}
 	}
 
-	rendered := p.renderers.System.RenderToString(event)
-	return processResultFromBatch(rendered, "system", patch)
+	r := p.renderers
+	rendered := r.System.RenderToString(event)
+	res := processResultFromBatch(rendered, "system", patch)
+	if r.VerboseSystem != nil && len(event.Agents)+len(event.MCPServers)+len(event.Plugins) > 0 {
+		p.setVerbose(&res, func() string { return r.VerboseSystem.RenderToString(event) })
+	}
+	return res
+}
+
+// setVerbose gives the entry of res a rendering at the highest verbosity,
+// made by render when a view asks for it, unless the session is already
+// rendered verbosely.
+func (p *EventProcessor) setVerbose(res *ProcessResult, render func() string) {
+	if len(res.Batch.Entries) == 0 || p.renderers.Config.IsVerbose() {
+		return
+	}
+	res.Batch.Entries[0].Verbose = render
+}
+
+// hasThinking reports whether content has thinking that only shows
+// verbosely.
+func hasThinking(content []types.ContentBlock) bool {
+	for _, block := range content {
+		if block.Type == "thinking" && strings.TrimSpace(block.Thinking) != "" {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *EventProcessor) processAssistant(event assistant.Event) ProcessResult {
@@ -308,25 +520,53 @@ func (p *EventProcessor) processAssistant(event assistant.Event) ProcessResult {
 			CacheCreated: u.CacheCreationInputTokens,
 			CacheRead:    u.CacheReadInputTokens,
 		}
+		// Sub-agents run in their own context, so only the main agent's
+		// requests measure the session's window; each model's latest
+		// request, whoever made it, measures its own.
+		context := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens + u.OutputTokens
+		if p.renderers.Stats != nil {
+			p.renderers.Stats.ModelContextUsed(event.Message.Model, context)
+		}
+		if event.ParentToolUseID == nil {
+			patch.ContextTokens = timeline.IntPtr(context)
+			if p.renderers.Stats != nil {
+				p.renderers.Stats.ContextUsed(context)
+			}
+		}
 	}
 	p.state.ApplyPatch(patch)
 
 	r := p.renderers
 
+	// Sub-agent messages are never streamed into the main block state (see
+	// processStream), so they are rendered in full and must not reset it.
+	subAgent := event.ParentToolUseID != nil
+	// Streamed thinking counts as a text block: either way the prose was
+	// already rendered as it arrived.
+	inTextBlock := r.Stream.InTextBlock() || r.Stream.InThinkingBlock()
+	inToolUseBlock := r.Stream.InToolUseBlock()
+	if subAgent {
+		inTextBlock, inToolUseBlock = false, false
+	}
+
+	p.startToolTimes(event.Message.Content)
+	p.startLanes(event.Message.Content, stringValue(event.ParentToolUseID))
+	prefetchPageTitles(event.Message.Content)
+
 	// Buffer tool_use blocks using the tracker's method
 	msg := tools.AssistantMessage{
 		Content:         event.Message.Content,
 		ParentToolUseID: event.ParentToolUseID,
 	}
-	if r.PendingTools.BufferFromAssistantMessage(msg, r.Stream.InToolUseBlock()) {
+	if r.PendingTools.BufferFromAssistantMessage(msg, inToolUseBlock) {
 		// Update state to show the first pending tool
 		for _, block := range event.Message.Content {
 			if block.Type == "tool_use" && block.ID != "" {
 				activity := timeline.Activity{
 					ID:       block.ID,
 					ParentID: stringValue(event.ParentToolUseID),
-					Name:     block.Name,
-					Input:    tools.GetToolArgFromBlock(block),
+					Name:     tools.DisplayName(block.Name),
+					Input:    activityInput(block),
 				}
 				p.state.ApplyPatch(timeline.StatePatch{CurrentActivity: &activity})
 				patch.CurrentActivity = &activity
@@ -336,22 +576,91 @@ func (p *EventProcessor) processAssistant(event assistant.Event) ProcessResult {
 		}
 	}
 
+	if subAgent {
+		p.observeSubAgent(*event.ParentToolUseID, event)
+		rendered := p.renderParentHeader(*event.ParentToolUseID) +
+			r.Assistant.RenderNestedToString(event, inTextBlock, true)
+		rendered = withLaneRule(rendered, p.laneRule(*event.ParentToolUseID))
+		res := processResultFromBatch(rendered, "assistant", patch)
+		markAssistantError(&res, event)
+		res.HasPendingTools = r.PendingTools.Len() > 0
+		return res
+	}
+
 	// Render text blocks only (tools are buffered)
 	rendered := r.Assistant.RenderToString(
 		event,
-		r.Stream.InTextBlock(),
+		inTextBlock,
 		true, // Suppress tool rendering - we handle it separately
 	)
 	r.Stream.ResetBlockState()
 
 	res := processResultFromBatch(rendered, "assistant", patch)
+	if text, ok := onlyText(event.Message.Content); ok && !inTextBlock && event.Error == "" {
+		if merged, ok := p.observeText(event.Message.ID, "assistant", text); ok {
+			p.mergeText(&res, merged)
+		}
+	}
+	if r.VerboseAssistant != nil && !inTextBlock && hasThinking(event.Message.Content) {
+		p.setVerbose(&res, func() string { return r.VerboseAssistant.RenderToString(event, false, true) })
+	}
+	markAssistantError(&res, event)
 	res.HasPendingTools = r.PendingTools.Len() > 0
 	return res
 }
 
+// markAssistantError marks the entry of an assistant message that carries
+// an API error (e.g. a failed authentication) as an error.
+func markAssistantError(res *ProcessResult, event assistant.Event) {
+	if event.Error != "" && len(res.Batch.Entries) > 0 {
+		res.Batch.Entries[0].Status = timeline.StatusError
+	}
+}
+
+// processReadContinuation renders the Read chunks merged so far as one
+// block that replaces the block of the previous chunks. The streamed output
+// only adds the updated summary beneath that block, which is already shown.
+func (p *EventProcessor) processReadContinuation(block types.ContentBlock, toolHeader string, patch timeline.StatePatch) ProcessResult {
+	r := p.renderers
+	rendered := p.reads.render(r.User, r.Config.IsVerbose())
+	entry := timeline.Entry{
+		Kind:     "user",
+		Title:    block.Name,
+		Arg:      tools.GetToolArgFromBlock(block),
+		Body:     toolHeader + rendered,
+		Replaces: true,
+	}
+	if r.Full != nil {
+		entry.Full = toolHeader + p.reads.render(r.Full, true)
+	}
+	return ProcessResult{
+		Rendered:        rendered,
+		Batch:           timeline.Batch{Entries: []timeline.Entry{entry}, Patch: patch},
+		HasPendingTools: r.PendingTools.Len() > 0,
+	}
+}
+
+// renderToolResult renders a tool result with ur, nested under a sub-agent
+// when isNested, followed by its elapsed time when timed.
+func (p *EventProcessor) renderToolResult(ur *user.Renderer, event user.Event, isNested bool, elapsed time.Duration, timed bool) string {
+	prefix, cont := style.OutputPrefix, style.OutputContinue
+	rendered := ""
+	if isNested {
+		prefix, cont = style.NestedOutputPrefix, style.NestedOutputContinue
+		rendered = ur.RenderNestedToString(event)
+	} else {
+		rendered = ur.RenderToString(event)
+	}
+	if timed {
+		rendered = withElapsed(rendered, elapsed, prefix, cont)
+	}
+	return rendered
+}
+
 func (p *EventProcessor) processUser(event user.Event) ProcessResult {
 	patch := toolUseResultPatch(event.ToolUseResult)
 	p.state.ApplyPatch(patch)
+	p.recordUserStats(event)
 	r := p.renderers
 
 	var content strings.Builder
@@ -360,22 +669,18 @@ func (p *EventProcessor) processUser(event user.Event) ProcessResult {
 	// Check if this is a sub-agent prompt (text content with parent_tool_use_id)
 	if event.ParentToolUseID != nil && p.isSubAgentPrompt(event) {
 		// Resolve the parent tool early and render its header
-		if resolved := r.PendingTools.ResolveParentEarly(*event.ParentToolUseID); resolved != nil {
-			isNested = resolved.IsNested
-			if activity, ok := p.activities[*event.ParentToolUseID]; ok {
-				activity.HeaderRendered = true
-				p.setActivity(activity)
-			}
-			str, _ := tools.RenderResolved(*resolved)
-			content.WriteString(str)
+		if pending, ok := r.PendingTools.Get(*event.ParentToolUseID); ok {
+			isNested = r.PendingTools.IsNested(pending)
 		}
+		content.WriteString(p.renderParentHeader(*event.ParentToolUseID))
 		// Render the prompt text with truncation
 		if isNested {
 			content.WriteString(r.User.RenderNestedSubAgentPromptToString(event))
 		} else {
 			content.WriteString(r.User.RenderSubAgentPromptToString(event))
 		}
-		res := processResultFromBatch(content.String(), "user", patch)
+		rendered := withLaneRule(content.String(), p.laneRule(*event.ParentToolUseID))
+		res := processResultFromBatch(rendered, "user", patch)
 		res.HasPendingTools = r.PendingTools.Len() > 0
 		return res
 	}
@@ -394,20 +699,45 @@ func (p *EventProcessor) processUser(event user.Event) ProcessResult {
 		}
 	}
 	matched := r.PendingTools.MatchFromUserMessage(msg)
-
-	// Render matched tool headers (unless already rendered) and set context
+	elapsed, timed := p.finishToolTimes(event)
+	// Results of calls running alongside others are marked with their lane.
+	rule := ""
+	if len(matched) > 0 {
+		rule = p.laneRule(matched[0].Block.ID)
+	}
+	p.finishLanes(event.Message.Content)
+
+	// Render matched tool headers (unless already rendered), each with an
+	// alert when it touches a watched path or breaks a read-only permission
+	// mode, a note when it retries a failed call and its raw input at -vv,
+	// and set context
+	var toolHeader string
+	var untitled []untitledHeader
+	failed := failedResults(event)
 	for _, match := range matched {
 		isNested = match.IsNested
+		str, ctx := tools.RenderResolved(match.ResolvedTool)
+		if done := pendingPageTitle(match.Block); done != nil && !match.HeaderRendered {
+			untitled = append(untitled, untitledHeader{match.ResolvedTool, str, done})
+		}
+		cont := style.OutputContinue
+		if isNested {
+			cont = style.NestedOutputContinue
+		}
+		str += p.watchAlert(match.Block, cont)
+		str += p.readOnlyAlert(match.Block, failed[match.Block.ID], cont)
+		str += renderRetryNote(p.observeRetry(match.Block, stringValue(event.ParentToolUseID), failed[match.Block.ID]), cont)
+		str += p.renderRawInput(match.Block, cont)
+		toolHeader = str
 		if !match.HeaderRendered {
-			str, ctx := tools.RenderResolved(match.ResolvedTool)
 			content.WriteString(str)
-			r.User.SetToolContext(ctx)
-		} else {
-			// Header was rendered early, just set context
-			_, ctx := tools.RenderResolved(match.ResolvedTool)
-			r.User.SetToolContext(ctx)
+		}
+		r.User.SetToolContext(ctx)
+		if r.Full != nil {
+			r.Full.SetToolContext(ctx)
 		}
 	}
+	header := content.String()
 
 	// Clear tool state if no more pending tools
 	if r.PendingTools.Len() == 0 {
@@ -415,18 +745,167 @@ func (p *EventProcessor) processUser(event user.Event) ProcessResult {
 		p.state.ApplyPatch(timeline.StatePatch{ClearActivity: true})
 	}
 
-	// Render the tool result (with nested prefix if applicable)
-	if isNested {
-		content.WriteString(r.User.RenderNestedToString(event))
-	} else {
-		content.WriteString(r.User.RenderToString(event))
+	// A Read continuing the previous chunk of a file replaces its block
+	toolName := ""
+	if len(matched) == 1 && !isNested {
+		toolName = matched[0].Block.Name
+	}
+	if !p.lowMemory && p.observeRead(toolName, event) {
+		return p.processReadContinuation(matched[0].Block, toolHeader, patch)
 	}
 
-	res := processResultFromBatch(content.String(), "user", patch)
+	// Render the tool result (with nested prefix if applicable)
+	content.WriteString(p.renderToolResult(r.User, event, isNested, elapsed, timed))
+
+	res := processResultFromBatch(withLaneRule(content.String(), rule), "user", patch)
+	if len(matched) > 0 && len(res.Batch.Entries) > 0 {
+		// Tag the entry with the tool whose result it shows.
+		block := matched[0].Block
+		res.Batch.Entries[0].Title = block.Name
+		res.Batch.Entries[0].Arg = tools.GetToolArgFromBlock(block)
+		res.Batch.Entries[0].SubAgent = p.finishSubAgent(block.ID)
+		if r.Full != nil {
+			res.Batch.Entries[0].Full = withLaneRule(header+p.renderToolResult(r.Full, event, isNested, elapsed, timed), rule)
+		}
+	}
+	if len(res.Batch.Entries) > 0 && hasToolError(event) {
+		res.Batch.Entries[0].Status = timeline.StatusError
+	}
+	if len(untitled) > 0 && len(res.Batch.Entries) > 0 {
+		setRetitle(&res, untitled)
+	}
 	res.HasPendingTools = r.PendingTools.Len() > 0
 	return res
 }
 
+// activityInput returns the argument shown for a running tool. File paths
+// are shortened in the middle so the basename survives the timeline's width
+// limit.
+func activityInput(block types.ContentBlock) string {
+	arg := tools.GetToolArgFromBlock(block)
+	if tools.IsFilePathTool(block.Name) {
+		return textutil.ShortenPath(arg, activityArgWidth)
+	}
+	return arg
+}
+
+// activityArgWidth matches the argument width of render.TimelineRenderer.
+const activityArgWidth = 80
+
+// prefetchPageTitles starts looking up the titles of the pages fetched by
+// WebFetch calls in content, when -resolve-titles is on.
+func prefetchPageTitles(content []types.ContentBlock) {
+	for _, block := range content {
+		if block.Type == "tool_use" && block.Name == "WebFetch" {
+			pagetitle.Default().Prefetch(tools.GetToolArgFromBlock(block))
+		}
+	}
+}
+
+// pendingPageTitle returns a channel closed when the title of the page
+// fetched by a WebFetch call has been looked up, or nil when there is no
+// lookup to wait for. Headers are rendered without waiting, with whatever
+// title is known, and re-rendered once it finishes.
+func pendingPageTitle(block types.ContentBlock) <-chan struct{} {
+	if block.Name != "WebFetch" {
+		return nil
+	}
+	return pagetitle.Default().Pending(tools.GetToolArgFromBlock(block))
+}
+
+// untitledHeader is a WebFetch header rendered while its page title was
+// still being looked up.
+type untitledHeader struct {
+	tool     tools.ResolvedTool
+	rendered string
+	done     <-chan struct{}
+}
+
+// setRetitle sets res up to re-render its entry once the page titles of
+// headers have been looked up.
+func setRetitle(res *ProcessResult, headers []untitledHeader) {
+	entry := res.Batch.Entries[0]
+	for _, h := range headers {
+		res.TitleLookups = append(res.TitleLookups, h.done)
+	}
+	res.Retitle = func() timeline.Entry {
+		retitled := entry
+		for _, h := range headers {
+			str, _ := tools.RenderResolved(h.tool)
+			retitled.Body = strings.Replace(retitled.Body, h.rendered, str, 1)
+			retitled.Full = strings.Replace(retitled.Full, h.rendered, str, 1)
+		}
+		return retitled
+	}
+}
+
+// startToolTimes starts timing every tool_use block in content.
+func (p *EventProcessor) startToolTimes(content []types.ContentBlock) {
+	timer := p.renderers.ToolTimes
+	if timer == nil {
+		return
+	}
+	for _, block := range content {
+		if block.Type == "tool_use" {
+			timer.Start(block.ID, block.Name)
+		}
+	}
+}
+
+// finishToolTimes stops timing the tool calls whose results event carries
+// and returns the duration of the first one, if it is long enough to show.
+func (p *EventProcessor) finishToolTimes(event user.Event) (time.Duration, bool) {
+	timer := p.renderers.ToolTimes
+	if timer == nil {
+		return 0, false
+	}
+	var first time.Duration
+	found := false
+	for _, c := range event.Message.Content {
+		if c.Type != "tool_result" {
+			continue
+		}
+		if elapsed, ok := timer.Finish(c.ToolUseID); ok && !found {
+			first, found = elapsed, true
+		}
+	}
+	return first, found && first >= tools.MinShownElapsed
+}
+
+// withElapsed appends a tool's duration to its rendered result: on the same
+// line when the result is a one-line summary, otherwise on a line of its own.
+func withElapsed(rendered string, elapsed time.Duration, prefix, cont string) string {
+	label := style.MutedText("(" + tools.FormatElapsed(elapsed) + ")")
+	switch {
+	case rendered == "":
+		return prefix + label + "\n"
+	case strings.Count(rendered, "\n") == 1 && strings.HasSuffix(rendered, "\n"):
+		return strings.TrimSuffix(rendered, "\n") + " " + label + "\n"
+	default:
+		if !strings.HasSuffix(rendered, "\n") {
+			rendered += "\n"
+		}
+		return rendered + cont + label + "\n"
+	}
+}
+
+// renderParentHeader renders the header of the Task that spawned a sub-agent
+// the first time the sub-agent produces output, so its nested block appears
+// beneath the header instead of before it. It returns "" once the header has
+// been rendered or if the parent is unknown.
+func (p *EventProcessor) renderParentHeader(parentID string) string {
+	resolved := p.renderers.PendingTools.ResolveParentEarly(parentID)
+	if resolved == nil {
+		return ""
+	}
+	if activity, ok := p.activities[parentID]; ok {
+		activity.HeaderRendered = true
+		p.setActivity(activity)
+	}
+	str, _ := tools.RenderResolved(*resolved)
+	return str
+}
+
 // isSubAgentPrompt checks if a user event is a sub-agent prompt.
 // Sub-agent prompts have text content (not tool_result) and are used to pass
 // the prompt to a Task sub-agent.
@@ -440,6 +919,13 @@ func (p *EventProcessor) isSubAgentPrompt(event user.Event) bool {
 }
 
 func (p *EventProcessor) processStream(event stream.Event) ProcessResult {
+	// Sub-agent deltas would interleave with the main session's stream and
+	// corrupt its block state. The sub-agent's complete assistant message
+	// follows and is rendered nested under its Task instead.
+	if event.ParentToolUseID != nil {
+		return ProcessResult{HasPendingTools: p.HasPendingTools()}
+	}
+
 	r := p.renderers
 	rendered := r.Stream.RenderToString(event)
 
@@ -449,13 +935,38 @@ func (p *EventProcessor) processStream(event stream.Event) ProcessResult {
 		if toolName == "" {
 			toolName = r.Stream.CurrentBlockType()
 		}
-		activity := timeline.Activity{Name: toolName}
+		if r.ToolTimes != nil {
+			r.ToolTimes.Start(r.Stream.CurrentToolID(), toolName)
+		}
+		if r.Lanes != nil {
+			r.Lanes.Start(r.Stream.CurrentToolID(), toolName, "", "")
+		}
+		activity := timeline.Activity{Name: tools.DisplayName(toolName)}
 		patch := timeline.StatePatch{CurrentActivity: &activity}
 		p.state.ApplyPatch(patch)
 		return processResultFromBatch(rendered, "stream", patch)
 	}
 
-	return processResultFromRendered(rendered, "stream")
+	res := processResultFromRendered(rendered, "stream")
+	switch {
+	case event.Event.Type == "message_start":
+		p.texts = nil
+	case event.Event.Type == "content_block_stop" && r.Stream.InTextBlock() && rendered != "":
+		// Streamed blocks of the current message: message_start ends the run.
+		if merged, ok := p.observeText("stream", "stream", r.Stream.GetBufferedText()); ok {
+			p.mergeText(&res, merged)
+		}
+	}
+	if event.Event.Type == "content_block_stop" && r.Stream.InThinkingBlock() && r.VerboseAssistant != nil {
+		if block := r.Stream.CurrentThinkingBlock(); hasThinking([]types.ContentBlock{block}) {
+			p.setVerbose(&res, func() string {
+				var sb strings.Builder
+				render.WriteThinking(&sb, block, true)
+				return sb.String()
+			})
+		}
+	}
+	return res
 }
 
 func (p *EventProcessor) processResult(event result.Event) ProcessResult {
@@ -472,12 +983,58 @@ func (p *EventProcessor) processResult(event result.Event) ProcessResult {
 		content.WriteString(style.OutputPrefix + style.MutedText("(no result)") + "\n")
 	}
 	p.clearActivities()
+	if r.Lanes != nil {
+		r.Lanes.Clear()
+	}
+	clear(p.subAgents)
 
 	patch := resultPatch(event)
 	p.state.ApplyPatch(patch)
 	content.WriteString(r.Result.RenderToString(event))
 
-	return processResultFromBatch(content.String(), "result", patch)
+	res := processResultFromBatch(content.String(), "result", patch)
+	// Permission denials are marked too: they are tool calls that failed.
+	if (event.IsError || len(event.PermissionDenials) > 0) && len(res.Batch.Entries) > 0 {
+		res.Batch.Entries[0].Status = timeline.StatusError
+	}
+	return res
+}
+
+// hasToolError reports whether any tool result in a user event failed.
+func hasToolError(event user.Event) bool {
+	for _, c := range event.Message.Content {
+		if c.Type == "tool_result" && c.IsError {
+			return true
+		}
+	}
+	return false
+}
+
+// recordUserStats counts the failed tool calls, changed files and empty
+// Grep/Glob searches of a user event in the session stats. Edit, MultiEdit
+// and Write results carry the changed file's path at the top of
+// tool_use_result.
+func (p *EventProcessor) recordUserStats(event user.Event) {
+	stats := p.renderers.Stats
+	if stats == nil {
+		return
+	}
+	if hasToolError(event) {
+		stats.ToolFailed()
+	}
+	var changed struct {
+		FilePath string `json:"filePath"`
+	}
+	if len(event.ToolUseResult) > 0 && json.Unmarshal(event.ToolUseResult, &changed) == nil {
+		stats.FileChanged(changed.FilePath)
+	}
+	for _, c := range event.Message.Content {
+		if pending, ok := p.renderers.PendingTools.Get(c.ToolUseID); ok && c.Type == "tool_result" &&
+			user.EmptySearch(pending.Block.Name, event.ToolUseResult) {
+			stats.EmptySearch()
+			break
+		}
+	}
 }
 
 // processCodex handles an event from the Codex CLI stream. Codex events are
@@ -488,13 +1045,22 @@ func (p *EventProcessor) processResult(event result.Event) ProcessResult {
 func (p *EventProcessor) processCodex(event codex.Event) ProcessResult {
 	p.prepareCodexFileChange(event)
 	p.applyCodexState(event)
+	p.recordCodexStats(event)
 	res := processResultFromBatch(p.renderers.Codex.Render(event), "codex", codexPatch(event))
+	if len(res.Batch.Entries) > 0 {
+		if event.Item != nil {
+			res.Batch.Entries[0].Title = event.Item.Type
+		}
+		if codexFailed(event) {
+			res.Batch.Entries[0].Status = timeline.StatusError
+		}
+	}
 	res.HasPendingTools = len(p.activities) > 0
 	return res
 }
 
 func (p *EventProcessor) prepareCodexFileChange(event codex.Event) {
-	if event.Item == nil || event.Item.Type != codex.ItemFileChange {
+	if event.Item == nil || event.Item.Type != codex.ItemFileChange || p.lowMemory {
 		return
 	}
 	if p.codexSnapshots == nil {
@@ -508,6 +1074,38 @@ func (p *EventProcessor) prepareCodexFileChange(event codex.Event) {
 	}
 }
 
+// recordCodexStats counts the files of completed Codex file changes and the
+// failed items in the session stats.
+func (p *EventProcessor) recordCodexStats(event codex.Event) {
+	stats := p.renderers.Stats
+	if stats == nil || event.Type != codex.TypeItemCompleted || event.Item == nil {
+		return
+	}
+	if event.Item.Type == codex.ItemFileChange {
+		for _, c := range event.Item.Changes {
+			stats.FileChanged(c.Path)
+		}
+	}
+	if codexFailed(event) {
+		stats.ToolFailed()
+	}
+}
+
+// codexFailed reports whether a Codex event shows a failed turn, a stream
+// error or a failed item.
+func codexFailed(event codex.Event) bool {
+	switch event.Type {
+	case codex.TypeTurnFailed, codex.TypeError:
+		return true
+	}
+	item := event.Item
+	if item == nil {
+		return false
+	}
+	return item.Type == codex.ItemError || item.Status == "failed" ||
+		item.ExitCode != nil && *item.ExitCode != 0
+}
+
 func codexPatch(event codex.Event) timeline.StatePatch {
 	switch event.Type {
 	case codex.TypeTurnStarted:
@@ -534,23 +1132,23 @@ func codexPatch(event codex.Event) timeline.StatePatch {
 		case codex.ItemTodoList:
 			return timeline.StatePatch{ReplaceTodos: true, Todos: codexTodos(event.Item.Items)}
 		case codex.ItemCommandExecution:
-			return codexActivityPatch(completed, "Shell", codex.ShellCommand(event.Item.Command))
+			return codexActivityPatch(completed, "Shell", codex.ShellCommand(event.Item.Command), event.Item.AggregatedOutput)
 		case codex.ItemMCPToolCall:
-			return codexActivityPatch(completed, codex.MCPLabel(event.Item), "")
+			return codexActivityPatch(completed, codex.MCPLabel(event.Item), "", "")
 		case codex.ItemFileChange:
-			return codexActivityPatch(completed, "Edit", codex.FileChangeSummary(event.Item.Changes))
+			return codexActivityPatch(completed, "Edit", codex.FileChangeSummary(event.Item.Changes), "")
 		case codex.ItemWebSearch:
-			return codexActivityPatch(completed, "Web Search", event.Item.Query)
+			return codexActivityPatch(completed, "Web Search", event.Item.Query, "")
 		}
 	}
 	return timeline.StatePatch{}
 }
 
-func codexActivityPatch(completed bool, name, input string) timeline.StatePatch {
+func codexActivityPatch(completed bool, name, input, output string) timeline.StatePatch {
 	if completed {
 		return timeline.StatePatch{ClearActivity: true}
 	}
-	activity := timeline.Activity{Name: name, Input: input}
+	activity := timeline.Activity{Name: name, Input: input, Output: output}
 	return timeline.StatePatch{CurrentActivity: &activity}
 }
 
@@ -596,13 +1194,13 @@ func (p *EventProcessor) applyCodexItem(phase string, item *codex.Item) {
 		// latest completion state, even though the inline render dedupes by id.
 		p.state.ApplyPatch(timeline.StatePatch{ReplaceTodos: true, Todos: codexTodos(item.Items)})
 	case codex.ItemCommandExecution:
-		p.updateCodexActiveTool(completed, item.ID, "Shell", codex.ShellCommand(item.Command))
+		p.updateCodexActiveTool(completed, item.ID, "Shell", codex.ShellCommand(item.Command), item.AggregatedOutput)
 	case codex.ItemMCPToolCall:
-		p.updateCodexActiveTool(completed, item.ID, codex.MCPLabel(item), "")
+		p.updateCodexActiveTool(completed, item.ID, codex.MCPLabel(item), "", "")
 	case codex.ItemFileChange:
-		p.updateCodexActiveTool(completed, item.ID, "Edit", codex.FileChangeSummary(item.Changes))
+		p.updateCodexActiveTool(completed, item.ID, "Edit", codex.FileChangeSummary(item.Changes), "")
 	case codex.ItemWebSearch:
-		p.updateCodexActiveTool(completed, item.ID, "Web Search", item.Query)
+		p.updateCodexActiveTool(completed, item.ID, "Web Search", item.Query, "")
 	}
 }
 
@@ -610,13 +1208,13 @@ func (p *EventProcessor) applyCodexItem(phase string, item *codex.Item) {
 // spinner while preserving older overlapping items. Codex can start multiple
 // command_execution items before completing them; when the visible item
 // completes, the spinner falls back to the next still-active item instead of
-// going blank.
-func (p *EventProcessor) updateCodexActiveTool(completed bool, id, name, input string) {
+// going blank. Each item.updated replaces the output streamed so far.
+func (p *EventProcessor) updateCodexActiveTool(completed bool, id, name, input, output string) {
 	if id == "" {
 		if completed {
 			p.state.ApplyPatch(timeline.StatePatch{ClearActivity: true})
 		} else {
-			activity := timeline.Activity{Name: name, Input: input}
+			activity := timeline.Activity{Name: name, Input: input, Output: output}
 			p.state.ApplyPatch(timeline.StatePatch{CurrentActivity: &activity})
 		}
 		return
@@ -636,8 +1234,8 @@ func (p *EventProcessor) updateCodexActiveTool(completed bool, id, name, input s
 	if _, exists := p.codexActiveTools[id]; !exists {
 		p.codexToolOrder = append(p.codexToolOrder, id)
 	}
-	p.codexActiveTools[id] = codexActiveTool{name: name, input: input}
-	activity := timeline.Activity{ID: id, Name: name, Input: input}
+	p.codexActiveTools[id] = codexActiveTool{name: name, input: input, output: output}
+	activity := timeline.Activity{ID: id, Name: name, Input: input, Output: output}
 	p.setActivity(activity)
 	p.state.ApplyPatch(timeline.StatePatch{CurrentActivity: &activity})
 }
@@ -646,7 +1244,7 @@ func (p *EventProcessor) restoreLatestCodexTool() {
 	for i := len(p.codexToolOrder) - 1; i >= 0; i-- {
 		id := p.codexToolOrder[i]
 		if tool, ok := p.codexActiveTools[id]; ok {
-			activity := timeline.Activity{ID: id, Name: tool.name, Input: tool.input}
+			activity := timeline.Activity{ID: id, Name: tool.name, Input: tool.input, Output: tool.output}
 			p.state.ApplyPatch(timeline.StatePatch{CurrentActivity: &activity})
 			return
 		}
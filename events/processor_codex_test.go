@@ -187,6 +187,32 @@ func TestProcessCodex_OverlappingCommandsRestoreSpinner(t *testing.T) {
 	}
 }
 
+func TestProcessCodex_CommandStreamsOutput(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+	item := codex.Item{ID: "c1", Type: codex.ItemCommandExecution, Command: "/usr/bin/zsh -lc 'go test ./...'", Status: "in_progress"}
+
+	p.Process(CodexEvent{Data: codex.Event{Type: codex.TypeItemStarted, Item: &item}})
+	if s.CurrentToolOutput != "" {
+		t.Errorf("CurrentToolOutput after item.started = %q, want empty", s.CurrentToolOutput)
+	}
+
+	// Each item.updated carries the output aggregated so far.
+	item.AggregatedOutput = "ok  \tpkg/a\n"
+	p.Process(CodexEvent{Data: codex.Event{Type: codex.TypeItemUpdated, Item: &item}})
+	item.AggregatedOutput += "ok  \tpkg/b\n"
+	p.Process(CodexEvent{Data: codex.Event{Type: codex.TypeItemUpdated, Item: &item}})
+	if s.CurrentToolOutput != item.AggregatedOutput {
+		t.Errorf("CurrentToolOutput = %q, want %q", s.CurrentToolOutput, item.AggregatedOutput)
+	}
+
+	item.Status = "completed"
+	p.Process(CodexEvent{Data: codex.Event{Type: codex.TypeItemCompleted, Item: &item}})
+	if s.CurrentToolOutput != "" {
+		t.Errorf("CurrentToolOutput after item.completed = %q, want empty", s.CurrentToolOutput)
+	}
+}
+
 func TestProcessCodex_FileChangeSnapshotRendersPatch(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "app.go")
 	if err := os.WriteFile(path, []byte("package main\n\nfunc old() {}\n"), 0o600); err != nil {
@@ -2,18 +2,27 @@ package events
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/pagetitle"
+	"github.com/johnnyfreeman/viewscreen/record"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/state"
 	streampkg "github.com/johnnyfreeman/viewscreen/stream"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/system"
+	"github.com/johnnyfreeman/viewscreen/timeline"
 	"github.com/johnnyfreeman/viewscreen/tools"
 	"github.com/johnnyfreeman/viewscreen/types"
 	"github.com/johnnyfreeman/viewscreen/user"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
 )
 
 func init() {
@@ -33,6 +42,40 @@ func TestNewEventProcessor(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_RecordsRenderTimings(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+
+	assistantEvent := AssistantEvent{
+		Data: assistant.Event{
+			Message: assistant.Message{
+				Content: []types.ContentBlock{{Type: "text", Text: "Hello"}},
+			},
+		},
+	}
+	p.Process(assistantEvent)
+	p.Process(assistantEvent)
+	p.Process(IgnoredEvent{Type: "rate_limit_event"})
+
+	stats := p.Timings().Stats()
+	if len(stats) != 1 {
+		t.Fatalf("expected timings for one event kind, got %+v", stats)
+	}
+	if stats[0].Kind != "assistant" || stats[0].Count != 2 {
+		t.Errorf("expected 2 assistant samples, got %+v", stats[0])
+	}
+}
+
+func TestEventProcessor_NilRenderTimings(t *testing.T) {
+	p := NewEventProcessor(&state.State{})
+
+	p.Process(AssistantEvent{})
+
+	if p.Timings() != nil {
+		t.Error("expected no timings when the state does not collect them")
+	}
+}
+
 func TestEventProcessor_ProcessSystemEvent(t *testing.T) {
 	s := state.NewState()
 	p := NewEventProcessor(s)
@@ -65,6 +108,24 @@ func TestEventProcessor_ProcessSystemEvent(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_ProcessSkipEvent(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	for skipped, want := range map[time.Duration]string{
+		4 * time.Minute:                   "⏩ skipped 4m idle",
+		4*time.Minute + 30*time.Second:    "⏩ skipped 4m30s idle",
+		time.Hour + 1500*time.Millisecond: "⏩ skipped 1h2s idle",
+		45 * time.Second:                  "⏩ skipped 45s idle",
+	} {
+		res := p.Process(SkipEvent{Data: record.Skip{SkippedMS: skipped.Milliseconds()}})
+		if got := ansi.Strip(res.Rendered); !strings.Contains(got, want) {
+			t.Errorf("skip of %v rendered %q, want %q", skipped, got, want)
+		}
+		if len(res.Batch.Entries) != 1 || res.Batch.Entries[0].Kind != "skip" {
+			t.Errorf("entries = %+v, want one skip entry", res.Batch.Entries)
+		}
+	}
+}
+
 func TestEventProcessor_ProcessSubAgentSystemEvent(t *testing.T) {
 	s := state.NewState()
 	// Pre-populate state to verify it's not overwritten
@@ -249,9 +310,10 @@ func TestEventProcessor_ProcessUserEvent_MatchesTool(t *testing.T) {
 			Message: assistant.Message{
 				Content: []types.ContentBlock{
 					{
-						Type: "tool_use",
-						ID:   "tool-123",
-						Name: "Bash",
+						Type:  "tool_use",
+						ID:    "tool-123",
+						Name:  "Bash",
+						Input: json.RawMessage(`{"command":"ls"}`),
 					},
 				},
 			},
@@ -287,6 +349,550 @@ func TestEventProcessor_ProcessUserEvent_MatchesTool(t *testing.T) {
 	if result.Rendered == "" {
 		t.Error("ProcessResult should have rendered output for user event")
 	}
+
+	// The entry should name the tool whose result it shows
+	if len(result.Batch.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(result.Batch.Entries))
+	}
+	if entry := result.Batch.Entries[0]; entry.Title != "Bash" || entry.Arg != "ls" {
+		t.Errorf("entry Title/Arg = %q/%q, want Bash/ls", entry.Title, entry.Arg)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_MarksErrors(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Bash", Input: json.RawMessage(`{"command":"false"}`)}},
+	}}})
+
+	result := p.Process(UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", IsError: true, RawContent: json.RawMessage(`"exit status 1"`)}},
+	}}})
+	if len(result.Batch.Entries) != 1 || result.Batch.Entries[0].Status != timeline.StatusError {
+		t.Errorf("entries = %+v, want one marked as an error", result.Batch.Entries)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_RetitlesWebFetch(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<title>Release Notes</title>")
+	}))
+	defer srv.Close()
+	defer close(release)
+	pagetitle.SetDefault(pagetitle.New(pagetitle.WithClient(srv.Client())))
+	defer pagetitle.SetDefault(nil)
+
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "WebFetch", Input: json.RawMessage(`{"url":"` + srv.URL + `"}`)}},
+	}}})
+	result := p.Process(UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", RawContent: json.RawMessage(`"page text"`)}},
+	}}})
+	if len(result.Batch.Entries) != 1 || strings.Contains(result.Batch.Entries[0].Body, "Release Notes") {
+		t.Fatalf("entries = %+v, want one rendered without waiting for the title", result.Batch.Entries)
+	}
+	if result.Retitle == nil || len(result.TitleLookups) != 1 {
+		t.Fatalf("Retitle set = %v, %d lookups, want the lookup to wait for", result.Retitle != nil, len(result.TitleLookups))
+	}
+
+	release <- struct{}{}
+	<-result.TitleLookups[0]
+	retitled := result.Retitle()
+	if !strings.Contains(retitled.Body, "Release Notes") {
+		t.Errorf("Retitle().Body = %q, want the title in the header", retitled.Body)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_CountsStats(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{
+			{Type: "tool_use", ID: "t1", Name: "Edit", Input: json.RawMessage(`{"file_path":"/tmp/a.go"}`)},
+			{Type: "tool_use", ID: "t2", Name: "Bash", Input: json.RawMessage(`{"command":"false"}`)},
+		},
+	}}})
+	p.Process(UserEvent{Data: user.Event{
+		Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", RawContent: json.RawMessage(`"ok"`)}},
+		},
+		ToolUseResult: json.RawMessage(`{"filePath":"/tmp/a.go","oldString":"a","newString":"b"}`),
+	}})
+	p.Process(UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t2", IsError: true, RawContent: json.RawMessage(`"exit status 1"`)}},
+	}}})
+
+	stats := p.renderers.Stats
+	if stats.FilesChanged() != 1 || stats.ToolErrors() != 1 {
+		t.Errorf("FilesChanged() = %d, ToolErrors() = %d, want 1 and 1", stats.FilesChanged(), stats.ToolErrors())
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_CountsEmptySearches(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Glob", Input: json.RawMessage(`{"pattern":"*.rs"}`)}},
+	}}})
+	p.Process(UserEvent{Data: user.Event{
+		Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", RawContent: json.RawMessage(`"No files found"`)}},
+		},
+		ToolUseResult: json.RawMessage(`{"filenames":[],"numFiles":0}`),
+	}})
+
+	if got := p.renderers.Stats.EmptySearches(); got != 1 {
+		t.Errorf("EmptySearches() = %d, want 1", got)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_FullResults(t *testing.T) {
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	output, _ := json.Marshal(strings.Join(lines, "\n"))
+	process := func(p *EventProcessor) timeline.Entry {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Read", Input: json.RawMessage(`{"file_path":"/tmp/a.txt"}`)}},
+		}}})
+		res := p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", RawContent: output}},
+		}}})
+		if len(res.Batch.Entries) != 1 {
+			t.Fatalf("expected one entry, got %d", len(res.Batch.Entries))
+		}
+		return res.Batch.Entries[0]
+	}
+
+	if entry := process(NewEventProcessor(state.NewState())); entry.Full != "" {
+		t.Errorf("expected no full rendering unless enabled, got %q", entry.Full)
+	}
+
+	p := NewEventProcessor(state.NewState())
+	p.Renderers().EnableFullResults()
+	entry := process(p)
+	if strings.Contains(entry.Body, "line 30") {
+		t.Errorf("expected the body to keep the default summary, got %q", entry.Body)
+	}
+	if !strings.Contains(entry.Full, "Read") || !strings.Contains(entry.Full, "line 30") {
+		t.Errorf("expected the full rendering to show the header and every line, got %q", entry.Full)
+	}
+	if !entry.Foldable() {
+		t.Error("expected the entry to be foldable")
+	}
+}
+
+func TestEventProcessor_Process_VerboseThinking(t *testing.T) {
+	thinking := []types.ContentBlock{{Type: "thinking", Thinking: "weigh the options"}, {Type: "text", Text: "Done."}}
+	process := func(p *EventProcessor) timeline.Entry {
+		res := p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{Content: thinking}}})
+		if len(res.Batch.Entries) != 1 {
+			t.Fatalf("expected one entry, got %d", len(res.Batch.Entries))
+		}
+		return res.Batch.Entries[0]
+	}
+
+	if entry := process(NewEventProcessor(state.NewState())); entry.Verbose != nil {
+		t.Error("expected no verbose rendering unless enabled")
+	}
+
+	p := NewEventProcessor(state.NewState())
+	p.Renderers().EnableFullResults()
+	entry := process(p)
+	if strings.Contains(entry.Body, "weigh the options") {
+		t.Errorf("expected the body to keep the thinking summary, got %q", entry.Body)
+	}
+	if entry.Verbose == nil || !strings.Contains(entry.Verbose(), "weigh the options") || !strings.Contains(entry.Verbose(), "Done.") {
+		t.Fatal("expected the verbose rendering to show the whole message with its thinking")
+	}
+	if !entry.Foldable() {
+		t.Error("expected the entry to be foldable")
+	}
+
+	// Streamed thinking gets the verbose rendering on its stream entry.
+	p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{
+		Type:         "content_block_start",
+		ContentBlock: json.RawMessage(`{"type":"thinking","thinking":""}`),
+	}}})
+	p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{
+		Type:  "content_block_delta",
+		Delta: json.RawMessage(`{"type":"thinking_delta","thinking":"streamed thought"}`),
+	}}})
+	res := p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{Type: "content_block_stop"}}})
+	if len(res.Batch.Entries) != 1 || res.Batch.Entries[0].Verbose == nil {
+		t.Fatalf("expected a verbose stream entry, got %+v", res.Batch.Entries)
+	}
+	if got := res.Batch.Entries[0].Verbose(); !strings.Contains(got, "streamed thought") {
+		t.Errorf("expected the streamed thinking in full, got %q", got)
+	}
+}
+
+func TestEventProcessor_ProcessAssistant_MergesTextBlocks(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	text := func(id, s string) ProcessResult {
+		return p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			ID:      id,
+			Content: []types.ContentBlock{{Type: "text", Text: s}},
+		}}})
+	}
+
+	if res := text("m1", "Steps:\n\n1. alpha"); res.Batch.Entries[0].Replaces {
+		t.Error("expected the first block to be a block of its own")
+	}
+	res := text("m1", "2. beta")
+	if len(res.Batch.Entries) != 1 || !res.Batch.Entries[0].Replaces {
+		t.Fatalf("expected one entry replacing the first block, got %+v", res.Batch.Entries)
+	}
+	if body := res.Batch.Entries[0].Body; !strings.Contains(body, "alpha") || !strings.Contains(body, "beta") {
+		t.Errorf("expected both blocks in the merged entry, got %q", body)
+	}
+	if strings.Contains(res.Rendered, "alpha") {
+		t.Errorf("expected only the new block in the streamed output, got %q", res.Rendered)
+	}
+
+	if res := text("m2", "Other message."); res.Batch.Entries[0].Replaces {
+		t.Error("expected the text of another message to start a new block")
+	}
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		ID:      "m2",
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Bash", Input: json.RawMessage(`{"command":"ls"}`)}},
+	}}})
+	p.Process(UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "t1", RawContent: json.RawMessage(`"out"`)}},
+	}}})
+	if res := text("m2", "After the tool."); res.Batch.Entries[0].Replaces {
+		t.Error("expected text after other output to start a new block")
+	}
+}
+
+func TestEventProcessor_ProcessStream_MergesTextBlocks(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	block := func(index int, s string) ProcessResult {
+		p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{
+			Type: "content_block_start", Index: index,
+			ContentBlock: json.RawMessage(`{"type":"text","text":""}`),
+		}}})
+		p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{
+			Type: "content_block_delta", Index: index,
+			Delta: json.RawMessage(fmt.Sprintf(`{"type":"text_delta","text":%q}`, s)),
+		}}})
+		return p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{Type: "content_block_stop", Index: index}}})
+	}
+
+	p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{Type: "message_start"}}})
+	if res := block(0, "- alpha"); res.Batch.Entries[0].Replaces {
+		t.Error("expected the first block to be a block of its own")
+	}
+	res := block(1, "- beta")
+	if len(res.Batch.Entries) != 1 || !res.Batch.Entries[0].Replaces {
+		t.Fatalf("expected one entry replacing the first block, got %+v", res.Batch.Entries)
+	}
+	if body := res.Batch.Entries[0].Body; !strings.Contains(body, "alpha") || !strings.Contains(body, "beta") {
+		t.Errorf("expected both blocks in the merged entry, got %q", body)
+	}
+
+	p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{Type: "message_start"}}})
+	if res := block(0, "Next message."); res.Batch.Entries[0].Replaces {
+		t.Error("expected a new message to start a new block")
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_RetryNote(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	call := func(id, input string, isError bool) ProcessResult {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: id, Name: "Grep", Input: json.RawMessage(input)}},
+		}}})
+		return p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, IsError: isError, RawContent: json.RawMessage(`"result"`)}},
+		}}})
+	}
+
+	if res := call("t1", `{"pattern":"foo("}`, true); strings.Contains(res.Rendered, "retry") {
+		t.Errorf("expected no retry note on a first call, got %q", res.Rendered)
+	}
+	res := call("t2", `{"pattern":"foo\\(","-i":true}`, true)
+	if !strings.Contains(res.Rendered, "↻ retry #2: changed pattern, added -i flag") {
+		t.Errorf("expected a retry note, got %q", res.Rendered)
+	}
+	res = call("t3", `{"pattern":"foo\\(","-i":true}`, false)
+	if !strings.Contains(res.Rendered, "↻ retry #3: same input") {
+		t.Errorf("expected the third attempt, got %q", res.Rendered)
+	}
+	if res := call("t4", `{"pattern":"bar"}`, false); strings.Contains(res.Rendered, "retry") {
+		t.Errorf("expected no retry note after a success, got %q", res.Rendered)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_ParallelCallsAreNotRetries(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{
+			{Type: "tool_use", ID: "t1", Name: "Bash", Input: json.RawMessage(`{"command":"false"}`)},
+			{Type: "tool_use", ID: "t2", Name: "Bash", Input: json.RawMessage(`{"command":"true"}`)},
+		},
+	}}})
+	result := func(id string, isError bool) ProcessResult {
+		return p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, IsError: isError, RawContent: json.RawMessage(`"out"`)}},
+		}}})
+	}
+	result("t1", true)
+	if res := result("t2", false); strings.Contains(res.Rendered, "retry") {
+		t.Errorf("expected no retry note for a call made alongside the failure, got %q", res.Rendered)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_LaneRules(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+	result := func(id string) string {
+		return p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, RawContent: json.RawMessage(`"out"`)}},
+		}}}).Rendered
+	}
+
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "t1", Name: "Bash", Input: json.RawMessage(`{"command":"ls"}`)}},
+	}}})
+	if got := result("t1"); strings.HasPrefix(got, "1 ") {
+		t.Errorf("expected no lane rule for a call running alone, got %q", got)
+	}
+
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{
+			{Type: "tool_use", ID: "t2", Name: "Bash", Input: json.RawMessage(`{"command":"make"}`)},
+			{Type: "tool_use", ID: "t3", Name: "Bash", Input: json.RawMessage(`{"command":"make test"}`)},
+		},
+	}}})
+	if active := s.Lanes.Active(); len(active) != 2 || active[1].Input != "make test" {
+		t.Errorf("expected the state to share both lanes, got %+v", active)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(result("t3"), "\n"), "\n") {
+		if !strings.HasPrefix(line, "2 ") {
+			t.Errorf("expected every line of the second lane's result to start with its rule, got %q", line)
+		}
+	}
+	if got := result("t2"); !strings.HasPrefix(got, "1 ") {
+		t.Errorf("expected the first lane's rule, got %q", got)
+	}
+	if s.Lanes.Active() != nil {
+		t.Error("expected no lanes once every call finished")
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_WatchAlert(t *testing.T) {
+	w, err := watchlist.New([]string{".env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchlist.SetDefault(w)
+	defer watchlist.SetDefault(nil)
+
+	p := NewEventProcessor(state.NewState())
+	call := func(id, name, input string) ProcessResult {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: id, Name: name, Input: json.RawMessage(input)}},
+		}}})
+		return p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, RawContent: json.RawMessage(`"ok"`)}},
+		}}})
+	}
+
+	res := call("t1", "Write", `{"file_path":"/app/.env","content":"X=1"}`)
+	if !strings.Contains(res.Rendered, "⚠ watched path: write /app/.env (matches .env)") {
+		t.Errorf("expected a watch alert, got %q", res.Rendered)
+	}
+	if res := call("t2", "Read", `{"file_path":"/app/main.go"}`); strings.Contains(res.Rendered, "watched") {
+		t.Errorf("expected no alert for an unwatched path, got %q", res.Rendered)
+	}
+	if got := p.Renderers().Stats.WatchedPathCalls(); got != 1 {
+		t.Errorf("WatchedPathCalls() = %d, want 1", got)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_ReadOnlyAlert(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	call := func(id, name, input string, isError bool) ProcessResult {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: id, Name: name, Input: json.RawMessage(input)}},
+		}}})
+		return p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, IsError: isError, RawContent: json.RawMessage(`"ok"`)}},
+		}}})
+	}
+
+	if res := call("t1", "Bash", `{"command":"make"}`, false); strings.Contains(res.Rendered, "read-only") {
+		t.Errorf("expected no alert outside a read-only mode, got %q", res.Rendered)
+	}
+
+	p.Process(SystemEvent{Data: system.Event{Subtype: "init", Model: "claude", PermissionMode: "plan"}})
+	if res := call("t2", "Read", `{"file_path":"/app/main.go"}`, false); strings.Contains(res.Rendered, "read-only") {
+		t.Errorf("expected no alert for a read, got %q", res.Rendered)
+	}
+	res := call("t3", "Edit", `{"file_path":"/app/main.go","old_string":"a","new_string":"b"}`, false)
+	if !strings.Contains(res.Rendered, "⚠ read-only violation: Edit in plan mode") {
+		t.Errorf("expected a read-only alert, got %q", res.Rendered)
+	}
+
+	// Plan mode ends only when ExitPlanMode succeeds
+	call("t4", "ExitPlanMode", `{"plan":"edit main.go"}`, true)
+	if res := call("t5", "Write", `{"file_path":"/app/x.go","content":"x"}`, false); !strings.Contains(res.Rendered, "read-only violation") {
+		t.Errorf("expected an alert after a rejected ExitPlanMode, got %q", res.Rendered)
+	}
+	call("t6", "ExitPlanMode", `{"plan":"edit main.go"}`, false)
+	if res := call("t7", "Write", `{"file_path":"/app/x.go","content":"x"}`, false); strings.Contains(res.Rendered, "read-only") {
+		t.Errorf("expected no alert once plan mode was left, got %q", res.Rendered)
+	}
+	if got := p.Renderers().Stats.ReadOnlyViolations(); got != 2 {
+		t.Errorf("ReadOnlyViolations() = %d, want 2", got)
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_StitchesReadChunks(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	read := func(id string, start, n int) ProcessResult {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: id, Name: "Read", Input: json.RawMessage(`{"file_path":"/src/foo.go"}`)}},
+		}}})
+		return p.Process(UserEvent{Data: user.Event{
+			Message: user.Message{
+				Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, RawContent: json.RawMessage(`"lines"`)}},
+			},
+			ToolUseResult: json.RawMessage(fmt.Sprintf(
+				`{"type":"text","file":{"filePath":"/src/foo.go","content":"x","numLines":%d,"startLine":%d,"totalLines":400}}`, n, start)),
+		}})
+	}
+
+	if res := read("t1", 1, 200); res.Batch.Entries[0].Replaces {
+		t.Error("expected the first chunk to be a block of its own")
+	}
+	read("t2", 201, 100)
+	res := read("t3", 301, 100)
+	if len(res.Batch.Entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(res.Batch.Entries))
+	}
+	entry := res.Batch.Entries[0]
+	if !entry.Replaces || entry.Title != "Read" || entry.Arg != "/src/foo.go" {
+		t.Errorf("expected a Read entry replacing the earlier chunks, got %+v", entry)
+	}
+	if !strings.Contains(entry.Body, "Read lines 1–400 of foo.go (3 chunks)") {
+		t.Errorf("expected the merged summary, got %q", entry.Body)
+	}
+	if strings.Count(res.Rendered, "\n") != 1 || !strings.Contains(res.Rendered, "(3 chunks)") {
+		t.Errorf("expected only the updated summary in the streamed output, got %q", res.Rendered)
+	}
+
+	if res := read("t4", 50, 10); res.Batch.Entries[0].Replaces {
+		t.Error("expected a Read that does not continue the last chunk to start a new block")
+	}
+
+	p.SetLowMemory(true)
+	read("t5", 60, 100)
+	if res := read("t6", 160, 100); res.Batch.Entries[0].Replaces || p.reads != nil {
+		t.Error("expected low memory mode to show each chunk on its own")
+	}
+}
+
+func TestEventProcessor_ProcessUserEvent_ShowsElapsed(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewEventProcessor(state.NewState())
+	p.renderers.ToolTimes = tools.NewToolTimer(func() time.Time { return now })
+
+	runTool := func(id string, elapsed time.Duration) string {
+		p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+			Content: []types.ContentBlock{{Type: "tool_use", ID: id, Name: "Bash"}},
+		}}})
+		now = now.Add(elapsed)
+		res := p.Process(UserEvent{Data: user.Event{Message: user.Message{
+			Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, Text: "ok"}},
+		}}})
+		return res.Rendered
+	}
+
+	if got := runTool("slow", 2400*time.Millisecond); !strings.Contains(got, "(2.4s)") {
+		t.Errorf("expected elapsed time after the result, got %q", got)
+	}
+	if got := runTool("fast", 10*time.Millisecond); strings.Contains(got, "(0.0s)") {
+		t.Errorf("expected no elapsed time for a fast tool, got %q", got)
+	}
+
+	totals := p.renderers.ToolTimes.Totals()
+	if len(totals) != 1 || totals[0].Count != 2 {
+		t.Errorf("Totals() = %+v, want 2 Bash calls", totals)
+	}
+}
+
+func TestEventProcessor_ProcessStreamEvent_StartsToolTimer(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewEventProcessor(state.NewState())
+	p.renderers.ToolTimes = tools.NewToolTimer(func() time.Time { return now })
+
+	p.Process(StreamEvent{Data: streampkg.Event{Event: streampkg.EventData{
+		Type:         "content_block_start",
+		ContentBlock: json.RawMessage(`{"type":"tool_use","id":"toolu_1","name":"Bash"}`),
+	}}})
+	now = now.Add(time.Second)
+	// The complete assistant message must not restart the timer
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{{Type: "tool_use", ID: "toolu_1", Name: "Bash"}},
+	}}})
+	now = now.Add(time.Second)
+
+	if elapsed, ok := p.renderers.ToolTimes.Finish("toolu_1"); !ok || elapsed != 2*time.Second {
+		t.Errorf("Finish() = %v, %v; want 2s from the stream start", elapsed, ok)
+	}
+}
+
+func TestEventProcessor_Process_DropsBlankRendering(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+
+	res := p.Process(AssistantEvent{Data: assistant.Event{
+		BaseEvent: types.BaseEvent{UUID: "u-1"},
+		Message:   assistant.Message{Content: []types.ContentBlock{}},
+	}})
+	if res.Rendered != "" || len(res.Batch.Entries) != 0 {
+		t.Errorf("expected an empty assistant message to render nothing, got %q with %d entries", res.Rendered, len(res.Batch.Entries))
+	}
+}
+
+func TestDropBlank(t *testing.T) {
+	res := ProcessResult{
+		Rendered: "\x1b[2m \x1b[0m\n",
+		Batch: timeline.Batch{Entries: []timeline.Entry{
+			{Kind: "assistant", Body: "\n\n"},
+			{Kind: "assistant", Body: "kept\n"},
+		}},
+	}
+	dropBlank(&res)
+	if res.Rendered != "" {
+		t.Errorf("Rendered = %q, want empty", res.Rendered)
+	}
+	if len(res.Batch.Entries) != 1 || res.Batch.Entries[0].Body != "kept\n" {
+		t.Errorf("Entries = %+v, want only the non-blank entry", res.Batch.Entries)
+	}
+}
+
+func TestWithElapsed(t *testing.T) {
+	tests := []struct {
+		name     string
+		rendered string
+		want     string
+	}{
+		{"empty", "", "P(1.5s)\n"},
+		{"one line", "P3 lines\n", "P3 lines (1.5s)\n"},
+		{"several lines", "Pa\nCb\n", "Pa\nCb\nC(1.5s)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withElapsed(tt.rendered, 1500*time.Millisecond, "P", "C"); got != tt.want {
+				t.Errorf("withElapsed() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestEventProcessor_ProcessStreamEvent_SetsCurrentToolName(t *testing.T) {
@@ -319,6 +925,128 @@ func TestEventProcessor_ProcessStreamEvent_SetsCurrentToolName(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_ProcessSubAgentAssistantEvent_NestedUnderTask(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+	p.Process(AssistantEvent{
+		Data: assistant.Event{
+			Message: assistant.Message{
+				Content: []types.ContentBlock{
+					{Type: "tool_use", ID: "task-1", Name: "Task", Input: json.RawMessage(`{"description":"Explore repo"}`)},
+				},
+			},
+		},
+	})
+
+	parentID := "task-1"
+	result := p.Process(AssistantEvent{
+		Data: assistant.Event{
+			Message: assistant.Message{
+				Content: []types.ContentBlock{
+					{Type: "text", Text: "Looking around"},
+				},
+			},
+			BaseEvent: types.BaseEvent{ParentToolUseID: &parentID},
+		},
+	})
+
+	header := strings.Index(result.Rendered, "Task")
+	text := strings.Index(result.Rendered, "Looking around")
+	if header < 0 || text < 0 || header > text {
+		t.Fatalf("expected Task header before sub-agent text, got %q", result.Rendered)
+	}
+	if !strings.Contains(result.Rendered, style.NestedPrefix+"Looking around") {
+		t.Errorf("expected sub-agent text behind the nested prefix, got %q", result.Rendered)
+	}
+	if !result.HasPendingTools {
+		t.Error("Task should stay pending until its result arrives")
+	}
+
+	// The header is only rendered once.
+	result = p.Process(AssistantEvent{
+		Data: assistant.Event{
+			Message: assistant.Message{
+				Content: []types.ContentBlock{
+					{Type: "text", Text: "Still looking"},
+				},
+			},
+			BaseEvent: types.BaseEvent{ParentToolUseID: &parentID},
+		},
+	})
+	if strings.Contains(result.Rendered, "Task") {
+		t.Errorf("expected Task header only once, got %q", result.Rendered)
+	}
+}
+
+func TestEventProcessor_SubAgentSummary(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		Content: []types.ContentBlock{
+			{Type: "tool_use", ID: "task-1", Name: "Task", Input: json.RawMessage(`{"description":"Explore repo"}`)},
+		},
+	}}})
+
+	parentID := "task-1"
+	subAgent := func(messageID string, outputTokens int, block types.ContentBlock) ProcessResult {
+		return p.Process(AssistantEvent{Data: assistant.Event{
+			Message: assistant.Message{
+				ID:      messageID,
+				Content: []types.ContentBlock{block},
+				Usage:   &types.Usage{InputTokens: 100, OutputTokens: outputTokens},
+			},
+			BaseEvent: types.BaseEvent{ParentToolUseID: &parentID},
+		}})
+	}
+	res := subAgent("msg-1", 10, types.ContentBlock{Type: "text", Text: "Looking around"})
+	if len(res.Batch.Entries) == 0 || res.Batch.Entries[0].TaskID != "task-1" {
+		t.Errorf("expected the sub-agent's entry tagged with its Task, got %+v", res.Batch.Entries)
+	}
+	// The blocks of one message count once
+	subAgent("msg-1", 10, types.ContentBlock{Type: "tool_use", ID: "r1", Name: "Read", Input: json.RawMessage(`{"file_path":"a.go"}`)})
+	subAgent("msg-2", 20, types.ContentBlock{Type: "tool_use", ID: "r2", Name: "Read", Input: json.RawMessage(`{"file_path":"b.go"}`)})
+
+	res = p.Process(UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: "task-1", RawContent: json.RawMessage(`"done"`)}},
+	}}})
+	if len(res.Batch.Entries) == 0 {
+		t.Fatal("expected the Task's result")
+	}
+	entry := res.Batch.Entries[0]
+	want := timeline.SubAgent{TaskID: "task-1", Turns: 2, InputTokens: 200, OutputTokens: 30, Tools: map[string]int{"Read": 2}}
+	if entry.TaskID != "" || entry.SubAgent == nil || entry.SubAgent.Turns != want.Turns ||
+		entry.SubAgent.InputTokens != want.InputTokens || entry.SubAgent.OutputTokens != want.OutputTokens ||
+		entry.SubAgent.Tools["Read"] != 2 || entry.SubAgent.TaskID != want.TaskID {
+		t.Errorf("expected the main agent's result to summarize the sub-agent as %+v, got %+v", want, entry)
+	}
+}
+
+func TestEventProcessor_ProcessSubAgentStreamEvent_Skipped(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+
+	contentBlock, _ := json.Marshal(types.ContentBlock{Type: "text"})
+	p.Process(StreamEvent{
+		Data: streampkg.Event{
+			Event: streampkg.EventData{Type: "content_block_start", ContentBlock: contentBlock},
+		},
+	})
+
+	parentID := "task-1"
+	result := p.Process(StreamEvent{
+		Data: streampkg.Event{
+			BaseEvent: types.BaseEvent{ParentToolUseID: &parentID},
+			Event:     streampkg.EventData{Type: "content_block_stop"},
+		},
+	})
+
+	if result.Rendered != "" {
+		t.Errorf("expected sub-agent stream event to render nothing, got %q", result.Rendered)
+	}
+	if !p.Renderers().Stream.InTextBlock() {
+		t.Error("sub-agent stream event should not change the main stream's block state")
+	}
+}
+
 func TestEventProcessor_ProcessResultEvent(t *testing.T) {
 	s := state.NewState()
 	p := NewEventProcessor(s)
@@ -351,6 +1079,26 @@ func TestEventProcessor_ProcessResultEvent(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_MarksErrors(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+
+	res := p.Process(AssistantEvent{Data: assistant.Event{
+		Error:   "authentication_failed",
+		Message: assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: "Invalid API key"}}},
+	}})
+	if len(res.Batch.Entries) != 1 || res.Batch.Entries[0].Status != timeline.StatusError {
+		t.Errorf("expected an assistant error entry, got %+v", res.Batch.Entries)
+	}
+
+	res = p.Process(ResultEvent{Data: result.Event{
+		Subtype:           "success",
+		PermissionDenials: []result.PermissionDenial{{ToolName: "Bash", ToolUseID: "t1"}},
+	}})
+	if len(res.Batch.Entries) != 1 || res.Batch.Entries[0].Status != timeline.StatusError {
+		t.Errorf("expected a result with permission denials marked as an error, got %+v", res.Batch.Entries)
+	}
+}
+
 func TestEventProcessor_ProcessResultEvent_FlushesOrphanedTools(t *testing.T) {
 	s := state.NewState()
 	p := NewEventProcessor(s)
@@ -553,6 +1301,38 @@ func TestEventProcessor_AccumulatesTokenUsage(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_ContextUsage(t *testing.T) {
+	s := state.NewState()
+	p := NewEventProcessor(s)
+	usage := func(parent *string, input int) AssistantEvent {
+		return AssistantEvent{Data: assistant.Event{
+			BaseEvent: types.BaseEvent{ParentToolUseID: parent},
+			Message: assistant.Message{
+				Content: []types.ContentBlock{{Type: "text", Text: "hi"}},
+				Usage:   &types.Usage{InputTokens: input, CacheReadInputTokens: 40_000, CacheCreationInputTokens: 5_000, OutputTokens: 500},
+			},
+		}}
+	}
+	p.Process(usage(nil, 100))
+	parent := "toolu_task"
+	p.Process(usage(&parent, 90_000))
+
+	if s.ContextTokens != 45_600 {
+		t.Errorf("ContextTokens = %d, want the main agent's 45600", s.ContextTokens)
+	}
+	if got := p.renderers.Stats.ContextTokens(); got != 45_600 {
+		t.Errorf("Stats.ContextTokens() = %d, want 45600", got)
+	}
+
+	p.Process(ResultEvent{Data: result.Event{ModelUsage: map[string]result.ModelUsage{
+		"claude-haiku":  {ContextWindow: 200_000},
+		"claude-sonnet": {ContextWindow: 1_000_000},
+	}}})
+	if s.ContextWindow != 1_000_000 {
+		t.Errorf("ContextWindow = %d, want the largest reported 1000000", s.ContextWindow)
+	}
+}
+
 func TestEventProcessor_NilUsageDoesNotPanic(t *testing.T) {
 	s := state.NewState()
 	p := NewEventProcessor(s)
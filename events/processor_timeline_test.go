@@ -61,3 +61,38 @@ func TestEventProcessorCodexOverlappingActivities(t *testing.T) {
 		t.Fatalf("CurrentTool after completion = %q, want Shell", s.CurrentTool)
 	}
 }
+
+func TestEventProcessorStampsEntryIDs(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+
+	result := p.Process(AssistantEvent{Data: assistant.Event{
+		BaseEvent: types.BaseEvent{UUID: "a1b2"},
+		Message:   assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: "hello"}}},
+	}})
+
+	if len(result.Batch.Entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(result.Batch.Entries))
+	}
+	if got := result.Batch.Entries[0].ID; got != "a1b2" {
+		t.Fatalf("entry ID = %q, want a1b2", got)
+	}
+}
+
+func TestEventProcessorStampsEntryTurns(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	assistantText := func(text string) AssistantEvent {
+		return AssistantEvent{Data: assistant.Event{
+			Message: assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: text}}},
+		}}
+	}
+
+	for i, text := range []string{"first", "second"} {
+		result := p.Process(assistantText(text))
+		if len(result.Batch.Entries) != 1 {
+			t.Fatalf("entries = %d, want 1", len(result.Batch.Entries))
+		}
+		if got := result.Batch.Entries[0].Turn; got != i+1 {
+			t.Errorf("turn of %q = %d, want %d", text, got, i+1)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// readOnlyModes are the permission modes in which the agent may not change
+// anything: Claude Code's plan mode and the read-only sandbox.
+var readOnlyModes = map[string]bool{
+	"plan":      true,
+	"read-only": true,
+	"readOnly":  true,
+}
+
+// mutatingTools are the tools that write files or run commands.
+var mutatingTools = map[string]bool{
+	"Write":        true,
+	"Edit":         true,
+	"MultiEdit":    true,
+	"NotebookEdit": true,
+	"Bash":         true,
+}
+
+// readOnlyAlert counts the call block when it writes or runs something
+// although the session's permission mode is read-only, which the agent
+// should never do, and returns the alert shown beneath its header, behind
+// cont, e.g. "⚠ read-only violation: Edit in plan mode". A successful
+// ExitPlanMode call, failed being false, leaves plan mode, after which
+// changes are expected.
+func (p *EventProcessor) readOnlyAlert(block types.ContentBlock, failed bool, cont string) string {
+	if block.Name == "ExitPlanMode" && !failed {
+		p.permissionMode = ""
+	}
+	mode := p.permissionMode
+	if !readOnlyModes[mode] || !mutatingTools[block.Name] {
+		return ""
+	}
+	if p.renderers.Stats != nil {
+		p.renderers.Stats.ReadOnlyViolated()
+	}
+	return cont + style.ErrorBoldText("⚠ read-only violation: "+block.Name+" in "+mode+" mode") + "\n"
+}
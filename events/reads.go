@@ -0,0 +1,89 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/user"
+)
+
+// readResult is the part of a Read tool result that locates the lines read.
+type readResult struct {
+	File struct {
+		FilePath   string `json:"filePath"`
+		NumLines   int    `json:"numLines"`
+		StartLine  int    `json:"startLine"`
+		TotalLines int    `json:"totalLines"`
+	} `json:"file"`
+}
+
+// readChunks follows a file read in consecutive offset/limit chunks, so the
+// chunks can be shown as one block.
+type readChunks struct {
+	path    string
+	first   int // first line read
+	next    int // line after the last line read
+	chunks  int
+	content []string // tool result text of each chunk
+}
+
+// observeRead folds a top-level tool result into the Read chunks being
+// followed and reports whether it continued them: a Read of the same file
+// starting where the last chunk ended. Any other result ends the run.
+func (p *EventProcessor) observeRead(toolName string, event user.Event) bool {
+	var res readResult
+	if toolName != "Read" || hasToolError(event) || len(event.ToolUseResult) == 0 ||
+		json.Unmarshal(event.ToolUseResult, &res) != nil || res.File.FilePath == "" {
+		p.reads = nil
+		return false
+	}
+	f := res.File
+	text := toolResultText(event)
+	if r := p.reads; r != nil && r.path == f.FilePath && f.StartLine == r.next {
+		r.next += f.NumLines
+		r.chunks++
+		r.content = append(r.content, text)
+		return true
+	}
+	p.reads = &readChunks{
+		path:    f.FilePath,
+		first:   f.StartLine,
+		next:    f.StartLine + f.NumLines,
+		chunks:  1,
+		content: []string{text},
+	}
+	return false
+}
+
+// toolResultText returns the text of the tool results in a user event.
+func toolResultText(event user.Event) string {
+	var parts []string
+	for _, c := range event.Message.Content {
+		if c.Type == "tool_result" {
+			parts = append(parts, c.Content())
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// summary describes the merged chunks, e.g.
+// "Read lines 1–400 of foo.go (3 chunks)".
+func (r *readChunks) summary() string {
+	return fmt.Sprintf("Read lines %d–%d of %s (%d chunks)", r.first, r.next-1, filepath.Base(r.path), r.chunks)
+}
+
+// render renders the merged chunks as a tool result: the summary line,
+// after the merged content (as ur truncates it) when expand is set.
+func (r *readChunks) render(ur *user.Renderer, expand bool) string {
+	summary := style.MutedText(r.summary())
+	if !expand {
+		return style.OutputPrefix + summary + "\n"
+	}
+	merged := user.Event{Message: user.Message{Content: []user.ToolResultContent{
+		{Type: "tool_result", Text: strings.Join(r.content, "\n")},
+	}}}
+	return ur.RenderToString(merged) + style.OutputContinue + summary + "\n"
+}
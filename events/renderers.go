@@ -3,6 +3,7 @@ package events
 import (
 	"github.com/johnnyfreeman/viewscreen/assistant"
 	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/config"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/stream"
 	"github.com/johnnyfreeman/viewscreen/system"
@@ -20,26 +21,89 @@ type RendererSet struct {
 	Result       *result.Renderer
 	Stream       *stream.Renderer
 	PendingTools *tools.ToolUseTracker
-	Codex        *codex.Renderer
+	ToolTimes    *tools.ToolTimer
+	Stats        *result.SessionStats
+	// Lanes allocates the concurrency lanes that mark the results of tool
+	// calls running at the same time.
+	Lanes *tools.Lanes
+	Codex *codex.Renderer
+	// Full renders tool results with nothing truncated, for timeline
+	// entries the TUI can expand. Nil unless EnableFullResults is called.
+	Full *user.Renderer
+	// VerboseSystem and VerboseAssistant re-render session starts and
+	// thinking at the highest verbosity, for the Verbose func of their
+	// timeline entries. Nil unless EnableFullResults is called.
+	VerboseSystem    *system.Renderer
+	VerboseAssistant *assistant.Renderer
+	// Config is the configuration the renderers, and the processor using
+	// them, render with.
+	Config config.Provider
 }
 
-// NewRendererSet creates a new RendererSet with default renderers.
+// NewRendererSet creates a new RendererSet with default renderers, rendering
+// with the process-wide configuration.
 func NewRendererSet() *RendererSet {
+	return NewRendererSetWithConfig(config.Get())
+}
+
+// NewRendererSetWithConfig creates a new RendererSet whose renderers render
+// with cfg, for callers such as the viewscreen package that keep their own
+// configuration. The result renderer lists the per-tool totals of ToolTimes
+// and the files changed and tool errors counted in Stats in its footer.
+func NewRendererSetWithConfig(cfg config.Provider) *RendererSet {
+	toolTimes := tools.NewToolTimer()
+	stats := result.NewSessionStats()
 	return &RendererSet{
-		System:       system.NewRenderer(),
-		Assistant:    assistant.NewRenderer(),
-		User:         user.NewRenderer(),
-		Result:       result.NewRenderer(),
-		Stream:       stream.NewRenderer(),
+		System:       system.NewRenderer(system.WithConfigProvider(cfg)),
+		Assistant:    assistant.NewRenderer(assistant.WithConfigProvider(cfg)),
+		User:         user.NewRenderer(user.WithConfigProvider(cfg)),
+		Result:       result.NewRenderer(result.WithToolTimer(toolTimes), result.WithSessionStats(stats), result.WithConfigProvider(cfg)),
+		Stream:       stream.NewRenderer(stream.WithConfigProvider(cfg)),
 		PendingTools: tools.NewToolUseTracker(),
-		Codex:        codex.NewRenderer(),
+		ToolTimes:    toolTimes,
+		Stats:        stats,
+		Lanes:        tools.NewLanes(),
+		Codex:        codex.NewRenderer(codex.WithConfigProvider(cfg)),
+		Config:       cfg,
 	}
 }
 
-// SetWidth updates the word-wrap width for all markdown renderers.
-// This is called when the viewport resizes.
+// SetWidth updates the word-wrap width for all markdown renderers and the
+// layout width for tool results. This is called when the viewport resizes.
 func (rs *RendererSet) SetWidth(width int) {
+	rs.User.SetWidth(width)
 	rs.Stream.SetWidth(width)
 	rs.Assistant.SetWidth(width)
 	rs.Codex.SetWidth(width)
+	if rs.Full != nil {
+		rs.Full.SetWidth(width)
+	}
+	if rs.VerboseAssistant != nil {
+		rs.VerboseAssistant.SetWidth(width)
+	}
+}
+
+// fullLineBudget is the line budget of the Full renderer: large enough that
+// no tool result is truncated.
+const fullLineBudget = 1 << 20
+
+// EnableFullResults makes the processor also render each tool result at the
+// highest verbosity with no line limit, into the Full field of its timeline
+// entry, and give entries whose other details only show verbosely (thinking,
+// a session's agents and MCP servers) a Verbose func that renders them on
+// demand. Rendering twice costs time, so only views that expand results
+// enable it.
+func (rs *RendererSet) EnableFullResults() {
+	full := config.FullDetail(rs.Config)
+	rs.Full = user.NewRenderer(user.WithConfigProvider(full))
+	rs.Full.SetLineBudget(fullLineBudget)
+	rs.VerboseSystem = system.NewRenderer(system.WithConfigProvider(full))
+	rs.VerboseAssistant = assistant.NewRenderer(assistant.WithConfigProvider(full))
+}
+
+// SetLineBudget sets how many lines a single tool result may use before it
+// is truncated. The TUI derives it from the viewport height; zero keeps the
+// fixed limits.
+func (rs *RendererSet) SetLineBudget(lines int) {
+	rs.User.SetLineBudget(lines)
 }
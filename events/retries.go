@@ -0,0 +1,69 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/tools"
+	"github.com/johnnyfreeman/viewscreen/types"
+	"github.com/johnnyfreeman/viewscreen/user"
+)
+
+// failedCall is a tool call whose result was an error. The next call of the
+// same tool by the same agent retries it.
+type failedCall struct {
+	input   json.RawMessage
+	attempt int // 1 for a first call, 2 for its first retry, …
+	// concurrent are the calls already made when it failed, which ran
+	// alongside it rather than retrying it.
+	concurrent map[string]bool
+}
+
+// observeRetry records whether the call block, made by the agent parent,
+// failed, and returns the note shown beneath its header when it retries a
+// failed call: the attempt and what changed in its input, e.g.
+// "retry #2: changed pattern, added -i flag".
+func (p *EventProcessor) observeRetry(block types.ContentBlock, parent string, failed bool) string {
+	key := parent + "\x00" + block.Name
+	prev := p.failed[key]
+	if prev != nil && prev.concurrent[block.ID] {
+		return ""
+	}
+	note := ""
+	attempt := 1
+	if prev != nil {
+		attempt = prev.attempt + 1
+		note = fmt.Sprintf("retry #%d: %s", attempt, tools.DescribeInputChange(prev.input, block.Input))
+	}
+	if !failed {
+		delete(p.failed, key)
+		return note
+	}
+	concurrent := make(map[string]bool)
+	p.renderers.PendingTools.ForEach(func(id string, _ tools.PendingTool) {
+		concurrent[id] = true
+	})
+	p.failed[key] = &failedCall{input: block.Input, attempt: attempt, concurrent: concurrent}
+	return note
+}
+
+// renderRetryNote renders a note from observeRetry, behind cont.
+func renderRetryNote(note, cont string) string {
+	if note == "" {
+		return ""
+	}
+	return cont + style.MutedText("↻ "+note) + "\n"
+}
+
+// failedResults returns the IDs of the tool calls an event reports as
+// failed.
+func failedResults(event user.Event) map[string]bool {
+	failed := make(map[string]bool)
+	for _, c := range event.Message.Content {
+		if c.Type == "tool_result" && c.IsError {
+			failed[c.ToolUseID] = true
+		}
+	}
+	return failed
+}
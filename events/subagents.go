@@ -0,0 +1,63 @@
+package events
+
+import (
+	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// subAgentRun is what the sub-agent of a Task call has done so far.
+type subAgentRun struct {
+	summary timeline.SubAgent
+	// message is the ID of the request counted last: Claude Code sends
+	// each content block of a message as its own event, with the same
+	// usage.
+	message string
+}
+
+// observeSubAgent counts an assistant message of the sub-agent of the Task
+// call taskID.
+func (p *EventProcessor) observeSubAgent(taskID string, event assistant.Event) {
+	run := p.subAgents[taskID]
+	if run == nil {
+		run = &subAgentRun{summary: timeline.SubAgent{TaskID: taskID, Tools: make(map[string]int)}}
+		p.subAgents[taskID] = run
+	}
+	if id := event.Message.ID; id == "" || id != run.message {
+		run.message = id
+		run.summary.Turns++
+		if u := event.Message.Usage; u != nil {
+			run.summary.InputTokens += u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+			run.summary.OutputTokens += u.OutputTokens
+		}
+	}
+	for _, block := range event.Message.Content {
+		if block.Type == "tool_use" {
+			run.summary.Tools[block.Name]++
+		}
+	}
+}
+
+// finishSubAgent returns the summary of the sub-agent of the Task call
+// taskID, which has returned, or nil when it did nothing.
+func (p *EventProcessor) finishSubAgent(taskID string) *timeline.SubAgent {
+	run := p.subAgents[taskID]
+	if run == nil {
+		return nil
+	}
+	delete(p.subAgents, taskID)
+	return &run.summary
+}
+
+// parentToolUseID returns the Task call whose sub-agent sent event, or ""
+// for the main agent's events.
+func parentToolUseID(event Event) string {
+	switch e := event.(type) {
+	case AssistantEvent:
+		return stringValue(e.Data.ParentToolUseID)
+	case UserEvent:
+		return stringValue(e.Data.ParentToolUseID)
+	case StreamEvent:
+		return stringValue(e.Data.ParentToolUseID)
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// textRun follows the text blocks of one assistant message shown one after
+// another. Claude Code sends each content block of a message as its own
+// event, so a list or code fence the model split between blocks would
+// otherwise be rendered as two broken halves.
+type textRun struct {
+	id   string // the message the text belongs to
+	kind string // the kind of the entry showing it
+	text string
+}
+
+// observeText follows text, a text block of message id shown in an entry
+// of kind, and reports the text of the whole run when it continues the text
+// shown last. Any other entry in between ends the run (see Process).
+func (p *EventProcessor) observeText(id, kind, text string) (string, bool) {
+	p.textObserved = true
+	if p.lowMemory || id == "" {
+		p.texts = nil
+		return "", false
+	}
+	if r := p.texts; r != nil && r.id == id && r.kind == kind {
+		r.text = assistant.JoinText(r.text, text)
+		return r.text, true
+	}
+	p.texts = &textRun{id: id, kind: kind, text: text}
+	return "", false
+}
+
+// mergeText makes the entry of res show the whole run of text, replacing
+// the entry that showed its earlier blocks. The streamed output keeps only
+// the new block, beneath the earlier ones already shown.
+func (p *EventProcessor) mergeText(res *ProcessResult, text string) {
+	if len(res.Batch.Entries) != 1 {
+		return
+	}
+	event := assistant.Event{Message: assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: text}}}}
+	res.Batch.Entries[0].Body = p.renderers.Assistant.RenderToString(event, false, true)
+	res.Batch.Entries[0].Replaces = true
+}
+
+// onlyText returns the text of content when it holds nothing but text
+// blocks.
+func onlyText(content []types.ContentBlock) (string, bool) {
+	var text string
+	for _, block := range content {
+		if block.Type != "text" {
+			return "", false
+		}
+		text = assistant.JoinText(text, block.Text)
+	}
+	return text, strings.TrimSpace(text) != ""
+}
@@ -0,0 +1,196 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/terminal"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// turnSummary is a turn of the main agent and the tokens of its request.
+type turnSummary struct {
+	turn                    int
+	input, output           int
+	cacheRead, cacheCreated int
+	// cost is what the session's result reported the turn cost, as the
+	// delta of its total_cost_usd, when costed is set.
+	cost   float64
+	costed bool
+}
+
+// turnSegmenter splits the main agent's output into turns, one per request
+// it makes. A turn begins with a message_start or an assistant message
+// with a new message ID, or, for streams without message IDs, with the
+// first assistant message after a tool result; Codex marks its turns with
+// turn.started. A session starting or ending starts the count again.
+type turnSegmenter struct {
+	current turnSummary
+	message string // the ID of the current turn's message
+	// answered is whether the main agent has had a tool result since the
+	// current turn began.
+	answered bool
+	// session and cost are the session ID and total_cost_usd of the last
+	// result, which a later result of the same session adds to.
+	session string
+	cost    float64
+}
+
+// observe notes event. It returns the turn event ended, if any (turn 0
+// otherwise), and whether event begins the next turn; the session's result
+// ends its last turn without beginning another.
+func (t *turnSegmenter) observe(event Event) (turnSummary, bool) {
+	switch e := event.(type) {
+	case SystemEvent:
+		if e.Data.Subtype == "init" && e.Data.ParentToolUseID == nil {
+			*t = turnSegmenter{session: t.session, cost: t.cost}
+		}
+	case ResultEvent:
+		ended := t.current
+		ended.cost, ended.costed = e.Data.TotalCostUSD, true
+		if e.Data.SessionID == t.session {
+			ended.cost -= t.cost
+		}
+		*t = turnSegmenter{session: e.Data.SessionID, cost: e.Data.TotalCostUSD}
+		return ended, false
+	case UserEvent:
+		if e.Data.ParentToolUseID == nil && t.current.turn > 0 {
+			t.answered = true
+		}
+	case StreamEvent:
+		if e.Data.ParentToolUseID == nil && e.Data.Event.Type == "message_start" {
+			var message struct {
+				ID string `json:"id"`
+			}
+			_ = json.Unmarshal(e.Data.Event.Message, &message)
+			return t.begin(message.ID, false)
+		}
+	case AssistantEvent:
+		if e.Data.ParentToolUseID != nil {
+			break
+		}
+		ended, ok := t.begin(e.Data.Message.ID, false)
+		if u := e.Data.Message.Usage; u != nil {
+			t.current.input = u.InputTokens
+			t.current.output = u.OutputTokens
+			t.current.cacheRead = u.CacheReadInputTokens
+			t.current.cacheCreated = u.CacheCreationInputTokens
+		}
+		return ended, ok
+	case CodexEvent:
+		switch e.Data.Type {
+		case "turn.started":
+			return t.begin("", true)
+		case "turn.completed":
+			if u := e.Data.Usage; u != nil {
+				t.current.input = u.InputTokens - u.CachedInputTokens
+				t.current.output = u.OutputTokens
+				t.current.cacheRead = u.CachedInputTokens
+			}
+		}
+	}
+	return turnSummary{}, false
+}
+
+// begin starts a turn for a message of the main agent with ID id, unless
+// the message continues the current turn and force is not set. It returns
+// the turn that ended, if any.
+func (t *turnSegmenter) begin(id string, force bool) (turnSummary, bool) {
+	if t.current.turn > 0 && !force {
+		if id != "" && id == t.message {
+			return turnSummary{}, false
+		}
+		if id == "" && !t.answered {
+			return turnSummary{}, false
+		}
+	}
+	ended := t.current
+	t.current = turnSummary{turn: ended.turn + 1}
+	t.message = id
+	t.answered = false
+	return ended, true
+}
+
+// SetTurnDivider sets how the main agent's turns are separated: one of
+// config.TurnDividerNone, config.TurnDividerThin or
+// config.TurnDividerLabeled. The divider is rendered before the first
+// output of each turn but the first, as its own "turn" entry, after the
+// -vv usage of the turn that ended.
+func (p *EventProcessor) SetTurnDivider(divider string) {
+	p.turnDivider = divider
+}
+
+// addTurnEnd puts the end of the turn ended before the output of the event
+// that ended it: its usage at -vv, then the divider when the event begins
+// the next turn.
+func (p *EventProcessor) addTurnEnd(res *ProcessResult, ended turnSummary, begins bool) {
+	turn := p.state.TurnCount
+	if len(res.Batch.Entries) > 0 {
+		turn = res.Batch.Entries[0].Turn
+	}
+	var rendered string
+	var entries []timeline.Entry
+	if usage := p.renderTurnUsage(ended); usage != "" {
+		rendered += usage
+		entries = append(entries, timeline.Entry{Kind: "usage", Body: usage, Turn: ended.turn})
+	}
+	if divider := p.renderTurnDivider(ended); begins && divider != "" {
+		rendered += divider
+		entries = append(entries, timeline.Entry{Kind: timeline.KindTurnDivider, Body: divider, Turn: turn})
+	}
+	res.Rendered = rendered + res.Rendered
+	res.Batch.Entries = append(entries, res.Batch.Entries...)
+}
+
+// renderTurnUsage renders the usage of the main agent's turn that ended,
+// with -usage or at -vv, e.g. "Turn 3: 1.2k in · 340 out · 18k cache read".
+// The turn the session's result ended adds what the session cost since the
+// previous result, e.g. "· +$0.0123".
+func (p *EventProcessor) renderTurnUsage(ended turnSummary) string {
+	if cfg := p.renderers.Config; !cfg.ShowUsage() && cfg.VerbosityLevel() < turnUsageLevel {
+		return ""
+	}
+	if ended.input+ended.output+ended.cacheRead+ended.cacheCreated == 0 && ended.cost == 0 {
+		return ""
+	}
+	nf := numfmt.Default()
+	parts := []string{
+		nf.Tokens(ended.input) + " in",
+		nf.Tokens(ended.output) + " out",
+	}
+	if ended.cacheRead > 0 {
+		parts = append(parts, nf.Tokens(ended.cacheRead)+" cache read")
+	}
+	if ended.cacheCreated > 0 {
+		parts = append(parts, nf.Tokens(ended.cacheCreated)+" cache write")
+	}
+	if ended.costed {
+		parts = append(parts, "+"+nf.Cost(ended.cost, 4))
+	}
+	return style.OutputPrefix + style.MutedText(fmt.Sprintf("Turn %s: %s", nf.Int(ended.turn), strings.Join(parts, " · "))) + "\n"
+}
+
+// renderTurnDivider renders the divider after the turn ended, e.g.
+// "─── Turn 7 · ↑12k ↓340 ───" when labeled.
+func (p *EventProcessor) renderTurnDivider(ended turnSummary) string {
+	width := p.width
+	if width <= 0 {
+		width = terminal.Width()
+	}
+	switch p.turnDivider {
+	case config.TurnDividerThin:
+		return "\n" + style.Rule(width, "") + "\n"
+	case config.TurnDividerLabeled:
+		nf := numfmt.Default()
+		label := fmt.Sprintf("Turn %s", nf.Int(ended.turn))
+		if in := ended.input + ended.cacheRead + ended.cacheCreated; in+ended.output > 0 {
+			label += fmt.Sprintf(" · ↑%s ↓%s", nf.Tokens(in), nf.Tokens(ended.output))
+		}
+		return "\n" + style.Rule(width, label) + "\n"
+	}
+	return ""
+}
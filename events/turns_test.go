@@ -0,0 +1,156 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/result"
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+	"github.com/johnnyfreeman/viewscreen/types"
+	"github.com/johnnyfreeman/viewscreen/user"
+)
+
+func assistantMessage(id, text string, parent *string) AssistantEvent {
+	return AssistantEvent{Data: assistant.Event{
+		BaseEvent: types.BaseEvent{ParentToolUseID: parent},
+		Message: assistant.Message{
+			ID:      id,
+			Content: []types.ContentBlock{{Type: "text", Text: text}},
+			Usage:   &types.Usage{InputTokens: 1000, CacheReadInputTokens: 200, OutputTokens: 30},
+		},
+	}}
+}
+
+func toolResult(id string) UserEvent {
+	return UserEvent{Data: user.Event{Message: user.Message{
+		Content: []user.ToolResultContent{{Type: "tool_result", ToolUseID: id, RawContent: json.RawMessage(`"ok"`)}},
+	}}}
+}
+
+// dividers returns the divider entries of res.
+func dividers(res ProcessResult) []timeline.Entry {
+	var found []timeline.Entry
+	for _, entry := range res.Batch.Entries {
+		if entry.Kind == timeline.KindTurnDivider {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+func TestEventProcessor_TurnDivider(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.SetWidth(40)
+	p.SetTurnDivider(config.TurnDividerLabeled)
+
+	if res := p.Process(assistantMessage("msg-1", "first", nil)); len(dividers(res)) != 0 {
+		t.Errorf("expected no divider before the first turn, got %+v", res.Batch.Entries)
+	}
+	// Blocks of the same message continue the turn
+	if res := p.Process(assistantMessage("msg-1", "more", nil)); len(dividers(res)) != 0 {
+		t.Errorf("expected no divider within a turn, got %+v", res.Batch.Entries)
+	}
+	// Sub-agents' messages are not the main agent's turns
+	task := "task-1"
+	if res := p.Process(assistantMessage("sub-1", "sub-agent", &task)); len(dividers(res)) != 0 {
+		t.Errorf("expected no divider for a sub-agent, got %+v", res.Batch.Entries)
+	}
+
+	res := p.Process(assistantMessage("msg-2", "second", nil))
+	found := dividers(res)
+	if n := len(res.Batch.Entries); len(found) != 1 || n < 2 || res.Batch.Entries[n-2].Kind != timeline.KindTurnDivider {
+		t.Fatalf("expected a divider before the second turn, got %+v", res.Batch.Entries)
+	}
+	rule := ansi.Strip(found[0].Body)
+	if !strings.Contains(rule, "─── Turn 1 · ↑1.2k ↓30 ───") || ansi.StringWidth(strings.TrimSpace(rule)) != 40 {
+		t.Errorf("expected a labeled rule 40 columns wide, got %q", rule)
+	}
+	if !strings.HasSuffix(res.Rendered, found[0].Body+"second\n") {
+		t.Errorf("expected the divider right before the turn's output, got %q", res.Rendered)
+	}
+
+	// A session ending starts the count again
+	p.Process(ResultEvent{Data: result.Event{}})
+	if res := p.Process(assistantMessage("msg-3", "next session", nil)); len(dividers(res)) != 0 {
+		t.Errorf("expected no divider before a session's first turn, got %+v", res.Batch.Entries)
+	}
+}
+
+func TestEventProcessor_TurnDivider_WithoutMessageIDs(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.SetTurnDivider(config.TurnDividerThin)
+
+	p.Process(assistantMessage("", "first", nil))
+	if res := p.Process(assistantMessage("", "still first", nil)); len(dividers(res)) != 0 {
+		t.Errorf("expected messages without IDs to continue the turn until a tool result, got %+v", res.Batch.Entries)
+	}
+	p.Process(toolResult("t1"))
+	res := p.Process(assistantMessage("", "second", nil))
+	found := dividers(res)
+	if len(found) != 1 || strings.Contains(ansi.Strip(found[0].Body), "Turn") {
+		t.Errorf("expected an unlabeled divider after the tool result, got %+v", res.Batch.Entries)
+	}
+}
+
+func TestEventProcessor_TurnDivider_None(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(assistantMessage("msg-1", "first", nil))
+	if res := p.Process(assistantMessage("msg-2", "second", nil)); len(dividers(res)) != 0 {
+		t.Errorf("expected no dividers by default, got %+v", res.Batch.Entries)
+	}
+}
+
+// usageLine returns the body of the usage entry of res, if any.
+func usageLine(res ProcessResult) string {
+	for _, entry := range res.Batch.Entries {
+		if entry.Kind == "usage" {
+			return ansi.Strip(entry.Body)
+		}
+	}
+	return ""
+}
+
+func TestEventProcessor_TurnUsage(t *testing.T) {
+	p := NewEventProcessor(state.NewState())
+	p.Process(assistantMessage("msg-1", "first", nil))
+	res := p.Process(assistantMessage("msg-2", "second", nil))
+	if got := usageLine(res); !strings.Contains(got, "Turn 1: 1.0k in · 30 out · 200 cache read") || strings.Contains(got, "$") {
+		t.Errorf("expected turn 1's tokens without a cost, got %q", got)
+	}
+	if !strings.HasPrefix(ansi.Strip(res.Rendered), "  ⎿  Turn 1:") {
+		t.Errorf("expected the usage before the turn's output, got %q", res.Rendered)
+	}
+
+	// A result costs the turn it ends the session's cost since the last one
+	session := types.BaseEvent{SessionID: "s1"}
+	res = p.Process(ResultEvent{Data: result.Event{BaseEvent: session, TotalCostUSD: 0.05}})
+	if got := usageLine(res); !strings.Contains(got, "Turn 2: 1.0k in · 30 out · 200 cache read · +$0.0500") {
+		t.Errorf("expected turn 2 with the session's cost, got %q", got)
+	}
+	p.Process(assistantMessage("msg-3", "follow-up", nil))
+	res = p.Process(ResultEvent{Data: result.Event{BaseEvent: session, TotalCostUSD: 0.08}})
+	if got := usageLine(res); !strings.Contains(got, "+$0.0300") {
+		t.Errorf("expected the cost since the previous result, got %q", got)
+	}
+}
+
+func TestEventProcessor_TurnUsage_Disabled(t *testing.T) {
+	defer func(prev bool) { config.Get().DisplayUsage = prev }(config.Get().DisplayUsage)
+	config.Get().DisplayUsage = false
+
+	p := NewEventProcessor(state.NewState())
+	p.Process(assistantMessage("msg-1", "first", nil))
+	if got := usageLine(p.Process(assistantMessage("msg-2", "second", nil))); got != "" {
+		t.Errorf("expected no usage with -usage=false, got %q", got)
+	}
+
+	withVerbosity(t, 2)
+	if got := usageLine(p.Process(assistantMessage("msg-3", "third", nil))); !strings.Contains(got, "Turn 2:") {
+		t.Errorf("expected the usage at -vv, got %q", got)
+	}
+}
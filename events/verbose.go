@@ -0,0 +1,60 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// Verbosity levels of the details the processor adds to the renderers'
+// output.
+const (
+	// rawInputLevel (-vv) shows each tool call's input as sent.
+	rawInputLevel = 2
+	// turnUsageLevel (-vv) shows the usage of each turn of the main agent,
+	// even with -usage=false.
+	turnUsageLevel = 2
+	// rawEventLevel (-vvv) shows the JSON of each event.
+	rawEventLevel = 3
+)
+
+// renderRawInput renders the input of the tool call block as compact JSON
+// behind cont, at -vv and above.
+func (p *EventProcessor) renderRawInput(block types.ContentBlock, cont string) string {
+	if p.renderers.Config.VerbosityLevel() < rawInputLevel || len(block.Input) == 0 {
+		return ""
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, block.Input) != nil {
+		return ""
+	}
+	return cont + style.MutedText("input "+compact.String()) + "\n"
+}
+
+// addRawEvent follows the output of an event with its JSON, line, at -vvv.
+// Stream deltas are left out: their output continues the text of the block
+// they are part of.
+func (p *EventProcessor) addRawEvent(res *ProcessResult, event Event, line string) {
+	if line == "" || p.renderers.Config.VerbosityLevel() < rawEventLevel {
+		return
+	}
+	if _, ok := event.(StreamEvent); ok {
+		return
+	}
+	rendered := style.MutedText(strings.TrimSpace(line)) + "\n"
+	entry := timeline.Entry{Kind: "raw", Body: rendered, Turn: p.state.TurnCount, TaskID: parentToolUseID(event)}
+	res.Rendered += rendered
+	res.Batch.Entries = append(res.Batch.Entries, entry)
+}
+
+// ProcessLine processes event, parsed from the input line line, as Process
+// does, followed at -vvv by the JSON of line.
+func (p *EventProcessor) ProcessLine(line string, event Event) ProcessResult {
+	res := p.Process(event)
+	p.addRawEvent(&res, event, line)
+	return res
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// withVerbosity sets the config's verbosity level for the rest of the test.
+func withVerbosity(t *testing.T, level int) {
+	t.Helper()
+	prev := config.Get().VerboseLevel
+	config.Get().VerboseLevel = level
+	t.Cleanup(func() { config.Get().VerboseLevel = prev })
+}
+
+func bashCall(id, command string) AssistantEvent {
+	return AssistantEvent{Data: assistant.Event{Message: assistant.Message{
+		ID: "msg-" + id,
+		Content: []types.ContentBlock{{
+			Type:  "tool_use",
+			ID:    id,
+			Name:  "Bash",
+			Input: json.RawMessage(`{"command": "` + command + `"}`),
+		}},
+	}}}
+}
+
+func TestEventProcessor_RawInput(t *testing.T) {
+	for _, tt := range []struct {
+		level int
+		want  bool
+	}{{1, false}, {2, true}} {
+		withVerbosity(t, tt.level)
+		p := NewEventProcessor(state.NewState())
+		p.Process(bashCall("t1", "ls"))
+		got := ansi.Strip(p.Process(toolResult("t1")).Rendered)
+		if strings.Contains(got, `input {"command":"ls"}`) != tt.want {
+			t.Errorf("level %d: raw input shown = %v, want %v in %q", tt.level, !tt.want, tt.want, got)
+		}
+	}
+}
+
+func TestEventProcessor_RawEvent(t *testing.T) {
+	line := `{"type":"assistant","message":{"id":"msg-1","content":[{"type":"text","text":"hi"}]}}`
+
+	withVerbosity(t, 2)
+	p := NewEventProcessor(state.NewState())
+	if res := p.ProcessLine(line, Parse(line)); strings.Contains(res.Rendered, `"type":"assistant"`) {
+		t.Errorf("expected no raw event below -vvv, got %q", res.Rendered)
+	}
+
+	withVerbosity(t, 3)
+	p = NewEventProcessor(state.NewState())
+	res := p.ProcessLine(line, Parse(line))
+	n := len(res.Batch.Entries)
+	if n < 2 || res.Batch.Entries[n-1].Kind != "raw" || !strings.Contains(res.Batch.Entries[n-1].Body, line) {
+		t.Errorf("expected the event's JSON after its output, got %+v", res.Batch.Entries)
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/types"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
+)
+
+// watchAlert counts the call block when it touches paths on the
+// watchlist.Default watchlist, and returns the alert shown beneath its
+// header, behind cont, e.g. "⚠ watched path: write .env (matches .env)".
+func (p *EventProcessor) watchAlert(block types.ContentBlock, cont string) string {
+	hits := watchlist.Default().Check(block.Name, block.Input)
+	if len(hits) == 0 {
+		return ""
+	}
+	if p.renderers.Stats != nil {
+		p.renderers.Stats.WatchedPathTouched()
+	}
+	descs := make([]string, len(hits))
+	for i, hit := range hits {
+		descs[i] = hit.String()
+	}
+	label := "⚠ watched path: "
+	if len(hits) > 1 {
+		label = "⚠ watched paths: "
+	}
+	return cont + style.ErrorBoldText(label+strings.Join(descs, ", ")) + "\n"
+}
@@ -0,0 +1,138 @@
+// Package export writes a rendered session timeline to shareable documents
+// (Markdown and standalone HTML). Entries that carry an event UUID get a
+// stable anchor so individual turns can be deep-linked.
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Format identifies an export document format.
+type Format string
+
+// Supported export formats.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// Options configures an export.
+type Options struct {
+	// Title is the document title. Defaults to "viewscreen session".
+	Title string
+	// Split, when positive, makes WriteFile write a chapter file for every
+	// Split turns and an index page linking them (see WriteSplit). It
+	// applies to the built-in formats only.
+	Split int
+	// Events are the raw input lines of the session, passed to exporters
+	// added with RegisterExporter. The built-in formats ignore them.
+	Events []string
+
+	// nav links a chapter of a split export to its neighbours.
+	nav []link
+	// before are the entries of the chapters before this one of a split
+	// export, which the HTML "copy as prompt" buttons include.
+	before []timeline.Entry
+	// sessionTitle is the title of the whole split export.
+	sessionTitle string
+}
+
+func (o Options) title() string {
+	if o.Title == "" {
+		return "viewscreen session"
+	}
+	return o.Title
+}
+
+// promptTitle is the heading of the Markdown the "copy as prompt" buttons
+// copy: the session's title, not the chapter's.
+func (o Options) promptTitle() string {
+	if o.sessionTitle != "" {
+		return o.sessionTitle
+	}
+	return o.title()
+}
+
+// FormatForPath infers the export format from a file extension: an
+// extension added with RegisterExporter selects its exporter, .html and .htm
+// select HTML, and anything else Markdown.
+func FormatForPath(path string) Format {
+	ext := filepath.Ext(path)
+	if format := extFormat(ext); Registered(format) {
+		return format
+	}
+	switch strings.ToLower(ext) {
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMarkdown
+	}
+}
+
+// AnchorID returns the document anchor for an entry ID, or "" when the entry
+// has no ID. Anchors are prefixed so they are valid HTML ids even when the
+// ID starts with a digit. An ID with characters other than letters, digits,
+// '-' and '_' comes from the input, not Claude Code, so it is hashed rather
+// than written into the document.
+func AnchorID(id string) string {
+	if id == "" {
+		return ""
+	}
+	if strings.IndexFunc(id, func(r rune) bool { return !isAnchorRune(r) }) >= 0 {
+		sum := sha256.Sum256([]byte(id))
+		id = hex.EncodeToString(sum[:8])
+	}
+	return "evt-" + id
+}
+
+func isAnchorRune(r rune) bool {
+	return r == '-' || r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// Write renders entries to w in the given format.
+func Write(w io.Writer, format Format, entries []timeline.Entry, opts Options) error {
+	if e, ok := exporters[format]; ok {
+		return e.Export(w, Session{Title: opts.title(), Events: opts.Events, Entries: entries})
+	}
+	switch format {
+	case FormatHTML:
+		return writeHTML(w, entries, opts)
+	case FormatMarkdown:
+		return writeMarkdown(w, entries, opts)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// WriteFile renders entries to the file at path, inferring the format from
+// its extension. With opts.Split set, the session is split into chapters as
+// by WriteSplit.
+func WriteFile(path string, entries []timeline.Entry, opts Options) error {
+	if opts.Split > 0 && !Registered(FormatForPath(path)) {
+		return WriteSplit(path, entries, opts)
+	}
+	return createFile(path, func(w io.Writer) error {
+		return Write(w, FormatForPath(path), entries, opts)
+	})
+}
+
+// createFile creates the file at path and writes it with write.
+func createFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating export: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,398 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func TestFormatForPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want Format
+	}{
+		{"session.html", FormatHTML},
+		{"session.HTM", FormatHTML},
+		{"session.md", FormatMarkdown},
+		{"session", FormatMarkdown},
+	}
+	for _, tt := range tests {
+		if got := FormatForPath(tt.path); got != tt.want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAnchorID(t *testing.T) {
+	if got := AnchorID(""); got != "" {
+		t.Errorf("AnchorID(\"\") = %q, want empty", got)
+	}
+	if got := AnchorID("1234-abcd"); got != "evt-1234-abcd" {
+		t.Errorf("AnchorID = %q, want evt-1234-abcd", got)
+	}
+	hostile := AnchorID(`x"><script>alert(1)</script>`)
+	if strings.ContainsAny(hostile, `"<>&' `) || !strings.HasPrefix(hostile, "evt-") {
+		t.Errorf("AnchorID kept markup: %q", hostile)
+	}
+	if hostile == AnchorID(`y"><script>alert(1)</script>`) {
+		t.Error("expected different IDs to get different anchors")
+	}
+}
+
+func TestWrite_HostileID(t *testing.T) {
+	id := `x" onmouseover="alert(1)"><script>alert(1)</script>`
+	entries := annotations.Set{id: {"look"}}.Interleave([]timeline.Entry{
+		{ID: id, Kind: "assistant", Body: "hello\n"},
+	})
+	for _, format := range []Format{FormatHTML, FormatMarkdown} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, entries, Options{}); err != nil {
+			t.Fatalf("Write %s: %v", format, err)
+		}
+		if out := buf.String(); strings.Contains(out, "alert(1)") {
+			t.Errorf("%s: expected the ID kept out of the markup, got:\n%s", format, out)
+		}
+	}
+}
+
+func TestWrite_Markdown(t *testing.T) {
+	entries := []timeline.Entry{
+		{ID: "uuid-1", Kind: "assistant", Body: "\x1b[1mhello\x1b[0m\n"},
+		{Kind: "error", Body: "no id\n"},
+		{ID: "uuid-2", Kind: "assistant", Body: "   \n"},
+		{ID: "uuid-3", Kind: "tool", Body: "```go\nx := 1\n```\n"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatMarkdown, entries, Options{Title: "Run"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "# Run\n") {
+		t.Errorf("expected title heading, got %q", out)
+	}
+	if !strings.Contains(out, "<a id=\"evt-uuid-1\"></a>\n\n```text\nhello\n```") {
+		t.Errorf("expected anchored, ANSI-stripped block, got:\n%s", out)
+	}
+	if strings.Contains(out, "\x1b") {
+		t.Error("expected ANSI sequences to be stripped")
+	}
+	if !strings.Contains(out, "```text\nno id\n```") || strings.Count(out, "<a id=") != 2 {
+		t.Errorf("expected entries without IDs to have no anchor, got:\n%s", out)
+	}
+	if strings.Contains(out, "evt-uuid-2") {
+		t.Error("expected blank entries to be skipped")
+	}
+	if !strings.Contains(out, "````text\n```go") {
+		t.Errorf("expected a longer fence around nested fences, got:\n%s", out)
+	}
+}
+
+func TestWrite_HTML(t *testing.T) {
+	entries := []timeline.Entry{
+		{ID: "uuid-1", Kind: "assistant", Body: "\x1b[38;2;255;0;0m<b>red</b>\x1b[0m plain\n"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatHTML, entries, Options{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "<title>viewscreen session</title>") {
+		t.Error("expected default title")
+	}
+	if !strings.Contains(out, `id="evt-uuid-1"`) || !strings.Contains(out, `href="#evt-uuid-1"`) {
+		t.Errorf("expected anchor and self-link, got:\n%s", out)
+	}
+	if !strings.Contains(out, `<span style="color:#ff0000">&lt;b&gt;red&lt;/b&gt;</span> plain`) {
+		t.Errorf("expected escaped, colored span, got:\n%s", out)
+	}
+}
+
+func TestView(t *testing.T) {
+	got, ok := View(timeline.Entry{ID: "uuid-1", Kind: "tool", Body: "<ls> \x1b[1mok\x1b[0m\n"})
+	want := EntryView{Kind: "tool", Anchor: "evt-uuid-1", Spans: []Span{{Text: "<ls> "}, {Text: "ok", Style: "font-weight:bold"}}}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("View = %+v, %v, want %+v", got, ok, want)
+	}
+	note, ok := View(timeline.Entry{Kind: annotations.Kind, ParentID: "uuid-1", Lines: []string{"why?"}})
+	if !ok || note.Anchor != "evt-uuid-1" || len(note.Comments) != 1 || note.Spans != nil {
+		t.Errorf("View(annotation) = %+v, %v", note, ok)
+	}
+	if _, ok := View(timeline.Entry{Body: "  \n"}); ok {
+		t.Error("expected a blank entry to be skipped")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTMLShell(&buf, Options{Title: "live"}, "<script>go()</script>\n"); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "<title>live</title>") || !strings.Contains(out, "<script>go()</script>\n<script>") {
+		t.Errorf("expected the head, the script and the theme toggle, got:\n%s", out)
+	}
+}
+
+func TestWrite_HTMLCopyPrompt(t *testing.T) {
+	entries := []timeline.Entry{
+		{ID: "init", Body: "session started\n"},
+		{ID: "a1", Turn: 1, Kind: "assistant", Body: "\x1b[1mlook</script>\x1b[0m\n"},
+		{ID: "a2", Turn: 2, Kind: "assistant", Body: "done\n"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatHTML, entries, Options{Title: "Run"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Count(out, `class="copy-prompt"`) != 2 {
+		t.Errorf("expected a button per turn, got:\n%s", out)
+	}
+	if !strings.Contains(out, "look&lt;/script&gt;</span></pre></section>\n<div class=\"turn-end\"><button class=\"copy-prompt\" type=\"button\" data-turn=\"1\"") {
+		t.Errorf("expected turn 1 to end with its button, got:\n%s", out)
+	}
+	if strings.Contains(out, "look</script>") {
+		t.Error("expected the turn data to escape entries")
+	}
+	for _, want := range []string{`"title":"Run"`, `{"turn":1,"markdown":"\n`, "session started", `{"turn":2,`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the turn data, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestTurnPrompts(t *testing.T) {
+	got := turnPrompts([]timeline.Entry{
+		{ID: "init", Body: "started\n"},
+		{ID: "a1", Turn: 1, Body: "\x1b[1mone\x1b[0m\n"},
+		{ID: "note", Kind: annotations.Kind, Lines: []string{"reviewed"}},
+		{ID: "blank", Turn: 2, Body: " \n"},
+		{ID: "a3", Turn: 3, Body: "three\n"},
+	})
+	want := []turnPrompt{
+		{Turn: 1, Markdown: "\n```text\nstarted\n```\n\n```text\none\n```\n\n> **Review:** reviewed\n"},
+		{Turn: 3, Markdown: "\n```text\nthree\n```\n"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("turnPrompts = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("turn %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWrite_HTMLThemes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatHTML, []timeline.Entry{{Body: "hi\n"}}, Options{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`:root { color-scheme: dark;`,
+		`--bg: ` + string(style.DefaultTheme.BgBase) + `;`,
+		`@media (prefers-color-scheme: light)`,
+		`:root[data-theme="light"] { color-scheme: light;`,
+		`--bg: ` + string(style.LightTheme.BgBase) + `;`,
+		`id="theme-toggle"`,
+		`localStorage.setItem("viewscreen-theme"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the page, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWrite_Annotations(t *testing.T) {
+	entries := annotations.Set{"uuid-1": {"why <this>?"}}.Interleave([]timeline.Entry{
+		{ID: "uuid-1", Kind: "assistant", Body: "hello\n"},
+	})
+
+	var md bytes.Buffer
+	if err := Write(&md, FormatMarkdown, entries, Options{}); err != nil {
+		t.Fatalf("Write markdown: %v", err)
+	}
+	if !strings.Contains(md.String(), "```\n\n> **Review:** why <this>?\n") {
+		t.Errorf("expected blockquote after block, got:\n%s", md.String())
+	}
+
+	var page bytes.Buffer
+	if err := Write(&page, FormatHTML, entries, Options{}); err != nil {
+		t.Fatalf("Write HTML: %v", err)
+	}
+	if !strings.Contains(page.String(), `<aside class="annotation" data-for="evt-uuid-1">why &lt;this&gt;?</aside>`) {
+		t.Errorf("expected escaped aside, got:\n%s", page.String())
+	}
+	if strings.Count(page.String(), `id="evt-uuid-1"`) != 1 {
+		t.Error("expected a single anchor for the annotated block")
+	}
+}
+
+func TestWrite_SubAgentSections(t *testing.T) {
+	entries := []timeline.Entry{
+		{ID: "u1", Kind: "assistant", Body: "delegating\n", Turn: 1},
+		{ID: "s1", Kind: "user", Body: "explore prompt\n", Turn: 1, TaskID: "task-a"},
+		{ID: "s2", Kind: "user", Body: "other prompt\n", Turn: 1, TaskID: "task-b"},
+		{ID: "s3", Kind: "assistant", Body: "found it\n", Turn: 2, TaskID: "task-a"},
+		{Kind: annotations.Kind, ParentID: "s3", Lines: []string{"nice"}},
+		{ID: "r1", Kind: "user", Title: "Task", Arg: "Explore the parser", Body: "done\n", Turn: 3,
+			SubAgent: &timeline.SubAgent{TaskID: "task-a", Turns: 2, InputTokens: 1200, OutputTokens: 300,
+				Tools: map[string]int{"Grep": 1, "Read": 3}}},
+	}
+
+	var md bytes.Buffer
+	if err := Write(&md, FormatMarkdown, entries, Options{Title: "Run"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := md.String()
+	heading := "<summary>Sub-agent: Explore the parser · 2 turns · ↑1.2k ↓300 tokens · Read ×3, Grep ×1</summary>"
+	if !strings.Contains(out, heading) {
+		t.Errorf("expected the sub-agent's summary, got:\n%s", out)
+	}
+	explore, other, result := strings.Index(out, "explore prompt"), strings.Index(out, "other prompt"), strings.Index(out, "done")
+	found, review := strings.Index(out, "found it"), strings.Index(out, "**Review:** nice")
+	if !(explore < found && found < review && review < other && other < result) {
+		t.Errorf("expected each sub-agent's entries and comments grouped where it started, got:\n%s", out)
+	}
+	if !strings.Contains(out, "<summary>Sub-agent · running</summary>") {
+		t.Errorf("expected a sub-agent without a result to be running, got:\n%s", out)
+	}
+
+	var page bytes.Buffer
+	if err := Write(&page, FormatHTML, entries, Options{Title: "Run"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out = page.String()
+	if !strings.Contains(out, `<details class="subagent" data-task="task-a"><summary>Sub-agent: Explore the parser`) {
+		t.Errorf("expected a folded section, got:\n%s", out)
+	}
+	if strings.Count(out, `class="copy-prompt"`) != 2 || !strings.Contains(out, `data-turn="2"`) || strings.Contains(out, `data-turn="1"`) {
+		t.Errorf("expected copy buttons only outside the sections, got:\n%s", out)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, Format("pdf"), nil, Options{}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.html")
+	if err := WriteFile(path, []timeline.Entry{{ID: "x", Body: "hi\n"}}, Options{}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "<!DOCTYPE html>") {
+		t.Errorf("expected HTML document, got %q", data[:min(len(data), 40)])
+	}
+}
+
+func TestWriteFile_Split(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.md")
+	entries := []timeline.Entry{
+		{ID: "init", Body: "session started\n"},
+		{ID: "a1", Turn: 1, Body: "turn one\n"},
+		{ID: "note", Kind: "annotation", Lines: []string{"reviewed"}},
+		{ID: "a2", Turn: 2, Body: "turn two\n"},
+		{ID: "a5", Turn: 5, Body: "turn five\n"},
+	}
+	if err := WriteFile(path, entries, Options{Title: "Run", Split: 2}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	index := read("session.md")
+	if !strings.Contains(index, "- [Turns 1–2](session-001.md)\n- [Turn 5](session-002.md)\n") {
+		t.Errorf("expected the index to link both chapters, got:\n%s", index)
+	}
+
+	first := read("session-001.md")
+	for _, want := range []string{"# Run — Turns 1–2", "[Index](session.md)", "[Turn 5 →](session-002.md)", "session started", "reviewed", "turn two"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("expected chapter 1 to contain %q, got:\n%s", want, first)
+		}
+	}
+	if strings.Contains(first, "turn five") {
+		t.Error("expected turn 5 to be in the second chapter")
+	}
+	second := read("session-002.md")
+	if !strings.Contains(second, "turn five") || !strings.Contains(second, "[← Turns 1–2](session-001.md)") {
+		t.Errorf("expected chapter 2 to hold turn 5 and link back, got:\n%s", second)
+	}
+}
+
+func TestWriteSplit_HTMLCopyPrompt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.html")
+	entries := []timeline.Entry{
+		{ID: "a1", Turn: 1, Body: "turn one\n"},
+		{ID: "a2", Turn: 2, Body: "turn two\n"},
+	}
+	if err := WriteFile(path, entries, Options{Title: "Run", Split: 1}); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "session-002.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if strings.Count(out, `class="copy-prompt"`) != 1 || !strings.Contains(out, `data-turn="2"`) {
+		t.Errorf("expected a button for turn 2 only, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"title":"Run","turns":[{"turn":1,"markdown":"\n`+"```"+`text\nturn one`) {
+		t.Errorf("expected the earlier chapter's turns in the turn data, got:\n%s", out)
+	}
+}
+
+func TestChapterPath(t *testing.T) {
+	if got := ChapterPath("out/session.html", 12); got != "out/session-012.html" {
+		t.Errorf("ChapterPath() = %q, want out/session-012.html", got)
+	}
+}
+
+func TestAnsiToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "a & b", "a &amp; b"},
+		{"bold reset", "\x1b[1mx\x1b[22my", `<span style="font-weight:bold">x</span>y`},
+		{"basic color", "\x1b[31mx\x1b[39m", `<span style="color:#cd0000">x</span>`},
+		{"256 color", "\x1b[38;5;196mx", `<span style="color:#ff0000">x</span>`},
+		{"background", "\x1b[48;2;1;2;3mx\x1b[m", `<span style="background:#010203">x</span>`},
+		{"drops cursor moves", "\x1b[2Kx", "x"},
+		{"drops OSC hyperlinks", "\x1b]8;;http://e\x1b\\x\x1b]8;;\x07", "x"},
+		{"theme color", "\x1b[38;2;168;85;247mx", `<span style="color:var(--accent)">x</span>`},
+	}
+	vars := themeVars(style.DefaultTheme)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ansiToHTML(tt.in, vars); got != tt.want {
+				t.Errorf("ansiToHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
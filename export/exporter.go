@@ -0,0 +1,56 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Session is what an Exporter writes: the session's input events and the
+// transcript rendered from them.
+type Session struct {
+	// Title is the document title.
+	Title string
+	// Events are the raw input lines, one JSON event each, in stream order.
+	Events []string
+	// Entries is the rendered transcript.
+	Entries []timeline.Entry
+}
+
+// Exporter writes a session in a format viewscreen does not build in, such
+// as an organization's internal archive format.
+type Exporter interface {
+	Export(w io.Writer, s Session) error
+}
+
+// ExporterFunc adapts a function to an Exporter.
+type ExporterFunc func(w io.Writer, s Session) error
+
+// Export calls f(w, s).
+func (f ExporterFunc) Export(w io.Writer, s Session) error {
+	return f(w, s)
+}
+
+// exporters holds the exporters added with RegisterExporter, keyed by the
+// format named after their file extension.
+var exporters = map[Format]Exporter{}
+
+// RegisterExporter makes exports to files with extension ext (such as
+// ".audit") use e, replacing a built-in format if ext selects one.
+// FormatForPath names the format after the extension without its dot.
+func RegisterExporter(ext string, e Exporter) {
+	exporters[extFormat(ext)] = e
+}
+
+// Registered reports whether format was added with RegisterExporter.
+// Exports in such formats need Options.Events.
+func Registered(format Format) bool {
+	_, ok := exporters[format]
+	return ok
+}
+
+// extFormat returns the format named after a file extension.
+func extFormat(ext string) Format {
+	return Format(strings.TrimPrefix(strings.ToLower(ext), "."))
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// registerTestExporter registers e for ext until the test ends.
+func registerTestExporter(t *testing.T, ext string, e Exporter) {
+	t.Helper()
+	RegisterExporter(ext, e)
+	t.Cleanup(func() { delete(exporters, extFormat(ext)) })
+}
+
+func TestRegisterExporter(t *testing.T) {
+	var got Session
+	registerTestExporter(t, ".Audit", ExporterFunc(func(w io.Writer, s Session) error {
+		got = s
+		_, err := fmt.Fprintf(w, "%d events, %d entries", len(s.Events), len(s.Entries))
+		return err
+	}))
+
+	if format := FormatForPath("run.audit"); format != "audit" || !Registered(format) {
+		t.Fatalf("FormatForPath(run.audit) = %q, want the registered format", format)
+	}
+	if Registered(FormatHTML) {
+		t.Error("expected the built-in formats not to be registered")
+	}
+
+	path := filepath.Join(t.TempDir(), "run.audit")
+	entries := []timeline.Entry{{ID: "a", Body: "hi\n"}}
+	opts := Options{Title: "Run", Events: []string{`{"type":"system"}`, `{"type":"result"}`}, Split: 1}
+	if err := WriteFile(path, entries, opts); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "2 events, 1 entries" {
+		t.Errorf("export = %q, want the exporter's output in one file", data)
+	}
+	if got.Title != "Run" || got.Events[1] != `{"type":"result"}` {
+		t.Errorf("exporter got %+v, want the title and raw events", got)
+	}
+}
+
+func TestRegisterExporter_ReplacesBuiltIn(t *testing.T) {
+	registerTestExporter(t, ".md", ExporterFunc(func(w io.Writer, s Session) error {
+		_, err := io.WriteString(w, "custom")
+		return err
+	}))
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatForPath("notes.md"), nil, Options{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if buf.String() != "custom" {
+		t.Errorf("Write() = %q, want the registered exporter's output", buf.String())
+	}
+	if FormatForPath("notes.html") != FormatHTML {
+		t.Error("expected other extensions to keep their built-in format")
+	}
+}
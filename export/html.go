@@ -0,0 +1,495 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// writeHTML renders a standalone HTML document. Terminal colors in entry
+// bodies are converted to inline styles so the export looks like the TUI.
+// Theme colors become CSS custom properties, so the page can switch between
+// the dark theme the session was rendered with and style.LightTheme: it
+// follows prefers-color-scheme until the reader picks one with the toggle.
+// Each turn ends with a button copying the session up to it as Markdown, and
+// the entries of each sub-agent are folded into a section headed by its
+// summary.
+func writeHTML(w io.Writer, entries []timeline.Entry, opts Options) error {
+	bw := bufio.NewWriter(w)
+	dark := pageTheme()
+	vars := themeVars(renderTheme())
+	writeHTMLHead(bw, opts, dark)
+
+	turn := 0
+	sections := false
+	for _, item := range groupSubAgents(entries) {
+		entry := item.entry
+		if item.section != nil {
+			entry = item.section.entries[0]
+		}
+		if next := max(turn, entry.Turn, 1); next != turn {
+			if turn > 0 {
+				writeCopyPrompt(bw, turn)
+			}
+			turn = next
+		}
+		if item.section != nil {
+			// The turns a sub-agent's requests count end inside it
+			writeHTMLSection(bw, item.section, vars)
+			turn = max(turn, item.section.lastTurn())
+			sections = true
+			continue
+		}
+		writeHTMLEntry(bw, entry, vars)
+	}
+	if sections {
+		bw.WriteString(openTargetScript)
+	}
+	if turn > 0 {
+		writeCopyPrompt(bw, turn)
+		writeTurnPrompts(bw, opts.promptTitle(), turnPrompts(slices.Concat(opts.before, entries)))
+	}
+
+	writeHTMLFoot(bw)
+	return bw.Flush()
+}
+
+// writeHTMLEntry renders one timeline entry as a section, or as asides for
+// reviewer comments. Blank entries and turn dividers are skipped.
+func writeHTMLEntry(bw *bufio.Writer, entry timeline.Entry, vars map[string]string) {
+	if entry.Kind == timeline.KindTurnDivider {
+		return
+	}
+	if entry.Kind == annotations.Kind {
+		writeHTMLAnnotation(bw, entry)
+		return
+	}
+	body := entry.Text()
+	if strings.TrimSpace(body) == "" {
+		return
+	}
+	if anchor := AnchorID(entry.ID); anchor != "" {
+		fmt.Fprintf(bw, "<section class=\"entry entry-%s\" id=\"%s\"><a class=\"anchor\" href=\"#%s\">#</a>",
+			html.EscapeString(entry.Kind), html.EscapeString(anchor), html.EscapeString(anchor))
+	} else {
+		fmt.Fprintf(bw, "<section class=\"entry entry-%s\">", html.EscapeString(entry.Kind))
+	}
+	bw.WriteString("<pre>")
+	bw.WriteString(ansiToHTML(strings.TrimRight(body, "\n"), vars))
+	bw.WriteString("</pre></section>\n")
+}
+
+// EntryView is an entry as the HTML export shows it, for a page written with
+// WriteHTMLShell that builds its entries itself: the kind, anchor and styled
+// body of a block, or the comments of an annotation and the anchor of the
+// block they are on.
+type EntryView struct {
+	Kind     string   `json:"kind"`
+	Anchor   string   `json:"anchor,omitempty"`
+	Spans    []Span   `json:"spans,omitempty"`
+	Comments []string `json:"comments,omitempty"`
+}
+
+// View returns entry as the HTML export shows it. It reports false for the
+// entries the export skips: blank entries and turn dividers.
+func View(entry timeline.Entry) (EntryView, bool) {
+	switch {
+	case entry.Kind == timeline.KindTurnDivider:
+		return EntryView{}, false
+	case entry.Kind == annotations.Kind:
+		return EntryView{Kind: entry.Kind, Anchor: AnchorID(entry.ParentID), Comments: entry.Lines}, len(entry.Lines) > 0
+	}
+	body := entry.Text()
+	if strings.TrimSpace(body) == "" {
+		return EntryView{}, false
+	}
+	spans := ansiSpans(strings.TrimRight(body, "\n"), themeVars(renderTheme()))
+	return EntryView{Kind: entry.Kind, Anchor: AnchorID(entry.ID), Spans: spans}, true
+}
+
+// WriteHTMLShell writes an HTML export without entries whose body ends
+// with script, which adds the entries, from their View, as they arrive.
+func WriteHTMLShell(w io.Writer, opts Options, script string) error {
+	bw := bufio.NewWriter(w)
+	writeHTMLHead(bw, opts, pageTheme())
+	bw.WriteString(script)
+	writeHTMLFoot(bw)
+	return bw.Flush()
+}
+
+// pageTheme returns the dark palette of the page: the theme the session was
+// rendered with, or its dark variant when it was rendered on a light
+// terminal.
+func pageTheme() style.Theme {
+	if style.CurrentTheme.FgBase == "" {
+		return style.DefaultTheme // -no-color renders without a theme
+	}
+	return style.DarkTheme()
+}
+
+// renderTheme returns the theme the entry bodies were rendered with, whose
+// colors become the page's custom properties: the light variant on a light
+// terminal, so the bodies still follow the toggle.
+func renderTheme() style.Theme {
+	if style.CurrentTheme.FgBase == "" {
+		return style.DefaultTheme
+	}
+	return style.CurrentTheme
+}
+
+// writeHTMLHead writes the document up to and including its heading, with
+// the palettes of the dark theme and style.LightTheme.
+func writeHTMLHead(bw *bufio.Writer, opts Options, dark style.Theme) {
+	title := html.EscapeString(opts.title())
+	fmt.Fprintf(bw, `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+`, title)
+	writePalette(bw, ":root", "dark", dark)
+	bw.WriteString("@media (prefers-color-scheme: light) {\n")
+	writePalette(bw, ":root", "light", style.LightTheme)
+	bw.WriteString("}\n")
+	writePalette(bw, `:root[data-theme="dark"]`, "dark", dark)
+	writePalette(bw, `:root[data-theme="light"]`, "light", style.LightTheme)
+	fmt.Fprintf(bw, `body { background: var(--bg); color: var(--fg); font-family: ui-monospace, SFMono-Regular, Menlo, monospace; margin: 2rem; }
+h1 { font-size: 1.2rem; color: var(--accent); }
+a { color: var(--info); }
+.entry { position: relative; }
+.entry pre { margin: 0; white-space: pre-wrap; word-break: break-word; }
+.anchor { position: absolute; left: -1.5rem; color: var(--fg-subtle); text-decoration: none; visibility: hidden; }
+.entry:hover .anchor, .entry:target .anchor { visibility: visible; }
+.entry:target { background: var(--bg-subtle); }
+.annotation { margin: 0.25rem 0 1rem 1rem; padding: 0.25rem 0.75rem; border-left: 3px solid var(--accent); color: var(--fg-muted); white-space: pre-wrap; }
+.turn-end { margin: 0.25rem 0 1rem; }
+.subagent { margin: 0.25rem 0; padding-left: 0.75rem; border-left: 2px solid var(--bg-overlay); }
+.subagent > summary { color: var(--fg-muted); cursor: pointer; }
+.copy-prompt { background: var(--bg-subtle); color: var(--fg-muted); border: 1px solid var(--bg-overlay); border-radius: 4px; font: inherit; font-size: 0.8rem; cursor: pointer; opacity: 0.6; }
+.copy-prompt:hover, .copy-prompt:focus { opacity: 1; }
+#theme-toggle { position: fixed; top: 1rem; right: 1rem; background: var(--bg-subtle); color: var(--fg-muted); border: 1px solid var(--bg-overlay); border-radius: 4px; font: inherit; cursor: pointer; }
+</style>
+<script>
+try { var saved = localStorage.getItem("viewscreen-theme"); if (saved) document.documentElement.dataset.theme = saved; } catch (e) {}
+</script>
+</head>
+<body>
+<button id="theme-toggle" type="button" title="Toggle light/dark theme">◐</button>
+<h1>%s</h1>
+`, title)
+	writeHTMLNav(bw, opts.nav)
+}
+
+// writeHTMLNav writes the links between the chapters of a split export.
+func writeHTMLNav(bw *bufio.Writer, nav []link) {
+	if len(nav) == 0 {
+		return
+	}
+	bw.WriteString("<nav>")
+	for i, l := range nav {
+		if i > 0 {
+			bw.WriteString(" · ")
+		}
+		fmt.Fprintf(bw, `<a href="%s">%s</a>`, html.EscapeString(l.href), html.EscapeString(l.text))
+	}
+	bw.WriteString("</nav>\n")
+}
+
+// writeHTMLFoot closes the document.
+func writeHTMLFoot(bw *bufio.Writer) {
+	bw.WriteString(themeToggleScript)
+	bw.WriteString("</body>\n</html>\n")
+}
+
+// writeHTMLIndex renders the index page of a split export, linking to each
+// chapter.
+func writeHTMLIndex(w io.Writer, chapters []chapter, opts Options) error {
+	bw := bufio.NewWriter(w)
+	writeHTMLHead(bw, opts, pageTheme())
+	bw.WriteString("<ul>\n")
+	for _, c := range chapters {
+		fmt.Fprintf(bw, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(c.file), html.EscapeString(c.title()))
+	}
+	bw.WriteString("</ul>\n")
+	writeHTMLFoot(bw)
+	return bw.Flush()
+}
+
+// themeToggleScript switches the page to the theme it is not showing and
+// remembers the choice.
+const themeToggleScript = `<script>
+document.getElementById("theme-toggle").addEventListener("click", function () {
+  var root = document.documentElement;
+  var dark = root.dataset.theme ? root.dataset.theme === "dark" : !matchMedia("(prefers-color-scheme: light)").matches;
+  root.dataset.theme = dark ? "light" : "dark";
+  try { localStorage.setItem("viewscreen-theme", root.dataset.theme); } catch (e) {}
+});
+</script>
+`
+
+// writePalette writes a CSS rule setting a custom property for each color
+// role of t, e.g. --accent.
+func writePalette(bw *bufio.Writer, selector, scheme string, t style.Theme) {
+	colors := t.Colors()
+	fmt.Fprintf(bw, "%s { color-scheme: %s;", selector, scheme)
+	for _, role := range style.Roles() {
+		if c := colors[role]; c != "" {
+			fmt.Fprintf(bw, " --%s: %s;", role, c)
+		}
+	}
+	bw.WriteString(" }\n")
+}
+
+// themeVars maps the CSS colors of t's roles to the custom properties
+// writePalette defines, so text colored by the theme follows the page's
+// palette. Where roles share a color, the first role in Roles order wins.
+func themeVars(t style.Theme) map[string]string {
+	colors := t.Colors()
+	vars := make(map[string]string, len(colors))
+	for _, role := range style.Roles() {
+		c := strings.ToLower(string(colors[role]))
+		if _, taken := vars[c]; c != "" && !taken {
+			vars[c] = "var(--" + role + ")"
+		}
+	}
+	return vars
+}
+
+// writeHTMLAnnotation renders reviewer comments as an aside linked to the
+// block they reference.
+func writeHTMLAnnotation(bw *bufio.Writer, entry timeline.Entry) {
+	for _, comment := range entry.Lines {
+		if anchor := AnchorID(entry.ParentID); anchor != "" {
+			fmt.Fprintf(bw, "<aside class=\"annotation\" data-for=\"%s\">", html.EscapeString(anchor))
+		} else {
+			bw.WriteString("<aside class=\"annotation\">")
+		}
+		bw.WriteString(html.EscapeString(comment))
+		bw.WriteString("</aside>\n")
+	}
+}
+
+// sgrState is the subset of SGR attributes carried into HTML.
+type sgrState struct {
+	fg, bg                  string
+	bold, italic, underline bool
+}
+
+// css returns the inline style of s. Colors found in vars are replaced by
+// the custom property they map to.
+func (s sgrState) css(vars map[string]string) string {
+	var parts []string
+	if s.fg != "" {
+		parts = append(parts, "color:"+cssColor(s.fg, vars))
+	}
+	if s.bg != "" {
+		parts = append(parts, "background:"+cssColor(s.bg, vars))
+	}
+	if s.bold {
+		parts = append(parts, "font-weight:bold")
+	}
+	if s.italic {
+		parts = append(parts, "font-style:italic")
+	}
+	if s.underline {
+		parts = append(parts, "text-decoration:underline")
+	}
+	return strings.Join(parts, ";")
+}
+
+func cssColor(c string, vars map[string]string) string {
+	if v, ok := vars[c]; ok {
+		return v
+	}
+	return c
+}
+
+// ansiToHTML escapes text for HTML and converts SGR color sequences into
+// <span style> runs, replacing colors found in vars (see themeVars). Other
+// escape sequences (cursor movement, OSC) are dropped.
+func ansiToHTML(s string, vars map[string]string) string {
+	var sb strings.Builder
+	for _, span := range ansiSpans(s, vars) {
+		if span.Style == "" {
+			sb.WriteString(html.EscapeString(span.Text))
+			continue
+		}
+		sb.WriteString(`<span style="` + span.Style + `">`)
+		sb.WriteString(html.EscapeString(span.Text))
+		sb.WriteString("</span>")
+	}
+	return sb.String()
+}
+
+// Span is a run of entry text in one style: inline CSS declarations, or ""
+// for unstyled text.
+type Span struct {
+	Text  string `json:"text"`
+	Style string `json:"style,omitempty"`
+}
+
+// ansiSpans splits s into runs of one style, as ansiToHTML converts them.
+func ansiSpans(s string, vars map[string]string) []Span {
+	var spans []Span
+	var cur sgrState
+	var text strings.Builder
+
+	emit := func() {
+		if text.Len() > 0 {
+			spans = append(spans, Span{Text: text.String(), Style: cur.css(vars)})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		if s[i] != '\x1b' || i+1 >= len(s) {
+			j := strings.IndexByte(s[i:], '\x1b')
+			if j < 0 {
+				j = len(s) - i
+			} else if j == 0 {
+				j = 1
+			}
+			text.WriteString(s[i : i+j])
+			i += j
+			continue
+		}
+
+		switch s[i+1] {
+		case '[':
+			end := i + 2
+			for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+				end++
+			}
+			if end >= len(s) {
+				i = len(s)
+				continue
+			}
+			if s[end] == 'm' {
+				if next := applySGR(cur, s[i+2:end]); next != cur {
+					emit()
+					cur = next
+				}
+			}
+			i = end + 1
+		case ']':
+			// OSC: skip to BEL or ST (ESC \).
+			end := i + 2
+			for end < len(s) && s[end] != '\a' && !(s[end] == '\x1b' && end+1 < len(s) && s[end+1] == '\\') {
+				end++
+			}
+			if end < len(s) && s[end] == '\x1b' {
+				end++
+			}
+			i = end + 1
+		default:
+			i += 2
+		}
+	}
+	emit()
+	return spans
+}
+
+// applySGR applies a semicolon- (or colon-) separated SGR parameter list.
+func applySGR(st sgrState, params string) sgrState {
+	if params == "" {
+		return sgrState{}
+	}
+	fields := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	for i := 0; i < len(fields); i++ {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			st = sgrState{}
+		case n == 1:
+			st.bold = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = true
+		case n == 22:
+			st.bold = false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n == 39:
+			st.fg = ""
+		case n == 49:
+			st.bg = ""
+		case (n == 38 || n == 48) && i+1 < len(fields):
+			color, consumed := extendedColor(fields[i+1:])
+			i += consumed
+			if n == 38 {
+				st.fg = color
+			} else {
+				st.bg = color
+			}
+		case n >= 30 && n <= 37:
+			st.fg = ansi16[n-30]
+		case n >= 90 && n <= 97:
+			st.fg = ansi16[n-90+8]
+		case n >= 40 && n <= 47:
+			st.bg = ansi16[n-40]
+		case n >= 100 && n <= 107:
+			st.bg = ansi16[n-100+8]
+		}
+	}
+	return st
+}
+
+// extendedColor decodes the parameters following 38/48: "2;r;g;b" truecolor
+// or "5;n" 256-color. It returns the CSS color and parameters consumed.
+func extendedColor(fields []string) (string, int) {
+	switch fields[0] {
+	case "2":
+		if len(fields) < 4 {
+			return "", len(fields)
+		}
+		r, _ := strconv.Atoi(fields[1])
+		g, _ := strconv.Atoi(fields[2])
+		b, _ := strconv.Atoi(fields[3])
+		return fmt.Sprintf("#%02x%02x%02x", r&0xff, g&0xff, b&0xff), 4
+	case "5":
+		if len(fields) < 2 {
+			return "", len(fields)
+		}
+		n, _ := strconv.Atoi(fields[1])
+		return xterm256(n), 2
+	}
+	return "", 1
+}
+
+// ansi16 is the xterm default palette for the 16 basic colors.
+var ansi16 = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// xterm256 converts a 256-color palette index to a CSS hex color.
+func xterm256(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansi16[n]
+	case n < 232:
+		n -= 16
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[(n/6)%6], levels[n%6])
+	default:
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// writeMarkdown renders each entry as a plain-text fenced block. Entries with
+// an ID are preceded by an HTML anchor, which GitHub and most renderers honor
+// for #fragment links. The entries of each sub-agent are folded into a
+// section headed by its summary.
+func writeMarkdown(w io.Writer, entries []timeline.Entry, opts Options) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n", opts.title())
+	writeMarkdownNav(bw, opts.nav)
+
+	for _, item := range groupSubAgents(entries) {
+		if item.section != nil {
+			writeMarkdownSection(bw, item.section)
+			continue
+		}
+		writeMarkdownEntry(bw, item.entry, true)
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownEntry renders entry as a plain-text fenced block, anchored
+// when anchored is set, or as blockquotes for reviewer comments. Blank
+// entries and turn dividers are skipped.
+func writeMarkdownEntry(bw *bufio.Writer, entry timeline.Entry, anchored bool) {
+	if entry.Kind == timeline.KindTurnDivider {
+		return
+	}
+	if entry.Kind == annotations.Kind {
+		writeMarkdownAnnotation(bw, entry)
+		return
+	}
+	body := strings.TrimRight(ansi.Strip(entry.Text()), "\n")
+	if strings.TrimSpace(body) == "" {
+		return
+	}
+	bw.WriteString("\n")
+	if anchor := AnchorID(entry.ID); anchor != "" && anchored {
+		fmt.Fprintf(bw, "<a id=\"%s\"></a>\n\n", html.EscapeString(anchor))
+	}
+	fence := codeFence(body)
+	fmt.Fprintf(bw, "%stext\n%s\n%s\n", fence, body, fence)
+}
+
+// writeMarkdownNav writes the links between the chapters of a split export.
+func writeMarkdownNav(bw *bufio.Writer, nav []link) {
+	if len(nav) == 0 {
+		return
+	}
+	links := make([]string, len(nav))
+	for i, l := range nav {
+		links[i] = fmt.Sprintf("[%s](%s)", l.text, l.href)
+	}
+	fmt.Fprintf(bw, "\n%s\n", strings.Join(links, " · "))
+}
+
+// writeMarkdownIndex renders the index page of a split export, linking to
+// each chapter.
+func writeMarkdownIndex(w io.Writer, chapters []chapter, opts Options) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", opts.title())
+	for _, c := range chapters {
+		fmt.Fprintf(bw, "- [%s](%s)\n", c.title(), c.file)
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownAnnotation renders reviewer comments as a blockquote beneath
+// the block they reference.
+func writeMarkdownAnnotation(bw *bufio.Writer, entry timeline.Entry) {
+	for _, comment := range entry.Lines {
+		bw.WriteString("\n")
+		for i, line := range strings.Split(comment, "\n") {
+			if i == 0 {
+				line = "**Review:** " + line
+			}
+			fmt.Fprintf(bw, "> %s\n", line)
+		}
+	}
+}
+
+// codeFence returns a backtick fence longer than any backtick run in body so
+// tool output containing fences cannot terminate the block early.
+func codeFence(body string) string {
+	longest, run := 0, 0
+	for _, r := range body {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
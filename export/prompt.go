@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// turnPrompt is the Markdown of the entries of one turn. The HTML export
+// embeds them so its "copy as prompt" buttons can put the session up to a
+// turn on the clipboard, to re-ask or fork the conversation elsewhere.
+type turnPrompt struct {
+	Turn     int    `json:"turn"`
+	Markdown string `json:"markdown"`
+}
+
+// turnPrompts renders the entries of each turn as the Markdown export does,
+// without anchors. Entries before the first turn (session init) join it,
+// and entries without a turn stay with the turn before them.
+func turnPrompts(entries []timeline.Entry) []turnPrompt {
+	var turns []turnPrompt
+	turn := 0
+	for _, entry := range entries {
+		turn = max(turn, entry.Turn, 1)
+		var sb strings.Builder
+		bw := bufio.NewWriter(&sb)
+		writeMarkdownEntry(bw, entry, false)
+		bw.Flush()
+		if sb.Len() == 0 {
+			continue
+		}
+		if n := len(turns); n == 0 || turns[n-1].Turn != turn {
+			turns = append(turns, turnPrompt{Turn: turn})
+		}
+		turns[len(turns)-1].Markdown += sb.String()
+	}
+	return turns
+}
+
+// writeCopyPrompt writes the button copying the session up to turn.
+func writeCopyPrompt(bw *bufio.Writer, turn int) {
+	fmt.Fprintf(bw, "<div class=\"turn-end\"><button class=\"copy-prompt\" type=\"button\" data-turn=\"%d\" title=\"Copy the session up to turn %d as Markdown\">Copy as prompt</button></div>\n", turn, turn)
+}
+
+// writeTurnPrompts writes the Markdown of each turn as JSON, followed by
+// copyPromptScript. JSON escapes <, > and &, so no entry can end the script
+// element early.
+func writeTurnPrompts(bw *bufio.Writer, title string, turns []turnPrompt) {
+	data, _ := json.Marshal(struct {
+		Title string       `json:"title"`
+		Turns []turnPrompt `json:"turns"`
+	}{title, turns})
+	bw.WriteString(`<script type="application/json" id="turn-prompts">`)
+	bw.Write(data)
+	bw.WriteString("</script>\n")
+	bw.WriteString(copyPromptScript)
+}
+
+// copyPromptScript copies the title and the turns up to a button's turn to
+// the clipboard, falling back to a selected textarea where the Clipboard
+// API is unavailable.
+const copyPromptScript = `<script>
+(function () {
+  var data = JSON.parse(document.getElementById("turn-prompts").textContent);
+  function prompt(turn) {
+    var md = "# " + data.title + "\n";
+    data.turns.forEach(function (t) { if (t.turn <= turn) md += t.markdown; });
+    return md;
+  }
+  function fallback(text) {
+    var area = document.createElement("textarea");
+    area.value = text;
+    document.body.appendChild(area);
+    area.select();
+    document.execCommand("copy");
+    area.remove();
+  }
+  document.querySelectorAll(".copy-prompt").forEach(function (button) {
+    button.addEventListener("click", function () {
+      var text = prompt(Number(button.dataset.turn));
+      var done = function () {
+        button.textContent = "Copied";
+        setTimeout(function () { button.textContent = "Copy as prompt"; }, 1500);
+      };
+      if (navigator.clipboard) {
+        navigator.clipboard.writeText(text).then(done, function () { fallback(text); done(); });
+      } else {
+        fallback(text);
+        done();
+      }
+    });
+  });
+})();
+</script>
+`
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// link is a navigation link between the pages of a split export.
+type link struct {
+	href, text string
+}
+
+// chapter is one file of a split export: the entries of a range of turns.
+type chapter struct {
+	file        string // base name of the chapter file
+	first, last int    // turns covered
+	entries     []timeline.Entry
+}
+
+func (c chapter) title() string {
+	if c.first == c.last {
+		return fmt.Sprintf("Turn %d", c.first)
+	}
+	return fmt.Sprintf("Turns %d–%d", c.first, c.last)
+}
+
+// ChapterPath returns the path of chapter n (counted from 1) of a split
+// export to path, e.g. "session-002.html" for "session.html".
+func ChapterPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// WriteSplit renders a long session as chapter files of opts.Split turns
+// each, named by ChapterPath, and writes an index page linking them to
+// path. Each chapter links back to the index and to its neighbours.
+func WriteSplit(path string, entries []timeline.Entry, opts Options) error {
+	format := FormatForPath(path)
+	index := filepath.Base(path)
+	chapters := splitChapters(path, entries, max(opts.Split, 1))
+	var before []timeline.Entry
+	for i, c := range chapters {
+		nav := []link{{href: index, text: "Index"}}
+		if i > 0 {
+			nav = append(nav, link{href: chapters[i-1].file, text: "← " + chapters[i-1].title()})
+		}
+		if i+1 < len(chapters) {
+			nav = append(nav, link{href: chapters[i+1].file, text: chapters[i+1].title() + " →"})
+		}
+		copts := Options{Title: opts.title() + " — " + c.title(), nav: nav, before: before, sessionTitle: opts.title()}
+		err := createFile(filepath.Join(filepath.Dir(path), c.file), func(w io.Writer) error {
+			return Write(w, format, c.entries, copts)
+		})
+		if err != nil {
+			return err
+		}
+		before = append(before, c.entries...)
+	}
+	return createFile(path, func(w io.Writer) error {
+		if format == FormatHTML {
+			return writeHTMLIndex(w, chapters, opts)
+		}
+		return writeMarkdownIndex(w, chapters, opts)
+	})
+}
+
+// splitChapters groups entries into chapters of the given number of turns.
+// Entries without a turn (session init, annotations) stay with the entry
+// before them, or join the first chapter. Ranges with no entries get no
+// chapter.
+func splitChapters(path string, entries []timeline.Entry, turns int) []chapter {
+	var chapters []chapter
+	turn := 0
+	for _, entry := range entries {
+		turn = max(turn, entry.Turn)
+		first := max(turn-1, 0)/turns*turns + 1
+		if n := len(chapters); n == 0 || chapters[n-1].first != first {
+			chapters = append(chapters, chapter{
+				file:  filepath.Base(ChapterPath(path, n+1)),
+				first: first,
+			})
+		}
+		c := &chapters[len(chapters)-1]
+		c.entries = append(c.entries, entry)
+		c.last = max(c.first, turn)
+	}
+	return chapters
+}
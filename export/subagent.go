@@ -0,0 +1,158 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// exportItem is an entry of the main agent, or the section of the entries
+// of one Task call's sub-agent.
+type exportItem struct {
+	entry   timeline.Entry
+	section *subAgentSection
+}
+
+// subAgentSection is what the sub-agent of a Task call did, which exports
+// fold into a collapsible subsection headed by its summary.
+type subAgentSection struct {
+	taskID string
+	// arg is the argument of the Task call, its description.
+	arg     string
+	summary *timeline.SubAgent
+	entries []timeline.Entry
+}
+
+// groupSubAgents gathers the entries of each sub-agent into a section where
+// its first entry was, so the entries of sub-agents running in parallel no
+// longer interleave. Reviewer comments follow the entry they reference into
+// its section. The result entry of the Task call stays with the main agent
+// and gives the section its summary.
+func groupSubAgents(entries []timeline.Entry) []exportItem {
+	var items []exportItem
+	sections := make(map[string]*subAgentSection)
+	inSection := make(map[string]*subAgentSection)
+	for _, entry := range entries {
+		section := sections[entry.TaskID]
+		if entry.Kind == annotations.Kind && entry.ParentID != "" {
+			section = inSection[entry.ParentID]
+		}
+		if entry.TaskID != "" && section == nil {
+			section = &subAgentSection{taskID: entry.TaskID}
+			sections[entry.TaskID] = section
+			items = append(items, exportItem{section: section})
+		}
+		if section == nil {
+			items = append(items, exportItem{entry: entry})
+		} else {
+			section.entries = append(section.entries, entry)
+			if entry.ID != "" {
+				inSection[entry.ID] = section
+			}
+		}
+		if s := entry.SubAgent; s != nil && sections[s.TaskID] != nil {
+			sections[s.TaskID].summary = s
+			sections[s.TaskID].arg = entry.Arg
+		}
+	}
+	return items
+}
+
+// lastTurn returns the turn of the section's last entry.
+func (s *subAgentSection) lastTurn() int {
+	turn := 0
+	for _, entry := range s.entries {
+		turn = max(turn, entry.Turn)
+	}
+	return turn
+}
+
+// heading returns the one-line summary of the section, e.g.
+// "Sub-agent: Explore the parser · 3 turns · ↑12k ↓1.2k tokens · Read ×4,
+// Grep ×2". A sub-agent that has not returned yet has no summary.
+func (s *subAgentSection) heading() string {
+	parts := []string{"Sub-agent"}
+	if s.arg != "" {
+		parts[0] += ": " + s.arg
+	}
+	if sum := s.summary; sum != nil {
+		nf := numfmt.Default()
+		turns := "1 turn"
+		if sum.Turns != 1 {
+			turns = nf.Int(sum.Turns) + " turns"
+		}
+		parts = append(parts, turns)
+		if sum.InputTokens+sum.OutputTokens > 0 {
+			parts = append(parts, fmt.Sprintf("↑%s ↓%s tokens", nf.Tokens(sum.InputTokens), nf.Tokens(sum.OutputTokens)))
+		}
+		if tools := toolCounts(sum.Tools); tools != "" {
+			parts = append(parts, tools)
+		}
+	} else {
+		parts = append(parts, "running")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// toolCounts lists tool calls by name, most called first, e.g.
+// "Read ×4, Grep ×2".
+func toolCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s ×%d", name, counts[name])
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeMarkdownSection writes a section as a <details> block, which GitHub
+// and most renderers fold.
+func writeMarkdownSection(bw *bufio.Writer, s *subAgentSection) {
+	fmt.Fprintf(bw, "\n<details>\n<summary>%s</summary>\n", html.EscapeString(s.heading()))
+	for _, entry := range s.entries {
+		writeMarkdownEntry(bw, entry, true)
+	}
+	bw.WriteString("\n</details>\n")
+}
+
+// writeHTMLSection writes a section as a folded <details> element.
+func writeHTMLSection(bw *bufio.Writer, s *subAgentSection, vars map[string]string) {
+	fmt.Fprintf(bw, "<details class=\"subagent\" data-task=\"%s\"><summary>%s</summary>\n",
+		html.EscapeString(s.taskID), html.EscapeString(s.heading()))
+	for _, entry := range s.entries {
+		writeHTMLEntry(bw, entry, vars)
+	}
+	bw.WriteString("</details>\n")
+}
+
+// openTargetScript unfolds the sub-agent sections holding the block a link
+// points to, so anchors into them still land on the block.
+const openTargetScript = `<script>
+(function () {
+  function open() {
+    var target = location.hash && document.getElementById(decodeURIComponent(location.hash.slice(1)));
+    for (var el = target; el; el = el.parentElement) {
+      if (el.tagName === "DETAILS") el.open = true;
+    }
+    if (target) target.scrollIntoView();
+  }
+  window.addEventListener("hashchange", open);
+  open();
+})();
+</script>
+`
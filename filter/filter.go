@@ -0,0 +1,335 @@
+// Package filter trims a stream-json session to the events and content
+// blocks a viewer cares about, for the -only and -hide flags.
+//
+// Names match an event's "type" (assistant, user, result, system,
+// stream_event, or a Codex type such as item.completed), the type of a Codex
+// item (agent_message, command_execution, ...), or the type of a content
+// block inside a Claude message (text, thinking, tool_use, tool_result).
+// Codex reasoning items also match "thinking". Streamed deltas belong to the
+// assistant message and to the type of the block they build.
+//
+// Tools can also be muted (-mute-tool) or focused (-focus-tool): a hidden
+// tool's calls, results and sub-agent events are dropped. Tool names are
+// resolved with tools.MatchesName, so an MCP server name selects all of its
+// tools. Codex tool items are named by their type (command_execution,
+// file_change, ...).
+package filter
+
+import (
+	"encoding/json"
+
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+// Filter decides which input lines reach the renderers. A Filter tracks the
+// blocks of a streamed message, so use one per input stream.
+type Filter struct {
+	only map[string]bool
+	hide map[string]bool
+
+	mutedTools []string
+	focusTools []string
+
+	// streamBlocks maps the index of each open streamed block to its type,
+	// and streamHidden marks the open blocks of hidden tool calls.
+	streamBlocks map[int]string
+	streamHidden map[int]bool
+	// hiddenCalls holds the IDs of hidden tool calls, to drop their results
+	// and sub-agent events.
+	hiddenCalls map[string]bool
+}
+
+// Option configures a Filter.
+type Option func(*Filter)
+
+// WithMutedTools hides the calls and results of the named tools.
+func WithMutedTools(names []string) Option {
+	return func(f *Filter) {
+		f.mutedTools = names
+	}
+}
+
+// WithFocusTools hides the calls and results of every tool not named. Text,
+// thinking and other events are unaffected.
+func WithFocusTools(names []string) Option {
+	return func(f *Filter) {
+		f.focusTools = names
+	}
+}
+
+// New returns a Filter keeping only events and blocks named in only (all of
+// them when only is empty) and dropping those named in hide. hide wins when a
+// name is in both. New returns nil, which keeps everything, when there is
+// nothing to filter.
+func New(only, hide []string, opts ...Option) *Filter {
+	f := &Filter{
+		only:         set(only),
+		hide:         set(hide),
+		streamBlocks: make(map[int]string),
+		streamHidden: make(map[int]bool),
+		hiddenCalls:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if len(only) == 0 && len(hide) == 0 && len(f.mutedTools) == 0 && len(f.focusTools) == 0 {
+		return nil
+	}
+	return f
+}
+
+func set(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// Apply filters one input line. It returns the line to render, with hidden
+// content blocks removed, and false if the whole line should be skipped.
+// Lines that are not JSON objects pass through unchanged so parse errors are
+// still reported.
+func (f *Filter) Apply(line string) (string, bool) {
+	if f == nil {
+		return line, true
+	}
+	var ev map[string]json.RawMessage
+	if json.Unmarshal([]byte(line), &ev) != nil {
+		return line, true
+	}
+	if parent := stringField(ev, "parent_tool_use_id"); parent != "" && f.hiddenCalls[parent] {
+		return line, false
+	}
+	eventType := stringField(ev, "type")
+	names := []string{eventType}
+	switch eventType {
+	case metadata.Type, record.SkipType:
+		// The -tag preamble describes the whole session, and replay's skip
+		// markers the playback rather than an event.
+		return line, true
+	case "assistant", "user":
+		return f.applyMessage(line, ev, names)
+	case "stream_event":
+		return line, f.keepStream(ev["event"])
+	}
+	if item := objectField(ev, "item"); item != nil {
+		itemType := stringField(item, "type")
+		if name, ok := codexToolName(item); ok && !f.showTool(name) {
+			return line, false
+		}
+		names = append(names, itemType)
+		if itemType == "reasoning" {
+			names = append(names, "thinking")
+		}
+	}
+	return line, f.keep(names...)
+}
+
+// keep reports whether an event or block with the given names is kept.
+func (f *Filter) keep(names ...string) bool {
+	for _, name := range names {
+		if f.hide[name] {
+			return false
+		}
+	}
+	return f.selected(names...)
+}
+
+// selected reports whether -only includes any of names.
+func (f *Filter) selected(names ...string) bool {
+	if len(f.only) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if f.only[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// showTool reports whether the calls of the named tool are shown.
+func (f *Filter) showTool(name string) bool {
+	for _, selector := range f.mutedTools {
+		if tools.MatchesName(selector, name) {
+			return false
+		}
+	}
+	if len(f.focusTools) == 0 {
+		return true
+	}
+	for _, selector := range f.focusTools {
+		if tools.MatchesName(selector, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepBlock reports whether a content block passes the tool selection,
+// recording hidden tool calls so their results are dropped too.
+func (f *Filter) keepBlock(typ, id, name, toolUseID string) bool {
+	switch typ {
+	case "tool_use", "server_tool_use":
+		if !f.showTool(name) {
+			f.hiddenCalls[id] = true
+			return false
+		}
+	case "tool_result":
+		if f.hiddenCalls[toolUseID] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyMessage filters the content blocks of an assistant or user message.
+// A block is kept when the event as a whole is selected or the block's own
+// type is, so "-only tool_result" keeps the results out of user events.
+func (f *Filter) applyMessage(line string, ev map[string]json.RawMessage, names []string) (string, bool) {
+	for _, name := range names {
+		if f.hide[name] {
+			return line, false
+		}
+	}
+	eventSelected := f.selected(names...)
+
+	msg := objectField(ev, "message")
+	var blocks []json.RawMessage
+	if msg == nil || json.Unmarshal(msg["content"], &blocks) != nil || len(blocks) == 0 {
+		// Plain-text prompts have no blocks to select.
+		return line, eventSelected
+	}
+
+	kept := blocks[:0:0]
+	for _, block := range blocks {
+		var b struct {
+			Type      string `json:"type"`
+			ID        string `json:"id"`
+			Name      string `json:"name"`
+			ToolUseID string `json:"tool_use_id"`
+		}
+		_ = json.Unmarshal(block, &b)
+		if !f.keepBlock(b.Type, b.ID, b.Name, b.ToolUseID) {
+			continue
+		}
+		if f.hide[b.Type] || !eventSelected && !f.selected(b.Type) {
+			continue
+		}
+		kept = append(kept, block)
+	}
+	switch {
+	case len(kept) == 0:
+		return line, false
+	case len(kept) == len(blocks):
+		return line, true
+	}
+
+	content, err := json.Marshal(kept)
+	if err != nil {
+		return line, true
+	}
+	msg["content"] = content
+	if ev["message"], err = json.Marshal(msg); err != nil {
+		return line, true
+	}
+	out, err := json.Marshal(ev)
+	if err != nil {
+		return line, true
+	}
+	return string(out), true
+}
+
+// keepStream filters a streamed message event. Events building a block
+// follow the block's type; the message envelope follows "assistant".
+func (f *Filter) keepStream(raw json.RawMessage) bool {
+	var se struct {
+		Type         string `json:"type"`
+		Index        int    `json:"index"`
+		ContentBlock struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"content_block"`
+		Delta struct {
+			Type string `json:"type"`
+		} `json:"delta"`
+	}
+	_ = json.Unmarshal(raw, &se)
+
+	names := []string{"stream_event", "assistant"}
+	var blockType string
+	switch se.Type {
+	case "content_block_start":
+		blockType = se.ContentBlock.Type
+		f.streamBlocks[se.Index] = blockType
+		f.streamHidden[se.Index] = !f.keepBlock(blockType, se.ContentBlock.ID, se.ContentBlock.Name, "")
+	case "content_block_delta":
+		blockType = f.streamBlocks[se.Index]
+		if blockType == "" {
+			blockType = deltaBlockTypes[se.Delta.Type]
+		}
+	case "content_block_stop":
+		blockType = f.streamBlocks[se.Index]
+		delete(f.streamBlocks, se.Index)
+		if f.streamHidden[se.Index] {
+			delete(f.streamHidden, se.Index)
+			return false
+		}
+	default:
+		return f.keep(names...)
+	}
+	if f.streamHidden[se.Index] {
+		return false
+	}
+
+	for _, name := range names {
+		if f.hide[name] {
+			return false
+		}
+	}
+	if f.hide[blockType] {
+		return false
+	}
+	return f.selected(names...) || f.selected(blockType)
+}
+
+// codexToolName returns the tool name of a Codex item that shows a tool call:
+// its item type, or mcp__<server>__<tool> for MCP calls.
+func codexToolName(item map[string]json.RawMessage) (string, bool) {
+	switch itemType := stringField(item, "type"); itemType {
+	case "command_execution", "file_change", "web_search", "todo_list":
+		return itemType, true
+	case "mcp_tool_call":
+		return "mcp__" + stringField(item, "server") + "__" + stringField(item, "tool"), true
+	}
+	return "", false
+}
+
+// deltaBlockTypes maps stream delta types to the block type they build, for
+// deltas whose content_block_start was not seen.
+var deltaBlockTypes = map[string]string{
+	"text_delta":       "text",
+	"thinking_delta":   "thinking",
+	"signature_delta":  "thinking",
+	"input_json_delta": "tool_use",
+}
+
+// stringField returns the string value of key in obj, or "".
+func stringField(obj map[string]json.RawMessage, key string) string {
+	var s string
+	_ = json.Unmarshal(obj[key], &s)
+	return s
+}
+
+// objectField returns the object value of key in obj, or nil.
+func objectField(obj map[string]json.RawMessage, key string) map[string]json.RawMessage {
+	var m map[string]json.RawMessage
+	if json.Unmarshal(obj[key], &m) != nil {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	assistantLine  = `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Hi"},{"type":"tool_use","id":"t1","name":"Bash"}]}}`
+	toolResultLine = `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`
+	promptLine     = `{"type":"user","message":{"content":"fix the build"}}`
+	resultLine     = `{"type":"result","subtype":"success"}`
+	reasoningLine  = `{"type":"item.completed","item":{"type":"reasoning","text":"hmm"}}`
+)
+
+func TestNew_Empty(t *testing.T) {
+	f := New(nil, nil)
+	if f != nil {
+		t.Fatal("expected nil filter for no names")
+	}
+	if line, ok := f.Apply(resultLine); !ok || line != resultLine {
+		t.Errorf("nil filter Apply() = %q, %v", line, ok)
+	}
+}
+
+func TestFilter_Hide(t *testing.T) {
+	f := New(nil, []string{"tool_result", "thinking"})
+
+	line, ok := f.Apply(assistantLine)
+	if !ok || strings.Contains(line, "thinking") || !strings.Contains(line, `"Hi"`) || !strings.Contains(line, "tool_use") {
+		t.Errorf("assistant line = %q, %v; want thinking removed", line, ok)
+	}
+	if _, ok := f.Apply(toolResultLine); ok {
+		t.Error("expected a user event with only tool results to be dropped")
+	}
+	if line, ok := f.Apply(promptLine); !ok || line != promptLine {
+		t.Errorf("prompt line = %q, %v; want unchanged", line, ok)
+	}
+	if _, ok := f.Apply(reasoningLine); ok {
+		t.Error("expected Codex reasoning to match thinking")
+	}
+}
+
+func TestFilter_Only(t *testing.T) {
+	f := New([]string{"assistant", "result"}, nil)
+
+	if line, ok := f.Apply(assistantLine); !ok || line != assistantLine {
+		t.Errorf("assistant line = %q, %v; want unchanged", line, ok)
+	}
+	if line, ok := f.Apply(resultLine); !ok || line != resultLine {
+		t.Errorf("result line = %q, %v; want unchanged", line, ok)
+	}
+	for _, line := range []string{toolResultLine, promptLine, reasoningLine} {
+		if _, ok := f.Apply(line); ok {
+			t.Errorf("expected %s to be dropped", line)
+		}
+	}
+}
+
+func TestFilter_KeepsViewscreenEvents(t *testing.T) {
+	for _, line := range []string{
+		`{"type":"viewscreen_metadata","tags":{"repo":"viewscreen"}}`,
+		`{"type":"viewscreen_skip","skipped_ms":240000}`,
+	} {
+		for _, f := range []*Filter{New([]string{"assistant"}, nil), New(nil, []string{"viewscreen_metadata", "viewscreen_skip"})} {
+			if got, ok := f.Apply(line); !ok || got != line {
+				t.Errorf("line = %q, %v; want %s kept", got, ok, line)
+			}
+		}
+	}
+}
+
+func TestFilter_OnlyBlockType(t *testing.T) {
+	f := New([]string{"tool_result", "text"}, []string{"text"})
+
+	if line, ok := f.Apply(toolResultLine); !ok || line != toolResultLine {
+		t.Errorf("tool result line = %q, %v; want unchanged", line, ok)
+	}
+	// text is both selected and hidden: hide wins, leaving nothing.
+	if _, ok := f.Apply(assistantLine); ok {
+		t.Error("expected the assistant event to be dropped")
+	}
+}
+
+func TestFilter_Stream(t *testing.T) {
+	f := New(nil, []string{"thinking"})
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"type":"stream_event","event":{"type":"message_start"}}`, true},
+		{`{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"thinking"}}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta"}}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_stop","index":0}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"text"}}}`, true},
+		{`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta"}}}`, true},
+		{`{"type":"stream_event","event":{"type":"content_block_delta","index":3,"delta":{"type":"thinking_delta"}}}`, false},
+	}
+	for i, tt := range tests {
+		if _, ok := f.Apply(tt.line); ok != tt.want {
+			t.Errorf("line %d kept = %v, want %v", i, ok, tt.want)
+		}
+	}
+}
+
+func TestFilter_InvalidJSONPassesThrough(t *testing.T) {
+	f := New([]string{"result"}, nil)
+	if line, ok := f.Apply("not json"); !ok || line != "not json" {
+		t.Errorf("Apply() = %q, %v; want the line kept for error reporting", line, ok)
+	}
+}
+
+func TestFilter_MutedTools(t *testing.T) {
+	f := New(nil, nil, WithMutedTools([]string{"Read", "github"}))
+	tests := []struct {
+		name, line string
+		want       bool
+	}{
+		{"muted call", `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r1","name":"Read"}]}}`, false},
+		{"muted result", `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"r1"}]}}`, false},
+		{"mcp server", `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"g1","name":"mcp__github__search"}]}}`, false},
+		{"other tool", `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"b1","name":"Bash"}]}}`, true},
+		{"other result", `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"b1"}]}}`, true},
+		{"text", `{"type":"assistant","message":{"content":[{"type":"text","text":"Reading files"}]}}`, true},
+	}
+	for _, tt := range tests {
+		if _, ok := f.Apply(tt.line); ok != tt.want {
+			t.Errorf("%s: kept = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+
+	line, ok := f.Apply(`{"type":"assistant","message":{"content":[{"type":"text","text":"Hi"},{"type":"tool_use","id":"r2","name":"read"}]}}`)
+	if !ok || strings.Contains(line, "tool_use") {
+		t.Errorf("mixed message = %q, %v; want the Read call removed", line, ok)
+	}
+}
+
+func TestFilter_FocusTools(t *testing.T) {
+	f := New(nil, nil, WithFocusTools([]string{"Edit", "Task"}))
+	tests := []struct {
+		name, line string
+		want       bool
+	}{
+		{"unfocused call", `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r1","name":"Read"}]}}`, false},
+		{"focused call", `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"e1","name":"Edit"}]}}`, true},
+		{"focused result", `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"e1"}]}}`, true},
+		{"text", `{"type":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}`, true},
+		{"codex command", `{"type":"item.completed","item":{"type":"command_execution","command":"ls"}}`, false},
+		{"codex message", `{"type":"item.completed","item":{"type":"agent_message","text":"hi"}}`, true},
+	}
+	for _, tt := range tests {
+		if _, ok := f.Apply(tt.line); ok != tt.want {
+			t.Errorf("%s: kept = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestFilter_MutedSubAgent(t *testing.T) {
+	f := New(nil, nil, WithMutedTools([]string{"Task"}))
+	f.Apply(`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Task"}]}}`)
+	if _, ok := f.Apply(`{"type":"assistant","parent_tool_use_id":"t1","message":{"content":[{"type":"text","text":"sub-agent"}]}}`); ok {
+		t.Error("expected the muted Task's sub-agent events to be dropped")
+	}
+}
+
+func TestFilter_MutedStreamedCall(t *testing.T) {
+	f := New(nil, nil, WithMutedTools([]string{"Read"}))
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"type":"stream_event","event":{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"r1","name":"Read"}}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta"}}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_stop","index":1}}`, false},
+		{`{"type":"stream_event","event":{"type":"content_block_start","index":1,"content_block":{"type":"text"}}}`, true},
+		{`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"r1"}]}}`, false},
+	}
+	for i, tt := range tests {
+		if _, ok := f.Apply(tt.line); ok != tt.want {
+			t.Errorf("line %d kept = %v, want %v", i, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Package fixture captures golden-test fixtures from live sessions.
+//
+// A Capturer watches raw input lines and, the first time it sees a given
+// combination of event type and tool name, writes the line to its own
+// .jsonl file named after that combination (e.g. "user_bash.jsonl",
+// "system_init.jsonl", "stream_event_content_block_delta.jsonl"). Tool
+// results are written together with the assistant line that made the tool
+// call, so every fixture renders on its own. Existing files are never
+// overwritten, so repeated runs over the same directory only add new
+// combinations.
+//
+// Lines are sanitized before they are written: the home directory becomes
+// /home/user, session IDs are replaced with a fixed placeholder, and strings
+// that look like API keys or tokens are redacted.
+package fixture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/record"
+)
+
+// Extension is the file extension of written fixtures.
+const Extension = ".jsonl"
+
+// placeholders substituted by Sanitize.
+const (
+	sanitizedHome      = "/home/user"
+	sanitizedSessionID = "00000000-0000-0000-0000-000000000000"
+	redacted           = "REDACTED"
+)
+
+var (
+	sessionIDPattern = regexp.MustCompile(`"(session_id|sessionId|thread_id)":"[^"]*"`)
+	secretPattern    = regexp.MustCompile(`\b(sk-[A-Za-z0-9_-]{16,}|gh[pousr]_[A-Za-z0-9]{20,}|xox[abpr]-[A-Za-z0-9-]{10,})`)
+	nonWord          = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// event is the subset of stream-json and Codex event fields used to key
+// fixtures.
+type event struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	Event   *struct {
+		Type string `json:"type"`
+	} `json:"event"`
+	Item *struct {
+		Type string `json:"type"`
+	} `json:"item"`
+	Message *struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type contentBlock struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ToolUseID string `json:"tool_use_id"`
+}
+
+// Capturer writes one sanitized fixture per unique event/tool combination.
+// It is safe for concurrent use.
+type Capturer struct {
+	mu      sync.Mutex
+	dir     string
+	home    string
+	seen    map[string]bool
+	calls   map[string]toolCall // tool_use id -> originating call
+	written []string
+	err     error
+}
+
+type toolCall struct {
+	name string
+	line string // sanitized assistant line
+}
+
+// Option configures a Capturer.
+type Option func(*Capturer)
+
+// WithHomeDir sets the home directory replaced during sanitizing (default:
+// the current user's).
+func WithHomeDir(dir string) Option {
+	return func(c *Capturer) {
+		c.home = dir
+	}
+}
+
+// NewCapturer creates a Capturer writing fixtures into dir, creating it if
+// needed.
+func NewCapturer(dir string, opts ...Option) (*Capturer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating fixture directory: %w", err)
+	}
+	home, _ := os.UserHomeDir()
+	c := &Capturer{
+		dir:   dir,
+		home:  home,
+		seen:  make(map[string]bool),
+		calls: make(map[string]toolCall),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Record implements record.LineRecorder. Lines that are not JSON objects
+// are ignored.
+func (c *Capturer) Record(line string) error {
+	var e event
+	if err := json.Unmarshal([]byte(line), &e); err != nil || e.Type == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+
+	sanitized := Sanitize(line, c.home)
+	var tools []string
+	var prelude []string
+	for _, block := range contentBlocks(e) {
+		switch block.Type {
+		case "tool_use":
+			c.calls[block.ID] = toolCall{name: block.Name, line: sanitized}
+			tools = append(tools, block.Name)
+		case "tool_result":
+			if call, ok := c.calls[block.ToolUseID]; ok {
+				tools = append(tools, call.name)
+				prelude = append(prelude, call.line)
+			}
+		}
+	}
+
+	name := Name(e.Type, eventSubtype(e), tools)
+	if c.seen[name] {
+		return nil
+	}
+	c.seen[name] = true
+
+	path := filepath.Join(c.dir, name+Extension)
+	lines := append(dedupe(prelude), sanitized)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		c.err = fmt.Errorf("writing fixture: %w", err)
+		return c.err
+	}
+	_, err = io.WriteString(f, strings.Join(lines, "\n")+"\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		c.err = fmt.Errorf("writing fixture %s: %w", path, err)
+		return c.err
+	}
+	c.written = append(c.written, path)
+	return nil
+}
+
+// Written returns the fixture files created so far.
+func (c *Capturer) Written() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.written...)
+}
+
+// Close reports how many fixtures were captured and returns the first write
+// error, if any.
+func (c *Capturer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.written) > 0 {
+		diag.Default().Infof("captured %d new fixtures in %s", len(c.written), c.dir)
+	}
+	return c.err
+}
+
+// Name returns the fixture file name (without extension) for an event type,
+// its subtype and the tools it involves, e.g. "user_bash".
+func Name(eventType, subtype string, tools []string) string {
+	parts := []string{eventType}
+	if subtype != "" {
+		parts = append(parts, subtype)
+	}
+	unique := make(map[string]bool)
+	for _, tool := range tools {
+		unique[tool] = true
+	}
+	names := make([]string, 0, len(unique))
+	for tool := range unique {
+		names = append(names, tool)
+	}
+	sort.Strings(names)
+	parts = append(parts, names...)
+
+	name := nonWord.ReplaceAllString(strings.ToLower(strings.Join(parts, "_")), "_")
+	return strings.Trim(name, "_")
+}
+
+// eventSubtype is the secondary discriminator of an event: the subtype of
+// system and result events, the inner event type of stream events, or the
+// item type of Codex item events.
+func eventSubtype(e event) string {
+	switch {
+	case e.Subtype != "":
+		return e.Subtype
+	case e.Event != nil:
+		return e.Event.Type
+	case e.Item != nil:
+		return e.Item.Type
+	}
+	return ""
+}
+
+// contentBlocks returns the message content blocks of e. String content
+// (plain user prompts) has none.
+func contentBlocks(e event) []contentBlock {
+	if e.Message == nil {
+		return nil
+	}
+	var blocks []contentBlock
+	_ = json.Unmarshal(e.Message.Content, &blocks)
+	return blocks
+}
+
+func dedupe(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if len(out) == 0 || out[len(out)-1] != line {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// Sanitize replaces home, session IDs and secret-looking strings in line.
+func Sanitize(line, home string) string {
+	home = strings.TrimRight(home, "/")
+	if home != "" && home != sanitizedHome {
+		line = strings.ReplaceAll(line, home, sanitizedHome)
+	}
+	line = sessionIDPattern.ReplaceAllString(line, `"$1":"`+sanitizedSessionID+`"`)
+	return secretPattern.ReplaceAllString(line, redacted)
+}
+
+// TeeToDir wraps r so fixtures are captured from its lines into dir. When
+// dir is empty, r is returned unchanged. The returned Closer reports write
+// errors and must be closed once input is exhausted.
+func TeeToDir(dir string, r io.Reader) (io.Reader, io.Closer, error) {
+	if dir == "" {
+		return r, nopCloser{}, nil
+	}
+	c, err := NewCapturer(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record.TeeReader(r, c), c, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -92,7 +92,7 @@ func (s *Spinner) Frame() string {
 	// with surrounding escape codes when spinner frames are
 	// composed with other styled text.
 	uvStyle := &uv.Style{
-		Fg: style.ColorfulToRGBA(blended),
+		Fg: style.ProfileColor(style.ColorfulToRGBA(blended)),
 	}
 	return uvStyle.Styled(frame)
 }
@@ -160,7 +160,7 @@ func (i *StreamingIndicator) Show() {
 		// Subtle pulsing dot - use theme accent color via Ultraviolet for consistent styling
 		accent, _ := colorful.Hex(string(style.CurrentTheme.Accent))
 		uvStyle := &uv.Style{
-			Fg: style.ColorfulToRGBA(accent),
+			Fg: style.ProfileColor(style.ColorfulToRGBA(accent)),
 		}
 		ind = uvStyle.Styled("●")
 	}
@@ -0,0 +1,201 @@
+package ingest
+
+import (
+	"encoding/json"
+
+	"github.com/johnnyfreeman/viewscreen/sse"
+)
+
+// This file builds the Claude Code stream-json lines the adapters emit.
+
+func marshalLine(v any) string {
+	line, _ := json.Marshal(v)
+	return string(line)
+}
+
+// initLine is a system init line, shown as "Session Started".
+func initLine(sessionID, model, cwd string) string {
+	return marshalLine(map[string]any{
+		"type": "system", "subtype": "init", "session_id": sessionID, "model": model, "cwd": cwd,
+	})
+}
+
+// assistantLine is an assistant message holding content.
+func assistantLine(model string, content ...map[string]any) string {
+	message := map[string]any{"role": "assistant", "content": content}
+	if model != "" {
+		message["model"] = model
+	}
+	return marshalLine(map[string]any{"type": "assistant", "message": message})
+}
+
+func textBlock(text string) map[string]any {
+	return map[string]any{"type": "text", "text": text}
+}
+
+func toolUseBlock(id, name string, input map[string]any) map[string]any {
+	if input == nil {
+		input = map[string]any{}
+	}
+	return map[string]any{"type": "tool_use", "id": id, "name": name, "input": input}
+}
+
+// toolResultLine is a user message holding the result of tool call id.
+func toolResultLine(id, content string, isError bool) string {
+	return marshalLine(map[string]any{
+		"type": "user",
+		"message": map[string]any{"role": "user", "content": []map[string]any{{
+			"type": "tool_result", "tool_use_id": id, "content": content, "is_error": isError,
+		}}},
+	})
+}
+
+// result is the final result of a session.
+type result struct {
+	IsError    bool
+	Errors     []string
+	DurationMS int64
+	NumTurns   int
+	CostUSD    float64
+	Usage      usage
+}
+
+// usage is token usage in Claude's terms.
+type usage struct {
+	Input, Output, CacheRead, CacheCreation int
+}
+
+func (u usage) add(o usage) usage {
+	return usage{u.Input + o.Input, u.Output + o.Output, u.CacheRead + o.CacheRead, u.CacheCreation + o.CacheCreation}
+}
+
+func (u usage) fields() map[string]any {
+	return map[string]any{
+		"input_tokens":                u.Input,
+		"output_tokens":               u.Output,
+		"cache_read_input_tokens":     u.CacheRead,
+		"cache_creation_input_tokens": u.CacheCreation,
+	}
+}
+
+// resultLine is a result line, shown as the session summary.
+func resultLine(r result) string {
+	subtype := "success"
+	if r.IsError {
+		subtype = "error_during_execution"
+	}
+	line := map[string]any{
+		"type": "result", "subtype": subtype, "is_error": r.IsError,
+		"duration_ms": r.DurationMS, "num_turns": r.NumTurns,
+		"total_cost_usd": r.CostUSD, "usage": r.Usage.fields(),
+	}
+	if len(r.Errors) > 0 {
+		line["errors"] = r.Errors
+	}
+	return marshalLine(line)
+}
+
+// tool maps a tool of another agent to the Claude tool viewscreen renders
+// the same way, so it gets that tool's header and result rendering.
+type tool struct {
+	Name string
+	// Keys renames input fields to the Claude tool's.
+	Keys map[string]string
+}
+
+// claudeTool returns the Claude name and input for a call of the tool
+// name, or them unchanged for tools without a mapping.
+func claudeTool(tools map[string]tool, name string, input map[string]any) (string, map[string]any) {
+	t, ok := tools[name]
+	if !ok {
+		return name, input
+	}
+	renamed := make(map[string]any, len(input))
+	for k, v := range input {
+		if to, ok := t.Keys[k]; ok {
+			k = to
+		}
+		renamed[k] = v
+	}
+	return t.Name, renamed
+}
+
+// blocks streams content blocks as Anthropic Messages API events, which an
+// sse.Translator turns into stream_event lines followed by an assistant
+// line per finished block, as Claude Code streams partial messages. One
+// block is open at a time.
+type blocks struct {
+	api     *sse.Translator
+	started bool
+	open    string // type of the open block, "" for none
+	count   int    // blocks started in this message
+}
+
+func newBlocks() *blocks {
+	return &blocks{api: sse.NewTranslator()}
+}
+
+// startMessage starts a message unless one is streaming.
+func (b *blocks) startMessage(id, model string) []string {
+	if b.started {
+		return nil
+	}
+	b.started = true
+	b.count = 0
+	return b.emit(map[string]any{"type": "message_start", "message": map[string]any{
+		"id": id, "type": "message", "role": "assistant", "model": model, "content": []any{},
+	}})
+}
+
+// stopMessage stops the open block and the message, if any.
+func (b *blocks) stopMessage() []string {
+	lines := b.stopBlock()
+	if !b.started {
+		return lines
+	}
+	b.started = false
+	return append(lines, b.emit(map[string]any{"type": "message_stop"})...)
+}
+
+// startBlock starts a block of block's type unless one is open, stopping
+// the open block first. Tool calls always start a new block.
+func (b *blocks) startBlock(block map[string]any) []string {
+	kind, _ := block["type"].(string)
+	if b.open == kind && kind != "tool_use" {
+		return nil
+	}
+	lines := b.stopBlock()
+	b.open = kind
+	b.count++
+	return append(lines, b.emit(map[string]any{"type": "content_block_start", "index": b.count - 1, "content_block": block})...)
+}
+
+// stopBlock stops the open block, if any.
+func (b *blocks) stopBlock() []string {
+	if b.open == "" {
+		return nil
+	}
+	b.open = ""
+	return b.emit(map[string]any{"type": "content_block_stop", "index": b.count - 1})
+}
+
+// delta adds to the open block.
+func (b *blocks) delta(delta map[string]any) []string {
+	return b.emit(map[string]any{"type": "content_block_delta", "index": b.count - 1, "delta": delta})
+}
+
+// emit translates an API event to stream-json lines.
+func (b *blocks) emit(event map[string]any) []string {
+	return b.api.Event(sse.Event{Data: marshalLine(event)})
+}
+
+// lineType returns the type field of a JSON line, or "" when it has none.
+func lineType(line []byte) string {
+	var v struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(line, &v) != nil {
+		return ""
+	}
+	return v.Type
+}
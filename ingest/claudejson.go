@@ -0,0 +1,130 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// claudeJSON reads a Claude Code session saved as one JSON document rather
+// than a line per event: the array of stream-json events `claude -p
+// --output-format json --verbose` writes, or an object whose "messages"
+// hold the conversation. Elements that are bare API messages (a role and
+// content, without a stream-json type) become assistant and user lines.
+type claudeJSON struct {
+	dec  *json.Decoder
+	out  bytes.Buffer
+	open bool
+	done bool
+}
+
+func newClaudeJSON(r io.Reader) io.Reader {
+	return &claudeJSON{dec: json.NewDecoder(r)}
+}
+
+// isClaudeJSON reports whether the first line of a stream opens a session
+// document: a JSON array, which no line-based format starts with, or an
+// object of messages rather than an event. The first line of an indented
+// object is a bare "{", and of one on a single line the whole document.
+func isClaudeJSON(line []byte) bool {
+	if bytes.HasPrefix(line, []byte("[")) || bytes.Equal(line, []byte("{")) {
+		return true
+	}
+	rest, ok := bytes.CutPrefix(line, []byte("{"))
+	if !ok {
+		return false
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(rest), []byte(`"messages"`)) {
+		return true
+	}
+	var doc struct {
+		Type     *string           `json:"type"`
+		Messages []json.RawMessage `json:"messages"`
+	}
+	return json.Unmarshal(line, &doc) == nil && doc.Type == nil && doc.Messages != nil
+}
+
+func (c *claudeJSON) Read(p []byte) (int, error) {
+	for c.out.Len() == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if !c.open {
+			if err := c.openMessages(); err != nil {
+				return 0, err
+			}
+			c.open = true
+		}
+		if !c.dec.More() {
+			c.done = true
+			continue
+		}
+		var raw json.RawMessage
+		if err := c.dec.Decode(&raw); err != nil {
+			return 0, fmt.Errorf("claude-json: %w", err)
+		}
+		if line := claudeJSONLine(raw); line != "" {
+			c.out.WriteString(line)
+			c.out.WriteByte('\n')
+		}
+	}
+	return c.out.Read(p)
+}
+
+// openMessages reads up to the first element of the document's array of
+// events: the document itself, or its "messages" field.
+func (c *claudeJSON) openMessages() error {
+	tok, err := c.dec.Token()
+	if err != nil {
+		return fmt.Errorf("claude-json: %w", err)
+	}
+	switch tok {
+	case json.Delim('['):
+		return nil
+	case json.Delim('{'):
+	default:
+		return errors.New("claude-json: expected an array of events or an object with messages")
+	}
+	for c.dec.More() {
+		key, err := c.dec.Token()
+		if err != nil {
+			return fmt.Errorf("claude-json: %w", err)
+		}
+		if key == "messages" {
+			if tok, err := c.dec.Token(); err != nil || tok != json.Delim('[') {
+				return errors.New("claude-json: messages is not an array")
+			}
+			return nil
+		}
+		var skip json.RawMessage
+		if err := c.dec.Decode(&skip); err != nil {
+			return fmt.Errorf("claude-json: %w", err)
+		}
+	}
+	return errors.New("claude-json: no messages")
+}
+
+// claudeJSONLine returns the stream-json line of an element of the
+// document, or "" for one that is not an object.
+func claudeJSONLine(raw json.RawMessage) string {
+	var head struct {
+		Type string `json:"type"`
+		Role string `json:"role"`
+	}
+	if json.Unmarshal(raw, &head) != nil {
+		return ""
+	}
+	if head.Type == "" || head.Type == "message" {
+		if head.Role != "assistant" && head.Role != "user" {
+			return ""
+		}
+		return marshalLine(map[string]any{"type": head.Role, "message": raw})
+	}
+	var line bytes.Buffer
+	if json.Compact(&line, raw) != nil {
+		return ""
+	}
+	return line.String()
+}
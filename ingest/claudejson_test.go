@@ -0,0 +1,70 @@
+package ingest
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClaudeJSON_Array(t *testing.T) {
+	lines := convertFile(t, "../testdata/ingest/claude_session.json", FormatAuto)
+
+	want := []string{"system", "assistant", "user", "assistant", "result"}
+	if got := lineTypes(lines); !slices.Equal(got, want) {
+		t.Errorf("line types = %v, want %v", got, want)
+	}
+	if got := find(lines, "result")[0]; !strings.Contains(got, `"total_cost_usd":0.0123`) {
+		t.Errorf("expected the result passed through, got %s", got)
+	}
+}
+
+func TestClaudeJSON_Messages(t *testing.T) {
+	input := `{
+  "session_id": "s1",
+  "messages": [
+    {"role": "user", "content": "List the files"},
+    {"id": "msg_01", "type": "message", "role": "assistant", "content": [{"type": "text", "text": "README.md"}]},
+    {"type": "result", "subtype": "success", "num_turns": 1},
+    "not an event"
+  ]
+}`
+	lines := convert(t, strings.NewReader(input), "claude-json")
+
+	want := []string{"user", "assistant", "result"}
+	if got := lineTypes(lines); !slices.Equal(got, want) {
+		t.Errorf("line types = %v, want %v", got, want)
+	}
+	if got := find(lines, "assistant")[0]; !strings.Contains(got, `"text":"README.md"`) {
+		t.Errorf("expected the message wrapped in an assistant line, got %s", got)
+	}
+}
+
+func TestClaudeJSON_DetectsMessages(t *testing.T) {
+	for _, input := range []string{
+		"{\n  \"session_id\": \"s1\",\n  \"messages\": [{\"role\": \"user\", \"content\": \"hi\"}]\n}",
+		`{"messages": [{"role": "user", "content": "hi"}]}`,
+		`{"session_id": "s1", "messages": [{"role": "user", "content": "hi"}]}`,
+	} {
+		if got := lineTypes(convert(t, strings.NewReader(input), FormatAuto)); !slices.Equal(got, []string{"user"}) {
+			t.Errorf("line types of %q = %v, want the user message", input, got)
+		}
+	}
+	for _, line := range []string{
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"user","messages":[]}`,
+		`{"session_id": "s1"}`,
+	} {
+		if isClaudeJSON([]byte(line)) {
+			t.Errorf("isClaudeJSON(%s) = true, want a line-based event", line)
+		}
+	}
+}
+
+func TestClaudeJSON_Malformed(t *testing.T) {
+	for _, input := range []string{`{"session_id": "s1"}`, `"events"`, `[{"type":"system"}`} {
+		if _, err := io.ReadAll(Convert(strings.NewReader(input), "claude-json")); err == nil {
+			t.Errorf("expected an error for %s", input)
+		}
+	}
+}
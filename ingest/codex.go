@@ -0,0 +1,23 @@
+package ingest
+
+import "strings"
+
+// codex passes Codex CLI's `codex exec --json` events through: viewscreen
+// renders them natively (see package codex), so the adapter only
+// identifies the format.
+type codex struct{}
+
+func newCodex() Translator { return codex{} }
+
+func (codex) Translate(line string) []string { return []string{line} }
+
+// isCodex reports whether line is a Codex thread, turn or item event.
+func isCodex(line []byte) bool {
+	typ := lineType(line)
+	for _, prefix := range []string{"thread.", "turn.", "item."} {
+		if strings.HasPrefix(typ, prefix) {
+			return true
+		}
+	}
+	return false
+}
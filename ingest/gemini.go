@@ -0,0 +1,138 @@
+package ingest
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// geminiTools maps Gemini CLI's built-in tools to their Claude equivalents.
+var geminiTools = map[string]tool{
+	"run_shell_command":   {Name: "Bash"},
+	"read_file":           {Name: "Read", Keys: map[string]string{"absolute_path": "file_path"}},
+	"write_file":          {Name: "Write"},
+	"replace":             {Name: "Edit"},
+	"glob":                {Name: "Glob"},
+	"search_file_content": {Name: "Grep"},
+	"list_directory":      {Name: "LS"},
+	"web_fetch":           {Name: "WebFetch"},
+	"google_web_search":   {Name: "WebSearch"},
+	"write_todos":         {Name: "TodoWrite"},
+}
+
+// geminiEvent is a line of Gemini CLI's `--output-format stream-json`.
+type geminiEvent struct {
+	Type       string         `json:"type"`
+	Timestamp  string         `json:"timestamp"`
+	SessionID  string         `json:"session_id"`
+	Model      string         `json:"model"`
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	Delta      bool           `json:"delta"`
+	ToolName   string         `json:"tool_name"`
+	ToolID     string         `json:"tool_id"`
+	Parameters map[string]any `json:"parameters"`
+	Status     string         `json:"status"`
+	Output     string         `json:"output"`
+	Severity   string         `json:"severity"`
+	Message    string         `json:"message"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Stats *struct {
+		InputTokens  int   `json:"input_tokens"`
+		OutputTokens int   `json:"output_tokens"`
+		Cached       int   `json:"cached"`
+		DurationMS   int64 `json:"duration_ms"`
+	} `json:"stats"`
+}
+
+// geminiTypes are the event types of Gemini CLI's stream.
+var geminiTypes = []string{"init", "message", "tool_use", "tool_result", "error", "result"}
+
+// isGemini reports whether line is a Gemini CLI event: its init event, or
+// a timestamped event of its types (Claude's events carry no timestamp
+// field at the top level, opencode's carry a part).
+func isGemini(line []byte) bool {
+	var e struct {
+		Type      string          `json:"type"`
+		Timestamp json.RawMessage `json:"timestamp"`
+		SessionID string          `json:"session_id"`
+		Part      json.RawMessage `json:"part"`
+	}
+	if json.Unmarshal(line, &e) != nil || e.Part != nil {
+		return false
+	}
+	if e.Type == "init" && e.SessionID != "" {
+		return true
+	}
+	var ts string
+	return slices.Contains(geminiTypes, e.Type) && json.Unmarshal(e.Timestamp, &ts) == nil && ts != ""
+}
+
+// gemini translates Gemini CLI's stream-json output. Assistant text
+// streamed in deltas is streamed as a text block.
+type gemini struct {
+	blocks *blocks
+	model  string
+	turns  int
+}
+
+func newGemini() Translator {
+	return &gemini{blocks: newBlocks()}
+}
+
+func (t *gemini) Translate(line string) []string {
+	var e geminiEvent
+	if json.Unmarshal([]byte(line), &e) != nil {
+		return []string{line}
+	}
+	b := t.blocks
+	switch e.Type {
+	case "init":
+		t.model = e.Model
+		return []string{initLine(e.SessionID, e.Model, "")}
+	case "message":
+		if e.Role != "assistant" || e.Content == "" {
+			return nil
+		}
+		if !b.started {
+			t.turns++
+		}
+		if !e.Delta {
+			return append(b.stopMessage(), assistantLine(t.model, textBlock(e.Content)))
+		}
+		lines := b.startMessage("", t.model)
+		lines = append(lines, b.startBlock(textBlock(""))...)
+		return append(lines, b.delta(map[string]any{"type": "text_delta", "text": e.Content})...)
+	case "tool_use":
+		name, input := claudeTool(geminiTools, e.ToolName, e.Parameters)
+		return append(b.stopMessage(), assistantLine(t.model, toolUseBlock(e.ToolID, name, input)))
+	case "tool_result":
+		content := e.Output
+		if e.Error != nil && e.Error.Message != "" {
+			content = e.Error.Message
+		}
+		return append(b.stopMessage(), toolResultLine(e.ToolID, content, e.Status == "error"))
+	case "error":
+		message := e.Message
+		if e.Severity == "warning" {
+			message = "warning: " + message
+		}
+		return append(b.stopMessage(), marshalLine(map[string]any{
+			"type": "assistant", "error": message,
+			"message": map[string]any{"role": "assistant", "content": []any{}},
+		}))
+	case "result":
+		r := result{IsError: e.Status == "error", NumTurns: t.turns}
+		if e.Error != nil && e.Error.Message != "" {
+			r.Errors = []string{e.Error.Message}
+		}
+		if s := e.Stats; s != nil {
+			r.DurationMS = s.DurationMS
+			r.Usage = usage{Input: s.InputTokens - s.Cached, Output: s.OutputTokens, CacheRead: s.Cached}
+		}
+		return append(b.stopMessage(), resultLine(r))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGemini(t *testing.T) {
+	lines := convertFile(t, "../testdata/ingest/gemini.jsonl", FormatAuto)
+
+	want := []string{
+		"system",
+		"message_start", "content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "assistant", "message_stop",
+		"assistant", "user", "assistant", "user",
+		"message_start", "content_block_start", "content_block_delta", "content_block_stop", "assistant", "message_stop",
+		"result",
+	}
+	if got := lineTypes(lines); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("lines = %v\nwant %v", got, want)
+	}
+
+	assistant := find(lines, "assistant")
+	user := find(lines, "user")
+	for _, check := range []struct{ line, want string }{
+		{find(lines, "system")[0], `"model":"gemini-2.5-pro"`},
+		{assistant[0], `"text":"I'll read the file."`},
+		{assistant[1], `"input":{"file_path":"/src/app/main.go"},"name":"Read"`},
+		{assistant[2], `"name":"Bash"`},
+		{user[0], `"is_error":false,"tool_use_id":"read_file-1"`},
+		{user[1], `"content":"exit status 1","is_error":true`},
+		{find(lines, "result")[0], `"duration_ms":4100,"is_error":false,"num_turns":2`},
+		{find(lines, "result")[0], `"cache_read_input_tokens":800,"input_tokens":400,"output_tokens":100`},
+	} {
+		if !strings.Contains(check.line, check.want) {
+			t.Errorf("missing %s in %s", check.want, check.line)
+		}
+	}
+}
+
+func TestGemini_Errors(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"error","timestamp":"t","severity":"warning","message":"Loop detected"}`,
+		`{"type":"result","timestamp":"t","status":"error","error":{"type":"FatalAuthenticationError","message":"not logged in"}}`,
+	}, "\n")
+	lines := convert(t, strings.NewReader(input), "gemini")
+	if got := find(lines, "assistant"); len(got) != 1 || !strings.Contains(got[0], `"error":"warning: Loop detected"`) {
+		t.Errorf("warning = %v", got)
+	}
+	if got := find(lines, "result"); len(got) != 1 || !strings.Contains(got[0], `"errors":["not logged in"],"is_error":true`) {
+		t.Errorf("result = %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+// Package ingest normalizes input in formats other than Claude Code's to
+// the stream-json lines viewscreen renders, so one viewer works across agent
+// tools and model servers. Each format has an Adapter; Convert picks one by
+// name (the -format flag) or detects it from the first line of the stream.
+// The normalized lines then go through the same parser, recordings, exports
+// and summaries as Claude Code's own output.
+package ingest
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+	"github.com/johnnyfreeman/viewscreen/sse"
+)
+
+// FormatAuto detects the input format: a registered Adapter's when it
+// recognizes the first line, an Anthropic Messages API event stream, or
+// stream-json.
+const FormatAuto = "auto"
+
+// A Translator converts the lines of one stream to stream-json lines.
+type Translator interface {
+	// Translate returns the stream-json lines for one line of input.
+	Translate(line string) []string
+}
+
+// Adapter describes an input format.
+type Adapter struct {
+	// Name selects the format with -format.
+	Name string
+	// Detect reports whether the first line of a stream is in this format.
+	// Nil for formats that must be selected by name.
+	Detect func(line []byte) bool
+	// New returns a Translator for one stream.
+	New func() Translator
+	// Decode, when set instead of New, converts a whole stream at once,
+	// for formats that are one JSON document rather than a line per event.
+	Decode func(r io.Reader) io.Reader
+}
+
+// convert returns a reader of the stream-json lines of r, read as a.
+func (a Adapter) convert(r io.Reader) io.Reader {
+	if a.Decode != nil {
+		return a.Decode(r)
+	}
+	return newConverter(r, a.New())
+}
+
+var adapters []Adapter
+
+// Register adds an input format, replacing any of the same name.
+func Register(a Adapter) {
+	for i, existing := range adapters {
+		if existing.Name == a.Name {
+			adapters[i] = a
+			return
+		}
+	}
+	adapters = append(adapters, a)
+}
+
+// Lookup returns the format named name.
+func Lookup(name string) (Adapter, bool) {
+	for _, a := range adapters {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Adapter{}, false
+}
+
+// Formats lists the names -format accepts: FormatAuto, then the registered
+// formats.
+func Formats() []string {
+	names := []string{FormatAuto}
+	for _, a := range adapters {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// Convert returns a reader of the stream-json lines in r, read as format.
+// FormatAuto detects the format from the first non-blank line, and passes
+// input no adapter recognizes through unchanged.
+func Convert(r io.Reader, format string) io.Reader {
+	if a, ok := Lookup(format); ok {
+		return a.convert(r)
+	}
+	return jsonl.Sniff(r, func(line string, in io.Reader) io.Reader {
+		for _, a := range adapters {
+			if a.Detect != nil && a.Detect([]byte(strings.TrimSpace(line))) {
+				return a.convert(in)
+			}
+		}
+		return sse.Convert(in)
+	})
+}
+
+// converter reads the stream-json lines a Translator makes of each line.
+type converter struct {
+	scanner   *jsonl.Reader
+	translate Translator
+	out       bytes.Buffer
+	done      bool
+}
+
+func newConverter(r io.Reader, t Translator) *converter {
+	return &converter{scanner: jsonl.NewReader(r, nil), translate: t}
+}
+
+func (c *converter) Read(p []byte) (int, error) {
+	for c.out.Len() == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if !c.scanner.Scan() {
+			if err := c.scanner.Err(); err != nil {
+				return 0, err
+			}
+			c.done = true
+			continue
+		}
+		line := strings.TrimSpace(c.scanner.Text())
+		if line == "" {
+			continue
+		}
+		for _, out := range c.translate.Translate(line) {
+			c.out.WriteString(out)
+			c.out.WriteByte('\n')
+		}
+	}
+	return c.out.Read(p)
+}
+
+// builtinAdapters are the formats viewscreen understands out of the box.
+var builtinAdapters = []Adapter{
+	{Name: "codex", Detect: isCodex, New: newCodex},
+	{Name: "gemini", Detect: isGemini, New: newGemini},
+	{Name: "opencode", Detect: isOpencode, New: newOpencode},
+	{Name: "openai", New: newOpenAI},
+	{Name: "claude-json", Detect: isClaudeJSON, Decode: newClaudeJSON},
+}
+
+func init() {
+	for _, a := range builtinAdapters {
+		Register(a)
+	}
+}
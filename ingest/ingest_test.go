@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// convert reads r as format and decodes the stream-json lines.
+func convert(t *testing.T, r io.Reader, format string) []map[string]any {
+	t.Helper()
+	out, err := io.ReadAll(Convert(r, format))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func convertFile(t *testing.T, path, format string) []map[string]any {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	return convert(t, f, format)
+}
+
+// lineTypes returns the type of each line, with the API event type for
+// stream_event lines.
+func lineTypes(lines []map[string]any) []string {
+	var types []string
+	for _, line := range lines {
+		if ev, ok := line["event"].(map[string]any); ok {
+			types = append(types, ev["type"].(string))
+		} else {
+			types = append(types, line["type"].(string))
+		}
+	}
+	return types
+}
+
+// find returns the lines of type typ, marshalled.
+func find(lines []map[string]any, typ string) []string {
+	var found []string
+	for _, line := range lines {
+		if line["type"] == typ {
+			b, _ := json.Marshal(line)
+			found = append(found, string(b))
+		}
+	}
+	return found
+}
+
+func TestFormats(t *testing.T) {
+	want := []string{FormatAuto, "codex", "gemini", "opencode", "openai", "claude-json"}
+	if got := Formats(); !slices.Equal(got, want) {
+		t.Errorf("Formats() = %v, want %v", got, want)
+	}
+	if _, ok := Lookup(FormatAuto); ok {
+		t.Error("expected auto not to be an adapter")
+	}
+}
+
+func TestConvert_Detects(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"claude", `{"type":"system","subtype":"init","model":"claude-sonnet-4-5"}`, "system"},
+		{"codex", `{"type":"thread.started","thread_id":"t1"}`, "thread.started"},
+		{"gemini", `{"type":"init","timestamp":"2026-01-05T10:00:00Z","session_id":"s","model":"gemini-2.5-pro"}`, "system"},
+		{"opencode", `{"type":"text","sessionID":"ses_01","part":{"type":"text","text":"hi"}}`, "assistant"},
+		{"claude json", `[{"type":"system","subtype":"init","model":"claude-sonnet-4-5"}]`, "system"},
+		{"anthropic api", "event: ping\ndata: {\"type\":\"ping\"}\n\nevent: message_stop\ndata: {\"type\":\"message_stop\"}", "stream_event"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := convert(t, strings.NewReader("\n"+tt.input+"\n"), FormatAuto)
+			if got := lines[0]["type"]; got != tt.want {
+				t.Errorf("first line type = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert_PassesStreamJSONThrough(t *testing.T) {
+	input := "{\"type\":\"system\"}\n{\"type\":\"result\"}\n"
+	for _, format := range []string{FormatAuto, "codex"} {
+		got, err := io.ReadAll(Convert(strings.NewReader(input), format))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != input {
+			t.Errorf("%s: Convert changed stream-json input: %q", format, got)
+		}
+	}
+
+	boom := errors.New("boom")
+	if _, err := io.ReadAll(Convert(io.MultiReader(strings.NewReader("{}"), iotest.ErrReader(boom)), FormatAuto)); !errors.Is(err, boom) {
+		t.Errorf("read error = %v, want %v", err, boom)
+	}
+	if _, err := io.ReadAll(Convert(io.MultiReader(strings.NewReader("{}\n"), iotest.ErrReader(boom)), "gemini")); !errors.Is(err, boom) {
+		t.Errorf("read error = %v, want %v", err, boom)
+	}
+}
+
+func TestConvert_DetectsOnFirstRead(t *testing.T) {
+	src := &countingReader{r: strings.NewReader("{\"type\":\"system\"}\n")}
+	r := Convert(src, FormatAuto)
+	if src.reads != 0 {
+		t.Fatalf("Convert read its input %d times before the first Read", src.reads)
+	}
+	if got, _ := io.ReadAll(r); string(got) != "{\"type\":\"system\"}\n" {
+		t.Errorf("Convert = %q", got)
+	}
+}
+
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestRegister(t *testing.T) {
+	saved := slices.Clone(adapters)
+	defer func() { adapters = saved }()
+
+	upper := Adapter{Name: "upper", New: func() Translator { return translatorFunc(strings.ToUpper) }}
+	Register(upper)
+	Register(upper)
+	if n := len(Formats()); n != len(saved)+2 {
+		t.Errorf("expected the adapter registered once, got formats %v", Formats())
+	}
+	got, _ := io.ReadAll(Convert(strings.NewReader("a\n\nb\n"), "upper"))
+	if string(got) != "A\nB\n" {
+		t.Errorf("Convert(upper) = %q", got)
+	}
+}
+
+type translatorFunc func(string) string
+
+func (f translatorFunc) Translate(line string) []string { return []string{f(line)} }
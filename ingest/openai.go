@@ -0,0 +1,139 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/sse"
+)
+
+// openAI translates OpenAI-compatible chat.completion.chunk streams, as
+// many local model servers and agents emit them, framed as Server-Sent
+// Events ("data: {...}") or one chunk per line. Reasoning
+// (reasoning_content, as DeepSeek and vLLM stream it) becomes a thinking
+// block, content a text block and each tool call a tool_use block, so they
+// render like Claude's.
+//
+// Only the first choice is rendered. Blocks are streamed one at a time, so
+// arguments for a tool call after a later block has started are dropped.
+type openAI struct {
+	blocks *blocks
+	tools  map[int]bool // tool call indexes seen in this message
+	tool   int          // tool call index of the open tool_use block
+}
+
+func newOpenAI() Translator {
+	return &openAI{blocks: newBlocks(), tools: make(map[int]bool)}
+}
+
+// openAIChunk is a chat.completion.chunk, or the error some servers send
+// in its place.
+type openAIChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Type    string `json:"type"`
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
+			Reasoning        string `json:"reasoning"`
+			ToolCalls        []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// stopReasons maps OpenAI finish reasons to Anthropic stop reasons.
+var stopReasons = map[string]string{
+	"stop":           "end_turn",
+	"length":         "max_tokens",
+	"tool_calls":     "tool_use",
+	"function_call":  "tool_use",
+	"content_filter": "refusal",
+}
+
+// Translate returns the stream-json lines for one chunk. The "[DONE]"
+// sentinel ends the message. Lines that are already stream-json (they have
+// a type) pass through, as does data that is not JSON, so it is reported
+// like any malformed line.
+func (t *openAI) Translate(line string) []string {
+	data, framed := strings.CutPrefix(line, "data:")
+	if !framed && sse.IsStreamLine(line) {
+		return nil
+	}
+	data = strings.TrimSpace(data)
+	if data == "[DONE]" {
+		return t.blocks.stopMessage()
+	}
+	var c openAIChunk
+	if err := json.Unmarshal([]byte(data), &c); err != nil || c.Type != "" {
+		return []string{data}
+	}
+	b := t.blocks
+	if c.Error != nil {
+		lines := b.stopBlock()
+		return append(lines, b.emit(map[string]any{"type": "error", "error": c.Error})...)
+	}
+
+	if !b.started {
+		clear(t.tools)
+	}
+	lines := b.startMessage(c.ID, c.Model)
+	for _, choice := range c.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		d := choice.Delta
+		if reasoning := d.ReasoningContent + d.Reasoning; reasoning != "" {
+			lines = append(lines, b.startBlock(map[string]any{"type": "thinking", "thinking": ""})...)
+			lines = append(lines, b.delta(map[string]any{"type": "thinking_delta", "thinking": reasoning})...)
+		}
+		if d.Content != "" {
+			lines = append(lines, b.startBlock(textBlock(""))...)
+			lines = append(lines, b.delta(map[string]any{"type": "text_delta", "text": d.Content})...)
+		}
+		for _, call := range d.ToolCalls {
+			if !t.tools[call.Index] {
+				lines = append(lines, b.startBlock(toolUseBlock(call.ID, call.Function.Name, nil))...)
+				t.tools[call.Index] = true
+				t.tool = call.Index
+			}
+			if call.Function.Arguments != "" && b.open == "tool_use" && t.tool == call.Index {
+				lines = append(lines, b.delta(map[string]any{"type": "input_json_delta", "partial_json": call.Function.Arguments})...)
+			}
+		}
+		if choice.FinishReason != "" {
+			lines = append(lines, b.stopBlock()...)
+			reason := stopReasons[choice.FinishReason]
+			if reason == "" {
+				reason = choice.FinishReason
+			}
+			lines = append(lines, b.emit(map[string]any{"type": "message_delta", "delta": map[string]any{"stop_reason": reason}})...)
+		}
+	}
+	if u := c.Usage; u != nil {
+		cached := u.PromptTokensDetails.CachedTokens
+		lines = append(lines, b.emit(map[string]any{"type": "message_delta", "delta": map[string]any{},
+			"usage": usage{Input: u.PromptTokens - cached, Output: u.CompletionTokens, CacheRead: cached}.fields()})...)
+	}
+	return lines
+}
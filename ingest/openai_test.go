@@ -0,0 +1,65 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpenAI(t *testing.T) {
+	lines := convertFile(t, "../testdata/openai_chat.sse", "openai")
+
+	want := []string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "assistant",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "assistant",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop", "assistant",
+		"message_delta", "message_delta", "message_stop",
+	}
+	if got := lineTypes(lines); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("events = %v\nwant %v", got, want)
+	}
+
+	messages := find(lines, "assistant")
+	for i, want := range []string{
+		`"content":[{"thinking":"The user wants the weather.","type":"thinking"}]`,
+		`"content":[{"text":"Let me check the weather.","type":"text"}]`,
+		`"content":[{"id":"call_01","input":{"location":"Paris"},"name":"get_weather","type":"tool_use"}]`,
+	} {
+		if !strings.Contains(messages[i], want) || !strings.Contains(messages[i], `"model":"qwen3-coder"`) {
+			t.Errorf("message %d = %s, want %s", i, messages[i], want)
+		}
+	}
+
+	stop, _ := json.Marshal(lines[16]["event"])
+	usage, _ := json.Marshal(lines[17]["event"])
+	if !strings.Contains(string(stop), `"stop_reason":"tool_use"`) {
+		t.Errorf("finish reason = %s, want tool_use", stop)
+	}
+	if !strings.Contains(string(usage), `"cache_read_input_tokens":100,"input_tokens":20,"output_tokens":30`) {
+		t.Errorf("usage = %s", usage)
+	}
+}
+
+func TestOpenAI_LinesAndPassThrough(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"system","subtype":"init"}`,
+		`: keep-alive`,
+		`{"id":"c","model":"m","choices":[{"index":0,"delta":{"content":"hi"}},{"index":1,"delta":{"content":"ignored"}}]}`,
+		`{"id":"c","model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"error":{"type":"server_error","message":"model crashed"}}`,
+	}, "\n")
+	lines := convert(t, strings.NewReader(input+"\n"), "openai")
+	if lines[0]["type"] != "system" {
+		t.Errorf("stream-json line changed: %v", lines[0])
+	}
+	out, _ := json.Marshal(lines)
+	for _, want := range []string{`"text":"hi"`, `"stop_reason":"end_turn"`, `"text":"API Error: server_error: model crashed"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("missing %s in %s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "ignored") {
+		t.Errorf("expected only the first choice rendered: %s", out)
+	}
+}
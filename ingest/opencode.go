@@ -0,0 +1,154 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// opencodeTools maps opencode's built-in tools to their Claude equivalents.
+var opencodeTools = map[string]tool{
+	"bash":      {Name: "Bash"},
+	"read":      {Name: "Read", Keys: map[string]string{"filePath": "file_path"}},
+	"write":     {Name: "Write", Keys: map[string]string{"filePath": "file_path"}},
+	"edit":      {Name: "Edit", Keys: map[string]string{"filePath": "file_path", "oldString": "old_string", "newString": "new_string", "replaceAll": "replace_all"}},
+	"glob":      {Name: "Glob"},
+	"grep":      {Name: "Grep"},
+	"list":      {Name: "LS"},
+	"webfetch":  {Name: "WebFetch"},
+	"todowrite": {Name: "TodoWrite"},
+	"task":      {Name: "Task"},
+}
+
+// opencodeEvent is a line of `opencode run --format json`: an event
+// carrying a part of the assistant's message.
+type opencodeEvent struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionID"`
+	Part      struct {
+		Type   string  `json:"type"`
+		Text   string  `json:"text"`
+		Tool   string  `json:"tool"`
+		CallID string  `json:"callID"`
+		Reason string  `json:"reason"`
+		Cost   float64 `json:"cost"`
+		Tokens struct {
+			Input     int `json:"input"`
+			Output    int `json:"output"`
+			Reasoning int `json:"reasoning"`
+			Cache     struct {
+				Read  int `json:"read"`
+				Write int `json:"write"`
+			} `json:"cache"`
+		} `json:"tokens"`
+		State struct {
+			Status string         `json:"status"`
+			Input  map[string]any `json:"input"`
+			Output string         `json:"output"`
+			Error  string         `json:"error"`
+		} `json:"state"`
+		Time struct {
+			Start int64 `json:"start"`
+			End   int64 `json:"end"`
+		} `json:"time"`
+	} `json:"part"`
+	Error *struct {
+		Name string `json:"name"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+// isOpencode reports whether line is an opencode event, which carries the
+// session ID and a message part.
+func isOpencode(line []byte) bool {
+	var e struct {
+		SessionID string          `json:"sessionID"`
+		Part      json.RawMessage `json:"part"`
+	}
+	return json.Unmarshal(line, &e) == nil && e.SessionID != "" && e.Part != nil
+}
+
+// opencode translates opencode's JSON event output. Each step of the agent
+// is a turn; a step that finishes for any reason but calling tools ends
+// the session.
+type opencode struct {
+	started bool
+	turns   int
+	start   int64 // ms, when the first step started
+	cost    float64
+	usage   usage
+}
+
+func newOpencode() Translator {
+	return &opencode{}
+}
+
+func (t *opencode) Translate(line string) []string {
+	var e opencodeEvent
+	if json.Unmarshal([]byte(line), &e) != nil {
+		return []string{line}
+	}
+	p := e.Part
+	switch e.Type {
+	case "step_start":
+		t.turns++
+		if t.started {
+			return nil
+		}
+		t.started = true
+		t.start = p.Time.Start
+		return []string{initLine(e.SessionID, "", "")}
+	case "text":
+		if p.Text == "" {
+			return nil
+		}
+		return []string{assistantLine("", textBlock(p.Text))}
+	case "reasoning":
+		if p.Text == "" {
+			return nil
+		}
+		return []string{assistantLine("", map[string]any{"type": "thinking", "thinking": p.Text})}
+	case "tool_use":
+		name, input := claudeTool(opencodeTools, p.Tool, p.State.Input)
+		lines := []string{assistantLine("", toolUseBlock(p.CallID, name, input))}
+		switch p.State.Status {
+		case "completed":
+			lines = append(lines, toolResultLine(p.CallID, p.State.Output, false))
+		case "error":
+			lines = append(lines, toolResultLine(p.CallID, p.State.Error, true))
+		}
+		return lines
+	case "step_finish":
+		t.cost += p.Cost
+		t.usage = t.usage.add(usage{
+			Input:         p.Tokens.Input,
+			Output:        p.Tokens.Output + p.Tokens.Reasoning,
+			CacheRead:     p.Tokens.Cache.Read,
+			CacheCreation: p.Tokens.Cache.Write,
+		})
+		if p.Reason == "tool-calls" {
+			return nil
+		}
+		return []string{resultLine(t.result(p.Time.End, nil))}
+	case "error":
+		message := "error"
+		if e.Error != nil {
+			message = e.Error.Name
+			if e.Error.Data.Message != "" {
+				message = fmt.Sprintf("%s: %s", e.Error.Name, e.Error.Data.Message)
+			}
+		}
+		return []string{resultLine(t.result(0, []string{message}))}
+	}
+	return nil
+}
+
+// result is the session's result so far, ended at end (ms) when known.
+func (t *opencode) result(end int64, errors []string) result {
+	r := result{IsError: len(errors) > 0, Errors: errors, NumTurns: t.turns, CostUSD: t.cost, Usage: t.usage}
+	if end > 0 && t.start > 0 {
+		r.DurationMS = end - t.start
+	}
+	return r
+}
@@ -0,0 +1,41 @@
+package ingest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpencode(t *testing.T) {
+	lines := convertFile(t, "../testdata/ingest/opencode.jsonl", FormatAuto)
+
+	want := []string{"system", "assistant", "assistant", "user", "assistant", "user", "assistant", "result"}
+	if got := lineTypes(lines); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("lines = %v\nwant %v", got, want)
+	}
+
+	assistant := find(lines, "assistant")
+	user := find(lines, "user")
+	result := find(lines, "result")[0]
+	for _, check := range []struct{ line, want string }{
+		{find(lines, "system")[0], `"session_id":"ses_01"`},
+		{assistant[1], `"input":{"command":"ls","description":"List files"},"name":"Bash"`},
+		{assistant[2], `"input":{"file_path":"/src/app/missing.go"},"name":"Read"`},
+		{user[0], `"content":"go.mod\nmain.go\n","is_error":false`},
+		{user[1], `"content":"File not found","is_error":true`},
+		{result, `"duration_ms":4500,"is_error":false,"num_turns":2`},
+		{result, `"total_cost_usd":0.003`},
+		{result, `"cache_read_input_tokens":900,"input_tokens":950,"output_tokens":52`},
+	} {
+		if !strings.Contains(check.line, check.want) {
+			t.Errorf("missing %s in %s", check.want, check.line)
+		}
+	}
+}
+
+func TestOpencode_Error(t *testing.T) {
+	input := `{"type":"error","timestamp":1,"sessionID":"ses_01","part":{},"error":{"name":"ProviderAuthError","data":{"message":"invalid key"}}}`
+	lines := convert(t, strings.NewReader(input), FormatAuto)
+	if got := find(lines, "result"); len(got) != 1 || !strings.Contains(got[0], `"errors":["ProviderAuthError: invalid key"],"is_error":true`) {
+		t.Errorf("result = %v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+)
+
+// Reader splits a stream of JSON events into one event per record, like a
+// Scanner of its lines, but recovers from what a line scanner cannot:
+//
+//   - lines of any length, where a Scanner stops at MaxScannerCapacity;
+//   - several JSON objects written on one line, returned one by one;
+//   - an object split across lines, joined back together;
+//   - a torn write, the start of an object cut off by the next one, which
+//     is skipped.
+//
+// Skipped bytes are logged as warnings. Lines that are not JSON objects are
+// returned unchanged, for the caller to report.
+type Reader struct {
+	br     *bufio.Reader
+	logger *diag.Logger
+
+	line     int      // lines read so far
+	pending  [][]byte // records split from the last line, not yet returned
+	torn     []byte   // an incomplete object waiting for the rest of it
+	tornLine int      // the line the incomplete object started on
+	record   []byte
+	err      error
+}
+
+// NewReader creates a Reader of the JSON events in r. Skipped input is
+// reported on logger; a nil logger uses diag.Default().
+func NewReader(r io.Reader, logger *diag.Logger) *Reader {
+	return &Reader{br: bufio.NewReader(r), logger: logger}
+}
+
+// Scan advances to the next record, which is then available through Bytes
+// or Text. It returns false at the end of the input or on a read error.
+func (r *Reader) Scan() bool {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			if len(r.torn) > 0 {
+				r.skip(r.torn, r.tornLine)
+				r.torn = nil
+			}
+			r.record = nil
+			return false
+		}
+		line, err := r.br.ReadBytes('\n')
+		r.err = err
+		if len(line) == 0 {
+			continue
+		}
+		r.line++
+		r.split(bytes.TrimRight(line, "\r\n"))
+	}
+	r.record, r.pending = r.pending[0], r.pending[1:]
+	return true
+}
+
+// Bytes returns the current record. It is valid until the next Scan.
+func (r *Reader) Bytes() []byte {
+	return r.record
+}
+
+// Text returns the current record as a string.
+func (r *Reader) Text() string {
+	return string(r.record)
+}
+
+// Err returns the read error that ended the input, or nil at its end.
+func (r *Reader) Err() error {
+	if errors.Is(r.err, io.EOF) {
+		return nil
+	}
+	return r.err
+}
+
+// split queues the records of line, completing an object torn across the
+// previous lines when line continues it.
+func (r *Reader) split(line []byte) {
+	if r.torn != nil {
+		// The break is dropped: between tokens it is only whitespace, and a
+		// write split inside a string must be joined without it.
+		joined := append(r.torn, line...)
+		values, _, err := decodeObjects(joined)
+		if len(values) == 0 && (!errors.Is(err, io.ErrUnexpectedEOF) || isObject(line)) {
+			// The line does not continue the object: it was cut off.
+			r.skip(r.torn, r.tornLine)
+		} else {
+			line = joined
+		}
+		r.torn = nil
+	}
+
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 {
+		return
+	}
+	if trimmed[0] != '{' {
+		r.pending = append(r.pending, line)
+		return
+	}
+	recovered := false
+	for len(trimmed) > 0 {
+		values, rest, err := decodeObjects(trimmed)
+		r.pending = append(r.pending, values...)
+		recovered = recovered || len(values) > 0
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			r.torn = bytes.Clone(rest)
+			r.tornLine = r.line
+			return
+		}
+		next := -1
+		if len(rest) > 1 {
+			next = bytes.IndexByte(rest[1:], '{')
+		}
+		if next < 0 && !recovered {
+			// Nothing to recover: leave the malformed line to the caller.
+			r.pending = append(r.pending, line)
+			return
+		}
+		if next < 0 {
+			r.skip(rest, r.line)
+			return
+		}
+		r.skip(rest[:next+1], r.line)
+		trimmed = rest[next+1:]
+		recovered = true
+	}
+}
+
+// skip logs that data, found on line n, was dropped.
+func (r *Reader) skip(data []byte, n int) {
+	logger := r.logger
+	if logger == nil {
+		logger = diag.Default()
+	}
+	logger.Warnf("skipped %d bytes of malformed input at line %d", len(data), n)
+}
+
+// decodeObjects decodes the JSON values in data, returning them and, when
+// one fails, the data from its start with the error.
+func decodeObjects(data []byte) (values [][]byte, rest []byte, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		start := dec.InputOffset()
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return values, nil, nil
+		} else if err != nil {
+			return values, bytes.TrimSpace(data[start:]), err
+		}
+		values = append(values, raw)
+	}
+}
+
+// isObject reports whether line holds a complete JSON object on its own.
+func isObject(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+	values, _, _ := decodeObjects(trimmed)
+	return len(values) > 0
+}
@@ -0,0 +1,125 @@
+package jsonl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+)
+
+func readAll(t *testing.T, input string) ([]string, string) {
+	t.Helper()
+	var log bytes.Buffer
+	r := NewReader(strings.NewReader(input), diag.New(&log, diag.LevelWarn))
+	var records []string
+	for r.Scan() {
+		records = append(records, r.Text())
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	return records, log.String()
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		log   string
+	}{
+		{
+			name:  "lines",
+			input: "{\"a\":1}\r\n\n  {\"b\":2}\n{\"c\":3}",
+			want:  []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+		},
+		{
+			name:  "concatenated objects",
+			input: `{"a":1}{"b":2} {"c":3}` + "\n",
+			want:  []string{`{"a":1}`, `{"b":2}`, `{"c":3}`},
+		},
+		{
+			name:  "object split across lines",
+			input: "{\"a\":\"hel\nlo\",\n\"b\":2}\n{\"c\":3}\n",
+			want:  []string{`{"a":"hello","b":2}`, `{"c":3}`},
+		},
+		{
+			name:  "torn write on its own line",
+			input: "{\"type\":\"assis\n{\"type\":\"user\"}\n",
+			want:  []string{`{"type":"user"}`},
+			log:   "warn: skipped 14 bytes of malformed input at line 1\n",
+		},
+		{
+			name:  "torn write on the same line",
+			input: `{"type":"assis{"type":"user"}` + "\n",
+			want:  []string{`{"type":"user"}`},
+			log:   "warn: skipped 14 bytes of malformed input at line 1\n",
+		},
+		{
+			name:  "trailing garbage",
+			input: `{"a":1} oops` + "\n",
+			want:  []string{`{"a":1}`},
+			log:   "warn: skipped 4 bytes of malformed input at line 1\n",
+		},
+		{
+			name:  "cut off by the end of input",
+			input: "{\"a\":1}\n{\"b\":",
+			want:  []string{`{"a":1}`},
+			log:   "warn: skipped 5 bytes of malformed input at line 2\n",
+		},
+		{
+			name:  "not JSON",
+			input: "plain text\n{not json}\n",
+			want:  []string{"plain text", "{not json}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, log := readAll(t, tt.input)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+			if log != tt.log {
+				t.Errorf("log = %q, want %q", log, tt.log)
+			}
+		})
+	}
+}
+
+func TestReader_LongLine(t *testing.T) {
+	long := `{"text":"` + strings.Repeat("x", MaxScannerCapacity+1) + `"}`
+	got, _ := readAll(t, long+"\n{\"b\":2}\n")
+	if len(got) != 2 || got[0] != long || got[1] != `{"b":2}` {
+		t.Errorf("expected the long line and the next, got %d records", len(got))
+	}
+}
+
+func TestReader_Err(t *testing.T) {
+	errRead := errors.New("read failed")
+	r := NewReader(&failingReader{data: "{\"a\":1}\n", err: errRead}, nil)
+	if !r.Scan() || r.Text() != `{"a":1}` {
+		t.Fatal("expected the record before the error")
+	}
+	if r.Scan() {
+		t.Fatal("expected Scan to stop at the error")
+	}
+	if !errors.Is(r.Err(), errRead) {
+		t.Errorf("Err() = %v, want %v", r.Err(), errRead)
+	}
+}
+
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
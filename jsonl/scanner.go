@@ -1,4 +1,6 @@
-// Package jsonl provides shared JSONL stream scanning helpers.
+// Package jsonl provides shared JSONL stream scanning helpers: a Scanner for
+// line-oriented input viewscreen writes itself, and a Reader that recovers
+// the events of agent output however its lines were written.
 package jsonl
 
 import (
@@ -6,7 +8,8 @@ import (
 	"io"
 )
 
-// MaxScannerCapacity is the maximum JSONL line size accepted by viewscreen.
+// MaxScannerCapacity is the maximum line size accepted by NewScanner. Reader
+// has no limit.
 const MaxScannerCapacity = 10 * 1024 * 1024
 
 // NewScanner creates a scanner configured for large agent JSONL events.
@@ -0,0 +1,51 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Sniff returns a reader of what choose makes of r, given r's first non-blank
+// line (or its last partial line, or "") and a reader replaying all of r.
+// The line is read on the first Read rather than by Sniff, so a caller
+// setting up a pipeline, such as the TUI before it starts, does not wait for
+// input to arrive.
+func Sniff(r io.Reader, choose func(line string, in io.Reader) io.Reader) io.Reader {
+	return &sniffer{src: r, choose: choose}
+}
+
+type sniffer struct {
+	src    io.Reader
+	choose func(string, io.Reader) io.Reader
+	r      io.Reader
+}
+
+func (s *sniffer) Read(p []byte) (int, error) {
+	if s.r == nil {
+		s.r = s.sniff()
+	}
+	return s.r.Read(p)
+}
+
+func (s *sniffer) sniff() io.Reader {
+	br := bufio.NewReader(s.src)
+	var head bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		head.WriteString(line)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return io.MultiReader(&head, errReader{err})
+		}
+		if strings.TrimSpace(line) == "" && err == nil {
+			continue
+		}
+		return s.choose(line, io.MultiReader(&head, br))
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
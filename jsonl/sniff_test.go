@@ -0,0 +1,39 @@
+package jsonl
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSniff(t *testing.T) {
+	var first string
+	r := Sniff(strings.NewReader("\n  \nfirst\nsecond\n"), func(line string, in io.Reader) io.Reader {
+		first = line
+		return in
+	})
+	if first != "" {
+		t.Fatalf("Sniff read the first line before the first Read")
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "first\n" {
+		t.Errorf("line = %q, want %q", first, "first\n")
+	}
+	if string(got) != "\n  \nfirst\nsecond\n" {
+		t.Errorf("Sniff did not replay its input: %q", got)
+	}
+
+	boom := errors.New("boom")
+	r = Sniff(io.MultiReader(strings.NewReader("{}"), iotest.ErrReader(boom)), func(_ string, in io.Reader) io.Reader {
+		t.Error("choose called after a read error")
+		return in
+	})
+	if _, err := io.ReadAll(r); !errors.Is(err, boom) {
+		t.Errorf("read error = %v, want %v", err, boom)
+	}
+}
@@ -0,0 +1,303 @@
+// Package live serves a session to browsers as it is rendered, so
+// teammates can watch an agent work without sharing a terminal. A Server
+// is a sink: every timeline entry it receives is broadcast over WebSocket,
+// as the export.View of it, to the page served at "/", which builds it the
+// way -export renders it. Viewers who join late are sent the session so far,
+// up to its last historySize entries. It is enabled with -serve.
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/export"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/worker"
+	"golang.org/x/net/websocket"
+)
+
+// SocketPath is where the page connects for entries.
+const SocketPath = "/ws"
+
+// viewerBuffer is how many entries a viewer may fall behind before it is
+// disconnected; a slow browser must not hold up rendering.
+const viewerBuffer = 256
+
+// historySize is how many of the latest entries are kept for viewers who
+// join late, so a long session does not grow without bound in memory.
+const historySize = 5000
+
+// Server broadcasts rendered entries to the browsers watching the session.
+// It is safe for concurrent use.
+type Server struct {
+	mu sync.Mutex
+	// history is a ring of the last historySize entries broadcast, as
+	// JSON; once it is full, the oldest is at first.
+	history []string
+	first   int
+	viewers map[chan string]struct{}
+}
+
+// NewServer creates a Server with no entries and no viewers.
+func NewServer() *Server {
+	return &Server{viewers: make(map[chan string]struct{})}
+}
+
+var (
+	mu         sync.RWMutex
+	defaultSrv *Server
+)
+
+// Default returns the process-wide Server, or nil when the session is not
+// served.
+func Default() *Server {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultSrv
+}
+
+// SetDefault replaces the process-wide Server.
+func SetDefault(s *Server) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultSrv = s
+}
+
+// Serve serves s on addr until g is closed: the page at "/" and its
+// WebSocket at SocketPath. The address is bound before Serve returns, so a
+// port already in use is reported immediately.
+func Serve(g *worker.Group, addr string, s *Server) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("-serve: %w", err)
+	}
+	srv := &http.Server{Handler: s.Handler(g.Context()), ReadHeaderTimeout: 5 * time.Second}
+	g.Go("live view server", func(ctx context.Context) error {
+		stop := context.AfterFunc(ctx, func() { srv.Close() })
+		defer stop()
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+// Handler returns the HTTP handler of the page and its WebSocket. Viewer
+// connections are closed when ctx is done.
+func (s *Server) Handler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = export.WriteHTMLShell(w, export.Options{Title: "viewscreen live"}, pageScript)
+	})
+	mux.Handle(SocketPath, websocket.Server{
+		Handshake: checkOrigin,
+		Handler:   func(ws *websocket.Conn) { s.watch(ctx, ws) },
+	})
+	return mux
+}
+
+// checkOrigin rejects WebSocket connections opened by pages of other sites,
+// which would otherwise be able to read the session from the viewer's
+// browser. The live page itself and pages served from this machine are
+// allowed, as are clients that send no Origin, which are not browsers.
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host != req.Host && !isLocalHost(u.Hostname()) {
+		return fmt.Errorf("live: origin %s not allowed", origin)
+	}
+	config.Origin = u
+	return nil
+}
+
+// isLocalHost reports whether host names this machine's loopback interface.
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// watch sends the session so far to a viewer, then each new entry, until
+// the viewer leaves, falls too far behind or ctx is done.
+func (s *Server) watch(ctx context.Context, ws *websocket.Conn) {
+	backlog, entries := s.join()
+	defer s.leave(entries)
+
+	left := make(chan struct{})
+	go func() {
+		// Viewers send nothing; reading only notices when they go away.
+		_, _ = io.Copy(io.Discard, ws)
+		close(left)
+	}()
+
+	for _, entry := range backlog {
+		if websocket.Message.Send(ws, entry) != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok || websocket.Message.Send(ws, entry) != nil {
+				return
+			}
+		case <-left:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// join registers a viewer, returning the entries broadcast so far and the
+// channel later ones arrive on.
+func (s *Server) join() ([]string, chan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make(chan string, viewerBuffer)
+	s.viewers[entries] = struct{}{}
+	return slices.Concat(s.history[s.first:], s.history[:s.first]), entries
+}
+
+func (s *Server) leave(entries chan string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.viewers, entries)
+}
+
+// broadcast records entry and sends it to every viewer. A viewer whose
+// buffer is full is dropped; its page reconnects and catches up from the
+// history.
+func (s *Server) broadcast(entry string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) < historySize {
+		s.history = append(s.history, entry)
+	} else {
+		s.history[s.first] = entry
+		s.first = (s.first + 1) % historySize
+	}
+	for entries := range s.viewers {
+		select {
+		case entries <- entry:
+		default:
+			delete(s.viewers, entries)
+			close(entries)
+		}
+	}
+}
+
+// Sink returns a sink.Sink broadcasting the entries of every event it
+// receives.
+func (s *Server) Sink() sink.Sink {
+	return serverSink{s}
+}
+
+type serverSink struct{ s *Server }
+
+func (w serverSink) Write(ev sink.Event) error {
+	for _, entry := range ev.Entries {
+		view, ok := export.View(entry)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(view)
+		if err != nil {
+			return err
+		}
+		w.s.broadcast(string(data))
+	}
+	return nil
+}
+
+func (serverSink) Close() error { return nil }
+
+// pageScript connects the page to the WebSocket and builds each entry from
+// its View with DOM APIs, so no text from the session is parsed as markup,
+// following the bottom of the session unless the viewer has scrolled up.
+// When the connection drops it keeps what was shown and retries; on
+// reconnecting the server resends the whole session, which replaces it.
+const pageScript = `<main id="session"></main>
+<p id="status">connecting…</p>
+<script>
+(function () {
+  var session = document.getElementById("session");
+  var status = document.getElementById("status");
+  var url = (location.protocol === "https:" ? "wss://" : "ws://") + location.host + "` + SocketPath + `";
+  function element(tag, className) {
+    var el = document.createElement(tag);
+    if (className) el.className = className;
+    return el;
+  }
+  function build(view) {
+    var frag = document.createDocumentFragment();
+    if (view.comments) {
+      view.comments.forEach(function (comment) {
+        var aside = element("aside", "annotation");
+        if (view.anchor) aside.setAttribute("data-for", view.anchor);
+        aside.textContent = comment;
+        frag.appendChild(aside);
+      });
+      return frag;
+    }
+    var section = element("section", "entry entry-" + view.kind);
+    if (view.anchor) {
+      section.id = view.anchor;
+      var link = element("a", "anchor");
+      link.href = "#" + view.anchor;
+      link.textContent = "#";
+      section.appendChild(link);
+    }
+    var pre = element("pre");
+    (view.spans || []).forEach(function (span) {
+      if (!span.style) {
+        pre.appendChild(document.createTextNode(span.text));
+        return;
+      }
+      var el = element("span");
+      el.setAttribute("style", span.style);
+      el.textContent = span.text;
+      pre.appendChild(el);
+    });
+    section.appendChild(pre);
+    frag.appendChild(section);
+    return frag;
+  }
+  function connect() {
+    var ws = new WebSocket(url);
+    var fresh = true;
+    ws.onopen = function () { status.textContent = "live"; };
+    ws.onmessage = function (e) {
+      if (fresh) { session.textContent = ""; fresh = false; }
+      var atBottom = innerHeight + scrollY >= document.body.scrollHeight - 40;
+      session.appendChild(build(JSON.parse(e.data)));
+      if (atBottom) scrollTo(0, document.body.scrollHeight);
+    };
+    ws.onclose = function () {
+      status.textContent = "disconnected, retrying…";
+      setTimeout(connect, 2000);
+    };
+  }
+  connect();
+})();
+</script>
+`
@@ -0,0 +1,165 @@
+package live
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/export"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+	"github.com/johnnyfreeman/viewscreen/worker"
+	"golang.org/x/net/websocket"
+)
+
+func entry(id, body string) sink.Event {
+	return sink.Event{Entries: []timeline.Entry{{ID: id, Kind: "assistant", Body: body}}}
+}
+
+func dial(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	t.Helper()
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+SocketPath, "", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func receive(t *testing.T, ws *websocket.Conn) export.EntryView {
+	t.Helper()
+	var view export.EntryView
+	if err := websocket.JSON.Receive(ws, &view); err != nil {
+		t.Fatal(err)
+	}
+	return view
+}
+
+func TestServer_Broadcast(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s.Handler(context.Background()))
+	defer srv.Close()
+
+	out := s.Sink()
+	if err := out.Write(entry("1", "first\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = out.Write(sink.Event{Raw: `{"type":"system"}`}) // no entries
+	_ = out.Write(entry("2", "  \n"))                   // blank
+
+	ws := dial(t, srv)
+	if got := receive(t, ws); got.Anchor != "evt-1" || len(got.Spans) != 1 || got.Spans[0].Text != "first" {
+		t.Errorf("backlog entry = %+v", got)
+	}
+
+	_ = out.Write(entry("3", "<second>\n"))
+	if got := receive(t, ws); len(got.Spans) != 1 || got.Spans[0].Text != "<second>" {
+		t.Errorf("live entry = %+v", got)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_Page(t *testing.T) {
+	srv := httptest.NewServer(NewServer().Handler(context.Background()))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(string(body), "<title>viewscreen live</title>") || !strings.Contains(string(body), `"`+SocketPath+`"`) {
+		t.Errorf("unexpected page:\n%s", body)
+	}
+	if strings.Contains(string(body), "insertAdjacentHTML") || strings.Contains(string(body), "innerHTML") {
+		t.Error("expected the page to build entries without parsing markup")
+	}
+
+	if resp, err := srv.Client().Get(srv.URL + "/missing"); err != nil || resp.StatusCode != 404 {
+		t.Errorf("GET /missing = %v, %v; want 404", resp, err)
+	}
+}
+
+func TestServer_DropsSlowViewers(t *testing.T) {
+	s := NewServer()
+	_, entries := s.join()
+	for range viewerBuffer + 1 {
+		s.broadcast("x")
+	}
+	if _, ok := <-entries; !ok {
+		t.Fatal("expected the buffered entries to remain readable")
+	}
+	for range entries {
+	}
+	if len(s.viewers) != 0 {
+		t.Errorf("expected the slow viewer dropped, have %d viewers", len(s.viewers))
+	}
+	if len(s.history) != viewerBuffer+1 {
+		t.Errorf("history has %d entries, want %d", len(s.history), viewerBuffer+1)
+	}
+}
+
+func TestServer_HistoryKeepsLatest(t *testing.T) {
+	s := NewServer()
+	for i := range historySize + 2 {
+		s.broadcast(strconv.Itoa(i))
+	}
+	backlog, _ := s.join()
+	if len(backlog) != historySize || backlog[0] != "2" || backlog[historySize-1] != strconv.Itoa(historySize+1) {
+		t.Errorf("backlog has %d entries from %s to %s, want the latest %d", len(backlog), backlog[0], backlog[len(backlog)-1], historySize)
+	}
+}
+
+func TestServer_RejectsOtherOrigins(t *testing.T) {
+	srv := httptest.NewServer(NewServer().Handler(context.Background()))
+	defer srv.Close()
+	socket := "ws" + strings.TrimPrefix(srv.URL, "http") + SocketPath
+
+	if _, err := websocket.Dial(socket, "", "https://evil.example"); err == nil {
+		t.Error("expected a connection from another site to be rejected")
+	}
+	for _, origin := range []string{srv.URL, "http://localhost:3000"} {
+		ws, err := websocket.Dial(socket, "", origin)
+		if err != nil {
+			t.Errorf("Dial from %s: %v", origin, err)
+			continue
+		}
+		ws.Close()
+	}
+}
+
+func TestServe(t *testing.T) {
+	g := worker.New(context.Background(), diag.New(&bytes.Buffer{}, diag.LevelOff))
+	if err := Serve(g, "127.0.0.1:0", NewServer()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("Close() = %v, want the server stopped cleanly", err)
+	}
+	if err := Serve(g, "not an address", NewServer()); err == nil || !strings.HasPrefix(err.Error(), "-serve: ") {
+		t.Errorf("Serve(bad address) = %v, want an error naming the flag", err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(nil)
+	if Default() != nil {
+		t.Fatal("expected no default Server")
+	}
+	s := NewServer()
+	SetDefault(s)
+	if Default() != s {
+		t.Error("expected SetDefault to set the default Server")
+	}
+}
@@ -1,15 +1,50 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/johnnyfreeman/viewscreen/agent"
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/budget"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/debugevents"
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/doctor"
+	"github.com/johnnyfreeman/viewscreen/filter"
+	"github.com/johnnyfreeman/viewscreen/ingest"
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+	"github.com/johnnyfreeman/viewscreen/live"
+	"github.com/johnnyfreeman/viewscreen/metrics"
+	"github.com/johnnyfreeman/viewscreen/notify"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/pagetitle"
 	"github.com/johnnyfreeman/viewscreen/parser"
+	"github.com/johnnyfreeman/viewscreen/perf"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/redact"
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/report"
+	"github.com/johnnyfreeman/viewscreen/sessions"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/source"
+	"github.com/johnnyfreeman/viewscreen/stats"
+	"github.com/johnnyfreeman/viewscreen/summary"
+	"github.com/johnnyfreeman/viewscreen/terminal"
+	"github.com/johnnyfreeman/viewscreen/tools"
 	"github.com/johnnyfreeman/viewscreen/tui"
+	"github.com/johnnyfreeman/viewscreen/update"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
+	"github.com/johnnyfreeman/viewscreen/worker"
 	"golang.org/x/term"
 )
 
@@ -19,11 +54,14 @@ var stdinReader io.Reader = os.Stdin
 
 // Runner encapsulates application dependencies for testability
 type Runner struct {
+	output        io.Writer
 	errOutput     io.Writer
 	parserFactory func() *parser.Parser
 	promptStarter func(string, io.Reader) (promptProcess, error)
 	exitFunc      func(int)
 	configOpts    []config.Option
+	updateOpts    []update.Option
+	doctorOpts    []doctor.Option
 }
 
 type promptProcess interface {
@@ -35,6 +73,13 @@ type promptProcess interface {
 // RunnerOption is a functional option for configuring a Runner
 type RunnerOption func(*Runner)
 
+// WithOutput sets a custom output writer for subcommand messages
+func WithOutput(w io.Writer) RunnerOption {
+	return func(r *Runner) {
+		r.output = w
+	}
+}
+
 // WithErrOutput sets a custom error output writer
 func WithErrOutput(w io.Writer) RunnerOption {
 	return func(r *Runner) {
@@ -70,9 +115,26 @@ func WithConfigOpts(opts ...config.Option) RunnerOption {
 	}
 }
 
+// WithUpdateOptions sets options for the updater used by "viewscreen update"
+// (for testing)
+func WithUpdateOptions(opts ...update.Option) RunnerOption {
+	return func(r *Runner) {
+		r.updateOpts = opts
+	}
+}
+
+// WithDoctorOptions sets extra options for the checks run by "viewscreen
+// doctor" (for testing)
+func WithDoctorOptions(opts ...doctor.Option) RunnerOption {
+	return func(r *Runner) {
+		r.doctorOpts = opts
+	}
+}
+
 // NewRunner creates a new Runner with default options
 func NewRunner(opts ...RunnerOption) *Runner {
 	r := &Runner{
+		output:        os.Stdout,
 		errOutput:     os.Stderr,
 		parserFactory: parser.NewParser,
 		promptStarter: func(prompt string, stdin io.Reader) (promptProcess, error) {
@@ -96,6 +158,112 @@ func (r *Runner) Run() {
 		return
 	}
 
+	if cfg.ToolDefsPath != "" {
+		if err := tools.LoadDefinitions(cfg.ToolDefsPath); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+			return
+		}
+	}
+	for server, field := range cfg.MCPHeaderFields {
+		tools.RegisterMCPServer(server, tools.ToolDefinition{HeaderField: field})
+	}
+	// Background work (title lookups, notifications, the metrics and live
+	// view servers) is cancelled and waited for when the run ends.
+	workers := worker.New(context.Background(), nil)
+	worker.SetDefault(workers)
+	defer workers.Close()
+
+	if cfg.ResolveTitles {
+		pagetitle.SetDefault(pagetitle.New(pagetitle.WithWorkers(workers)))
+	}
+	if cfg.MetricsListen != "" {
+		reg := metrics.NewRegistry()
+		if err := metrics.Serve(workers, cfg.MetricsListen, reg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+			return
+		}
+		metrics.SetDefault(reg)
+		defer metrics.SetDefault(nil)
+	}
+	if cfg.ServeAddr != "" {
+		srv := live.NewServer()
+		if err := live.Serve(workers, cfg.ServeAddr, srv); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+			return
+		}
+		live.SetDefault(srv)
+		defer live.SetDefault(nil)
+	}
+
+	if cfg.Command == config.CommandSessions {
+		if err := r.runSessionsPrune(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+	if err := autoRecord(cfg); err != nil {
+		diag.Default().Warnf("-auto-record: %v", err)
+	}
+
+	if cfg.Command == config.CommandReplay {
+		if err := r.runReplay(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.Command == config.CommandUpdate {
+		if err := r.runUpdate(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.Command == config.CommandBench {
+		if err := r.runBench(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.Command == config.CommandStats {
+		if err := r.runStats(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.Command == config.CommandReport {
+		if err := r.runReportDigest(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.Command == config.CommandDoctor {
+		if !r.runDoctor(cfg) {
+			r.exitFunc(1)
+		}
+		return
+	}
+
+	if cfg.InputPath != "" && cfg.InputPath != parser.StdinPath {
+		if err := r.runFile(cfg); err != nil {
+			fmt.Fprintf(r.errOutput, "%v\n", err)
+			r.exitFunc(1)
+		}
+		return
+	}
+
 	// Resolve prompt: positional args take priority, then -p reads stdin
 	prompt := cfg.Prompt
 	if prompt == "" && cfg.PromptMode {
@@ -159,12 +327,372 @@ func (r *Runner) Run() {
 
 	// Legacy mode: stream directly to stdout
 	p := r.parserFactory()
-	if err := p.Run(); err != nil {
+	if err := runParser(cfg, p); err != nil {
 		fmt.Fprintf(r.errOutput, "%v\n", err)
 		r.exitFunc(1)
 	}
 }
 
+// runParser runs p on its input as opened by source.Open, writing the
+// sink.Open outputs in the same pass as the terminal output. The parser
+// masks secrets when -redact is set, drops the events -only and -hide
+// exclude, renders -annotations comments inline and checks spending against
+// -budget as results arrive; with -low-memory it keeps nothing beyond the
+// event being rendered. With -page-on-exit, output taller than
+// -page-threshold goes to $PAGER instead of the terminal.
+//
+// The error is the first of: a failure to run or to close the inputs and
+// outputs; with -exit-on-error, a failed final session as a
+// *parser.SessionError; a -fail-on outcome as an *outcome.Error; and with
+// -watch-fail, tool calls that touched watched paths as a *watchlist.Error.
+func runParser(cfg *config.Config, p *parser.Parser) error {
+	if cfg.LowMemory {
+		p.LowMemory()
+	}
+	if cfg.PassNoise {
+		p.PassNoise()
+	}
+	p.TurnDivider(cfg.TurnDivider)
+	if !terminal.FixedWidth() && term.IsTerminal(int(os.Stdout.Fd())) {
+		resized := make(chan os.Signal, 1)
+		terminal.NotifyResize(resized)
+		defer signal.Stop(resized)
+		p.FollowResize(resized, terminal.Width)
+	}
+	if cfg.AnnotationsPath != "" {
+		set, err := annotations.Load(cfg.AnnotationsPath)
+		if err != nil {
+			return err
+		}
+		p.Annotate(set)
+	}
+	if cfg.Redact {
+		redactor, err := redact.New(cfg.RedactPatterns)
+		if err != nil {
+			return err
+		}
+		p.Redact(redactor)
+	}
+	p.Filter(filter.New(cfg.Only, cfg.Hide, filter.WithMutedTools(cfg.MuteTools), filter.WithFocusTools(cfg.FocusTools)))
+	p.Budget(budget.Budget{Limit: cfg.Budget, Stop: cfg.BudgetStop})
+	debugLog, err := debugevents.Create(cfg.DebugEventsPath)
+	if err != nil {
+		return err
+	}
+	p.DebugEvents(debugLog)
+	var input io.Closer
+	var inputErr error
+	p.WrapInput(func(in io.Reader) io.Reader {
+		wrapped, closer, err := source.Open(cfg, in)
+		if err != nil {
+			inputErr = err
+			return in
+		}
+		input = closer
+		return wrapped
+	})
+	if inputErr != nil {
+		return errors.Join(inputErr, debugLog.Close())
+	}
+	outputs, err := sink.Open(sink.Paths{Text: cfg.TextLogPath, JSON: cfg.EmitJSONPath, Export: cfg.ExportPath, ExportSplit: cfg.ExportSplit, Blame: cfg.BlamePath, Summary: cfg.SummaryJSONPath})
+	if err != nil {
+		return errors.Join(err, input.Close(), debugLog.Close())
+	}
+	p.AddSink(outputs)
+	if cfg.Notify {
+		p.AddSink(notify.Sink(notify.New(os.Stderr)))
+	}
+	if reg := metrics.Default(); reg != nil {
+		p.AddSink(reg.Sink())
+	}
+	if srv := live.Default(); srv != nil {
+		p.AddSink(srv.Sink())
+	}
+	var pager *terminal.Pager
+	if cfg.PageOnExit && term.IsTerminal(int(os.Stdout.Fd())) {
+		threshold := cfg.PageThreshold
+		if threshold <= 0 {
+			threshold = terminal.Height()
+		}
+		pager = terminal.NewPager(os.Getenv("PAGER"), threshold, os.Stdout, os.Stderr)
+		p.WrapOutput(func(io.Writer) io.Writer { return pager })
+	}
+	runErr := p.Run()
+	if err := input.Close(); runErr == nil {
+		runErr = err
+	}
+	if err := debugLog.Close(); runErr == nil {
+		runErr = err
+	}
+	if err := outputs.Close(); runErr == nil {
+		runErr = err
+	}
+	if pager != nil {
+		if err := pager.Close(); runErr == nil {
+			runErr = err
+		}
+	}
+	if runErr == nil && cfg.ExitOnError {
+		runErr = p.SessionError()
+	}
+	if o := p.Outcome(); runErr == nil && slices.Contains(cfg.FailOn, o) {
+		runErr = &outcome.Error{Outcome: o}
+	}
+	if n := p.WatchedPathCalls(); runErr == nil && cfg.WatchFail && n > 0 {
+		runErr = &watchlist.Error{Hits: n}
+	}
+	return runErr
+}
+
+// runReplay plays back a recorded session with its original timing, scaled by
+// the -speed flag and capped by -smooth, through the TUI or the legacy
+// streaming renderer. In step mode the TUI paces playback itself, so lines
+// are read without delay.
+func (r *Runner) runReplay(cfg *config.Config) error {
+	if cfg.Step && !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("-step requires a terminal")
+	}
+	f, err := os.Open(cfg.CommandArgs[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	speed := cfg.Speed
+	if cfg.Step {
+		speed = 0
+	}
+	var opts []record.PlayerOption
+	if cfg.Smooth {
+		opts = append(opts, record.WithMaxGap(cfg.SmoothGap))
+	}
+	return r.renderInput(cfg, record.NewPlayer(f, speed, opts...))
+}
+
+// runUpdate checks GitHub for a newer release and, unless -check-only is set,
+// installs it over the running binary.
+func (r *Runner) runUpdate(cfg *config.Config) error {
+	u, err := update.New(r.updateOpts...)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	release, err := u.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := update.CurrentVersion()
+	if !update.Newer(release.TagName, current) {
+		fmt.Fprintf(r.output, "viewscreen %s is up to date\n", current)
+		return nil
+	}
+	if cfg.CheckOnly {
+		fmt.Fprintf(r.output, "viewscreen %s is available (current: %s)\n", release.TagName, current)
+		if release.HTMLURL != "" {
+			fmt.Fprintln(r.output, release.HTMLURL)
+		}
+		return nil
+	}
+
+	if !u.Signed() {
+		fmt.Fprintln(r.errOutput, "warning: this build has no release key; checksums only detect a corrupted download, not a tampered release")
+	}
+	if err := u.Install(ctx, release); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.output, "Updated viewscreen %s -> %s\n", current, release.TagName)
+	return nil
+}
+
+// runDoctor prints environment diagnostics with suggested fixes. It returns
+// false if any check failed outright.
+func (r *Runner) runDoctor(cfg *config.Config) bool {
+	opts := append([]doctor.Option{
+		doctor.WithTerminal(term.IsTerminal(int(os.Stdout.Fd()))),
+		doctor.WithAgent(cfg.Agent),
+		doctor.WithAnnotations(cfg.AnnotationsPath),
+		doctor.WithOutputPaths(cfg.RecordPath, cfg.ExportPath, cfg.TextLogPath, cfg.EmitJSONPath, cfg.BlamePath, cfg.SummaryJSONPath),
+	}, r.doctorOpts...)
+
+	fmt.Fprintf(r.output, "viewscreen %s\n\n", update.CurrentVersion())
+	return doctor.Report(r.output, doctor.New(opts...).Checks()) != doctor.StatusFail
+}
+
+// runBench renders a transcript or recorded session -runs times without
+// displaying it, then prints the p50/p95 render time of each event kind.
+func (r *Runner) runBench(cfg *config.Config) error {
+	path := cfg.CommandArgs[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	inputs := make([]io.Reader, cfg.Runs)
+	for i := range inputs {
+		inputs[i] = bytes.NewReader(data)
+		if filepath.Ext(path) == record.Extension {
+			inputs[i] = record.NewPlayer(inputs[i], 0)
+		}
+	}
+
+	p := parser.NewParserWithOptions(
+		parser.WithInput(io.MultiReader(inputs...)),
+		parser.WithOutput(io.Discard),
+		parser.WithErrOutput(r.errOutput),
+	)
+	if err := p.Run(); err != nil {
+		return err
+	}
+	return perf.WriteTable(r.output, p.Timings().Stats())
+}
+
+// sessionsRoot returns the root of the managed recordings.
+func sessionsRoot(cfg *config.Config) (string, error) {
+	if cfg.SessionsDir != "" {
+		return cfg.SessionsDir, nil
+	}
+	return sessions.DefaultRoot()
+}
+
+// autoRecord points cfg.RecordPath at a new recording in the current
+// project's directory when -auto-record is set, after pruning the
+// directory. Only live sessions are recorded: subcommands, an explicit
+// -record and transcript files read without -follow are left alone.
+func autoRecord(cfg *config.Config) error {
+	streaming := cfg.InputPath == "" || cfg.InputPath == parser.StdinPath || cfg.Follow
+	if !cfg.AutoRecord || cfg.RecordPath != "" || cfg.Command != "" || !streaming {
+		return nil
+	}
+	root, err := sessionsRoot(cfg)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(root, sessions.Project(cwd))
+	now := time.Now()
+	if _, err := cfg.SessionsPolicy.Prune(dir, now); err != nil {
+		diag.Default().Warnf("-auto-record: pruning %s: %v", dir, err)
+	}
+	path, err := sessions.NewPath(dir, now)
+	if err != nil {
+		return err
+	}
+	cfg.RecordPath = path
+	return nil
+}
+
+// runSessionsPrune applies the retention policy to every project's
+// recordings and lists the recordings it removed.
+func (r *Runner) runSessionsPrune(cfg *config.Config) error {
+	root, err := sessionsRoot(cfg)
+	if err != nil {
+		return err
+	}
+	removed, err := cfg.SessionsPolicy.PruneAll(root, time.Now())
+	var freed int64
+	for _, rec := range removed {
+		fmt.Fprintf(r.output, "removed %s\n", rec.Path)
+		freed += rec.Size
+	}
+	fmt.Fprintf(r.output, "%d recordings removed, %.1f MB freed\n", len(removed), float64(freed)/(1<<20))
+	return err
+}
+
+// runStats prints the aggregate metrics of a transcript or recorded
+// session, as a table or, with -json, as JSON.
+func (r *Runner) runStats(cfg *config.Config) error {
+	path := cfg.CommandArgs[0]
+	f, err := parser.OpenInput(path, false)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var in io.Reader = f
+	if filepath.Ext(path) == record.Extension {
+		in = record.NewPlayer(in, 0)
+	} else {
+		in = ingest.Convert(in, cfg.Format)
+	}
+
+	c := summary.NewCollector()
+	scanner := jsonl.NewReader(in, nil)
+	for scanner.Scan() {
+		c.Observe(scanner.Bytes())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	st := stats.FromSummary(c.Summary())
+	if cfg.StatsJSON {
+		return stats.WriteJSON(r.output, st)
+	}
+	return stats.WriteTable(r.output, st)
+}
+
+// runReportDigest sums up the last week of recordings under the sessions
+// root as Markdown, written to -out or printed through the markdown
+// renderer. Recordings that cannot be read are reported after the digest
+// of the others.
+func (r *Runner) runReportDigest(cfg *config.Config) error {
+	root, err := sessionsRoot(cfg)
+	if err != nil {
+		return err
+	}
+	d, readErr := report.BuildDigest(root, time.Now())
+	if cfg.ReportOut == "" {
+		var sb strings.Builder
+		if err := report.WriteDigest(&sb, d); err != nil {
+			return err
+		}
+		fmt.Fprint(r.output, render.NewMarkdownRenderer(cfg.NoColor(), terminal.Width()).Render(sb.String()))
+		return readErr
+	}
+	f, err := os.Create(cfg.ReportOut)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteDigest(f, d); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.output, "wrote %s (%d sessions)\n", cfg.ReportOut, d.Sessions)
+	return readErr
+}
+
+// runFile renders a transcript file given as a positional argument, tailing
+// it when -follow is set.
+func (r *Runner) runFile(cfg *config.Config) error {
+	in, err := parser.OpenInput(cfg.InputPath, cfg.Follow)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return r.renderInput(cfg, in)
+}
+
+// renderInput renders stream-json read from in through the TUI when stdout is
+// a terminal, or the legacy streaming renderer otherwise.
+func (r *Runner) renderInput(cfg *config.Config, in io.Reader) error {
+	if !cfg.NoTUI && term.IsTerminal(int(os.Stdout.Fd())) {
+		content, err := tui.RunWithInput(in)
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+		if cfg.Dump && content != "" {
+			fmt.Print(content)
+		}
+		return nil
+	}
+
+	p := parser.NewParserWithOptions(parser.WithInput(in))
+	return runParser(cfg, p)
+}
+
 func (r *Runner) runPromptLegacy(prompt string) error {
 	proc, err := r.promptStarter(prompt, nil)
 	if err != nil {
@@ -179,7 +707,7 @@ func (r *Runner) runPromptLegacy(prompt string) error {
 	}
 
 	p := parser.NewParserWithOptions(parser.WithInput(stdout))
-	parseErr := p.Run()
+	parseErr := runParser(config.Get(), p)
 	waitErr := proc.Wait()
 	if parseErr != nil {
 		return parseErr
@@ -2,13 +2,27 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/doctor"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/outcome"
 	"github.com/johnnyfreeman/viewscreen/parser"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/sessions"
+	"github.com/johnnyfreeman/viewscreen/stats"
+	"github.com/johnnyfreeman/viewscreen/update"
 )
 
 // noopStyleInit prevents style.Init from affecting other tests
@@ -214,6 +228,76 @@ func TestRunner_Run_MultipleEvents(t *testing.T) {
 	r.Run()
 }
 
+func TestRunner_Run_ExitOnError(t *testing.T) {
+	input := `{"type":"result","subtype":"error_max_turns","is_error":true,"duration_ms":100}` + "\n"
+	for _, tt := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-no-tui", "-no-color"}, -1},
+		{[]string{"-no-tui", "-no-color", "-exit-on-error"}, 1},
+	} {
+		errBuf := &bytes.Buffer{}
+		exitCode := -1
+		r := NewRunner(
+			WithErrOutput(errBuf),
+			WithConfigOpts(config.WithArgs(tt.args)),
+			WithParserFactory(func() *parser.Parser {
+				return parser.NewParserWithOptions(
+					parser.WithInput(strings.NewReader(input)),
+					parser.WithOutput(io.Discard),
+				)
+			}),
+			WithExitFunc(func(code int) { exitCode = code }),
+		)
+		r.Run()
+
+		if exitCode != tt.want {
+			t.Errorf("%v: exit code %d, want %d", tt.args, exitCode, tt.want)
+		}
+		if tt.want == 1 && !strings.Contains(errBuf.String(), "session failed: error_max_turns") {
+			t.Errorf("%v: expected the failure on stderr, got %q", tt.args, errBuf.String())
+		}
+	}
+}
+
+func TestRunner_Run_FailOn(t *testing.T) {
+	defer outcome.SetDefault(outcome.DefaultRules)
+	partial := `{"type":"result","subtype":"success","is_error":false,"permission_denials":[{"tool_name":"Bash"}]}` + "\n"
+	for _, tt := range []struct {
+		args  []string
+		input string
+		want  int
+	}{
+		{[]string{"-no-tui", "-no-color", "-fail-on", "failed"}, partial, -1},
+		{[]string{"-no-tui", "-no-color", "-fail-on", "partial,failed"}, partial, 1},
+		{[]string{"-no-tui", "-no-color", "-fail-on", "partial", "-partial-denials", "1"}, partial, -1},
+		{[]string{"-no-tui", "-no-color", "-fail-on", "interrupted"}, "", 1},
+	} {
+		errBuf := &bytes.Buffer{}
+		exitCode := -1
+		r := NewRunner(
+			WithErrOutput(errBuf),
+			WithConfigOpts(config.WithArgs(tt.args)),
+			WithParserFactory(func() *parser.Parser {
+				return parser.NewParserWithOptions(
+					parser.WithInput(strings.NewReader(tt.input)),
+					parser.WithOutput(io.Discard),
+				)
+			}),
+			WithExitFunc(func(code int) { exitCode = code }),
+		)
+		r.Run()
+
+		if exitCode != tt.want {
+			t.Errorf("%v: exit code %d, want %d", tt.args, exitCode, tt.want)
+		}
+		if tt.want == 1 && !strings.Contains(errBuf.String(), "session outcome: ") {
+			t.Errorf("%v: expected the outcome on stderr, got %q", tt.args, errBuf.String())
+		}
+	}
+}
+
 func TestRunner_Run_PromptNoTUIStartsClaude(t *testing.T) {
 	proc := &fakePromptProcess{stdout: io.NopCloser(strings.NewReader(""))}
 	var gotPrompt string
@@ -255,6 +339,566 @@ func TestRunner_Run_PromptNoTUIStartsClaude(t *testing.T) {
 	}
 }
 
+func TestRunner_Run_RecordsLegacyInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session"+record.Extension)
+	input := `{"type":"result","subtype":"success","is_error":false,"duration_ms":100,"result":"done"}`
+
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-record", path})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input+"\n")),
+				parser.WithOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open recording: %v", err)
+	}
+	defer f.Close()
+	entry, err := record.NewReader(f).Next()
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	if entry.Line != input {
+		t.Errorf("recorded line = %q, want %q", entry.Line, input)
+	}
+}
+
+func TestRunner_Run_StrictLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strict.jsonl")
+	input := `{"type":"result","subtype":"success","is_error":false,"shiny_new_field":1}`
+
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-strict", "-strict-log", path})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input+"\n")),
+				parser.WithOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read strict log: %v", err)
+	}
+	want := `{"line":1,"event":"result","problem":"unknown_field","path":"shiny_new_field"}`
+	if got := strings.TrimSpace(string(data)); got != want {
+		t.Errorf("strict log = %q, want %q", got, want)
+	}
+}
+
+func TestRunner_Run_TagsRecordingAndOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session"+record.Extension)
+	input := `{"type":"result","subtype":"success","is_error":false,"duration_ms":100,"result":"done"}`
+
+	var out bytes.Buffer
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-no-color", "-record", path, "-tag", "repo=viewscreen", "-tag", "ticket=ENG-12"})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input+"\n")),
+				parser.WithOutput(&out),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	if got := out.String(); !strings.Contains(got, "repo: viewscreen") || !strings.Contains(got, "ticket: ENG-12") {
+		t.Errorf("expected the tags in the output, got %q", got)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open recording: %v", err)
+	}
+	defer f.Close()
+	reader := record.NewReader(f)
+	entry, err := reader.Next()
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	if meta, ok := metadata.Parse([]byte(entry.Line)); !ok || meta.Tags["ticket"] != "ENG-12" {
+		t.Errorf("first recorded line = %q, want the tag preamble", entry.Line)
+	}
+	if entry, err := reader.Next(); err != nil || entry.Line != input {
+		t.Errorf("second recorded line = %q, %v; want the input", entry.Line, err)
+	}
+}
+
+func TestRunner_Run_TeesRawInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.jsonl")
+	input := "{\"type\":\"result\",\"subtype\":\"success\",\"result\":\"done\"}\r\nnot json\n"
+
+	var out bytes.Buffer
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-no-color", "-tee", path, "-tag", "repo=viewscreen"})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input)),
+				parser.WithOutput(&out),
+				parser.WithErrOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	if !strings.Contains(out.String(), "Session Complete") {
+		t.Errorf("expected the input to render, got %q", out.String())
+	}
+	teed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(teed) != input {
+		t.Errorf("teed %q, want the input without the tag preamble, byte for byte", teed)
+	}
+}
+
+func TestRunner_Run_AnthropicSSE(t *testing.T) {
+	input, err := os.ReadFile("testdata/anthropic_api.sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-no-color"})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(bytes.NewReader(input)),
+				parser.WithOutput(&out),
+				parser.WithErrOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	got := out.String()
+	if n := strings.Count(got, "Let me check the weather."); n != 1 {
+		t.Errorf("expected the streamed text once, found %d times in %q", n, got)
+	}
+	if !strings.Contains(got, "get_weather") {
+		t.Errorf("expected the tool call rendered, got %q", got)
+	}
+}
+
+func TestRunner_Run_Formats(t *testing.T) {
+	for _, tt := range []struct {
+		fixture string
+		args    []string
+		want    []string
+	}{
+		{"openai_chat.sse", []string{"-format", "openai"}, []string{"Thinking", "The user wants the weather.", "Let me check the weather.", `get_weather {"location":"Paris"}`}},
+		{"ingest/gemini.jsonl", nil, []string{"gemini-2.5-pro", "I'll read the file.", "Read /src/app/main.go", "Bash go vet ./...", "It is an empty program.", "Session Complete"}},
+		{"ingest/opencode.jsonl", nil, []string{"Let me list the files.", "Bash ls", "File not found", "Session Complete"}},
+	} {
+		input, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out bytes.Buffer
+		r := NewRunner(
+			WithConfigOpts(config.WithArgs(append([]string{"-no-tui", "-no-color", "-v"}, tt.args...))),
+			WithParserFactory(func() *parser.Parser {
+				return parser.NewParserWithOptions(
+					parser.WithInput(bytes.NewReader(input)),
+					parser.WithOutput(&out),
+					parser.WithErrOutput(io.Discard),
+				)
+			}),
+			WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+		)
+		r.Run()
+
+		got := out.String()
+		for _, want := range tt.want {
+			if n := strings.Count(got, want); n != 1 {
+				t.Errorf("%s: expected %q once, found %d times in %q", tt.fixture, want, n, got)
+			}
+		}
+	}
+}
+
+func TestRunner_Run_MetricsListenError(t *testing.T) {
+	var errOut bytes.Buffer
+	exitCode := -1
+	r := NewRunner(
+		WithErrOutput(&errOut),
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-metrics-listen", "not an address"})),
+		WithParserFactory(func() *parser.Parser {
+			t.Fatal("expected no rendering when the metrics listener fails")
+			return nil
+		}),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != 1 || !strings.Contains(errOut.String(), "-metrics-listen") {
+		t.Errorf("exit %d, stderr %q; want exit 1 naming the flag", exitCode, errOut.String())
+	}
+}
+
+func TestRunner_Run_ServeError(t *testing.T) {
+	var errOut bytes.Buffer
+	exitCode := -1
+	r := NewRunner(
+		WithErrOutput(&errOut),
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-serve", "not an address"})),
+		WithParserFactory(func() *parser.Parser {
+			t.Fatal("expected no rendering when the live view server fails")
+			return nil
+		}),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != 1 || !strings.Contains(errOut.String(), "-serve") {
+		t.Errorf("exit %d, stderr %q; want exit 1 naming the flag", exitCode, errOut.String())
+	}
+}
+
+func TestRunner_Run_CapturesFixtures(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	input := `{"type":"result","subtype":"success","is_error":false,"duration_ms":100,"result":"done"}`
+
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-capture-fixtures", dir})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input+"\n")),
+				parser.WithOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	data, err := os.ReadFile(filepath.Join(dir, "result_success.jsonl"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != input {
+		t.Errorf("fixture = %q, want %q", data, input)
+	}
+}
+
+func TestRunner_Run_ExportsLegacySession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.md")
+	input := `{"type":"assistant","uuid":"turn-42","message":{"content":[{"type":"text","text":"exported text"}]}}`
+
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", "-no-color", "-export", path})),
+		WithParserFactory(func() *parser.Parser {
+			return parser.NewParserWithOptions(
+				parser.WithInput(strings.NewReader(input+"\n")),
+				parser.WithOutput(io.Discard),
+			)
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if !strings.Contains(string(data), `<a id="evt-turn-42"></a>`) {
+		t.Errorf("expected anchor for event UUID, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "exported text") {
+		t.Errorf("expected rendered text in export, got:\n%s", data)
+	}
+}
+
+func TestRunner_Run_FileArgument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	input := `{"type":"result","subtype":"success","is_error":false,"duration_ms":100,"result":"done"}` + "\n"
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parserFactoryCalled := false
+
+	r := NewRunner(
+		WithConfigOpts(config.WithArgs([]string{"-no-tui", path})),
+		WithParserFactory(func() *parser.Parser {
+			parserFactoryCalled = true
+			return parser.NewParserWithOptions(parser.WithInput(strings.NewReader("")))
+		}),
+		WithPromptStarter(func(string, io.Reader) (promptProcess, error) {
+			t.Fatal("did not expect a file argument to be treated as a prompt")
+			return nil, nil
+		}),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	if parserFactoryCalled {
+		t.Error("did not expect the stdin parser factory for a file argument")
+	}
+}
+
+func TestRunner_Run_ReplayMissingFile(t *testing.T) {
+	errBuf := &bytes.Buffer{}
+	exitCode := -1
+
+	r := NewRunner(
+		WithErrOutput(errBuf),
+		WithConfigOpts(config.WithArgs([]string{"replay", "-no-tui", filepath.Join(t.TempDir(), "missing.viewscreen")})),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if errBuf.Len() == 0 {
+		t.Error("expected an error message")
+	}
+}
+
+func TestRunner_Run_InvalidToolDefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(`[{"header_field": "x"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	errBuf := &bytes.Buffer{}
+	exitCode := -1
+
+	r := NewRunner(
+		WithErrOutput(errBuf),
+		WithConfigOpts(config.WithArgs([]string{"-tool-def", path})),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode)
+	}
+	if !strings.Contains(errBuf.String(), "tool definitions") {
+		t.Errorf("error = %q, want a tool definitions error", errBuf.String())
+	}
+}
+
+func TestRunner_Run_UpdateCheckOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name":"v999.0.0","html_url":"https://example.com/v999","assets":[]}`)
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	exitCode := -1
+	r := NewRunner(
+		WithOutput(out),
+		WithErrOutput(io.Discard),
+		WithConfigOpts(config.WithArgs([]string{"update", "-check-only"})),
+		WithUpdateOptions(update.WithAPIURL(srv.URL)),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != -1 {
+		t.Fatalf("unexpected exit code %d", exitCode)
+	}
+	if !strings.Contains(out.String(), "viewscreen v999.0.0 is available") {
+		t.Errorf("expected available message, got: %q", out.String())
+	}
+}
+
+func TestRunner_Run_Bench(t *testing.T) {
+	out := &bytes.Buffer{}
+	exitCode := -1
+	r := NewRunner(
+		WithOutput(out),
+		WithErrOutput(io.Discard),
+		WithConfigOpts(config.WithArgs([]string{"bench", "-runs", "2", filepath.Join("testdata", "bash_ls.jsonl")})),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != -1 {
+		t.Fatalf("unexpected exit code %d", exitCode)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 2 || !strings.Contains(lines[0], "p95") {
+		t.Fatalf("expected a timing table, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "assistant") {
+		t.Errorf("expected an assistant row, got:\n%s", out.String())
+	}
+}
+
+func TestRunner_Run_Stats(t *testing.T) {
+	out := &bytes.Buffer{}
+	exitCode := -1
+	r := NewRunner(
+		WithOutput(out),
+		WithErrOutput(io.Discard),
+		WithConfigOpts(config.WithArgs([]string{"stats", "-json", filepath.Join("testdata", "bash_ls.jsonl")})),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != -1 {
+		t.Fatalf("unexpected exit code %d", exitCode)
+	}
+	var st stats.Stats
+	if err := json.Unmarshal(out.Bytes(), &st); err != nil {
+		t.Fatalf("expected JSON stats, got %q: %v", out.String(), err)
+	}
+	if st.ToolCounts["Bash"] == 0 || st.ToolCalls == 0 {
+		t.Errorf("expected Bash calls to be counted, got %+v", st)
+	}
+}
+
+func TestRunner_Run_AutoRecord(t *testing.T) {
+	root := t.TempDir()
+	for _, tt := range []struct {
+		args []string
+		want int // recordings in the project directory afterwards
+	}{
+		{[]string{"-no-tui", "-auto-record", "-sessions-dir", root}, 1},
+		{[]string{"-no-tui", "-auto-record", "-sessions-dir", root}, 2},
+		// Pruned to one before the new recording starts.
+		{[]string{"-no-tui", "-auto-record", "-sessions-dir", root, "-sessions-max-count", "1"}, 2},
+		// An explicit -record is used instead.
+		{[]string{"-no-tui", "-auto-record", "-sessions-dir", root, "-record", filepath.Join(t.TempDir(), "own.viewscreen")}, 2},
+	} {
+		r := NewRunner(
+			WithConfigOpts(config.WithArgs(tt.args)),
+			WithParserFactory(func() *parser.Parser {
+				return parser.NewParserWithOptions(
+					parser.WithInput(strings.NewReader(`{"type":"result","subtype":"success","is_error":false}`+"\n")),
+					parser.WithOutput(io.Discard),
+				)
+			}),
+			WithExitFunc(func(code int) { t.Fatalf("%v: unexpected exit %d", tt.args, code) }),
+		)
+		r.Run()
+
+		cwd, _ := os.Getwd()
+		recs, err := sessions.List(filepath.Join(root, sessions.Project(cwd)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recs) != tt.want {
+			t.Errorf("%v: %d recordings, want %d", tt.args, len(recs), tt.want)
+		}
+	}
+}
+
+func TestRunner_Run_SessionsPrune(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "project")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old := filepath.Join(dir, "old.viewscreen")
+	for _, path := range []string{old, filepath.Join(dir, "new.viewscreen")} {
+		if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	longAgo := time.Now().Add(-60 * 24 * time.Hour)
+	if err := os.Chtimes(old, longAgo, longAgo); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	r := NewRunner(
+		WithOutput(out),
+		WithConfigOpts(config.WithArgs([]string{"sessions", "prune", "-sessions-dir", root})),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	if got := out.String(); !strings.Contains(got, "removed "+old) || !strings.Contains(got, "1 recordings removed") {
+		t.Errorf("unexpected output %q", got)
+	}
+	if recs, _ := sessions.List(dir); len(recs) != 1 || filepath.Base(recs[0].Path) != "new.viewscreen" {
+		t.Errorf("expected only the new recording kept, got %+v", recs)
+	}
+}
+
+func TestRunner_Run_ReportDigest(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "project")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := record.Create(filepath.Join(dir, "run.viewscreen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Record(`{"type":"result","subtype":"success","is_error":false,"total_cost_usd":0.25}`)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	path := filepath.Join(t.TempDir(), "digest.md")
+	r := NewRunner(
+		WithOutput(out),
+		WithConfigOpts(config.WithArgs([]string{"report", "digest", "-sessions-dir", root, "-out", path})),
+		WithExitFunc(func(code int) { t.Fatalf("unexpected exit %d", code) }),
+	)
+	r.Run()
+
+	if got := out.String(); !strings.Contains(got, "wrote "+path+" (1 sessions)") {
+		t.Errorf("unexpected output %q", got)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "- **Sessions run:** 1") || !strings.Contains(string(data), "- **Total cost:** $0.25") {
+		t.Errorf("unexpected digest:\n%s", data)
+	}
+}
+
+func TestRunner_Run_Doctor(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "review.json")
+	if err := os.WriteFile(bad, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	exitCode := -1
+	r := NewRunner(
+		WithOutput(out),
+		WithConfigOpts(
+			config.WithArgs([]string{"doctor", "-annotations", bad}),
+			config.WithStyleInitializer(&config.DefaultStyleInitializer{}),
+		),
+		WithDoctorOptions(doctor.WithEnv(func(key string) string {
+			return map[string]string{"TERM": "xterm-256color", "LANG": "en_US.UTF-8"}[key]
+		})),
+		WithExitFunc(func(code int) { exitCode = code }),
+	)
+	r.Run()
+
+	if exitCode != 1 {
+		t.Errorf("exit code = %d, want 1 for a failed check", exitCode)
+	}
+	for _, want := range []string{"color", "unicode", "fail  annotations"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in doctor output, got: %q", want, out.String())
+		}
+	}
+}
+
 func TestWithErrOutput_Option(t *testing.T) {
 	buf := &bytes.Buffer{}
 	opt := WithErrOutput(buf)
@@ -0,0 +1,100 @@
+// Package metadata attaches user-supplied tags (-tag key=value) to a
+// session, such as the repository, a ticket ID or an experiment name.
+//
+// Tags travel as a preamble event at the head of the input stream:
+//
+//	{"type":"viewscreen_metadata","tags":{"repo":"viewscreen","ticket":"ENG-12"}}
+//
+// Because it is an ordinary input line, the preamble is kept by -record,
+// re-emitted by -emit-json and rendered like any other event, so recordings,
+// exports and reports all carry the tags, and a replayed recording shows
+// them again.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// Type is the event type of the metadata preamble.
+const Type = "viewscreen_metadata"
+
+// Kind is the timeline entry kind used for rendered tags.
+const Kind = "metadata"
+
+// Event is a metadata preamble.
+type Event struct {
+	Type string            `json:"type"`
+	Tags map[string]string `json:"tags"`
+}
+
+// ParseTag splits a -tag argument of the form key=value. The key must be
+// non-empty; the value may be.
+func ParseTag(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid tag %q (want key=value)", s)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// Preamble returns the preamble event line for tags, without a newline, or
+// "" when there are none.
+func Preamble(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	// A map of strings always marshals.
+	data, _ := json.Marshal(Event{Type: Type, Tags: tags})
+	return string(data)
+}
+
+// Prepend returns r with the preamble for tags in front of it, or r itself
+// when there are no tags.
+func Prepend(r io.Reader, tags map[string]string) io.Reader {
+	line := Preamble(tags)
+	if line == "" {
+		return r
+	}
+	return io.MultiReader(strings.NewReader(line+"\n"), r)
+}
+
+// Parse decodes a preamble line. It reports false for any other line.
+func Parse(line []byte) (Event, bool) {
+	var ev Event
+	if json.Unmarshal(line, &ev) != nil || ev.Type != Type {
+		return Event{}, false
+	}
+	return ev, true
+}
+
+// Keys returns the keys of tags in sorted order.
+func Keys(tags map[string]string) []string {
+	return slices.Sorted(maps.Keys(tags))
+}
+
+// Render returns the terminal text for tags, one "key: value" line each.
+func Render(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(style.BulletHeader("Session Tags"))
+	sb.WriteString("\n")
+	for i, key := range Keys(tags) {
+		prefix := style.OutputContinue
+		if i == 0 {
+			prefix = style.OutputPrefix
+		}
+		fmt.Fprintf(&sb, "%s%s %s\n", prefix, style.MutedText(key+":"), tags[key])
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
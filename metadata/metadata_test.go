@@ -0,0 +1,71 @@
+package metadata
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		in, key, value string
+		ok             bool
+	}{
+		{"repo=viewscreen", "repo", "viewscreen", true},
+		{" ticket = ENG-12 ", "ticket", "ENG-12", true},
+		{"url=https://x.test/?a=b", "url", "https://x.test/?a=b", true},
+		{"empty=", "empty", "", true},
+		{"novalue", "", "", false},
+		{"=value", "", "", false},
+	}
+	for _, tt := range tests {
+		key, value, err := ParseTag(tt.in)
+		if (err == nil) != tt.ok || key != tt.key || value != tt.value {
+			t.Errorf("ParseTag(%q) = %q, %q, %v", tt.in, key, value, err)
+		}
+	}
+}
+
+func TestPrepend(t *testing.T) {
+	tags := map[string]string{"repo": "viewscreen", "ticket": "ENG-12"}
+	data, err := io.ReadAll(Prepend(strings.NewReader("{\"type\":\"result\"}\n"), tags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[1] != `{"type":"result"}` {
+		t.Fatalf("expected the preamble then the input, got %q", data)
+	}
+	ev, ok := Parse([]byte(lines[0]))
+	if !ok || ev.Tags["repo"] != "viewscreen" || ev.Tags["ticket"] != "ENG-12" {
+		t.Errorf("Parse(%s) = %+v, %v", lines[0], ev, ok)
+	}
+
+	in := strings.NewReader("x")
+	if Prepend(in, nil) != in {
+		t.Error("expected the input unchanged without tags")
+	}
+}
+
+func TestParse_OtherEvents(t *testing.T) {
+	for _, line := range []string{`{"type":"result"}`, `not json`, ``} {
+		if _, ok := Parse([]byte(line)); ok {
+			t.Errorf("Parse(%q) reported a preamble", line)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	got := ansi.Strip(Render(map[string]string{"ticket": "ENG-12", "repo": "viewscreen"}))
+	if !strings.Contains(got, "Session Tags") {
+		t.Errorf("expected a header, got %q", got)
+	}
+	if i, j := strings.Index(got, "repo: viewscreen"), strings.Index(got, "ticket: ENG-12"); i < 0 || j < i {
+		t.Errorf("expected the tags sorted by key, got %q", got)
+	}
+	if Render(nil) != "" {
+		t.Error("expected nothing without tags")
+	}
+}
@@ -0,0 +1,245 @@
+// Package metrics counts what the sessions viewscreen renders are doing —
+// events processed, tool calls and their durations, tokens, cost and
+// session outcomes — and serves the totals in the Prometheus text format,
+// so fleets of agents can be watched from one place. It is enabled with
+// -metrics-listen; OpenTelemetry collectors can scrape the same endpoint
+// with their Prometheus receiver. A JSON snapshot of the session being
+// rendered is served alongside, for status bar scripts.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/worker"
+)
+
+// Path is where Listen serves the metrics.
+const Path = "/metrics"
+
+// DurationBuckets are the upper bounds, in seconds, of the tool duration
+// histogram's buckets.
+var DurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Registry holds the metrics of every session rendered by this process. It
+// is safe for concurrent use.
+type Registry struct {
+	now func() time.Time
+
+	mu            sync.Mutex
+	events        map[string]float64 // by event type
+	toolCalls     map[string]float64 // by tool name
+	toolErrors    map[string]float64 // by tool name
+	toolDurations map[string]*histogram
+	tokens        map[string]float64 // by kind: input, output, cache_read, ...
+	costUSD       float64
+	sessions      map[string]float64 // by outcome: success or error
+	status        Status
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithClock sets the clock tool durations are measured with.
+func WithClock(now func() time.Time) Option {
+	return func(r *Registry) {
+		r.now = now
+	}
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		now:           time.Now,
+		events:        make(map[string]float64),
+		toolCalls:     make(map[string]float64),
+		toolErrors:    make(map[string]float64),
+		toolDurations: make(map[string]*histogram),
+		tokens:        make(map[string]float64),
+		sessions:      make(map[string]float64),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+var (
+	mu         sync.RWMutex
+	defaultReg *Registry
+)
+
+// Default returns the process-wide Registry, or nil when metrics are not
+// exported.
+func Default() *Registry {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultReg
+}
+
+// SetDefault replaces the process-wide Registry.
+func SetDefault(r *Registry) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultReg = r
+}
+
+// Serve serves r's metrics at Path, and its Status at StatusPath, on addr
+// (e.g. ":9090") in the background of g, until g is closed. It returns once
+// the address is bound. The Status shows the running tool's input, such as
+// a whole shell command, so an address without a host listens on loopback
+// only, and one that is reachable from other machines logs a warning.
+func Serve(g *worker.Group, addr string, r *Registry) error {
+	addr, exposed := listenAddr(addr)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("-metrics-listen: %w", err)
+	}
+	if exposed {
+		diag.Default().Warnf("-metrics-listen %s serves the running tool's input and the todos to anyone who can reach it; listen on 127.0.0.1 to keep them local", addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+	mux.HandleFunc(StatusPath, r.ServeStatus)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	g.Go("metrics server", func(ctx context.Context) error {
+		stop := context.AfterFunc(ctx, func() { srv.Close() })
+		defer stop()
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	return nil
+}
+
+// listenAddr returns addr with a missing host replaced by the loopback
+// address, and whether it listens on more than loopback. Addresses that do
+// not parse are returned as they are, for net.Listen to report.
+func listenAddr(addr string) (string, bool) {
+	host, port, err := net.SplitHostPort(addr)
+	switch {
+	case err != nil:
+		return addr, false
+	case host == "":
+		return net.JoinHostPort("127.0.0.1", port), false
+	case host == "localhost":
+		return addr, false
+	}
+	ip := net.ParseIP(host)
+	return addr, ip == nil || !ip.IsLoopback()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text exposition format, each
+// family's series sorted by label.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, "viewscreen_events_total", "Input events processed, by event type.", "type", r.events)
+	writeCounter(&b, "viewscreen_tool_calls_total", "Tool calls made by the agent, by tool.", "tool", r.toolCalls)
+	writeCounter(&b, "viewscreen_tool_errors_total", "Tool calls that failed, by tool.", "tool", r.toolErrors)
+	writeHistogram(&b, "viewscreen_tool_duration_seconds", "Time from a tool call to its result, by tool.", "tool", r.toolDurations)
+	writeCounter(&b, "viewscreen_tokens_total", "Tokens used by finished sessions, by kind.", "kind", r.tokens)
+	writeCounter(&b, "viewscreen_cost_usd_total", "Cost of finished sessions in US dollars.", "", map[string]float64{"": r.costUSD})
+	writeCounter(&b, "viewscreen_sessions_total", "Finished sessions, by outcome.", "outcome", r.sessions)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// histogram counts observations into cumulative DurationBuckets.
+type histogram struct {
+	counts []float64 // per bucket, not cumulative
+	count  float64
+	sum    float64
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]float64, len(DurationBuckets))
+	}
+	for i, le := range DurationBuckets {
+		if v <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func writeHeader(b *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeCounter(b *strings.Builder, name, help, label string, values map[string]float64) {
+	writeHeader(b, name, help, "counter")
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s%s %s\n", name, labels(label, key), formatValue(values[key]))
+	}
+}
+
+func writeHistogram(b *strings.Builder, name, help, label string, values map[string]*histogram) {
+	writeHeader(b, name, help, "histogram")
+	for _, key := range sortedKeys(values) {
+		h := values[key]
+		var cumulative float64
+		for i, le := range DurationBuckets {
+			if h.counts != nil {
+				cumulative += h.counts[i]
+			}
+			fmt.Fprintf(b, "%s_bucket%s %s\n", name, labels(label, key, "le", formatValue(le)), formatValue(cumulative))
+		}
+		fmt.Fprintf(b, "%s_bucket%s %s\n", name, labels(label, key, "le", "+Inf"), formatValue(h.count))
+		fmt.Fprintf(b, "%s_sum%s %s\n", name, labels(label, key), formatValue(h.sum))
+		fmt.Fprintf(b, "%s_count%s %s\n", name, labels(label, key), formatValue(h.count))
+	}
+}
+
+// labelEscaper escapes a label value as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats name/value pairs as a label set, skipping empty names.
+func labels(pairs ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == "" {
+			continue
+		}
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/worker"
+)
+
+// clock is a fake clock the tests advance by hand.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func write(t *testing.T, s sink.Sink, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if err := s.Write(sink.Event{Raw: line}); err != nil {
+			t.Fatalf("Write(%s): %v", line, err)
+		}
+	}
+}
+
+func exposition(t *testing.T, r *Registry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func assertLines(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, line := range want {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestSink_Claude(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	r := NewRegistry(WithClock(c.now))
+	s := r.Sink()
+
+	write(t, s,
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"b1","name":"Bash"},{"type":"tool_use","id":"r1","name":"Read"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"b1","name":"Bash"}]}}`,
+	)
+	c.t = c.t.Add(3 * time.Second)
+	write(t, s,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"b1","is_error":true}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"r1"}]}}`,
+		`{"type":"result","is_error":false,"total_cost_usd":0.25,"usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":30,"cache_read_input_tokens":40}}`,
+		`not json`,
+	)
+
+	assertLines(t, exposition(t, r),
+		"# TYPE viewscreen_events_total counter",
+		`viewscreen_events_total{type="assistant"} 2`,
+		`viewscreen_events_total{type="user"} 2`,
+		`viewscreen_tool_calls_total{tool="Bash"} 1`,
+		`viewscreen_tool_calls_total{tool="Read"} 1`,
+		`viewscreen_tool_errors_total{tool="Bash"} 1`,
+		"# TYPE viewscreen_tool_duration_seconds histogram",
+		`viewscreen_tool_duration_seconds_bucket{tool="Bash",le="2.5"} 0`,
+		`viewscreen_tool_duration_seconds_bucket{tool="Bash",le="5"} 1`,
+		`viewscreen_tool_duration_seconds_bucket{tool="Bash",le="+Inf"} 1`,
+		`viewscreen_tool_duration_seconds_sum{tool="Bash"} 3`,
+		`viewscreen_tool_duration_seconds_count{tool="Bash"} 1`,
+		`viewscreen_tokens_total{kind="cache_read"} 40`,
+		`viewscreen_tokens_total{kind="input"} 10`,
+		"viewscreen_cost_usd_total 0.25",
+		`viewscreen_sessions_total{outcome="success"} 1`,
+	)
+}
+
+func TestSink_Codex(t *testing.T) {
+	r := NewRegistry()
+	write(t, r.Sink(),
+		`{"type":"item.started","item":{"id":"c1","type":"command_execution","command":"ls"}}`,
+		`{"type":"item.completed","item":{"id":"c1","type":"command_execution","command":"ls","exit_code":2}}`,
+		`{"type":"item.completed","item":{"id":"m1","type":"agent_message","text":"hi"}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":60,"output_tokens":5,"reasoning_output_tokens":3}}`,
+		`{"type":"turn.failed","error":{"message":"boom"}}`,
+	)
+	out := exposition(t, r)
+	assertLines(t, out,
+		`viewscreen_tool_calls_total{tool="command_execution"} 1`,
+		`viewscreen_tool_errors_total{tool="command_execution"} 1`,
+		`viewscreen_tokens_total{kind="reasoning"} 3`,
+		`viewscreen_sessions_total{outcome="error"} 1`,
+		`viewscreen_sessions_total{outcome="success"} 1`,
+	)
+	if strings.Contains(out, `tool="agent_message"`) {
+		t.Errorf("expected agent messages not to count as tool calls:\n%s", out)
+	}
+}
+
+func TestSink_SessionsAddUp(t *testing.T) {
+	r := NewRegistry()
+	for range 2 {
+		write(t, r.Sink(), `{"type":"result","is_error":true,"total_cost_usd":1.5}`)
+	}
+	assertLines(t, exposition(t, r),
+		"viewscreen_cost_usd_total 3",
+		`viewscreen_sessions_total{outcome="error"} 2`,
+	)
+}
+
+func TestLabelsEscaped(t *testing.T) {
+	if got, want := labels("tool", "mcp__a\"b\\c\nd"), `{tool="mcp__a\"b\\c\nd"}`; got != want {
+		t.Errorf("labels = %s, want %s", got, want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	write(t, r.Sink(), `{"type":"system","subtype":"init"}`)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", Path, nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	assertLines(t, rec.Body.String(), `viewscreen_events_total{type="system"} 1`)
+}
+
+func TestServe(t *testing.T) {
+	g := worker.New(context.Background(), diag.New(&bytes.Buffer{}, diag.LevelOff))
+	if err := Serve(g, "127.0.0.1:0", NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("Close() = %v, want the server stopped cleanly", err)
+	}
+	if err := Serve(g, "not an address", NewRegistry()); err == nil || !strings.HasPrefix(err.Error(), "-metrics-listen: ") {
+		t.Errorf("Serve(bad address) = %v, want an error naming the flag", err)
+	}
+}
+
+func TestServe_WarnsWhenExposed(t *testing.T) {
+	var log bytes.Buffer
+	defer diag.SetDefault(diag.Default())
+	diag.SetDefault(diag.New(&log, diag.LevelWarn))
+
+	g := worker.New(context.Background(), diag.New(&bytes.Buffer{}, diag.LevelOff))
+	defer g.Close()
+	if err := Serve(g, ":0", NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	if log.Len() != 0 {
+		t.Errorf("expected no warning for a loopback address, got %q", log.String())
+	}
+	if err := Serve(g, "0.0.0.0:0", NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(log.String(), "anyone who can reach it") {
+		t.Errorf("expected a warning for all interfaces, got %q", log.String())
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		exposed bool
+	}{
+		{":9090", "127.0.0.1:9090", false},
+		{"localhost:9090", "localhost:9090", false},
+		{"[::1]:9090", "[::1]:9090", false},
+		{"0.0.0.0:9090", "0.0.0.0:9090", true},
+		{"example.com:9090", "example.com:9090", true},
+		{"not an address", "not an address", false},
+	}
+	for _, tt := range tests {
+		got, exposed := listenAddr(tt.addr)
+		if got != tt.want || exposed != tt.exposed {
+			t.Errorf("listenAddr(%q) = %q, %v; want %q, %v", tt.addr, got, exposed, tt.want, tt.exposed)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package metrics
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/result"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/tools"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// metricsSink counts the input events of one stream into a Registry, and
+// keeps its Status.
+type metricsSink struct {
+	r        *Registry
+	pending  map[string]call // tool calls awaiting their result, by ID
+	seen     map[string]bool // tool call IDs already counted
+	messages map[string]bool // main agent message IDs of the session
+	current  string          // the ID of the call shown in the Status
+}
+
+// call is a tool call awaiting its result.
+type call struct {
+	tool    string
+	input   string
+	started time.Time
+}
+
+// Sink returns a sink.Sink that counts every input event it receives into
+// r. Each rendered stream needs its own sink; the counts add up in r.
+func (r *Registry) Sink() sink.Sink {
+	return &metricsSink{r: r, pending: make(map[string]call), seen: make(map[string]bool), messages: make(map[string]bool)}
+}
+
+func (s *metricsSink) Write(ev sink.Event) error {
+	if ev.Raw != "" {
+		s.observe([]byte(ev.Raw))
+	}
+	return nil
+}
+
+func (s *metricsSink) Close() error { return nil }
+
+// observe counts one raw input line.
+func (s *metricsSink) observe(raw []byte) {
+	ev, ok := types.ParseLine(raw)
+	if !ok || ev.Type == "" {
+		return
+	}
+	now := s.r.now()
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.events[ev.Type]++
+	s.r.status.UpdatedAt = now
+
+	if codex.IsEventType(ev.Type) {
+		s.observeCodex(raw, now)
+		return
+	}
+	blocks := ev.Blocks()
+
+	switch ev.Type {
+	case "system":
+		if ev.Subtype == "init" {
+			s.startSession(ev.SessionID)
+		}
+	case "assistant":
+		if !ev.IsSubAgent() && (ev.Message.ID == "" || !s.messages[ev.Message.ID]) {
+			s.messages[ev.Message.ID] = true
+			s.r.status.Turn++
+		}
+		for _, b := range blocks {
+			if b.Type == "tool_use" && b.ID != "" {
+				arg := tools.GetToolArgFromBlock(types.ContentBlock{Name: b.Name, Input: b.Input})
+				s.start(b.ID, b.Name, arg, now)
+			}
+		}
+	case "user":
+		for _, b := range blocks {
+			if b.Type == "tool_result" {
+				s.finish(b.ToolUseID, b.IsError, now)
+			}
+		}
+		if todos, ok := claudeTodos(ev.ToolUseResult); ok {
+			s.r.status.Todos = todos
+		}
+	case "result":
+		var res result.Event
+		if json.Unmarshal(raw, &res) != nil {
+			return
+		}
+		s.r.tokens["input"] += float64(res.Usage.InputTokens)
+		s.r.tokens["output"] += float64(res.Usage.OutputTokens)
+		s.r.tokens["cache_creation"] += float64(res.Usage.CacheCreationInputTokens)
+		s.r.tokens["cache_read"] += float64(res.Usage.CacheReadInputTokens)
+		s.r.costUSD += res.TotalCostUSD
+		s.r.status.CostUSD += res.TotalCostUSD
+		if res.NumTurns > 0 {
+			s.r.status.Turn = res.NumTurns
+		}
+		s.endSession(res.IsError)
+	}
+}
+
+// observeCodex counts a codex event. Codex reports no cost, and its tools
+// are the items that do work: commands, file changes, MCP calls and web
+// searches, named by item type.
+func (s *metricsSink) observeCodex(raw []byte, now time.Time) {
+	var ev codex.Event
+	if json.Unmarshal(raw, &ev) != nil {
+		return
+	}
+	switch ev.Type {
+	case codex.TypeThreadStarted:
+		s.startSession(ev.ThreadID)
+	case codex.TypeTurnStarted:
+		s.r.status.Turn++
+		s.r.status.Finished = false
+	case codex.TypeItemStarted:
+		if isCodexTool(ev.Item) {
+			s.start(ev.Item.ID, ev.Item.Type, codexInput(ev.Item), now)
+		}
+	case codex.TypeItemUpdated, codex.TypeItemCompleted:
+		if ev.Item != nil && ev.Item.Type == codex.ItemTodoList {
+			s.r.status.Todos = codexTodos(ev.Item.Items)
+		}
+		if ev.Type == codex.TypeItemCompleted && isCodexTool(ev.Item) {
+			s.start(ev.Item.ID, ev.Item.Type, codexInput(ev.Item), now) // items may complete unannounced
+			failed := ev.Item.Status == "failed" || (ev.Item.ExitCode != nil && *ev.Item.ExitCode != 0)
+			s.finish(ev.Item.ID, failed, now)
+		}
+	case codex.TypeTurnCompleted:
+		if ev.Usage != nil {
+			s.r.tokens["input"] += float64(ev.Usage.InputTokens)
+			s.r.tokens["cache_read"] += float64(ev.Usage.CachedInputTokens)
+			s.r.tokens["output"] += float64(ev.Usage.OutputTokens)
+			s.r.tokens["reasoning"] += float64(ev.Usage.ReasoningOutputTokens)
+		}
+		s.endSession(false)
+	case codex.TypeTurnFailed:
+		s.endSession(true)
+	}
+}
+
+func isCodexTool(item *codex.Item) bool {
+	if item == nil || item.ID == "" {
+		return false
+	}
+	switch item.Type {
+	case codex.ItemCommandExecution, codex.ItemFileChange, codex.ItemMCPToolCall, codex.ItemWebSearch:
+		return true
+	}
+	return false
+}
+
+// start counts a tool call, once per ID, and starts timing it. It becomes
+// the Status's running tool.
+func (s *metricsSink) start(id, tool, input string, now time.Time) {
+	if s.seen[id] {
+		return
+	}
+	s.seen[id] = true
+	s.pending[id] = call{tool: tool, input: input, started: now}
+	s.r.toolCalls[tool]++
+	s.show(id)
+}
+
+// show makes the pending call id the Status's running tool, or clears it
+// when id is "".
+func (s *metricsSink) show(id string) {
+	s.current = id
+	c, ok := s.pending[id]
+	if !ok {
+		s.r.status.Tool = nil
+		return
+	}
+	s.r.status.Tool = &ToolStatus{Name: c.tool, Input: c.input, StartedAt: c.started}
+}
+
+// finish records the duration and outcome of the tool call id.
+func (s *metricsSink) finish(id string, failed bool, now time.Time) {
+	c, ok := s.pending[id]
+	if !ok {
+		return
+	}
+	delete(s.pending, id)
+	if id == s.current {
+		s.show(s.latestPending())
+	}
+	h := s.r.toolDurations[c.tool]
+	if h == nil {
+		h = &histogram{}
+		s.r.toolDurations[c.tool] = h
+	}
+	h.observe(now.Sub(c.started).Seconds())
+	if failed {
+		s.r.toolErrors[c.tool]++
+	}
+}
+
+// latestPending returns the ID of the pending call started last, or "".
+func (s *metricsSink) latestPending() string {
+	latest := ""
+	for id, c := range s.pending {
+		if l, ok := s.pending[latest]; !ok || c.started.After(l.started) || (c.started.Equal(l.started) && id > latest) {
+			latest = id
+		}
+	}
+	return latest
+}
+
+// startSession resets the Status for a new session, keeping the cost of
+// the stream's earlier ones.
+func (s *metricsSink) startSession(id string) {
+	s.r.status = Status{SessionID: id, CostUSD: s.r.status.CostUSD, UpdatedAt: s.r.status.UpdatedAt}
+	s.messages = make(map[string]bool)
+	s.current = ""
+}
+
+func (s *metricsSink) endSession(failed bool) {
+	s.r.status.Finished = true
+	s.show("")
+	if failed {
+		s.r.sessions["error"]++
+	} else {
+		s.r.sessions["success"]++
+	}
+}
+
+// codexInput is the argument of a codex tool item: its command, query or
+// MCP tool.
+func codexInput(item *codex.Item) string {
+	switch {
+	case item.Command != "":
+		return item.Command
+	case item.Query != "":
+		return item.Query
+	case item.Tool != "":
+		return item.Server + " ▸ " + item.Tool
+	}
+	return ""
+}
+
+func codexTodos(items []codex.TodoItem) []Todo {
+	todos := make([]Todo, len(items))
+	for i, item := range items {
+		todos[i] = Todo{Content: item.Text, Status: "pending"}
+		if item.Completed {
+			todos[i].Status = "completed"
+		}
+	}
+	return todos
+}
+
+// claudeTodos reads the todo list a TodoWrite or task tool result reports.
+func claudeTodos(raw json.RawMessage) ([]Todo, bool) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, false
+	}
+	var envelope struct {
+		NewTodos []struct {
+			Content string `json:"content"`
+			Status  string `json:"status"`
+		} `json:"newTodos"`
+		Tasks []struct {
+			Subject string `json:"subject"`
+			Status  string `json:"status"`
+		} `json:"tasks"`
+	}
+	if json.Unmarshal(raw, &envelope) != nil {
+		return nil, false
+	}
+	switch {
+	case envelope.NewTodos != nil:
+		todos := make([]Todo, len(envelope.NewTodos))
+		for i, t := range envelope.NewTodos {
+			todos[i] = Todo{Content: t.Content, Status: t.Status}
+		}
+		return todos, true
+	case envelope.Tasks != nil:
+		todos := make([]Todo, len(envelope.Tasks))
+		for i, t := range envelope.Tasks {
+			todos[i] = Todo{Content: t.Subject, Status: t.Status}
+		}
+		return todos, true
+	}
+	return nil, false
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// StatusPath is where Serve serves the session snapshot.
+const StatusPath = "/status"
+
+// Status is a snapshot of the session being rendered, served as JSON at
+// StatusPath for status bar scripts (tmux, waybar) to poll. Turn, Tool and
+// Todos describe the latest session of the stream; CostUSD adds up every
+// session that has reported its cost, as the TUI's cost does.
+type Status struct {
+	SessionID string `json:"session_id,omitempty"`
+	// Turn counts the main agent's requests in the session so far, or the
+	// turns it reported once it finished.
+	Turn int `json:"turn"`
+	// Tool is the tool call started last of those still running, if any.
+	Tool    *ToolStatus `json:"tool"`
+	CostUSD float64     `json:"cost_usd"`
+	Todos   []Todo      `json:"todos"`
+	// Finished is whether the session has reported its result.
+	Finished  bool      `json:"finished"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToolStatus is a running tool call.
+type ToolStatus struct {
+	Name string `json:"name"`
+	// Input is the argument shown in the tool's header, e.g. a command or
+	// file path.
+	Input     string    `json:"input,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	// ElapsedSeconds is how long it has been running when the snapshot is
+	// taken.
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// Todo is an item of the agent's todo list.
+type Todo struct {
+	Content string `json:"content"`
+	// Status is pending, in_progress or completed.
+	Status string `json:"status"`
+}
+
+// Status returns a snapshot of the session.
+func (r *Registry) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.status
+	st.Todos = append([]Todo{}, r.status.Todos...)
+	if r.status.Tool != nil {
+		tool := *r.status.Tool
+		tool.ElapsedSeconds = r.now().Sub(tool.StartedAt).Seconds()
+		st.Tool = &tool
+	}
+	return st
+}
+
+// ServeStatus writes the Status snapshot as JSON.
+func (r *Registry) ServeStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(r.Status())
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStatus_Claude(t *testing.T) {
+	c := &clock{t: time.Unix(100, 0)}
+	r := NewRegistry(WithClock(c.now))
+	s := r.Sink()
+
+	write(t, s,
+		`{"type":"system","subtype":"init","session_id":"s1"}`,
+		`{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Looking"}]}}`,
+		`{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"assistant","parent_tool_use_id":"x","message":{"id":"m9","content":[{"type":"text","text":"nested"}]}}`,
+	)
+	c.t = c.t.Add(4 * time.Second)
+	st := r.Status()
+	if st.SessionID != "s1" || st.Turn != 1 || st.Finished {
+		t.Errorf("Status = %+v, want session s1 on turn 1", st)
+	}
+	if st.Tool == nil || st.Tool.Name != "Bash" || st.Tool.Input != "go test ./..." || st.Tool.ElapsedSeconds != 4 {
+		t.Errorf("Tool = %+v, want the running Bash call", st.Tool)
+	}
+
+	write(t, s,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"b1"}]},"tool_use_result":{"newTodos":[{"content":"Fix tests","status":"in_progress","activeForm":"Fixing tests"}]}}`,
+		`{"type":"result","num_turns":3,"total_cost_usd":0.5}`,
+	)
+	st = r.Status()
+	want := []Todo{{Content: "Fix tests", Status: "in_progress"}}
+	if st.Tool != nil || !reflect.DeepEqual(st.Todos, want) || st.Turn != 3 || st.CostUSD != 0.5 || !st.Finished {
+		t.Errorf("Status = %+v, want the finished session", st)
+	}
+
+	write(t, s, `{"type":"system","subtype":"init","session_id":"s2"}`)
+	if st := r.Status(); st.SessionID != "s2" || st.Turn != 0 || len(st.Todos) != 0 || st.CostUSD != 0.5 {
+		t.Errorf("Status = %+v, want a fresh session keeping the cost", st)
+	}
+}
+
+func TestStatus_ParallelTools(t *testing.T) {
+	c := &clock{t: time.Unix(0, 0)}
+	r := NewRegistry(WithClock(c.now))
+	s := r.Sink()
+	write(t, s, `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"/a.go"}}]}}`)
+	c.t = c.t.Add(time.Second)
+	write(t, s,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r2","name":"Read","input":{"file_path":"/b.go"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"r2"}]}}`,
+	)
+	if st := r.Status(); st.Tool == nil || st.Tool.Input != "/a.go" {
+		t.Errorf("Tool = %+v, want the call still running", st.Tool)
+	}
+}
+
+func TestStatus_Codex(t *testing.T) {
+	r := NewRegistry()
+	write(t, r.Sink(),
+		`{"type":"thread.started","thread_id":"th1"}`,
+		`{"type":"turn.started"}`,
+		`{"type":"item.started","item":{"id":"c1","type":"command_execution","command":"ls"}}`,
+		`{"type":"item.updated","item":{"id":"t1","type":"todo_list","items":[{"text":"a","completed":true},{"text":"b"}]}}`,
+	)
+	st := r.Status()
+	want := []Todo{{Content: "a", Status: "completed"}, {Content: "b", Status: "pending"}}
+	if st.SessionID != "th1" || st.Turn != 1 || st.Tool == nil || st.Tool.Input != "ls" || !reflect.DeepEqual(st.Todos, want) {
+		t.Errorf("Status = %+v", st)
+	}
+}
+
+func TestServeStatus(t *testing.T) {
+	r := NewRegistry()
+	write(t, r.Sink(), `{"type":"system","subtype":"init","session_id":"s1"}`)
+
+	rec := httptest.NewRecorder()
+	r.ServeStatus(rec, httptest.NewRequest("GET", StatusPath, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["session_id"] != "s1" || got["tool"] != nil || got["todos"] == nil {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,106 @@
+// Package notify raises desktop notifications when a session needs the
+// user's attention: when it finishes or fails, when the agent asks a
+// question, or when a tool call waits on a permission prompt. Long agent
+// runs otherwise finish silently while the user is in another window.
+//
+// Notifications go through notify-send on Linux and the BSDs and osascript
+// on macOS. Where neither is available, the terminal bell is rung instead.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/worker"
+)
+
+// AppName is the application name notifications are sent under.
+const AppName = "viewscreen"
+
+// Notifier shows a notification with a title and a one-line message.
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// lookPath finds a notification command; tests replace it.
+var lookPath = exec.LookPath
+
+// New returns the Notifier for this platform, falling back to a terminal
+// bell written to w when no notification command is installed.
+func New(w io.Writer) Notifier {
+	bell := Bell(w)
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := lookPath("osascript"); err == nil {
+			return commandNotifier{path: path, args: osascriptArgs, fallback: bell}
+		}
+	case "windows":
+		// No notification command; ring the bell.
+	default:
+		if path, err := lookPath("notify-send"); err == nil {
+			return commandNotifier{path: path, args: notifySendArgs, fallback: bell}
+		}
+	}
+	return bell
+}
+
+// commandNotifier runs a notification command, ringing the fallback when it
+// cannot be started.
+type commandNotifier struct {
+	path     string
+	args     func(title, message string) []string
+	fallback Notifier
+}
+
+// Notify starts the command without waiting for it, so a slow notification
+// daemon does not hold up rendering; it is waited for in worker.Default(),
+// which reports a failure.
+func (n commandNotifier) Notify(title, message string) error {
+	cmd := exec.Command(n.path, n.args(title, message)...)
+	if err := cmd.Start(); err != nil {
+		return n.fallback.Notify(title, message)
+	}
+	worker.Default().Go("notification", func(context.Context) error {
+		return cmd.Wait()
+	})
+	return nil
+}
+
+// notifySendArgs ends the options with "--", so a title or message starting
+// with "-" (such as a failed command's output) is not read as one.
+func notifySendArgs(title, message string) []string {
+	return []string{"--app-name=" + AppName, "--", title, message}
+}
+
+func osascriptArgs(title, message string) []string {
+	script := fmt.Sprintf("display notification %s with title %s subtitle %s",
+		appleScriptString(message), appleScriptString(AppName), appleScriptString(title))
+	return []string{"-e", script}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// bellNotifier rings the terminal bell.
+type bellNotifier struct {
+	w io.Writer
+}
+
+// Bell returns a Notifier that rings the terminal bell by writing BEL to w.
+// The title and message are not shown.
+func Bell(w io.Writer) Notifier {
+	return bellNotifier{w: w}
+}
+
+func (n bellNotifier) Notify(string, string) error {
+	_, err := io.WriteString(n.w, "\a")
+	return err
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/sink"
+)
+
+type recorder struct {
+	notes []note
+}
+
+func (r *recorder) Notify(title, message string) error {
+	r.notes = append(r.notes, note{title, message})
+	return nil
+}
+
+func write(t *testing.T, s sink.Sink, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if err := s.Write(sink.Event{Raw: line}); err != nil {
+			t.Fatalf("Write(%s): %v", line, err)
+		}
+	}
+}
+
+func TestSink(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []note
+	}{
+		{
+			name:  "session complete",
+			lines: []string{`{"type":"result","subtype":"success","is_error":false,"num_turns":3,"result":"All tests pass.\nDone."}`},
+			want:  []note{{"Session complete", "All tests pass. Done."}},
+		},
+		{
+			name:  "session complete without a result",
+			lines: []string{`{"type":"result","subtype":"success","num_turns":3,"result":""}`},
+			want:  []note{{"Session complete", "Finished after 3 turns"}},
+		},
+		{
+			name:  "session failed",
+			lines: []string{`{"type":"result","subtype":"error_max_turns","is_error":true,"result":""}`},
+			want:  []note{{"Session failed", "error_max_turns"}},
+		},
+		{
+			name:  "session failed with errors",
+			lines: []string{`{"type":"result","subtype":"error_during_execution","is_error":true,"errors":["API overloaded"]}`},
+			want:  []note{{"Session failed", "API overloaded"}},
+		},
+		{
+			name: "question notified once",
+			lines: []string{
+				`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"AskUserQuestion","input":{"questions":[{"question":"Cats or dogs?"}]}}]}}`,
+				`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"AskUserQuestion","input":{"questions":[{"question":"Cats or dogs?"}]}}]}}`,
+			},
+			want: []note{{"Question waiting", "Cats or dogs?"}},
+		},
+		{
+			name: "permission prompt",
+			lines: []string{
+				`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_2","is_error":true,"content":"Claude requested permissions to use Bash, but you haven't granted it yet."}]}}`,
+			},
+			want: []note{{"Permission needed", "Claude requested permissions to use Bash, but you haven't granted it yet."}},
+		},
+		{
+			name: "permission prompt in text blocks",
+			lines: []string{
+				`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_3","is_error":true,"content":[{"type":"text","text":"This command requires approval"}]}]}}`,
+			},
+			want: []note{{"Permission needed", "This command requires approval"}},
+		},
+		{
+			name: "ordinary tool error",
+			lines: []string{
+				`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_4","is_error":true,"content":"exit status 1"}]}}`,
+				`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_5","name":"Bash","input":{"command":"ls"}}]}}`,
+				`{"type":"user","message":{"content":"a prompt"}}`,
+				`not json`,
+			},
+		},
+		{
+			name: "codex turn",
+			lines: []string{
+				`{"type":"turn.started"}`,
+				`{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":5}}`,
+				`{"type":"turn.failed","error":{"message":"stream disconnected"}}`,
+			},
+			want: []note{{"Session complete", "Codex finished its turn"}, {"Session failed", "stream disconnected"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			write(t, Sink(r), tt.lines...)
+			if len(r.notes) != len(tt.want) {
+				t.Fatalf("notes = %v, want %v", r.notes, tt.want)
+			}
+			for i := range tt.want {
+				if r.notes[i] != tt.want[i] {
+					t.Errorf("note %d = %v, want %v", i, r.notes[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	got := truncate(strings.Repeat("é", maxMessageLen+10))
+	if n := len([]rune(got)); n != maxMessageLen || !strings.HasSuffix(got, "…") {
+		t.Errorf("truncate kept %d runes (%q), want %d ending in …", n, got, maxMessageLen)
+	}
+	if got := truncate("  short\n\tmessage "); got != "short message" {
+		t.Errorf("truncate = %q, want %q", got, "short message")
+	}
+}
+
+func TestAppleScriptString(t *testing.T) {
+	if got, want := appleScriptString(`say "hi" \ bye`), `"say \"hi\" \\ bye"`; got != want {
+		t.Errorf("appleScriptString = %s, want %s", got, want)
+	}
+}
+
+func TestNotifySendArgs(t *testing.T) {
+	got := notifySendArgs("Session failed", "--help: unknown flag")
+	want := []string{"--app-name=" + AppName, "--", "Session failed", "--help: unknown flag"}
+	if !slices.Equal(got, want) {
+		t.Errorf("notifySendArgs = %q, want %q", got, want)
+	}
+}
+
+func TestNewFallsBackToBell(t *testing.T) {
+	defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+	var buf bytes.Buffer
+	if err := New(&buf).Notify("Session complete", "done"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if buf.String() != "\a" {
+		t.Errorf("wrote %q, want the bell", buf.String())
+	}
+}
+
+func TestNewUsesCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no notification command on Windows")
+	}
+	defer func(orig func(string) (string, error)) { lookPath = orig }(lookPath)
+	var looked string
+	lookPath = func(name string) (string, error) {
+		looked = name
+		return "/usr/bin/" + name, nil
+	}
+
+	n, ok := New(&bytes.Buffer{}).(commandNotifier)
+	if !ok {
+		t.Fatalf("New returned %T, want a command notifier", n)
+	}
+	want := "notify-send"
+	if runtime.GOOS == "darwin" {
+		want = "osascript"
+	}
+	if looked != want || n.path != "/usr/bin/"+want {
+		t.Errorf("looked up %q (path %q), want %q", looked, n.path, want)
+	}
+}
+
+func TestCommandNotifierFallsBack(t *testing.T) {
+	var buf bytes.Buffer
+	n := commandNotifier{path: "/nonexistent/notify", args: notifySendArgs, fallback: Bell(&buf)}
+	if err := n.Notify("t", "m"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if buf.String() != "\a" {
+		t.Errorf("wrote %q, want the bell when the command cannot start", buf.String())
+	}
+}
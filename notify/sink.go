@@ -0,0 +1,180 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/result"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// maxMessageLen caps the length of a notification message.
+const maxMessageLen = 200
+
+// permissionPhrases mark a failed tool result as a permission prompt the
+// user has yet to answer.
+var permissionPhrases = []string{"requested permissions", "requires approval"}
+
+// notifySink watches the input events for ones that need attention.
+type notifySink struct {
+	n    Notifier
+	seen map[string]bool // tool_use IDs already notified about
+}
+
+// Sink returns a sink.Sink that sends a notification through n when the
+// session finishes or fails, when the agent asks the user a question with
+// AskUserQuestion, and when a tool call is held for permission. Failed
+// notifications are dropped rather than interrupting the session.
+func Sink(n Notifier) sink.Sink {
+	return &notifySink{n: n, seen: make(map[string]bool)}
+}
+
+func (s *notifySink) Write(ev sink.Event) error {
+	if ev.Raw == "" {
+		return nil
+	}
+	for _, note := range s.observe([]byte(ev.Raw)) {
+		_ = s.n.Notify(note.title, truncate(note.message))
+	}
+	return nil
+}
+
+func (s *notifySink) Close() error { return nil }
+
+// note is a notification to send.
+type note struct {
+	title, message string
+}
+
+// observe returns the notifications one raw input line calls for.
+func (s *notifySink) observe(raw []byte) []note {
+	ev, ok := types.ParseLine(raw)
+	if !ok {
+		return nil
+	}
+	if codex.IsEventType(ev.Type) {
+		return observeCodex(raw)
+	}
+	blocks := ev.Blocks()
+
+	var notes []note
+	switch ev.Type {
+	case "assistant":
+		for _, b := range blocks {
+			if b.Type != "tool_use" || b.Name != "AskUserQuestion" || s.seen[b.ID] {
+				continue
+			}
+			s.seen[b.ID] = true
+			notes = append(notes, note{"Question waiting", firstQuestion(b.Input)})
+		}
+	case "user":
+		for _, b := range blocks {
+			if b.Type != "tool_result" || !b.IsError || s.seen[b.ToolUseID] {
+				continue
+			}
+			if text := resultText(b.Content); isPermissionPrompt(text) {
+				s.seen[b.ToolUseID] = true
+				notes = append(notes, note{"Permission needed", text})
+			}
+		}
+	case "result":
+		var res result.Event
+		if json.Unmarshal(raw, &res) == nil {
+			notes = append(notes, resultNote(res))
+		}
+	}
+	return notes
+}
+
+// resultNote describes how a Claude session ended.
+func resultNote(res result.Event) note {
+	if res.IsError {
+		message := res.Result
+		if len(res.Errors) > 0 {
+			message = res.Errors[0]
+		}
+		if message == "" {
+			message = res.Subtype
+		}
+		return note{"Session failed", message}
+	}
+	message := res.Result
+	if message == "" {
+		message = fmt.Sprintf("Finished after %d turns", res.NumTurns)
+	}
+	return note{"Session complete", message}
+}
+
+// observeCodex returns the notifications for a codex event: codex runs a
+// single turn, so a completed or failed turn ends the session.
+func observeCodex(raw []byte) []note {
+	var ev codex.Event
+	if json.Unmarshal(raw, &ev) != nil {
+		return nil
+	}
+	switch ev.Type {
+	case codex.TypeTurnCompleted:
+		return []note{{"Session complete", "Codex finished its turn"}}
+	case codex.TypeTurnFailed:
+		message := "Codex turn failed"
+		if ev.Error != nil && ev.Error.Message != "" {
+			message = ev.Error.Message
+		}
+		return []note{{"Session failed", message}}
+	}
+	return nil
+}
+
+// firstQuestion returns the text of the first question in AskUserQuestion
+// input.
+func firstQuestion(input json.RawMessage) string {
+	var in struct {
+		Questions []struct {
+			Question string `json:"question"`
+		} `json:"questions"`
+	}
+	if json.Unmarshal(input, &in) != nil || len(in.Questions) == 0 || in.Questions[0].Question == "" {
+		return "The agent is asking a question"
+	}
+	return in.Questions[0].Question
+}
+
+// resultText returns the text of tool result content, which is either a
+// string or a list of text blocks.
+func resultText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var blocks []struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(content, &blocks)
+	parts := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		parts = append(parts, b.Text)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func isPermissionPrompt(text string) bool {
+	lower := strings.ToLower(text)
+	for _, phrase := range permissionPhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate flattens message to one line of at most maxMessageLen runes.
+func truncate(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxMessageLen {
+		return string(runes[:maxMessageLen-1]) + "…"
+	}
+	return message
+}
@@ -0,0 +1,277 @@
+// Package numfmt formats token counts and costs consistently across the
+// sidebar, header, result summaries and exports. Separators follow the
+// selected locale, and costs (always reported in USD) can be converted to
+// another currency at a fixed rate.
+package numfmt
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Locale holds the number conventions of a language.
+type Locale struct {
+	// Group separates thousands ("1,234"); Decimal separates the fraction.
+	Group   string
+	Decimal string
+	// SymbolAfter places the currency symbol after the amount ("1,23 €").
+	SymbolAfter bool
+}
+
+// English is the default locale.
+var English = Locale{Group: ",", Decimal: "."}
+
+var (
+	continental = Locale{Group: ".", Decimal: ",", SymbolAfter: true}
+	spaced      = Locale{Group: "\u00a0", Decimal: ",", SymbolAfter: true}
+)
+
+// locales maps language (or language_REGION) tags to their conventions.
+// Region-specific entries take precedence over the bare language.
+var locales = map[string]Locale{
+	"c":     English,
+	"posix": English,
+	"en":    English,
+	"ja":    English,
+	"ko":    English,
+	"zh":    English,
+	"de":    continental,
+	"es":    continental,
+	"it":    continental,
+	"nl":    continental,
+	"pt":    continental,
+	"tr":    continental,
+	"da":    continental,
+	"id":    continental,
+	"fr":    {Group: "\u202f", Decimal: ",", SymbolAfter: true},
+	"cs":    spaced,
+	"fi":    spaced,
+	"nb":    spaced,
+	"pl":    spaced,
+	"ru":    spaced,
+	"sv":    spaced,
+	"uk":    spaced,
+	"de_ch": {Group: "’", Decimal: "."},
+	"fr_ch": {Group: "\u202f", Decimal: "."},
+	"pt_br": continental,
+}
+
+// ParseLocale resolves a locale tag such as "de", "de_DE.UTF-8" or "pt-BR".
+func ParseLocale(tag string) (Locale, error) {
+	key := strings.ToLower(tag)
+	if i := strings.IndexAny(key, ".@"); i >= 0 {
+		key = key[:i]
+	}
+	key = strings.ReplaceAll(key, "-", "_")
+	if l, ok := locales[key]; ok {
+		return l, nil
+	}
+	if lang, _, found := strings.Cut(key, "_"); found {
+		if l, ok := locales[lang]; ok {
+			return l, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("unknown locale %q", tag)
+}
+
+// EnvLocale returns the locale tag from the environment, checking LC_ALL,
+// LC_NUMERIC and LANG in the order POSIX gives them precedence.
+func EnvLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Currency is the unit costs are shown in. Rate converts from USD.
+type Currency struct {
+	Code   string
+	Symbol string
+	Rate   float64
+}
+
+// USD is the default currency; agents report costs in US dollars.
+var USD = Currency{Code: "USD", Symbol: "$", Rate: 1}
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+	"BRL": "R$",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// ParseCurrency validates an ISO 4217 code and conversion rate. Codes without
+// a well-known symbol are shown by code ("CHF 1.23").
+func ParseCurrency(code string, rate float64) (Currency, error) {
+	code = strings.ToUpper(code)
+	if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return Currency{}, fmt.Errorf("invalid currency code %q (want a 3-letter ISO 4217 code)", code)
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return Currency{}, fmt.Errorf("invalid currency rate %v (must be > 0)", rate)
+	}
+	symbol, ok := currencySymbols[code]
+	if !ok {
+		symbol = code
+	}
+	return Currency{Code: code, Symbol: symbol, Rate: rate}, nil
+}
+
+// Formatter formats numbers for display.
+type Formatter struct {
+	locale   Locale
+	currency Currency
+	spellOut bool
+}
+
+// Option is a functional option for configuring a Formatter.
+type Option func(*Formatter)
+
+// WithLocale sets the separator conventions.
+func WithLocale(l Locale) Option {
+	return func(f *Formatter) {
+		f.locale = l
+	}
+}
+
+// WithCurrency sets the currency costs are converted to.
+func WithCurrency(c Currency) Option {
+	return func(f *Formatter) {
+		f.currency = c
+	}
+}
+
+// WithSpellOut shows token counts in full ("12,345") instead of compact
+// ("12.3k").
+func WithSpellOut(spellOut bool) Option {
+	return func(f *Formatter) {
+		f.spellOut = spellOut
+	}
+}
+
+// New creates a Formatter. Without options it uses English separators, USD
+// and compact token counts.
+func New(opts ...Option) *Formatter {
+	f := &Formatter{locale: English, currency: USD}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+var (
+	mu         sync.RWMutex
+	defaultFmt = New()
+)
+
+// Default returns the process-wide Formatter configured from flags.
+func Default() *Formatter {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultFmt
+}
+
+// SetDefault replaces the process-wide Formatter.
+func SetDefault(f *Formatter) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultFmt = f
+}
+
+// Int formats n with thousands separators (e.g. 1234567 -> "1,234,567").
+func (f *Formatter) Int(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	return sign + f.group(digits)
+}
+
+// Float formats v with prec fraction digits and locale separators.
+func (f *Formatter) Float(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	out := sign + f.group(whole)
+	if hasFrac {
+		out += f.locale.Decimal + frac
+	}
+	return out
+}
+
+// Tokens formats a token count compactly (e.g. 1234 -> "1.2k", 1234567 ->
+// "1.2M"), or in full with separators in spell-out mode.
+func (f *Formatter) Tokens(n int) string {
+	if f.spellOut {
+		return f.Int(n)
+	}
+	switch {
+	case n >= 1_000_000:
+		return f.compact(float64(n)/1_000_000) + "M"
+	case n >= 1_000:
+		return f.compact(float64(n)/1_000) + "k"
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// compact formats a scaled count with one fraction digit and no grouping,
+// so values just under the next unit read "1000.0k" rather than "1,000.0k".
+func (f *Formatter) compact(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', 1, 64), ".", f.locale.Decimal, 1)
+}
+
+// Convert converts a USD amount to the configured currency.
+func (f *Formatter) Convert(usd float64) float64 {
+	return usd * f.currency.Rate
+}
+
+// Cost converts a USD amount and formats it with the currency symbol and
+// prec fraction digits (e.g. "$0.1234", "0,1140 €").
+func (f *Formatter) Cost(usd float64, prec int) string {
+	amount := f.Float(f.Convert(usd), prec)
+	symbol := f.currency.Symbol
+	switch {
+	case f.locale.SymbolAfter:
+		return amount + "\u00a0" + symbol
+	case len(symbol) == 3 && symbol == f.currency.Code:
+		return symbol + "\u00a0" + amount
+	default:
+		return symbol + amount
+	}
+}
+
+// group inserts the locale's group separator every three digits.
+func (f *Formatter) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var sb strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteString(f.locale.Group)
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+	return sb.String()
+}
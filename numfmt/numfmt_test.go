@@ -0,0 +1,144 @@
+package numfmt
+
+import "testing"
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Locale
+	}{
+		{"en", English},
+		{"C", English},
+		{"en_US.UTF-8", English},
+		{"de_DE.UTF-8", continental},
+		{"pt-BR", continental},
+		{"de_CH", Locale{Group: "’", Decimal: "."}},
+		{"sv_SE@euro", spaced},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocale(tt.tag)
+		if err != nil {
+			t.Errorf("ParseLocale(%q): %v", tt.tag, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLocale(%q) = %+v, want %+v", tt.tag, got, tt.want)
+		}
+	}
+
+	if _, err := ParseLocale("xx_YY"); err == nil {
+		t.Error("expected error for unknown locale")
+	}
+}
+
+func TestEnvLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := EnvLocale(); got != "de_DE.UTF-8" {
+		t.Errorf("EnvLocale() = %q, want LC_NUMERIC to win over LANG", got)
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	c, err := ParseCurrency("eur", 0.92)
+	if err != nil {
+		t.Fatalf("ParseCurrency: %v", err)
+	}
+	if c.Code != "EUR" || c.Symbol != "€" || c.Rate != 0.92 {
+		t.Errorf("ParseCurrency(eur) = %+v", c)
+	}
+
+	c, err = ParseCurrency("CHF", 0.9)
+	if err != nil || c.Symbol != "CHF" {
+		t.Errorf("expected code as symbol for CHF, got %+v (%v)", c, err)
+	}
+
+	for _, bad := range []struct {
+		code string
+		rate float64
+	}{{"EURO", 1}, {"E1R", 1}, {"EUR", 0}, {"EUR", -1}} {
+		if _, err := ParseCurrency(bad.code, bad.rate); err == nil {
+			t.Errorf("ParseCurrency(%q, %v) expected error", bad.code, bad.rate)
+		}
+	}
+}
+
+func TestFormatter_Int(t *testing.T) {
+	en := New()
+	de := New(WithLocale(continental))
+	tests := []struct {
+		f    *Formatter
+		n    int
+		want string
+	}{
+		{en, 0, "0"},
+		{en, 999, "999"},
+		{en, 1000, "1,000"},
+		{en, 1234567, "1,234,567"},
+		{en, -12345, "-12,345"},
+		{de, 1234567, "1.234.567"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Int(tt.n); got != tt.want {
+			t.Errorf("Int(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatter_Tokens(t *testing.T) {
+	tests := []struct {
+		f    *Formatter
+		n    int
+		want string
+	}{
+		{New(), 999, "999"},
+		{New(), 1500, "1.5k"},
+		{New(), 999999, "1000.0k"},
+		{New(), 3400000, "3.4M"},
+		{New(WithLocale(continental)), 1500, "1,5k"},
+		{New(WithSpellOut(true)), 3400000, "3,400,000"},
+		{New(WithLocale(spaced), WithSpellOut(true)), 12345, "12 345"},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Tokens(tt.n); got != tt.want {
+			t.Errorf("Tokens(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatter_Cost(t *testing.T) {
+	eur, _ := ParseCurrency("EUR", 0.5)
+	chf, _ := ParseCurrency("CHF", 2)
+	tests := []struct {
+		name string
+		f    *Formatter
+		usd  float64
+		prec int
+		want string
+	}{
+		{"default", New(), 0.1234, 4, "$0.1234"},
+		{"grouped", New(), 1234.5, 2, "$1,234.50"},
+		{"converted after", New(WithLocale(continental), WithCurrency(eur)), 2.5, 2, "1,25 €"},
+		{"converted before", New(WithCurrency(eur)), 2.5, 2, "€1.25"},
+		{"code symbol", New(WithCurrency(chf)), 1, 2, "CHF 2.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.f.Cost(tt.usd, tt.prec); got != tt.want {
+				t.Errorf("Cost(%v, %d) = %q, want %q", tt.usd, tt.prec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	orig := Default()
+	t.Cleanup(func() { SetDefault(orig) })
+
+	f := New(WithSpellOut(true))
+	SetDefault(f)
+	if Default() != f {
+		t.Error("expected Default to return the formatter passed to SetDefault")
+	}
+}
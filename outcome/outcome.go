@@ -0,0 +1,122 @@
+// Package outcome classifies how a session ended — success, partial,
+// failed or interrupted — from its final result and the errors and
+// permission denials along the way. The verdict is shown on the session
+// summary, written to -summary-json and can fail the run with -fail-on.
+package outcome
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Outcome is the verdict on a session.
+type Outcome string
+
+const (
+	// Success is a session that finished without an error and within the
+	// Rules' tolerance for tool errors and denials.
+	Success Outcome = "success"
+	// Partial is a session that finished without an error but had more
+	// tool errors or permission denials than the Rules allow.
+	Partial Outcome = "partial"
+	// Failed is a session whose result was an error.
+	Failed Outcome = "failed"
+	// Interrupted is a session that never reported a result, e.g. because
+	// the agent was killed or the stream was cut off.
+	Interrupted Outcome = "interrupted"
+)
+
+// All lists the outcomes from best to worst.
+var All = []Outcome{Success, Partial, Failed, Interrupted}
+
+// Parse returns the outcome named s.
+func Parse(s string) (Outcome, error) {
+	for _, o := range All {
+		if string(o) == s {
+			return o, nil
+		}
+	}
+	return "", fmt.Errorf("unknown outcome %q (want one of %s)", s, strings.Join(names(), ", "))
+}
+
+func names() []string {
+	out := make([]string, len(All))
+	for i, o := range All {
+		out[i] = string(o)
+	}
+	return out
+}
+
+// Facts are what a session's outcome is judged on.
+type Facts struct {
+	// Finished is whether the session reported a result (a Claude result
+	// event, or a completed or failed Codex turn).
+	Finished bool
+	// IsError is whether that result was an error.
+	IsError    bool
+	ToolErrors int
+	Denials    int
+}
+
+// Rules set how many tool errors and permission denials a finished
+// session may have and still count as a Success.
+type Rules struct {
+	MaxToolErrors int
+	MaxDenials    int
+}
+
+// DefaultRules tolerate a couple of failed tool calls, which agents
+// routinely recover from, but no permission denials.
+var DefaultRules = Rules{MaxToolErrors: 2, MaxDenials: 0}
+
+// Validate checks that the limits are not negative.
+func (r Rules) Validate() error {
+	if r.MaxToolErrors < 0 || r.MaxDenials < 0 {
+		return fmt.Errorf("outcome limits must be >= 0, got %d tool errors and %d denials", r.MaxToolErrors, r.MaxDenials)
+	}
+	return nil
+}
+
+// Classify returns the outcome of a session with the given facts.
+func (r Rules) Classify(f Facts) Outcome {
+	switch {
+	case !f.Finished:
+		return Interrupted
+	case f.IsError:
+		return Failed
+	case f.ToolErrors > r.MaxToolErrors || f.Denials > r.MaxDenials:
+		return Partial
+	}
+	return Success
+}
+
+// Error reports that a session ended with an outcome the run was told to
+// fail on.
+type Error struct {
+	Outcome Outcome
+}
+
+func (e *Error) Error() string {
+	return "session outcome: " + string(e.Outcome)
+}
+
+var (
+	mu           sync.RWMutex
+	defaultRules = DefaultRules
+)
+
+// Default returns the process-wide rules, DefaultRules unless configured
+// with -partial-tool-errors or -partial-denials.
+func Default() Rules {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultRules
+}
+
+// SetDefault replaces the process-wide rules.
+func SetDefault(r Rules) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultRules = r
+}
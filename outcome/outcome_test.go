@@ -0,0 +1,59 @@
+package outcome
+
+import "testing"
+
+func TestRules_Classify(t *testing.T) {
+	tests := []struct {
+		name  string
+		facts Facts
+		want  Outcome
+	}{
+		{"no result", Facts{ToolErrors: 5}, Interrupted},
+		{"error result", Facts{Finished: true, IsError: true}, Failed},
+		{"clean", Facts{Finished: true}, Success},
+		{"tolerated tool errors", Facts{Finished: true, ToolErrors: 2}, Success},
+		{"too many tool errors", Facts{Finished: true, ToolErrors: 3}, Partial},
+		{"denied", Facts{Finished: true, Denials: 1}, Partial},
+	}
+	for _, tt := range tests {
+		if got := DefaultRules.Classify(tt.facts); got != tt.want {
+			t.Errorf("%s: Classify(%+v) = %s, want %s", tt.name, tt.facts, got, tt.want)
+		}
+	}
+
+	lenient := Rules{MaxToolErrors: 10, MaxDenials: 1}
+	if got := lenient.Classify(Facts{Finished: true, ToolErrors: 3, Denials: 1}); got != Success {
+		t.Errorf("lenient rules: Classify = %s, want success", got)
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	if err := DefaultRules.Validate(); err != nil {
+		t.Errorf("DefaultRules.Validate() = %v", err)
+	}
+	if err := (Rules{MaxToolErrors: -1}).Validate(); err == nil {
+		t.Error("expected negative limits to be rejected")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, o := range All {
+		if got, err := Parse(string(o)); err != nil || got != o {
+			t.Errorf("Parse(%q) = %q, %v", o, got, err)
+		}
+	}
+	if _, err := Parse("meh"); err == nil {
+		t.Error("expected an unknown outcome to be rejected")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(DefaultRules)
+	if Default() != DefaultRules {
+		t.Fatalf("Default() = %+v, want DefaultRules", Default())
+	}
+	SetDefault(Rules{MaxToolErrors: 7})
+	if Default().MaxToolErrors != 7 {
+		t.Errorf("Default() = %+v after SetDefault", Default())
+	}
+}
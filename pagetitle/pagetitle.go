@@ -0,0 +1,245 @@
+// Package pagetitle looks up the titles of web pages an agent fetched, for
+// the -resolve-titles flag, so WebFetch headers can name the page next to
+// its URL.
+//
+// Looking up a title makes a request of its own to the page, which a
+// transcript reader may not expect, so nothing is fetched unless a Resolver
+// is installed with SetDefault. Only the start of an HTML page is read, and
+// each URL is fetched at most once per process.
+package pagetitle
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/worker"
+)
+
+// Defaults for a Resolver created without options.
+const (
+	DefaultTimeout  = 3 * time.Second
+	DefaultMaxBytes = 32 << 10
+)
+
+// maxTitleLen is the longest title returned, in runes; longer titles are
+// cut with an ellipsis.
+const maxTitleLen = 60
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title`)
+
+// Resolver fetches and caches page titles. A nil Resolver resolves nothing.
+type Resolver struct {
+	client   *http.Client
+	timeout  time.Duration
+	maxBytes int64
+	workers  *worker.Group
+
+	mu    sync.Mutex
+	cache map[string]*lookup
+}
+
+// lookup is a title fetch, done or in flight.
+type lookup struct {
+	done  chan struct{}
+	title string
+}
+
+// Option is a functional option for configuring a Resolver.
+type Option func(*Resolver)
+
+// WithClient sets the HTTP client used to fetch pages.
+func WithClient(c *http.Client) Option {
+	return func(r *Resolver) {
+		r.client = c
+	}
+}
+
+// WithTimeout sets how long a page fetch may take.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Resolver) {
+		r.timeout = d
+	}
+}
+
+// WithMaxBytes sets how much of a page is read looking for its title.
+func WithMaxBytes(n int64) Option {
+	return func(r *Resolver) {
+		r.maxBytes = n
+	}
+}
+
+// WithWorkers sets the Group lookups run in (default: worker.Default()).
+// Closing it cancels lookups still in flight.
+func WithWorkers(g *worker.Group) Option {
+	return func(r *Resolver) {
+		r.workers = g
+	}
+}
+
+// New creates a Resolver.
+func New(opts ...Option) *Resolver {
+	r := &Resolver{
+		client:   http.DefaultClient,
+		timeout:  DefaultTimeout,
+		maxBytes: DefaultMaxBytes,
+		cache:    make(map[string]*lookup),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+var (
+	mu         sync.RWMutex
+	defaultRes *Resolver
+)
+
+// Default returns the process-wide Resolver, or nil when titles are not
+// resolved.
+func Default() *Resolver {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultRes
+}
+
+// SetDefault replaces the process-wide Resolver.
+func SetDefault(r *Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultRes = r
+}
+
+// Prefetch starts looking up the title of rawURL in the background, so it
+// is likely ready by the time the agent's fetch of the page completes.
+func (r *Resolver) Prefetch(rawURL string) {
+	r.start(rawURL)
+}
+
+// Title returns the title of rawURL, waiting for its lookup to finish. It
+// returns "" when the page has no title or could not be fetched.
+func (r *Resolver) Title(rawURL string) string {
+	l := r.start(rawURL)
+	if l == nil {
+		return ""
+	}
+	<-l.done
+	return l.title
+}
+
+// Cached returns the title of rawURL if its lookup has finished, without
+// waiting.
+func (r *Resolver) Cached(rawURL string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.Lock()
+	l, ok := r.cache[rawURL]
+	r.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	select {
+	case <-l.done:
+		return l.title, true
+	default:
+		return "", false
+	}
+}
+
+// Pending returns a channel that is closed when the lookup of rawURL
+// finishes, or nil when no lookup of it is in flight.
+func (r *Resolver) Pending(rawURL string) <-chan struct{} {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	l, ok := r.cache[rawURL]
+	r.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	select {
+	case <-l.done:
+		return nil
+	default:
+		return l.done
+	}
+}
+
+// start returns the lookup of rawURL, starting it if needed. It returns nil
+// for a nil Resolver and for URLs that are not http or https.
+func (r *Resolver) start(rawURL string) *lookup {
+	if r == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l, ok := r.cache[rawURL]; ok {
+		return l
+	}
+	l := &lookup{done: make(chan struct{})}
+	r.cache[rawURL] = l
+	workers := r.workers
+	if workers == nil {
+		workers = worker.Default()
+	}
+	workers.Go("page title", func(ctx context.Context) error {
+		defer close(l.done)
+		var err error
+		l.title, err = r.fetch(ctx, rawURL)
+		return err
+	})
+	return l
+}
+
+// fetch reads the start of the page at rawURL and returns its title. Pages
+// that are missing or not HTML have no title; only failed requests are
+// errors.
+func (r *Resolver) fetch(ctx context.Context, rawURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 || !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, r.maxBytes))
+	if err != nil && len(body) == 0 {
+		return "", err
+	}
+	return ParseTitle(body), nil
+}
+
+// ParseTitle returns the text of the first <title> element in page, with
+// entities decoded and whitespace collapsed.
+func ParseTitle(page []byte) string {
+	m := titleRe.FindSubmatch(page)
+	if m == nil {
+		return ""
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if runes := []rune(title); len(runes) > maxTitleLen {
+		title = string(runes[:maxTitleLen-1]) + "…"
+	}
+	return title
+}
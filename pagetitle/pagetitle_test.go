@@ -0,0 +1,152 @@
+package pagetitle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/worker"
+)
+
+func TestParseTitle(t *testing.T) {
+	tests := []struct {
+		page, want string
+	}{
+		{`<html><head><title>Go Docs</title></head>`, "Go Docs"},
+		{`<TITLE lang="en">  Tom &amp; Jerry
+		  </TITLE>`, "Tom & Jerry"},
+		{`<html><body>no title</body>`, ""},
+		{`<title>` + strings.Repeat("x", 100) + `</title>`, strings.Repeat("x", maxTitleLen-1) + "…"},
+	}
+	for _, tt := range tests {
+		if got := ParseTitle([]byte(tt.page)); got != tt.want {
+			t.Errorf("ParseTitle(%q) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestResolver_Title(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		switch req.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<title>Example Page</title>")
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"title":"<title>no</title>"}`)
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	r := New(WithClient(srv.Client()))
+	if got := r.Title(srv.URL + "/page"); got != "Example Page" {
+		t.Errorf("Title(page) = %q, want %q", got, "Example Page")
+	}
+	if got, ok := r.Cached(srv.URL + "/page"); !ok || got != "Example Page" {
+		t.Errorf("Cached(page) = %q, %v, want the fetched title", got, ok)
+	}
+	r.Title(srv.URL + "/page")
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want the title fetched once", n)
+	}
+
+	for _, path := range []string{"/json", "/missing"} {
+		if got := r.Title(srv.URL + path); got != "" {
+			t.Errorf("Title(%s) = %q, want none", path, got)
+		}
+	}
+}
+
+func TestResolver_Pending(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<title>Late</title>")
+	}))
+	defer srv.Close()
+
+	r := New(WithClient(srv.Client()))
+	if r.Pending(srv.URL) != nil {
+		t.Error("expected no pending lookup before Prefetch")
+	}
+	r.Prefetch(srv.URL)
+	done := r.Pending(srv.URL)
+	if done == nil {
+		t.Fatal("expected a pending lookup after Prefetch")
+	}
+	close(release)
+	<-done
+	if got, ok := r.Cached(srv.URL); !ok || got != "Late" {
+		t.Errorf("Cached() = %q, %v, want the fetched title", got, ok)
+	}
+	if r.Pending(srv.URL) != nil {
+		t.Error("expected no pending lookup once it finished")
+	}
+}
+
+func TestResolver_IgnoresNonHTTP(t *testing.T) {
+	r := New()
+	if got := r.Title("file:///etc/passwd"); got != "" {
+		t.Errorf("Title(file URL) = %q, want none", got)
+	}
+	if _, ok := r.Cached("file:///etc/passwd"); ok {
+		t.Error("expected no lookup for a file URL")
+	}
+}
+
+func TestResolver_Nil(t *testing.T) {
+	var r *Resolver
+	r.Prefetch("https://example.com")
+	if got := r.Title("https://example.com"); got != "" {
+		t.Errorf("nil Resolver Title() = %q, want none", got)
+	}
+}
+
+func TestResolver_ReportsFailedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // refuse connections
+
+	var log bytes.Buffer
+	g := worker.New(context.Background(), diag.New(&log, diag.LevelWarn))
+	r := New(WithWorkers(g))
+	if got := r.Title(srv.URL + "/page"); got != "" {
+		t.Errorf("Title() = %q, want none", got)
+	}
+	r.Title(srv.URL + "/missing")
+	if err := g.Close(); err == nil {
+		t.Error("expected the failed request to be reported")
+	}
+	if !strings.Contains(log.String(), "page title: ") {
+		t.Errorf("expected a diagnostic, got %q", log.String())
+	}
+}
+
+func TestResolver_CancelledByWorkers(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	g := worker.New(context.Background(), diag.New(&bytes.Buffer{}, diag.LevelOff))
+	r := New(WithClient(srv.Client()), WithWorkers(g))
+	r.Prefetch(srv.URL + "/slow")
+	if err := g.Close(); err != nil {
+		t.Errorf("Close() = %v, want the lookup cancelled quietly", err)
+	}
+	if got, ok := r.Cached(srv.URL + "/slow"); !ok || got != "" {
+		t.Errorf("Cached() = %q, %v, want a finished lookup without a title", got, ok)
+	}
+}
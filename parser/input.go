@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// StdinPath is the input path that selects standard input.
+const StdinPath = "-"
+
+// DefaultFollowInterval is how often a followed file is polled for new data.
+const DefaultFollowInterval = 250 * time.Millisecond
+
+// OpenInput opens the transcript at path for reading. StdinPath selects
+// standard input. When follow is true the returned reader behaves like
+// `tail -f`: instead of returning io.EOF it waits for the file to grow.
+func OpenInput(path string, follow bool) (io.ReadCloser, error) {
+	if path == StdinPath {
+		return io.NopCloser(os.Stdin), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if follow {
+		return NewFollowReader(f, DefaultFollowInterval), nil
+	}
+	return f, nil
+}
+
+// FollowReader reads a growing file, polling for new data at EOF rather than
+// ending the stream. If the file is truncated (e.g. rotated in place), reading
+// restarts from the beginning.
+type FollowReader struct {
+	f        *os.File
+	interval time.Duration
+	sleep    func(time.Duration)
+	offset   int64
+	closed   chan struct{}
+}
+
+// NewFollowReader creates a FollowReader over f that polls every interval.
+func NewFollowReader(f *os.File, interval time.Duration) *FollowReader {
+	return &FollowReader{
+		f:        f,
+		interval: interval,
+		sleep:    time.Sleep,
+		closed:   make(chan struct{}),
+	}
+}
+
+// Read implements io.Reader. It blocks at EOF until more data is appended or
+// the reader is closed.
+func (r *FollowReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.f.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if r.isClosed() {
+			return 0, io.EOF
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		r.sleep(r.interval)
+		if info, statErr := r.f.Stat(); statErr == nil && info.Size() < r.offset {
+			if _, err := r.f.Seek(0, io.SeekStart); err != nil {
+				return 0, err
+			}
+			r.offset = 0
+		}
+	}
+}
+
+func (r *FollowReader) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops following and closes the file.
+func (r *FollowReader) Close() error {
+	if r.isClosed() {
+		return nil
+	}
+	close(r.closed)
+	return r.f.Close()
+}
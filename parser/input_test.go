@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenInput(t *testing.T) {
+	t.Run("stdin", func(t *testing.T) {
+		in, err := OpenInput(StdinPath, false)
+		if err != nil {
+			t.Fatalf("OpenInput: %v", err)
+		}
+		if in == nil {
+			t.Fatal("expected a reader for stdin")
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "t.jsonl")
+		if err := os.WriteFile(path, []byte("line\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		in, err := OpenInput(path, false)
+		if err != nil {
+			t.Fatalf("OpenInput: %v", err)
+		}
+		defer in.Close()
+		data, err := io.ReadAll(in)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if string(data) != "line\n" {
+			t.Errorf("data = %q", data)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := OpenInput(filepath.Join(t.TempDir(), "missing"), false); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+}
+
+func TestFollowReader_WaitsForAppendedData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(path, []byte("first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewFollowReader(f, time.Millisecond)
+	defer r.Close()
+
+	polls := 0
+	r.sleep = func(time.Duration) {
+		polls++
+		if polls == 1 {
+			w, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.WriteString("second\n")
+			w.Close()
+		}
+	}
+
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "first\n" {
+		t.Fatalf("first read = %q, %v", buf[:n], err)
+	}
+	n, err = r.Read(buf)
+	if err != nil || string(buf[:n]) != "second\n" {
+		t.Fatalf("second read = %q, %v", buf[:n], err)
+	}
+	if polls != 1 {
+		t.Errorf("polls = %d, want 1", polls)
+	}
+}
+
+func TestFollowReader_RestartsAfterTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(path, []byte("old content\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewFollowReader(f, time.Millisecond)
+	defer r.Close()
+	r.sleep = func(time.Duration) {
+		if err := os.WriteFile(path, []byte("new\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := make([]byte, 64)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "new\n" {
+		t.Fatalf("read after truncation = %q, %v", buf[:n], err)
+	}
+}
+
+func TestFollowReader_CloseEndsStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t.jsonl")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewFollowReader(f, time.Millisecond)
+	r.sleep = func(time.Duration) { r.Close() }
+
+	if _, err := r.Read(make([]byte, 8)); err != io.EOF {
+		t.Errorf("expected io.EOF after Close, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+)
+
+// SessionError reports that the last session in the stream failed: its
+// result was an error, or tool calls were denied permission. With
+// -exit-on-error it becomes the run's error, so CI sees a non-zero exit.
+type SessionError struct {
+	// Reason is the first error message, or the result subtype when there
+	// is none. It is empty when the session only had denials.
+	Reason string
+	// Denied names the tools whose calls were denied permission.
+	Denied []string
+}
+
+func (e *SessionError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("session had %d permission denials (%s)", len(e.Denied), strings.Join(e.Denied, ", "))
+	}
+	msg := "session failed: " + e.Reason
+	if len(e.Denied) > 0 {
+		msg += fmt.Sprintf(" (%d permission denials)", len(e.Denied))
+	}
+	return msg
+}
+
+// recordOutcome remembers how the session parsed ended, when it is a
+// Claude result or a finished Codex turn. Each session replaces the last,
+// so a stream of several sessions is judged by its final one.
+func (p *Parser) recordOutcome(parsed events.Event) {
+	switch e := parsed.(type) {
+	case events.ResultEvent:
+		p.ending = outcome.Facts{Finished: true, IsError: e.Data.IsError, Denials: len(e.Data.PermissionDenials)}
+		p.failure = nil
+		if !e.Data.IsError && len(e.Data.PermissionDenials) == 0 {
+			return
+		}
+		failure := &SessionError{}
+		if e.Data.IsError {
+			failure.Reason = e.Data.Subtype
+			if len(e.Data.Errors) > 0 {
+				failure.Reason = e.Data.Errors[0]
+			}
+			if failure.Reason == "" {
+				failure.Reason = "error"
+			}
+		}
+		for _, denial := range e.Data.PermissionDenials {
+			failure.Denied = append(failure.Denied, denial.ToolName)
+		}
+		p.failure = failure
+	case events.CodexEvent:
+		switch e.Data.Type {
+		case codex.TypeTurnStarted:
+			p.ending.Finished = false
+		case codex.TypeTurnCompleted:
+			p.ending = outcome.Facts{Finished: true}
+			p.failure = nil
+		case codex.TypeTurnFailed:
+			p.ending = outcome.Facts{Finished: true, IsError: true}
+			reason := "turn failed"
+			if e.Data.Error != nil && e.Data.Error.Message != "" {
+				reason = e.Data.Error.Message
+			}
+			p.failure = &SessionError{Reason: reason}
+		}
+	}
+}
+
+// SessionError returns why the last session in the stream failed, or nil
+// when it succeeded or no session has ended.
+func (p *Parser) SessionError() error {
+	if p.failure == nil {
+		return nil
+	}
+	return p.failure
+}
+
+// Outcome classifies the last session in the stream by the
+// outcome.Default rules, counting the failed tool calls of the whole
+// stream. A stream that ends before a result is Interrupted.
+func (p *Parser) Outcome() outcome.Outcome {
+	facts := p.ending
+	if stats := p.processor.Renderers().Stats; stats != nil {
+		facts.ToolErrors = stats.ToolErrors()
+	}
+	return outcome.Default().Classify(facts)
+}
+
+// WatchedPathCalls returns how many tool calls in the stream touched paths
+// on the watchlist.
+func (p *Parser) WatchedPathCalls() int {
+	if stats := p.processor.Renderers().Stats; stats != nil {
+		return stats.WatchedPathCalls()
+	}
+	return 0
+}
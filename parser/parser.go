@@ -1,27 +1,56 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/budget"
+	"github.com/johnnyfreeman/viewscreen/debugevents"
+	"github.com/johnnyfreeman/viewscreen/diag"
 	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/filter"
 	"github.com/johnnyfreeman/viewscreen/jsonl"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/perf"
+	"github.com/johnnyfreeman/viewscreen/redact"
+	"github.com/johnnyfreeman/viewscreen/sink"
 	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
 )
 
 // EventHandler is called for each parsed event
 type EventHandler func(eventType string, line []byte) error
 
 // Parser handles reading and dispatching events from an input source.
-// It uses EventProcessor internally for event processing, writing output
-// directly to stdout for streaming display.
+// Events of a type with a registered EventRenderer go to it; the rest are
+// rendered by an internal EventProcessor, writing output directly to stdout
+// for streaming display.
 type Parser struct {
 	input        io.Reader
 	output       io.Writer
 	errOutput    io.Writer
+	logger       *diag.Logger
 	eventHandler EventHandler
 	processor    *events.EventProcessor
+	state        *state.State
+	entryHandler func(timeline.Entry)
+	annotations  annotations.Set
+	sinks        []sink.Sink
+	renderers    map[string]EventRenderer
+	filter       *filter.Filter
+	redactor     *redact.Redactor
+	budget       budget.Budget
+	budgetLevel  budget.Level
+	failure      *SessionError
+	debug        *debugevents.Log
+	ending       outcome.Facts
+	passNoise    bool
+	resized      <-chan os.Signal
+	width        func() int
 }
 
 // Option configures a Parser
@@ -41,6 +70,14 @@ func WithErrOutput(w io.Writer) Option {
 	}
 }
 
+// WithLogger sets the diagnostics logger (default: the -log-level logger
+// writing to the error output)
+func WithLogger(l *diag.Logger) Option {
+	return func(p *Parser) {
+		p.logger = l
+	}
+}
+
 // WithEventHandler sets a custom event handler for testing
 func WithEventHandler(h EventHandler) Option {
 	return func(p *Parser) {
@@ -52,7 +89,8 @@ func WithEventHandler(h EventHandler) Option {
 func WithRendererSet(rs *events.RendererSet) Option {
 	return func(p *Parser) {
 		// Create a new processor with the custom renderers
-		p.processor = events.NewEventProcessorWithRenderers(state.NewState(), rs)
+		p.state = state.NewState()
+		p.processor = events.NewEventProcessorWithRenderers(p.state, rs)
 	}
 }
 
@@ -70,91 +108,181 @@ func NewParser() *Parser {
 
 // NewParserWithOptions creates a new Parser with custom options
 func NewParserWithOptions(opts ...Option) *Parser {
+	s := state.NewState()
 	p := &Parser{
 		input:     os.Stdin,
 		output:    os.Stdout,
 		errOutput: os.Stderr,
-		processor: events.NewEventProcessor(state.NewState()),
+		processor: events.NewEventProcessor(s),
+		state:     s,
+		renderers: make(map[string]EventRenderer, len(registered)),
+	}
+	for eventType, r := range registered {
+		p.renderers[eventType] = r
 	}
 	for _, opt := range opts {
 		opt(p)
 	}
+	if p.logger == nil {
+		p.logger = diag.Default().WithOutput(p.errOutput)
+	}
 	return p
 }
 
+// WrapInput replaces the parser's input with wrap(input). It is used to layer
+// behavior such as recording onto a parser built by a factory.
+func (p *Parser) WrapInput(wrap func(io.Reader) io.Reader) {
+	p.input = wrap(p.input)
+}
+
+// WrapOutput replaces the parser's output with wrap(output), e.g. to send it
+// through a pager.
+func (p *Parser) WrapOutput(wrap func(io.Writer) io.Writer) {
+	p.output = wrap(p.output)
+}
+
+// OnEntry registers h to be called with every timeline entry the parser
+// renders, e.g. to collect the session for export.
+func (p *Parser) OnEntry(h func(timeline.Entry)) {
+	p.entryHandler = h
+}
+
+// AddSink registers s to receive every rendered event alongside the output
+// writer, e.g. a text log or export. The caller closes s after Run.
+func (p *Parser) AddSink(s sink.Sink) {
+	p.sinks = append(p.sinks, s)
+}
+
+// Annotate renders the reviewer comments in set beneath the blocks they
+// reference.
+func (p *Parser) Annotate(set annotations.Set) {
+	p.annotations = set
+}
+
+// Filter drops the events and content blocks f excludes before they are
+// dispatched to a renderer or written to the sinks.
+func (p *Parser) Filter(f *filter.Filter) {
+	p.filter = f
+}
+
+// LowMemory makes rendering strictly streaming: no render timings are
+// collected and the processor keeps nothing beyond the event at hand (see
+// events.EventProcessor.SetLowMemory).
+func (p *Parser) LowMemory() {
+	p.state.RenderTimings = nil
+	p.processor.SetLowMemory(true)
+}
+
+// Redact masks the secrets r finds in each input line before it is
+// filtered, rendered or written to the sinks.
+func (p *Parser) Redact(r *redact.Redactor) {
+	p.redactor = r
+}
+
+// FollowResize re-wraps the output to width() from the next event on each
+// time resized receives, such as the SIGWINCH terminal.NotifyResize relays.
+func (p *Parser) FollowResize(resized <-chan os.Signal, width func() int) {
+	p.resized = resized
+	p.width = width
+}
+
+// PassNoise shows input lines that are not JSON at all, such as warnings of
+// the script that started the agent, as muted output instead of warning
+// about each (see events.IsNoise).
+func (p *Parser) PassNoise() {
+	p.passNoise = true
+}
+
+// TurnDivider separates the main agent's turns with divider, one of the
+// config.TurnDivider* values (see events.EventProcessor.SetTurnDivider).
+func (p *Parser) TurnDivider(divider string) {
+	p.processor.SetTurnDivider(divider)
+}
+
+// DebugEvents logs how each input line was classified and rendered to l.
+func (p *Parser) DebugEvents(l *debugevents.Log) {
+	p.debug = l
+}
+
+// Budget warns in the output once the stream's sessions have cost most of
+// b's limit, and again when they exceed it. With b.Stop, exceeding the limit
+// stops rendering and Run returns a *budget.ExceededError.
+func (p *Parser) Budget(b budget.Budget) {
+	p.budget = b
+}
+
+// checkBudget reports spending that has reached a new budget level.
+func (p *Parser) checkBudget() error {
+	spent := p.state.CumulativeCost
+	level := p.budget.Level(spent)
+	if level == p.budgetLevel {
+		return nil
+	}
+	p.budgetLevel = level
+	switch level {
+	case budget.Warning:
+		fmt.Fprintf(p.output, "%s %s\n", style.WarningText("⚠"), style.WarningText(p.budget.Message(spent)))
+	case budget.Exceeded:
+		fmt.Fprintf(p.output, "%s %s\n", style.ErrorBoldText("✗"), style.ErrorBoldText(p.budget.Message(spent)))
+		if p.budget.Stop {
+			return &budget.ExceededError{Spent: spent, Limit: p.budget.Limit}
+		}
+	}
+	return nil
+}
+
 // Renderers returns the underlying RendererSet for tests that need to inspect state.
 func (p *Parser) Renderers() *events.RendererSet {
 	return p.processor.Renderers()
 }
 
+// Timings returns the render timings collected while running.
+func (p *Parser) Timings() *perf.Timings {
+	return p.processor.Timings()
+}
+
 // Run reads events from input and renders them
 func (p *Parser) Run() error {
-	scanner := jsonl.NewScanner(p.input)
+	defer p.logger.Flush()
+	scanner := jsonl.NewReader(p.input, p.logger)
+	out := sink.Multi(append([]sink.Sink{sink.Terminal(p.output)}, p.sinks...)...)
+
+	builtin := builtinRenderer{p: p, out: out}
 
 	for scanner.Scan() {
+		select {
+		case <-p.resized:
+			p.processor.SetWidth(p.width())
+		default:
+		}
 		line := scanner.Text()
 		if line == "" {
 			continue
 		}
-
-		// Parse the event using the events package
-		parsed := events.Parse(line)
-		if parsed == nil {
+		line = p.redactor.Line(line)
+		filtered, keep := p.filter.Apply(line)
+		if !keep {
+			p.debug.Filtered(line)
 			continue
 		}
+		line = filtered
 
-		// Handle parse errors
-		if parseErr, ok := parsed.(events.ParseError); ok {
-			if parseErr.Err != nil {
-				fmt.Fprintf(p.errOutput, "Error parsing JSON: %v\n", parseErr.Err)
-			} else {
-				fmt.Fprintf(p.errOutput, "%s\n", parseErr.Line)
+		if eventType, r, ok := p.registeredRenderer(line); ok {
+			err := r.Render(json.RawMessage(line))
+			p.debug.Registered(line, err)
+			if err != nil {
+				return fmt.Errorf("rendering %s event: %w", eventType, err)
 			}
 			continue
 		}
-
-		// Call event handler if set (for testing)
-		if p.eventHandler != nil {
-			eventType := eventTypeName(parsed)
-			if err := p.eventHandler(eventType, []byte(line)); err != nil {
-				return err
-			}
-		}
-
-		// Process the event through EventProcessor and write output
-		result := p.processor.Process(parsed)
-		if result.Rendered != "" {
-			fmt.Fprint(p.output, result.Rendered)
+		if err := builtin.Render(json.RawMessage(line)); err != nil {
+			return err
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		if err != io.EOF {
-			return fmt.Errorf("error reading input: %w", err)
-		}
+		return fmt.Errorf("error reading input: %w", err)
 	}
 
 	return nil
 }
-
-// eventTypeName returns the event type name for the handler callback
-func eventTypeName(event events.Event) string {
-	switch event.(type) {
-	case events.SystemEvent:
-		return "system"
-	case events.SubAgentSystemEvent:
-		return "system"
-	case events.AssistantEvent:
-		return "assistant"
-	case events.UserEvent:
-		return "user"
-	case events.StreamEvent:
-		return "stream_event"
-	case events.ResultEvent:
-		return "result"
-	case events.CodexEvent:
-		return "codex"
-	default:
-		return "unknown"
-	}
-}
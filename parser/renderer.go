@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"encoding/json"
+
+	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/sink"
+)
+
+// EventRenderer renders the raw JSON line of one event. Returning an error
+// stops the parser.
+type EventRenderer interface {
+	Render(raw json.RawMessage) error
+}
+
+// EventRendererFunc adapts a function to an EventRenderer.
+type EventRendererFunc func(raw json.RawMessage) error
+
+// Render calls f(raw).
+func (f EventRendererFunc) Render(raw json.RawMessage) error {
+	return f(raw)
+}
+
+// registered holds the renderers added with RegisterRenderer, keyed by event
+// type. Types without one use the parser's built-in renderer.
+var registered = map[string]EventRenderer{}
+
+// RegisterRenderer routes events whose "type" is eventType to r in parsers
+// created afterwards, replacing the built-in rendering of that type or adding
+// a new one. Output written by r bypasses the parser's output and sinks.
+func RegisterRenderer(eventType string, r EventRenderer) {
+	registered[eventType] = r
+}
+
+// WithRenderer routes events whose "type" is eventType to r in this parser
+// only, taking precedence over RegisterRenderer.
+func WithRenderer(eventType string, r EventRenderer) Option {
+	return func(p *Parser) {
+		p.renderers[eventType] = r
+	}
+}
+
+// registeredRenderer returns the type of a raw event line and the renderer
+// registered for it, if any.
+func (p *Parser) registeredRenderer(line string) (string, EventRenderer, bool) {
+	if len(p.renderers) == 0 {
+		return "", nil, false
+	}
+	var base struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal([]byte(line), &base) != nil {
+		return "", nil, false
+	}
+	r, ok := p.renderers[base.Type]
+	return base.Type, r, ok
+}
+
+// builtinRenderer renders Claude Code and Codex events through the parser's
+// EventProcessor and writes the result to its output and sinks.
+type builtinRenderer struct {
+	p   *Parser
+	out sink.Sink
+}
+
+func (b builtinRenderer) Render(raw json.RawMessage) error {
+	p := b.p
+	line := string(raw)
+
+	// Parse the event using the events package
+	parsed := events.Parse(line)
+	if parsed == nil {
+		return nil
+	}
+	if p.passNoise {
+		parsed = events.PassNoise(parsed)
+	}
+
+	// Report parse errors and skipped events as diagnostics
+	switch e := parsed.(type) {
+	case events.ParseError:
+		p.debug.Event(line, parsed, nil)
+		if e.Err != nil {
+			p.logger.Warnf("Error parsing JSON: %v", e.Err)
+		} else {
+			p.logger.Warnf("%s", e.Line)
+		}
+		return nil
+	case events.IgnoredEvent:
+		p.logger.Debugf("Ignoring %s event", e.Type)
+	}
+
+	// Call event handler if set (for testing)
+	if p.eventHandler != nil {
+		eventType := events.TypeName(parsed)
+		if eventType == "" {
+			eventType = "unknown"
+		}
+		if err := p.eventHandler(eventType, raw); err != nil {
+			return err
+		}
+	}
+
+	// Process the event through EventProcessor and write it to every sink
+	result := p.processor.ProcessLine(line, parsed)
+	p.recordOutcome(parsed)
+	p.debug.Event(line, parsed, result.Batch.Entries)
+	entries := p.annotations.Interleave(result.Batch.Entries)
+	// A failing file sink must not stop the terminal output; its error
+	// is reported when the caller closes it.
+	_ = b.out.Write(sink.Event{Raw: line, Rendered: result.Rendered, Entries: entries})
+	if p.entryHandler != nil {
+		for _, entry := range entries {
+			p.entryHandler(entry)
+		}
+	}
+	return p.checkBudget()
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParser_WithRenderer_AddsEventType(t *testing.T) {
+	input := `{"type":"deploy","env":"prod"}` + "\n" +
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"hello"}]}}` + "\n"
+
+	var got []string
+	var out, errOut bytes.Buffer
+	p := NewParserWithOptions(
+		WithInput(strings.NewReader(input)),
+		WithOutput(&out),
+		WithErrOutput(&errOut),
+		WithRenderer("deploy", EventRendererFunc(func(raw json.RawMessage) error {
+			got = append(got, string(raw))
+			return nil
+		})),
+	)
+
+	if err := p.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != `{"type":"deploy","env":"prod"}` {
+		t.Errorf("custom renderer got %q", got)
+	}
+	if strings.Contains(errOut.String(), "Unknown event type") {
+		t.Errorf("expected the custom type to be handled, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), "hello") {
+		t.Errorf("expected built-in types to render as before, got %q", out.String())
+	}
+}
+
+func TestParser_WithRenderer_OverridesBuiltin(t *testing.T) {
+	input := `{"type":"assistant","message":{"content":[{"type":"text","text":"hello"}]}}`
+
+	calls := 0
+	var out bytes.Buffer
+	p := NewParserWithOptions(
+		WithInput(strings.NewReader(input)),
+		WithOutput(&out),
+		WithRenderer("assistant", EventRendererFunc(func(json.RawMessage) error {
+			calls++
+			return nil
+		})),
+	)
+
+	if err := p.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the override to be called once, got %d", calls)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no built-in output, got %q", out.String())
+	}
+}
+
+func TestParser_WithRenderer_Error(t *testing.T) {
+	boom := errors.New("boom")
+	p := NewParserWithOptions(
+		WithInput(strings.NewReader(`{"type":"deploy"}`)),
+		WithErrOutput(io.Discard),
+		WithRenderer("deploy", EventRendererFunc(func(json.RawMessage) error { return boom })),
+	)
+
+	err := p.Run()
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the renderer error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "deploy") {
+		t.Errorf("expected the event type in %q", err.Error())
+	}
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	orig := registered
+	t.Cleanup(func() { registered = orig })
+	registered = map[string]EventRenderer{}
+
+	calls := 0
+	RegisterRenderer("deploy", EventRendererFunc(func(json.RawMessage) error {
+		calls++
+		return nil
+	}))
+	local := 0
+	p := NewParserWithOptions(
+		WithInput(strings.NewReader(`{"type":"deploy"}` + "\n" + `{"type":"deploy"}`)),
+	)
+	if err := p.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the registered renderer to be called twice, got %d", calls)
+	}
+
+	p = NewParserWithOptions(
+		WithInput(strings.NewReader(`{"type":"deploy"}`)),
+		WithRenderer("deploy", EventRendererFunc(func(json.RawMessage) error {
+			local++
+			return nil
+		})),
+	)
+	if err := p.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local != 1 || calls != 2 {
+		t.Errorf("expected WithRenderer to take precedence, got local=%d registered=%d", local, calls)
+	}
+}
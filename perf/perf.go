@@ -0,0 +1,90 @@
+// Package perf collects how long viewscreen spends rendering each kind of
+// event, so regressions in markdown or syntax highlighting performance show
+// up as numbers in the details panel and in `viewscreen bench` output.
+package perf
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Timings records render durations per event kind. The zero value is not
+// usable; create one with NewTimings.
+type Timings struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+// NewTimings creates an empty Timings.
+func NewTimings() *Timings {
+	return &Timings{samples: make(map[string][]time.Duration)}
+}
+
+// Record adds one render duration for kind.
+func (t *Timings) Record(kind string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples[kind] = append(t.samples[kind], d)
+}
+
+// Stat summarizes the render durations of one event kind.
+type Stat struct {
+	Kind  string
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	Total time.Duration
+}
+
+// Stats returns a summary per event kind, sorted by kind.
+func (t *Timings) Stats() []Stat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]Stat, 0, len(t.samples))
+	for kind, samples := range t.samples {
+		sorted := append([]time.Duration(nil), samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		var total time.Duration
+		for _, d := range sorted {
+			total += d
+		}
+		stats = append(stats, Stat{
+			Kind:  kind,
+			Count: len(sorted),
+			P50:   percentile(sorted, 50),
+			P95:   percentile(sorted, 95),
+			Total: total,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Kind < stats[j].Kind })
+	return stats
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, which must
+// not be empty.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// FormatDuration formats a render duration at microsecond precision
+// (e.g. "412µs", "1.204ms").
+func FormatDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// WriteTable writes stats as an aligned table with a header row.
+func WriteTable(w io.Writer, stats []Stat) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "event\tcount\tp50\tp95\ttotal\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t\n",
+			s.Kind, s.Count, FormatDuration(s.P50), FormatDuration(s.P95), FormatDuration(s.Total))
+	}
+	return tw.Flush()
+}
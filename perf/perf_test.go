@@ -0,0 +1,82 @@
+package perf
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimings_Stats(t *testing.T) {
+	timings := NewTimings()
+	for i := 1; i <= 20; i++ {
+		timings.Record("user", time.Duration(i)*time.Millisecond)
+	}
+	timings.Record("assistant", 3*time.Millisecond)
+
+	stats := timings.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("Stats() returned %d kinds, want 2", len(stats))
+	}
+
+	if got := stats[0]; got.Kind != "assistant" || got.Count != 1 || got.P50 != 3*time.Millisecond || got.P95 != 3*time.Millisecond {
+		t.Errorf("assistant stat = %+v", got)
+	}
+
+	want := Stat{
+		Kind:  "user",
+		Count: 20,
+		P50:   10 * time.Millisecond,
+		P95:   19 * time.Millisecond,
+		Total: 210 * time.Millisecond,
+	}
+	if stats[1] != want {
+		t.Errorf("user stat = %+v, want %+v", stats[1], want)
+	}
+}
+
+func TestTimings_StatsEmpty(t *testing.T) {
+	if stats := NewTimings().Stats(); len(stats) != 0 {
+		t.Errorf("Stats() = %v, want none", stats)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{412 * time.Microsecond, "412µs"},
+		{1204567 * time.Nanosecond, "1.205ms"},
+		{0, "0s"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var sb strings.Builder
+	err := WriteTable(&sb, []Stat{
+		{Kind: "assistant", Count: 12, P50: 400 * time.Microsecond, P95: 2 * time.Millisecond, Total: 9 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("WriteTable() wrote %d lines, want 2:\n%s", len(lines), sb.String())
+	}
+	for _, want := range []string{"event", "count", "p50", "p95", "total"} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("header %q missing %q", lines[0], want)
+		}
+	}
+	for _, want := range []string{"assistant", "12", "400µs", "2ms", "9ms"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q missing %q", lines[1], want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package record captures raw stream-json input to a .viewscreen session file
+// and plays it back with the original timing.
+//
+// A session file is JSONL: a header line followed by one entry per raw input
+// line, each stamped with its offset from the start of the recording. Lines
+// are stored verbatim as strings so malformed input replays exactly as it was
+// received.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+)
+
+// Extension is the conventional file extension for recorded sessions.
+const Extension = ".viewscreen"
+
+// FormatVersion is the session file format written by Recorder.
+const FormatVersion = 1
+
+// Header is the first line of a session file.
+type Header struct {
+	Version int       `json:"version"`
+	Start   time.Time `json:"start"`
+}
+
+// Entry is a single recorded input line.
+type Entry struct {
+	// Offset is the time elapsed between the start of the recording and
+	// the moment the line was read.
+	Offset time.Duration
+	Line   string
+}
+
+type entryJSON struct {
+	OffsetMS int64  `json:"t"`
+	Line     string `json:"line"`
+}
+
+// Recorder writes session entries to an underlying writer. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	start  time.Time
+	now    func() time.Time
+	err    error
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithClock sets the time source used to stamp entries (for testing).
+func WithClock(now func() time.Time) RecorderOption {
+	return func(r *Recorder) {
+		r.now = now
+	}
+}
+
+// NewRecorder creates a Recorder that writes a session to w. The header is
+// written immediately.
+func NewRecorder(w io.Writer, opts ...RecorderOption) *Recorder {
+	r := &Recorder{w: w, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.start = r.now()
+	r.writeJSON(Header{Version: FormatVersion, Start: r.start})
+	return r
+}
+
+// Create creates (or truncates) the session file at path and returns a
+// Recorder writing to it. Close the Recorder to flush and close the file.
+func Create(path string, opts ...RecorderOption) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating recording: %w", err)
+	}
+	bw := bufio.NewWriter(f)
+	r := NewRecorder(bw, opts...)
+	r.closer = flushCloser{bw: bw, f: f}
+	return r, nil
+}
+
+// Record appends a raw input line to the session.
+func (r *Recorder) Record(line string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	offset := r.now().Sub(r.start)
+	r.writeJSONLocked(entryJSON{OffsetMS: offset.Milliseconds(), Line: line})
+	return r.err
+}
+
+// Err returns the first write error encountered, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close flushes and closes the underlying file when the Recorder was created
+// with Create. It returns the first error encountered while recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closer != nil {
+		if err := r.closer.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.closer = nil
+	}
+	return r.err
+}
+
+func (r *Recorder) writeJSON(v any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeJSONLocked(v)
+}
+
+func (r *Recorder) writeJSONLocked(v any) {
+	if r.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return
+	}
+	data = append(data, '\n')
+	if _, err := r.w.Write(data); err != nil {
+		r.err = err
+	}
+}
+
+type flushCloser struct {
+	bw *bufio.Writer
+	f  *os.File
+}
+
+func (c flushCloser) Close() error {
+	flushErr := c.bw.Flush()
+	closeErr := c.f.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// LineRecorder receives raw input lines. *Recorder implements it.
+type LineRecorder interface {
+	Record(line string) error
+}
+
+// TeeReader returns a reader that passes r through unchanged while recording
+// every complete line it yields. A trailing line without a newline is
+// recorded when r reaches EOF.
+func TeeReader(r io.Reader, rec LineRecorder) io.Reader {
+	return &teeReader{r: r, rec: rec}
+}
+
+type teeReader struct {
+	r       io.Reader
+	rec     LineRecorder
+	pending []byte
+}
+
+func (t *teeReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.pending = append(t.pending, p[:n]...)
+		for {
+			i := bytes.IndexByte(t.pending, '\n')
+			if i < 0 {
+				break
+			}
+			t.record(t.pending[:i])
+			t.pending = t.pending[i+1:]
+		}
+	}
+	if err != nil && len(t.pending) > 0 {
+		t.record(t.pending)
+		t.pending = nil
+	}
+	return n, err
+}
+
+func (t *teeReader) record(line []byte) {
+	_ = t.rec.Record(string(bytes.TrimSuffix(line, []byte("\r"))))
+}
+
+// Reader decodes a session file.
+type Reader struct {
+	scanner *jsonl.Reader
+	header  *Header
+}
+
+// NewReader creates a Reader over a session file.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: jsonl.NewReader(r, nil)}
+}
+
+// ErrNotSession is returned when the input does not start with a session header.
+var ErrNotSession = errors.New("not a viewscreen session file")
+
+// Header returns the session header, reading it if necessary.
+func (r *Reader) Header() (Header, error) {
+	if r.header != nil {
+		return *r.header, nil
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return Header{}, err
+		}
+		return Header{}, ErrNotSession
+	}
+	var h Header
+	if err := json.Unmarshal(r.scanner.Bytes(), &h); err != nil || h.Version == 0 {
+		return Header{}, ErrNotSession
+	}
+	if h.Version > FormatVersion {
+		return Header{}, fmt.Errorf("unsupported session format version %d", h.Version)
+	}
+	r.header = &h
+	return h, nil
+}
+
+// Next returns the next recorded entry, or io.EOF when the session ends.
+func (r *Reader) Next() (Entry, error) {
+	if _, err := r.Header(); err != nil {
+		return Entry{}, err
+	}
+	for r.scanner.Scan() {
+		data := r.scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		var e entryJSON
+		if err := json.Unmarshal(data, &e); err != nil {
+			return Entry{}, fmt.Errorf("decoding session entry: %w", err)
+		}
+		return Entry{Offset: time.Duration(e.OffsetMS) * time.Millisecond, Line: e.Line}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Entry{}, err
+	}
+	return Entry{}, io.EOF
+}
+
+// SkipType is the event type of the marker a smoothed Player inserts where
+// it shortened an idle period.
+const SkipType = "viewscreen_skip"
+
+// MinMarkedSkip is the shortest skipped idle time a smoothed Player marks;
+// shorter trims pass silently.
+const MinMarkedSkip = 10 * time.Second
+
+// Skip is the marker event for an idle period a smoothed Player shortened.
+type Skip struct {
+	Type      string `json:"type"`
+	SkippedMS int64  `json:"skipped_ms"`
+}
+
+// Skipped returns the idle time the marker stands for.
+func (s Skip) Skipped() time.Duration {
+	return time.Duration(s.SkippedMS) * time.Millisecond
+}
+
+// Player is an io.Reader that yields the raw lines of a session, sleeping
+// between lines so they arrive with their recorded spacing divided by speed.
+type Player struct {
+	reader *Reader
+	speed  float64
+	maxGap time.Duration
+	sleep  func(time.Duration)
+	last   time.Duration
+	buf    []byte
+	err    error
+}
+
+// PlayerOption configures a Player.
+type PlayerOption func(*Player)
+
+// WithSleep sets the function used to wait between lines (for testing).
+func WithSleep(sleep func(time.Duration)) PlayerOption {
+	return func(p *Player) {
+		p.sleep = sleep
+	}
+}
+
+// WithMaxGap caps the recorded time waited between two lines at gap, so
+// playback does not stall where the session waited on a slow tool. Idle
+// periods cut by at least MinMarkedSkip are marked with a Skip event. A gap
+// <= 0 keeps the recorded spacing.
+func WithMaxGap(gap time.Duration) PlayerOption {
+	return func(p *Player) {
+		p.maxGap = gap
+	}
+}
+
+// NewPlayer creates a Player over a session file. A speed of 2 plays twice
+// as fast as recorded; a speed <= 0 disables pacing entirely.
+func NewPlayer(r io.Reader, speed float64, opts ...PlayerOption) *Player {
+	p := &Player{reader: NewReader(r), speed: speed, sleep: time.Sleep}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Read implements io.Reader.
+func (p *Player) Read(b []byte) (int, error) {
+	for len(p.buf) == 0 {
+		if p.err != nil {
+			return 0, p.err
+		}
+		entry, err := p.reader.Next()
+		if err != nil {
+			p.err = err
+			continue
+		}
+		if skipped := p.wait(entry.Offset); skipped >= MinMarkedSkip {
+			marker, _ := json.Marshal(Skip{Type: SkipType, SkippedMS: skipped.Milliseconds()})
+			p.buf = append(marker, '\n')
+		}
+		p.buf = append(p.buf, entry.Line...)
+		p.buf = append(p.buf, '\n')
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return n, nil
+}
+
+// wait sleeps until the line recorded at offset is due and returns the
+// recorded idle time the max gap cut from the wait.
+func (p *Player) wait(offset time.Duration) time.Duration {
+	delta := offset - p.last
+	p.last = offset
+	if p.speed <= 0 || delta <= 0 {
+		return 0
+	}
+	var skipped time.Duration
+	if p.maxGap > 0 && delta > p.maxGap {
+		skipped = delta - p.maxGap
+		delta = p.maxGap
+	}
+	p.sleep(time.Duration(float64(delta) / p.speed))
+	return skipped
+}
+
+// TeeToFile wraps r so its lines are recorded to a new session file at path.
+// When path is empty, r is returned unchanged. The returned Closer finishes
+// the recording and must be closed once input is exhausted.
+func TeeToFile(path string, r io.Reader) (io.Reader, io.Closer, error) {
+	if path == "" {
+		return r, nopCloser{}, nil
+	}
+	rec, err := Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return TeeReader(r, rec), rec, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
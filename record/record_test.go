@@ -0,0 +1,206 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stepClock returns a clock that advances by step on every call.
+func stepClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func() time.Time {
+		t := now
+		now = now.Add(step)
+		return t
+	}
+}
+
+func TestRecorder_WritesHeaderAndEntries(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, WithClock(stepClock(250*time.Millisecond)))
+
+	if err := rec.Record(`{"type":"system"}`); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := rec.Record("not json"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	r := NewReader(&buf)
+	h, err := r.Header()
+	if err != nil {
+		t.Fatalf("Header: %v", err)
+	}
+	if h.Version != FormatVersion {
+		t.Errorf("Version = %d, want %d", h.Version, FormatVersion)
+	}
+
+	first, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if first.Line != `{"type":"system"}` || first.Offset != 250*time.Millisecond {
+		t.Errorf("first entry = %+v", first)
+	}
+	second, err := r.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if second.Line != "not json" || second.Offset != 500*time.Millisecond {
+		t.Errorf("second entry = %+v", second)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReader_RejectsNonSession(t *testing.T) {
+	r := NewReader(strings.NewReader(`{"type":"system"}` + "\n"))
+	if _, err := r.Next(); !errors.Is(err, ErrNotSession) {
+		t.Errorf("expected ErrNotSession, got %v", err)
+	}
+}
+
+func TestTeeReader_RecordsLinesAndPassesThrough(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf)
+	input := "one\r\ntwo\nthree"
+
+	out, err := io.ReadAll(TeeReader(strings.NewReader(input), rec))
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(out) != input {
+		t.Errorf("passthrough = %q, want %q", out, input)
+	}
+
+	r := NewReader(&buf)
+	var lines []string
+	for {
+		e, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		lines = append(lines, e.Line)
+	}
+	if strings.Join(lines, ",") != "one,two,three" {
+		t.Errorf("recorded lines = %q", lines)
+	}
+}
+
+func TestPlayer_PacesLinesBySpeed(t *testing.T) {
+	var session bytes.Buffer
+	rec := NewRecorder(&session, WithClock(stepClock(time.Second)))
+	rec.Record("a")
+	rec.Record("b")
+	rec.Record("c")
+
+	var sleeps []time.Duration
+	p := NewPlayer(&session, 2, WithSleep(func(d time.Duration) { sleeps = append(sleeps, d) }))
+	out, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(out) != "a\nb\nc\n" {
+		t.Errorf("output = %q", out)
+	}
+	want := []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}
+	if len(sleeps) != len(want) {
+		t.Fatalf("sleeps = %v, want %v", sleeps, want)
+	}
+	for i := range want {
+		if sleeps[i] != want[i] {
+			t.Errorf("sleep[%d] = %v, want %v", i, sleeps[i], want[i])
+		}
+	}
+}
+
+func TestPlayer_MaxGapSkipsIdleTime(t *testing.T) {
+	// The recorder reads the clock once for its start, then once per line.
+	offsets := []time.Duration{0, 0, time.Second, 4*time.Minute + time.Second, 4*time.Minute + 6*time.Second}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var session bytes.Buffer
+	rec := NewRecorder(&session, WithClock(func() time.Time {
+		offset := offsets[0]
+		offsets = offsets[1:]
+		return start.Add(offset)
+	}))
+	for _, line := range []string{"a", "b", "c", "d"} {
+		rec.Record(line)
+	}
+
+	var sleeps []time.Duration
+	p := NewPlayer(&session, 2, WithMaxGap(2*time.Second), WithSleep(func(d time.Duration) { sleeps = append(sleeps, d) }))
+	out, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	want := []string{"a", "b", `{"type":"viewscreen_skip","skipped_ms":238000}`, "c", "d"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	// b waits its 1s, c and d are capped at 2s; all halved by the speed.
+	wantSleeps := []time.Duration{500 * time.Millisecond, time.Second, time.Second}
+	if !slices.Equal(sleeps, wantSleeps) {
+		t.Errorf("sleeps = %v, want %v", sleeps, wantSleeps)
+	}
+}
+
+func TestPlayer_ZeroSpeedDisablesPacing(t *testing.T) {
+	var session bytes.Buffer
+	rec := NewRecorder(&session, WithClock(stepClock(time.Second)))
+	rec.Record("a")
+
+	p := NewPlayer(&session, 0, WithSleep(func(time.Duration) { t.Error("unexpected sleep") }))
+	if _, err := io.ReadAll(p); err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+}
+
+func TestTeeToFile(t *testing.T) {
+	t.Run("empty path passes through", func(t *testing.T) {
+		in := strings.NewReader("x")
+		r, c, err := TeeToFile("", in)
+		if err != nil {
+			t.Fatalf("TeeToFile: %v", err)
+		}
+		if r != in {
+			t.Error("expected reader to be returned unchanged")
+		}
+		if err := c.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+
+	t.Run("records to file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "s"+Extension)
+		r, c, err := TeeToFile(path, strings.NewReader("line\n"))
+		if err != nil {
+			t.Fatalf("TeeToFile: %v", err)
+		}
+		if _, err := io.ReadAll(r); err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if err := c.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !strings.Contains(string(data), `"line":"line"`) {
+			t.Errorf("session file missing entry: %s", data)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+// Package redact masks credentials in a session before it is rendered or
+// exported, for the -redact flag, so transcripts can be shared without
+// leaking secrets an agent read from its environment.
+//
+// Built-in patterns cover AWS access keys and secret keys, GitHub tokens,
+// JWTs and the credentials of Authorization headers. Extra patterns are
+// regular expressions; a pattern with a capture group masks only the first
+// group's text, so the label around a secret stays readable.
+//
+// Separately, EnvAssignments masks the values of sensitive KEY=value
+// assignments in the shell commands viewscreen displays, which is on unless
+// -no-redact-env is given.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces each redacted secret.
+const Mask = "[REDACTED]"
+
+// builtinPatterns match common secrets.
+var builtinPatterns = []*regexp.Regexp{
+	// AWS access key IDs
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// AWS secret access keys, which have no prefix of their own
+	regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`),
+	// GitHub personal access, OAuth, user-to-server, server-to-server and
+	// refresh tokens, and fine-grained personal access tokens
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,255}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,255}\b`),
+	// JWTs: base64url header, payload and signature
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	// Authorization header credentials, keeping the scheme
+	regexp.MustCompile(`(?i)\bauthorization["']?\s*[:=]\s*["']?(?:(?:bearer|basic|token|digest)\s+)?([^\s"'\\]+)`),
+}
+
+// Redactor masks secrets. A nil Redactor masks nothing.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New returns a Redactor for the built-in patterns and the regular
+// expressions in extra.
+func New(extra []string) (*Redactor, error) {
+	r := &Redactor{patterns: append([]*regexp.Regexp(nil), builtinPatterns...)}
+	for _, expr := range extra {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// String returns s with every secret replaced by Mask.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = mask(re, s)
+	}
+	return s
+}
+
+// mask replaces the matches of re in s, or only their first group when re
+// has one.
+func mask(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(Mask)
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// DefaultEnvKeys are the words that mark a variable as sensitive in
+// EnvAssignments.
+var DefaultEnvKeys = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "API_KEY", "APIKEY", "PRIVATE_KEY", "CREDENTIAL"}
+
+// envAssignment matches a KEY=value assignment at the start of a shell word,
+// optionally exported. The value is quoted or runs to the next separator.
+var envAssignment = regexp.MustCompile(`(?:^|[\s;&|(])(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|[^\s;&|)]*)`)
+
+// EnvAssignments masks the values of KEY=value assignments in a shell
+// command whose KEY contains one of keys, ignoring case, as in
+// "GITHUB_TOKEN=ghp_x gh pr list". Values that only reference another
+// variable ("$TOKEN") are kept, since they reveal nothing.
+func EnvAssignments(cmd string, keys []string) string {
+	if len(keys) == 0 || !strings.Contains(cmd, "=") {
+		return cmd
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range envAssignment.FindAllStringSubmatchIndex(cmd, -1) {
+		name, value := cmd[m[2]:m[3]], cmd[m[4]:m[5]]
+		if value == "" || strings.HasPrefix(strings.Trim(value, `"`), "$") || !sensitiveKey(name, keys) {
+			continue
+		}
+		sb.WriteString(cmd[last:m[4]])
+		sb.WriteString(Mask)
+		last = m[5]
+	}
+	if last == 0 {
+		return cmd
+	}
+	sb.WriteString(cmd[last:])
+	return sb.String()
+}
+
+// sensitiveKey reports whether name contains one of keys, ignoring case.
+func sensitiveKey(name string, keys []string) bool {
+	name = strings.ToUpper(name)
+	for _, key := range keys {
+		if key != "" && strings.Contains(name, strings.ToUpper(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Line masks the secrets in the string values of a JSON input line, so the
+// line stays valid JSON. Lines that are not JSON are masked as plain text.
+func (r *Redactor) Line(line string) string {
+	if r == nil {
+		return line
+	}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return r.String(line)
+	}
+	v, changed := r.value(v)
+	if !changed {
+		return line
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return r.String(line)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// value masks the strings in a decoded JSON value, reporting whether any
+// changed.
+func (r *Redactor) value(v any) (any, bool) {
+	switch v := v.(type) {
+	case string:
+		masked := r.String(v)
+		return masked, masked != v
+	case map[string]any:
+		changed := false
+		for k, elem := range v {
+			if masked, ok := r.value(elem); ok {
+				v[k] = masked
+				changed = true
+			}
+		}
+		return v, changed
+	case []any:
+		changed := false
+		for i, elem := range v {
+			if masked, ok := r.value(elem); ok {
+				v[i] = masked
+				changed = true
+			}
+		}
+		return v, changed
+	}
+	return v, false
+}
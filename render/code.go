@@ -11,6 +11,7 @@ import (
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/colorprofile"
 	uv "github.com/charmbracelet/ultraviolet"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/lucasb-eyer/go-colorful"
@@ -24,18 +25,27 @@ const maxCodeSize = 1024 * 1024
 // Uses Ultraviolet for proper style/content separation - this ensures that
 // syntax-highlighted code with backgrounds can be safely composed with other
 // styles without escape sequence conflicts.
+//
+// When spans are set, the bytes they cover use emphBg instead, so changed
+// words stand out within a diff line.
 type bgFormatter struct {
 	bgColor style.Color
+	emphBg  style.Color
+	spans   []Span
 }
 
-func (f bgFormatter) Format(w io.Writer, style *chroma.Style, it chroma.Iterator) error {
-	// Convert background color once for all tokens
-	bg := hexToRGBA(string(f.bgColor))
+func (f bgFormatter) Format(w io.Writer, chromaStyle *chroma.Style, it chroma.Iterator) error {
+	// Convert background colors once for all tokens
+	bg := style.ProfileColor(hexToRGBA(string(f.bgColor)))
+	emph := style.ProfileColor(hexToRGBA(string(f.emphBg)))
+	offset := 0
 
 	for token := it(); token != chroma.EOF; token = it() {
 		value := strings.TrimRight(token.Value, "\n")
+		start := offset
+		offset += len(token.Value)
 
-		entry := style.Get(token.Type)
+		entry := chromaStyle.Get(token.Type)
 
 		// Build Ultraviolet style with background always set
 		uvStyle := &uv.Style{
@@ -60,17 +70,55 @@ func (f bgFormatter) Format(w io.Writer, style *chroma.Style, it chroma.Iterator
 
 			// Set foreground color if specified
 			if entry.Colour.IsSet() {
-				uvStyle.Fg = hexToRGBA(entry.Colour.String())
+				uvStyle.Fg = style.ProfileColor(hexToRGBA(entry.Colour.String()))
 			}
 		}
 
-		if _, err := fmt.Fprint(w, uvStyle.Styled(value)); err != nil {
-			return err
+		for _, seg := range splitBySpans(value, start, f.spans) {
+			segStyle := *uvStyle
+			if seg.emphasized {
+				segStyle.Bg = emph
+			}
+			if _, err := fmt.Fprint(w, segStyle.Styled(seg.text)); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
+type spanSegment struct {
+	text       string
+	emphasized bool
+}
+
+// splitBySpans splits value, which starts at byte offset start in the line,
+// into runs inside and outside spans.
+func splitBySpans(value string, start int, spans []Span) []spanSegment {
+	if len(spans) == 0 || value == "" {
+		return []spanSegment{{text: value}}
+	}
+	var segs []spanSegment
+	end := start + len(value)
+	pos := start
+	for _, sp := range spans {
+		if sp.End <= pos || sp.Start >= end {
+			continue
+		}
+		if sp.Start > pos {
+			segs = append(segs, spanSegment{text: value[pos-start : sp.Start-start]})
+			pos = sp.Start
+		}
+		stop := min(sp.End, end)
+		segs = append(segs, spanSegment{text: value[pos-start : stop-start], emphasized: true})
+		pos = stop
+	}
+	if pos < end {
+		segs = append(segs, spanSegment{text: value[pos-start:]})
+	}
+	return segs
+}
+
 // hexToRGBA converts a hex color string to color.RGBA.
 func hexToRGBA(hex string) color.RGBA {
 	c, err := colorful.Hex(hex)
@@ -85,6 +133,15 @@ func hexToRGBA(hex string) color.RGBA {
 	}
 }
 
+// LineStyle is the background HighlightFileLines applies to one line. A zero
+// Bg leaves the line on the terminal background; the byte ranges in Spans
+// use EmphBg instead of Bg.
+type LineStyle struct {
+	Bg     style.Color
+	EmphBg style.Color
+	Spans  []Span
+}
+
 // CodeHighlighter abstracts code highlighting for testability.
 // *CodeRenderer satisfies this interface.
 type CodeHighlighter interface {
@@ -92,6 +149,8 @@ type CodeHighlighter interface {
 	HighlightFile(code, filename string) string
 	HighlightWithBg(code, language string, bgColor style.Color) string
 	HighlightFileWithBg(code, filename string, bgColor style.Color) string
+	HighlightFileWithEmphasis(code, filename string, bgColor, emphBg style.Color, spans []Span) string
+	HighlightFileLines(lines []string, filename string, styles []LineStyle) []string
 }
 
 // CodeRenderer handles syntax highlighting with chroma
@@ -101,6 +160,30 @@ type CodeRenderer struct {
 	noColor   bool
 }
 
+// highlightStyle names the chroma style code is highlighted with: monokai,
+// or its light variant when style.Init picked the light theme.
+func highlightStyle() string {
+	if style.LightBackground() {
+		return "monokailight"
+	}
+	return "monokai"
+}
+
+// codeFormatter returns the chroma formatter for the colors the terminal
+// supports: TrueColor (24-bit) for richer syntax highlighting where it can.
+func codeFormatter() chroma.Formatter {
+	switch style.Profile() {
+	case colorprofile.ANSI256:
+		return formatters.TTY256
+	case colorprofile.ANSI:
+		return formatters.TTY16
+	}
+	if f := formatters.Get("terminal16m"); f != nil {
+		return f
+	}
+	return formatters.TTY256
+}
+
 // NewCodeRenderer creates a new code renderer
 func NewCodeRenderer(noColor bool) *CodeRenderer {
 	var formatter chroma.Formatter
@@ -110,12 +193,8 @@ func NewCodeRenderer(noColor bool) *CodeRenderer {
 		formatter = formatters.NoOp
 		style = styles.Fallback
 	} else {
-		// Use TrueColor (24-bit) formatter for richer syntax highlighting
-		formatter = formatters.Get("terminal16m")
-		if formatter == nil {
-			formatter = formatters.TTY256
-		}
-		style = styles.Get("monokai")
+		formatter = codeFormatter()
+		style = styles.Get(highlightStyle())
 		if style == nil {
 			style = styles.Fallback
 		}
@@ -220,3 +299,113 @@ func (c *CodeRenderer) HighlightFileWithBg(code, filename string, bgColor style.
 
 	return c.formatWith(code, lexer, bgFormatter{bgColor: bgColor})
 }
+
+// HighlightFileWithEmphasis highlights a diff line like HighlightFileWithBg,
+// but uses emphBg behind the byte ranges in spans to mark changed words.
+// Files without a matching lexer are still painted with both backgrounds.
+func (c *CodeRenderer) HighlightFileWithEmphasis(code, filename string, bgColor, emphBg style.Color, spans []Span) string {
+	if c.shouldSkip(code) {
+		return code
+	}
+
+	lexer := lexers.Match(filename)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	return c.formatWith(code, lexer, bgFormatter{bgColor: bgColor, emphBg: emphBg, spans: spans})
+}
+
+// HighlightFileLines highlights lines of one file as a single block, so the
+// lexer runs once per hunk instead of once per line and constructs spanning
+// lines (block comments, raw strings) are colored correctly. Each line's
+// background from styles (indexed like lines) is applied afterwards. An
+// empty filename skips syntax coloring and applies backgrounds only.
+func (c *CodeRenderer) HighlightFileLines(lines []string, filename string, styles []LineStyle) []string {
+	out := append([]string(nil), lines...)
+	code := strings.Join(lines, "\n")
+	if c.shouldSkip(code) {
+		return out
+	}
+
+	lexer := lexers.Fallback
+	if filename != "" {
+		if lexer = lexers.Match(filename); lexer == nil {
+			lexer = lexers.Analyse(code)
+		}
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return out
+	}
+
+	for i, tokens := range splitTokenLines(iterator, len(lines)) {
+		var ls LineStyle
+		if i < len(styles) {
+			ls = styles[i]
+		}
+		formatter := c.formatter
+		if ls.Bg != "" {
+			formatter = bgFormatter{bgColor: ls.Bg, emphBg: ls.EmphBg, spans: ls.Spans}
+		} else if filename == "" {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := formatter.Format(&buf, c.style, chroma.Literator(tokens...)); err == nil {
+			out[i] = buf.String()
+		}
+	}
+	return out
+}
+
+// HighlightHunk highlights the content (after the +/- marker) of unified diff
+// lines, indexed like lines. The old side (context and removed lines) and the
+// new side (context and added lines) are each highlighted as one block, with
+// styleOf giving the background of the line at each index.
+func HighlightHunk(h CodeHighlighter, lines []string, filename string, styleOf func(i int) LineStyle) []string {
+	styled := make([]string, len(lines))
+	for _, skip := range []byte{'+', '-'} {
+		var idx []int
+		var content []string
+		var styles []LineStyle
+		for i, line := range lines {
+			if len(line) == 0 || line[0] == skip {
+				continue
+			}
+			idx = append(idx, i)
+			content = append(content, line[1:])
+			styles = append(styles, styleOf(i))
+		}
+		for k, s := range h.HighlightFileLines(content, filename, styles) {
+			styled[idx[k]] = s
+		}
+	}
+	return styled
+}
+
+// splitTokenLines splits a token stream into the tokens of each of n lines,
+// breaking tokens that span lines at their newlines.
+func splitTokenLines(it chroma.Iterator, n int) [][]chroma.Token {
+	lines := make([][]chroma.Token, n)
+	line := 0
+	for token := it(); token != chroma.EOF && line < n; token = it() {
+		for i, part := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				line++
+			}
+			if line >= n {
+				break
+			}
+			if part != "" {
+				lines[line] = append(lines[line], chroma.Token{Type: token.Type, Value: part})
+			}
+		}
+	}
+	return lines
+}
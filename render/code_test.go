@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/testutil"
 )
 
 func TestCodeRenderer_NewCodeRenderer(t *testing.T) {
@@ -38,6 +39,19 @@ func TestCodeRenderer_NewCodeRenderer(t *testing.T) {
 	})
 }
 
+func TestCodeRenderer_NewCodeRenderer_LightBackground(t *testing.T) {
+	style.SetBackground(style.BackgroundLight)
+	style.Init(false)
+	defer func() {
+		style.SetBackground(style.BackgroundDark)
+		style.Init(false)
+	}()
+
+	if cr := NewCodeRenderer(false); cr.style.Name != "monokailight" {
+		t.Errorf("style = %q, want monokailight on a light background", cr.style.Name)
+	}
+}
+
 func TestCodeRenderer_Highlight(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -322,3 +336,100 @@ func TestBgFormatter(t *testing.T) {
 		}
 	})
 }
+
+func TestSplitBySpans(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		start int
+		spans []Span
+		want  []spanSegment
+	}{
+		{"no spans", "abc", 0, nil, []spanSegment{{text: "abc"}}},
+		{"inside token", "return count", 0, []Span{{7, 12}}, []spanSegment{{text: "return "}, {text: "count", emphasized: true}}},
+		{"offset token", "count", 7, []Span{{7, 12}}, []spanSegment{{text: "count", emphasized: true}}},
+		{"span before token", "x", 5, []Span{{0, 2}}, []spanSegment{{text: "x"}}},
+		{"span crosses token end", "ab", 0, []Span{{1, 5}}, []spanSegment{{text: "a"}, {text: "b", emphasized: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitBySpans(tt.value, tt.start, tt.spans)
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitBySpans = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("segment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCodeRenderer_HighlightFileWithEmphasis(t *testing.T) {
+	t.Run("no color returns code unchanged", func(t *testing.T) {
+		cr := NewCodeRenderer(true)
+		code := "return total"
+		if got := cr.HighlightFileWithEmphasis(code, "f.go", "#00ff00", "#00aa00", []Span{{7, 12}}); got != code {
+			t.Errorf("got %q, want %q", got, code)
+		}
+	})
+
+	t.Run("preserves text with color", func(t *testing.T) {
+		cr := NewCodeRenderer(false)
+		code := "return total"
+		got := cr.HighlightFileWithEmphasis(code, "f.unknownext", "#00ff00", "#00aa00", []Span{{7, 12}})
+		if stripped := testutil.StripANSI(got); stripped != code {
+			t.Errorf("stripped output = %q, want %q", stripped, code)
+		}
+		if got == code {
+			t.Error("expected backgrounds to be applied even without a lexer match")
+		}
+	})
+}
+
+func TestCodeRenderer_HighlightFileLines(t *testing.T) {
+	lines := []string{"/* start", "still comment */", "x := 1"}
+	styles := []LineStyle{{}, {Bg: "#ff0000"}, {Bg: "#00ff00", EmphBg: "#00aa00", Spans: []Span{{0, 1}}}}
+
+	t.Run("no color returns lines unchanged", func(t *testing.T) {
+		cr := NewCodeRenderer(true)
+		got := cr.HighlightFileLines(lines, "f.go", styles)
+		if strings.Join(got, "\n") != strings.Join(lines, "\n") {
+			t.Errorf("got %q, want %q", got, lines)
+		}
+	})
+
+	t.Run("preserves text line by line", func(t *testing.T) {
+		cr := NewCodeRenderer(false)
+		got := cr.HighlightFileLines(lines, "f.go", styles)
+		if len(got) != len(lines) {
+			t.Fatalf("got %d lines, want %d", len(got), len(lines))
+		}
+		for i := range lines {
+			if stripped := testutil.StripANSI(got[i]); stripped != lines[i] {
+				t.Errorf("line %d stripped = %q, want %q", i, stripped, lines[i])
+			}
+		}
+	})
+
+	t.Run("lexes lines as one block", func(t *testing.T) {
+		cr := NewCodeRenderer(false)
+		got := cr.HighlightFileLines(lines, "f.go", styles)
+		alone := cr.HighlightFileWithBg(lines[1], "f.go", "#ff0000")
+		if got[1] == alone {
+			t.Error("expected the comment continuation to be highlighted as a comment")
+		}
+	})
+
+	t.Run("empty filename applies backgrounds only", func(t *testing.T) {
+		cr := NewCodeRenderer(false)
+		got := cr.HighlightFileLines(lines, "", styles)
+		if got[0] != lines[0] {
+			t.Errorf("expected line without background unchanged, got %q", got[0])
+		}
+		if got[1] == lines[1] || testutil.StripANSI(got[1]) != lines[1] {
+			t.Errorf("expected background on line 1, got %q", got[1])
+		}
+	})
+}
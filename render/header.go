@@ -0,0 +1,34 @@
+package render
+
+import "github.com/johnnyfreeman/viewscreen/terminal"
+
+const (
+	// headerGutter is the columns a header line leaves free at its end, for
+	// the lane rule that may precede it and terminals that wrap at the last
+	// column.
+	headerGutter = 2
+	// minHeaderArgWidth is the fewest columns a header argument is cut to,
+	// however narrow the terminal.
+	minHeaderArgWidth = 20
+)
+
+// headerWidth is the width SetHeaderWidth set, or 0 to follow the terminal.
+var headerWidth int
+
+// SetHeaderWidth sets the width tool header lines fit their argument into,
+// such as the TUI's content width; 0 follows terminal.Width, so -no-tui
+// output picks up a resize from the next header.
+func SetHeaderWidth(width int) {
+	headerWidth = max(width, 0)
+}
+
+// HeaderArgWidth returns the columns left for the argument of a tool header
+// line whose prefix, icon and name take used columns, so wide terminals show
+// whole commands and narrow ones cut them early.
+func HeaderArgWidth(used int) int {
+	width := headerWidth
+	if width <= 0 {
+		width = terminal.Width()
+	}
+	return max(width-used-headerGutter, minHeaderArgWidth)
+}
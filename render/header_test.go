@@ -0,0 +1,20 @@
+package render
+
+import "testing"
+
+func TestHeaderArgWidth(t *testing.T) {
+	defer SetHeaderWidth(0)
+	tests := []struct {
+		width, used, want int
+	}{
+		{200, 10, 188},
+		{80, 10, 68},
+		{30, 10, minHeaderArgWidth},
+	}
+	for _, tt := range tests {
+		SetHeaderWidth(tt.width)
+		if got := HeaderArgWidth(tt.used); got != tt.want {
+			t.Errorf("HeaderArgWidth(%d) at width %d = %d, want %d", tt.used, tt.width, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/johnnyfreeman/viewscreen/style"
 )
 
 // MarkdownRenderer wraps glamour for markdown rendering with multiple styles
@@ -30,9 +32,15 @@ func NewMarkdownRenderer(noColor bool, width int) *MarkdownRenderer {
 		return mr
 	}
 
-	// Full styled renderer
+	// Full styled renderer. Glamour detects the background on its own, but
+	// only on a terminal; a light background chosen by style.Init is passed
+	// on so piped and -background light output match the theme.
+	styleOption := glamour.WithAutoStyle()
+	if style.LightBackground() {
+		styleOption = glamour.WithStandardStyle(styles.LightStyle)
+	}
 	full, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		styleOption,
 		glamour.WithWordWrap(width),
 	)
 	if err == nil {
@@ -97,9 +105,15 @@ func (m *MarkdownRenderer) SetWidth(width int) {
 		return
 	}
 
-	// Full styled renderer
+	// Full styled renderer. Glamour detects the background on its own, but
+	// only on a terminal; a light background chosen by style.Init is passed
+	// on so piped and -background light output match the theme.
+	styleOption := glamour.WithAutoStyle()
+	if style.LightBackground() {
+		styleOption = glamour.WithStandardStyle(styles.LightStyle)
+	}
 	full, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		styleOption,
 		glamour.WithWordWrap(width),
 	)
 	if err == nil {
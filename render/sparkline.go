@@ -0,0 +1,25 @@
+package render
+
+import "strings"
+
+// sparkBars are the bar heights of a sparkline, lowest first.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a row of bars scaled to the largest value, one
+// bar per value. Zero and negative values draw the lowest bar.
+func Sparkline(values []float64) string {
+	var highest float64
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+	var b strings.Builder
+	top := float64(len(sparkBars) - 1)
+	for _, v := range values {
+		level := 0
+		if highest > 0 && v > 0 {
+			level = int(v/highest*top + 0.5)
+		}
+		b.WriteRune(sparkBars[level])
+	}
+	return b.String()
+}
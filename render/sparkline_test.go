@@ -0,0 +1,23 @@
+package render
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"scaled to the highest", []float64{0, 1, 3.5, 7}, "▁▂▅█"},
+		{"all zero", []float64{0, 0}, "▁▁"},
+		{"negative", []float64{-1, 2}, "▁█"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// StatusColumnWidth is the width of the status column: an icon and a space.
+const StatusColumnWidth = 2
+
+// Status column icons by block kind.
+const (
+	StatusIconAssistant = "✦"
+	StatusIconTool      = "▸"
+	StatusIconError     = "✗"
+	StatusIconDiff      = "±"
+)
+
+// diffTools are the tools whose results are shown as diffs.
+var diffTools = map[string]bool{
+	"Edit":         true,
+	"MultiEdit":    true,
+	"Write":        true,
+	"NotebookEdit": true,
+	"file_change":  true, // Codex
+}
+
+// codexToolItems are the Codex item types that show a tool call.
+var codexToolItems = map[string]bool{
+	"command_execution": true,
+	"mcp_tool_call":     true,
+	"web_search":        true,
+	"todo_list":         true,
+}
+
+// StatusIcon returns the styled status column icon for a timeline entry, or
+// "" for entries with no marked kind (system messages, results, prompts).
+func StatusIcon(entry timeline.Entry) string {
+	switch {
+	case IsErrorEntry(entry):
+		return style.ErrorText(StatusIconError)
+	case diffTools[entry.Title]:
+		return style.WarningText(StatusIconDiff)
+	case entry.Kind == "user" && entry.Title != "", codexToolItems[entry.Title]:
+		return style.MutedText(StatusIconTool)
+	case entry.Kind == "assistant", entry.Kind == "stream", entry.Title == "agent_message":
+		return style.AccentText(StatusIconAssistant)
+	}
+	return ""
+}
+
+// IsErrorEntry reports whether a timeline entry shows an error: a failed
+// tool call, turn or session, or an error message from viewscreen itself.
+func IsErrorEntry(entry timeline.Entry) bool {
+	return entry.Status == timeline.StatusError || entry.Kind == "error" || entry.Kind == "parse_error"
+}
+
+// StatusColumn prefixes rendered timeline entries with a narrow column
+// marking each block's kind. Entries must be prefixed in order: an entry can
+// begin in the middle of the previous one's last line, as streamed text
+// does, and a streamed block is marked once rather than at every delta.
+type StatusColumn struct {
+	// ErrorsOnly leaves every block but errors unmarked, making the column
+	// an error gutter.
+	ErrorsOnly bool
+
+	midLine    bool
+	prevStream bool
+}
+
+// Prefix returns text, the rendering of entry, with the status column added
+// to each line it starts.
+func (c *StatusColumn) Prefix(entry timeline.Entry, text string) string {
+	icon := StatusIcon(entry)
+	if c.ErrorsOnly && !IsErrorEntry(entry) {
+		icon = ""
+	}
+	if entry.Kind == "stream" && c.prevStream {
+		icon = ""
+	}
+	c.prevStream = entry.Kind == "stream"
+	blank := strings.Repeat(" ", StatusColumnWidth)
+
+	var sb strings.Builder
+	sb.Grow(len(text) + StatusColumnWidth*(strings.Count(text, "\n")+1))
+	for len(text) > 0 {
+		if !c.midLine {
+			if icon != "" {
+				sb.WriteString(icon + " ")
+				icon = ""
+			} else {
+				sb.WriteString(blank)
+			}
+		}
+		line, rest, found := strings.Cut(text, "\n")
+		sb.WriteString(line)
+		if found {
+			sb.WriteByte('\n')
+		}
+		c.midLine = !found
+		text = rest
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func TestStatusIcon(t *testing.T) {
+	style.Init(true)
+	tests := []struct {
+		name  string
+		entry timeline.Entry
+		want  string
+	}{
+		{"assistant", timeline.Entry{Kind: "assistant"}, StatusIconAssistant},
+		{"streamed text", timeline.Entry{Kind: "stream"}, StatusIconAssistant},
+		{"tool result", timeline.Entry{Kind: "user", Title: "Bash"}, StatusIconTool},
+		{"edit", timeline.Entry{Kind: "user", Title: "Edit"}, StatusIconDiff},
+		{"failed edit", timeline.Entry{Kind: "user", Title: "Edit", Status: timeline.StatusError}, StatusIconError},
+		{"input error", timeline.Entry{Kind: "error"}, StatusIconError},
+		{"codex command", timeline.Entry{Kind: "codex", Title: "command_execution"}, StatusIconTool},
+		{"codex file change", timeline.Entry{Kind: "codex", Title: "file_change"}, StatusIconDiff},
+		{"codex message", timeline.Entry{Kind: "codex", Title: "agent_message"}, StatusIconAssistant},
+		{"prompt", timeline.Entry{Kind: "user"}, ""},
+		{"result", timeline.Entry{Kind: "result"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusIcon(tt.entry); got != tt.want {
+				t.Errorf("StatusIcon() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusColumnPrefix(t *testing.T) {
+	style.Init(true)
+	var col StatusColumn
+	var got string
+	for _, e := range []struct {
+		entry timeline.Entry
+		text  string
+	}{
+		{timeline.Entry{Kind: "assistant"}, "hello\nworld\n"},
+		{timeline.Entry{Kind: "user", Title: "Bash"}, "● Bash ls\n"},
+		{timeline.Entry{Kind: "stream"}, "stre"},
+		{timeline.Entry{Kind: "stream"}, "amed\nmore\n"},
+		{timeline.Entry{Kind: "result"}, "done\n"},
+	} {
+		got += col.Prefix(e.entry, e.text)
+	}
+
+	want := "✦ hello\n  world\n▸ ● Bash ls\n✦ streamed\n  more\n  done\n"
+	if got != want {
+		t.Errorf("prefixed =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestStatusColumnPrefix_ErrorsOnly(t *testing.T) {
+	style.Init(true)
+	col := StatusColumn{ErrorsOnly: true}
+	got := col.Prefix(timeline.Entry{Kind: "assistant"}, "hello\n") +
+		col.Prefix(timeline.Entry{Kind: "user", Title: "Bash", Status: timeline.StatusError}, "● Bash false\n")
+
+	want := "  hello\n✗ ● Bash false\n"
+	if got != want {
+		t.Errorf("prefixed =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -19,6 +19,8 @@ type StyleApplier interface {
 	ErrorText(text string) string
 	ErrorBoldText(text string) string
 	SuccessBoldText(text string) string
+	MatchText(text string) string
+	Hyperlink(text, url string) string
 
 	// Output prefixes
 	OutputPrefix() string
@@ -29,6 +31,8 @@ type StyleApplier interface {
 	LineNumberSepRender(text string) string
 	DiffAddBg() style.Color
 	DiffRemoveBg() style.Color
+	DiffAddEmphBg() style.Color
+	DiffRemoveEmphBg() style.Color
 
 	// Session/header styles
 	SessionHeaderRender(text string) string
@@ -51,6 +55,8 @@ func (d DefaultStyleApplier) MutedText(text string) string       { return style.
 func (d DefaultStyleApplier) ErrorText(text string) string       { return style.ErrorText(text) }
 func (d DefaultStyleApplier) ErrorBoldText(text string) string   { return style.ErrorBoldText(text) }
 func (d DefaultStyleApplier) SuccessBoldText(text string) string { return style.SuccessBoldText(text) }
+func (d DefaultStyleApplier) MatchText(text string) string       { return style.MatchText(text) }
+func (d DefaultStyleApplier) Hyperlink(text, url string) string  { return style.Hyperlink(text, url) }
 
 // Output prefixes
 func (d DefaultStyleApplier) OutputPrefix() string   { return style.OutputPrefix }
@@ -61,6 +67,8 @@ func (d DefaultStyleApplier) LineNumberRender(text string) string    { return st
 func (d DefaultStyleApplier) LineNumberSepRender(text string) string { return style.LineNumberSepText("│") }
 func (d DefaultStyleApplier) DiffAddBg() style.Color                 { return style.DiffAddBg }
 func (d DefaultStyleApplier) DiffRemoveBg() style.Color              { return style.DiffRemoveBg }
+func (d DefaultStyleApplier) DiffAddEmphBg() style.Color             { return style.DiffAddEmphBg }
+func (d DefaultStyleApplier) DiffRemoveEmphBg() style.Color          { return style.DiffRemoveEmphBg }
 
 // Session/header styles
 func (d DefaultStyleApplier) SessionHeaderRender(text string) string { return style.InfoBoldText(text) }
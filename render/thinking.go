@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+// ThinkingSymbol marks extended thinking blocks.
+const ThinkingSymbol = "✻"
+
+// thinkingTokens estimates the token count of thought text. Claude does not
+// report usage per content block, so this uses the usual ~4 bytes per token.
+func thinkingTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// WriteThinking writes a thinking or redacted_thinking block as a collapsed
+// "✻ Thinking… (n tokens)" line. In verbose mode the thought text follows
+// in muted italics; redacted thinking is encrypted, so it never expands.
+func WriteThinking(out io.Writer, block types.ContentBlock, verbose bool) {
+	if block.Type == "redacted_thinking" {
+		fmt.Fprintln(out, style.MutedText(ThinkingSymbol+" Thinking… (redacted)"))
+		return
+	}
+	text := strings.TrimSpace(block.Thinking)
+	if text == "" {
+		return
+	}
+	tokens := numfmt.Default().Tokens(thinkingTokens(text))
+	fmt.Fprintln(out, style.MutedText(fmt.Sprintf("%s Thinking… (%s tokens)", ThinkingSymbol, tokens)))
+	if !verbose {
+		return
+	}
+	prefix := style.OutputPrefix
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			fmt.Fprintln(out, strings.TrimRight(style.OutputContinue, " "))
+		} else {
+			fmt.Fprintln(out, prefix+style.MutedItalicText(line))
+		}
+		prefix = style.OutputContinue
+	}
+}
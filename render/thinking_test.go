@@ -0,0 +1,57 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/types"
+)
+
+func TestWriteThinking(t *testing.T) {
+	style.Init(true)
+	thought := types.ContentBlock{Type: "thinking", Thinking: strings.Repeat("a", 398) + "\n\nsecond"}
+
+	tests := []struct {
+		name    string
+		block   types.ContentBlock
+		verbose bool
+		want    string
+	}{
+		{
+			name:  "collapsed",
+			block: thought,
+			want:  "✻ Thinking… (102 tokens)\n",
+		},
+		{
+			name:    "verbose",
+			block:   thought,
+			verbose: true,
+			want: "✻ Thinking… (102 tokens)\n" +
+				style.OutputPrefix + strings.Repeat("a", 398) + "\n\n" +
+				style.OutputContinue + "second\n",
+		},
+		{
+			name:    "redacted",
+			block:   types.ContentBlock{Type: "redacted_thinking", Data: "EmwKAhgB"},
+			verbose: true,
+			want:    "✻ Thinking… (redacted)\n",
+		},
+		{
+			name:  "empty",
+			block: types.ContentBlock{Type: "thinking", Thinking: "  \n"},
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			WriteThinking(&buf, tt.block, tt.verbose)
+			if buf.String() != tt.want {
+				t.Errorf("WriteThinking() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package render
 import (
 	"strings"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/timeline"
 )
@@ -32,24 +33,20 @@ func (r *TimelineRenderer) RenderEntries(entries []timeline.Entry) string {
 // RenderActivity returns terminal text for a live pending item.
 func (r *TimelineRenderer) RenderActivity(activity timeline.Activity, icon string) string {
 	var sb strings.Builder
+	prefix := ""
 	if activity.Nested {
-		sb.WriteString(style.OutputPrefix)
+		prefix = style.OutputPrefix
 	}
+	sb.WriteString(prefix)
 	sb.WriteString(style.ApplyThemeBoldGradient(icon + " " + activity.Name))
 	if activity.Input != "" {
-		sb.WriteString(" " + style.MutedText(truncateTimelineArg(activity.Input, 80)))
+		width := HeaderArgWidth(ansi.StringWidth(prefix + icon + " " + activity.Name + " "))
+		sb.WriteString(" " + style.MutedText(truncateTimelineArg(activity.Input, width)))
 	}
 	sb.WriteString("\n")
 	return sb.String()
 }
 
 func truncateTimelineArg(s string, max int) string {
-	s = strings.ReplaceAll(s, "\n", " ")
-	if len(s) <= max {
-		return s
-	}
-	if max <= 3 {
-		return s[:max]
-	}
-	return s[:max-3] + "..."
+	return ansi.Truncate(strings.ReplaceAll(s, "\n", " "), max, "...")
 }
@@ -0,0 +1,40 @@
+package render
+
+import "strings"
+
+// Whitespace markers, drawn in place of characters that are otherwise
+// invisible in a diff.
+const (
+	TabMarker   = "⇥"
+	SpaceMarker = "·"
+	CRMarker    = "␍"
+)
+
+// tabMarkerPad follows TabMarker so a marked tab is as wide as a tab
+// expanded to four columns.
+const tabMarkerPad = "   "
+
+// MarkWhitespace returns line with its whitespace made visible: every tab
+// becomes TabMarker, every carriage return CRMarker, and trailing spaces
+// SpaceMarker. Spaces between words are left alone, so a whitespace-only
+// change stands out without the rest of the line turning to dots.
+func MarkWhitespace(line string) string {
+	if !strings.ContainsAny(line, " \t\r") {
+		return line
+	}
+	trailing := len(strings.TrimRight(line, " \t\r"))
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\t':
+			sb.WriteString(TabMarker + tabMarkerPad)
+		case c == '\r':
+			sb.WriteString(CRMarker)
+		case c == ' ' && i >= trailing:
+			sb.WriteString(SpaceMarker)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package render
+
+import "testing"
+
+func TestMarkWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "no whitespace", line: "return", want: "return"},
+		{name: "inner spaces kept", line: "return x + 1", want: "return x + 1"},
+		{name: "trailing spaces", line: "x := 1  ", want: "x := 1··"},
+		{name: "only spaces", line: "   ", want: "···"},
+		{name: "tab indent", line: "\t\tx", want: "⇥   ⇥   x"},
+		{name: "mixed indent", line: " \tx", want: " ⇥   x"},
+		{name: "carriage return", line: "x\r", want: "x␍"},
+		{name: "trailing tab and space", line: "x \t", want: "x·⇥   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkWhitespace(tt.line); got != tt.want {
+				t.Errorf("MarkWhitespace(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
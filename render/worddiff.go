@@ -0,0 +1,132 @@
+package render
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a half-open byte range [Start, End) within a line.
+type Span struct {
+	Start int
+	End   int
+}
+
+const (
+	// maxWordDiffTokens bounds the LCS table so pathological lines (minified
+	// code, huge literals) fall back to whole-line highlighting.
+	maxWordDiffTokens = 512
+	// minWordDiffSimilarity is the fraction of bytes two lines must share
+	// before intra-line emphasis is shown. Below it the lines are effectively
+	// rewritten and emphasizing nearly everything only adds noise.
+	minWordDiffSimilarity = 0.4
+)
+
+// WordDiff compares a removed line with its replacement and returns the byte
+// spans that changed in each. Lines are split into words, runs of whitespace
+// and single punctuation characters, then aligned by longest common
+// subsequence. It returns nil spans when the lines are too dissimilar (or too
+// long) for word-level emphasis to be useful.
+func WordDiff(oldLine, newLine string) (oldSpans, newSpans []Span) {
+	if oldLine == newLine {
+		return nil, nil
+	}
+	a, b := diffTokens(oldLine), diffTokens(newLine)
+	if len(a) > maxWordDiffTokens || len(b) > maxWordDiffTokens {
+		return nil, nil
+	}
+
+	keepA, keepB, shared := lcsTokens(oldLine, newLine, a, b)
+	total := len(oldLine) + len(newLine)
+	if total == 0 || float64(2*shared)/float64(total) < minWordDiffSimilarity {
+		return nil, nil
+	}
+	return changedSpans(a, keepA), changedSpans(b, keepB)
+}
+
+// diffTokens splits s into word, whitespace and punctuation tokens.
+func diffTokens(s string) []Span {
+	var tokens []Span
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		end := i + size
+		switch {
+		case isWordRune(r):
+			for end < len(s) {
+				next, n := utf8.DecodeRuneInString(s[end:])
+				if !isWordRune(next) {
+					break
+				}
+				end += n
+			}
+		case unicode.IsSpace(r):
+			for end < len(s) {
+				next, n := utf8.DecodeRuneInString(s[end:])
+				if !unicode.IsSpace(next) {
+					break
+				}
+				end += n
+			}
+		}
+		tokens = append(tokens, Span{Start: i, End: end})
+		i = end
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lcsTokens marks the tokens of a and b that belong to their longest common
+// subsequence and returns the number of shared bytes.
+func lcsTokens(sa, sb string, a, b []Span) (keepA, keepB []bool, shared int) {
+	text := func(s string, t Span) string { return s[t.Start:t.End] }
+
+	// lengths[i][j] is the LCS length of a[i:] and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if text(sa, a[i]) == text(sb, b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	keepA, keepB = make([]bool, len(a)), make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case text(sa, a[i]) == text(sb, b[j]):
+			keepA[i], keepB[j] = true, true
+			shared += a[i].End - a[i].Start
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return keepA, keepB, shared
+}
+
+// changedSpans merges adjacent tokens not in the common subsequence into
+// spans.
+func changedSpans(tokens []Span, keep []bool) []Span {
+	var spans []Span
+	for i, t := range tokens {
+		if keep[i] {
+			continue
+		}
+		if n := len(spans); n > 0 && spans[n-1].End == t.Start {
+			spans[n-1].End = t.End
+			continue
+		}
+		spans = append(spans, t)
+	}
+	return spans
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		name             string
+		old, new         string
+		wantOld, wantNew []string
+	}{
+		{
+			name: "single word changed",
+			old:  "\treturn count + 1", new: "\treturn total + 1",
+			wantOld: []string{"count"}, wantNew: []string{"total"},
+		},
+		{
+			name: "one character operator",
+			old:  "if a < b {", new: "if a <= b {",
+			wantOld: nil, wantNew: []string{"="},
+		},
+		{
+			name: "insertion only",
+			old:  "foo(a)", new: "foo(a, b)",
+			wantOld: nil, wantNew: []string{", b"},
+		},
+		{
+			name: "shared punctuation splits spans",
+			old:  "x := a.b", new: "x := c.d",
+			wantOld: []string{"a", "b"}, wantNew: []string{"c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldSpans, newSpans := WordDiff(tt.old, tt.new)
+			if got := spanTexts(tt.old, oldSpans); !reflect.DeepEqual(got, tt.wantOld) {
+				t.Errorf("old spans = %q, want %q", got, tt.wantOld)
+			}
+			if got := spanTexts(tt.new, newSpans); !reflect.DeepEqual(got, tt.wantNew) {
+				t.Errorf("new spans = %q, want %q", got, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestWordDiff_NoEmphasis(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+	}{
+		{"identical", "same line", "same line"},
+		{"rewritten", "completely different", "x"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if oldSpans, newSpans := WordDiff(tt.old, tt.new); oldSpans != nil || newSpans != nil {
+				t.Errorf("WordDiff = %v, %v; want nil spans", oldSpans, newSpans)
+			}
+		})
+	}
+}
+
+func TestDiffTokens(t *testing.T) {
+	s := "héllo_1  (x)"
+	want := []string{"héllo_1", "  ", "(", "x", ")"}
+	if got := spanTexts(s, diffTokens(s)); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffTokens = %q, want %q", got, want)
+	}
+}
+
+func spanTexts(s string, spans []Span) []string {
+	var out []string
+	for _, sp := range spans {
+		out = append(out, s[sp.Start:sp.End])
+	}
+	return out
+}
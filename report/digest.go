@@ -0,0 +1,228 @@
+// Package report builds the reports `viewscreen report` writes from the
+// recordings -auto-record keeps (see package sessions). The digest sums up
+// a week of sessions as Markdown, for sharing with a team.
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/sessions"
+	"github.com/johnnyfreeman/viewscreen/summary"
+)
+
+// DigestPeriod is the span of recordings a digest covers, up to when it is
+// built.
+const DigestPeriod = 7 * 24 * time.Hour
+
+// maxListed caps the files and errors a digest lists.
+const maxListed = 10
+
+// Count is how many sessions something happened in.
+type Count struct {
+	Name string
+	N    int
+}
+
+// Project is what one project's sessions added up to.
+type Project struct {
+	Name      string
+	Sessions  int
+	Succeeded int
+	CostUSD   float64
+}
+
+// Digest sums up the recorded sessions of a period.
+type Digest struct {
+	Since, Until time.Time
+	Sessions     int
+	// Succeeded counts the sessions whose outcome was outcome.Success.
+	Succeeded  int
+	CostUSD    float64
+	ToolCalls  int
+	ToolErrors int
+	// Projects are listed by sessions run, most first.
+	Projects []Project
+	// Files are the files changed in the most sessions, most first.
+	Files []Count
+	// Errors are the errors the most sessions ended with, most first.
+	Errors []Count
+}
+
+// SuccessRate is the fraction of sessions that succeeded, or 0 without
+// sessions.
+func (d Digest) SuccessRate() float64 {
+	if d.Sessions == 0 {
+		return 0
+	}
+	return float64(d.Succeeded) / float64(d.Sessions)
+}
+
+// BuildDigest sums up the recordings of every project under root last
+// modified in the DigestPeriod before now. A recording that cannot be read
+// is left out and its error returned with the digest of the others.
+func BuildDigest(root string, now time.Time) (Digest, error) {
+	d := Digest{Since: now.Add(-DigestPeriod), Until: now}
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	files := make(map[string]int)
+	failures := make(map[string]int)
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		recs, err := sessions.List(filepath.Join(root, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		project := Project{Name: e.Name()}
+		for _, rec := range recs {
+			if rec.ModTime.Before(d.Since) || rec.ModTime.After(now) {
+				continue
+			}
+			s, err := summarize(rec.Path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", rec.Path, err))
+				continue
+			}
+			project.Sessions++
+			project.CostUSD += s.CostUSD
+			if s.Outcome == outcome.Success {
+				project.Succeeded++
+			}
+			for _, n := range s.ToolCounts {
+				d.ToolCalls += n
+			}
+			d.ToolErrors += s.ToolErrors
+			for _, path := range s.FilesChanged {
+				files[path]++
+			}
+			// An error repeated within a session counts once.
+			seen := make(map[string]bool)
+			for _, msg := range s.Errors {
+				if msg = strings.TrimSpace(msg); msg != "" && !seen[msg] {
+					seen[msg] = true
+					failures[msg]++
+				}
+			}
+		}
+		if project.Sessions > 0 {
+			d.Projects = append(d.Projects, project)
+			d.Sessions += project.Sessions
+			d.Succeeded += project.Succeeded
+			d.CostUSD += project.CostUSD
+		}
+	}
+	sort.SliceStable(d.Projects, func(i, j int) bool { return d.Projects[i].Sessions > d.Projects[j].Sessions })
+	d.Files = topCounts(files)
+	d.Errors = topCounts(failures)
+	return d, errors.Join(errs...)
+}
+
+// summarize reads the recording at path into its session summary.
+func summarize(path string) (summary.Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return summary.Summary{}, err
+	}
+	defer f.Close()
+	c := summary.NewCollector()
+	scanner := jsonl.NewReader(record.NewPlayer(f, 0), nil)
+	for scanner.Scan() {
+		c.Observe(scanner.Bytes())
+	}
+	return c.Summary(), scanner.Err()
+}
+
+// topCounts returns the maxListed largest counts, most first and then by
+// name.
+func topCounts(counts map[string]int) []Count {
+	list := make([]Count, 0, len(counts))
+	for name, n := range counts {
+		list = append(list, Count{name, n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].N != list[j].N {
+			return list[i].N > list[j].N
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list[:min(len(list), maxListed)]
+}
+
+// WriteDigest writes d as Markdown: the totals, then the sessions of each
+// project, the most edited files and the most frequent errors.
+func WriteDigest(w io.Writer, d Digest) error {
+	nf := numfmt.Default()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Weekly digest: %s – %s\n\n", d.Since.Format("Jan 2"), d.Until.Format("Jan 2, 2006"))
+	if d.Sessions == 0 {
+		sb.WriteString("No sessions were recorded this week.\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	fmt.Fprintf(&sb, "- **Sessions run:** %s\n", nf.Int(d.Sessions))
+	fmt.Fprintf(&sb, "- **Success rate:** %s (%s of %s)\n", percent(d.SuccessRate()), nf.Int(d.Succeeded), nf.Int(d.Sessions))
+	fmt.Fprintf(&sb, "- **Total cost:** %s\n", nf.Cost(d.CostUSD, 2))
+	fmt.Fprintf(&sb, "- **Tool calls:** %s (%s failed)\n", nf.Int(d.ToolCalls), nf.Int(d.ToolErrors))
+
+	if len(d.Projects) > 1 {
+		sb.WriteString("\n## Projects\n\n")
+		sb.WriteString("| Project | Sessions | Success rate | Cost |\n")
+		sb.WriteString("| --- | ---: | ---: | ---: |\n")
+		for _, p := range d.Projects {
+			rate := float64(p.Succeeded) / float64(p.Sessions)
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", cell(p.Name), nf.Int(p.Sessions), percent(rate), nf.Cost(p.CostUSD, 2))
+		}
+	}
+
+	if len(d.Files) > 0 {
+		sb.WriteString("\n## Most edited files\n\n")
+		sb.WriteString("| File | Sessions |\n")
+		sb.WriteString("| --- | ---: |\n")
+		for _, f := range d.Files {
+			fmt.Fprintf(&sb, "| `%s` | %s |\n", cell(f.Name), nf.Int(f.N))
+		}
+	}
+
+	if len(d.Errors) > 0 {
+		sb.WriteString("\n## Frequent errors\n\n")
+		sb.WriteString("| Error | Sessions |\n")
+		sb.WriteString("| --- | ---: |\n")
+		for _, e := range d.Errors {
+			fmt.Fprintf(&sb, "| %s | %s |\n", cell(e.Name), nf.Int(e.N))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// cell makes s safe inside a Markdown table cell.
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// percent formats a fraction as a percentage with one decimal.
+func percent(f float64) string {
+	return numfmt.Default().Float(f*100, 1) + "%"
+}
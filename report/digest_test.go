@@ -0,0 +1,115 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/record"
+)
+
+// edit is a session that edits path and ends with result.
+func edit(path, result string) []string {
+	return []string{
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"` + path + `"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]},"tool_use_result":{"filePath":"` + path + `"}}`,
+		result,
+	}
+}
+
+const (
+	success = `{"type":"result","subtype":"success","is_error":false,"total_cost_usd":0.5}`
+	failure = `{"type":"result","subtype":"error_max_turns","is_error":true,"total_cost_usd":1.25,"errors":["max turns reached"]}`
+)
+
+// writeRecording records lines as project's recording name, last modified
+// at mod.
+func writeRecording(t *testing.T, root, project, name string, mod time.Time, lines []string) {
+	t.Helper()
+	dir := filepath.Join(root, project)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name+record.Extension)
+	rec, err := record.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range lines {
+		if err := rec.Record(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	writeRecording(t, root, "api", "a", now.Add(-day), edit("/api/main.go", success))
+	writeRecording(t, root, "api", "b", now.Add(-2*day), edit("/api/main.go", failure))
+	writeRecording(t, root, "api", "c", now.Add(-3*day), edit("/api/db.go", failure))
+	writeRecording(t, root, "web", "a", now.Add(-4*day), edit("/web/app.ts", success))
+	// Older than a week
+	writeRecording(t, root, "web", "old", now.Add(-8*day), edit("/web/old.ts", failure))
+
+	d, err := BuildDigest(root, now)
+	if err != nil {
+		t.Fatalf("BuildDigest: %v", err)
+	}
+	if d.Sessions != 4 || d.Succeeded != 2 || d.SuccessRate() != 0.5 {
+		t.Errorf("Sessions = %d, Succeeded = %d, SuccessRate = %v", d.Sessions, d.Succeeded, d.SuccessRate())
+	}
+	if d.CostUSD != 3.5 {
+		t.Errorf("CostUSD = %v, want 3.5", d.CostUSD)
+	}
+	if len(d.Projects) != 2 || d.Projects[0].Name != "api" || d.Projects[0].Sessions != 3 {
+		t.Errorf("Projects = %+v, want api's 3 sessions first", d.Projects)
+	}
+	if len(d.Files) != 3 || d.Files[0] != (Count{"/api/main.go", 2}) {
+		t.Errorf("Files = %+v, want /api/main.go first", d.Files)
+	}
+	if len(d.Errors) != 1 || d.Errors[0] != (Count{"max turns reached", 2}) {
+		t.Errorf("Errors = %+v", d.Errors)
+	}
+
+	var sb strings.Builder
+	if err := WriteDigest(&sb, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Weekly digest: Mar 7 – Mar 14, 2026",
+		"- **Sessions run:** 4",
+		"- **Success rate:** 50.0% (2 of 4)",
+		"- **Total cost:** $3.50",
+		"| api | 3 | 33.3% | $3.00 |",
+		"| `/api/main.go` | 2 |",
+		"| max turns reached | 2 |",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("expected %q in digest, got:\n%s", want, sb.String())
+		}
+	}
+}
+
+func TestBuildDigest_Empty(t *testing.T) {
+	d, err := BuildDigest(filepath.Join(t.TempDir(), "missing"), time.Now())
+	if err != nil || d.Sessions != 0 {
+		t.Fatalf("BuildDigest = %+v, %v", d, err)
+	}
+	var sb strings.Builder
+	if err := WriteDigest(&sb, d); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "No sessions were recorded this week.") {
+		t.Errorf("unexpected digest %q", sb.String())
+	}
+}
@@ -0,0 +1,106 @@
+package result
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+)
+
+// modelColumns heads the columns of the model usage table.
+var modelColumns = []string{"Model", "In", "Out", "Cache read", "Cache write", "Cost", "Context"}
+
+// modelTable lays out the event's ModelUsage as aligned lines under a muted
+// header: each model's tokens in and out, cache reads and writes, cost, and
+// how full its context window was at its latest request. Models are listed
+// by cost, highest first. It returns nil when usage is hidden or no model
+// was reported.
+func (r *Renderer) modelTable(event Event) []string {
+	if !r.config.ShowUsage() || len(event.ModelUsage) == 0 {
+		return nil
+	}
+	nf := numfmt.Default()
+	names := slices.Sorted(maps.Keys(event.ModelUsage))
+	slices.SortStableFunc(names, func(a, b string) int {
+		return cmp.Compare(event.ModelUsage[b].CostUSD, event.ModelUsage[a].CostUSD)
+	})
+
+	rows := [][]string{modelColumns}
+	for _, name := range names {
+		u := event.ModelUsage[name]
+		rows = append(rows, []string{
+			name,
+			nf.Tokens(u.InputTokens),
+			nf.Tokens(u.OutputTokens),
+			nf.Tokens(u.CacheReadInputTokens),
+			nf.Tokens(u.CacheCreationInputTokens),
+			nf.Cost(u.CostUSD, 4),
+			r.modelContext(name, u),
+		})
+	}
+	widths := make([]int, len(modelColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], ansi.StringWidth(cell))
+		}
+	}
+
+	sa := r.styleApplier
+	lines := make([]string, len(rows))
+	for n, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			pad := strings.Repeat(" ", widths[i]-ansi.StringWidth(cell))
+			// The model's name reads left to right; numbers line up on
+			// their last digit.
+			if i == 0 {
+				cells[i] = cell + pad
+			} else {
+				cells[i] = pad + cell
+			}
+		}
+		if n == 0 {
+			lines[n] = sa.MutedText(strings.Join(cells, "  "))
+			continue
+		}
+		last := len(cells) - 1
+		switch r.modelContextLevel(row[0], event.ModelUsage[row[0]]) {
+		case ctxwindow.Critical:
+			cells[last] = sa.ErrorText(cells[last])
+		case ctxwindow.Warning:
+			cells[last] = sa.WarningText(cells[last])
+		}
+		lines[n] = strings.Join(cells, "  ")
+	}
+	return lines
+}
+
+// modelWindow returns the context window of the model name, falling back to
+// the one its name implies when the event does not report it.
+func modelWindow(name string, u ModelUsage) int {
+	if u.ContextWindow > 0 {
+		return u.ContextWindow
+	}
+	return ctxwindow.WindowFor(name)
+}
+
+// modelContext describes how full the model's context window was at its
+// latest request, e.g. "62% of 200.0k", or "—" when none was recorded.
+func (r *Renderer) modelContext(name string, u ModelUsage) string {
+	if r.stats == nil || r.stats.ModelContextTokens(name) == 0 {
+		return "—"
+	}
+	return ctxwindow.Format(r.stats.ModelContextTokens(name), modelWindow(name, u))
+}
+
+// modelContextLevel is the ctxwindow level of the model's context.
+func (r *Renderer) modelContextLevel(name string, u ModelUsage) ctxwindow.Level {
+	if r.stats == nil || r.stats.ModelContextTokens(name) == 0 {
+		return ctxwindow.Normal
+	}
+	return ctxwindow.Default().Level(ctxwindow.Percent(r.stats.ModelContextTokens(name), modelWindow(name, u)))
+}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
+	"charm.land/lipgloss/v2"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/outcome"
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/tools"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
 
@@ -68,11 +74,24 @@ type Event struct {
 	FastModeState     string                `json:"fast_mode_state"`
 }
 
+// ContextWindow returns the largest context window among the models the
+// session used, or 0 when none was reported.
+func (e Event) ContextWindow() int {
+	window := 0
+	for _, usage := range e.ModelUsage {
+		window = max(window, usage.ContextWindow)
+	}
+	return window
+}
+
 // Renderer handles rendering of result events with configurable output and options
 type Renderer struct {
 	output       io.Writer
 	config       config.Provider
 	styleApplier render.StyleApplier
+	toolTimer    *tools.ToolTimer
+	stats        *SessionStats
+	summary      string
 }
 
 // RendererOption is a functional option for configuring a Renderer
@@ -99,12 +118,39 @@ func WithStyleApplier(sa render.StyleApplier) RendererOption {
 	}
 }
 
+// WithToolTimer sets the timer whose per-tool totals are listed in the footer
+func WithToolTimer(t *tools.ToolTimer) RendererOption {
+	return func(r *Renderer) {
+		r.toolTimer = t
+	}
+}
+
+// WithSessionStats sets the stats whose files changed and tool errors the
+// summary card shows
+func WithSessionStats(s *SessionStats) RendererOption {
+	return func(r *Renderer) {
+		r.stats = s
+	}
+}
+
+// WithSummaryStyle selects the summary layout: config.SummaryCard for a
+// bordered card, anything else for the flat list of lines
+func WithSummaryStyle(summary string) RendererOption {
+	return func(r *Renderer) {
+		r.summary = summary
+	}
+}
+
+// maxToolTotals caps the tools listed in the footer's tool time line.
+const maxToolTotals = 5
+
 // NewRenderer creates a new Renderer with the given options
 func NewRenderer(opts ...RendererOption) *Renderer {
 	r := &Renderer{
 		output:       os.Stdout,
 		config:       config.Get(),
 		styleApplier: render.DefaultStyleApplier{},
+		summary:      config.Get().SummaryStyle,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -114,6 +160,15 @@ func NewRenderer(opts ...RendererOption) *Renderer {
 
 // renderTo writes the result event to the given output
 func (r *Renderer) renderTo(out *render.Output, event Event) {
+	if r.summary == config.SummaryCard {
+		r.renderCard(out, event)
+		return
+	}
+	r.renderPlain(out, event)
+}
+
+// renderPlain writes the summary as a flat list of lines.
+func (r *Renderer) renderPlain(out *render.Output, event Event) {
 	sa := r.styleApplier
 	fmt.Fprintln(out)
 	if event.IsError {
@@ -125,19 +180,44 @@ func (r *Renderer) renderTo(out *render.Output, event Event) {
 		fmt.Fprintln(out, style.BulletSuccessHeader("Session Complete"))
 	}
 
+	fmt.Fprintf(out, "%s%s %s\n", sa.OutputPrefix(), sa.MutedText("Outcome:"), r.verdict(event))
 	fmt.Fprintf(out, "%s%s %.2fs (API: %.2fs)\n",
-		sa.OutputPrefix(),
+		sa.OutputContinue(),
 		sa.MutedText("Duration:"),
 		float64(event.DurationMS)/1000, float64(event.DurationAPIMS)/1000)
-	fmt.Fprintf(out, "%s%s %d\n", sa.OutputContinue(), sa.MutedText("Turns:"), event.NumTurns)
-	fmt.Fprintf(out, "%s%s $%.4f\n", sa.OutputContinue(), sa.MutedText("Cost:"), event.TotalCostUSD)
+	nf := numfmt.Default()
+	fmt.Fprintf(out, "%s%s %s\n", sa.OutputContinue(), sa.MutedText("Turns:"), nf.Int(event.NumTurns))
+	fmt.Fprintf(out, "%s%s %s\n", sa.OutputContinue(), sa.MutedText("Cost:"), nf.Cost(event.TotalCostUSD, 4))
 
 	if r.config.ShowUsage() {
-		fmt.Fprintf(out, "%s%s in=%d out=%d (cache: created=%d read=%d)\n",
+		fmt.Fprintf(out, "%s%s in=%s out=%s (cache: created=%s read=%s)\n",
 			sa.OutputContinue(),
 			sa.MutedText("Tokens:"),
-			event.Usage.InputTokens, event.Usage.OutputTokens,
-			event.Usage.CacheCreationInputTokens, event.Usage.CacheReadInputTokens)
+			nf.Int(event.Usage.InputTokens), nf.Int(event.Usage.OutputTokens),
+			nf.Int(event.Usage.CacheCreationInputTokens), nf.Int(event.Usage.CacheReadInputTokens))
+	}
+
+	if context := r.contextUsage(event); context != "" {
+		fmt.Fprintf(out, "%s%s %s\n", sa.OutputContinue(), sa.MutedText("Context:"), context)
+	}
+
+	if table := r.modelTable(event); table != nil {
+		fmt.Fprintf(out, "%s%s\n", sa.OutputContinue(), sa.MutedText("Models:"))
+		for _, line := range table {
+			fmt.Fprintf(out, "%s  %s\n", sa.OutputContinue(), line)
+		}
+	}
+
+	r.renderToolTimes(out)
+
+	if r.stats != nil && r.stats.EmptySearches() > 0 {
+		fmt.Fprintf(out, "%s%s %d\n", sa.OutputContinue(), sa.WarningText("Empty searches:"), r.stats.EmptySearches())
+	}
+	if r.stats != nil && r.stats.WatchedPathCalls() > 0 {
+		fmt.Fprintf(out, "%s%s %d\n", sa.OutputContinue(), sa.ErrorText("Watched paths:"), r.stats.WatchedPathCalls())
+	}
+	if r.stats != nil && r.stats.ReadOnlyViolations() > 0 {
+		fmt.Fprintf(out, "%s%s %d\n", sa.OutputContinue(), sa.ErrorText("Read-only violations:"), r.stats.ReadOnlyViolations())
 	}
 
 	if len(event.PermissionDenials) > 0 {
@@ -151,6 +231,176 @@ func (r *Renderer) renderTo(out *render.Output, event Event) {
 	}
 }
 
+// contextUsage describes how full the context window was at the session's
+// last request, colored by the ctxwindow thresholds, or "" when no usage was
+// recorded. The window falls back to ctxwindow.DefaultWindow when the event
+// does not report one.
+func (r *Renderer) contextUsage(event Event) string {
+	if r.stats == nil || r.stats.ContextTokens() == 0 {
+		return ""
+	}
+	used, window := r.stats.ContextTokens(), event.ContextWindow()
+	if window == 0 {
+		window = ctxwindow.DefaultWindow
+	}
+	text := ctxwindow.Format(used, window)
+	switch ctxwindow.Default().Level(ctxwindow.Percent(used, window)) {
+	case ctxwindow.Critical:
+		return r.styleApplier.ErrorText(text)
+	case ctxwindow.Warning:
+		return r.styleApplier.WarningText(text)
+	}
+	return text
+}
+
+// outcome classifies the session by the outcome.Default rules.
+func (r *Renderer) outcome(event Event) outcome.Outcome {
+	facts := outcome.Facts{Finished: true, IsError: event.IsError, Denials: len(event.PermissionDenials)}
+	if r.stats != nil {
+		facts.ToolErrors = r.stats.ToolErrors()
+	}
+	return outcome.Default().Classify(facts)
+}
+
+// verdict is the session's outcome, colored by how it went.
+func (r *Renderer) verdict(event Event) string {
+	sa := r.styleApplier
+	switch o := r.outcome(event); o {
+	case outcome.Success:
+		return sa.SuccessText("✓ " + string(o))
+	case outcome.Partial:
+		return sa.WarningText("◐ " + string(o))
+	default:
+		return sa.ErrorText("✗ " + string(o))
+	}
+}
+
+// renderToolTimes writes the time spent per tool.
+func (r *Renderer) renderToolTimes(out *render.Output) {
+	times := r.toolTimes()
+	if times == "" {
+		return
+	}
+	sa := r.styleApplier
+	fmt.Fprintf(out, "%s%s %s\n", sa.OutputContinue(), sa.MutedText("Tool time:"), times)
+}
+
+// toolTimes lists the time spent per tool, longest first. Tools under
+// tools.MinShownElapsed are left out.
+func (r *Renderer) toolTimes() string {
+	if r.toolTimer == nil {
+		return ""
+	}
+	var parts []string
+	shown := 0
+	for _, total := range r.toolTimer.Totals() {
+		if total.Total < tools.MinShownElapsed {
+			break
+		}
+		if shown == maxToolTotals {
+			parts = append(parts, "…")
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %s (%d)", tools.DisplayName(total.Name), tools.FormatElapsed(total.Total), total.Count))
+		shown++
+	}
+	return strings.Join(parts, ", ")
+}
+
+// summaryRow is one labelled line of the summary card.
+type summaryRow struct {
+	label, value string
+}
+
+// renderCard writes the summary as a bordered card with aligned columns,
+// colored by the session's outcome.
+func (r *Renderer) renderCard(out *render.Output, event Event) {
+	sa := r.styleApplier
+	nf := numfmt.Default()
+	fmt.Fprintln(out)
+	if event.IsError {
+		fmt.Fprintln(out, style.BulletErrorHeader("Session Error"))
+	} else {
+		fmt.Fprintln(out, style.BulletSuccessHeader("Session Complete"))
+	}
+
+	rows := []summaryRow{
+		{"Outcome", r.verdict(event)},
+		{"Duration", fmt.Sprintf("%.2fs (API: %.2fs)", float64(event.DurationMS)/1000, float64(event.DurationAPIMS)/1000)},
+		{"Turns", nf.Int(event.NumTurns)},
+		{"Cost", nf.Cost(event.TotalCostUSD, 4)},
+	}
+	if r.config.ShowUsage() {
+		rows = append(rows, summaryRow{"Tokens", fmt.Sprintf("in=%s out=%s (cache: created=%s read=%s)",
+			nf.Int(event.Usage.InputTokens), nf.Int(event.Usage.OutputTokens),
+			nf.Int(event.Usage.CacheCreationInputTokens), nf.Int(event.Usage.CacheReadInputTokens))})
+	}
+	if context := r.contextUsage(event); context != "" {
+		rows = append(rows, summaryRow{"Context", context})
+	}
+	errors := len(event.Errors)
+	if r.stats != nil {
+		rows = append(rows, summaryRow{"Files changed", nf.Int(r.stats.FilesChanged())})
+		errors += r.stats.ToolErrors()
+	}
+	if r.stats != nil || errors > 0 {
+		value := nf.Int(errors)
+		if errors > 0 {
+			value = sa.ErrorText(value)
+		}
+		rows = append(rows, summaryRow{"Errors", value})
+	}
+	if times := r.toolTimes(); times != "" {
+		rows = append(rows, summaryRow{"Tool time", times})
+	}
+	if r.stats != nil && r.stats.EmptySearches() > 0 {
+		rows = append(rows, summaryRow{"Empty searches", sa.WarningText(nf.Int(r.stats.EmptySearches()))})
+	}
+	if r.stats != nil && r.stats.WatchedPathCalls() > 0 {
+		rows = append(rows, summaryRow{"Watched paths", sa.ErrorText(nf.Int(r.stats.WatchedPathCalls()))})
+	}
+	if r.stats != nil && r.stats.ReadOnlyViolations() > 0 {
+		rows = append(rows, summaryRow{"Read-only violations", sa.ErrorText(nf.Int(r.stats.ReadOnlyViolations()))})
+	}
+	if len(event.PermissionDenials) > 0 {
+		names := make([]string, len(event.PermissionDenials))
+		for i, denial := range event.PermissionDenials {
+			names[i] = denial.ToolName
+		}
+		rows = append(rows, summaryRow{"Denied", sa.WarningText(fmt.Sprintf("%d (%s)", len(names), strings.Join(names, ", ")))})
+	}
+
+	labelWidth := 0
+	for _, row := range rows {
+		labelWidth = max(labelWidth, len(row.label))
+	}
+	var lines []string
+	for _, err := range event.Errors {
+		lines = append(lines, sa.ErrorText(err))
+	}
+	for _, row := range rows {
+		lines = append(lines, sa.MutedText(fmt.Sprintf("%-*s", labelWidth, row.label))+"  "+row.value)
+	}
+	if table := r.modelTable(event); table != nil {
+		lines = append(append(lines, ""), table...)
+	}
+
+	card := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
+	if !sa.NoColor() {
+		border := style.CurrentTheme.Success
+		switch r.outcome(event) {
+		case outcome.Partial:
+			border = style.CurrentTheme.Warning
+		case outcome.Failed:
+			border = style.CurrentTheme.Error
+		}
+		card = card.BorderForeground(lipgloss.Color(string(border)))
+	}
+	for _, line := range strings.Split(card.Render(strings.Join(lines, "\n")), "\n") {
+		fmt.Fprintf(out, "  %s\n", line)
+	}
+}
+
 // Render outputs the result event using this renderer's configuration
 func (r *Renderer) Render(event Event) {
 	r.renderTo(render.WriterOutput(r.output), event)
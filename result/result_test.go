@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/johnnyfreeman/viewscreen/config"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/testutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
 )
 
 func TestNewRenderer(t *testing.T) {
@@ -194,8 +197,8 @@ func TestRenderer_Render_WithUsage(t *testing.T) {
 	output := buf.String()
 
 	// Check usage info
-	if !strings.Contains(output, "in=1000") {
-		t.Errorf("expected input tokens 'in=1000' in output, got %s", output)
+	if !strings.Contains(output, "in=1,000") {
+		t.Errorf("expected input tokens 'in=1,000' in output, got %s", output)
 	}
 	if !strings.Contains(output, "out=500") {
 		t.Errorf("expected output tokens 'out=500' in output, got %s", output)
@@ -291,6 +294,264 @@ func TestRenderer_Render_WithPermissionDenials(t *testing.T) {
 	}
 }
 
+func TestRenderer_Render_ToolTimes(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timer := tools.NewToolTimer(func() time.Time { return now })
+	for i, call := range []struct {
+		name    string
+		elapsed time.Duration
+	}{
+		{"Bash", 2 * time.Second},
+		{"Read", 300 * time.Millisecond},
+		{"Read", 200 * time.Millisecond},
+		{"Glob", 10 * time.Millisecond},
+	} {
+		id := string(rune('a' + i))
+		timer.Start(id, call.name)
+		now = now.Add(call.elapsed)
+		timer.Finish(id)
+	}
+
+	buf := &bytes.Buffer{}
+	r := NewRenderer(
+		WithOutput(buf),
+		WithConfigProvider(testutil.MockConfigProvider{}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+		WithToolTimer(timer),
+	)
+	r.Render(Event{NumTurns: 1})
+
+	output := buf.String()
+	if !strings.Contains(output, "Tool time:] Bash 2.0s (1), Read 0.5s (2)") {
+		t.Errorf("expected per-tool totals longest first, got %q", output)
+	}
+	if strings.Contains(output, "Glob") {
+		t.Errorf("expected tools under %v to be left out, got %q", tools.MinShownElapsed, output)
+	}
+
+	buf.Reset()
+	r = NewRenderer(
+		WithOutput(buf),
+		WithConfigProvider(testutil.MockConfigProvider{}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+	)
+	r.Render(Event{NumTurns: 1})
+	if strings.Contains(buf.String(), "Tool time:") {
+		t.Errorf("expected no tool times without a timer, got %q", buf.String())
+	}
+}
+
+func TestRenderer_Render_Card(t *testing.T) {
+	stats := NewSessionStats()
+	stats.FileChanged("/tmp/a.go")
+	stats.FileChanged("/tmp/a.go")
+	stats.FileChanged("/tmp/b.go")
+	stats.ToolFailed()
+
+	buf := &bytes.Buffer{}
+	r := NewRenderer(
+		WithOutput(buf),
+		WithConfigProvider(testutil.MockConfigProvider{ShowUsageVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+		WithSummaryStyle(config.SummaryCard),
+		WithSessionStats(stats),
+	)
+	r.Render(Event{
+		DurationMS:        5000,
+		DurationAPIMS:     3000,
+		NumTurns:          10,
+		TotalCostUSD:      0.1234,
+		Usage:             Usage{InputTokens: 100, OutputTokens: 50},
+		Errors:            []string{"max turns reached"},
+		PermissionDenials: []PermissionDenial{{ToolName: "Bash"}},
+	})
+
+	output := buf.String()
+	// The header is drawn with a gradient, one escape sequence per letter.
+	if !strings.Contains(testutil.StripANSI(output), "Session Complete") {
+		t.Errorf("expected \"Session Complete\" in card, got:\n%s", output)
+	}
+	for _, want := range []string{
+		"╭", "╰",
+		"[MUTED:Duration     ]  5.00s (API: 3.00s)",
+		"[MUTED:Turns        ]  10",
+		"[MUTED:Cost         ]  $0.1234",
+		"[MUTED:Tokens       ]  in=100 out=50",
+		"[MUTED:Files changed]  2",
+		"[MUTED:Errors       ]  [ERROR:2]",
+		"[MUTED:Denied       ]  [WARNING:1 (Bash)]",
+		"[ERROR:max turns reached]",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in card, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderer_Render_PlainSummary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	r := NewRenderer(
+		WithOutput(buf),
+		WithConfigProvider(testutil.MockConfigProvider{}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+		WithSummaryStyle(config.SummaryPlain),
+		WithSessionStats(NewSessionStats()),
+	)
+	r.Render(Event{NumTurns: 3})
+
+	output := buf.String()
+	if strings.Contains(output, "╭") || strings.Contains(output, "Files changed") {
+		t.Errorf("expected the flat summary, got %q", output)
+	}
+	if !strings.Contains(output, "Turns:] 3") {
+		t.Errorf("expected turns in the flat summary, got %q", output)
+	}
+}
+
+func TestRenderer_Render_ContextUsage(t *testing.T) {
+	tests := []struct {
+		name    string
+		used    int
+		window  int
+		summary string
+		want    string
+	}{
+		{"card with reported window", 62_000, 100_000, config.SummaryCard, "[MUTED:Context      ]  62% of 100.0k"},
+		{"card past warning", 150_000, 0, config.SummaryCard, "[MUTED:Context      ]  [WARNING:75% of 200.0k]"},
+		{"plain past critical", 190_000, 0, config.SummaryPlain, "[MUTED:Context:] [ERROR:95% of 200.0k]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := NewSessionStats()
+			stats.ContextUsed(tt.used)
+			buf := &bytes.Buffer{}
+			r := NewRenderer(
+				WithOutput(buf),
+				WithConfigProvider(testutil.MockConfigProvider{}),
+				WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+				WithSummaryStyle(tt.summary),
+				WithSessionStats(stats),
+			)
+			r.Render(Event{ModelUsage: map[string]ModelUsage{"claude": {ContextWindow: tt.window}}})
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("expected %q in summary, got:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	NewRenderer(WithOutput(buf), WithConfigProvider(testutil.MockConfigProvider{}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+		WithSessionStats(NewSessionStats())).Render(Event{})
+	if strings.Contains(buf.String(), "Context") {
+		t.Errorf("expected no context row without usage, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderer_Render_ModelUsage(t *testing.T) {
+	event := Event{ModelUsage: map[string]ModelUsage{
+		"claude-haiku": {InputTokens: 800, OutputTokens: 40, CostUSD: 0.002, ContextWindow: 200_000},
+		"claude-opus": {
+			InputTokens: 1200, OutputTokens: 3400, CacheReadInputTokens: 45_000, CacheCreationInputTokens: 9000,
+			CostUSD: 0.4321, ContextWindow: 200_000,
+		},
+	}}
+	stats := NewSessionStats()
+	stats.ModelContextUsed("claude-opus", 150_000)
+
+	for _, summary := range []string{config.SummaryPlain, config.SummaryCard} {
+		buf := &bytes.Buffer{}
+		NewRenderer(
+			WithOutput(buf),
+			WithConfigProvider(testutil.MockConfigProvider{ShowUsageVal: true}),
+			WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+			WithSummaryStyle(summary),
+			WithSessionStats(stats),
+		).Render(event)
+		output := buf.String()
+		for _, want := range []string{
+			"[MUTED:Model           In   Out  Cache read  Cache write     Cost        Context]",
+			"claude-opus   1.2k  3.4k       45.0k         9.0k  $0.4321  [WARNING:75% of 200.0k]",
+			"claude-haiku   800    40           0            0  $0.0020              —",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: expected %q in summary, got:\n%s", summary, want, output)
+			}
+		}
+		if strings.Index(output, "claude-opus") > strings.Index(output, "claude-haiku") {
+			t.Errorf("%s: expected models by cost, highest first, got:\n%s", summary, output)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	NewRenderer(WithOutput(buf), WithConfigProvider(testutil.MockConfigProvider{}),
+		WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true})).Render(event)
+	if strings.Contains(buf.String(), "claude-opus") {
+		t.Errorf("expected no model table with -usage=false, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderer_Render_EmptySearches(t *testing.T) {
+	stats := NewSessionStats()
+	stats.EmptySearch()
+	stats.EmptySearch()
+
+	for _, summary := range []string{config.SummaryCard, config.SummaryPlain} {
+		buf := &bytes.Buffer{}
+		r := NewRenderer(
+			WithOutput(buf),
+			WithConfigProvider(testutil.MockConfigProvider{}),
+			WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+			WithSummaryStyle(summary),
+			WithSessionStats(stats),
+		)
+		r.Render(Event{NumTurns: 3})
+
+		output := buf.String()
+		if !strings.Contains(output, "Empty searches") || !strings.Contains(output, "2") {
+			t.Errorf("%s summary: expected the empty search count, got %q", summary, output)
+		}
+	}
+}
+
+func TestRenderer_Render_WatchedPaths(t *testing.T) {
+	stats := NewSessionStats()
+	stats.WatchedPathTouched()
+
+	for _, summary := range []string{config.SummaryCard, config.SummaryPlain} {
+		buf := &bytes.Buffer{}
+		NewRenderer(
+			WithOutput(buf),
+			WithConfigProvider(testutil.MockConfigProvider{}),
+			WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+			WithSummaryStyle(summary),
+			WithSessionStats(stats),
+		).Render(Event{NumTurns: 1})
+		if !strings.Contains(buf.String(), "Watched paths") {
+			t.Errorf("%s summary: expected the watched path count, got %q", summary, buf.String())
+		}
+	}
+}
+
+func TestRenderer_Render_ReadOnlyViolations(t *testing.T) {
+	stats := NewSessionStats()
+	stats.ReadOnlyViolated()
+
+	for _, summary := range []string{config.SummaryCard, config.SummaryPlain} {
+		buf := &bytes.Buffer{}
+		NewRenderer(
+			WithOutput(buf),
+			WithConfigProvider(testutil.MockConfigProvider{}),
+			WithStyleApplier(testutil.MockStyleApplier{NoColorVal: true}),
+			WithSummaryStyle(summary),
+			WithSessionStats(stats),
+		).Render(Event{NumTurns: 1})
+		if !strings.Contains(buf.String(), "Read-only violations") {
+			t.Errorf("%s summary: expected the read-only violation count, got %q", summary, buf.String())
+		}
+	}
+}
+
 func TestRenderer_Render_NoPermissionDenials(t *testing.T) {
 	buf := &bytes.Buffer{}
 	r := NewRenderer(
@@ -0,0 +1,102 @@
+package result
+
+// SessionStats counts what a session did beyond what the result event
+// reports: the files its tools changed, the tool calls that failed, the
+// searches that found nothing, the calls that touched watched paths and
+// the calls that wrote or ran something in a read-only permission mode.
+type SessionStats struct {
+	files         map[string]bool
+	errors        int
+	emptySearches int
+	watched       int
+	readOnly      int
+	context       int
+	models        map[string]int // context of each model's latest request
+}
+
+// NewSessionStats creates empty session stats.
+func NewSessionStats() *SessionStats {
+	return &SessionStats{files: make(map[string]bool), models: make(map[string]int)}
+}
+
+// FileChanged records a change to path. Each file is counted once.
+func (s *SessionStats) FileChanged(path string) {
+	if path != "" {
+		s.files[path] = true
+	}
+}
+
+// ToolFailed records a failed tool call.
+func (s *SessionStats) ToolFailed() {
+	s.errors++
+}
+
+// EmptySearch records a Grep or Glob call that found nothing.
+func (s *SessionStats) EmptySearch() {
+	s.emptySearches++
+}
+
+// WatchedPathTouched records a tool call that touched a path on the
+// watchlist (see package watchlist).
+func (s *SessionStats) WatchedPathTouched() {
+	s.watched++
+}
+
+// FilesChanged returns the number of distinct files changed.
+func (s *SessionStats) FilesChanged() int {
+	return len(s.files)
+}
+
+// ToolErrors returns the number of failed tool calls.
+func (s *SessionStats) ToolErrors() int {
+	return s.errors
+}
+
+// EmptySearches returns the number of searches that found nothing.
+func (s *SessionStats) EmptySearches() int {
+	return s.emptySearches
+}
+
+// WatchedPathCalls returns the number of tool calls that touched watched
+// paths.
+func (s *SessionStats) WatchedPathCalls() int {
+	return s.watched
+}
+
+// ReadOnlyViolated records a tool call that wrote or ran something although
+// the session's permission mode was read-only.
+func (s *SessionStats) ReadOnlyViolated() {
+	s.readOnly++
+}
+
+// ReadOnlyViolations returns the number of tool calls that wrote or ran
+// something in a read-only permission mode.
+func (s *SessionStats) ReadOnlyViolations() int {
+	return s.readOnly
+}
+
+// ContextUsed records the tokens the latest main-agent request filled of
+// the context window.
+func (s *SessionStats) ContextUsed(tokens int) {
+	s.context = tokens
+}
+
+// ContextTokens returns the tokens the latest request filled, or 0 when no
+// usage was reported.
+func (s *SessionStats) ContextTokens() int {
+	return s.context
+}
+
+// ModelContextUsed records the tokens the latest request to model, by the
+// main agent or a sub-agent, filled of its context window.
+func (s *SessionStats) ModelContextUsed(model string, tokens int) {
+	if model != "" {
+		s.models[model] = tokens
+	}
+}
+
+// ModelContextTokens returns the tokens the latest request to model filled,
+// or 0 when none was recorded.
+func (s *SessionStats) ModelContextTokens(model string) int {
+	return s.models[model]
+}
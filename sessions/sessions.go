@@ -0,0 +1,185 @@
+// Package sessions manages the recordings -auto-record keeps: a directory
+// per project under a root (by default $XDG_DATA_HOME/viewscreen, or
+// ~/.local/share/viewscreen), pruned by a retention Policy at startup and
+// by `viewscreen sessions prune`, so recording every session does not grow
+// without bound.
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/record"
+)
+
+// Policy limits the recordings kept in a project directory. The newest
+// recordings are kept until one exceeds a limit; it and every older
+// recording are removed. A zero limit is no limit.
+type Policy struct {
+	MaxCount int
+	MaxAge   time.Duration
+	// MaxSize is the most bytes a project's recordings may take together.
+	MaxSize int64
+}
+
+// DefaultPolicy keeps a project's last 100 recordings from the last 30
+// days, up to 500 MB.
+var DefaultPolicy = Policy{MaxCount: 100, MaxAge: 30 * 24 * time.Hour, MaxSize: 500 << 20}
+
+// Validate checks that the limits are not negative.
+func (p Policy) Validate() error {
+	if p.MaxCount < 0 || p.MaxAge < 0 || p.MaxSize < 0 {
+		return errors.New("retention limits must not be negative")
+	}
+	return nil
+}
+
+// Recording is a recorded session file.
+type Recording struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+}
+
+// DefaultRoot returns the directory project recordings are kept under.
+func DefaultRoot() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "viewscreen"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "viewscreen"), nil
+}
+
+// Project names the project a directory belongs to: the base name of the
+// enclosing git repository, or of dir itself outside one.
+func Project(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			dir = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == filepath.Separator || r == ' ' || r == ':' {
+			return '_'
+		}
+		return r
+	}, filepath.Base(dir))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return "default"
+	}
+	return name
+}
+
+// NewPath returns a path for a new recording in dir, named for when it
+// starts, creating dir if needed.
+func NewPath(dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	base := now.Format("20060102-150405")
+	for n := 1; ; n++ {
+		name := base + record.Extension
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, n, record.Extension)
+		}
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return path, nil
+		}
+	}
+}
+
+// List returns the recordings in dir, newest first. A missing dir has
+// none.
+func List(dir string) ([]Recording, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var recs []Recording
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != record.Extension {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		recs = append(recs, Recording{Path: filepath.Join(dir, e.Name()), ModTime: info.ModTime(), Size: info.Size()})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].ModTime.After(recs[j].ModTime) })
+	return recs, nil
+}
+
+// expired returns the recordings, newest first, that p does not keep.
+func (p Policy) expired(recs []Recording, now time.Time) []Recording {
+	var size int64
+	for i, r := range recs {
+		size += r.Size
+		if (p.MaxCount > 0 && i >= p.MaxCount) ||
+			(p.MaxAge > 0 && now.Sub(r.ModTime) > p.MaxAge) ||
+			(p.MaxSize > 0 && size > p.MaxSize) {
+			return recs[i:]
+		}
+	}
+	return nil
+}
+
+// Prune removes the recordings in dir that p does not keep and returns
+// them.
+func (p Policy) Prune(dir string, now time.Time) ([]Recording, error) {
+	recs, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []Recording
+	var errs []error
+	for _, r := range p.expired(recs, now) {
+		if err := os.Remove(r.Path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, r)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// PruneAll prunes every project directory under root.
+func (p Policy) PruneAll(root string, now time.Time) ([]Recording, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var removed []Recording
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		recs, err := p.Prune(filepath.Join(root, e.Name()), now)
+		removed = append(removed, recs...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return removed, errors.Join(errs...)
+}
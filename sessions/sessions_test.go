@@ -0,0 +1,135 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// recording writes a recording of size bytes to dir, last modified age ago.
+func recording(t *testing.T, dir, name string, size int, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func names(recs []Recording) []string {
+	var out []string
+	for _, r := range recs {
+		out = append(out, filepath.Base(r.Path))
+	}
+	return out
+}
+
+func TestPolicy_Prune(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []string // removed
+	}{
+		{"no limits", Policy{}, nil},
+		{"count", Policy{MaxCount: 2}, []string{"c.viewscreen", "d.viewscreen"}},
+		{"age", Policy{MaxAge: 36 * time.Hour}, []string{"c.viewscreen", "d.viewscreen"}},
+		{"size", Policy{MaxSize: 250}, []string{"b.viewscreen", "c.viewscreen", "d.viewscreen"}},
+		{"tightest limit wins", Policy{MaxCount: 3, MaxAge: 12 * time.Hour}, []string{"b.viewscreen", "c.viewscreen", "d.viewscreen"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			recording(t, dir, "a.viewscreen", 200, time.Hour)
+			recording(t, dir, "b.viewscreen", 100, 24*time.Hour)
+			recording(t, dir, "c.viewscreen", 100, 48*time.Hour)
+			recording(t, dir, "d.viewscreen", 100, 72*time.Hour)
+			recording(t, dir, "notes.txt", 1000, 100*time.Hour)
+
+			removed, err := tt.policy.Prune(dir, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(removed); !slices.Equal(got, tt.want) {
+				t.Errorf("removed %v, want %v", got, tt.want)
+			}
+			left, _ := List(dir)
+			if len(left)+len(removed) != 4 {
+				t.Errorf("left %v after removing %v", names(left), names(removed))
+			}
+			if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+				t.Errorf("expected other files kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestPolicy_PruneAll(t *testing.T) {
+	root := t.TempDir()
+	for _, project := range []string{"api", "web"} {
+		dir := filepath.Join(root, project)
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		recording(t, dir, "new.viewscreen", 10, time.Hour)
+		recording(t, dir, "old.viewscreen", 10, 90*24*time.Hour)
+	}
+	removed, err := DefaultPolicy.PruneAll(root, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(removed); !slices.Equal(got, []string{"old.viewscreen", "old.viewscreen"}) {
+		t.Errorf("removed %v, want each project's old recording", got)
+	}
+	if removed, err := DefaultPolicy.PruneAll(filepath.Join(root, "missing"), now); err != nil || removed != nil {
+		t.Errorf("PruneAll(missing root) = %v, %v", removed, err)
+	}
+}
+
+func TestNewPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "project")
+	first, err := NewPath(dir, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "20260301-120000.viewscreen"); first != want {
+		t.Errorf("NewPath = %s, want %s", first, want)
+	}
+	if err := os.WriteFile(first, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := NewPath(dir, now); second != filepath.Join(dir, "20260301-120000-2.viewscreen") {
+		t.Errorf("NewPath with the name taken = %s", second)
+	}
+}
+
+func TestProject(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "my app")
+	sub := filepath.Join(root, "cmd", "server")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := Project(sub); got != "my_app" {
+		t.Errorf("Project(%s) = %q, want the repository's name", sub, got)
+	}
+}
+
+func TestDefaultRoot(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data")
+	if got, _ := DefaultRoot(); got != filepath.Join("/data", "viewscreen") {
+		t.Errorf("DefaultRoot() = %s", got)
+	}
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "/home/me")
+	if got, _ := DefaultRoot(); got != filepath.Join("/home/me", ".local", "share", "viewscreen") {
+		t.Errorf("DefaultRoot() = %s", got)
+	}
+}
@@ -0,0 +1,339 @@
+// Package sink fans a rendering pass out to several outputs at once: the
+// terminal, a plain-text log, an HTML or Markdown export and a re-emitted
+// copy of the JSON input. Renderers produce each event once and every sink
+// receives the result, so a single run can feed all of them.
+package sink
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/blame"
+	"github.com/johnnyfreeman/viewscreen/export"
+	"github.com/johnnyfreeman/viewscreen/summary"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Event is the output of rendering one input event.
+type Event struct {
+	// Raw is the input line the event was parsed from, without its newline.
+	Raw string
+	// Rendered is the streaming terminal output of the event.
+	Rendered string
+	// Entries are the timeline entries the event committed, with any
+	// reviewer annotations interleaved.
+	Entries []timeline.Entry
+}
+
+// Sink receives rendered events.
+type Sink interface {
+	// Write delivers one rendered event.
+	Write(ev Event) error
+	// Close flushes buffered output and releases the sink's resources.
+	Close() error
+}
+
+// terminalSink streams rendered output to a terminal.
+type terminalSink struct {
+	w io.Writer
+}
+
+// Terminal returns a Sink that writes each event's rendered output, followed
+// by its annotations, to w. Closing it does not close w.
+func Terminal(w io.Writer) Sink {
+	return terminalSink{w: w}
+}
+
+func (s terminalSink) Write(ev Event) error {
+	if ev.Rendered != "" {
+		if _, err := io.WriteString(s.w, ev.Rendered); err != nil {
+			return err
+		}
+	}
+	for _, entry := range ev.Entries {
+		if entry.Kind != annotations.Kind {
+			continue
+		}
+		if _, err := io.WriteString(s.w, entry.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (terminalSink) Close() error { return nil }
+
+// textSink writes timeline entries without ANSI styling.
+type textSink struct {
+	bw *bufio.Writer
+	c  io.Closer
+}
+
+// Text returns a Sink that writes the text of every timeline entry to wc
+// with ANSI styling stripped, as a plain-text log. Closing the sink closes wc.
+func Text(wc io.WriteCloser) Sink {
+	return &textSink{bw: bufio.NewWriter(wc), c: wc}
+}
+
+func (s *textSink) Write(ev Event) error {
+	for _, entry := range ev.Entries {
+		if _, err := s.bw.WriteString(ansi.Strip(entry.Text())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *textSink) Close() error {
+	return flushClose(s.bw, s.c)
+}
+
+// jsonSink re-emits the input lines.
+type jsonSink struct {
+	bw *bufio.Writer
+	c  io.Closer
+}
+
+// JSON returns a Sink that re-emits each event's raw input line to wc as
+// JSONL. Closing the sink closes wc.
+func JSON(wc io.WriteCloser) Sink {
+	return &jsonSink{bw: bufio.NewWriter(wc), c: wc}
+}
+
+func (s *jsonSink) Write(ev Event) error {
+	if ev.Raw == "" {
+		return nil
+	}
+	if _, err := s.bw.WriteString(ev.Raw); err != nil {
+		return err
+	}
+	return s.bw.WriteByte('\n')
+}
+
+func (s *jsonSink) Close() error {
+	return flushClose(s.bw, s.c)
+}
+
+// exportSink collects the timeline for a document written on close.
+type exportSink struct {
+	path    string
+	opts    export.Options
+	entries []timeline.Entry
+}
+
+// Export returns a Sink that collects timeline entries and writes them to a
+// Markdown or HTML document at path (chosen by extension) when closed. An
+// extension added with export.RegisterExporter also receives the input lines.
+func Export(path string) Sink {
+	return &exportSink{path: path}
+}
+
+// ExportSplit is like Export but, for turns > 0, splits the document into
+// chapter files of that many turns with an index page at path.
+func ExportSplit(path string, turns int) Sink {
+	return &exportSink{path: path, opts: export.Options{Split: turns}}
+}
+
+func (s *exportSink) Write(ev Event) error {
+	if ev.Raw != "" && export.Registered(export.FormatForPath(s.path)) {
+		s.opts.Events = append(s.opts.Events, ev.Raw)
+	}
+	s.entries = timeline.Merge(s.entries, ev.Entries...)
+	return nil
+}
+
+func (s *exportSink) Close() error {
+	return export.WriteFile(s.path, s.entries, s.opts)
+}
+
+// blameSink tracks file changes for a report written on close.
+type blameSink struct {
+	tracker *blame.Tracker
+	wc      io.WriteCloser
+}
+
+// Blame returns a Sink that follows the file edits in the input events and,
+// when closed, writes to wc a report of which turn last changed each region
+// of each file. Closing the sink closes wc.
+func Blame(wc io.WriteCloser) Sink {
+	return &blameSink{tracker: blame.NewTracker(), wc: wc}
+}
+
+func (s *blameSink) Write(ev Event) error {
+	if ev.Raw != "" {
+		s.tracker.Observe([]byte(ev.Raw))
+	}
+	return nil
+}
+
+func (s *blameSink) Close() error {
+	bw := bufio.NewWriter(s.wc)
+	if err := s.tracker.WriteReport(bw); err != nil {
+		s.wc.Close()
+		return err
+	}
+	return flushClose(bw, s.wc)
+}
+
+// summarySink collects session totals for a JSON summary written on close.
+type summarySink struct {
+	collector *summary.Collector
+	wc        io.WriteCloser
+}
+
+// SummaryJSON returns a Sink that follows the input events and, when closed,
+// writes to wc a JSON summary of the session's cost, tokens, duration, files
+// changed, errors, permission denials and tool counts. Closing the sink
+// closes wc.
+func SummaryJSON(wc io.WriteCloser) Sink {
+	return &summarySink{collector: summary.NewCollector(), wc: wc}
+}
+
+func (s *summarySink) Write(ev Event) error {
+	if ev.Raw != "" {
+		s.collector.Observe([]byte(ev.Raw))
+	}
+	return nil
+}
+
+func (s *summarySink) Close() error {
+	bw := bufio.NewWriter(s.wc)
+	if err := s.collector.WriteJSON(bw); err != nil {
+		s.wc.Close()
+		return err
+	}
+	return flushClose(bw, s.wc)
+}
+
+// multiSink fans events out to several sinks.
+type multiSink struct {
+	sinks []Sink
+	err   error
+}
+
+// Multi returns a Sink that delivers every event to each of sinks, skipping
+// nil ones. A sink that fails does not stop the others: Write and Close
+// return the first error seen, and Close closes every sink.
+func Multi(sinks ...Sink) Sink {
+	m := &multiSink{}
+	for _, s := range sinks {
+		if s != nil {
+			m.sinks = append(m.sinks, s)
+		}
+	}
+	return m
+}
+
+func (m *multiSink) Write(ev Event) error {
+	for _, s := range m.sinks {
+		if err := s.Write(ev); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+	return m.err
+}
+
+func (m *multiSink) Close() error {
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+	return m.err
+}
+
+// Paths names the files that Open writes. Empty paths are skipped.
+type Paths struct {
+	// Text receives a plain-text log of the session.
+	Text string
+	// JSON receives the input events, re-emitted as JSONL.
+	JSON string
+	// Export receives a Markdown or HTML document of the session.
+	Export string
+	// ExportSplit, when positive, splits the Export document into chapters
+	// of that many turns.
+	ExportSplit int
+	// Blame receives a report of the turn that last changed each edited
+	// region of each file.
+	Blame string
+	// Summary receives a JSON summary of the session.
+	Summary string
+}
+
+// Open creates the file-backed sinks named by paths and combines them with
+// Multi. The text log, JSON, blame and summary files are created immediately so
+// a bad path fails before rendering starts.
+func Open(paths Paths) (Sink, error) {
+	var sinks []Sink
+	for _, file := range []struct {
+		path string
+		sink func(io.WriteCloser) Sink
+		what string
+	}{
+		{paths.Text, Text, "text log"},
+		{paths.JSON, JSON, "JSON output"},
+		{paths.Blame, Blame, "blame report"},
+		{paths.Summary, SummaryJSON, "JSON summary"},
+	} {
+		if file.path == "" {
+			continue
+		}
+		f, err := os.Create(file.path)
+		if err != nil {
+			Multi(sinks...).Close()
+			return nil, fmt.Errorf("creating %s: %w", file.what, err)
+		}
+		sinks = append(sinks, file.sink(f))
+	}
+	if paths.Export != "" {
+		sinks = append(sinks, ExportSplit(paths.Export, paths.ExportSplit))
+	}
+	return Multi(sinks...), nil
+}
+
+// Save writes a whole session to path in the format its extension selects:
+// .jsonl and .json re-emit the raw input lines, .txt and .log write a
+// plain-text log, and anything else a document as with Export. Extensions
+// added with export.RegisterExporter always use their exporter. It reuses
+// the sinks Open creates, for saving a session after the fact.
+func Save(path string, raw []string, entries []timeline.Entry) error {
+	newSink := map[string]func(io.WriteCloser) Sink{
+		".jsonl": JSON, ".json": JSON, ".txt": Text, ".log": Text,
+	}[strings.ToLower(filepath.Ext(path))]
+	s := Export(path)
+	if newSink != nil && !export.Registered(export.FormatForPath(path)) {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", path, err)
+		}
+		s = newSink(f)
+	}
+	var err error
+	for _, line := range raw {
+		if werr := s.Write(Event{Raw: line}); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if werr := s.Write(Event{Entries: entries}); werr != nil && err == nil {
+		err = werr
+	}
+	if cerr := s.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// flushClose flushes bw and closes c, returning the first error.
+func flushClose(bw *bufio.Writer, c io.Closer) error {
+	err := bw.Flush()
+	if cerr := c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,281 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/export"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// bufferCloser is a bytes.Buffer that records whether it was closed.
+type bufferCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufferCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+// failingSink fails every write and close.
+type failingSink struct{ writes int }
+
+func (f *failingSink) Write(Event) error {
+	f.writes++
+	return errors.New("disk full")
+}
+
+func (f *failingSink) Close() error { return errors.New("close failed") }
+
+var testEvent = Event{
+	Raw:      `{"type":"assistant"}`,
+	Rendered: "\x1b[1mHello\x1b[0m\n",
+	Entries: []timeline.Entry{
+		{Kind: "assistant", Body: "\x1b[1mHello\x1b[0m\n"},
+		{Kind: annotations.Kind, Body: "review note\n", Lines: []string{"review note"}},
+	},
+}
+
+func TestTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	s := Terminal(&buf)
+	if err := s.Write(testEvent); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if want := "\x1b[1mHello\x1b[0m\nreview note\n"; buf.String() != want {
+		t.Errorf("Terminal output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestText(t *testing.T) {
+	buf := &bufferCloser{}
+	s := Text(buf)
+	if err := s.Write(testEvent); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if want := "Hello\nreview note\n"; buf.String() != want {
+		t.Errorf("Text output = %q, want %q", buf.String(), want)
+	}
+	if !buf.closed {
+		t.Error("expected Close to close the writer")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	buf := &bufferCloser{}
+	s := JSON(buf)
+	s.Write(testEvent)
+	s.Write(Event{Rendered: "no raw line"})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if want := testEvent.Raw + "\n"; buf.String() != want {
+		t.Errorf("JSON output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.md")
+	s := Export(path)
+	s.Write(testEvent)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected the export to be written only on Close")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Hello") || !strings.Contains(string(data), "review note") {
+		t.Errorf("export missing session content:\n%s", data)
+	}
+}
+
+func TestBlame(t *testing.T) {
+	buf := &bufferCloser{}
+	s := Blame(buf)
+	s.Write(Event{Raw: `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"w1","name":"Write"}]}}`})
+	s.Write(Event{Raw: `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"w1"}]},"tool_use_result":{"type":"create","filePath":"main.go","content":"package main\n"}}`})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if want := "main.go\n  1  turn 1: Write\n"; buf.String() != want {
+		t.Errorf("Blame output = %q, want %q", buf.String(), want)
+	}
+	if !buf.closed {
+		t.Error("expected Close to close the writer")
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	buf := &bufferCloser{}
+	s := SummaryJSON(buf)
+	s.Write(Event{Raw: `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"b1","name":"Bash"}]}}`})
+	s.Write(Event{Raw: `{"type":"result","num_turns":1,"total_cost_usd":0.5}`})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	var got struct {
+		NumTurns   int            `json:"num_turns"`
+		CostUSD    float64        `json:"cost_usd"`
+		ToolCounts map[string]int `json:"tool_counts"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, buf.String())
+	}
+	if got.NumTurns != 1 || got.CostUSD != 0.5 || got.ToolCounts["Bash"] != 1 {
+		t.Errorf("summary = %+v", got)
+	}
+	if !buf.closed {
+		t.Error("expected Close to close the writer")
+	}
+}
+
+func TestMulti(t *testing.T) {
+	var terminal bytes.Buffer
+	log := &bufferCloser{}
+	failing := &failingSink{}
+	s := Multi(Terminal(&terminal), nil, failing, Text(log))
+
+	if err := s.Write(testEvent); err == nil || err.Error() != "disk full" {
+		t.Errorf("Write() error = %v, want the failing sink's error", err)
+	}
+	s.Write(testEvent)
+	if failing.writes != 2 {
+		t.Errorf("failing sink got %d writes, want 2", failing.writes)
+	}
+	if err := s.Close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Close() error = %v, want the first error", err)
+	}
+
+	if strings.Count(terminal.String(), "Hello") != 2 {
+		t.Errorf("terminal sink missed events: %q", terminal.String())
+	}
+	if strings.Count(log.String(), "Hello") != 2 || !log.closed {
+		t.Errorf("text sink missed events or was not closed: %q", log.String())
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	paths := Paths{
+		Text:   filepath.Join(dir, "session.log"),
+		JSON:   filepath.Join(dir, "session.jsonl"),
+		Export: filepath.Join(dir, "session.html"),
+	}
+	s, err := Open(paths)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s.Write(testEvent)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	for path, want := range map[string]string{
+		paths.Text:   "Hello\n",
+		paths.JSON:   testEvent.Raw + "\n",
+		paths.Export: "<html",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("reading %s: %v", filepath.Base(path), err)
+			continue
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s = %q, want it to contain %q", filepath.Base(path), data, want)
+		}
+	}
+}
+
+func TestOpen_BadPath(t *testing.T) {
+	_, err := Open(Paths{Text: filepath.Join(t.TempDir(), "missing", "session.log")})
+	if err == nil || !strings.Contains(err.Error(), "creating text log") {
+		t.Errorf("Open() error = %v, want a text log error", err)
+	}
+}
+
+func TestOpen_NoPaths(t *testing.T) {
+	s, err := Open(Paths{})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := s.Write(testEvent); err != nil {
+		t.Errorf("Write() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	raw := []string{testEvent.Raw, `{"type":"result"}`}
+	for name, want := range map[string]string{
+		"session.jsonl": testEvent.Raw + "\n" + `{"type":"result"}` + "\n",
+		"session.txt":   "Hello\nreview note\n",
+		"session.md":    "review note",
+		"session.html":  "<html",
+	} {
+		path := filepath.Join(dir, name)
+		if err := Save(path, raw, testEvent.Entries); err != nil {
+			t.Fatalf("Save(%s) error = %v", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasSuffix(name, ".md") || strings.HasSuffix(name, ".html") {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s = %q, want it to contain %q", name, data, want)
+			}
+		} else if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+
+	if err := Save(filepath.Join(dir, "missing", "session.jsonl"), raw, nil); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestExport_RegisteredExporter(t *testing.T) {
+	export.RegisterExporter(".sinktest", export.ExporterFunc(func(w io.Writer, s export.Session) error {
+		_, err := io.WriteString(w, strings.Join(s.Events, "\n")+"|"+s.Entries[0].Body)
+		return err
+	}))
+
+	path := filepath.Join(t.TempDir(), "session.sinktest")
+	s := Export(path)
+	if err := s.Write(testEvent); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(Event{Raw: `{"type":"result"}`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testEvent.Raw + "\n" + `{"type":"result"}` + "|" + testEvent.Entries[0].Body
+	if string(data) != want {
+		t.Errorf("export = %q, want %q", data, want)
+	}
+}
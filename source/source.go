@@ -0,0 +1,65 @@
+// Package source builds the input side of a session: the chain of readers
+// that raw agent output passes through on its way to the renderer. It is the
+// counterpart of sink.Open, and is shared by the plain renderer and the TUI
+// so both tee, convert, tag, record, capture and check input the same way.
+package source
+
+import (
+	"errors"
+	"io"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/fixture"
+	"github.com/johnnyfreeman/viewscreen/ingest"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/strict"
+	"github.com/johnnyfreeman/viewscreen/tee"
+)
+
+// Open wraps r in the readers cfg asks for, in order: the raw -tee copy,
+// conversion from -format, the -tag preamble, the -record session file,
+// -capture-fixtures and the -strict check. The returned Closer closes each
+// of them, last first, and reports every error they return; it must be
+// closed once input is exhausted, and closing it again does nothing. If a
+// reader cannot be created, those already created are closed and the error
+// is returned.
+func Open(cfg *config.Config, r io.Reader) (io.Reader, io.Closer, error) {
+	c := &closers{}
+	fail := func(err error) (io.Reader, io.Closer, error) {
+		return nil, nil, errors.Join(err, c.Close())
+	}
+	in, closer, err := tee.Wrap(cfg.TeePath, cfg.TeeFD, r)
+	if err != nil {
+		return fail(err)
+	}
+	c.list = append(c.list, closer)
+	in = metadata.Prepend(ingest.Convert(in, cfg.Format), cfg.Tags)
+	if in, closer, err = record.TeeToFile(cfg.RecordPath, in); err != nil {
+		return fail(err)
+	}
+	c.list = append(c.list, closer)
+	if in, closer, err = fixture.TeeToDir(cfg.CaptureFixturesDir, in); err != nil {
+		return fail(err)
+	}
+	c.list = append(c.list, closer)
+	if in, closer, err = strict.TeeToLog(cfg.Strict, cfg.StrictLogPath, in); err != nil {
+		return fail(err)
+	}
+	c.list = append(c.list, closer)
+	return in, c, nil
+}
+
+// closers closes each of its Closers, last first, once.
+type closers struct {
+	list []io.Closer
+}
+
+func (c *closers) Close() error {
+	var errs []error
+	for i := len(c.list) - 1; i >= 0; i-- {
+		errs = append(errs, c.list[i].Close())
+	}
+	c.list = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package source
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		TeePath:    filepath.Join(dir, "raw.jsonl"),
+		RecordPath: filepath.Join(dir, "session.jsonl"),
+		Tags:       map[string]string{"run": "7"},
+	}
+	input := "{\"type\":\"system\"}\n"
+	r, closer, err := Open(cfg, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+
+	if !strings.Contains(string(got), `"run":"7"`) || !strings.HasSuffix(string(got), input) {
+		t.Errorf("Open() yielded %q, want the tag preamble then the input", got)
+	}
+	if raw, _ := os.ReadFile(cfg.TeePath); string(raw) != input {
+		t.Errorf("tee file = %q, want the raw input %q", raw, input)
+	}
+	if rec, _ := os.ReadFile(cfg.RecordPath); !strings.Contains(string(rec), "system") {
+		t.Errorf("record file = %q, want the input recorded", rec)
+	}
+}
+
+func TestOpen_Error(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		TeePath:    filepath.Join(dir, "raw.jsonl"),
+		RecordPath: filepath.Join(dir, "missing", "session.jsonl"),
+	}
+	if _, _, err := Open(cfg, strings.NewReader("")); err == nil {
+		t.Fatal("Open() with an uncreatable -record path succeeded")
+	}
+}
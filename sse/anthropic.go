@@ -0,0 +1,214 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Translator turns Anthropic Messages API stream events into stream-json
+// lines the way Claude Code writes them with --include-partial-messages:
+// each event becomes a stream_event line, so text is shown as it streams,
+// and each finished content block is also written as an assistant message
+// holding that block. The assistant line follows the block's
+// content_block_stop, the order the stream renderer expects so it does not
+// render the block twice.
+type Translator struct {
+	message map[string]any   // the message being streamed, from message_start
+	blocks  []map[string]any // its content blocks, by index
+	partial map[int]*strings.Builder
+}
+
+// NewTranslator returns a Translator awaiting a message.
+func NewTranslator() *Translator {
+	return &Translator{partial: make(map[int]*strings.Builder)}
+}
+
+// apiEvent is the data of a Messages API stream event.
+type apiEvent struct {
+	Type         string          `json:"type"`
+	Index        int             `json:"index"`
+	Message      map[string]any  `json:"message"`
+	ContentBlock map[string]any  `json:"content_block"`
+	Delta        json.RawMessage `json:"delta"`
+	Usage        map[string]any  `json:"usage"`
+	Error        struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// blockDelta is the delta of a content_block_delta event.
+type blockDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
+	PartialJSON string `json:"partial_json"`
+}
+
+// Event returns the stream-json lines for ev. Data that is not JSON is
+// passed through on one line, so it is reported like any malformed line.
+func (t *Translator) Event(ev Event) []string {
+	var data bytes.Buffer
+	if err := json.Compact(&data, []byte(ev.Data)); err != nil {
+		return []string{strings.ReplaceAll(ev.Data, "\n", " ")}
+	}
+	var e apiEvent
+	if json.Unmarshal(data.Bytes(), &e) != nil {
+		return []string{data.String()}
+	}
+	if e.Type == "" {
+		e.Type = ev.Name
+	}
+
+	switch e.Type {
+	case "ping":
+		return nil
+	case "error":
+		return []string{t.errorLine(e)}
+	case "message_start":
+		t.message = e.Message
+		t.blocks = nil
+		clear(t.partial)
+	case "content_block_start":
+		t.setBlock(e.Index, e.ContentBlock)
+	case "content_block_delta":
+		t.applyDelta(e.Index, e.Delta)
+	case "content_block_stop":
+		if block := t.finishBlock(e.Index); block != nil {
+			return []string{streamLine(data.Bytes()), t.assistantLine(block)}
+		}
+	case "message_delta":
+		t.applyMessageDelta(e)
+	}
+	return []string{streamLine(data.Bytes())}
+}
+
+// streamLine wraps the data of an API event as a stream_event line.
+func streamLine(data json.RawMessage) string {
+	line, _ := json.Marshal(struct {
+		Type  string          `json:"type"`
+		Event json.RawMessage `json:"event"`
+	}{"stream_event", data})
+	return string(line)
+}
+
+func (t *Translator) setBlock(index int, block map[string]any) {
+	if index < 0 || block == nil {
+		return
+	}
+	for len(t.blocks) <= index {
+		t.blocks = append(t.blocks, nil)
+	}
+	t.blocks[index] = block
+}
+
+func (t *Translator) block(index int) map[string]any {
+	if index < 0 || index >= len(t.blocks) {
+		return nil
+	}
+	return t.blocks[index]
+}
+
+func (t *Translator) applyDelta(index int, raw json.RawMessage) {
+	block := t.block(index)
+	var d blockDelta
+	if block == nil || json.Unmarshal(raw, &d) != nil {
+		return
+	}
+	switch d.Type {
+	case "text_delta":
+		block["text"] = stringField(block, "text") + d.Text
+	case "thinking_delta":
+		block["thinking"] = stringField(block, "thinking") + d.Thinking
+	case "signature_delta":
+		block["signature"] = d.Signature
+	case "input_json_delta":
+		b := t.partial[index]
+		if b == nil {
+			b = &strings.Builder{}
+			t.partial[index] = b
+		}
+		b.WriteString(d.PartialJSON)
+	}
+}
+
+// finishBlock returns the block at index with its streamed tool input
+// decoded, or nil if no such block was started. Input that does not parse
+// is kept as the streamed text.
+func (t *Translator) finishBlock(index int) map[string]any {
+	block := t.block(index)
+	b := t.partial[index]
+	if block == nil || b == nil {
+		return block
+	}
+	delete(t.partial, index)
+	var input any
+	if err := json.Unmarshal([]byte(b.String()), &input); err != nil {
+		input = map[string]any{"partial_json": b.String()}
+	}
+	block["input"] = input
+	return block
+}
+
+func (t *Translator) applyMessageDelta(e apiEvent) {
+	if t.message == nil {
+		t.message = map[string]any{}
+	}
+	var delta map[string]any
+	if json.Unmarshal(e.Delta, &delta) == nil {
+		for k, v := range delta {
+			t.message[k] = v
+		}
+	}
+	if len(e.Usage) > 0 {
+		usage, _ := t.message["usage"].(map[string]any)
+		if usage == nil {
+			usage = map[string]any{}
+			t.message["usage"] = usage
+		}
+		for k, v := range e.Usage {
+			usage[k] = v
+		}
+	}
+}
+
+// assistantLine returns an assistant line for block: the message being
+// streamed with block as its only content.
+func (t *Translator) assistantLine(block map[string]any) string {
+	message := map[string]any{"role": "assistant"}
+	for k, v := range t.message {
+		message[k] = v
+	}
+	message["content"] = []map[string]any{block}
+	line, _ := json.Marshal(map[string]any{"type": "assistant", "message": message})
+	return string(line)
+}
+
+// errorLine reports a stream error event the way Claude Code reports a
+// failed API request: as an assistant message naming the error.
+func (t *Translator) errorLine(e apiEvent) string {
+	kind := e.Error.Type
+	if kind == "" {
+		kind = "api_error"
+	}
+	text := "API Error: " + kind
+	if e.Error.Message != "" {
+		text += ": " + e.Error.Message
+	}
+	line, _ := json.Marshal(map[string]any{
+		"type":  "assistant",
+		"error": kind,
+		"message": map[string]any{
+			"role":    "assistant",
+			"content": []map[string]any{{"type": "text", "text": text}},
+		},
+	})
+	return string(line)
+}
+
+func stringField(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
@@ -0,0 +1,119 @@
+// Package sse reads Server-Sent Events streams, the framing the Anthropic
+// Messages API streams responses in, and converts them to the stream-json
+// lines viewscreen renders. Convert detects the format, so a raw API
+// response can be piped to viewscreen like Claude Code's output:
+//
+//	curl -sN https://api.anthropic.com/v1/messages ... | viewscreen
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+)
+
+// Event is one dispatched Server-Sent Event.
+type Event struct {
+	// Name is the event field ("message_start", ...), or "" when the
+	// stream does not name its events.
+	Name string
+	// Data is the event's data lines joined by newlines.
+	Data string
+}
+
+// Reader splits a Server-Sent Events stream into events.
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+// NewReader returns a Reader of the events in r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{scanner: jsonl.NewScanner(r)}
+}
+
+// Next returns the next event that carries data. It returns io.EOF at the
+// end of the stream; an event cut off by the end is still returned.
+func (r *Reader) Next() (Event, error) {
+	var ev Event
+	var data []string
+	for r.scanner.Scan() {
+		line := strings.TrimSuffix(r.scanner.Text(), "\r")
+		if line == "" {
+			if data != nil {
+				ev.Data = strings.Join(data, "\n")
+				return ev, nil
+			}
+			ev = Event{}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Name = value
+		case "data":
+			data = append(data, value)
+		}
+		// Comments (":" lines), id and retry carry nothing to render.
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	if data != nil {
+		ev.Data = strings.Join(data, "\n")
+		return ev, nil
+	}
+	return Event{}, io.EOF
+}
+
+// IsStreamLine reports whether line is a Server-Sent Events field or
+// comment rather than a stream-json line.
+func IsStreamLine(line string) bool {
+	for _, field := range []string{"event:", "data:", "id:", "retry:", ":"} {
+		if strings.HasPrefix(line, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Convert returns a reader of the stream-json lines in r. Input whose
+// first non-blank line is a Server-Sent Events field is translated (see
+// Translator); anything else is passed through unchanged.
+func Convert(r io.Reader) io.Reader {
+	return jsonl.Sniff(r, func(line string, in io.Reader) io.Reader {
+		if IsStreamLine(line) {
+			return &converter{events: NewReader(in), translate: NewTranslator()}
+		}
+		return in
+	})
+}
+
+// converter reads stream-json lines translated from an event stream.
+type converter struct {
+	events    *Reader
+	translate *Translator
+	out       bytes.Buffer
+	err       error
+}
+
+func (c *converter) Read(p []byte) (int, error) {
+	for c.out.Len() == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		ev, err := c.events.Next()
+		if err != nil {
+			c.err = err
+			continue
+		}
+		for _, line := range c.translate.Event(ev) {
+			c.out.WriteString(line)
+			c.out.WriteByte('\n')
+		}
+	}
+	return c.out.Read(p)
+}
@@ -0,0 +1,141 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader(": keep-alive\r\nevent: a\r\ndata: one\r\ndata:two\r\n\r\nid: 7\nretry: 10\n\nevent: b\ndata: {}"))
+
+	want := []Event{{Name: "a", Data: "one\ntwo"}, {Name: "b", Data: "{}"}}
+	for _, w := range want {
+		ev, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev != w {
+			t.Errorf("Next() = %+v, want %+v", ev, w)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestConvert_PassesStreamJSONThrough(t *testing.T) {
+	input := "\n{\"type\":\"system\"}\n{\"type\":\"result\"}\n"
+	got, err := io.ReadAll(Convert(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != input {
+		t.Errorf("Convert changed stream-json input: %q", got)
+	}
+
+	got, _ = io.ReadAll(Convert(strings.NewReader("")))
+	if len(got) != 0 {
+		t.Errorf("Convert(empty) = %q", got)
+	}
+
+	boom := errors.New("boom")
+	if _, err := io.ReadAll(Convert(io.MultiReader(strings.NewReader("{}"), iotest.ErrReader(boom)))); !errors.Is(err, boom) {
+		t.Errorf("read error = %v, want %v", err, boom)
+	}
+}
+
+func convertFixture(t *testing.T) []map[string]any {
+	t.Helper()
+	f, err := os.Open("../testdata/anthropic_api.sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out, err := io.ReadAll(Convert(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestConvert_Anthropic(t *testing.T) {
+	lines := convertFixture(t)
+	// 11 events without the ping, and an assistant message after each of
+	// the two blocks stops.
+	if len(lines) != 13 {
+		t.Fatalf("got %d lines, want 13", len(lines))
+	}
+	var messages []string
+	for i, line := range lines {
+		if line["type"] == "stream_event" {
+			continue
+		}
+		if line["type"] != "assistant" {
+			t.Fatalf("line %d = %v, want a stream_event or assistant line", i, line)
+		}
+		if ev := lines[i-1]["event"].(map[string]any); ev["type"] != "content_block_stop" {
+			t.Errorf("assistant line %d follows %v, want content_block_stop", i, ev)
+		}
+		msg, _ := json.Marshal(line["message"])
+		messages = append(messages, string(msg))
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d assistant lines, want 2", len(messages))
+	}
+	for i, want := range [][]string{
+		{`"content":[{"text":"Let me check the weather.","type":"text"}]`, `"id":"msg_01"`, `"model":"claude-sonnet-4-5"`, `"usage":{"input_tokens":25,"output_tokens":1}`},
+		{`"content":[{"id":"toolu_01","input":{"location":"Paris"},"name":"get_weather","type":"tool_use"}]`},
+	} {
+		for _, w := range want {
+			if !strings.Contains(messages[i], w) {
+				t.Errorf("message %d missing %s:\n%s", i, w, messages[i])
+			}
+		}
+	}
+}
+
+func TestTranslator_Error(t *testing.T) {
+	lines := NewTranslator().Event(Event{Name: "error", Data: `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`})
+	if len(lines) != 1 {
+		t.Fatalf("got %v", lines)
+	}
+	var ev struct {
+		Type    string `json:"type"`
+		Error   string `json:"error"`
+		Message struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != "assistant" || ev.Error != "overloaded_error" || ev.Message.Content[0].Text != "API Error: overloaded_error: Overloaded" {
+		t.Errorf("error line = %s", lines[0])
+	}
+}
+
+func TestTranslator_MalformedData(t *testing.T) {
+	got := NewTranslator().Event(Event{Data: "not\njson"})
+	if len(got) != 1 || got[0] != "not json" {
+		t.Errorf("Event(malformed) = %q, want the data on one line", got)
+	}
+	got = NewTranslator().Event(Event{Name: "content_block_delta", Data: "{\n\"type\": \"content_block_delta\", \"index\": 3}"})
+	if len(got) != 1 || got[0] != `{"type":"stream_event","event":{"type":"content_block_delta","index":3}}` {
+		t.Errorf("Event(multi-line data) = %q", got)
+	}
+}
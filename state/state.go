@@ -2,12 +2,16 @@ package state
 
 import (
 	"encoding/json"
+	"slices"
 	"time"
 
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/perf"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/system"
 	"github.com/johnnyfreeman/viewscreen/timeline"
+	"github.com/johnnyfreeman/viewscreen/tools"
 )
 
 // Todo represents a tracked task item from TodoWrite tool results.
@@ -55,6 +59,10 @@ type State struct {
 	TurnCount int
 	TotalCost float64
 
+	// CumulativeCost adds up the cost of every session in the stream. It
+	// equals TotalCost unless the stream holds more than one result event.
+	CumulativeCost float64
+
 	// Todos from TodoWrite results
 	Todos []Todo
 
@@ -63,6 +71,11 @@ type State struct {
 	CurrentToolInput string
 	ToolInProgress   bool
 
+	// CurrentToolOutput is the running tool's output streamed so far, for
+	// the TUI's tool output pane. Claude does not stream tool output, so it
+	// stays empty until a Codex command prints something.
+	CurrentToolOutput string
+
 	// Usage tracking
 	InputTokens  int
 	OutputTokens int
@@ -74,6 +87,12 @@ type State struct {
 	// them out separately, so this stays zero for Claude streams.
 	ReasoningTokens int
 
+	// ContextTokens is how much of the context window the latest main-agent
+	// request filled, and ContextWindow the window's size when a result
+	// event reported it (0 until then).
+	ContextTokens int
+	ContextWindow int
+
 	// Session timing
 	StartTime time.Time
 
@@ -81,16 +100,39 @@ type State struct {
 	IsError       bool
 	DurationMS    int
 	DurationAPIMS int
+
+	// RenderTimings collects how long each event kind takes to render.
+	// Nil disables collection.
+	RenderTimings *perf.Timings
+
+	// ToolTimes and Stats are the per-tool timings and session counts the
+	// details modal summarizes. The event processor points them at its
+	// renderers' collectors; nil leaves them out of the modal.
+	ToolTimes *tools.ToolTimer
+	Stats     *result.SessionStats
+	// Lanes holds the concurrency lanes of the running tool calls, which
+	// the sidebar maps to their tools. Nil hides the mapping.
+	Lanes *tools.Lanes
 }
 
 // NewState creates a new empty state
 func NewState() *State {
 	return &State{
-		Todos:     make([]Todo, 0),
-		StartTime: time.Now(),
+		Todos:         make([]Todo, 0),
+		StartTime:     time.Now(),
+		RenderTimings: perf.NewTimings(),
 	}
 }
 
+// Clone returns a copy of the state that shares nothing mutable with it
+// except the RenderTimings, ToolTimes, Stats and Lanes collectors.
+func (s *State) Clone() *State {
+	c := *s
+	c.Agents = slices.Clone(s.Agents)
+	c.Todos = slices.Clone(s.Todos)
+	return &c
+}
+
 // Elapsed returns the duration since the session started.
 func (s *State) Elapsed() time.Duration {
 	return time.Since(s.StartTime)
@@ -105,6 +147,24 @@ func (s *State) ReportsCost() bool {
 	return s.Agent != config.AgentCodex
 }
 
+// ContextUsage returns the tokens filling the context window and the
+// window's size. Until a result event reports the window, Claude sessions
+// assume their model's default; for other agents the window is unknown and
+// both are 0, so the gauge stays hidden.
+func (s *State) ContextUsage() (used, window int) {
+	if s.ContextTokens == 0 {
+		return 0, 0
+	}
+	window = s.ContextWindow
+	if window == 0 && s.ReportsCost() {
+		window = ctxwindow.WindowFor(s.Model)
+	}
+	if window == 0 {
+		return 0, 0
+	}
+	return s.ContextTokens, window
+}
+
 // CostRate returns the cost per minute based on total cost and elapsed time.
 // Returns 0 if elapsed time is less than 1 second (to avoid division by zero
 // and noisy early values).
@@ -145,6 +205,15 @@ func (s *State) IncrementTurnCount() {
 	s.TurnCount++
 }
 
+// TokensPerTurn returns the average input and output tokens used per turn,
+// or 0 before the first turn.
+func (s *State) TokensPerTurn() int {
+	if s.TurnCount <= 0 {
+		return 0
+	}
+	return (s.InputTokens + s.OutputTokens) / s.TurnCount
+}
+
 // AccumulateUsage adds per-turn token usage to the running totals.
 // This is called for each assistant message to provide real-time tracking.
 func (s *State) AccumulateUsage(input, output, cacheCreated, cacheRead int) {
@@ -189,6 +258,7 @@ func (s *State) ApplyPatch(p timeline.StatePatch) {
 	if p.TotalCost != nil {
 		s.TotalCost = *p.TotalCost
 	}
+	s.CumulativeCost += p.AddCost
 	if p.ReplaceTodos {
 		s.Todos = make([]Todo, len(p.Todos))
 		for i, todo := range p.Todos {
@@ -200,6 +270,7 @@ func (s *State) ApplyPatch(p timeline.StatePatch) {
 	}
 	if p.CurrentActivity != nil {
 		s.SetCurrentTool(p.CurrentActivity.Name, p.CurrentActivity.Input)
+		s.CurrentToolOutput = p.CurrentActivity.Output
 	}
 	if p.AddUsage != nil {
 		s.AccumulateUsage(p.AddUsage.InputTokens, p.AddUsage.OutputTokens, p.AddUsage.CacheCreated, p.AddUsage.CacheRead)
@@ -220,6 +291,12 @@ func (s *State) ApplyPatch(p timeline.StatePatch) {
 	if p.ReasoningTokens != nil {
 		s.ReasoningTokens = *p.ReasoningTokens
 	}
+	if p.ContextTokens != nil {
+		s.ContextTokens = *p.ContextTokens
+	}
+	if p.ContextWindow != nil && *p.ContextWindow > 0 {
+		s.ContextWindow = *p.ContextWindow
+	}
 	if p.IsError != nil {
 		s.IsError = *p.IsError
 	}
@@ -242,6 +319,7 @@ func (s *State) SetCurrentTool(name, input string) {
 func (s *State) ClearCurrentTool() {
 	s.CurrentTool = ""
 	s.CurrentToolInput = ""
+	s.CurrentToolOutput = ""
 	s.ToolInProgress = false
 }
 
@@ -288,6 +366,7 @@ func (s *State) UpdateFromToolUseResult(toolUseResult json.RawMessage) {
 func (s *State) UpdateFromResultEvent(event result.Event) {
 	s.TurnCount = event.NumTurns
 	s.TotalCost = event.TotalCostUSD
+	s.CumulativeCost += event.TotalCostUSD
 	s.IsError = event.IsError
 	s.DurationMS = event.DurationMS
 	s.DurationAPIMS = event.DurationAPIMS
@@ -8,6 +8,7 @@ import (
 	"github.com/johnnyfreeman/viewscreen/config"
 	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/system"
+	"github.com/johnnyfreeman/viewscreen/timeline"
 )
 
 func TestNewState(t *testing.T) {
@@ -310,6 +311,71 @@ func TestState_CostRate(t *testing.T) {
 	})
 }
 
+func TestState_CumulativeCost(t *testing.T) {
+	s := NewState()
+	s.UpdateFromResultEvent(result.Event{TotalCostUSD: 0.25})
+	s.ApplyPatch(timeline.StatePatch{TotalCost: timeline.FloatPtr(0.5), AddCost: 0.5})
+	if s.TotalCost != 0.5 {
+		t.Errorf("TotalCost = %g, want the last session's 0.5", s.TotalCost)
+	}
+	if s.CumulativeCost != 0.75 {
+		t.Errorf("CumulativeCost = %g, want both sessions' 0.75", s.CumulativeCost)
+	}
+}
+
+func TestState_ContextUsage(t *testing.T) {
+	s := NewState()
+	if used, window := s.ContextUsage(); used != 0 || window != 0 {
+		t.Errorf("ContextUsage() = %d, %d before any usage, want 0, 0", used, window)
+	}
+
+	s.Model = "claude-opus-4-1"
+	s.ApplyPatch(timeline.StatePatch{ContextTokens: timeline.IntPtr(50_000)})
+	if used, window := s.ContextUsage(); used != 50_000 || window != 200_000 {
+		t.Errorf("ContextUsage() = %d, %d, want the model's default window", used, window)
+	}
+
+	s.ApplyPatch(timeline.StatePatch{ContextWindow: timeline.IntPtr(1_000_000)})
+	if _, window := s.ContextUsage(); window != 1_000_000 {
+		t.Errorf("window = %d, want the reported 1000000", window)
+	}
+
+	s = NewState()
+	s.Agent = "codex"
+	s.ApplyPatch(timeline.StatePatch{ContextTokens: timeline.IntPtr(50_000)})
+	if _, window := s.ContextUsage(); window != 0 {
+		t.Errorf("window = %d for codex, want 0 (unknown)", window)
+	}
+}
+
+func TestState_Clone(t *testing.T) {
+	s := NewState()
+	s.Agents = []string{"explorer"}
+	s.Todos = []Todo{{Content: "one", Status: "pending"}}
+	c := s.Clone()
+	c.Agents[0] = "planner"
+	c.Todos[0].Status = "completed"
+	c.TurnCount = 3
+	if s.Agents[0] != "explorer" || s.Todos[0].Status != "pending" || s.TurnCount != 0 {
+		t.Errorf("expected changes to the clone to leave the state alone, got %+v", s)
+	}
+	if c.RenderTimings != s.RenderTimings {
+		t.Error("expected the clone to share the render timings")
+	}
+}
+
+func TestState_TokensPerTurn(t *testing.T) {
+	s := NewState()
+	s.InputTokens, s.OutputTokens = 900, 300
+	if got := s.TokensPerTurn(); got != 0 {
+		t.Errorf("TokensPerTurn() = %d before any turn, want 0", got)
+	}
+	s.TurnCount = 3
+	if got := s.TokensPerTurn(); got != 400 {
+		t.Errorf("TokensPerTurn() = %d, want 400", got)
+	}
+}
+
 func TestState_TodoProgress(t *testing.T) {
 	t.Run("empty todos", func(t *testing.T) {
 		s := NewState()
@@ -0,0 +1,117 @@
+// Package stats derives the aggregate metrics `viewscreen stats` prints for
+// a transcript: its outcome, turns, tool calls, error rate, cache hit ratio, cost per
+// model and wall versus API time. The raw totals come from summary.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/summary"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+// Stats are the aggregate metrics of one session.
+type Stats struct {
+	Outcome    outcome.Outcome `json:"outcome"`
+	Turns      int             `json:"turns"`
+	ToolCalls  int             `json:"tool_calls"`
+	ToolCounts map[string]int  `json:"tool_counts"`
+	ToolErrors int             `json:"tool_errors"`
+	// ErrorRate is ToolErrors as a fraction of ToolCalls.
+	ErrorRate float64 `json:"error_rate"`
+	// CacheHitRatio is the fraction of input tokens read from the prompt
+	// cache.
+	CacheHitRatio float64            `json:"cache_hit_ratio"`
+	CostUSD       float64            `json:"cost_usd"`
+	CostByModel   map[string]float64 `json:"cost_by_model"`
+	WallMS        int                `json:"wall_ms"`
+	APIMS         int                `json:"api_ms"`
+}
+
+// FromSummary computes the metrics of the session s summarizes.
+func FromSummary(s summary.Summary) Stats {
+	st := Stats{
+		Outcome:     s.Outcome,
+		Turns:       s.NumTurns,
+		ToolCounts:  s.ToolCounts,
+		ToolErrors:  s.ToolErrors,
+		CostUSD:     s.CostUSD,
+		CostByModel: s.CostByModel,
+		WallMS:      s.DurationMS,
+		APIMS:       s.DurationAPIMS,
+	}
+	if st.ToolCounts == nil {
+		st.ToolCounts = map[string]int{}
+	}
+	if st.CostByModel == nil {
+		st.CostByModel = map[string]float64{}
+	}
+	for _, n := range st.ToolCounts {
+		st.ToolCalls += n
+	}
+	if st.ToolCalls > 0 {
+		st.ErrorRate = float64(st.ToolErrors) / float64(st.ToolCalls)
+	}
+	u := s.Usage
+	if input := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens; input > 0 {
+		st.CacheHitRatio = float64(u.CacheReadInputTokens) / float64(input)
+	}
+	return st
+}
+
+// WriteJSON writes st as indented JSON.
+func WriteJSON(w io.Writer, st Stats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(st)
+}
+
+// WriteTable writes st as aligned tables: the totals, then calls per tool
+// and cost per model, each sorted by name.
+func WriteTable(w io.Writer, st Stats) error {
+	nf := numfmt.Default()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "outcome\t%s\n", st.Outcome)
+	fmt.Fprintf(tw, "turns\t%s\n", nf.Int(st.Turns))
+	fmt.Fprintf(tw, "tool calls\t%s\n", nf.Int(st.ToolCalls))
+	fmt.Fprintf(tw, "tool errors\t%s (%s)\n", nf.Int(st.ToolErrors), percent(st.ErrorRate))
+	fmt.Fprintf(tw, "cache hit ratio\t%s\n", percent(st.CacheHitRatio))
+	fmt.Fprintf(tw, "cost\t%s\n", nf.Cost(st.CostUSD, 4))
+	fmt.Fprintf(tw, "wall time\t%s\n", tools.FormatElapsed(time.Duration(st.WallMS)*time.Millisecond))
+	fmt.Fprintf(tw, "api time\t%s\n", tools.FormatElapsed(time.Duration(st.APIMS)*time.Millisecond))
+
+	if len(st.ToolCounts) > 0 {
+		fmt.Fprintln(tw, "\ntool\tcalls")
+		for _, name := range sortedKeys(st.ToolCounts) {
+			fmt.Fprintf(tw, "%s\t%s\n", name, nf.Int(st.ToolCounts[name]))
+		}
+	}
+	if len(st.CostByModel) > 0 {
+		fmt.Fprintln(tw, "\nmodel\tcost")
+		for _, model := range sortedKeys(st.CostByModel) {
+			fmt.Fprintf(tw, "%s\t%s\n", model, nf.Cost(st.CostByModel[model], 4))
+		}
+	}
+	return tw.Flush()
+}
+
+// percent formats a fraction as a percentage with one decimal.
+func percent(f float64) string {
+	return numfmt.Default().Float(f*100, 1) + "%"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
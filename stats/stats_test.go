@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/summary"
+)
+
+func sample() summary.Summary {
+	return summary.Summary{
+		NumTurns:      3,
+		DurationMS:    65000,
+		DurationAPIMS: 4200,
+		CostUSD:       0.25,
+		CostByModel:   map[string]float64{"claude-opus": 0.2, "claude-haiku": 0.05},
+		Usage:         summary.Usage{InputTokens: 10, CacheCreationInputTokens: 30, CacheReadInputTokens: 60},
+		ToolErrors:    1,
+		ToolCounts:    map[string]int{"Edit": 1, "Bash": 3},
+		Outcome:       outcome.Success,
+	}
+}
+
+func TestFromSummary(t *testing.T) {
+	st := FromSummary(sample())
+	if st.ToolCalls != 4 {
+		t.Errorf("ToolCalls = %d, want 4", st.ToolCalls)
+	}
+	if st.ErrorRate != 0.25 {
+		t.Errorf("ErrorRate = %v, want 0.25", st.ErrorRate)
+	}
+	if st.CacheHitRatio != 0.6 {
+		t.Errorf("CacheHitRatio = %v, want 0.6", st.CacheHitRatio)
+	}
+	if st.WallMS != 65000 || st.APIMS != 4200 {
+		t.Errorf("WallMS, APIMS = %d, %d, want 65000, 4200", st.WallMS, st.APIMS)
+	}
+}
+
+func TestFromSummary_Empty(t *testing.T) {
+	st := FromSummary(summary.Summary{})
+	if st.ErrorRate != 0 || st.CacheHitRatio != 0 {
+		t.Errorf("expected zero ratios without calls or tokens, got %+v", st)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, st); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["tool_counts"] == nil || got["cost_by_model"] == nil {
+		t.Errorf("expected empty objects rather than null, got %s", buf.String())
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTable(&buf, FromSummary(sample())); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"outcome          success\n",
+		"turns            3\n",
+		"tool errors      1 (25.0%)\n",
+		"cache hit ratio  60.0%\n",
+		"cost             $0.2500\n",
+		"wall time        1m05s\n",
+		"api time         4.2s\n",
+		"Bash  3\nEdit  1\n",
+		"claude-haiku  $0.0500\nclaude-opus   $0.2000\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -14,6 +14,7 @@ const (
 	BlockNone BlockType = iota
 	BlockText
 	BlockToolUse
+	BlockThinking
 )
 
 // String returns the string representation of a BlockType
@@ -23,6 +24,8 @@ func (b BlockType) String() string {
 		return "text"
 	case BlockToolUse:
 		return "tool_use"
+	case BlockThinking:
+		return "thinking"
 	default:
 		return "none"
 	}
@@ -35,6 +38,8 @@ type BlockState struct {
 	blockType  BlockType
 	blockIndex int
 	toolName   string
+	toolID     string
+	redacted   bool
 	textBuf    strings.Builder
 	toolBuf    strings.Builder
 }
@@ -62,6 +67,12 @@ func (s *BlockState) ToolName() string {
 	return s.toolName
 }
 
+// ToolID returns the current tool_use block's ID (only valid when Type is
+// BlockToolUse)
+func (s *BlockState) ToolID() string {
+	return s.toolID
+}
+
 // InTextBlock returns true if currently processing a text block
 func (s *BlockState) InTextBlock() bool {
 	return s.blockType == BlockText
@@ -72,6 +83,21 @@ func (s *BlockState) InToolUseBlock() bool {
 	return s.blockType == BlockToolUse
 }
 
+// InThinkingBlock returns true if currently processing a thinking or
+// redacted_thinking block
+func (s *BlockState) InThinkingBlock() bool {
+	return s.blockType == BlockThinking
+}
+
+// ThinkingBlock returns the current thinking block with its accumulated
+// text (only valid when Type is BlockThinking)
+func (s *BlockState) ThinkingBlock() types.ContentBlock {
+	if s.redacted {
+		return types.ContentBlock{Type: "redacted_thinking"}
+	}
+	return types.ContentBlock{Type: "thinking", Thinking: s.textBuf.String()}
+}
+
 // TextContent returns the accumulated text content
 func (s *BlockState) TextContent() string {
 	return s.textBuf.String()
@@ -88,6 +114,8 @@ func (s *BlockState) StartBlock(index int, contentBlock json.RawMessage) bool {
 	s.blockIndex = index
 	s.blockType = BlockNone
 	s.toolName = ""
+	s.toolID = ""
+	s.redacted = false
 
 	if len(contentBlock) == 0 {
 		return false
@@ -106,8 +134,15 @@ func (s *BlockState) StartBlock(index int, contentBlock json.RawMessage) bool {
 	case "tool_use":
 		s.blockType = BlockToolUse
 		s.toolName = block.Name
+		s.toolID = block.ID
 		s.toolBuf.Reset()
 		return true
+	case "thinking", "redacted_thinking":
+		s.blockType = BlockThinking
+		s.redacted = block.Type == "redacted_thinking"
+		s.textBuf.Reset()
+		s.textBuf.WriteString(block.Thinking)
+		return true
 	}
 
 	return false
@@ -123,6 +158,16 @@ func (s *BlockState) AccumulateText(text string) bool {
 	return true
 }
 
+// AccumulateThinking adds thought text to the text buffer.
+// Returns true if text was accumulated (i.e., we're in a thinking block).
+func (s *BlockState) AccumulateThinking(text string) bool {
+	if s.blockType != BlockThinking || s.redacted {
+		return false
+	}
+	s.textBuf.WriteString(text)
+	return true
+}
+
 // AccumulateToolInput adds JSON to the tool input buffer.
 // Returns true if input was accumulated (i.e., we're in a tool_use block).
 func (s *BlockState) AccumulateToolInput(partialJSON string) bool {
@@ -151,6 +196,8 @@ func (s *BlockState) Reset() {
 	s.blockType = BlockNone
 	s.blockIndex = -1
 	s.toolName = ""
+	s.toolID = ""
+	s.redacted = false
 }
 
 // ResetMessage resets state for a new message (on message_stop).
@@ -15,6 +15,7 @@ func TestBlockType_String(t *testing.T) {
 		{BlockNone, "none"},
 		{BlockText, "text"},
 		{BlockToolUse, "tool_use"},
+		{BlockThinking, "thinking"},
 	}
 
 	for _, tt := range tests {
@@ -91,6 +92,20 @@ func TestBlockState_StartBlock_Text(t *testing.T) {
 	}
 }
 
+func TestBlockState_StartBlock_ToolUseID(t *testing.T) {
+	s := NewBlockState()
+
+	s.StartBlock(0, json.RawMessage(`{"type":"tool_use","id":"toolu_1","name":"Bash"}`))
+	if s.ToolID() != "toolu_1" {
+		t.Errorf("expected tool ID to be 'toolu_1', got %q", s.ToolID())
+	}
+
+	s.StartBlock(1, makeTestContentBlock("text", ""))
+	if s.ToolID() != "" {
+		t.Errorf("expected tool ID to be cleared, got %q", s.ToolID())
+	}
+}
+
 func TestBlockState_StartBlock_ToolUse(t *testing.T) {
 	s := NewBlockState()
 
@@ -116,6 +131,43 @@ func TestBlockState_StartBlock_ToolUse(t *testing.T) {
 	}
 }
 
+func TestBlockState_StartBlock_Thinking(t *testing.T) {
+	s := NewBlockState()
+
+	if !s.StartBlock(0, makeTestContentBlock("thinking", "")) {
+		t.Error("expected StartBlock to return true for thinking block")
+	}
+	if !s.InThinkingBlock() {
+		t.Error("expected InThinkingBlock to be true")
+	}
+	if !s.AccumulateThinking("pondering") {
+		t.Error("expected AccumulateThinking to return true")
+	}
+	if got := s.ThinkingBlock(); got.Type != "thinking" || got.Thinking != "pondering" {
+		t.Errorf("ThinkingBlock() = %+v", got)
+	}
+	if s.AccumulateText("text") {
+		t.Error("expected AccumulateText to be ignored in a thinking block")
+	}
+}
+
+func TestBlockState_StartBlock_RedactedThinking(t *testing.T) {
+	s := NewBlockState()
+
+	if !s.StartBlock(0, makeTestContentBlock("redacted_thinking", "")) {
+		t.Error("expected StartBlock to return true for redacted_thinking block")
+	}
+	if !s.InThinkingBlock() {
+		t.Error("expected InThinkingBlock to be true")
+	}
+	if s.AccumulateThinking("ignored") {
+		t.Error("expected AccumulateThinking to be ignored in a redacted block")
+	}
+	if got := s.ThinkingBlock(); got.Type != "redacted_thinking" {
+		t.Errorf("ThinkingBlock().Type = %q, want redacted_thinking", got.Type)
+	}
+}
+
 func TestBlockState_StartBlock_EmptyContentBlock(t *testing.T) {
 	s := NewBlockState()
 
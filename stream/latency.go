@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+const (
+	// latencyBuckets is the most bars a latency sparkline has.
+	latencyBuckets = 24
+	// minLatencySpan is how long a block must take to stream before its
+	// sparkline is shown. Blocks read from a file arrive all at once, so
+	// their timings say nothing about the generation.
+	minLatencySpan = 250 * time.Millisecond
+)
+
+// arrival is when a text delta arrived and how many bytes it carried.
+type arrival struct {
+	at    time.Time
+	bytes int
+}
+
+// latency records when the text deltas of a streaming block arrive, to
+// chart how fast the block was generated.
+type latency struct {
+	arrivals []arrival
+}
+
+// Record notes a delta of n bytes arriving at t.
+func (l *latency) Record(t time.Time, n int) {
+	l.arrivals = append(l.arrivals, arrival{at: t, bytes: n})
+}
+
+// Reset forgets the recorded deltas.
+func (l *latency) Reset() {
+	l.arrivals = l.arrivals[:0]
+}
+
+// Span returns the time from the first delta to the last.
+func (l *latency) Span() time.Duration {
+	if len(l.arrivals) < 2 {
+		return 0
+	}
+	return l.arrivals[len(l.arrivals)-1].at.Sub(l.arrivals[0].at)
+}
+
+// LongestPause returns the longest wait between two deltas.
+func (l *latency) LongestPause() time.Duration {
+	var longest time.Duration
+	for i := 1; i < len(l.arrivals); i++ {
+		longest = max(longest, l.arrivals[i].at.Sub(l.arrivals[i-1].at))
+	}
+	return longest
+}
+
+// Speeds divides the span into up to n equal intervals and returns the
+// bytes per second that arrived in each. A stall shows as an interval
+// with nothing in it.
+func (l *latency) Speeds(n int) []float64 {
+	span := l.Span()
+	if span <= 0 {
+		return nil
+	}
+	n = min(n, len(l.arrivals)-1)
+	width := span / time.Duration(n)
+	speeds := make([]float64, n)
+	start := l.arrivals[0].at
+	// The first delta opens the span, so the bytes it carried were
+	// generated before it.
+	for _, a := range l.arrivals[1:] {
+		i := min(int(a.at.Sub(start)/width), n-1)
+		speeds[i] += float64(a.bytes)
+	}
+	for i := range speeds {
+		speeds[i] /= width.Seconds()
+	}
+	return speeds
+}
+
+// Summary returns a sparkline of the block's generation speed with its
+// average rate in tokens per second (at ~4 bytes per token) and its longest
+// pause, or "" when the block streamed too quickly to say.
+func (l *latency) Summary() string {
+	span := l.Span()
+	if span < minLatencySpan {
+		return ""
+	}
+	var bytes int
+	for _, a := range l.arrivals[1:] {
+		bytes += a.bytes
+	}
+	rate := float64(bytes) / 4 / span.Seconds()
+	return fmt.Sprintf("%s %.0f tok/s, longest pause %s",
+		render.Sparkline(l.Speeds(latencyBuckets)), rate, tools.FormatElapsed(l.LongestPause()))
+}
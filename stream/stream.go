@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/johnnyfreeman/viewscreen/config"
 	"github.com/johnnyfreeman/viewscreen/indicator"
@@ -53,6 +54,12 @@ type InputJSONDelta struct {
 	PartialJSON string `json:"partial_json"`
 }
 
+// ThinkingDelta represents a thinking delta in streaming
+type ThinkingDelta struct {
+	Type     string `json:"type"`
+	Thinking string `json:"thinking"`
+}
+
 // MessageDelta represents a message delta
 type MessageDelta struct {
 	StopReason   string `json:"stop_reason"`
@@ -68,6 +75,8 @@ type Renderer struct {
 	output           io.Writer
 	width            int
 	config           config.Provider
+	now              func() time.Time
+	latency          latency
 }
 
 // defaultToolHeaderRenderer adapts HeaderRenderer to the ToolHeaderRenderer interface
@@ -113,6 +122,14 @@ func WithConfigProvider(cp config.Provider) RendererOption {
 	}
 }
 
+// WithClock sets the clock that times text deltas for the verbose latency
+// sparkline
+func WithClock(now func() time.Time) RendererOption {
+	return func(r *Renderer) {
+		r.now = now
+	}
+}
+
 // NewRenderer creates a new stream Renderer with the given options
 func NewRenderer(opts ...RendererOption) *Renderer {
 	width := terminal.Width()
@@ -126,6 +143,7 @@ func NewRenderer(opts ...RendererOption) *Renderer {
 		output:           os.Stdout,
 		width:            width,
 		config:           cfg,
+		now:              time.Now,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -142,6 +160,7 @@ func (r *Renderer) renderTo(out *render.Output, event Event, showIndicator bool)
 
 	case "content_block_start":
 		r.block.StartBlock(event.Event.Index, event.Event.ContentBlock)
+		r.latency.Reset()
 
 	case "content_block_delta":
 		if len(event.Event.Delta) > 0 {
@@ -152,12 +171,22 @@ func (r *Renderer) renderTo(out *render.Output, event Event, showIndicator bool)
 					r.indicator.Show()
 				}
 				r.block.AccumulateText(textDelta.Text)
+				r.latency.Record(r.now(), len(textDelta.Text))
 				return
 			}
 			// Try input JSON delta
 			var jsonDelta InputJSONDelta
 			if err := json.Unmarshal(event.Event.Delta, &jsonDelta); err == nil && jsonDelta.Type == "input_json_delta" {
 				r.block.AccumulateToolInput(jsonDelta.PartialJSON)
+				return
+			}
+			// Try thinking delta; signature_delta carries nothing to show
+			var thinkingDelta ThinkingDelta
+			if err := json.Unmarshal(event.Event.Delta, &thinkingDelta); err == nil && thinkingDelta.Type == "thinking_delta" {
+				if showIndicator {
+					r.indicator.Show()
+				}
+				r.block.AccumulateThinking(thinkingDelta.Thinking)
 			}
 		}
 
@@ -173,6 +202,9 @@ func (r *Renderer) renderTo(out *render.Output, event Event, showIndicator bool)
 			if text != "" {
 				rendered := r.markdownRenderer.Render(text)
 				fmt.Fprint(out, rendered)
+				if summary := r.latency.Summary(); summary != "" && r.config.IsVerbose() {
+					fmt.Fprintln(out, style.MutedText(summary))
+				}
 			}
 		case BlockToolUse:
 			if input, ok := r.block.ParseToolInput(); ok {
@@ -180,8 +212,10 @@ func (r *Renderer) renderTo(out *render.Output, event Event, showIndicator bool)
 				out.WriteString(str)
 			} else {
 				// Fallback if JSON parse fails
-				fmt.Fprintln(out, style.BulletHeader(r.block.ToolName()))
+				fmt.Fprintln(out, style.BulletHeader(tools.DisplayName(r.block.ToolName())))
 			}
+		case BlockThinking:
+			render.WriteThinking(out, r.block.ThinkingBlock(), r.config.IsVerbose())
 		}
 
 	case "message_delta":
@@ -229,6 +263,18 @@ func (r *Renderer) InToolUseBlock() bool {
 	return r.block.InToolUseBlock()
 }
 
+// InThinkingBlock returns true if currently processing a thinking block.
+// This is used by external code (TUI, parser) to check streaming state.
+func (r *Renderer) InThinkingBlock() bool {
+	return r.block.InThinkingBlock()
+}
+
+// CurrentThinkingBlock returns the thinking block streamed so far, with its
+// accumulated text (only valid while InThinkingBlock).
+func (r *Renderer) CurrentThinkingBlock() types.ContentBlock {
+	return r.block.ThinkingBlock()
+}
+
 // CurrentBlockType returns the current block type as a string.
 // This is used by external code to query the block type.
 func (r *Renderer) CurrentBlockType() string {
@@ -240,6 +286,11 @@ func (r *Renderer) CurrentToolName() string {
 	return r.block.ToolName()
 }
 
+// CurrentToolID returns the active tool_use block's ID, if any.
+func (r *Renderer) CurrentToolID() string {
+	return r.block.ToolID()
+}
+
 // SetWidth updates the word-wrap width of the markdown renderer.
 // This is called when the viewport resizes.
 func (r *Renderer) SetWidth(width int) {
@@ -3,8 +3,11 @@ package stream
 import (
 	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/johnnyfreeman/viewscreen/testutil"
 	"github.com/johnnyfreeman/viewscreen/tools"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
@@ -384,6 +387,45 @@ func TestRenderer_Render_ContentBlockStop_TextBlock(t *testing.T) {
 	}
 }
 
+func TestRenderer_Render_ContentBlockStop_LatencySparkline(t *testing.T) {
+	// 40-byte deltas a second apart with a three second stall before the
+	// last, charted in five 1.4s intervals.
+	gaps := []time.Duration{0, time.Second, time.Second, time.Second, time.Second, 3 * time.Second}
+	tests := []struct {
+		name    string
+		verbose int
+		scale   time.Duration
+		want    string
+	}{
+		{"verbose", 1, 1, "▅▅█▁▅ 7 tok/s, longest pause 3.0s\n"},
+		{"not verbose", 0, 1, ""},
+		{"read from a file", 1, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			output := &bytes.Buffer{}
+			r := NewRenderer(
+				WithOutput(output),
+				WithIndicator(&mockIndicator{}),
+				WithMarkdownRenderer(&mockMarkdownRenderer{returnValue: "text\n"}),
+				WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: tt.verbose}),
+				WithClock(func() time.Time { return now }),
+			)
+			r.Render(Event{Event: EventData{Type: "content_block_start", ContentBlock: makeContentBlock("text", "")}})
+			for _, gap := range gaps {
+				now = now.Add(gap * tt.scale)
+				r.Render(Event{Event: EventData{Type: "content_block_delta", Delta: makeTextDelta(strings.Repeat("x", 40))}})
+			}
+			r.Render(Event{Event: EventData{Type: "content_block_stop"}})
+
+			if got := strings.TrimPrefix(testutil.StripANSI(output.String()), "text\n"); got != tt.want {
+				t.Errorf("got %q after the text, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderer_Render_ContentBlockStop_TextBlock_Empty(t *testing.T) {
 	output := &bytes.Buffer{}
 	markdown := &mockMarkdownRenderer{}
@@ -507,6 +549,55 @@ func TestRenderer_Render_ContentBlockStop_ToolUseBlock_InvalidJSON(t *testing.T)
 	}
 }
 
+func TestRenderer_Render_ContentBlockStop_ThinkingBlock(t *testing.T) {
+	thinkingDelta, _ := json.Marshal(ThinkingDelta{Type: "thinking_delta", Thinking: "Let me check the tests."})
+	signatureDelta := json.RawMessage(`{"type":"signature_delta","signature":"EqQBCgIYAhIM"}`)
+
+	tests := []struct {
+		name        string
+		verbose     int
+		wantThought bool
+	}{
+		{"collapsed", 0, false},
+		{"verbose", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			r := NewRenderer(
+				WithOutput(output),
+				WithIndicator(&mockIndicator{}),
+				WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: tt.verbose}),
+			)
+
+			for _, ev := range []EventData{
+				{Type: "content_block_start", Index: 0, ContentBlock: makeContentBlock("thinking", "")},
+				{Type: "content_block_delta", Index: 0, Delta: thinkingDelta},
+				{Type: "content_block_delta", Index: 0, Delta: signatureDelta},
+			} {
+				r.Render(Event{Event: ev})
+			}
+			if output.Len() != 0 {
+				t.Fatalf("expected no output before content_block_stop, got %q", output.String())
+			}
+			if !r.InThinkingBlock() {
+				t.Error("expected InThinkingBlock to be true")
+			}
+
+			r.Render(Event{Event: EventData{Type: "content_block_stop", Index: 0}})
+
+			got := testutil.StripANSI(output.String())
+			if !strings.Contains(got, "✻ Thinking… (6 tokens)") {
+				t.Errorf("expected collapsed thinking line, got %q", got)
+			}
+			if strings.Contains(got, "Let me check the tests.") != tt.wantThought {
+				t.Errorf("thought text shown = %v, want %v; output %q", !tt.wantThought, tt.wantThought, got)
+			}
+		})
+	}
+}
+
 func TestRenderer_Render_ContentBlockStop_WrongIndex(t *testing.T) {
 	markdown := &mockMarkdownRenderer{}
 
@@ -0,0 +1,367 @@
+// Package strict checks input events against the shapes viewscreen expects,
+// for -strict. A field it does not know, a value of the wrong JSON type or a
+// tool it has no rendering for is reported as a Diagnostic, one JSON object
+// per line, so a new agent release that changes its stream format shows up
+// as diagnostics instead of silently rendering wrong.
+package strict
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/johnnyfreeman/viewscreen/assistant"
+	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/record"
+	"github.com/johnnyfreeman/viewscreen/result"
+	"github.com/johnnyfreeman/viewscreen/stream"
+	"github.com/johnnyfreeman/viewscreen/system"
+	"github.com/johnnyfreeman/viewscreen/tools"
+	"github.com/johnnyfreeman/viewscreen/user"
+)
+
+// Problems a Diagnostic reports.
+const (
+	UnknownField     = "unknown_field"
+	TypeMismatch     = "type_mismatch"
+	UnknownTool      = "unknown_tool"
+	UnknownEventType = "unknown_event_type"
+	InvalidJSON      = "invalid_json"
+)
+
+// Diagnostic is a way an input line differs from what viewscreen expects.
+type Diagnostic struct {
+	// Line is the input line, counted from 1.
+	Line int `json:"line"`
+	// Event is the line's event type, "" when it has none.
+	Event   string `json:"event,omitempty"`
+	Problem string `json:"problem"`
+	// Path locates the value, as in message.content[0].name.
+	Path   string `json:"path,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// shapes are the Go types each event type is decoded into.
+var shapes = map[string]reflect.Type{
+	"system":        reflect.TypeFor[system.Event](),
+	"assistant":     reflect.TypeFor[assistant.Event](),
+	"user":          reflect.TypeFor[user.Event](),
+	"stream_event":  reflect.TypeFor[stream.Event](),
+	"result":        reflect.TypeFor[result.Event](),
+	metadata.Type:   reflect.TypeFor[metadata.Event](),
+	record.SkipType: reflect.TypeFor[record.Skip](),
+}
+
+// ignoredTypes are event types viewscreen knows and does not render.
+var ignoredTypes = map[string]bool{"rate_limit_event": true}
+
+// knownFields are fields Claude Code sends that viewscreen knows and does
+// not use, by event type, with [] for any array index and * for any map key.
+var knownFields = map[string][]string{
+	"assistant": {
+		"message.context_management",
+		"message.stop_sequence",
+		"message.usage.cache_creation",
+		"message.usage.inference_geo",
+	},
+	"result": {
+		"modelUsage.*.webSearchRequests",
+		"usage.cache_creation",
+		"usage.inference_geo",
+		"usage.iterations",
+	},
+}
+
+// Validator checks input lines, counting them for the Line of its
+// diagnostics.
+type Validator struct {
+	line int
+}
+
+// Check returns the diagnostics for the next input line. Blank lines are
+// counted and pass.
+func (v *Validator) Check(line []byte) []Diagnostic {
+	v.line++
+	if len(strings.TrimSpace(string(line))) == 0 {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(line, &value); err != nil {
+		return []Diagnostic{{Line: v.line, Problem: InvalidJSON, Detail: err.Error()}}
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return []Diagnostic{{Line: v.line, Problem: TypeMismatch, Detail: "want object, got " + kind(value)}}
+	}
+	eventType, _ := object["type"].(string)
+	c := checker{diag: Diagnostic{Line: v.line, Event: eventType}}
+	shape, ok := shapes[eventType]
+	switch {
+	case ok:
+	case codex.IsEventType(eventType):
+		shape = reflect.TypeFor[codex.Event]()
+	case ignoredTypes[eventType]:
+		return nil
+	default:
+		c.report(UnknownEventType, "type", fmt.Sprintf("%q", eventType))
+		return c.found
+	}
+	c.value("", "", object, shape)
+	c.tools(eventType, object)
+	return c.found
+}
+
+// checker collects the diagnostics of one line.
+type checker struct {
+	diag  Diagnostic
+	found []Diagnostic
+}
+
+func (c *checker) report(problem, path, detail string) {
+	d := c.diag
+	d.Problem, d.Path, d.Detail = problem, path, detail
+	c.found = append(c.found, d)
+}
+
+var (
+	rawMessageType  = reflect.TypeFor[json.RawMessage]()
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textType        = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// value checks the decoded JSON value at path against the Go type t. pattern
+// is path with array indexes and map keys generalized, as in knownFields.
+func (c *checker) value(path, pattern string, value any, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Null decodes into anything; raw and custom decoding take any shape.
+	if value == nil || t == rawMessageType || t.Kind() == reflect.Interface ||
+		reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	if _, isString := value.(string); isString && reflect.PointerTo(t).Implements(textType) {
+		return
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := fieldsOf(t)
+			for _, key := range sortedKeys(value) {
+				field, ok := fields[strings.ToLower(key)]
+				if !ok {
+					if !slices.Contains(knownFields[c.diag.Event], join(pattern, key)) {
+						c.report(UnknownField, join(path, key), "")
+					}
+					continue
+				}
+				c.value(join(path, key), join(pattern, key), value[key], field)
+			}
+			return
+		case reflect.Map:
+			for _, key := range sortedKeys(value) {
+				c.value(join(path, key), join(pattern, "*"), value[key], t.Elem())
+			}
+			return
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, elem := range value {
+				c.value(fmt.Sprintf("%s[%d]", path, i), pattern+"[]", elem, t.Elem())
+			}
+			return
+		}
+	case string:
+		if t.Kind() == reflect.String {
+			return
+		}
+	case float64:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return
+		}
+	case bool:
+		if t.Kind() == reflect.Bool {
+			return
+		}
+	}
+	c.report(TypeMismatch, path, fmt.Sprintf("want %s, got %s", jsonKind(t), kind(value)))
+}
+
+// tools reports the tool calls in an event that viewscreen has no
+// rendering for.
+func (c *checker) tools(eventType string, object map[string]any) {
+	check := func(path string, block any) {
+		b, ok := block.(map[string]any)
+		if !ok || b["type"] != "tool_use" {
+			return
+		}
+		name, _ := b["name"].(string)
+		if _, known := tools.GetDefinition(name); !known {
+			c.report(UnknownTool, join(path, "name"), fmt.Sprintf("%q", name))
+		}
+	}
+	switch eventType {
+	case "assistant":
+		message, _ := object["message"].(map[string]any)
+		content, _ := message["content"].([]any)
+		for i, block := range content {
+			check(fmt.Sprintf("message.content[%d]", i), block)
+		}
+	case "stream_event":
+		event, _ := object["event"].(map[string]any)
+		check("event.content_block", event["content_block"])
+	}
+}
+
+var fieldCache sync.Map // reflect.Type → map[string]reflect.Type
+
+// fieldsOf returns the types of the JSON fields of struct type t, keyed by
+// lowercased name, with embedded structs' fields promoted as encoding/json
+// does.
+func fieldsOf(t reflect.Type) map[string]reflect.Type {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range fieldsOf(f.Type) {
+				if _, ok := fields[k]; !ok {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// kind names the JSON type of a decoded value.
+func kind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// jsonKind names the JSON type Go type t decodes from.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return "number"
+}
+
+// Recorder writes the diagnostics of the input lines it receives as JSON
+// lines. It implements record.LineRecorder, so it can watch an input stream
+// through record.TeeReader.
+type Recorder struct {
+	mu        sync.Mutex
+	validator Validator
+	enc       *json.Encoder
+	count     int
+}
+
+// NewRecorder creates a Recorder writing to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+// Record checks line and writes its diagnostics.
+func (r *Recorder) Record(line string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.validator.Check([]byte(line)) {
+		r.count++
+		if err := r.enc.Encode(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns how many diagnostics have been written.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// TeeToLog returns a reader of r that checks each line it yields, writing
+// the diagnostics to the file at path, or to stderr when path is "". When
+// on is false, r is returned unchanged. The closer closes the log file.
+func TeeToLog(on bool, path string, r io.Reader) (io.Reader, io.Closer, error) {
+	if !on {
+		return r, nopCloser{}, nil
+	}
+	var w io.WriteCloser = nopCloser{os.Stderr}
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = f
+	}
+	return record.TeeReader(r, NewRecorder(w)), w, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,156 @@
+package strict
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidator_Check(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []Diagnostic
+	}{
+		{
+			name: "known shape",
+			line: `{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}],"usage":{"input_tokens":3}}}`,
+		},
+		{
+			name: "known unused fields",
+			line: `{"type":"result","subtype":"success","usage":{"inference_geo":"us"},"modelUsage":{"claude-x":{"webSearchRequests":0}}}`,
+		},
+		{
+			name: "ignored event type",
+			line: `{"type":"rate_limit_event","rate_limit_info":{}}`,
+		},
+		{
+			name: "unknown fields",
+			line: `{"type":"assistant","novel":1,"message":{"content":[{"type":"text","text":"hi","sparkle":true}]}}`,
+			want: []Diagnostic{
+				{Line: 1, Event: "assistant", Problem: UnknownField, Path: "message.content[0].sparkle"},
+				{Line: 1, Event: "assistant", Problem: UnknownField, Path: "novel"},
+			},
+		},
+		{
+			name: "type mismatches",
+			line: `{"type":"result","num_turns":"3","is_error":"no"}`,
+			want: []Diagnostic{
+				{Line: 1, Event: "result", Problem: TypeMismatch, Path: "is_error", Detail: "want boolean, got string"},
+				{Line: 1, Event: "result", Problem: TypeMismatch, Path: "num_turns", Detail: "want number, got string"},
+			},
+		},
+		{
+			name: "unknown tool",
+			line: `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Teleport","input":{}},{"type":"tool_use","id":"t2","name":"mcp__srv__go","input":{}}]}}`,
+			want: []Diagnostic{{Line: 1, Event: "assistant", Problem: UnknownTool, Path: "message.content[0].name", Detail: `"Teleport"`}},
+		},
+		{
+			name: "unknown tool streamed",
+			line: `{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"t1","name":"Teleport"}}}`,
+			want: []Diagnostic{{Line: 1, Event: "stream_event", Problem: UnknownTool, Path: "event.content_block.name", Detail: `"Teleport"`}},
+		},
+		{
+			name: "unknown event type",
+			line: `{"type":"hologram"}`,
+			want: []Diagnostic{{Line: 1, Event: "hologram", Problem: UnknownEventType, Path: "type", Detail: `"hologram"`}},
+		},
+		{
+			name: "not an object",
+			line: `[1,2]`,
+			want: []Diagnostic{{Line: 1, Problem: TypeMismatch, Detail: "want object, got array"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Validator
+			got := v.Check([]byte(tt.line))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidator_Check_InvalidJSON(t *testing.T) {
+	var v Validator
+	v.Check([]byte(""))
+	got := v.Check([]byte("{oops"))
+	if len(got) != 1 || got[0].Line != 2 || got[0].Problem != InvalidJSON || got[0].Detail == "" {
+		t.Errorf("Check() = %+v, want one invalid_json diagnostic on line 2", got)
+	}
+}
+
+// TestValidator_Testdata keeps the shapes in step with the fixtures: every
+// field Claude Code and Codex send must be modeled or listed as known.
+func TestValidator_Testdata(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "testdata", "*.jsonl"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 10<<20)
+		var v Validator
+		for scanner.Scan() {
+			for _, d := range v.Check(scanner.Bytes()) {
+				if d.Problem != UnknownTool {
+					t.Errorf("%s: %+v", filepath.Base(file), d)
+				}
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var out bytes.Buffer
+	r := NewRecorder(&out)
+	for _, line := range []string{`{"type":"result"}`, `{"type":"result","extra":1}`} {
+		if err := r.Record(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", r.Count())
+	}
+	var d Diagnostic
+	if err := json.Unmarshal(bytes.TrimSpace(out.Bytes()), &d); err != nil {
+		t.Fatalf("expected one JSON diagnostic, got %q: %v", out.String(), err)
+	}
+	if d.Line != 2 || d.Path != "extra" {
+		t.Errorf("diagnostic = %+v, want extra on line 2", d)
+	}
+}
+
+func TestTeeToLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strict.jsonl")
+	in, closer, err := TeeToLog(true, path, strings.NewReader("{\"type\":\"user\",\"surprise\":true}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bufio.NewReader(in).WriteTo(new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"path":"surprise"`) {
+		t.Errorf("log = %q, %v; want the surprise field reported", data, err)
+	}
+
+	r := strings.NewReader("")
+	if in, _, _ := TeeToLog(false, path, r); in != r {
+		t.Error("expected the reader unchanged when off")
+	}
+}
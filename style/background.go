@@ -0,0 +1,224 @@
+package style
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucasb-eyer/go-colorful"
+	"golang.org/x/term"
+)
+
+// Background is the kind of terminal background Init picks theme variants
+// for.
+type Background string
+
+// Backgrounds accepted by -background.
+const (
+	// BackgroundAuto asks the terminal, falling back to COLORFGBG and then
+	// to dark.
+	BackgroundAuto  Background = "auto"
+	BackgroundDark  Background = "dark"
+	BackgroundLight Background = "light"
+)
+
+// backgroundQueryTimeout bounds how long detection waits for the terminal
+// to answer. Terminals answer in a few milliseconds; one that ignores the
+// query should not hold up startup.
+const backgroundQueryTimeout = 150 * time.Millisecond
+
+// backgroundDrainTimeout bounds how much longer detection reads the answer
+// of a terminal slower than backgroundQueryTimeout, so it does not arrive
+// once the terminal is out of raw mode, to be echoed or read as keys.
+const backgroundDrainTimeout = 100 * time.Millisecond
+
+var (
+	// background is what SetBackground chose. Dark by default, so Init is
+	// deterministic for callers that never ask to detect.
+	background = BackgroundDark
+	// lightBackground is whether the last Init picked light variants.
+	lightBackground bool
+
+	// queryTerminal is whether detection may ask the terminal. See
+	// SetQueryTerminal.
+	queryTerminal bool
+	detectOnce    sync.Once
+	detected      bool
+
+	// queryBackground and getenv are replaced in tests, which must not talk
+	// to a real terminal.
+	queryBackground = queryTerminalBackground
+	getenv          = os.Getenv
+)
+
+// ParseBackground parses a -background value.
+func ParseBackground(s string) (Background, error) {
+	switch b := Background(strings.ToLower(strings.TrimSpace(s))); b {
+	case BackgroundAuto, BackgroundDark, BackgroundLight:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown background %q (want auto, dark or light)", s)
+}
+
+// SetBackground sets the background Init picks theme variants for.
+func SetBackground(b Background) {
+	background = b
+}
+
+// SetQueryTerminal sets whether BackgroundAuto may ask the terminal for its
+// background. The query is a round trip worth making only for runs that
+// render a session in color; without it, COLORFGBG decides.
+func SetQueryTerminal(on bool) {
+	queryTerminal = on
+}
+
+// LightBackground reports whether the last Init picked the light variants,
+// for renderers with palettes of their own, like syntax highlighting.
+func LightBackground() bool {
+	return lightBackground
+}
+
+// isLight resolves b, detecting the terminal's background at most once per
+// process for BackgroundAuto.
+func isLight(b Background) bool {
+	switch b {
+	case BackgroundLight:
+		return true
+	case BackgroundAuto:
+		detectOnce.Do(func() { detected = detectLightBackground() })
+		return detected
+	}
+	return false
+}
+
+// detectLightBackground asks the terminal for its background color with an
+// OSC 11 query, when SetQueryTerminal allows it, and otherwise or when it
+// does not answer reads COLORFGBG. Unknown is dark, the palette viewscreen
+// was designed on.
+func detectLightBackground() bool {
+	if !queryTerminal {
+		light, _ := parseColorFGBG(getenv("COLORFGBG"))
+		return light
+	}
+	if c, ok := queryBackground(); ok {
+		return isLightColor(c)
+	}
+	light, _ := parseColorFGBG(getenv("COLORFGBG"))
+	return light
+}
+
+// isLightColor reports whether c is light: whether dark text reads better
+// on it than light text, which is the case above a WCAG relative luminance
+// of about 0.18.
+func isLightColor(c color.Color) bool {
+	cc, ok := colorful.MakeColor(c)
+	return ok && luminance(cc) > 0.179
+}
+
+// parseColorFGBG reads the background out of a COLORFGBG value such as
+// "15;0" or "0;default;15", set by rxvt, Konsole and others. The last field
+// is an ANSI color index: 7 and 9 through 15 are light. ok is false when
+// the value names no index.
+func parseColorFGBG(v string) (light, ok bool) {
+	fields := strings.Split(v, ";")
+	n, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil || n < 0 || n > 15 {
+		return false, false
+	}
+	return n == 7 || n >= 9, true
+}
+
+// queryTerminalBackground asks the controlling terminal for its background
+// color. It goes through /dev/tty, so it works while stdin is the event
+// pipe, and gives up when there is no terminal or it does not answer in
+// time.
+func queryTerminalBackground() (color.Color, bool) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, false
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, false
+	}
+	defer tty.Close()
+	state, err := term.MakeRaw(int(tty.Fd()))
+	if err != nil {
+		return nil, false
+	}
+	defer term.Restore(int(tty.Fd()), state) //nolint:errcheck
+	return queryBackgroundColor(tty, tty, backgroundQueryTimeout)
+}
+
+// queryBackgroundColor writes the OSC 11 query to out, followed by a
+// primary device attributes query every terminal answers, and reads in
+// until the answer to the second. A terminal that answers only the second
+// does not support OSC 11, so detection does not wait out the timeout
+// there, and one that answers both answers in order, so nothing of the
+// answer is left unread. An answer later than timeout is read for up to
+// backgroundDrainTimeout more.
+func queryBackgroundColor(in io.Reader, out io.Writer, timeout time.Duration) (color.Color, bool) {
+	if _, err := io.WriteString(out, ansi.RequestBackgroundColor+ansi.RequestPrimaryDeviceAttributes); err != nil {
+		return nil, false
+	}
+	resp, done := readBackgroundResponse(in, nil, timeout)
+	if !done {
+		resp, _ = readBackgroundResponse(in, resp, backgroundDrainTimeout)
+	}
+	c, _ := parseBackgroundResponse(resp)
+	return c, c != nil
+}
+
+// readBackgroundResponse appends what in yields to resp until it holds the
+// device attributes answer, or for at most timeout. done reports whether
+// the answer is complete.
+func readBackgroundResponse(in io.Reader, resp []byte, timeout time.Duration) (_ []byte, done bool) {
+	rd, err := uv.NewCancelReader(in)
+	if err != nil {
+		return resp, false
+	}
+	defer rd.Close() //nolint:errcheck
+
+	timer := time.AfterFunc(timeout, func() { rd.Cancel() })
+	defer timer.Stop()
+
+	buf := make([]byte, 256)
+	for {
+		n, err := rd.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if _, done := parseBackgroundResponse(resp); done {
+			return resp, true
+		}
+		if err != nil {
+			return resp, false
+		}
+	}
+}
+
+// parseBackgroundResponse looks for the answer to the OSC 11 query in resp,
+// as in "ESC ] 11 ; rgb:RRRR/GGGG/BBBB" ended by BEL or ST. done is true
+// once resp also holds the device attributes answer, which terminals send
+// after any answer to OSC 11.
+func parseBackgroundResponse(resp []byte) (c color.Color, done bool) {
+	if i := bytes.Index(resp, []byte("\x1b]11;")); i >= 0 {
+		rest := resp[i+len("\x1b]11;"):]
+		end := bytes.IndexByte(rest, '\a')
+		if st := bytes.Index(rest, []byte("\x1b\\")); st >= 0 && (end < 0 || st < end) {
+			end = st
+		}
+		if end >= 0 {
+			c = ansi.XParseColor(string(rest[:end]))
+		}
+	}
+	if i := bytes.Index(resp, []byte("\x1b[?")); i >= 0 && bytes.IndexByte(resp[i:], 'c') >= 0 {
+		return c, true
+	}
+	return c, false
+}
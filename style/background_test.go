@@ -0,0 +1,170 @@
+package style
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestParseBackground(t *testing.T) {
+	for in, want := range map[string]Background{"auto": BackgroundAuto, " Light ": BackgroundLight, "dark": BackgroundDark} {
+		if got, err := ParseBackground(in); err != nil || got != want {
+			t.Errorf("ParseBackground(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBackground("sepia"); err == nil {
+		t.Error("expected an unknown background to be rejected")
+	}
+}
+
+func TestParseColorFGBG(t *testing.T) {
+	tests := []struct {
+		value     string
+		light, ok bool
+	}{
+		{"15;0", false, true},
+		{"0;15", true, true},
+		{"0;default;7", true, true},
+		{"7;8", false, true},
+		{"15;default", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		light, ok := parseColorFGBG(tt.value)
+		if light != tt.light || ok != tt.ok {
+			t.Errorf("parseColorFGBG(%q) = %v, %v; want %v, %v", tt.value, light, ok, tt.light, tt.ok)
+		}
+	}
+}
+
+func TestParseBackgroundResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		resp      string
+		wantLight bool
+		wantColor bool
+		wantDone  bool
+	}{
+		{"light, BEL", "\x1b]11;rgb:ffff/ffff/f0f0\a\x1b[?62;22c", true, true, true},
+		{"dark, ST", "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\\x1b[?1;2c", false, true, true},
+		{"no device attributes yet", "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\", false, true, false},
+		{"unsupported", "\x1b[?1;2c", false, false, true},
+		{"incomplete", "\x1b]11;rgb:ff", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, done := parseBackgroundResponse([]byte(tt.resp))
+			if done != tt.wantDone || (c != nil) != tt.wantColor {
+				t.Fatalf("parseBackgroundResponse = %v, %v; want color %v, done %v", c, done, tt.wantColor, tt.wantDone)
+			}
+			if c != nil && isLightColor(c) != tt.wantLight {
+				t.Errorf("isLightColor(%v) = %v, want %v", c, !tt.wantLight, tt.wantLight)
+			}
+		})
+	}
+}
+
+func TestQueryBackgroundColor(t *testing.T) {
+	var out bytes.Buffer
+	answer := strings.NewReader("\x1b]11;rgb:0000/0000/0000\a\x1b[?62c")
+	c, ok := queryBackgroundColor(iotest.OneByteReader(answer), &out, time.Second)
+	if !ok || isLightColor(c) {
+		t.Errorf("queryBackgroundColor = %v, %v; want black", c, ok)
+	}
+	if !strings.HasPrefix(out.String(), "\x1b]11;?") {
+		t.Errorf("query = %q, want OSC 11 first", out.String())
+	}
+	if answer.Len() != 0 {
+		t.Errorf("queryBackgroundColor left %d bytes of the answer unread", answer.Len())
+	}
+}
+
+func TestInitPicksLightVariant(t *testing.T) {
+	defer func() {
+		SetBackground(BackgroundDark)
+		SetBaseTheme(DefaultTheme)
+		Init(false)
+	}()
+
+	SetBackground(BackgroundLight)
+	Init(false)
+	if CurrentTheme != LightTheme || DiffAddBg != LightTheme.DiffAddBg || !LightBackground() {
+		t.Errorf("CurrentTheme = %+v, want LightTheme", CurrentTheme)
+	}
+	if DarkTheme() != DefaultTheme {
+		t.Error("expected DarkTheme to stay the base theme")
+	}
+
+	SetBaseTheme(HighContrastTheme)
+	Init(false)
+	if CurrentTheme != HighContrastLightTheme {
+		t.Errorf("CurrentTheme = %+v, want HighContrastLightTheme", CurrentTheme)
+	}
+
+	Init(true)
+	if LightBackground() {
+		t.Error("expected no light variant without color")
+	}
+}
+
+func TestInitDetectsBackground(t *testing.T) {
+	origQuery, origGetenv := queryBackground, getenv
+	SetQueryTerminal(true)
+	defer func() {
+		SetQueryTerminal(false)
+		queryBackground, getenv = origQuery, origGetenv
+		detectOnce = sync.Once{}
+		SetBackground(BackgroundDark)
+		Init(false)
+	}()
+
+	tests := []struct {
+		name  string
+		query color.Color
+		env   string
+		want  Theme
+	}{
+		{"terminal answers light", color.White, "15;0", LightTheme},
+		{"terminal answers dark", color.Black, "0;15", DefaultTheme},
+		{"COLORFGBG light", nil, "0;15", LightTheme},
+		{"unknown is dark", nil, "", DefaultTheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryBackground = func() (color.Color, bool) { return tt.query, tt.query != nil }
+			getenv = func(string) string { return tt.env }
+			detectOnce = sync.Once{}
+			SetBackground(BackgroundAuto)
+			Init(false)
+			if CurrentTheme != tt.want {
+				t.Errorf("CurrentTheme.BgBase = %s, want %s", CurrentTheme.BgBase, tt.want.BgBase)
+			}
+		})
+	}
+}
+
+func TestInitDetectsBackground_NoQuery(t *testing.T) {
+	origQuery, origGetenv := queryBackground, getenv
+	defer func() {
+		queryBackground, getenv = origQuery, origGetenv
+		detectOnce = sync.Once{}
+		SetBackground(BackgroundDark)
+		Init(false)
+	}()
+
+	queryBackground = func() (color.Color, bool) {
+		t.Error("asked the terminal without SetQueryTerminal")
+		return nil, false
+	}
+	getenv = func(string) string { return "0;15" }
+	detectOnce = sync.Once{}
+	SetBackground(BackgroundAuto)
+	Init(false)
+	if CurrentTheme != LightTheme {
+		t.Errorf("CurrentTheme.BgBase = %s, want COLORFGBG's light %s", CurrentTheme.BgBase, LightTheme.BgBase)
+	}
+}
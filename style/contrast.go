@@ -0,0 +1,136 @@
+package style
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Minimum contrast ratios from WCAG 2.1: MinTextContrast for body text
+// (level AA), MinSubtleContrast for large or de-emphasized text such as line
+// numbers.
+const (
+	MinTextContrast   = 4.5
+	MinSubtleContrast = 3.0
+)
+
+// contrastPair is a foreground role drawn over a background role, and the
+// lowest contrast ratio it needs to stay readable.
+type contrastPair struct {
+	fg, bg string
+	min    float64
+}
+
+// contrastPairs are the role combinations the renderers draw.
+var contrastPairs = []contrastPair{
+	{"fg", "bg", MinTextContrast},
+	{"fg-muted", "bg", MinTextContrast},
+	{"fg-subtle", "bg", MinSubtleContrast},
+	{"success", "bg", MinTextContrast},
+	{"error", "bg", MinTextContrast},
+	{"warning", "bg", MinTextContrast},
+	{"info", "bg", MinTextContrast},
+	{"accent", "bg", MinTextContrast},
+	{"warning", "bg-overlay", MinTextContrast}, // search matches
+	{"bg", "warning", MinTextContrast},         // current search match
+	{"fg", "diff-add-bg", MinTextContrast},
+	{"fg", "diff-remove-bg", MinTextContrast},
+	{"fg", "diff-add-emph-bg", MinTextContrast},
+	{"fg", "diff-remove-emph-bg", MinTextContrast},
+}
+
+// ContrastIssue is a foreground/background pair of a theme whose contrast
+// is below the ratio it needs.
+type ContrastIssue struct {
+	Fg, Bg     string // roles, as accepted by -color
+	Ratio, Min float64
+	// Suggested is the closest color to the foreground's that reaches Min
+	// against the background, or "" when none does.
+	Suggested Color
+}
+
+// String describes the issue and the suggested adjustment.
+func (i ContrastIssue) String() string {
+	s := fmt.Sprintf("%s on %s has contrast %.1f:1, below %.1f:1", i.Fg, i.Bg, i.Ratio, i.Min)
+	if i.Suggested != "" {
+		s += fmt.Sprintf(" (try -color %s=%s)", i.Fg, i.Suggested)
+	}
+	return s
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 (no
+// contrast) to 21 (black on white). It reports false when either color is
+// not a valid hex value.
+func ContrastRatio(fg, bg Color) (float64, bool) {
+	a, err := colorful.Hex(string(fg))
+	if err != nil {
+		return 0, false
+	}
+	b, err := colorful.Hex(string(bg))
+	if err != nil {
+		return 0, false
+	}
+	return contrast(a, b), true
+}
+
+// CheckContrast returns the pairs of t's colors below their minimum
+// contrast. Given roles, only pairs involving one of them are checked, so
+// a few overridden colors are not blamed for the base theme. Pairs with an
+// unset color are skipped.
+func (t Theme) CheckContrast(roles ...string) []ContrastIssue {
+	colors := t.Colors()
+	var issues []ContrastIssue
+	for _, p := range contrastPairs {
+		if len(roles) > 0 && !slices.Contains(roles, p.fg) && !slices.Contains(roles, p.bg) {
+			continue
+		}
+		fg, err := colorful.Hex(string(colors[p.fg]))
+		if err != nil {
+			continue
+		}
+		bg, err := colorful.Hex(string(colors[p.bg]))
+		if err != nil {
+			continue
+		}
+		if ratio := contrast(fg, bg); ratio < p.min {
+			issues = append(issues, ContrastIssue{
+				Fg: p.fg, Bg: p.bg, Ratio: ratio, Min: p.min,
+				Suggested: suggestContrast(fg, bg, p.min),
+			})
+		}
+	}
+	return issues
+}
+
+// suggestContrast keeps fg's hue and chroma and moves its lightness away
+// from bg's, in small steps, until the pair reaches min. Both directions
+// are tried and the smaller change wins.
+func suggestContrast(fg, bg colorful.Color, min float64) Color {
+	h, c, l := fg.Hcl()
+	for step := 0.01; step <= 1; step += 0.01 {
+		for _, next := range []float64{l + step, l - step} {
+			if next < 0 || next > 1 {
+				continue
+			}
+			candidate := colorful.Hcl(h, c, next).Clamped()
+			if contrast(candidate, bg) >= min {
+				return Color(candidate.Hex())
+			}
+		}
+	}
+	return ""
+}
+
+// contrast returns the WCAG contrast ratio of a and b.
+func contrast(a, b colorful.Color) float64 {
+	la, lb := luminance(a), luminance(b)
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
+
+// luminance returns the WCAG relative luminance of an sRGB color.
+func luminance(c colorful.Color) float64 {
+	r, g, b := c.LinearRgb()
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
@@ -4,12 +4,15 @@ import (
 	"image/color"
 	"strings"
 
+	"github.com/charmbracelet/colorprofile"
 	uv "github.com/charmbracelet/ultraviolet"
 	"github.com/lucasb-eyer/go-colorful"
 )
 
 // applyGradientCore applies a horizontal color gradient to text with optional bold.
-// Uses HCL color space for perceptually uniform blending.
+// Uses HCL color space for perceptually uniform blending, quantized to the
+// 256 colors when the SetProfile profile has no 24-bit color. With fewer
+// colors than that a gradient would only band, so text is drawn in from.
 // Uses Ultraviolet for proper style/content separation - this ensures that
 // gradient text can be safely composed with other styles without escape
 // sequence conflicts.
@@ -41,13 +44,16 @@ func applyGradientCore(text string, from, to Color, bold bool) string {
 		return text
 	}
 
-	var b strings.Builder
-	b.Grow(len(text) * 20) // Estimate for ANSI codes
-
 	var attrs uint8
 	if bold {
 		attrs = uv.AttrBold
 	}
+	if profile < colorprofile.ANSI256 {
+		return styled(text, themeStyle(from, attrs))
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) * 20) // Estimate for ANSI codes
 
 	for i, r := range runes {
 		// Calculate interpolation factor
@@ -64,7 +70,7 @@ func applyGradientCore(text string, from, to Color, bold bool) string {
 		// with surrounding escape codes when gradient text is further
 		// processed or wrapped in other styles.
 		style := &uv.Style{
-			Fg:    ColorfulToRGBA(blended),
+			Fg:    ProfileColor(ColorfulToRGBA(blended)),
 			Attrs: attrs,
 		}
 		b.WriteString(style.Styled(string(r)))
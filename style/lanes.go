@@ -0,0 +1,30 @@
+package style
+
+import "strconv"
+
+// laneColors are the colors of concurrency lanes, in lane order. Lanes past
+// them reuse them.
+func laneColors() []Color {
+	t := CurrentTheme
+	return []Color{t.Accent, t.Info, t.Success, t.Warning}
+}
+
+// LaneRule returns the left rule marking the lines of concurrency lane n
+// (from 0): a bar in the lane's color, or the lane's number when color is
+// disabled. It is two columns wide either way.
+func LaneRule(n int) string {
+	if noColor {
+		return strconv.Itoa((n%9)+1) + " "
+	}
+	bar := "▎"
+	if asciiGlyphs {
+		bar = "|"
+	}
+	return LaneText(n, bar) + " "
+}
+
+// LaneText colors text in the color of concurrency lane n.
+func LaneText(n int, text string) string {
+	colors := laneColors()
+	return styled(text, themeStyle(colors[n%len(colors)], 0))
+}
@@ -0,0 +1,95 @@
+package style
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// roleFields maps the semantic role names accepted by -color to the theme
+// field each one sets.
+var roleFields = map[string]func(*Theme) *Color{
+	"fg":                     func(t *Theme) *Color { return &t.FgBase },
+	"fg-muted":               func(t *Theme) *Color { return &t.FgMuted },
+	"fg-subtle":              func(t *Theme) *Color { return &t.FgSubtle },
+	"bg":                     func(t *Theme) *Color { return &t.BgBase },
+	"bg-subtle":              func(t *Theme) *Color { return &t.BgSubtle },
+	"bg-overlay":             func(t *Theme) *Color { return &t.BgOverlay },
+	"success":                func(t *Theme) *Color { return &t.Success },
+	"error":                  func(t *Theme) *Color { return &t.Error },
+	"warning":                func(t *Theme) *Color { return &t.Warning },
+	"info":                   func(t *Theme) *Color { return &t.Info },
+	"accent":                 func(t *Theme) *Color { return &t.Accent },
+	"diff-add-bg":            func(t *Theme) *Color { return &t.DiffAddBg },
+	"diff-remove-bg":         func(t *Theme) *Color { return &t.DiffRemoveBg },
+	"diff-add-emph-bg":       func(t *Theme) *Color { return &t.DiffAddEmphBg },
+	"diff-remove-emph-bg":    func(t *Theme) *Color { return &t.DiffRemoveEmphBg },
+	"gradient-start":         func(t *Theme) *Color { return &t.GradientStart },
+	"gradient-end":           func(t *Theme) *Color { return &t.GradientEnd },
+	"success-gradient-start": func(t *Theme) *Color { return &t.SuccessGradientStart },
+	"success-gradient-end":   func(t *Theme) *Color { return &t.SuccessGradientEnd },
+	"error-gradient-start":   func(t *Theme) *Color { return &t.ErrorGradientStart },
+	"error-gradient-end":     func(t *Theme) *Color { return &t.ErrorGradientEnd },
+	"spinner-gradient-start": func(t *Theme) *Color { return &t.SpinnerGradientStart },
+	"spinner-gradient-end":   func(t *Theme) *Color { return &t.SpinnerGradientEnd },
+}
+
+// overrides are the colors applied on top of the theme by Init.
+var overrides map[string]Color
+
+// Roles returns the semantic role names that can be overridden, sorted.
+func Roles() []string {
+	roles := make([]string, 0, len(roleFields))
+	for role := range roleFields {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// ParseColorOverride parses a role=color override such as "success=#00aa55".
+// The role must be one of Roles and the color a #RGB or #RRGGBB hex value.
+func ParseColorOverride(s string) (string, Color, error) {
+	role, value, ok := strings.Cut(s, "=")
+	role = strings.ToLower(strings.TrimSpace(role))
+	value = strings.TrimSpace(value)
+	if !ok || role == "" || value == "" {
+		return "", "", fmt.Errorf("invalid color override %q (want role=#rrggbb)", s)
+	}
+	if _, known := roleFields[role]; !known {
+		return "", "", fmt.Errorf("unknown color role %q (want one of %s)", role, strings.Join(Roles(), ", "))
+	}
+	if _, err := colorful.Hex(value); err != nil {
+		return "", "", fmt.Errorf("invalid color %q for %s (want #rgb or #rrggbb)", value, role)
+	}
+	return role, Color(value), nil
+}
+
+// Colors returns the color of each role of Roles in t.
+func (t Theme) Colors() map[string]Color {
+	colors := make(map[string]Color, len(roleFields))
+	for role, field := range roleFields {
+		colors[role] = *field(&t)
+	}
+	return colors
+}
+
+// SetOverrides sets the per-role colors that Init applies on top of the
+// theme, replacing any set before. Roles must come from ParseColorOverride;
+// unknown ones are ignored.
+func SetOverrides(colors map[string]Color) {
+	overrides = colors
+}
+
+// Apply returns t with the colors of overrides replacing those of their
+// roles.
+func (t Theme) Apply(overrides map[string]Color) Theme {
+	for role, c := range overrides {
+		if field, ok := roleFields[role]; ok {
+			*field(&t) = c
+		}
+	}
+	return t
+}
@@ -0,0 +1,100 @@
+package style
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+// ColorEnv is what the environment asks of colored output: the NO_COLOR
+// (https://no-color.org) and CLICOLOR_FORCE (https://bixense.com/clicolors)
+// conventions, and the colors TERM and COLORTERM say the terminal supports.
+type ColorEnv struct {
+	// NoColor is whether NO_COLOR is set, or TERM is dumb and color is not
+	// forced.
+	NoColor bool
+	// Forced is whether CLICOLOR_FORCE asks for color whatever TERM says.
+	Forced bool
+	// Dumb is whether TERM is dumb. Such terminals may not draw the
+	// box-drawing glyphs either, so Init uses ASCII ones.
+	Dumb bool
+	// Profile is the richest colors the terminal supports.
+	Profile colorprofile.Profile
+}
+
+var (
+	// profile is what SetProfile chose. TrueColor by default, so output is
+	// the same whether or not stdout is a terminal.
+	profile = colorprofile.TrueColor
+	// asciiGlyphs is what SetASCII chose.
+	asciiGlyphs bool
+)
+
+// DetectColorEnv reads the color conventions from getenv. NO_COLOR wins over
+// CLICOLOR_FORCE, and both are overridden by -no-color when it is given.
+//
+// Without TERM (pipes, CI) the profile is TrueColor, as viewscreen has
+// always written; otherwise it is TrueColor for COLORTERM=truecolor and the
+// terminals known to support it, 256 colors for a *256color TERM, and the 16
+// ANSI colors for anything else.
+func DetectColorEnv(getenv func(string) string) ColorEnv {
+	term := getenv("TERM")
+	forced := getenv("CLICOLOR_FORCE") != "" && getenv("CLICOLOR_FORCE") != "0"
+	env := ColorEnv{
+		Forced: forced,
+		Dumb:   term == "dumb",
+	}
+	env.NoColor = getenv("NO_COLOR") != "" || (env.Dumb && !forced)
+
+	colorterm := strings.ToLower(getenv("COLORTERM"))
+	switch {
+	case colorterm == "truecolor" || colorterm == "24bit", knownTruecolor(getenv):
+		env.Profile = colorprofile.TrueColor
+	case term == "", strings.HasSuffix(term, "direct"):
+		env.Profile = colorprofile.TrueColor
+	case strings.Contains(term, "256color"):
+		env.Profile = colorprofile.ANSI256
+	default:
+		env.Profile = colorprofile.ANSI
+	}
+	return env
+}
+
+// knownTruecolor reports terminals that support 24-bit color but may not
+// export COLORTERM (notably over ssh, which drops it). doctor checks the
+// same list.
+func knownTruecolor(getenv func(string) string) bool {
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+	return getenv("KITTY_WINDOW_ID") != "" || getenv("TERM") == "xterm-kitty" ||
+		getenv("WT_SESSION") != "" || getenv("ALACRITTY_WINDOW_ID") != ""
+}
+
+// SetProfile sets the colors Init and the styling functions emit. Below
+// 256 colors, gradients are drawn in their start color.
+func SetProfile(p colorprofile.Profile) {
+	profile = p
+}
+
+// Profile returns the SetProfile profile.
+func Profile() colorprofile.Profile {
+	return profile
+}
+
+// SetASCII sets whether Init uses ASCII bullets and prefixes instead of the
+// Unicode glyphs, for dumb terminals.
+func SetASCII(ascii bool) {
+	asciiGlyphs = ascii
+}
+
+// ProfileColor returns c in the colors of the SetProfile profile: the
+// nearest of the 256 or 16 colors when the terminal has no 24-bit color.
+func ProfileColor(c color.Color) color.Color {
+	if profile == colorprofile.TrueColor {
+		return c
+	}
+	return profile.Convert(c)
+}
@@ -0,0 +1,80 @@
+package style
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/colorprofile"
+)
+
+func TestDetectColorEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ColorEnv
+	}{
+		{"piped", nil, ColorEnv{Profile: colorprofile.TrueColor}},
+		{"COLORTERM", map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, ColorEnv{Profile: colorprofile.TrueColor}},
+		{"known terminal", map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, ColorEnv{Profile: colorprofile.TrueColor}},
+		{"256 colors", map[string]string{"TERM": "screen-256color"}, ColorEnv{Profile: colorprofile.ANSI256}},
+		{"16 colors", map[string]string{"TERM": "linux"}, ColorEnv{Profile: colorprofile.ANSI}},
+		{"NO_COLOR", map[string]string{"TERM": "xterm-256color", "NO_COLOR": "0"}, ColorEnv{NoColor: true, Profile: colorprofile.ANSI256}},
+		{"dumb", map[string]string{"TERM": "dumb"}, ColorEnv{NoColor: true, Dumb: true, Profile: colorprofile.ANSI}},
+		{"dumb forced", map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "1"}, ColorEnv{Forced: true, Dumb: true, Profile: colorprofile.ANSI}},
+		{"CLICOLOR_FORCE=0", map[string]string{"TERM": "dumb", "CLICOLOR_FORCE": "0"}, ColorEnv{NoColor: true, Dumb: true, Profile: colorprofile.ANSI}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectColorEnv(func(k string) string { return tt.env[k] })
+			if got != tt.want {
+				t.Errorf("DetectColorEnv = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradientProfiles(t *testing.T) {
+	defer func() {
+		SetProfile(colorprofile.TrueColor)
+		Init(false)
+	}()
+
+	SetProfile(colorprofile.TrueColor)
+	Init(false)
+	if got := ApplyGradient("ab", "#ff0000", "#0000ff"); !strings.Contains(got, "38;2;") {
+		t.Errorf("expected 24-bit colors, got %q", got)
+	}
+
+	SetProfile(colorprofile.ANSI256)
+	Init(false)
+	got := ApplyGradient("ab", "#ff0000", "#0000ff")
+	if strings.Contains(got, "38;2;") || strings.Count(got, "38;5;") != 2 {
+		t.Errorf("expected a gradient of 256 colors, got %q", got)
+	}
+
+	SetProfile(colorprofile.ANSI)
+	Init(false)
+	got = ApplyGradient("ab", "#ff0000", "#0000ff")
+	if strings.Contains(got, "38;") || strings.Count(got, "\x1b[") != 2 {
+		t.Errorf("expected one basic color for the whole text, got %q", got)
+	}
+}
+
+func TestInitASCII(t *testing.T) {
+	defer func() {
+		SetASCII(false)
+		Init(false)
+	}()
+
+	SetASCII(true)
+	Init(true)
+	if Bullet != "*" || OutputPrefix != "  |_ " || NestedOutputPrefix != "  |   |_ " {
+		t.Errorf("Bullet = %q, OutputPrefix = %q, NestedOutputPrefix = %q", Bullet, OutputPrefix, NestedOutputPrefix)
+	}
+
+	SetASCII(false)
+	Init(true)
+	if Bullet != "●" || OutputPrefix != "  ⎿  " {
+		t.Errorf("Bullet = %q, OutputPrefix = %q", Bullet, OutputPrefix)
+	}
+}
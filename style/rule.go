@@ -0,0 +1,25 @@
+package style
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Rule returns a subtle horizontal rule width columns wide, with label
+// after its first few columns when it is not empty, e.g.
+// "─── Turn 7 · ↑12k ↓340 ──────". The rule is drawn with "-" after
+// SetASCII(true).
+func Rule(width int, label string) string {
+	line := "─"
+	if asciiGlyphs {
+		line = "-"
+	}
+	width = max(width, 1)
+	if label == "" {
+		return SubtleText(strings.Repeat(line, width))
+	}
+	lead := strings.Repeat(line, 3) + " "
+	rest := width - ansi.StringWidth(lead+label) - 1
+	return SubtleText(lead) + MutedText(label) + SubtleText(" "+strings.Repeat(line, max(rest, 3)))
+}
@@ -1,6 +1,8 @@
 package style
 
 import (
+	"math"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -484,3 +486,129 @@ func TestInitStylesColorDisabled(t *testing.T) {
 		t.Errorf("DiffRemoveBg should be empty when color is disabled, got %q", DiffRemoveBg)
 	}
 }
+
+func TestParseColorOverride(t *testing.T) {
+	role, c, err := ParseColorOverride(" Success = #00aa55 ")
+	if err != nil || role != "success" || c != "#00aa55" {
+		t.Errorf("ParseColorOverride() = %q, %q, %v", role, c, err)
+	}
+
+	for _, s := range []string{"success", "=#fff", "success=", "sucess=#fff", "error=red"} {
+		if _, _, err := ParseColorOverride(s); err == nil {
+			t.Errorf("ParseColorOverride(%q) expected error", s)
+		}
+	}
+}
+
+func TestInitAppliesOverrides(t *testing.T) {
+	SetOverrides(map[string]Color{"success": "#00aa55", "diff-add-bg": "#003300"})
+	defer func() {
+		SetOverrides(nil)
+		Init(false)
+	}()
+
+	Init(false)
+	if CurrentTheme.Success != "#00aa55" || DiffAddBg != "#003300" {
+		t.Errorf("overrides not applied: Success = %q, DiffAddBg = %q", CurrentTheme.Success, DiffAddBg)
+	}
+	if CurrentTheme.Error != DefaultTheme.Error {
+		t.Errorf("Error = %q, want the theme color kept", CurrentTheme.Error)
+	}
+
+	Init(true)
+	if CurrentTheme != NoColorTheme {
+		t.Error("expected overrides to be ignored without color")
+	}
+}
+
+func TestRoles(t *testing.T) {
+	var theme Theme
+	for _, role := range Roles() {
+		*roleFields[role](&theme) = "#123456"
+	}
+	v := reflect.ValueOf(theme)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).String() != "#123456" {
+			t.Errorf("theme field %s has no role", v.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestInitUsesBaseTheme(t *testing.T) {
+	SetBaseTheme(HighContrastTheme)
+	defer func() {
+		SetBaseTheme(DefaultTheme)
+		Init(false)
+	}()
+
+	Init(false)
+	if CurrentTheme != HighContrastTheme {
+		t.Errorf("CurrentTheme = %+v, want HighContrastTheme", CurrentTheme)
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	theme, err := LookupTheme("High-Contrast")
+	if err != nil || theme != HighContrastTheme {
+		t.Errorf("LookupTheme(High-Contrast) = %v, %v", theme.FgBase, err)
+	}
+	if _, err := LookupTheme("solarized"); err == nil || !strings.Contains(err.Error(), "default, high-contrast") {
+		t.Errorf("expected unknown theme error listing the themes, got %v", err)
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		fg, bg Color
+		want   float64
+	}{
+		{"#000000", "#FFFFFF", 21},
+		{"#FFFFFF", "#FFFFFF", 1},
+		{"#777777", "#FFFFFF", 4.48},
+	}
+	for _, tt := range tests {
+		got, ok := ContrastRatio(tt.fg, tt.bg)
+		if !ok || math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("ContrastRatio(%s, %s) = %.2f, %v; want %.2f", tt.fg, tt.bg, got, ok, tt.want)
+		}
+	}
+	if _, ok := ContrastRatio("red", "#FFFFFF"); ok {
+		t.Error("expected an invalid color to be reported")
+	}
+}
+
+func TestHighContrastThemeMeetsAAA(t *testing.T) {
+	for name, theme := range map[string]Theme{"dark": HighContrastTheme, "light": HighContrastLightTheme} {
+		colors := theme.Colors()
+		for _, p := range contrastPairs {
+			if ratio, _ := ContrastRatio(colors[p.fg], colors[p.bg]); ratio < 7 {
+				t.Errorf("%s: %s on %s: contrast %.2f, want at least 7", name, p.fg, p.bg, ratio)
+			}
+		}
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	theme := DefaultTheme.Apply(map[string]Color{"fg-muted": "#333333"})
+	issues := theme.CheckContrast("fg-muted")
+	if len(issues) != 1 {
+		t.Fatalf("CheckContrast = %v, want one issue", issues)
+	}
+	issue := issues[0]
+	if issue.Fg != "fg-muted" || issue.Bg != "bg" || issue.Min != MinTextContrast || issue.Ratio >= MinTextContrast {
+		t.Errorf("issue = %+v", issue)
+	}
+	if ratio, _ := ContrastRatio(issue.Suggested, theme.BgBase); ratio < MinTextContrast {
+		t.Errorf("suggested %s has contrast %.2f, want at least %.1f", issue.Suggested, ratio, MinTextContrast)
+	}
+	if !strings.Contains(issue.String(), "try -color fg-muted=#") {
+		t.Errorf("String() = %q, want a suggested override", issue.String())
+	}
+
+	if issues := theme.CheckContrast("success"); len(issues) != 0 {
+		t.Errorf("CheckContrast(success) = %v, want pairs of other roles skipped", issues)
+	}
+	if issues := NoColorTheme.CheckContrast(); len(issues) != 0 {
+		t.Errorf("CheckContrast on NoColorTheme = %v, want unset colors skipped", issues)
+	}
+}
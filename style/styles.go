@@ -1,21 +1,23 @@
 package style
 
 import (
-	"github.com/charmbracelet/colorprofile"
-
 	"charm.land/lipgloss/v2"
 )
 
-const (
-	// Bullet is the icon for tool/action lines (without trailing space)
+// OutputContinue is the prefix for continued output lines
+const OutputContinue = "     "
+
+var (
+	// Bullet is the icon for tool/action lines (without trailing space).
+	// Init sets it to "*" for SetASCII.
 	Bullet = "●"
 	// OutputPrefix is the prefix for tool output lines
 	OutputPrefix = "  ⎿  "
-	// OutputContinue is the prefix for continued output lines
-	OutputContinue = "     "
 
 	// Nested prefix pipe character (unstyled, for composition)
 	nestedPipe = "│"
+	// outputElbow starts OutputPrefix and NestedOutputPrefix.
+	outputElbow = "⎿ "
 )
 
 var (
@@ -33,25 +35,42 @@ var (
 	// These are passed to HighlightFileWithBg() for syntax-highlighted diffs.
 	DiffAddBg    Color
 	DiffRemoveBg Color
+	// Stronger backgrounds for the changed words within a diff line.
+	DiffAddEmphBg    Color
+	DiffRemoveEmphBg Color
 
 	noColor bool
 )
 
-// Init initializes styles based on color settings
+// Init initializes styles based on color settings, applying the SetOverrides
+// colors on top of the SetBaseTheme theme, or its light variant on the
+// SetBackground background when that is light.
+//
+// Colors are emitted in the SetProfile profile, and the bullets and prefixes
+// are ASCII after SetASCII(true).
 func Init(disableColor bool) {
 	noColor = disableColor
+	lightBackground = false
+	initGlyphs()
 
 	if disableColor {
 		CurrentTheme = NoColorTheme
 	} else {
-		CurrentTheme = DefaultTheme
+		base := baseTheme
+		if isLight(background) {
+			lightBackground = true
+			if light, ok := lightVariants[base]; ok {
+				base = light
+			}
+		}
+		CurrentTheme = base.Apply(overrides)
 
-		// Force TrueColor output even when stdout is piped (not a TTY).
+		// Use the SetProfile profile even when stdout is piped (not a TTY).
 		//
 		// In Lipgloss v2, color handling is done through the Writer variable
-		// which uses colorprofile. We set TrueColor directly to ensure colors
-		// work in pipelines.
-		lipgloss.Writer.Profile = colorprofile.TrueColor
+		// which uses colorprofile. We set the profile directly, rather than
+		// let it detect one, to ensure colors work in pipelines.
+		lipgloss.Writer.Profile = profile
 	}
 
 	// Initialize nested prefixes with styled pipe character
@@ -60,19 +79,41 @@ func Init(disableColor bool) {
 	if noColor {
 		DiffAddBg = ""
 		DiffRemoveBg = ""
+		DiffAddEmphBg = ""
+		DiffRemoveEmphBg = ""
 		return
 	}
 
 	t := CurrentTheme
 	DiffAddBg = t.DiffAddBg
 	DiffRemoveBg = t.DiffRemoveBg
+	DiffAddEmphBg = t.DiffAddEmphBg
+	DiffRemoveEmphBg = t.DiffRemoveEmphBg
+}
+
+// DarkTheme returns the theme Init uses on a dark background: the
+// SetBaseTheme theme with the SetOverrides colors applied. It is
+// CurrentTheme unless Init picked a light variant.
+func DarkTheme() Theme {
+	return baseTheme.Apply(overrides)
+}
+
+// initGlyphs picks the Unicode or, for SetASCII, the ASCII bullet and
+// prefixes. Both are the same width, so output lines up either way.
+func initGlyphs() {
+	if asciiGlyphs {
+		Bullet, nestedPipe, outputElbow = "*", "|", "|_"
+	} else {
+		Bullet, nestedPipe, outputElbow = "●", "│", "⎿ "
+	}
+	OutputPrefix = "  " + outputElbow + " "
 }
 
 // initNestedPrefixes initializes the nested prefix strings with styled pipe characters.
 func initNestedPrefixes() {
 	styledPipe := SubtleText(nestedPipe)
 	NestedPrefix = "  " + styledPipe + " "
-	NestedOutputPrefix = "  " + styledPipe + "   ⎿  "
+	NestedOutputPrefix = "  " + styledPipe + "   " + outputElbow + " "
 	NestedOutputContinue = "  " + styledPipe + "      "
 }
 
@@ -1,5 +1,11 @@
 package style
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Color is a hex color string (e.g., "#A855F7").
 // This type replaces lipgloss.Color to avoid Lipgloss v1 dependency in the theme.
 // The actual styling is done by Ultraviolet, which accepts color.RGBA.
@@ -25,8 +31,10 @@ type Theme struct {
 	Accent  Color // Purple/magenta accent (Claude branding)
 
 	// Diff-specific colors
-	DiffAddBg    Color // Background for added lines
-	DiffRemoveBg Color // Background for removed lines
+	DiffAddBg        Color // Background for added lines
+	DiffRemoveBg     Color // Background for removed lines
+	DiffAddEmphBg    Color // Background for changed words in added lines
+	DiffRemoveEmphBg Color // Background for changed words in removed lines
 
 	// Gradient colors (for headers)
 	GradientStart Color
@@ -65,8 +73,10 @@ var DefaultTheme = Theme{
 	Accent:  "#A855F7", // Purple-500 (Claude-like)
 
 	// Diff colors (subtle backgrounds)
-	DiffAddBg:    "#14532D", // Green-900
-	DiffRemoveBg: "#7F1D1D", // Red-900
+	DiffAddBg:        "#14532D", // Green-900
+	DiffRemoveBg:     "#7F1D1D", // Red-900
+	DiffAddEmphBg:    "#15803D", // Green-700
+	DiffRemoveEmphBg: "#B91C1C", // Red-700
 
 	// Gradient (purple to violet-blue)
 	GradientStart: "#A855F7", // Purple-500
@@ -85,6 +95,137 @@ var DefaultTheme = Theme{
 	SpinnerGradientEnd:   "#22D3EE", // Cyan-400
 }
 
+// LightTheme is the DefaultTheme palette for light backgrounds, used on
+// light terminals and by the light mode of HTML exports. Each color is the
+// same hue a few shades darker (or, for backgrounds, lighter).
+var LightTheme = Theme{
+	// Foreground colors
+	FgBase:   "#18181B", // Zinc-900
+	FgMuted:  "#52525B", // Zinc-600
+	FgSubtle: "#A1A1AA", // Zinc-400
+
+	// Background colors
+	BgBase:    "#FAFAFA", // Zinc-50
+	BgSubtle:  "#F4F4F5", // Zinc-100
+	BgOverlay: "#E4E4E7", // Zinc-200
+
+	// Semantic colors
+	Success: "#16A34A", // Green-600
+	Error:   "#DC2626", // Red-600
+	Warning: "#CA8A04", // Yellow-600
+	Info:    "#0891B2", // Cyan-600
+	Accent:  "#9333EA", // Purple-600
+
+	// Diff colors (subtle backgrounds)
+	DiffAddBg:        "#DCFCE7", // Green-100
+	DiffRemoveBg:     "#FEE2E2", // Red-100
+	DiffAddEmphBg:    "#BBF7D0", // Green-200
+	DiffRemoveEmphBg: "#FECACA", // Red-200
+
+	// Gradient (purple to indigo)
+	GradientStart: "#9333EA", // Purple-600
+	GradientEnd:   "#4F46E5", // Indigo-600
+
+	// Success gradient (green to teal)
+	SuccessGradientStart: "#16A34A", // Green-600
+	SuccessGradientEnd:   "#0D9488", // Teal-600
+
+	// Error gradient (red to orange)
+	ErrorGradientStart: "#DC2626", // Red-600
+	ErrorGradientEnd:   "#EA580C", // Orange-600
+
+	// Spinner gradient (purple to cyan)
+	SpinnerGradientStart: "#9333EA", // Purple-600
+	SpinnerGradientEnd:   "#0891B2", // Cyan-600
+}
+
+// HighContrastTheme is a palette for low-vision users and washed-out
+// terminals: white and bright colors on black. Every pair the renderers
+// draw meets the WCAG AAA ratio of 7:1.
+var HighContrastTheme = Theme{
+	// Foreground colors
+	FgBase:   "#FFFFFF", // White
+	FgMuted:  "#E4E4E7", // Zinc-200
+	FgSubtle: "#A1A1AA", // Zinc-400
+
+	// Background colors
+	BgBase:    "#000000", // Black
+	BgSubtle:  "#18181B", // Zinc-900
+	BgOverlay: "#27272A", // Zinc-800
+
+	// Semantic colors
+	Success: "#86EFAC", // Green-300
+	Error:   "#FCA5A5", // Red-300
+	Warning: "#FDE047", // Yellow-300
+	Info:    "#67E8F9", // Cyan-300
+	Accent:  "#D8B4FE", // Purple-300
+
+	// Diff colors (dark enough for white text)
+	DiffAddBg:        "#052E16", // Green-950
+	DiffRemoveBg:     "#450A0A", // Red-950
+	DiffAddEmphBg:    "#166534", // Green-800
+	DiffRemoveEmphBg: "#991B1B", // Red-800
+
+	// Gradient (purple to indigo)
+	GradientStart: "#D8B4FE", // Purple-300
+	GradientEnd:   "#A5B4FC", // Indigo-300
+
+	// Success gradient (green to teal)
+	SuccessGradientStart: "#86EFAC", // Green-300
+	SuccessGradientEnd:   "#5EEAD4", // Teal-300
+
+	// Error gradient (red to orange)
+	ErrorGradientStart: "#FCA5A5", // Red-300
+	ErrorGradientEnd:   "#FDBA74", // Orange-300
+
+	// Spinner gradient (purple to cyan)
+	SpinnerGradientStart: "#D8B4FE", // Purple-300
+	SpinnerGradientEnd:   "#67E8F9", // Cyan-300
+}
+
+// HighContrastLightTheme is the HighContrastTheme palette for light
+// backgrounds: black and dark colors on white, meeting the same 7:1 ratio.
+var HighContrastLightTheme = Theme{
+	// Foreground colors
+	FgBase:   "#000000", // Black
+	FgMuted:  "#27272A", // Zinc-800
+	FgSubtle: "#52525B", // Zinc-600
+
+	// Background colors
+	BgBase:    "#FFFFFF", // White
+	BgSubtle:  "#FAFAFA", // Zinc-50
+	BgOverlay: "#F4F4F5", // Zinc-100
+
+	// Semantic colors
+	Success: "#166534", // Green-800
+	Error:   "#991B1B", // Red-800
+	Warning: "#713F12", // Yellow-900
+	Info:    "#155E75", // Cyan-800
+	Accent:  "#6B21A8", // Purple-800
+
+	// Diff colors (light enough for black text)
+	DiffAddBg:        "#F0FDF4", // Green-50
+	DiffRemoveBg:     "#FEF2F2", // Red-50
+	DiffAddEmphBg:    "#BBF7D0", // Green-200
+	DiffRemoveEmphBg: "#FECACA", // Red-200
+
+	// Gradient (purple to indigo)
+	GradientStart: "#6B21A8", // Purple-800
+	GradientEnd:   "#3730A3", // Indigo-800
+
+	// Success gradient (green to teal)
+	SuccessGradientStart: "#166534", // Green-800
+	SuccessGradientEnd:   "#115E59", // Teal-800
+
+	// Error gradient (red to orange)
+	ErrorGradientStart: "#991B1B", // Red-800
+	ErrorGradientEnd:   "#9A3412", // Orange-800
+
+	// Spinner gradient (purple to cyan)
+	SpinnerGradientStart: "#6B21A8", // Purple-800
+	SpinnerGradientEnd:   "#155E75", // Cyan-800
+}
+
 // NoColorTheme is used when color output is disabled
 var NoColorTheme = Theme{
 	FgBase:               "",
@@ -100,6 +241,8 @@ var NoColorTheme = Theme{
 	Accent:               "",
 	DiffAddBg:            "",
 	DiffRemoveBg:         "",
+	DiffAddEmphBg:        "",
+	DiffRemoveEmphBg:     "",
 	GradientStart:        "",
 	GradientEnd:          "",
 	SuccessGradientStart: "",
@@ -113,6 +256,47 @@ var NoColorTheme = Theme{
 // CurrentTheme holds the active theme
 var CurrentTheme = DefaultTheme
 
+// baseTheme is the theme Init applies the overrides to.
+var baseTheme = DefaultTheme
+
+// themes maps the names accepted by -theme to their palettes.
+var themes = map[string]Theme{
+	"default":       DefaultTheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// lightVariants maps the built-in themes, which are designed for dark
+// backgrounds, to their palettes for light ones. Init switches to the
+// variant on a light background; a theme without one is used as is.
+var lightVariants = map[Theme]Theme{
+	DefaultTheme:      LightTheme,
+	HighContrastTheme: HighContrastLightTheme,
+}
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the built-in theme with the given name.
+func LookupTheme(name string) (Theme, error) {
+	t, ok := themes[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// SetBaseTheme sets the theme Init applies the SetOverrides colors to.
+func SetBaseTheme(t Theme) {
+	baseTheme = t
+}
+
 // SetTheme sets the current theme
 func SetTheme(t Theme) {
 	CurrentTheme = t
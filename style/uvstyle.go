@@ -40,7 +40,7 @@ func styled(text string, s TextStyle) string {
 	}
 
 	style := &uv.Style{
-		Fg:        fg,
+		Fg:        ProfileColor(fg),
 		Attrs:     s.Attrs,
 		Underline: s.Underline,
 	}
@@ -94,6 +94,11 @@ func MutedText(text string) string {
 	return styled(text, themeStyle(CurrentTheme.FgMuted, 0))
 }
 
+// MutedItalicText applies muted foreground color in italics.
+func MutedItalicText(text string) string {
+	return styled(text, themeStyle(CurrentTheme.FgMuted, uv.AttrItalic))
+}
+
 // ErrorText applies error (red) foreground color.
 func ErrorText(text string) string {
 	return styled(text, themeStyle(CurrentTheme.Error, 0))
@@ -109,6 +114,49 @@ func SuccessBoldText(text string) string {
 	return styled(text, themeStyle(CurrentTheme.Success, uv.AttrBold))
 }
 
+// MatchText highlights search matches with bold warning (yellow) foreground.
+func MatchText(text string) string {
+	return styled(text, themeStyle(CurrentTheme.Warning, uv.AttrBold))
+}
+
+// SearchMatchText marks a TUI search match with the warning color on a
+// subtle background.
+func SearchMatchText(text string) string {
+	if noColor {
+		return text
+	}
+	style := &uv.Style{
+		Fg:    ProfileColor(hexToRGBA(string(CurrentTheme.Warning))),
+		Bg:    ProfileColor(hexToRGBA(string(CurrentTheme.BgOverlay))),
+		Attrs: uv.AttrBold,
+	}
+	return style.Styled(text)
+}
+
+// SearchCurrentMatchText marks the selected TUI search match on a warning
+// background, or in reverse video when color is disabled.
+func SearchCurrentMatchText(text string) string {
+	style := &uv.Style{Attrs: uv.AttrReverse}
+	if !noColor {
+		style = &uv.Style{
+			Fg:    ProfileColor(hexToRGBA(string(CurrentTheme.BgBase))),
+			Bg:    ProfileColor(hexToRGBA(string(CurrentTheme.Warning))),
+			Attrs: uv.AttrBold,
+		}
+	}
+	return style.Styled(text)
+}
+
+// Hyperlink wraps text in an OSC 8 hyperlink to url. Terminals without OSC 8
+// support show the text alone. Plain text is returned when color is disabled,
+// since that usually means the output is not a terminal.
+func Hyperlink(text, url string) string {
+	if noColor || url == "" {
+		return text
+	}
+	return ansi.SetHyperlink(url) + text + ansi.ResetHyperlink()
+}
+
 // InfoBoldText applies info (cyan) foreground color with bold.
 func InfoBoldText(text string) string {
 	return styled(text, themeStyle(CurrentTheme.Info, uv.AttrBold))
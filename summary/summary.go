@@ -0,0 +1,268 @@
+// Package summary collects the machine-readable summary of a session that
+// -summary-json writes: its outcome, cost, tokens, duration, the files
+// changed, errors, empty searches, permission denials, the watched paths
+// tool calls touched and how often each tool was called.
+//
+// Totals come from the final result event of a Claude Code session. Codex
+// sessions have none, so their turns and tokens are summed from turn events.
+package summary
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"sort"
+
+	"github.com/johnnyfreeman/viewscreen/codex"
+	"github.com/johnnyfreeman/viewscreen/metadata"
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/result"
+	"github.com/johnnyfreeman/viewscreen/types"
+	"github.com/johnnyfreeman/viewscreen/user"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
+)
+
+// Summary is the JSON document written for a session.
+type Summary struct {
+	IsError           bool           `json:"is_error"`
+	DurationMS        int            `json:"duration_ms"`
+	DurationAPIMS     int            `json:"duration_api_ms"`
+	NumTurns          int            `json:"num_turns"`
+	CostUSD           float64        `json:"cost_usd"`
+	Usage             Usage          `json:"usage"`
+	FilesChanged      []string       `json:"files_changed"`
+	Errors            []string       `json:"errors"`
+	ToolErrors        int            `json:"tool_errors"`
+	EmptySearches     int            `json:"empty_searches"`
+	PermissionDenials []Denial       `json:"permission_denials"`
+	ToolCounts        map[string]int `json:"tool_counts"`
+	// WatchedPaths are the tool calls that touched paths on the -watch
+	// watchlist, if any.
+	WatchedPaths []WatchedPath `json:"watched_paths,omitempty"`
+	// Tags are the -tag metadata the session was given, if any.
+	Tags map[string]string `json:"tags,omitempty"`
+	// CostByModel splits CostUSD by model, when the result reports it.
+	CostByModel map[string]float64 `json:"cost_by_model,omitempty"`
+	// Outcome classifies the session by the outcome.Default rules.
+	Outcome outcome.Outcome `json:"outcome"`
+}
+
+// Usage is the session's token usage.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// Denial is a tool call the user's permission settings refused.
+type Denial struct {
+	ToolName  string `json:"tool_name"`
+	ToolUseID string `json:"tool_use_id"`
+}
+
+// WatchedPath is a watched path a tool call touched.
+type WatchedPath struct {
+	ToolName  string `json:"tool_name"`
+	ToolUseID string `json:"tool_use_id"`
+	watchlist.Hit
+}
+
+// Collector builds a Summary from the raw input events of a session.
+type Collector struct {
+	summary  Summary
+	files    map[string]bool
+	calls    map[string]string // tool name by tool_use ID
+	finished bool              // the session reported a result
+}
+
+// NewCollector creates an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		summary: Summary{ToolCounts: make(map[string]int)},
+		files:   make(map[string]bool),
+		calls:   make(map[string]string),
+	}
+}
+
+// Observe folds one raw input line into the summary. Lines that are not
+// events are ignored.
+func (c *Collector) Observe(raw []byte) {
+	ev, ok := types.ParseLine(raw)
+	if !ok {
+		return
+	}
+	if codex.IsEventType(ev.Type) {
+		c.observeCodex(raw)
+		return
+	}
+	if meta, ok := metadata.Parse(raw); ok {
+		c.observeTags(meta.Tags)
+		return
+	}
+	blocks := ev.Blocks()
+
+	switch ev.Type {
+	case "assistant":
+		for _, b := range blocks {
+			if _, seen := c.calls[b.ID]; b.Type == "tool_use" && !seen {
+				c.calls[b.ID] = b.Name
+				c.summary.ToolCounts[b.Name]++
+				for _, hit := range watchlist.Default().Check(b.Name, b.Input) {
+					c.summary.WatchedPaths = append(c.summary.WatchedPaths, WatchedPath{ToolName: b.Name, ToolUseID: b.ID, Hit: hit})
+				}
+			}
+		}
+	case "user":
+		for _, b := range blocks {
+			if b.Type != "tool_result" {
+				continue
+			}
+			if b.IsError {
+				c.summary.ToolErrors++
+			} else if user.EmptySearch(c.calls[b.ToolUseID], ev.ToolUseResult) {
+				c.summary.EmptySearches++
+			}
+		}
+		var changed struct {
+			FilePath string `json:"filePath"`
+		}
+		if len(ev.ToolUseResult) > 0 && json.Unmarshal(ev.ToolUseResult, &changed) == nil && changed.FilePath != "" {
+			c.files[changed.FilePath] = true
+		}
+	case "result":
+		var res result.Event
+		if json.Unmarshal(raw, &res) == nil {
+			c.observeResult(res)
+			c.finished = true
+		}
+	}
+}
+
+// observeTags merges a metadata preamble's tags; a later preamble, such as
+// one added when replaying a tagged recording, wins on conflicting keys.
+func (c *Collector) observeTags(tags map[string]string) {
+	if len(tags) == 0 {
+		return
+	}
+	if c.summary.Tags == nil {
+		c.summary.Tags = make(map[string]string, len(tags))
+	}
+	maps.Copy(c.summary.Tags, tags)
+}
+
+func (c *Collector) observeResult(res result.Event) {
+	s := &c.summary
+	s.IsError = res.IsError
+	s.DurationMS = res.DurationMS
+	s.DurationAPIMS = res.DurationAPIMS
+	s.NumTurns = res.NumTurns
+	s.CostUSD = res.TotalCostUSD
+	s.CostByModel = nil
+	if len(res.ModelUsage) > 0 {
+		s.CostByModel = make(map[string]float64, len(res.ModelUsage))
+		for model, usage := range res.ModelUsage {
+			s.CostByModel[model] = usage.CostUSD
+		}
+	}
+	s.Usage = Usage{
+		InputTokens:              res.Usage.InputTokens,
+		OutputTokens:             res.Usage.OutputTokens,
+		CacheCreationInputTokens: res.Usage.CacheCreationInputTokens,
+		CacheReadInputTokens:     res.Usage.CacheReadInputTokens,
+	}
+	s.Errors = append(s.Errors, res.Errors...)
+	for _, d := range res.PermissionDenials {
+		s.PermissionDenials = append(s.PermissionDenials, Denial{ToolName: d.ToolName, ToolUseID: d.ToolUseID})
+	}
+}
+
+func (c *Collector) observeCodex(raw []byte) {
+	var ev codex.Event
+	if json.Unmarshal(raw, &ev) != nil {
+		return
+	}
+	s := &c.summary
+	switch ev.Type {
+	case codex.TypeTurnStarted:
+		s.NumTurns++
+		c.finished = false
+	case codex.TypeTurnCompleted:
+		c.finished = true
+		if ev.Usage != nil {
+			s.Usage.InputTokens += ev.Usage.InputTokens
+			s.Usage.OutputTokens += ev.Usage.OutputTokens
+			s.Usage.CacheReadInputTokens += ev.Usage.CachedInputTokens
+		}
+	case codex.TypeTurnFailed:
+		c.finished = true
+		s.IsError = true
+		if ev.Error != nil {
+			s.Errors = append(s.Errors, ev.Error.Message)
+		}
+	case codex.TypeError:
+		s.IsError = true
+		s.Errors = append(s.Errors, ev.Message)
+	case codex.TypeItemCompleted:
+		c.observeCodexItem(ev.Item)
+	}
+}
+
+func (c *Collector) observeCodexItem(item *codex.Item) {
+	if item == nil {
+		return
+	}
+	s := &c.summary
+	switch item.Type {
+	case codex.ItemCommandExecution, codex.ItemFileChange, codex.ItemWebSearch, codex.ItemTodoList:
+		s.ToolCounts[item.Type]++
+	case codex.ItemMCPToolCall:
+		s.ToolCounts["mcp__"+item.Server+"__"+item.Tool]++
+	case codex.ItemError:
+		s.Errors = append(s.Errors, item.Message)
+		return
+	default:
+		return
+	}
+	if item.Type == codex.ItemFileChange {
+		for _, change := range item.Changes {
+			c.files[change.Path] = true
+		}
+	}
+	if item.Status == "failed" || item.ExitCode != nil && *item.ExitCode != 0 {
+		s.ToolErrors++
+	}
+}
+
+// Summary returns the summary of the events observed so far. Lists are
+// empty rather than null, and files are sorted.
+func (c *Collector) Summary() Summary {
+	s := c.summary
+	s.FilesChanged = make([]string, 0, len(c.files))
+	for path := range c.files {
+		s.FilesChanged = append(s.FilesChanged, path)
+	}
+	sort.Strings(s.FilesChanged)
+	s.Errors = append([]string{}, s.Errors...)
+	s.PermissionDenials = append([]Denial{}, s.PermissionDenials...)
+	s.Tags = maps.Clone(c.summary.Tags)
+	s.CostByModel = maps.Clone(c.summary.CostByModel)
+	s.ToolCounts = make(map[string]int, len(c.summary.ToolCounts))
+	for name, n := range c.summary.ToolCounts {
+		s.ToolCounts[name] = n
+	}
+	s.Outcome = outcome.Default().Classify(outcome.Facts{
+		Finished:   c.finished,
+		IsError:    s.IsError,
+		ToolErrors: s.ToolErrors,
+		Denials:    len(s.PermissionDenials),
+	})
+	return s
+}
+
+// WriteJSON writes the summary to w as indented JSON.
+func (c *Collector) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Summary())
+}
@@ -0,0 +1,163 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/outcome"
+	"github.com/johnnyfreeman/viewscreen/watchlist"
+)
+
+func observeAll(c *Collector, lines ...string) {
+	for _, line := range lines {
+		c.Observe([]byte(line))
+	}
+}
+
+func TestCollector_Claude(t *testing.T) {
+	c := NewCollector()
+	observeAll(c,
+		`{"type":"system","subtype":"init"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"e1","name":"Edit"},{"type":"tool_use","id":"b1","name":"Bash"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"e1","name":"Edit"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"e1"}]},"tool_use_result":{"filePath":"b.go"}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"b1","is_error":true}]},"tool_use_result":"Error: exit status 1"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"w1","name":"Write"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"w1"}]},"tool_use_result":{"type":"create","filePath":"a.go"}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"g1","name":"Glob"}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"g1"}]},"tool_use_result":{"filenames":[],"numFiles":0}}`,
+		`{"type":"result","is_error":true,"duration_ms":5000,"duration_api_ms":3000,"num_turns":3,"total_cost_usd":0.25,`+
+			`"usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":30,"cache_read_input_tokens":40},`+
+			`"modelUsage":{"claude-opus":{"costUSD":0.2},"claude-haiku":{"costUSD":0.05}},`+
+			`"errors":["max turns"],"permission_denials":[{"tool_name":"WebFetch","tool_use_id":"f1"}]}`,
+		`not json`,
+	)
+
+	want := Summary{
+		IsError:           true,
+		DurationMS:        5000,
+		DurationAPIMS:     3000,
+		NumTurns:          3,
+		CostUSD:           0.25,
+		Usage:             Usage{InputTokens: 10, OutputTokens: 20, CacheCreationInputTokens: 30, CacheReadInputTokens: 40},
+		FilesChanged:      []string{"a.go", "b.go"},
+		Errors:            []string{"max turns"},
+		ToolErrors:        1,
+		EmptySearches:     1,
+		PermissionDenials: []Denial{{ToolName: "WebFetch", ToolUseID: "f1"}},
+		ToolCounts:        map[string]int{"Edit": 1, "Bash": 1, "Write": 1, "Glob": 1},
+		CostByModel:       map[string]float64{"claude-opus": 0.2, "claude-haiku": 0.05},
+		Outcome:           outcome.Failed,
+	}
+	if got := c.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestCollector_Tags(t *testing.T) {
+	c := NewCollector()
+	observeAll(c,
+		`{"type":"viewscreen_metadata","tags":{"repo":"viewscreen","run":"1"}}`,
+		`{"type":"viewscreen_metadata","tags":{"run":"2"}}`,
+	)
+	if got, want := c.Summary().Tags, map[string]string{"repo": "viewscreen", "run": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags = %v, want %v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := NewCollector().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"tags"`)) {
+		t.Errorf("expected no tags key without tags, got %s", buf.String())
+	}
+}
+
+func TestCollector_WatchedPaths(t *testing.T) {
+	w, err := watchlist.New([]string{".env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watchlist.SetDefault(w)
+	defer watchlist.SetDefault(nil)
+
+	c := NewCollector()
+	observeAll(c,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"/app/.env"}}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"r2","name":"Read","input":{"file_path":"/app/main.go"}}]}}`,
+	)
+	want := []WatchedPath{{ToolName: "Read", ToolUseID: "r1", Hit: watchlist.Hit{Path: "/app/.env", Pattern: ".env", Access: watchlist.Read}}}
+	if got := c.Summary().WatchedPaths; !reflect.DeepEqual(got, want) {
+		t.Errorf("WatchedPaths = %+v, want %+v", got, want)
+	}
+}
+
+func TestCollector_Codex(t *testing.T) {
+	c := NewCollector()
+	observeAll(c,
+		`{"type":"thread.started","thread_id":"t"}`,
+		`{"type":"turn.started"}`,
+		`{"type":"item.completed","item":{"id":"1","type":"command_execution","command":"false","exit_code":1,"status":"failed"}}`,
+		`{"type":"item.completed","item":{"id":"2","type":"file_change","changes":[{"path":"x.go","kind":"update"}],"status":"completed"}}`,
+		`{"type":"item.completed","item":{"id":"3","type":"mcp_tool_call","server":"docs","tool":"search","status":"completed"}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":50,"output_tokens":7}}`,
+	)
+
+	got := c.Summary()
+	if got.NumTurns != 1 || got.Usage.InputTokens != 100 || got.Usage.CacheReadInputTokens != 50 || got.Usage.OutputTokens != 7 {
+		t.Errorf("turns and usage = %d, %+v", got.NumTurns, got.Usage)
+	}
+	if !reflect.DeepEqual(got.FilesChanged, []string{"x.go"}) || got.ToolErrors != 1 {
+		t.Errorf("FilesChanged = %v, ToolErrors = %d", got.FilesChanged, got.ToolErrors)
+	}
+	wantCounts := map[string]int{"command_execution": 1, "file_change": 1, "mcp__docs__search": 1}
+	if !reflect.DeepEqual(got.ToolCounts, wantCounts) {
+		t.Errorf("ToolCounts = %v, want %v", got.ToolCounts, wantCounts)
+	}
+	if got.Outcome != outcome.Success {
+		t.Errorf("Outcome = %s, want success", got.Outcome)
+	}
+
+	c.Observe([]byte(`{"type":"turn.started"}`))
+	if got := c.Summary().Outcome; got != outcome.Interrupted {
+		t.Errorf("Outcome with a turn in progress = %s, want interrupted", got)
+	}
+}
+
+func TestCollector_Outcome(t *testing.T) {
+	defer outcome.SetDefault(outcome.DefaultRules)
+
+	c := NewCollector()
+	if got := c.Summary().Outcome; got != outcome.Interrupted {
+		t.Errorf("Outcome without a result = %s, want interrupted", got)
+	}
+	observeAll(c,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"1","is_error":true},{"type":"tool_result","tool_use_id":"2","is_error":true},{"type":"tool_result","tool_use_id":"3","is_error":true}]}}`,
+		`{"type":"result","is_error":false}`,
+	)
+	if got := c.Summary().Outcome; got != outcome.Partial {
+		t.Errorf("Outcome with 3 tool errors = %s, want partial", got)
+	}
+	outcome.SetDefault(outcome.Rules{MaxToolErrors: 3})
+	if got := c.Summary().Outcome; got != outcome.Success {
+		t.Errorf("Outcome with 3 tolerated tool errors = %s, want success", got)
+	}
+}
+
+func TestCollector_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCollector().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	for key, want := range map[string]string{"files_changed": "[]", "errors": "[]", "permission_denials": "[]", "tool_counts": "{}"} {
+		if string(doc[key]) != want {
+			t.Errorf("%s = %s, want %s", key, doc[key], want)
+		}
+	}
+}
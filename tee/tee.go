@@ -0,0 +1,74 @@
+// Package tee passes the raw input of a session through to a file or an
+// inherited file descriptor while viewscreen renders it, byte for byte, so
+// the machine-readable transcript is kept without a shell-level tee in the
+// pipeline.
+package tee
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Wrap returns a reader that yields r unchanged while copying everything
+// read to the file at path and to file descriptor fd. An empty path or a
+// zero fd skips that destination; with neither, r is returned as is. A
+// failed write stops the copy without interrupting rendering; the returned
+// Closer reports it, and must be closed once input is exhausted.
+func Wrap(path string, fd int, r io.Reader) (io.Reader, io.Closer, error) {
+	t := &teeWriter{}
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.files = append(t.files, f)
+	}
+	if fd != 0 {
+		f := os.NewFile(uintptr(fd), fmt.Sprintf("fd %d", fd))
+		if f == nil {
+			t.Close()
+			return nil, nil, fmt.Errorf("-tee-fd %d: invalid file descriptor", fd)
+		}
+		if _, err := f.Stat(); err != nil {
+			t.Close()
+			return nil, nil, fmt.Errorf("-tee-fd %d: %w", fd, err)
+		}
+		t.files = append(t.files, f)
+	}
+	if len(t.files) == 0 {
+		return r, t, nil
+	}
+	return io.TeeReader(r, t), t, nil
+}
+
+// teeWriter copies to each file until a write fails.
+type teeWriter struct {
+	files []*os.File
+	err   error
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if t.err != nil {
+		return len(p), nil
+	}
+	for _, f := range t.files {
+		if _, err := f.Write(p); err != nil {
+			t.err = fmt.Errorf("tee: %w", err)
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the files and returns the first write or close error.
+func (t *teeWriter) Close() error {
+	err := t.err
+	for _, f := range t.files {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("tee: %w", cerr)
+		}
+	}
+	t.files = nil
+	return err
+}
@@ -0,0 +1,102 @@
+package tee
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const input = "{\"type\":\"system\"}\r\n{\"type\":\"result\"}"
+
+func TestWrap_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw.jsonl")
+	r, c, err := Wrap(path, 0, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != input {
+		t.Errorf("read %q, want the input unchanged", got)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	teed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(teed) != input {
+		t.Errorf("teed %q, want %q byte for byte", teed, input)
+	}
+}
+
+func TestWrap_FD(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+
+	r, c, err := Wrap("", int(pw.Fd()), strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	teed, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(teed) != input {
+		t.Errorf("teed %q, want %q", teed, input)
+	}
+}
+
+func TestWrap_Off(t *testing.T) {
+	in := strings.NewReader(input)
+	r, c, err := Wrap("", 0, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != io.Reader(in) {
+		t.Error("expected the reader to be returned unchanged")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestWrap_BadFD(t *testing.T) {
+	if _, _, err := Wrap("", 987, strings.NewReader(input)); err == nil {
+		t.Error("expected an error for a descriptor that is not open")
+	}
+}
+
+func TestWrap_WriteErrorReportedOnClose(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr.Close()
+
+	r, c, err := Wrap("", int(pw.Fd()), strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != input {
+		t.Fatalf("read %q, %v; want the input despite the failed copy", got, err)
+	}
+	if err := c.Close(); err == nil {
+		t.Error("expected the write error from Close")
+	}
+}
@@ -10,11 +10,29 @@ import (
 // DefaultWidth is the fallback terminal width when detection fails.
 const DefaultWidth = 80
 
-// Width returns the current terminal width, or DefaultWidth if detection fails.
+// fixedWidth is the width SetWidth fixed, or 0 to detect it.
+var fixedWidth int
+
+// Width returns the width SetWidth fixed, or else the current terminal width,
+// or DefaultWidth if detection fails.
 // This centralizes terminal width detection to avoid duplication across packages.
 func Width() int {
+	if fixedWidth > 0 {
+		return fixedWidth
+	}
 	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
 		return w
 	}
 	return DefaultWidth
 }
+
+// SetWidth fixes the width Width returns, for -width; 0 detects it again.
+func SetWidth(width int) {
+	fixedWidth = max(width, 0)
+}
+
+// FixedWidth reports whether SetWidth fixed the width, so it does not
+// follow the terminal when it is resized.
+func FixedWidth() bool {
+	return fixedWidth > 0
+}
@@ -15,3 +15,15 @@ func TestWidth(t *testing.T) {
 		t.Errorf("Width() = %d, expected positive value", w)
 	}
 }
+
+func TestSetWidth(t *testing.T) {
+	defer SetWidth(0)
+	SetWidth(42)
+	if w := Width(); w != 42 || !FixedWidth() {
+		t.Errorf("Width() = %d, FixedWidth() = %v; want 42, true", w, FixedWidth())
+	}
+	SetWidth(0)
+	if FixedWidth() {
+		t.Error("expected SetWidth(0) to detect the width again")
+	}
+}
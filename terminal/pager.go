@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// DefaultPager is the pager run when $PAGER is unset.
+const DefaultPager = "less"
+
+// colorFlags are the flags that make a pager pass ANSI colors through, by
+// pager name, and the equivalent flags a user may already have given.
+var colorFlags = map[string]struct {
+	add   string
+	given []string
+}{
+	"less": {add: "-R", given: []string{"-R", "-r", "--RAW-CONTROL-CHARS", "--raw-control-chars"}},
+}
+
+// Height returns the current terminal height, or 0 if stdout is not a
+// terminal.
+func Height() int {
+	if _, h, err := term.GetSize(int(os.Stdout.Fd())); err == nil && h > 0 {
+		return h
+	}
+	return 0
+}
+
+// PagerCommand returns the program and arguments of the pager spec names,
+// a command line such as the value of $PAGER, or DefaultPager when spec is
+// blank. Known pagers get the flags that keep colors, e.g. -R for less.
+func PagerCommand(spec string) (string, []string) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		fields = []string{DefaultPager}
+	}
+	name, args := fields[0], fields[1:]
+	if flags, ok := colorFlags[filepath.Base(name)]; ok && !slices.ContainsFunc(args, func(arg string) bool {
+		return slices.Contains(flags.given, arg)
+	}) {
+		args = append([]string{flags.add}, args...)
+	}
+	return name, args
+}
+
+// Pager writes output to a pager once it grows taller than a threshold.
+// Until then it holds the output back: output that stays shorter is written
+// to out when the Pager is closed, and longer output is piped into the pager
+// as it is written, so nothing is shown twice.
+type Pager struct {
+	spec      string
+	threshold int
+	out       io.Writer
+	errOut    io.Writer
+
+	held  bytes.Buffer
+	lines int
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	err   error // why the pager could not be started
+	quit  bool  // the user quit the pager before the output ended
+}
+
+// NewPager returns a Pager that runs the pager spec names (see
+// PagerCommand), writing to out and errOut, once more than threshold lines
+// have been written.
+func NewPager(spec string, threshold int, out, errOut io.Writer) *Pager {
+	return &Pager{spec: spec, threshold: threshold, out: out, errOut: errOut}
+}
+
+// Write holds b back, or writes it to the pager once the output is taller
+// than the threshold. Output written after the user quits the pager is
+// dropped, and a pager that cannot be started falls back to out.
+func (p *Pager) Write(b []byte) (int, error) {
+	switch {
+	case p.quit:
+		return len(b), nil
+	case p.stdin != nil:
+		if _, err := p.stdin.Write(b); err != nil {
+			p.quit = true
+		}
+		return len(b), nil
+	case p.err != nil:
+		return p.out.Write(b)
+	}
+	p.held.Write(b)
+	p.lines += bytes.Count(b, []byte("\n"))
+	if p.lines > p.threshold {
+		p.start()
+	}
+	return len(b), nil
+}
+
+// start runs the pager and writes the output held back to it, or to out
+// when the pager cannot be started.
+func (p *Pager) start() {
+	name, args := PagerCommand(p.spec)
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = p.out
+	cmd.Stderr = p.errOut
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		p.err = err
+		_, _ = p.held.WriteTo(p.out)
+		return
+	}
+	p.cmd, p.stdin = cmd, stdin
+	if _, err := p.held.WriteTo(stdin); err != nil {
+		p.quit = true
+	}
+}
+
+// Close writes output that stayed within the threshold to out, or ends the
+// pager's input and waits for the user to quit it. It returns the error
+// that kept the pager from starting, if any.
+func (p *Pager) Close() error {
+	if p.cmd == nil {
+		_, err := p.held.WriteTo(p.out)
+		return errors.Join(p.err, err)
+	}
+	_ = p.stdin.Close()
+	return p.cmd.Wait()
+}
@@ -0,0 +1,79 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		spec     string
+		wantName string
+		wantArgs []string
+	}{
+		{"", "less", []string{"-R"}},
+		{"  ", "less", []string{"-R"}},
+		{"less -S", "less", []string{"-R", "-S"}},
+		{"/usr/bin/less -r", "/usr/bin/less", []string{"-r"}},
+		{"less --RAW-CONTROL-CHARS", "less", []string{"--RAW-CONTROL-CHARS"}},
+		{"more", "more", []string{}},
+		{"bat --paging=always", "bat", []string{"--paging=always"}},
+	}
+	for _, tt := range tests {
+		name, args := PagerCommand(tt.spec)
+		if name != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+			t.Errorf("PagerCommand(%q) = %q %q, want %q %q", tt.spec, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
+
+func TestPager(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	t.Run("short output is written on close", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPager("viewscreen-no-such-pager", 2, &out, &bytes.Buffer{})
+		fmt.Fprint(p, "one\ntwo\n")
+		if out.Len() != 0 {
+			t.Errorf("wrote %q before close, want the output held back", out.String())
+		}
+		if err := p.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if out.String() != "one\ntwo\n" {
+			t.Errorf("wrote %q, want the output once", out.String())
+		}
+	})
+
+	t.Run("long output goes through the pager", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPager("cat", 2, &out, &bytes.Buffer{})
+		fmt.Fprint(p, "\x1b[1mone\x1b[0m\ntwo\n")
+		fmt.Fprint(p, "three\n")
+		fmt.Fprint(p, "four\n")
+		if err := p.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if out.String() != "\x1b[1mone\x1b[0m\ntwo\nthree\nfour\n" {
+			t.Errorf("pager wrote %q, want the output once and unchanged", out.String())
+		}
+	})
+
+	t.Run("missing pager falls back to out", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPager("viewscreen-no-such-pager", 1, &out, &bytes.Buffer{})
+		fmt.Fprint(p, "one\ntwo\n")
+		fmt.Fprint(p, "three\n")
+		if err := p.Close(); err == nil {
+			t.Error("expected an error for a missing pager")
+		}
+		if out.String() != "one\ntwo\nthree\n" {
+			t.Errorf("wrote %q, want the output once", out.String())
+		}
+	})
+}
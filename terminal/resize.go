@@ -0,0 +1,15 @@
+//go:build unix
+
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// NotifyResize relays SIGWINCH, sent when the terminal is resized, to c.
+// Stop it with signal.Stop(c).
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
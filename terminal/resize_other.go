@@ -0,0 +1,9 @@
+//go:build !unix
+
+package terminal
+
+import "os"
+
+// NotifyResize does nothing where there is no SIGWINCH; the width is the
+// one detected at startup.
+func NotifyResize(c chan<- os.Signal) {}
@@ -19,6 +19,8 @@ func (m MockStyleApplier) MutedText(text string) string       { return "[MUTED:"
 func (m MockStyleApplier) ErrorText(text string) string       { return "[ERROR:" + text + "]" }
 func (m MockStyleApplier) ErrorBoldText(text string) string   { return "[ERROR_BOLD:" + text + "]" }
 func (m MockStyleApplier) SuccessBoldText(text string) string { return "[SUCCESS_BOLD:" + text + "]" }
+func (m MockStyleApplier) MatchText(text string) string       { return "[MATCH:" + text + "]" }
+func (m MockStyleApplier) Hyperlink(text, url string) string  { return "[LINK:" + url + "|" + text + "]" }
 
 // Output prefixes
 func (m MockStyleApplier) OutputPrefix() string   { return "  ⎿  " }
@@ -29,6 +31,8 @@ func (m MockStyleApplier) LineNumberRender(text string) string    { return "[LN:
 func (m MockStyleApplier) LineNumberSepRender(text string) string { return "│" }
 func (m MockStyleApplier) DiffAddBg() style.Color                 { return "#00ff00" }
 func (m MockStyleApplier) DiffRemoveBg() style.Color              { return "#ff0000" }
+func (m MockStyleApplier) DiffAddEmphBg() style.Color             { return "#00aa00" }
+func (m MockStyleApplier) DiffRemoveEmphBg() style.Color          { return "#aa0000" }
 
 // Session/header styles
 func (m MockStyleApplier) SessionHeaderRender(text string) string    { return "[HEADER:" + text + "]" }
@@ -77,16 +81,22 @@ func (m *TrackingStyleApplier) ApplyErrorGradient(text string) string {
 
 // MockConfigProvider is a test double for config.Provider.
 type MockConfigProvider struct {
-	VerboseLevelVal int
-	NoColorVal      bool
-	ShowUsageVal    bool
+	VerboseLevelVal    int
+	NoColorVal         bool
+	ShowUsageVal       bool
+	DiffLayoutVal      string
+	NoDiffHighlightVal bool
+	ShowWhitespaceVal  bool
 }
 
 func (m MockConfigProvider) IsVerbose() bool      { return m.VerboseLevelVal >= 1 }
 func (m MockConfigProvider) IsVeryVerbose() bool   { return m.VerboseLevelVal >= 2 }
-func (m MockConfigProvider) GetVerboseLevel() int  { return m.VerboseLevelVal }
+func (m MockConfigProvider) VerbosityLevel() int   { return m.VerboseLevelVal }
 func (m MockConfigProvider) NoColor() bool         { return m.NoColorVal }
 func (m MockConfigProvider) ShowUsage() bool       { return m.ShowUsageVal }
+func (m MockConfigProvider) DiffLayout() string    { return m.DiffLayoutVal }
+func (m MockConfigProvider) NoDiffHighlight() bool { return m.NoDiffHighlightVal }
+func (m MockConfigProvider) ShowWhitespace() bool  { return m.ShowWhitespaceVal }
 
 // StripANSI removes ANSI escape sequences from a string.
 // Useful for testing output that may contain color codes.
@@ -24,6 +24,56 @@ func Truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// ShortenPath fits a file path into maxWidth cells by replacing directories
+// in the middle with "…", keeping the leading directories and the basename
+// visible: "/home/u/…/internal/parser/parser.go". A basename too wide on its
+// own keeps its end, where the extension is.
+func ShortenPath(path string, maxWidth int) string {
+	if ansi.StringWidth(path) <= maxWidth {
+		return path
+	}
+	parts := strings.Split(path, "/")
+	last := len(parts) - 1
+	// elide joins parts[:head] and parts[tail:] around an ellipsis.
+	elide := func(head, tail int) string {
+		return strings.Join(parts[:head], "/") + "/…/" + strings.Join(parts[tail:], "/")
+	}
+	fits := func(head, tail int) bool {
+		return ansi.StringWidth(elide(head, tail)) <= maxWidth
+	}
+
+	if last < 2 || !fits(1, last) {
+		base := parts[last]
+		width := ansi.StringWidth(base)
+		switch {
+		case last > 0 && width+2 <= maxWidth:
+			return "…/" + base
+		case width < maxWidth:
+			return "…" + base
+		case maxWidth < 1:
+			return ""
+		default:
+			return ansi.TruncateLeft(base, width-maxWidth+1, "…")
+		}
+	}
+	// Grow both ends a directory at a time until nothing more fits.
+	head, tail := 1, last
+	for {
+		grew := false
+		if tail-1 > head && fits(head, tail-1) {
+			tail--
+			grew = true
+		}
+		if head+1 < tail && fits(head+1, tail) {
+			head++
+			grew = true
+		}
+		if !grew {
+			return elide(head, tail)
+		}
+	}
+}
+
 // systemReminderRegex matches <system-reminder>...</system-reminder> blocks
 var systemReminderRegex = regexp.MustCompile(`(?s)<system-reminder>.*?</system-reminder>\s*`)
 
@@ -52,6 +102,18 @@ func StripTerminalControls(s string) string {
 // DefaultMaxLines is the default number of lines to show before truncating.
 const DefaultMaxLines = 15
 
+// ScaleMaxLines scales a fixed line limit to a line budget, such as a share
+// of the TUI viewport height. The limit grows or shrinks in proportion to
+// budget/DefaultMaxLines, so a block that shows 10 lines by default shows
+// the same share of a taller or shorter screen. Limits of 0 (don't expand)
+// and -1 (no limit) and budgets <= 0 (no budget) leave fixed unchanged.
+func ScaleMaxLines(fixed, budget int) int {
+	if fixed <= 0 || budget <= 0 {
+		return fixed
+	}
+	return max(1, fixed*budget/DefaultMaxLines)
+}
+
 // WrapText wraps text to fit within maxWidth, breaking on word boundaries.
 // Limits output to 3 lines maximum, adding "..." if truncated.
 func WrapText(s string, maxWidth int) string {
@@ -98,7 +160,8 @@ func WrapText(s string, maxWidth int) string {
 }
 
 // TruncateLines limits output to maxLines and returns the truncated content
-// along with the number of remaining lines (0 if not truncated).
+// along with the number of remaining lines (0 if not truncated). A negative
+// maxLines means no limit.
 func TruncateLines(content string, maxLines int) (truncated string, remaining int) {
 	lines := strings.Split(content, "\n")
 
@@ -107,7 +170,7 @@ func TruncateLines(content string, maxLines int) (truncated string, remaining in
 		lines = lines[:len(lines)-1]
 	}
 
-	if len(lines) <= maxLines {
+	if maxLines < 0 || len(lines) <= maxLines {
 		return content, 0
 	}
 
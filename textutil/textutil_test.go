@@ -91,6 +91,34 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestShortenPath(t *testing.T) {
+	const long = "/home/u/projects/viewscreen/internal/parser/parser.go"
+	tests := []struct {
+		name     string
+		path     string
+		maxWidth int
+		expected string
+	}{
+		{"fits unchanged", "/etc/hosts", 20, "/etc/hosts"},
+		{"keeps head and tail", long, 40, "/home/u/…/internal/parser/parser.go"},
+		{"narrower", long, 30, "/home/u/…/parser/parser.go"},
+		{"only root and basename", long, 12, "/…/parser.go"},
+		{"relative path", "src/a/b/c/d/main.go", 15, "src/…/d/main.go"},
+		{"only the basename fits", "src/a/b/parser.go", 11, "…/parser.go"},
+		{"basename alone", "src/a/b/parser.go", 10, "…parser.go"},
+		{"basename too wide", "/tmp/a_very_long_file_name.go", 10, "…e_name.go"},
+		{"no directories", "a_very_long_file_name.go", 8, "…name.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShortenPath(tt.path, tt.maxWidth)
+			if got != tt.expected {
+				t.Errorf("ShortenPath(%q, %d) = %q, want %q", tt.path, tt.maxWidth, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestStripSystemReminders(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -567,6 +595,28 @@ func TestTruncationIndicator(t *testing.T) {
 	}
 }
 
+func TestScaleMaxLines(t *testing.T) {
+	tests := []struct {
+		fixed, budget int
+		expected      int
+	}{
+		{10, 0, 10},  // no budget
+		{10, 15, 10}, // budget matches the default
+		{10, 30, 20}, // taller screen
+		{5, 6, 2},    // shorter screen
+		{5, 1, 1},    // never below one line
+		{0, 30, 0},   // don't expand
+		{-1, 30, -1}, // no limit
+	}
+
+	for _, tt := range tests {
+		result := ScaleMaxLines(tt.fixed, tt.budget)
+		if result != tt.expected {
+			t.Errorf("ScaleMaxLines(%d, %d) = %d, want %d", tt.fixed, tt.budget, result, tt.expected)
+		}
+	}
+}
+
 func TestContentCleaner(t *testing.T) {
 	t.Run("empty cleaner does nothing", func(t *testing.T) {
 		c := NewContentCleaner()
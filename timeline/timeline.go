@@ -13,8 +13,47 @@ type Entry struct {
 	Lines    []string
 	Nested   bool
 	Status   string
+	// Turn is the agent turn the entry belongs to, counted from 1; entries
+	// before the first turn (e.g. session init) have 0.
+	Turn int
+	// Full is the entry rendered with nothing truncated, for views that let
+	// the reader expand it (the TUI's folds). Empty when not rendered.
+	Full string
+	// Verbose re-renders the entry from the event it was made from at the
+	// highest verbosity, for views that raise the verbosity of one block
+	// (the TUI's v), such as thinking shown in full. Nil when there is
+	// nothing more to show.
+	Verbose func() string
+	// Replaces marks an entry that supersedes the most recent entry with
+	// the same Kind, Title and Arg, such as a file Read merged with the
+	// chunks of it read before. Append-only outputs show both.
+	Replaces bool
+	// TaskID is the ID of the Task call whose sub-agent the entry belongs
+	// to; empty for the main agent's entries.
+	TaskID string
+	// SubAgent summarizes the sub-agent of the Task call whose result the
+	// entry shows. Nil for other entries.
+	SubAgent *SubAgent
 }
 
+// SubAgent summarizes what the sub-agent of a Task call did, for exports
+// to head its section with.
+type SubAgent struct {
+	TaskID       string
+	Turns        int
+	InputTokens  int
+	OutputTokens int
+	// Tools counts the sub-agent's tool calls by tool name.
+	Tools map[string]int
+}
+
+// StatusError marks an entry showing a failed tool call, turn or session.
+const StatusError = "error"
+
+// KindTurnDivider is the Kind of the dividers between the main agent's
+// turns. Exports, which mark turns their own way, leave them out.
+const KindTurnDivider = "turn-divider"
+
 // Text returns the rendered terminal text for the entry.
 func (e Entry) Text() string {
 	if e.Body != "" {
@@ -35,6 +74,38 @@ func (e Entry) Text() string {
 	return string(out)
 }
 
+// Merge appends added to entries, except that an entry marked Replaces
+// takes the place of the entry it supersedes when entries has one.
+func Merge(entries []Entry, added ...Entry) []Entry {
+	for _, entry := range added {
+		if i := superseded(entries, entry); i >= 0 {
+			entries[i] = entry
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// superseded returns the index of the entry that entry replaces, or -1.
+func superseded(entries []Entry, entry Entry) int {
+	if !entry.Replaces {
+		return -1
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Kind == entry.Kind && e.Title == entry.Title && e.Arg == entry.Arg {
+			return i
+		}
+	}
+	return -1
+}
+
+// Foldable reports whether the entry has more to show when expanded.
+func (e Entry) Foldable() bool {
+	return (e.Full != "" && e.Full != e.Text()) || e.Verbose != nil
+}
+
 // Activity is a live/pending timeline item.
 type Activity struct {
 	ID             string
@@ -43,6 +114,9 @@ type Activity struct {
 	Input          string
 	Nested         bool
 	HeaderRendered bool
+	// Output is what the activity has printed so far, for agents that
+	// stream it (Codex command executions). Empty otherwise.
+	Output string
 }
 
 // Todo is a provider-neutral task item.
@@ -66,6 +140,7 @@ type StatePatch struct {
 	IncrementTurns int
 	TurnCount      *int
 	TotalCost      *float64
+	AddCost        float64
 
 	Todos        []Todo
 	ReplaceTodos bool
@@ -80,6 +155,12 @@ type StatePatch struct {
 	ReasoningTokens *int
 	AddUsage        *Usage
 
+	// ContextTokens is the size of the latest main-agent request: its
+	// input, cache and output tokens. ContextWindow is the model's window,
+	// when the stream reports it.
+	ContextTokens *int
+	ContextWindow *int
+
 	IsError       *bool
 	DurationMS    *int
 	DurationAPIMS *int
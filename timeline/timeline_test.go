@@ -0,0 +1,24 @@
+package timeline
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	entries := []Entry{
+		{Kind: "user", Title: "Read", Arg: "a.go", Body: "chunk 1\n"},
+		{Kind: "assistant", Body: "text\n"},
+	}
+	entries = Merge(entries,
+		Entry{Kind: "user", Title: "Read", Arg: "a.go", Body: "chunks 1-2\n", Replaces: true},
+		Entry{Kind: "user", Title: "Read", Arg: "b.go", Body: "other\n", Replaces: true},
+	)
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Body != "chunks 1-2\n" {
+		t.Errorf("expected the first Read replaced in place, got %q", entries[0].Body)
+	}
+	if entries[2].Body != "other\n" {
+		t.Errorf("expected an entry with nothing to replace to be appended, got %q", entries[2].Body)
+	}
+}
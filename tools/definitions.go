@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// fileDefinition is one entry of a tool definitions file. Exactly one of
+// Name and Server is set: Name defines a single tool, Server every tool of
+// an MCP server.
+type fileDefinition struct {
+	Name             string `json:"name"`
+	Server           string `json:"server"`
+	HeaderField      string `json:"header_field"`
+	HeaderFallback   string `json:"header_fallback"`
+	FilePathField    string `json:"file_path_field"`
+	FilePathFallback string `json:"file_path_fallback"`
+	CountField       string `json:"count_field"`
+	Singular         string `json:"singular"`
+	Plural           string `json:"plural"`
+	PatternField     string `json:"pattern_field"`
+}
+
+// LoadDefinitions reads a tool definitions file and registers its entries.
+func LoadDefinitions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading tool definitions: %w", err)
+	}
+	if err := RegisterDefinitions(data); err != nil {
+		return fmt.Errorf("parsing tool definitions %s: %w", path, err)
+	}
+	return nil
+}
+
+// RegisterDefinitions decodes a JSON list of tool definitions and registers
+// them, replacing any definition of the same name:
+//
+//	[
+//	  {"name": "Deploy", "header_field": "environment"},
+//	  {"name": "Tag", "count_field": "files", "singular": "file"},
+//	  {"server": "github", "header_field": "query"}
+//	]
+//
+// Nothing is registered if any entry is invalid.
+func RegisterDefinitions(data []byte) error {
+	var entries []fileDefinition
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	defs := make([]ToolDefinition, len(entries))
+	for i, e := range entries {
+		if (e.Name == "") == (e.Server == "") {
+			return fmt.Errorf("entry %d: set exactly one of name or server", i+1)
+		}
+		if e.CountField != "" && e.Singular == "" {
+			return fmt.Errorf("entry %d: count_field requires singular", i+1)
+		}
+		if e.Plural == "" && e.Singular != "" {
+			e.Plural = e.Singular + "s"
+		}
+		defs[i] = ToolDefinition{
+			Name:             e.Name,
+			HeaderField:      e.HeaderField,
+			HeaderFallback:   e.HeaderFallback,
+			FilePathField:    e.FilePathField,
+			FilePathFallback: e.FilePathFallback,
+			CountField:       e.CountField,
+			Singular:         e.Singular,
+			Plural:           e.Plural,
+			PatternField:     e.PatternField,
+		}
+	}
+	for i, def := range defs {
+		if server := entries[i].Server; server != "" {
+			RegisterMCPServer(server, def)
+		} else {
+			RegisterDefinition(def)
+		}
+	}
+	return nil
+}
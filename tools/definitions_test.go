@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withEmptyRegistry(t *testing.T) {
+	t.Helper()
+	origDefs, origServers := definitions, mcpServers
+	t.Cleanup(func() { definitions, mcpServers = origDefs, origServers })
+	definitions = map[string]ToolDefinition{}
+	mcpServers = map[string]ToolDefinition{}
+}
+
+func TestRegisterDefinitions(t *testing.T) {
+	withEmptyRegistry(t)
+
+	err := RegisterDefinitions([]byte(`[
+		{"name": "Deploy", "header_field": "environment"},
+		{"name": "Tag", "count_field": "files", "singular": "file"},
+		{"name": "Lint", "header_field": "path", "file_path_field": "path"},
+		{"server": "github", "header_field": "repo"}
+	]`))
+	if err != nil {
+		t.Fatalf("RegisterDefinitions() error = %v", err)
+	}
+
+	tests := []struct {
+		toolName string
+		input    map[string]interface{}
+		expected string
+	}{
+		{"Deploy", map[string]interface{}{"environment": "staging"}, "staging"},
+		{"Tag", map[string]interface{}{"files": []interface{}{"a", "b"}}, "2 files"},
+		{"Tag", map[string]interface{}{"files": []interface{}{"a"}}, "1 file"},
+		{"mcp__github__list_issues", map[string]interface{}{"repo": "acme/app", "query": "bug"}, "acme/app"},
+	}
+	for _, tt := range tests {
+		def, ok := GetDefinition(tt.toolName)
+		if !ok {
+			t.Fatalf("GetDefinition(%q) not found", tt.toolName)
+		}
+		if got := def.RenderHeader(tt.input); got != tt.expected {
+			t.Errorf("%s header = %q, want %q", tt.toolName, got, tt.expected)
+		}
+	}
+	if !IsFilePathTool("Lint") {
+		t.Error("expected Lint to be a file path tool")
+	}
+}
+
+func TestRegisterDefinitions_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed JSON", `[{"name": }]`},
+		{"missing name and server", `[{"header_field": "x"}]`},
+		{"both name and server", `[{"name": "A", "server": "b"}]`},
+		{"count without singular", `[{"name": "A", "count_field": "items"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEmptyRegistry(t)
+			if err := RegisterDefinitions([]byte(tt.data)); err == nil {
+				t.Fatalf("expected error for %s", tt.data)
+			}
+			if len(definitions) != 0 {
+				t.Errorf("registered %v despite error", definitions)
+			}
+		})
+	}
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	withEmptyRegistry(t)
+
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "Deploy", "header_field": "environment"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadDefinitions(path); err != nil {
+		t.Fatalf("LoadDefinitions() error = %v", err)
+	}
+	if _, ok := GetDefinition("Deploy"); !ok {
+		t.Error("expected Deploy to be registered")
+	}
+	if err := LoadDefinitions(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
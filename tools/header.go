@@ -7,8 +7,11 @@ import (
 	"io"
 	"os"
 
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/pagetitle"
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/textutil"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
 
@@ -101,26 +104,32 @@ func (r *HeaderRenderer) RenderToString(toolName string, input map[string]any) (
 // renderTo is the core rendering logic.
 func (r *HeaderRenderer) renderTo(out *render.Output, toolName string, input map[string]any) ToolContext {
 	args := GetToolArg(toolName, input)
-
-	// Truncate long args
-	if len(args) > 80 {
-		args = args[:77] + "..."
-	}
+	title := PageTitle(toolName, args)
 
 	// Build header: [prefix][icon] ToolName args
 	icon := r.icon
 	if icon == "" {
 		icon = style.Bullet
 	}
+	name := DisplayName(toolName)
+
+	// Truncate long args to what is left of the line; file paths lose
+	// directories from the middle so the basename stays visible
+	width := render.HeaderArgWidth(ansi.StringWidth(r.prefix + icon + " " + name + " "))
+	if IsFilePathTool(toolName) {
+		args = textutil.ShortenPath(args, width)
+	} else {
+		args = ansi.Truncate(args, width, "...")
+	}
 
 	// Render prefix, icon, and tool name
 	fmt.Fprint(out, r.prefix)
 	if r.iconInGradient {
 		// Include icon in gradient (default bullet case)
-		fmt.Fprint(out, style.ApplyThemeBoldGradient(icon+" "+toolName))
+		fmt.Fprint(out, style.ApplyThemeBoldGradient(icon+" "+name))
 	} else {
 		// Icon is pre-styled (e.g., spinner frames), print separately
-		fmt.Fprint(out, icon+" "+style.ApplyThemeBoldGradient(toolName))
+		fmt.Fprint(out, icon+" "+style.ApplyThemeBoldGradient(name))
 	}
 
 	// Style args: file paths get muted color + dotted underline (combined in single
@@ -132,11 +141,16 @@ func (r *HeaderRenderer) renderTo(out *render.Output, toolName string, input map
 			fmt.Fprint(out, " "+style.MutedText(args))
 		}
 	}
+	if title != "" {
+		fmt.Fprint(out, " "+style.MutedText("—")+" "+title)
+	}
 	fmt.Fprintln(out)
 
 	return ToolContext{
-		ToolName: toolName,
-		FilePath: GetFilePath(toolName, input),
+		ToolName:    toolName,
+		FilePath:    GetFilePath(toolName, input),
+		Pattern:     GetPattern(toolName, input),
+		LineNumbers: GetLineNumbers(toolName, input),
 	}
 }
 
@@ -179,3 +193,14 @@ func RenderResolved(resolved ResolvedTool) (string, ToolContext) {
 	}
 	return r.RenderBlockToString(resolved.Block)
 }
+
+// PageTitle returns the title of the page a WebFetch call fetched from url,
+// if -resolve-titles is on and the title has been looked up. It never waits
+// for a lookup, so spinner frames can call it.
+func PageTitle(toolName, url string) string {
+	if toolName != "WebFetch" {
+		return ""
+	}
+	title, _ := pagetitle.Default().Cached(url)
+	return title
+}
@@ -3,9 +3,13 @@ package tools
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/johnnyfreeman/viewscreen/pagetitle"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
@@ -76,6 +80,27 @@ func TestHeaderRenderer_RenderToString(t *testing.T) {
 				"test task",
 			},
 		},
+		{
+			name:     "long file path shortened in the middle",
+			opts:     nil,
+			toolName: "Read",
+			input:    map[string]any{"file_path": "/home/u/" + strings.Repeat("deep/", 20) + "parser.go"},
+			wantContains: []string{
+				"/home/u/",
+				"/…/",
+				"/parser.go",
+			},
+		},
+		{
+			name:     "MCP tool",
+			opts:     nil,
+			toolName: "mcp__github__search_issues",
+			input:    map[string]any{"query": "is:open label:bug", "per_page": 10},
+			wantContains: []string{
+				"github ▸ search_issues",
+				"is:open label:bug",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -200,6 +225,30 @@ func TestHeaderRenderer_WithOutput(t *testing.T) {
 	}
 }
 
+func TestHeaderRenderer_WebFetchPageTitle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<title>Release Notes</title>")
+	}))
+	defer srv.Close()
+	url := srv.URL + "/notes"
+	input := map[string]any{"url": url}
+
+	str, _ := NewHeaderRenderer().RenderToString("WebFetch", input)
+	if strings.Contains(str, "Release Notes") {
+		t.Errorf("expected no title without -resolve-titles, got %q", str)
+	}
+
+	pagetitle.SetDefault(pagetitle.New(pagetitle.WithClient(srv.Client())))
+	defer pagetitle.SetDefault(nil)
+	pagetitle.Default().Title(url)
+
+	str, _ = NewHeaderRenderer().RenderToString("WebFetch", input)
+	if !strings.Contains(str, url) || !strings.Contains(str, "Release Notes") {
+		t.Errorf("expected the URL and page title, got %q", str)
+	}
+}
+
 func TestHeaderRenderer_ToolContext(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -0,0 +1,137 @@
+package tools
+
+import "sort"
+
+// Lane is a running tool call and the concurrency lane it holds.
+type Lane struct {
+	ID    string
+	Name  string
+	Input string
+	// Index is the lane, counted from 0.
+	Index int
+}
+
+// Lanes allocates concurrency lanes to tool calls, so the results of calls
+// that run at the same time can be told apart when they interleave. A call
+// takes the lowest lane no other running call holds; a call made by a
+// sub-agent takes the lane of the Task that started it, so everything the
+// sub-agent does shares its color. A call is concurrent once another
+// top-level call has run alongside it.
+type Lanes struct {
+	running    map[string]Lane   // top-level calls, by tool_use ID
+	order      []string          // running top-level calls, in start order
+	root       map[string]string // every known call to its top-level call
+	concurrent map[string]bool   // top-level calls that overlapped another
+}
+
+// NewLanes creates an empty lane allocator.
+func NewLanes() *Lanes {
+	return &Lanes{
+		running:    make(map[string]Lane),
+		root:       make(map[string]string),
+		concurrent: make(map[string]bool),
+	}
+}
+
+// Start allocates a lane to the call id of tool name, made by the sub-agent
+// of the call parentID when it is not empty. Starting a call twice, as the
+// streamed content_block_start and the assistant message that follows do,
+// keeps its lane and fills in the input the stream did not have yet.
+func (l *Lanes) Start(id, name, input, parentID string) {
+	if id == "" {
+		return
+	}
+	if root, ok := l.root[id]; ok {
+		if lane, ok := l.running[root]; ok && root == id && lane.Input == "" {
+			lane.Input = input
+			l.running[id] = lane
+		}
+		return
+	}
+	if root, ok := l.root[parentID]; ok && parentID != "" {
+		l.root[id] = root
+		return
+	}
+	lane := Lane{ID: id, Name: name, Input: input, Index: l.free()}
+	if len(l.order) > 0 {
+		l.concurrent[id] = true
+		for _, other := range l.order {
+			l.concurrent[other] = true
+		}
+	}
+	l.running[id] = lane
+	l.order = append(l.order, id)
+	l.root[id] = id
+}
+
+// free returns the lowest lane no running call holds.
+func (l *Lanes) free() int {
+	held := make(map[int]bool, len(l.running))
+	for _, lane := range l.running {
+		held[lane.Index] = true
+	}
+	n := 0
+	for held[n] {
+		n++
+	}
+	return n
+}
+
+// Lane returns the lane of the call id and whether its top-level call ran
+// concurrently with another. ok is false for a call that was never started
+// or whose top-level call has finished.
+func (l *Lanes) Lane(id string) (index int, concurrent, ok bool) {
+	lane, ok := l.running[l.root[id]]
+	if !ok {
+		return 0, false, false
+	}
+	return lane.Index, l.concurrent[lane.ID], true
+}
+
+// Finish ends the call id. A top-level call frees its lane; the calls of
+// its sub-agent lose theirs with it.
+func (l *Lanes) Finish(id string) {
+	root, ok := l.root[id]
+	if !ok {
+		return
+	}
+	delete(l.root, id)
+	if root != id {
+		return
+	}
+	delete(l.running, id)
+	delete(l.concurrent, id)
+	for i, running := range l.order {
+		if running == id {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+	for call, r := range l.root {
+		if r == id {
+			delete(l.root, call)
+		}
+	}
+}
+
+// Active returns the running top-level calls in lane order when more than
+// one is running, and nil otherwise.
+func (l *Lanes) Active() []Lane {
+	if len(l.order) < 2 {
+		return nil
+	}
+	active := make([]Lane, 0, len(l.order))
+	for _, id := range l.order {
+		active = append(active, l.running[id])
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Index < active[j].Index })
+	return active
+}
+
+// Clear forgets every call, as when a session ends with calls unanswered.
+func (l *Lanes) Clear() {
+	clear(l.running)
+	clear(l.root)
+	clear(l.concurrent)
+	l.order = nil
+}
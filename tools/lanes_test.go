@@ -0,0 +1,77 @@
+package tools
+
+import "testing"
+
+func TestLanes_Allocation(t *testing.T) {
+	l := NewLanes()
+
+	l.Start("a", "Bash", "make", "")
+	if lane, concurrent, ok := l.Lane("a"); !ok || lane != 0 || concurrent {
+		t.Errorf("Lane(a) = %d, %v, %v; want 0, false, true", lane, concurrent, ok)
+	}
+	if l.Active() != nil {
+		t.Error("expected no lanes shown for a single call")
+	}
+
+	l.Start("b", "Read", "", "")
+	l.Start("c", "Grep", "foo", "")
+	for id, want := range map[string]int{"a": 0, "b": 1, "c": 2} {
+		if lane, concurrent, ok := l.Lane(id); !ok || lane != want || !concurrent {
+			t.Errorf("Lane(%s) = %d, %v, %v; want %d, true, true", id, lane, concurrent, ok, want)
+		}
+	}
+
+	l.Finish("b")
+	if _, _, ok := l.Lane("b"); ok {
+		t.Error("expected a finished call to lose its lane")
+	}
+	l.Start("d", "Glob", "*.go", "")
+	if lane, _, _ := l.Lane("d"); lane != 1 {
+		t.Errorf("expected the lowest free lane 1, got %d", lane)
+	}
+
+	active := l.Active()
+	if len(active) != 3 || active[0].ID != "a" || active[1].ID != "d" || active[2].ID != "c" {
+		t.Errorf("Active() = %+v, want a, d, c in lane order", active)
+	}
+
+	l.Clear()
+	if _, _, ok := l.Lane("a"); ok || l.Active() != nil {
+		t.Error("expected Clear to forget every call")
+	}
+}
+
+func TestLanes_InputFilledIn(t *testing.T) {
+	l := NewLanes()
+	l.Start("a", "Read", "", "")
+	l.Start("b", "Bash", "ls", "")
+	// The assistant message after the stream fills in the input
+	l.Start("a", "Read", "main.go", "")
+	if active := l.Active(); active[0].Input != "main.go" {
+		t.Errorf("expected the input to be filled in, got %q", active[0].Input)
+	}
+}
+
+func TestLanes_SubAgentSharesTaskLane(t *testing.T) {
+	l := NewLanes()
+	l.Start("task", "Task", "explore", "")
+	l.Start("other", "Bash", "ls", "")
+	l.Start("nested", "Read", "a.go", "task")
+
+	if lane, concurrent, ok := l.Lane("nested"); !ok || lane != 0 || !concurrent {
+		t.Errorf("Lane(nested) = %d, %v, %v; want the Task's lane 0", lane, concurrent, ok)
+	}
+	if len(l.Active()) != 2 {
+		t.Errorf("expected nested calls not to take a lane, got %+v", l.Active())
+	}
+
+	l.Finish("nested")
+	if _, _, ok := l.Lane("task"); !ok {
+		t.Error("expected the Task to keep its lane after a nested call")
+	}
+	l.Start("nested2", "Grep", "x", "task")
+	l.Finish("task")
+	if _, _, ok := l.Lane("nested2"); ok {
+		t.Error("expected nested calls to lose their lane with the Task")
+	}
+}
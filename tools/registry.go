@@ -3,8 +3,10 @@ package tools
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/redact"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
 
@@ -36,6 +38,14 @@ type ToolDefinition struct {
 	CountField string
 	Singular   string
 	Plural     string
+
+	// PatternField is the input field containing a search regex (for match
+	// highlighting). Empty for tools that don't search.
+	PatternField string
+
+	// headerCandidates are tried in order when HeaderField is empty. Used
+	// for MCP tools whose server has no registered definition.
+	headerCandidates []string
 }
 
 // RenderHeader returns the argument string to display in the tool header.
@@ -62,6 +72,11 @@ func (d ToolDefinition) RenderHeader(input map[string]interface{}) string {
 			}
 		}
 	}
+	for _, field := range d.headerCandidates {
+		if val, ok := input[field].(string); ok && val != "" {
+			return val
+		}
+	}
 	return ""
 }
 
@@ -82,6 +97,33 @@ func (d ToolDefinition) GetFilePath(input map[string]interface{}) string {
 	return ""
 }
 
+// GetPattern extracts the search regex from the input. Case-insensitive
+// searches ("-i") are returned with a (?i) flag so the pattern matches the
+// same text the tool did.
+func (d ToolDefinition) GetPattern(input map[string]interface{}) string {
+	if d.PatternField == "" {
+		return ""
+	}
+	pattern, _ := input[d.PatternField].(string)
+	if pattern == "" {
+		return ""
+	}
+	if ignoreCase, _ := input["-i"].(bool); ignoreCase {
+		return "(?i)" + pattern
+	}
+	return pattern
+}
+
+// GetLineNumbers reports whether a search asked for line numbers ("-n") in
+// its content output, which then prefixes each line with its number.
+func (d ToolDefinition) GetLineNumbers(input map[string]interface{}) bool {
+	if d.PatternField == "" {
+		return false
+	}
+	lineNumbers, _ := input["-n"].(bool)
+	return lineNumbers
+}
+
 // IsFilePathTool returns true if this tool operates on a file path.
 func (d ToolDefinition) IsFilePathTool() bool {
 	return d.FilePathField != ""
@@ -96,10 +138,80 @@ func RegisterDefinition(def ToolDefinition) {
 	definitions[def.Name] = def
 }
 
+// mcpPrefix starts the name of every tool provided by an MCP server, as in
+// mcp__<server>__<tool>.
+const mcpPrefix = "mcp__"
+
+// mcpServers holds definitions that apply to every tool of an MCP server,
+// keyed by server name.
+var mcpServers = map[string]ToolDefinition{}
+
+// mcpHeaderFields are the input fields tried, in order, for the header of an
+// MCP tool whose server has no registered definition.
+var mcpHeaderFields = []string{"query", "url", "path", "file_path", "command", "name", "title", "id"}
+
+// RegisterMCPServer adds a definition shared by all tools of an MCP server.
+// Its Name is ignored; a definition registered for a tool's full name takes
+// precedence.
+func RegisterMCPServer(server string, def ToolDefinition) {
+	mcpServers[server] = def
+}
+
+// ParseMCPName splits an mcp__<server>__<tool> name into its server and tool.
+// ok is false for names that are not MCP tools.
+func ParseMCPName(name string) (server, tool string, ok bool) {
+	rest, found := strings.CutPrefix(name, mcpPrefix)
+	if !found {
+		return "", "", false
+	}
+	server, tool, ok = strings.Cut(rest, "__")
+	if !ok || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
+
+// DisplayName returns the name to show for a tool: "server ▸ tool" for MCP
+// tools, the name unchanged otherwise.
+func DisplayName(name string) string {
+	if server, tool, ok := ParseMCPName(name); ok {
+		return server + " ▸ " + tool
+	}
+	return name
+}
+
+// MatchesName reports whether selector, a name given on the command line,
+// selects the tool name. A selector matches a tool's name case-insensitively,
+// and an MCP server name (or mcp__<server>) selects every tool of that server.
+func MatchesName(selector, name string) bool {
+	if strings.EqualFold(selector, name) {
+		return true
+	}
+	server, _, ok := ParseMCPName(name)
+	if !ok {
+		return false
+	}
+	selector = strings.TrimPrefix(selector, mcpPrefix)
+	return strings.EqualFold(selector, server)
+}
+
 // GetDefinition returns the definition for a tool, or an empty definition if not found.
+// Every MCP tool has a definition: its server's, if registered, or a generic
+// one that shows the first common argument (query, url, path...).
 func GetDefinition(name string) (ToolDefinition, bool) {
-	def, ok := definitions[name]
-	return def, ok
+	if def, ok := definitions[name]; ok {
+		return def, true
+	}
+	server, _, ok := ParseMCPName(name)
+	if !ok {
+		return ToolDefinition{}, false
+	}
+	def, ok := mcpServers[server]
+	if !ok {
+		def = ToolDefinition{headerCandidates: mcpHeaderFields}
+	}
+	def.Name = name
+	return def, true
 }
 
 // GetToolArg returns the display argument for a tool using the registry.
@@ -112,6 +224,9 @@ func GetToolArg(toolName string, input map[string]interface{}) string {
 // Falls back to JSON preview for unknown tools in verbose mode.
 func GetToolArgWithConfig(toolName string, input map[string]interface{}, cfg config.Provider) string {
 	if def, ok := GetDefinition(toolName); ok {
+		if toolName == "Bash" {
+			return redact.EnvAssignments(def.RenderHeader(input), config.Get().EnvSecretKeys())
+		}
 		return def.RenderHeader(input)
 	}
 
@@ -144,15 +259,33 @@ func GetToolArgFromBlock(block types.ContentBlock) string {
 // GetFilePath extracts the file path from tool input if present.
 // Used for syntax highlighting context.
 func GetFilePath(toolName string, input map[string]interface{}) string {
-	if def, ok := definitions[toolName]; ok {
+	if def, ok := GetDefinition(toolName); ok {
 		return def.GetFilePath(input)
 	}
 	return ""
 }
 
+// GetPattern extracts the search regex from tool input if present.
+// Used for match highlighting context.
+func GetPattern(toolName string, input map[string]interface{}) string {
+	if def, ok := GetDefinition(toolName); ok {
+		return def.GetPattern(input)
+	}
+	return ""
+}
+
+// GetLineNumbers reports whether a search tool's input asked for line
+// numbers in its output.
+func GetLineNumbers(toolName string, input map[string]interface{}) bool {
+	if def, ok := GetDefinition(toolName); ok {
+		return def.GetLineNumbers(input)
+	}
+	return false
+}
+
 // IsFilePathTool returns true if the tool's argument is a file path
 func IsFilePathTool(toolName string) bool {
-	if def, ok := definitions[toolName]; ok {
+	if def, ok := GetDefinition(toolName); ok {
 		return def.IsFilePathTool()
 	}
 	return false
@@ -171,7 +304,7 @@ var builtinTools = []ToolDefinition{
 	// Simple field extractors - display a single string field
 	{Name: "Bash", HeaderField: "command"},
 	{Name: "Glob", HeaderField: "pattern"},
-	{Name: "Grep", HeaderField: "pattern"},
+	{Name: "Grep", HeaderField: "pattern", PatternField: "pattern"},
 	{Name: "Task", HeaderField: "description"},
 	{Name: "WebFetch", HeaderField: "url"},
 	{Name: "WebSearch", HeaderField: "query"},
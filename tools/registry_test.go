@@ -30,6 +30,8 @@ func TestGetDefinition(t *testing.T) {
 		{name: "EnterPlanMode definition exists", toolName: "EnterPlanMode", wantOK: true},
 		{name: "ExitPlanMode definition exists", toolName: "ExitPlanMode", wantOK: true},
 		{name: "ToolSearch definition exists", toolName: "ToolSearch", wantOK: true},
+		{name: "MCP tool definition exists", toolName: "mcp__github__search_issues", wantOK: true},
+		{name: "malformed MCP name not found", toolName: "mcp__github", wantOK: false},
 		{name: "unknown tool not found", toolName: "UnknownTool", wantOK: false},
 		{name: "empty string not found", toolName: "", wantOK: false},
 	}
@@ -44,6 +46,108 @@ func TestGetDefinition(t *testing.T) {
 	}
 }
 
+func TestParseMCPName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantServer string
+		wantTool   string
+		wantOK     bool
+	}{
+		{"mcp__github__search_issues", "github", "search_issues", true},
+		{"mcp__claude_ai_Linear__list_issues", "claude_ai_Linear", "list_issues", true},
+		{"mcp__db__query__v2", "db", "query__v2", true},
+		{"mcp__github", "", "", false},
+		{"mcp____tool", "", "", false},
+		{"Bash", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, tool, ok := ParseMCPName(tt.name)
+			if server != tt.wantServer || tool != tt.wantTool || ok != tt.wantOK {
+				t.Errorf("ParseMCPName(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.name, server, tool, ok, tt.wantServer, tt.wantTool, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if got := DisplayName("mcp__github__search_issues"); got != "github ▸ search_issues" {
+		t.Errorf("DisplayName(MCP tool) = %q", got)
+	}
+	if got := DisplayName("Bash"); got != "Bash" {
+		t.Errorf("DisplayName(Bash) = %q", got)
+	}
+}
+
+func TestMatchesName(t *testing.T) {
+	tests := []struct {
+		selector, name string
+		want           bool
+	}{
+		{"Read", "Read", true},
+		{"read", "Read", true},
+		{"Read", "ReadFile", false},
+		{"github", "mcp__github__search_issues", true},
+		{"mcp__github", "mcp__github__search_issues", true},
+		{"mcp__github__search_issues", "mcp__github__search_issues", true},
+		{"git", "mcp__github__search_issues", false},
+		{"github", "github", true},
+	}
+	for _, tt := range tests {
+		if got := MatchesName(tt.selector, tt.name); got != tt.want {
+			t.Errorf("MatchesName(%q, %q) = %v, want %v", tt.selector, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetToolArg_MCP(t *testing.T) {
+	orig := mcpServers
+	t.Cleanup(func() { mcpServers = orig })
+	mcpServers = map[string]ToolDefinition{}
+	RegisterMCPServer("docs", ToolDefinition{HeaderField: "topic", FilePathField: "file"})
+
+	tests := []struct {
+		name     string
+		toolName string
+		input    map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "unregistered server shows first common field",
+			toolName: "mcp__github__get_file",
+			input:    map[string]interface{}{"owner": "acme", "path": "README.md", "name": "readme"},
+			expected: "README.md",
+		},
+		{
+			name:     "unregistered server without common fields",
+			toolName: "mcp__github__list_repos",
+			input:    map[string]interface{}{"per_page": 10.0},
+			expected: "",
+		},
+		{
+			name:     "registered server uses its header field",
+			toolName: "mcp__docs__lookup",
+			input:    map[string]interface{}{"topic": "routing", "query": "ignored"},
+			expected: "routing",
+		},
+	}
+
+	cfg := testutil.MockConfigProvider{VerboseLevelVal: 1}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetToolArgWithConfig(tt.toolName, tt.input, cfg); got != tt.expected {
+				t.Errorf("GetToolArgWithConfig(%q) = %q, want %q", tt.toolName, got, tt.expected)
+			}
+		})
+	}
+
+	if got := GetFilePath("mcp__docs__open", map[string]interface{}{"file": "a.go"}); got != "a.go" {
+		t.Errorf("GetFilePath(MCP tool) = %q, want %q", got, "a.go")
+	}
+}
+
 func TestToolRenderers(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -277,6 +381,12 @@ func TestGetToolArg(t *testing.T) {
 			input:    map[string]interface{}{"command": "echo hello"},
 			expected: "echo hello",
 		},
+		{
+			name:     "Bash masks sensitive assignments",
+			toolName: "Bash",
+			input:    map[string]interface{}{"command": "GITHUB_TOKEN=ghp_abc GOOS=linux make release"},
+			expected: "GITHUB_TOKEN=[REDACTED] GOOS=linux make release",
+		},
 		{
 			name:     "unknown tool non-verbose returns empty",
 			toolName: "CustomTool",
@@ -421,6 +531,69 @@ func TestGetFilePath(t *testing.T) {
 	}
 }
 
+func TestGetPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolName string
+		input    map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "Grep pattern",
+			toolName: "Grep",
+			input:    map[string]interface{}{"pattern": "func \\w+", "output_mode": "content"},
+			expected: "func \\w+",
+		},
+		{
+			name:     "Grep case-insensitive",
+			toolName: "Grep",
+			input:    map[string]interface{}{"pattern": "todo", "-i": true},
+			expected: "(?i)todo",
+		},
+		{
+			name:     "Glob has no search regex",
+			toolName: "Glob",
+			input:    map[string]interface{}{"pattern": "**/*.go"},
+			expected: "",
+		},
+		{
+			name:     "unknown tool",
+			toolName: "Nope",
+			input:    map[string]interface{}{"pattern": "x"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetPattern(tt.toolName, tt.input); got != tt.expected {
+				t.Errorf("GetPattern(%q) = %q, want %q", tt.toolName, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetLineNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolName string
+		input    map[string]interface{}
+		expected bool
+	}{
+		{"Grep with -n", "Grep", map[string]interface{}{"pattern": "x", "-n": true}, true},
+		{"Grep without -n", "Grep", map[string]interface{}{"pattern": "x"}, false},
+		{"Glob", "Glob", map[string]interface{}{"pattern": "*.go", "-n": true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetLineNumbers(tt.toolName, tt.input); got != tt.expected {
+				t.Errorf("GetLineNumbers(%q) = %v, want %v", tt.toolName, got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsFilePathTool(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DescribeInputChange summarizes how a tool call's input differs from an
+// earlier call's, field by field, e.g. "changed pattern, added -i flag".
+// Fields named like command-line flags, as Grep's -i and -n are, are called
+// flags. It returns "same input" when nothing changed.
+func DescribeInputChange(prev, next json.RawMessage) string {
+	var a, b map[string]any
+	if json.Unmarshal(prev, &a) != nil || json.Unmarshal(next, &b) != nil {
+		if bytes.Equal(bytes.TrimSpace(prev), bytes.TrimSpace(next)) {
+			return "same input"
+		}
+		return "changed input"
+	}
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changed, added, removed []string
+	for _, k := range keys {
+		old, inA := a[k]
+		cur, inB := b[k]
+		switch {
+		case !inA:
+			added = append(added, "added "+fieldName(k))
+		case !inB:
+			removed = append(removed, "removed "+fieldName(k))
+		case !reflect.DeepEqual(old, cur):
+			changed = append(changed, "changed "+fieldName(k))
+		}
+	}
+	parts := slices.Concat(changed, added, removed)
+	if len(parts) == 0 {
+		return "same input"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fieldName names an input field in DescribeInputChange.
+func fieldName(key string) string {
+	if strings.HasPrefix(key, "-") {
+		return key + " flag"
+	}
+	return key
+}
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDescribeInputChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		prev, next string
+		want       string
+	}{
+		{name: "changed and added flag", prev: `{"pattern":"foo","path":"src"}`, next: `{"pattern":"fo+","path":"src","-i":true}`, want: "changed pattern, added -i flag"},
+		{name: "removed", prev: `{"command":"ls","timeout":5}`, next: `{"command":"ls"}`, want: "removed timeout"},
+		{name: "nested change", prev: `{"edits":[{"old_string":"a"}]}`, next: `{"edits":[{"old_string":"b"}]}`, want: "changed edits"},
+		{name: "same", prev: `{"command":"ls"}`, next: `{"command": "ls"}`, want: "same input"},
+		{name: "not objects", prev: `[1]`, next: `[2]`, want: "changed input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeInputChange(json.RawMessage(tt.prev), json.RawMessage(tt.next)); got != tt.want {
+				t.Errorf("DescribeInputChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MinShownElapsed is the shortest tool duration worth displaying. Shorter
+// times are noise, and every tool appears instant when a finished transcript
+// is rendered from a file.
+const MinShownElapsed = 100 * time.Millisecond
+
+// ToolTotal is the accumulated time spent in one tool.
+type ToolTotal struct {
+	Name  string
+	Count int
+	Total time.Duration
+}
+
+type toolStart struct {
+	name string
+	at   time.Time
+}
+
+// ToolTimer measures how long each tool call takes, from the first time its
+// tool_use block is seen to its tool_result, and totals the time per tool.
+type ToolTimer struct {
+	now     func() time.Time
+	started map[string]toolStart
+	totals  map[string]*ToolTotal
+}
+
+// NewToolTimer creates a timer reading the given clock (default time.Now).
+func NewToolTimer(clock ...func() time.Time) *ToolTimer {
+	now := time.Now
+	if len(clock) > 0 && clock[0] != nil {
+		now = clock[0]
+	}
+	return &ToolTimer{
+		now:     now,
+		started: make(map[string]toolStart),
+		totals:  make(map[string]*ToolTotal),
+	}
+}
+
+// Start records the start of the tool call id. A call already started keeps
+// its original start, so the streamed content_block_start and the complete
+// assistant message that follows it time the same call once.
+func (t *ToolTimer) Start(id, name string) {
+	if id == "" {
+		return
+	}
+	if _, ok := t.started[id]; ok {
+		return
+	}
+	t.started[id] = toolStart{name: name, at: t.now()}
+}
+
+// Finish ends the tool call id and adds its duration to the tool's total.
+// ok is false if the call was never started.
+func (t *ToolTimer) Finish(id string) (elapsed time.Duration, ok bool) {
+	start, ok := t.started[id]
+	if !ok {
+		return 0, false
+	}
+	delete(t.started, id)
+	elapsed = t.now().Sub(start.at)
+
+	total, exists := t.totals[start.name]
+	if !exists {
+		total = &ToolTotal{Name: start.name}
+		t.totals[start.name] = total
+	}
+	total.Count++
+	total.Total += elapsed
+	return elapsed, true
+}
+
+// Totals returns the per-tool totals, longest first.
+func (t *ToolTimer) Totals() []ToolTotal {
+	out := make([]ToolTotal, 0, len(t.totals))
+	for _, total := range t.totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total != out[j].Total {
+			return out[i].Total > out[j].Total
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// FormatElapsed formats a tool duration as "2.4s", or "1m05s" from a minute.
+func FormatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock returns a clock and a function advancing it.
+func fakeClock() (func() time.Time, func(time.Duration)) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time { return now }, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestToolTimer_StartFinish(t *testing.T) {
+	clock, advance := fakeClock()
+	timer := NewToolTimer(clock)
+
+	timer.Start("t1", "Bash")
+	advance(time.Second)
+	// A second start for the same call (assistant after stream) is ignored
+	timer.Start("t1", "Bash")
+	advance(1400 * time.Millisecond)
+
+	elapsed, ok := timer.Finish("t1")
+	if !ok || elapsed != 2400*time.Millisecond {
+		t.Errorf("Finish() = %v, %v; want 2.4s, true", elapsed, ok)
+	}
+	if _, ok := timer.Finish("t1"); ok {
+		t.Error("expected a finished call not to finish again")
+	}
+	if _, ok := timer.Finish("unknown"); ok {
+		t.Error("expected an unknown call not to finish")
+	}
+}
+
+func TestToolTimer_Totals(t *testing.T) {
+	clock, advance := fakeClock()
+	timer := NewToolTimer(clock)
+
+	timer.Start("r1", "Read")
+	timer.Start("b1", "Bash")
+	advance(time.Second)
+	timer.Finish("r1")
+	advance(2 * time.Second)
+	timer.Finish("b1")
+	timer.Start("r2", "Read")
+	advance(500 * time.Millisecond)
+	timer.Finish("r2")
+	timer.Start("pending", "Grep")
+
+	got := timer.Totals()
+	want := []ToolTotal{
+		{Name: "Bash", Count: 1, Total: 3 * time.Second},
+		{Name: "Read", Count: 2, Total: 1500 * time.Millisecond},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Totals() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Totals()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{2400 * time.Millisecond, "2.4s"},
+		{150 * time.Millisecond, "0.1s"},
+		{65 * time.Second, "1m05s"},
+		{10*time.Minute + 29600*time.Millisecond, "10m30s"},
+	}
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
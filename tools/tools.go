@@ -4,4 +4,10 @@ package tools
 type ToolContext struct {
 	ToolName string
 	FilePath string
+	// Pattern is the search regex of a search tool (e.g. Grep), used to
+	// highlight matches in its result.
+	Pattern string
+	// LineNumbers is set when a search tool's output lines start with
+	// their line numbers (Grep -n).
+	LineNumbers bool
 }
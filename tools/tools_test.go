@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/types"
@@ -39,13 +40,13 @@ func TestHeaderRenderer_Truncation(t *testing.T) {
 			wantContains: "...",
 		},
 		{
-			name:     "long file path truncated",
+			name:     "long file path shortened in the middle",
 			toolName: "Read",
 			input: map[string]any{
 				"file_path": "/home/user/some/very/deeply/nested/directory/structure/with/many/levels/that/exceeds/eighty/characters/file.go",
 			},
-			wantTrunc:    true,
-			wantContains: "...",
+			wantTrunc:    false,
+			wantContains: "/…/",
 		},
 		{
 			name:     "exactly 80 chars not truncated",
@@ -66,6 +67,10 @@ func TestHeaderRenderer_Truncation(t *testing.T) {
 		},
 	}
 
+	// "● Bash " and the gutter leave 80 columns for the command
+	render.SetHeaderWidth(89)
+	defer render.SetHeaderWidth(0)
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			output, _ := NewHeaderRenderer().RenderToString(tt.toolName, tt.input)
@@ -82,6 +87,26 @@ func TestHeaderRenderer_Truncation(t *testing.T) {
 	}
 }
 
+func TestHeaderRenderer_TruncationFollowsWidth(t *testing.T) {
+	defer render.SetHeaderWidth(0)
+	command := map[string]any{"command": strings.Repeat("a", 150)}
+
+	render.SetHeaderWidth(200)
+	if output, _ := NewHeaderRenderer().RenderToString("Bash", command); strings.Contains(output, "...") {
+		t.Errorf("expected the whole command on a wide terminal, got %q", output)
+	}
+
+	render.SetHeaderWidth(60)
+	output, _ := NewHeaderRenderer().RenderToString("Bash", command)
+	if !strings.Contains(output, "...") || ansi.StringWidth(strings.TrimSuffix(output, "\n")) > 58 {
+		t.Errorf("expected the command cut to fit 60 columns, got %q", output)
+	}
+	nested, _ := NewHeaderRenderer(WithNested()).RenderToString("Bash", command)
+	if ansi.StringWidth(strings.TrimSuffix(nested, "\n")) > 58 {
+		t.Errorf("expected the nested header to fit 60 columns with its prefix, got %q", nested)
+	}
+}
+
 func TestHeaderRenderer_RenderBlockToStringWithNesting(t *testing.T) {
 	block := types.ContentBlock{
 		Type:  "tool_use",
@@ -0,0 +1,153 @@
+// Package transcript accumulates a rendered session for programs that embed
+// viewscreen, such as a desktop shell, so they can present and query it
+// without re-parsing the input.
+//
+// A Transcript is a sink: pass it in viewscreen.Options.Sinks, or register
+// it with parser.Parser.AddSink, and read it from any goroutine while the
+// session renders.
+//
+//	t := transcript.New()
+//	go viewscreen.RenderSession(r, io.Discard, viewscreen.Options{Sinks: []viewscreen.Sink{t}})
+//	...
+//	for _, b := range t.Since(seen) { ... }
+package transcript
+
+import (
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Block is one rendered timeline entry with its metadata.
+type Block struct {
+	// Index is the block's position in the transcript.
+	Index int
+	// Type is the timeline entry kind, e.g. "assistant", "user", "result"
+	// or "annotation".
+	Type string
+	// Tool is the name of the tool whose result the block shows, and
+	// ToolArg its header argument. Both are empty for other blocks.
+	Tool    string
+	ToolArg string
+	// UUID identifies the event the block was rendered from, and ParentID
+	// the tool call of the sub-agent that produced it, if any.
+	UUID     string
+	ParentID string
+	// Styled is the rendered terminal text and Plain the same text with
+	// ANSI styling stripped.
+	Styled string
+	Plain  string
+}
+
+// Transcript is an append-only list of rendered blocks. It is safe for
+// concurrent use.
+type Transcript struct {
+	mu     sync.RWMutex
+	blocks []Block
+}
+
+// New returns an empty Transcript.
+func New() *Transcript {
+	return &Transcript{}
+}
+
+// Write appends the timeline entries of a rendered event. It implements
+// sink.Sink.
+func (t *Transcript) Write(ev sink.Event) error {
+	t.Append(ev.Entries...)
+	return nil
+}
+
+// Close implements sink.Sink. The transcript stays readable after Close.
+func (t *Transcript) Close() error { return nil }
+
+// Append adds timeline entries to the end of the transcript. Entries with no
+// text are skipped.
+func (t *Transcript) Append(entries ...timeline.Entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, entry := range entries {
+		styled := entry.Text()
+		if styled == "" {
+			continue
+		}
+		t.blocks = append(t.blocks, Block{
+			Index:    len(t.blocks),
+			Type:     entry.Kind,
+			Tool:     entry.Title,
+			ToolArg:  entry.Arg,
+			UUID:     entry.ID,
+			ParentID: entry.ParentID,
+			Styled:   styled,
+			Plain:    ansi.Strip(styled),
+		})
+	}
+}
+
+// Len returns the number of blocks.
+func (t *Transcript) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.blocks)
+}
+
+// Blocks returns a copy of every block.
+func (t *Transcript) Blocks() []Block {
+	return t.Slice(0, -1)
+}
+
+// Slice returns a copy of the blocks in [from, to). A negative to means the
+// end of the transcript; out-of-range bounds are clamped.
+func (t *Transcript) Slice(from, to int) []Block {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if to < 0 || to > len(t.blocks) {
+		to = len(t.blocks)
+	}
+	if from < 0 {
+		from = 0
+	}
+	if from >= to {
+		return nil
+	}
+	return append([]Block(nil), t.blocks[from:to]...)
+}
+
+// Since returns the blocks appended after the first n, for callers that poll
+// for new output.
+func (t *Transcript) Since(n int) []Block {
+	return t.Slice(n, -1)
+}
+
+// Filter returns the blocks for which match returns true.
+func (t *Transcript) Filter(match func(Block) bool) []Block {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var out []Block
+	for _, b := range t.blocks {
+		if match(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// ByType returns the blocks of the given timeline kind.
+func (t *Transcript) ByType(kind string) []Block {
+	return t.Filter(func(b Block) bool { return b.Type == kind })
+}
+
+// ByTool returns the blocks showing results of the named tool.
+func (t *Transcript) ByTool(name string) []Block {
+	return t.Filter(func(b Block) bool { return b.Tool == name })
+}
+
+// ByUUID returns the blocks rendered from the event with the given UUID.
+func (t *Transcript) ByUUID(uuid string) []Block {
+	if uuid == "" {
+		return nil
+	}
+	return t.Filter(func(b Block) bool { return b.UUID == uuid })
+}
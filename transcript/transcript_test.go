@@ -0,0 +1,141 @@
+package transcript
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/parser"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func sample() *Transcript {
+	t := New()
+	t.Append(
+		timeline.Entry{ID: "a1", Kind: "assistant", Body: "\x1b[1mHello\x1b[0m\n"},
+		timeline.Entry{Kind: "stream"},
+		timeline.Entry{ID: "u1", Kind: "user", Title: "Bash", Arg: "ls", Body: "● Bash ls\n"},
+		timeline.Entry{ID: "u2", Kind: "user", Title: "Read", Arg: "main.go", Body: "● Read main.go\n"},
+		timeline.Entry{ID: "r1", Kind: "result", Lines: []string{"done"}},
+	)
+	return t
+}
+
+func TestTranscript_Append(t *testing.T) {
+	tr := sample()
+	if tr.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4 (empty entries skipped)", tr.Len())
+	}
+	first := tr.Blocks()[0]
+	if first.Index != 0 || first.Type != "assistant" || first.UUID != "a1" {
+		t.Errorf("first block = %+v", first)
+	}
+	if first.Plain != "Hello\n" {
+		t.Errorf("Plain = %q, want ANSI stripped", first.Plain)
+	}
+	if !strings.Contains(first.Styled, "\x1b[1m") {
+		t.Errorf("Styled = %q, want ANSI kept", first.Styled)
+	}
+	if last := tr.Blocks()[3]; last.Index != 3 || last.Plain != "done\n" {
+		t.Errorf("last block = %+v", last)
+	}
+}
+
+func TestTranscript_Slice(t *testing.T) {
+	tr := sample()
+	tests := []struct {
+		name     string
+		from, to int
+		want     []int
+	}{
+		{"middle", 1, 3, []int{1, 2}},
+		{"to end", 2, -1, []int{2, 3}},
+		{"clamped", -5, 99, []int{0, 1, 2, 3}},
+		{"empty", 3, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tr.Slice(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Slice(%d, %d) returned %d blocks, want %d", tt.from, tt.to, len(got), len(tt.want))
+			}
+			for i, b := range got {
+				if b.Index != tt.want[i] {
+					t.Errorf("block %d Index = %d, want %d", i, b.Index, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if got := tr.Since(3); len(got) != 1 || got[0].UUID != "r1" {
+		t.Errorf("Since(3) = %+v", got)
+	}
+}
+
+func TestTranscript_Slice_ReturnsCopy(t *testing.T) {
+	tr := sample()
+	tr.Blocks()[0].Plain = "changed"
+	if tr.Blocks()[0].Plain != "Hello\n" {
+		t.Error("modifying a returned block changed the transcript")
+	}
+}
+
+func TestTranscript_Queries(t *testing.T) {
+	tr := sample()
+	if got := tr.ByType("user"); len(got) != 2 {
+		t.Errorf("ByType(user) returned %d blocks, want 2", len(got))
+	}
+	if got := tr.ByTool("Read"); len(got) != 1 || got[0].ToolArg != "main.go" {
+		t.Errorf("ByTool(Read) = %+v", got)
+	}
+	if got := tr.ByUUID("u1"); len(got) != 1 || got[0].Tool != "Bash" {
+		t.Errorf("ByUUID(u1) = %+v", got)
+	}
+	if got := tr.ByUUID(""); got != nil {
+		t.Errorf("ByUUID(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestTranscript_ConcurrentReads(t *testing.T) {
+	tr := New()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = tr.Write(sink.Event{Entries: []timeline.Entry{{Kind: "assistant", Body: "x\n"}}})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = tr.Since(tr.Len() / 2)
+		}
+	}()
+	wg.Wait()
+	if tr.Len() != 100 {
+		t.Errorf("Len() = %d, want 100", tr.Len())
+	}
+}
+
+func TestTranscript_ParserSink(t *testing.T) {
+	input := `{"type":"assistant","uuid":"msg-1","message":{"content":[{"type":"text","text":"Hi there"}]}}` + "\n"
+	p := parser.NewParserWithOptions(
+		parser.WithInput(strings.NewReader(input)),
+		parser.WithOutput(&strings.Builder{}),
+	)
+	tr := New()
+	p.AddSink(tr)
+	if err := p.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	blocks := tr.ByUUID("msg-1")
+	if len(blocks) != 1 {
+		t.Fatalf("ByUUID(msg-1) returned %d blocks, want 1", len(blocks))
+	}
+	if blocks[0].Type != "assistant" || !strings.Contains(blocks[0].Plain, "Hi there") {
+		t.Errorf("block = %+v", blocks[0])
+	}
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"github.com/johnnyfreeman/viewscreen/budget"
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// RenderBudgetBar renders the banner shown above the transcript once the
+// session has spent most of its -budget, or "" while spending is under it.
+func RenderBudgetBar(b budget.Budget, spent float64, width int) string {
+	switch b.Level(spent) {
+	case budget.Warning:
+		return fitBarLine(style.WarningText("⚠ "+b.Message(spent)), width)
+	case budget.Exceeded:
+		return fitBarLine(style.ErrorBoldText("✗ "+b.Message(spent)), width)
+	}
+	return ""
+}
+
+// checkBudget reacts to an event that changed spending from the level
+// before: the banner takes a row from the viewport, and exceeding the budget
+// with -budget-stop stops the agent and the rendering of later events.
+func (m *Model) checkBudget(before budget.Level) {
+	level := m.budget.Level(m.state.CumulativeCost)
+	if level == before {
+		return
+	}
+	if level == budget.Exceeded && m.budget.Stop {
+		m.budgetStopped = true
+		m.stopAgentProcessIfRunning()
+	}
+	m.updateViewportDimensions()
+}
+
+// budgetErr returns the error a run stopped by -budget-stop exits with.
+func (m Model) budgetErr() error {
+	if !m.budgetStopped {
+		return nil
+	}
+	return &budget.ExceededError{Spent: m.state.CumulativeCost, Limit: m.budget.Limit}
+}
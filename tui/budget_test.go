@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/budget"
+)
+
+func budgetResult(cost string) string {
+	return `{"type":"result","subtype":"success","num_turns":1,"result":"done","total_cost_usd":` + cost + `}`
+}
+
+func TestRenderBudgetBar(t *testing.T) {
+	b := budget.Budget{Limit: 1}
+	if bar := RenderBudgetBar(b, 0.5, 60); bar != "" {
+		t.Errorf("expected no bar under the budget, got %q", bar)
+	}
+	bar := RenderBudgetBar(b, 0.9, 60)
+	if !strings.Contains(ansi.Strip(bar), "⚠ Budget: $0.90 of $1.00 spent (90%)") || ansi.StringWidth(bar) != 60 {
+		t.Errorf("warning bar = %q", ansi.Strip(bar))
+	}
+	if bar := RenderBudgetBar(b, 1.2, 60); !strings.Contains(ansi.Strip(bar), "✗ Budget exceeded") {
+		t.Errorf("exceeded bar = %q", ansi.Strip(bar))
+	}
+}
+
+func TestBudgetBannerTakesAViewportRow(t *testing.T) {
+	m := newTestModel()
+	m.budget = budget.Budget{Limit: 1}
+	m.updateViewportDimensions()
+	height := m.viewport.Height()
+
+	m.applyLine(budgetResult("0.9"), true)
+	if got := m.viewport.Height(); got != height-1 {
+		t.Errorf("viewport height = %d, want %d with the banner shown", got, height-1)
+	}
+	if !strings.Contains(ansi.Strip(m.renderLayout()), "Budget: $0.90 of $1.00") {
+		t.Error("expected the budget banner in the layout")
+	}
+	if m.budgetErr() != nil {
+		t.Errorf("budgetErr = %v, want nil without -budget-stop", m.budgetErr())
+	}
+}
+
+func TestBudgetStop(t *testing.T) {
+	m := newTestModel()
+	proc := &fakeAgentProcess{}
+	m.agentProcess = proc
+	m.budget = budget.Budget{Limit: 1, Stop: true}
+
+	m.applyLine(budgetResult("1.5"), true)
+	m.applyLine(`{"type":"assistant","message":{"content":[{"type":"text","text":"after the limit"}]}}`, true)
+
+	if proc.killCount != 1 {
+		t.Errorf("agent killed %d times, want 1", proc.killCount)
+	}
+	if strings.Contains(m.content.String(), "after the limit") {
+		t.Error("expected events after the limit to be dropped")
+	}
+	var exceeded *budget.ExceededError
+	if err := m.budgetErr(); !errors.As(err, &exceeded) || exceeded.Spent != 1.5 {
+		t.Errorf("budgetErr = %v, want the exceeded budget", err)
+	}
+	if !strings.Contains(ansi.Strip(m.renderLayout()), "rendering stopped") {
+		t.Error("expected the stop notice in the layout")
+	}
+
+	m.resetSession("")
+	if m.budgetErr() != nil {
+		t.Error("expected a new session to start under budget")
+	}
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// CommandLine holds the state of the ":" command line, which runs commands
+// such as ":w <path>" to save the session.
+type CommandLine struct {
+	Active bool   // whether the command line is taking input
+	Input  string // the command typed so far, without the ":"
+	Notice string // outcome of the last command, shown until the next key
+}
+
+// Visible reports whether the command line bar is shown.
+func (c CommandLine) Visible() bool {
+	return c.Active || c.Notice != ""
+}
+
+// Enter activates the command line with input already typed.
+func (c *CommandLine) Enter(input string) {
+	c.Active = true
+	c.Input = input
+	c.Notice = ""
+}
+
+// Exit deactivates the command line, discarding its input.
+func (c *CommandLine) Exit() {
+	c.Active = false
+	c.Input = ""
+}
+
+// TypeText appends terminal text input to the command.
+func (c *CommandLine) TypeText(text string) {
+	c.Input += normalizeSearchQueryText(text)
+}
+
+// Backspace removes the last character from the command.
+func (c *CommandLine) Backspace() {
+	if c.Input != "" {
+		_, size := utf8.DecodeLastRuneInString(c.Input)
+		c.Input = c.Input[:len(c.Input)-size]
+	}
+}
+
+// RenderCommandLine renders the command line at the bottom of the viewport,
+// or the outcome of the last command.
+func RenderCommandLine(c CommandLine, width int) string {
+	if !c.Visible() || width <= 0 {
+		return ""
+	}
+	if !c.Active {
+		return fitBarLine(style.MutedText(c.Notice), width)
+	}
+	prefix := style.AccentText(":")
+	cursor := style.MutedText("█")
+	inputWidth := max(width-ansi.StringWidth(prefix)-ansi.StringWidth(cursor), 0)
+	return fitBarLine(prefix+rightmostCells(c.Input, inputWidth)+cursor, width)
+}
+
+// runCommand runs the command typed on the command line.
+func (m *Model) runCommand(input string) tea.Cmd {
+	name, arg, _ := strings.Cut(strings.TrimSpace(input), " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "":
+		return nil
+	case "w", "write":
+		if arg == "" {
+			m.cmdline.Notice = "Usage: :w <path> (.jsonl raw events, .txt plain text, .md or .html document)"
+			return nil
+		}
+		return SaveSession(arg, m.rawLines, m.timeline)
+	default:
+		m.cmdline.Notice = fmt.Sprintf("Unknown command: %s", name)
+		return nil
+	}
+}
+
+// SaveSession writes the session so far to path, in the format its
+// extension selects (see sink.Save). A SessionSavedMsg reports the outcome.
+func SaveSession(path string, raw []string, entries []timeline.Entry) tea.Cmd {
+	raw = append([]string(nil), raw...)
+	entries = append([]timeline.Entry(nil), entries...)
+	return func() tea.Msg {
+		return SessionSavedMsg{Path: path, Events: len(raw), Err: sink.Save(path, raw, entries)}
+	}
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func typeKeys(m Model, text string) Model {
+	for _, r := range text {
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: string(r)})
+	}
+	return m
+}
+
+func TestHandleKeyMsg_SaveSession(t *testing.T) {
+	m := newTestModel()
+	m.applyLine(`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello from the agent"}]}}`, true)
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	m = typeKeys(m, ":w "+path)
+	if !m.cmdline.Active || m.cmdline.Input != "w "+path {
+		t.Fatalf("expected the command typed on the command line, got %+v", m.cmdline)
+	}
+
+	m, cmd := m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.cmdline.Active || cmd == nil {
+		t.Fatalf("expected enter to run :w, got %+v", m.cmdline)
+	}
+	msg, ok := cmd().(SessionSavedMsg)
+	if !ok || msg.Err != nil || msg.Events != 1 {
+		t.Fatalf("expected one event saved, got %+v", msg)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Hello from the agent") {
+		t.Errorf("saved session = %q, want the raw event", data)
+	}
+
+	m = m.handleSessionSaved(msg)
+	if !strings.Contains(m.cmdline.Notice, "Saved 1 events to "+path) {
+		t.Errorf("Notice = %q, want the saved path", m.cmdline.Notice)
+	}
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "j"})
+	if m.cmdline.Visible() {
+		t.Error("expected the next key to clear the notice")
+	}
+}
+
+func TestHandleKeyMsg_WOpensWriteCommand(t *testing.T) {
+	m := newTestModel()
+	m.updateViewportDimensions()
+	height := m.viewport.Height()
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "w"})
+	if !m.cmdline.Active || m.cmdline.Input != "w " {
+		t.Fatalf("expected w to open the command line with \"w \", got %+v", m.cmdline)
+	}
+	if m.viewport.Height() != height-1 {
+		t.Errorf("viewport height = %d, want %d with the command line shown", m.viewport.Height(), height-1)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.cmdline.Visible() {
+		t.Error("expected esc to close the command line")
+	}
+}
+
+func TestRunCommand_Errors(t *testing.T) {
+	m := newTestModel()
+	m = typeKeys(m, ":w")
+	m, cmd := m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd != nil || !strings.HasPrefix(m.cmdline.Notice, "Usage: :w <path>") {
+		t.Errorf("expected usage for :w without a path, got %q", m.cmdline.Notice)
+	}
+
+	m = typeKeys(m, ":frobnicate")
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.cmdline.Notice != "Unknown command: frobnicate" {
+		t.Errorf("Notice = %q, want an unknown command", m.cmdline.Notice)
+	}
+
+	m = m.handleSessionSaved(SessionSavedMsg{Path: "x.jsonl", Err: errors.New("disk full")})
+	if m.cmdline.Notice != "Save failed: disk full" {
+		t.Errorf("Notice = %q, want the save error", m.cmdline.Notice)
+	}
+}
+
+func TestRenderCommandLine(t *testing.T) {
+	if got := RenderCommandLine(CommandLine{}, 40); got != "" {
+		t.Errorf("expected nothing for an inactive command line, got %q", got)
+	}
+	got := RenderCommandLine(CommandLine{Active: true, Input: "w out.md"}, 40)
+	if !strings.Contains(got, ":") || !strings.Contains(got, "w out.md") {
+		t.Errorf("RenderCommandLine() = %q, want the typed command", got)
+	}
+}
@@ -1,18 +1,21 @@
 package tui
 
 import (
-	"bufio"
 	"io"
+	"os"
+	"os/exec"
+	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/jsonl"
 	"golang.org/x/term"
 )
 
 // ReadStdinLine returns a command that reads the next line from stdin
-func ReadStdinLine(scanner *bufio.Scanner) tea.Cmd {
+func ReadStdinLine(scanner *jsonl.Reader) tea.Cmd {
 	return func() tea.Msg {
 		if scanner.Scan() {
 			return RawLineMsg{Line: scanner.Text()}
@@ -35,6 +38,21 @@ func AutoExitTick() tea.Cmd {
 	}
 }
 
+// WaitPageTitles returns a command that sends a PageTitleMsg once the page
+// title lookups of an event's result have finished.
+func WaitPageTitles(result events.ProcessResult) tea.Cmd {
+	if result.Retitle == nil || len(result.Batch.Entries) == 0 {
+		return nil
+	}
+	untitled := result.Batch.Entries[0].Body
+	return func() tea.Msg {
+		for _, done := range result.TitleLookups {
+			<-done
+		}
+		return PageTitleMsg{Untitled: untitled, Retitle: result.Retitle}
+	}
+}
+
 // WaitAgentProcess reaps a spawned agent subprocess without blocking Update.
 func WaitAgentProcess(proc managedAgentProcess) tea.Cmd {
 	if proc == nil {
@@ -45,6 +63,57 @@ func WaitAgentProcess(proc managedAgentProcess) tea.Cmd {
 	}
 }
 
+// Programs used when $PAGER or $VISUAL/$EDITOR are unset.
+const (
+	defaultPager  = "less -R"
+	defaultEditor = "vi"
+)
+
+// pagerCommand returns the command line of the user's pager.
+func pagerCommand(getenv func(string) string) []string {
+	return programCommand(defaultPager, getenv("PAGER"))
+}
+
+// editorCommand returns the command line of the user's editor, preferring
+// $VISUAL over $EDITOR.
+func editorCommand(getenv func(string) string) []string {
+	return programCommand(defaultEditor, getenv("VISUAL"), getenv("EDITOR"))
+}
+
+// programCommand splits the first non-empty setting into a command line,
+// falling back to def.
+func programCommand(def string, settings ...string) []string {
+	for _, s := range settings {
+		if fields := strings.Fields(s); len(fields) > 0 {
+			return fields
+		}
+	}
+	return strings.Fields(def)
+}
+
+// OpenInProgram writes content to a temporary file and hands the terminal to
+// the program in argv to view it, e.g. a pager or editor. The TUI is
+// suspended until the program exits, then an ExternalProgramExitedMsg is
+// sent.
+func OpenInProgram(argv []string, content string) tea.Cmd {
+	f, err := os.CreateTemp("", "viewscreen-*.txt")
+	if err != nil {
+		return func() tea.Msg { return ExternalProgramExitedMsg{Err: err} }
+	}
+	path := f.Name()
+	_, err = f.WriteString(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return func() tea.Msg { return ExternalProgramExitedMsg{Path: path, Err: err} }
+	}
+	cmd := exec.Command(argv[0], append(argv[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return ExternalProgramExitedMsg{Path: path, Err: err}
+	})
+}
+
 // resetTerminalModes clears terminal modes that can leak in from a previous TUI
 // before Bubble Tea starts reading input.
 func resetTerminalModes(w io.Writer) {
@@ -28,3 +28,25 @@ func TestTerminalModeResetSequence(t *testing.T) {
 		}
 	}
 }
+
+func TestExternalProgramCommands(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	if got := strings.Join(pagerCommand(env(nil)), " "); got != defaultPager {
+		t.Errorf("pager without $PAGER = %q, want %q", got, defaultPager)
+	}
+	if got := strings.Join(pagerCommand(env(map[string]string{"PAGER": "most -s"})), " "); got != "most -s" {
+		t.Errorf("pager = %q, want $PAGER split into arguments", got)
+	}
+	if got := strings.Join(editorCommand(env(map[string]string{"EDITOR": "nano", "VISUAL": " "})), " "); got != "nano" {
+		t.Errorf("editor = %q, want $EDITOR when $VISUAL is blank", got)
+	}
+	if got := strings.Join(editorCommand(env(map[string]string{"EDITOR": "nano", "VISUAL": "code --wait"})), " "); got != "code --wait" {
+		t.Errorf("editor = %q, want $VISUAL first", got)
+	}
+	if got := strings.Join(editorCommand(env(nil)), " "); got != defaultEditor {
+		t.Errorf("editor without settings = %q, want %q", got, defaultEditor)
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import renderpkg "github.com/johnnyfreeman/viewscreen/render"
+
+// errorStarts returns the first content line of each error in the timeline:
+// failed tool calls, assistant errors, permission denials and failed runs.
+func (m Model) errorStarts() []int {
+	var starts []int
+	for i, entry := range m.timeline {
+		if i < len(m.entryStarts) && renderpkg.IsErrorEntry(entry) {
+			starts = append(starts, m.entryStarts[i])
+		}
+	}
+	return starts
+}
+
+// jumpToError scrolls the viewport to the next error below its top line
+// (dir > 0) or the previous one above it (dir < 0), wrapping around at
+// either end.
+func (m *Model) jumpToError(dir int) {
+	starts := m.errorStarts()
+	if len(starts) == 0 {
+		return
+	}
+	top := m.viewport.YOffset()
+	target := -1
+	if dir > 0 {
+		target = starts[0]
+		for _, start := range starts {
+			if start > top {
+				target = start
+				break
+			}
+		}
+	} else {
+		target = starts[len(starts)-1]
+		for i := len(starts) - 1; i >= 0; i-- {
+			if starts[i] < top {
+				target = starts[i]
+				break
+			}
+		}
+	}
+	m.followMode = false
+	m.viewport.SetYOffset(target)
+}
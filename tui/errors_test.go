@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func newErrorTestModel() Model {
+	m := newTestModel()
+	m.viewport.SetHeight(2)
+	m.timeline = []timeline.Entry{
+		{Kind: "assistant", Body: "one\ntwo\n"},
+		{Kind: "user", Title: "Bash", Status: timeline.StatusError, Body: "● Bash false\n  ⎿  exit 1\n"},
+		{Kind: "assistant", Body: "three\nfour\n"},
+		{Kind: "error", Body: "Stream error\n"},
+		{Kind: "assistant", Body: "five\nsix\nseven\n"},
+	}
+	m.rebuildRenderedContent()
+	m.viewport.SetHeight(2)
+	m.setViewportContent(m.content.String())
+	m.viewport.GotoTop()
+	return m
+}
+
+func TestHandleKeyMsg_JumpToError(t *testing.T) {
+	m := newErrorTestModel()
+	errs := m.errorStarts()
+	if len(errs) != 2 {
+		t.Fatalf("errorStarts() = %v, want 2 errors", errs)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+	if got := m.viewport.YOffset(); got != errs[0] {
+		t.Errorf("after e, YOffset = %d, want %d", got, errs[0])
+	}
+	if m.followMode {
+		t.Error("expected jumping to an error to leave follow mode")
+	}
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+	if got := m.viewport.YOffset(); got != errs[1] {
+		t.Errorf("after second e, YOffset = %d, want %d", got, errs[1])
+	}
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+	if got := m.viewport.YOffset(); got != errs[0] {
+		t.Errorf("expected e to wrap to the first error, YOffset = %d, want %d", got, errs[0])
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "E"})
+	if got := m.viewport.YOffset(); got != errs[1] {
+		t.Errorf("expected E to wrap to the last error, YOffset = %d, want %d", got, errs[1])
+	}
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "E"})
+	if got := m.viewport.YOffset(); got != errs[0] {
+		t.Errorf("after E, YOffset = %d, want %d", got, errs[0])
+	}
+}
+
+func TestRebuildRenderedContent_ErrorGutter(t *testing.T) {
+	m := newTestModel()
+	m.timeline = []timeline.Entry{{Kind: "assistant", Body: "fine\n"}}
+	m.rebuildRenderedContent()
+	if m.gutter || m.content.String() != "fine\n" {
+		t.Errorf("expected no gutter without errors, got %q", m.content.String())
+	}
+
+	m.timeline = append(m.timeline, timeline.Entry{Kind: "user", Title: "Bash", Status: timeline.StatusError, Body: "● Bash false\n"})
+	m.rebuildRenderedContent()
+	lines := strings.Split(m.content.String(), "\n")
+	if !m.gutter || !strings.HasPrefix(lines[0], "  fine") || !strings.Contains(lines[1], "✗") {
+		t.Errorf("expected an error gutter marking only the failure, got %q", m.content.String())
+	}
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"math"
+
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// Folds holds the expanded/collapsed state of foldable timeline entries:
+// tool results with more output than their rendering shows, and blocks with
+// details only shown verbosely, such as thinking. Entries start collapsed,
+// showing output as truncated for the chosen verbosity (the summary line by
+// default), and expand to their full output or verbose rendering.
+type Folds struct {
+	expanded map[int]bool   // by timeline index
+	verbose  map[int]string // verbose renderings of expanded entries, by timeline index
+	pendingZ bool           // "z" was pressed; "a" completes "za"
+}
+
+// NewFolds creates a Folds with every entry collapsed.
+func NewFolds() Folds {
+	return Folds{expanded: make(map[int]bool), verbose: make(map[int]string)}
+}
+
+// IsExpanded reports whether the entry at timeline index i is expanded.
+func (f Folds) IsExpanded(i int) bool {
+	return f.expanded[i]
+}
+
+// Toggle expands the entry at timeline index i, or collapses it if it is
+// already expanded.
+func (f *Folds) Toggle(i int) {
+	if f.expanded[i] {
+		delete(f.expanded, i)
+		delete(f.verbose, i)
+		return
+	}
+	f.expanded[i] = true
+}
+
+// ExpandAll expands every foldable entry, or collapses them all when they
+// are already expanded.
+func (f *Folds) ExpandAll(entries []timeline.Entry) {
+	allExpanded := true
+	for i, entry := range entries {
+		if entry.Foldable() && !f.expanded[i] {
+			allExpanded = false
+			f.expanded[i] = true
+		}
+	}
+	if allExpanded {
+		clear(f.expanded)
+		clear(f.verbose)
+	}
+}
+
+// Reset collapses every entry, as when the timeline is discarded.
+func (f *Folds) Reset() {
+	f.expanded = make(map[int]bool)
+	f.verbose = make(map[int]string)
+	f.pendingZ = false
+}
+
+// Rewrap drops the verbose renderings, made for the previous width, so
+// expanded entries are rendered again from their events.
+func (f *Folds) Rewrap() {
+	clear(f.verbose)
+}
+
+// entryText returns the rendering of the entry at timeline index i: its full
+// output or verbose rendering when its fold is expanded. Verbose renderings
+// are made from the entry's event the first time they are shown.
+func (m *Model) entryText(i int, entry timeline.Entry) string {
+	if !entry.Foldable() || !m.folds.IsExpanded(i) {
+		return m.timelineRenderer.RenderEntry(entry)
+	}
+	if entry.Full != "" {
+		return entry.Full
+	}
+	text, ok := m.folds.verbose[i]
+	if !ok {
+		text = entry.Verbose()
+		m.folds.verbose[i] = text
+	}
+	return text
+}
+
+// focusedFold returns the timeline index of the first foldable entry shown
+// in the viewport, or -1 when none is.
+func (m Model) focusedFold() int {
+	top := m.viewport.YOffset()
+	bottom := top + m.viewport.Height()
+	for i, entry := range m.timeline {
+		if i >= len(m.entryStarts) || m.entryStarts[i] >= bottom {
+			break
+		}
+		end := math.MaxInt // the last entry runs to the end
+		if i+1 < len(m.entryStarts) {
+			end = m.entryStarts[i+1]
+		}
+		if end > top && entry.Foldable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleFocusedFold expands or collapses the first foldable entry in view,
+// raising just that block to the highest verbosity.
+// When the entry starts above the viewport, it is scrolled to its start so
+// the block stays anchored to its header.
+func (m *Model) toggleFocusedFold() {
+	i := m.focusedFold()
+	if i < 0 {
+		return
+	}
+	m.folds.Toggle(i)
+	m.followMode = false
+	m.refreshFolds()
+	if m.entryStarts[i] < m.viewport.YOffset() {
+		m.viewport.SetYOffset(m.entryStarts[i])
+	}
+}
+
+// expandAllFolds expands (or collapses) every foldable entry, keeping the
+// entry at the top of the viewport in place.
+func (m *Model) expandAllFolds() {
+	top := m.topEntry()
+	m.folds.ExpandAll(m.timeline)
+	m.refreshFolds()
+	if top >= 0 && !m.followMode {
+		m.viewport.SetYOffset(m.entryStarts[top])
+	}
+}
+
+// refreshFolds re-renders the transcript after fold state changes.
+func (m *Model) refreshFolds() {
+	m.rebuildRenderedContent()
+	m.updateSearchMatches()
+	m.updateViewportDimensions()
+}
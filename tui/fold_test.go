@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func newFoldTestModel() Model {
+	m := newTestModel()
+	m.timeline = []timeline.Entry{
+		{Kind: "assistant", Body: "intro\n"},
+		{Kind: "user", Title: "Bash", Body: "● Bash\n  ⎿  3 lines\n", Full: "● Bash\n  ⎿  one\n     two\n     three\n"},
+		{Kind: "user", Title: "Read", Body: "● Read\n  ⎿  2 lines\n", Full: "● Read\n  ⎿  alpha\n     beta\n"},
+	}
+	m.rebuildRenderedContent()
+	m.setViewportContent(m.content.String())
+	return m
+}
+
+func TestFolds_ExpandAll(t *testing.T) {
+	entries := []timeline.Entry{
+		{Body: "plain\n"},
+		{Body: "a\n", Full: "a\nb\n"},
+		{Body: "same\n", Full: "same\n"},
+	}
+	f := NewFolds()
+	f.ExpandAll(entries)
+	if !f.IsExpanded(1) || f.IsExpanded(0) || f.IsExpanded(2) {
+		t.Errorf("expected only the foldable entry expanded, got %v", f.expanded)
+	}
+	f.ExpandAll(entries)
+	if f.IsExpanded(1) {
+		t.Error("expected a second ExpandAll to collapse everything")
+	}
+}
+
+func TestHandleKeyMsg_EnterTogglesFocusedFold(t *testing.T) {
+	m := newFoldTestModel()
+	if strings.Contains(m.content.String(), "three") {
+		t.Fatal("expected tool results to start collapsed")
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	content := m.content.String()
+	if !strings.Contains(content, "three") {
+		t.Errorf("expected enter to expand the first tool result, got %q", content)
+	}
+	if strings.Contains(content, "beta") {
+		t.Errorf("expected other tool results to stay collapsed, got %q", content)
+	}
+	if m.followMode {
+		t.Error("expected toggling a fold to leave follow mode")
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if strings.Contains(m.content.String(), "three") {
+		t.Error("expected a second enter to collapse the tool result")
+	}
+}
+
+func TestHandleKeyMsg_ZAChord(t *testing.T) {
+	m := newFoldTestModel()
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "a"})
+	if strings.Contains(m.content.String(), "three") {
+		t.Error("expected a without z to do nothing")
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "z"})
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "a"})
+	if !strings.Contains(m.content.String(), "three") {
+		t.Error("expected za to expand the focused tool result")
+	}
+}
+
+func TestHandleKeyMsg_ShiftZExpandsAll(t *testing.T) {
+	m := newFoldTestModel()
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "Z"})
+	content := m.content.String()
+	if !strings.Contains(content, "three") || !strings.Contains(content, "beta") {
+		t.Errorf("expected Z to expand every tool result, got %q", content)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "Z"})
+	if strings.Contains(m.content.String(), "beta") {
+		t.Error("expected a second Z to collapse every tool result")
+	}
+}
+
+func TestFocusedFold_SkipsEntriesAboveViewport(t *testing.T) {
+	m := newFoldTestModel()
+	m.viewport.SetHeight(2)
+	m.viewport.SetYOffset(m.entryStarts[2])
+	if got := m.focusedFold(); got != 2 {
+		t.Errorf("focusedFold() = %d, want 2", got)
+	}
+}
+
+func TestHandleKeyMsg_VTogglesFocusedBlockVerbosity(t *testing.T) {
+	m := newTestModel()
+	renders := 0
+	m.timeline = []timeline.Entry{
+		{Kind: "assistant", Body: "✻ Thinking… (3 tokens)\n", Verbose: func() string {
+			renders++
+			return "✻ Thinking… (3 tokens)\n  ⎿  weigh the options\n"
+		}},
+		{Kind: "assistant", Body: "✻ Thinking… (2 tokens)\n", Verbose: func() string { return "  ⎿  later thought\n" }},
+	}
+	m.updateViewportDimensions()
+	m.rebuildRenderedContent()
+	m.setViewportContent(m.content.String())
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "v"})
+	content := m.content.String()
+	if !strings.Contains(content, "weigh the options") {
+		t.Errorf("expected v to render the focused block verbosely, got %q", content)
+	}
+	if strings.Contains(content, "later thought") {
+		t.Errorf("expected the other blocks to keep their verbosity, got %q", content)
+	}
+
+	m.rebuildRenderedContent()
+	if renders != 1 {
+		t.Errorf("expected the verbose rendering to be made once, made %d times", renders)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "v"})
+	if strings.Contains(m.content.String(), "weigh the options") {
+		t.Error("expected a second v to restore the block")
+	}
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"io"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+)
+
+// Harness drives a Model headlessly, without a terminal or a running
+// tea.Program, so UI behaviour such as scrolling, search and modals can be
+// tested end to end: load a session fixture, press keys or replay a key log
+// recorded with -record-keys, then inspect the screen.
+//
+// Messages are delivered synchronously through Model.Update. The commands
+// Update returns (stdin reads, spinner ticks, quitting, external programs)
+// are not run, so the harness observes only what a message does to the
+// model itself.
+type Harness struct {
+	model Model
+}
+
+// NewHarness returns a Harness whose model has a width x height terminal
+// and has read every line of the session fixture, up to end of input.
+func NewHarness(fixture io.Reader, width, height int, opts ...ModelOption) (*Harness, error) {
+	opts = append([]ModelOption{WithInitialSize(width, height)}, opts...)
+	opts = append(opts, WithInputReader(strings.NewReader("")))
+	h := &Harness{model: NewModel(opts...)}
+	h.Send(tea.WindowSizeMsg{Width: width, Height: height})
+
+	scanner := jsonl.NewReader(fixture, nil)
+	for scanner.Scan() {
+		h.Send(RawLineMsg{Line: scanner.Text()})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	h.Send(StdinClosedMsg{})
+	return h, nil
+}
+
+// Send delivers msg to the model.
+func (h *Harness) Send(msg tea.Msg) {
+	next, _ := h.model.Update(msg)
+	h.model = next.(Model)
+}
+
+// Press delivers a key press for each keystroke name (see ParseKey).
+func (h *Harness) Press(keys ...string) error {
+	for _, name := range keys {
+		key, err := ParseKey(name)
+		if err != nil {
+			return err
+		}
+		h.Send(key)
+	}
+	return nil
+}
+
+// Type delivers a key press for each character of text, as when typing a
+// search query.
+func (h *Harness) Type(text string) {
+	for _, r := range text {
+		h.Send(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+// Replay delivers the key presses and resizes of a key log in order. The
+// recorded timing is not reproduced.
+func (h *Harness) Replay(keylog io.Reader) error {
+	evs, err := ReadKeyLog(keylog)
+	if err != nil {
+		return err
+	}
+	for _, ev := range evs {
+		msg, err := ev.Msg()
+		if err != nil {
+			return err
+		}
+		h.Send(msg)
+	}
+	return nil
+}
+
+// Screen returns the rendered screen as plain text, without styling.
+func (h *Harness) Screen() string {
+	return ansi.Strip(h.model.renderLayout())
+}
+
+// Model returns the model in its current state.
+func (h *Harness) Model() Model {
+	return h.model
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/sse"
+)
+
+func newFixtureHarness(t *testing.T) *Harness {
+	t.Helper()
+	f, err := os.Open("../testdata/fresh_claude_20260522_103848.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h, err := NewHarness(f, 100, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func yOffset(h *Harness) int {
+	m := h.Model()
+	return m.viewport.YOffset()
+}
+
+func currentMatch(h *Harness) int {
+	m := h.Model()
+	return m.search.CurrentMatchIndex()
+}
+
+func TestHarness_Scrolling(t *testing.T) {
+	h := newFixtureHarness(t)
+	if !h.Model().viewport.AtBottom() {
+		t.Fatal("expected the session to follow the output to the bottom")
+	}
+	bottom := h.Screen()
+
+	if err := h.Press("g"); err != nil {
+		t.Fatal(err)
+	}
+	if got := yOffset(h); got != 0 {
+		t.Errorf("YOffset after g = %d, want 0", got)
+	}
+	if h.Screen() == bottom {
+		t.Error("expected the screen to change after scrolling to the top")
+	}
+
+	if err := h.Press("j", "j", "pgdown"); err != nil {
+		t.Fatal(err)
+	}
+	if got := yOffset(h); got <= 2 {
+		t.Errorf("YOffset after j j pgdown = %d, want past line 2", got)
+	}
+	if h.Model().followMode {
+		t.Error("expected follow mode off while scrolled up")
+	}
+
+	if err := h.Press("G"); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Model().viewport.AtBottom() || !h.Model().followMode {
+		t.Error("expected G to return to the bottom and follow the output")
+	}
+}
+
+func TestHarness_Search(t *testing.T) {
+	h := newFixtureHarness(t)
+	if err := h.Press("/"); err != nil {
+		t.Fatal(err)
+	}
+	h.Type("Bash")
+	if err := h.Press("enter"); err != nil {
+		t.Fatal(err)
+	}
+
+	search := h.Model().search
+	if search.Active || search.Query != "Bash" {
+		t.Fatalf("search = %+v, want the query Bash submitted", search)
+	}
+	if search.MatchCount() < 2 {
+		t.Fatalf("MatchCount() = %d, want several matches", search.MatchCount())
+	}
+	first := search.CurrentMatchIndex()
+	if err := h.Press("n"); err != nil {
+		t.Fatal(err)
+	}
+	if got := currentMatch(h); got == first {
+		t.Errorf("n left the current match at %d", got)
+	}
+	if err := h.Press("N"); err != nil {
+		t.Fatal(err)
+	}
+	if got := currentMatch(h); got != first {
+		t.Errorf("N moved to match %d, want %d", got, first)
+	}
+}
+
+func TestHarness_HelpModal(t *testing.T) {
+	h := newFixtureHarness(t)
+	if err := h.Press("?"); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Model().showHelpModal {
+		t.Fatal("expected ? to open the help modal")
+	}
+	offset := yOffset(h)
+	if err := h.Press("g"); err != nil {
+		t.Fatal(err)
+	}
+	if got := yOffset(h); got != offset {
+		t.Errorf("g scrolled to %d behind the help modal, want %d", got, offset)
+	}
+	if err := h.Press("esc"); err != nil {
+		t.Fatal(err)
+	}
+	if h.Model().showHelpModal {
+		t.Error("expected esc to close the help modal")
+	}
+}
+
+func TestHarness_Replay(t *testing.T) {
+	h := newFixtureHarness(t)
+	keylog := strings.Join([]string{
+		`{"at_ms":0,"width":80,"height":20}`,
+		`{"at_ms":120,"key":"g"}`,
+		`{"at_ms":300,"key":"j","code":106,"text":"j"}`,
+		`{"at_ms":410,"key":"?"}`,
+	}, "\n")
+	if err := h.Replay(strings.NewReader(keylog)); err != nil {
+		t.Fatal(err)
+	}
+	m := h.Model()
+	if m.width != 80 || m.height != 20 {
+		t.Errorf("size = %dx%d, want 80x20", m.width, m.height)
+	}
+	if got := m.viewport.YOffset(); got != 1 {
+		t.Errorf("YOffset = %d, want 1", got)
+	}
+	if !m.showHelpModal {
+		t.Error("expected the help modal open")
+	}
+
+	if err := h.Replay(strings.NewReader(`{"at_ms":0,"key":"nope"}`)); err == nil {
+		t.Error("expected an unknown key to fail the replay")
+	}
+}
+
+func TestHarness_AnthropicSSE(t *testing.T) {
+	f, err := os.Open("../testdata/anthropic_api.sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	h, err := NewHarness(sse.Convert(f), 100, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen := h.Screen()
+	if n := strings.Count(screen, "Let me check the weather."); n != 1 {
+		t.Errorf("expected the streamed text once, found %d times:\n%s", n, screen)
+	}
+	if !strings.Contains(screen, "get_weather") {
+		t.Errorf("expected the tool call on screen:\n%s", screen)
+	}
+}
@@ -0,0 +1,201 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/jsonl"
+)
+
+// KeyEvent is one line of a key log: a key press, or a terminal resize,
+// and when it happened relative to the start of the session.
+type KeyEvent struct {
+	AtMS int64 `json:"at_ms"`
+	// Key is the keystroke as Bubble Tea names it (e.g. "j", "ctrl+f",
+	// "enter"). Code, Mod and Text, when recorded, reproduce the key
+	// exactly; a hand-written log may give Key alone (see ParseKey).
+	Key  string     `json:"key,omitempty"`
+	Code rune       `json:"code,omitempty"`
+	Mod  tea.KeyMod `json:"mod,omitempty"`
+	Text string     `json:"text,omitempty"`
+	// Width and Height are set for a resize instead of Key.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+}
+
+// Msg returns the message the event replays.
+func (e KeyEvent) Msg() (tea.Msg, error) {
+	switch {
+	case e.Width > 0 && e.Height > 0:
+		return tea.WindowSizeMsg{Width: e.Width, Height: e.Height}, nil
+	case e.Code != 0:
+		return tea.KeyPressMsg{Code: e.Code, Mod: e.Mod, Text: e.Text}, nil
+	case e.Key != "":
+		return ParseKey(e.Key)
+	}
+	return nil, fmt.Errorf("key log event at %dms has no key or size", e.AtMS)
+}
+
+// KeyRecorder writes the key presses and resizes a TUI session receives to
+// a key log, one JSON KeyEvent per line, so the session's navigation can be
+// replayed by a Harness.
+type KeyRecorder struct {
+	mu     sync.Mutex
+	w      io.WriteCloser
+	enc    *json.Encoder
+	start  time.Time
+	now    func() time.Time
+	err    error
+	closed bool
+}
+
+// CreateKeyLog creates a key log at path, timed from now. An empty path
+// records nothing and returns a nil recorder.
+func CreateKeyLog(path string) (*KeyRecorder, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRecorder(f, time.Now), nil
+}
+
+// NewKeyRecorder returns a KeyRecorder writing to w, timed with now.
+func NewKeyRecorder(w io.WriteCloser, now func() time.Time) *KeyRecorder {
+	return &KeyRecorder{w: w, enc: json.NewEncoder(w), start: now(), now: now}
+}
+
+// Filter records msg if it is a key press or resize and passes it on
+// unchanged. It has the signature of a tea.WithFilter filter.
+func (r *KeyRecorder) Filter(_ tea.Model, msg tea.Msg) tea.Msg {
+	var ev KeyEvent
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		key := msg.Key()
+		ev = KeyEvent{Key: msg.String(), Code: key.Code, Mod: key.Mod, Text: key.Text}
+	case tea.WindowSizeMsg:
+		ev = KeyEvent{Width: msg.Width, Height: msg.Height}
+	default:
+		return msg
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ev.AtMS = r.now().Sub(r.start).Milliseconds()
+	if r.err == nil && !r.closed {
+		r.err = r.enc.Encode(ev)
+	}
+	return msg
+}
+
+// ProgramOptions returns the options that install r on a tea.Program; a
+// nil recorder installs nothing.
+func (r *KeyRecorder) ProgramOptions() []tea.ProgramOption {
+	if r == nil {
+		return nil
+	}
+	return []tea.ProgramOption{tea.WithFilter(r.Filter)}
+}
+
+// Close closes the key log and returns the first write error, if any.
+// Closing it again returns the same result.
+func (r *KeyRecorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return r.err
+	}
+	r.closed = true
+	if err := r.w.Close(); r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// ReadKeyLog reads the events of a key log. Blank lines are skipped.
+func ReadKeyLog(r io.Reader) ([]KeyEvent, error) {
+	var evs []KeyEvent
+	scanner := jsonl.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var ev KeyEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			return nil, fmt.Errorf("key log line %d: %w", n, err)
+		}
+		evs = append(evs, ev)
+	}
+	return evs, scanner.Err()
+}
+
+// namedKeys maps the key names ParseKey accepts to key codes.
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEscape,
+	"tab":       tea.KeyTab,
+	"backspace": tea.KeyBackspace,
+	"delete":    tea.KeyDelete,
+	"space":     tea.KeySpace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+}
+
+// keyMods maps modifier prefixes to modifiers.
+var keyMods = map[string]tea.KeyMod{
+	"ctrl":  tea.ModCtrl,
+	"alt":   tea.ModAlt,
+	"shift": tea.ModShift,
+}
+
+// ParseKey returns the key press a keystroke name describes: a single
+// character such as "j" or "?", a named key such as "enter" or "pgdown",
+// either one prefixed with modifiers such as "ctrl+f" or "shift+tab".
+func ParseKey(name string) (tea.KeyPressMsg, error) {
+	var mod tea.KeyMod
+	rest := name
+	for {
+		prefix, after, ok := strings.Cut(rest, "+")
+		if !ok || after == "" {
+			break
+		}
+		m, known := keyMods[prefix]
+		if !known {
+			break
+		}
+		mod |= m
+		rest = after
+	}
+	if code, ok := namedKeys[rest]; ok {
+		key := tea.KeyPressMsg{Code: code, Mod: mod}
+		if code == tea.KeySpace && mod == 0 {
+			key.Text = " "
+		}
+		return key, nil
+	}
+	if runes := []rune(rest); len(runes) == 1 {
+		key := tea.KeyPressMsg{Code: runes[0], Mod: mod}
+		if mod == 0 {
+			key.Text = rest
+		}
+		return key, nil
+	}
+	return tea.KeyPressMsg{}, fmt.Errorf("unknown key %q", name)
+}
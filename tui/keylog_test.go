@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestKeyRecorder_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(100, 0)
+	r := NewKeyRecorder(nopWriteCloser{&buf}, func() time.Time { return now })
+
+	msgs := []tea.Msg{
+		tea.KeyPressMsg{Code: 'j', Text: "j"},
+		tea.WindowSizeMsg{Width: 90, Height: 30},
+		tea.KeyPressMsg{Code: 'f', Mod: tea.ModCtrl},
+		tea.KeyPressMsg{Code: tea.KeyPgDown},
+	}
+	for i, msg := range msgs {
+		now = now.Add(250 * time.Millisecond)
+		if got := r.Filter(nil, msg); got != msg {
+			t.Errorf("Filter(%v) = %v, want it passed through", msg, got)
+		}
+		if i == 0 {
+			r.Filter(nil, StdinClosedMsg{}) // not recorded
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	evs, err := ReadKeyLog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != len(msgs) {
+		t.Fatalf("read %d events, want %d:\n%+v", len(evs), len(msgs), evs)
+	}
+	if evs[0].AtMS != 250 || evs[3].AtMS != 1000 {
+		t.Errorf("AtMS = %d, %d; want 250, 1000", evs[0].AtMS, evs[3].AtMS)
+	}
+	if evs[2].Key != "ctrl+f" {
+		t.Errorf("Key = %q, want ctrl+f", evs[2].Key)
+	}
+	for i, ev := range evs {
+		msg, err := ev.Msg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg != msgs[i] {
+			t.Errorf("event %d replays as %#v, want %#v", i, msg, msgs[i])
+		}
+	}
+}
+
+func TestCreateKeyLog_Empty(t *testing.T) {
+	r, err := CreateKeyLog("")
+	if err != nil || r != nil {
+		t.Fatalf("CreateKeyLog(\"\") = %v, %v; want nil, nil", r, err)
+	}
+	if r.ProgramOptions() != nil || r.Close() != nil {
+		t.Error("expected a nil recorder to install and close nothing")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []string{"j", "?", "G", "enter", "esc", "pgdown", "ctrl+f", "ctrl+c", "shift+tab", "alt+enter", "space"}
+	for _, name := range tests {
+		key, err := ParseKey(name)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", name, err)
+			continue
+		}
+		if got := key.String(); got != name {
+			t.Errorf("ParseKey(%q).String() = %q", name, got)
+		}
+	}
+	if key, _ := ParseKey("+"); key.Text != "+" {
+		t.Errorf("ParseKey(+) = %#v, want the plus key", key)
+	}
+	for _, name := range []string{"", "nope", "ctrl+"} {
+		if _, err := ParseKey(name); err == nil {
+			t.Errorf("ParseKey(%q) should fail", name)
+		}
+	}
+	if _, err := ReadKeyLog(strings.NewReader("{bad\n")); err == nil {
+		t.Error("expected a malformed key log to fail")
+	}
+}
@@ -1,5 +1,12 @@
 package tui
 
+import (
+	"crypto/sha256"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
 // RawLineMsg is sent when a line is read from stdin
 type RawLineMsg struct {
 	Line string
@@ -22,3 +29,48 @@ type AgentExitedMsg struct {
 type RerunMsg struct {
 	Prompt string
 }
+
+// ExternalProgramExitedMsg is sent when a pager or editor opened on the
+// transcript exits and the TUI takes back the terminal.
+type ExternalProgramExitedMsg struct {
+	Path string // temporary transcript file, removed on exit
+	Err  error
+}
+
+// ConfigChangedMsg is sent when the content of the -config file has changed
+// and it has been read again, into Live or Err, or when watching it failed.
+type ConfigChangedMsg struct {
+	Sum  [sha256.Size]byte // hash of the file's content as read
+	Live config.Live
+	Err  error
+}
+
+// ToastExpiredMsg is sent when a toast has been shown for toastDuration.
+// Shown identifies the toast.
+type ToastExpiredMsg struct {
+	Shown int
+}
+
+// PageTitleMsg is sent when the page titles a tool result's WebFetch
+// headers were rendered without have been looked up. Retitle re-renders the
+// entry, which was shown as Untitled.
+type PageTitleMsg struct {
+	Untitled string
+	Retitle  func() timeline.Entry
+}
+
+// SessionSavedMsg is sent when the session has been written to a file with
+// the :w command.
+type SessionSavedMsg struct {
+	Path   string
+	Events int // input events written
+	Err    error
+}
+
+// ClipboardCopiedMsg is sent when a block copied from selection mode has
+// been saved to a temporary file, alongside the OSC 52 clipboard write.
+type ClipboardCopiedMsg struct {
+	Path  string // temporary file holding the copy
+	Lines int
+	Err   error
+}
@@ -1,9 +1,10 @@
 package tui
 
 import (
-	"bufio"
+	"crypto/sha256"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,9 +12,15 @@ import (
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/budget"
+	"github.com/johnnyfreeman/viewscreen/debugevents"
 	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/filter"
 	"github.com/johnnyfreeman/viewscreen/jsonl"
+	"github.com/johnnyfreeman/viewscreen/redact"
 	renderpkg "github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/sink"
 	"github.com/johnnyfreeman/viewscreen/state"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/timeline"
@@ -27,9 +34,13 @@ type Model struct {
 	spinner           spinner.Model
 	state             *state.State
 	timeline          []timeline.Entry
+	entryStarts       []int            // first content line of each timeline entry
+	committed         []timeline.Entry // entries added by the last event, for the sinks
+	titleWaits        []tea.Cmd        // page title lookups to wait for, from the last events
+	rawLines          []string         // input lines applied so far, for :w
 	content           *strings.Builder // rendered cache; pointer avoids copy issues
 	stdinDone         bool
-	scanner           *bufio.Scanner
+	scanner           *jsonl.Reader
 	sidebarStyles     SidebarStyles
 	headerStyles      HeaderStyles
 	layoutMode        LayoutMode
@@ -38,12 +49,34 @@ type Model struct {
 	processor         *events.EventProcessor
 	timelineRenderer  *renderpkg.TimelineRenderer
 	search            Search
+	folds             Folds // expanded tool results
+	selection         Selection
+	cmdline           CommandLine
 	promptEditor      PromptEditor
+	stepper           Stepper             // replay step mode; inert unless enabled
+	replay            ReplayCache         // step mode: rendered events, for instant rewinds
+	replayActivities  []timeline.Activity // running tools of the restored event shown in step mode
 	followMode        bool                // auto-scroll to bottom on new content
 	autoExit          bool                // --auto-exit flag enabled
 	autoExitRemaining int                 // seconds left in countdown, 0 = inactive
 	autoExitCanceled  bool                // user interacted before auto-exit could start
 	showParseErrors   bool                // show malformed stream-json lines in content
+	passNoise         bool                // show non-JSON input lines as muted output
+	turnDivider       string              // the config.TurnDivider* between turns
+	annotations       annotations.Set     // reviewer comments rendered beneath referenced blocks
+	filter            *filter.Filter      // -only/-hide event selection; nil keeps everything
+	configWatcher     *ConfigWatcher      // reports changes to the -config file; nil for none
+	configSum         [sha256.Size]byte   // hash of the -config file's content when last read
+	reloadConfig      liveReloader        // reads the live settings of the -config file
+	toast             Toast               // status message shown for a few seconds
+	redactor          *redact.Redactor    // -redact secret masking; nil masks nothing
+	statusColumn      bool                // mark each block's kind in a left column
+	showToolPane      bool                // split the content area with the running tool's output
+	gutter            bool                // the left column is shown: status column or error gutter
+	outputs           sink.Sink           // text log, JSON and export outputs; nil when none
+	debugEvents       *debugevents.Log    // -debug-events log; nil logs nothing
+	budget            budget.Budget       // -budget spending limit; a zero Limit disables it
+	budgetStopped     bool                // -budget-stop ended rendering once the budget was exceeded
 	streamErr         error               // non-nil when stdin ended because of a scanner/read error
 	agentProcess      managedAgentProcess // non-nil when we spawned the agent
 	rerunStarter      agentProcessStarter // starts replacement agent runs for prompt edits
@@ -104,6 +137,87 @@ func WithVerboseParseErrors(enabled bool) ModelOption {
 	}
 }
 
+// WithNoisePassthrough shows input lines that are not JSON as muted output
+// instead of treating them as parse errors (see events.IsNoise).
+func WithNoisePassthrough(enabled bool) ModelOption {
+	return func(m *Model) {
+		m.passNoise = enabled
+	}
+}
+
+// WithAnnotations renders reviewer comments beneath the blocks they reference.
+func WithAnnotations(set annotations.Set) ModelOption {
+	return func(m *Model) {
+		m.annotations = set
+	}
+}
+
+// WithFilter drops the input lines and content blocks f excludes before they
+// are rendered, buffered for step mode or written to the sink.
+func WithFilter(f *filter.Filter) ModelOption {
+	return func(m *Model) {
+		m.filter = f
+	}
+}
+
+// WithRedactor masks the secrets r finds in each input line before it is
+// filtered, rendered, buffered for step mode or written to the sink.
+func WithRedactor(r *redact.Redactor) ModelOption {
+	return func(m *Model) {
+		m.redactor = r
+	}
+}
+
+// WithTurnDivider separates the main agent's turns with divider, one of
+// the config.TurnDivider* values.
+func WithTurnDivider(divider string) ModelOption {
+	return func(m *Model) {
+		m.turnDivider = divider
+		m.processor.SetTurnDivider(divider)
+	}
+}
+
+// WithStatusColumn shows a narrow column left of the transcript marking each
+// block's kind (assistant, tool, error or diff).
+func WithStatusColumn(enabled bool) ModelOption {
+	return func(m *Model) {
+		m.statusColumn = enabled
+		m.gutter = enabled
+	}
+}
+
+// WithSink writes every applied event to s as well as the viewport, e.g. the
+// -text-log, -emit-json and -export outputs. The caller closes s.
+func WithSink(s sink.Sink) ModelOption {
+	return func(m *Model) {
+		m.outputs = s
+	}
+}
+
+// WithDebugEvents logs how each input line was classified and rendered to
+// l.
+func WithDebugEvents(l *debugevents.Log) ModelOption {
+	return func(m *Model) {
+		m.debugEvents = l
+	}
+}
+
+// WithBudget warns above the transcript as spending nears b's limit and,
+// with b.Stop, stops rendering once it is exceeded.
+func WithBudget(b budget.Budget) ModelOption {
+	return func(m *Model) {
+		m.budget = b
+	}
+}
+
+// WithStepMode starts the model in replay step mode, where input lines are
+// buffered and only applied as the user steps through them.
+func WithStepMode(enabled bool) ModelOption {
+	return func(m *Model) {
+		m.stepper = NewStepper(enabled)
+	}
+}
+
 // WithPrompt sets the initial prompt.
 func WithPrompt(prompt string) ModelOption {
 	return func(m *Model) {
@@ -172,10 +286,13 @@ func NewModel(opts ...ModelOption) Model {
 		sidebarStyles:    NewSidebarStyles(),
 		headerStyles:     NewHeaderStyles(),
 		layoutMode:       LayoutSidebar, // default to sidebar mode
-		processor:        events.NewEventProcessor(st),
+		processor:        newEventProcessor(st),
 		timelineRenderer: renderpkg.NewTimelineRenderer(),
 		search:           NewSearch(),
+		folds:            NewFolds(),
 		promptEditor:     NewPromptEditor(),
+		stepper:          NewStepper(false),
+		replay:           NewReplayCache(replayCacheLimit),
 		followMode:       true, // auto-scroll to bottom by default
 	}
 
@@ -202,8 +319,16 @@ func NewModel(opts ...ModelOption) Model {
 	return m
 }
 
-func newStreamScanner(r io.Reader) *bufio.Scanner {
-	return jsonl.NewScanner(r)
+// newEventProcessor creates the event processor for a session, rendering
+// tool results in full as well so their folds can be expanded.
+func newEventProcessor(st *state.State) *events.EventProcessor {
+	p := events.NewEventProcessor(st)
+	p.Renderers().EnableFullResults()
+	return p
+}
+
+func newStreamScanner(r io.Reader) *jsonl.Reader {
+	return jsonl.NewReader(r, nil)
 }
 
 // Init initializes the model
@@ -211,6 +336,7 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		ReadStdinLine(m.scanner),
+		m.watchConfig(),
 	)
 }
 
@@ -262,6 +388,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case AgentExitedMsg:
 		m = m.handleAgentExited(msg)
 
+	case tea.ResumeMsg:
+		m, cmd = m.handleResume()
+		cmds = append(cmds, cmd)
+
+	case ExternalProgramExitedMsg:
+		m, cmd = m.handleExternalProgramExited(msg)
+		cmds = append(cmds, cmd)
+
+	case ClipboardCopiedMsg:
+		m = m.handleClipboardCopied(msg)
+
+	case SessionSavedMsg:
+		m = m.handleSessionSaved(msg)
+
+	case PageTitleMsg:
+		m = m.handlePageTitle(msg)
+
+	case ConfigChangedMsg:
+		m, cmd = m.handleConfigChanged(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ToastExpiredMsg:
+		m.toast.Expire(msg)
+		m.updateViewportDimensions()
+
 	case RerunMsg:
 		m, cmd = m.handleRerun(msg)
 		if cmd != nil {
@@ -272,6 +425,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m = m.handleParseError(msg)
 	}
 
+	// Wait for the page titles of the results just rendered
+	cmds = append(cmds, m.titleWaits...)
+	m.titleWaits = nil
+
 	// Update viewport
 	m.viewport, cmd = m.viewport.Update(msg)
 	if cmd != nil {
@@ -281,8 +438,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-// processEvent handles a parsed event message
-func (m Model) processEvent(msg tea.Msg) (Model, tea.Cmd) {
+// processEvent handles a parsed event message, read from the input line line
+func (m Model) processEvent(line string, msg tea.Msg) (Model, tea.Cmd) {
 	// The message is already an events.Event from ParseEvent
 	event, ok := msg.(events.Event)
 	if !ok {
@@ -290,23 +447,27 @@ func (m Model) processEvent(msg tea.Msg) (Model, tea.Cmd) {
 	}
 
 	// Process the event through the EventProcessor
-	result := m.processor.Process(event)
+	result := m.processor.ProcessLine(line, event)
 
 	// Append committed timeline entries and refresh the rendered cache.
 	if len(result.Batch.Entries) > 0 {
 		if len(m.timeline) == 0 && m.content.Len() > 0 {
 			m.timeline = append(m.timeline, timeline.Entry{Kind: "legacy", Body: m.content.String()})
 		}
-		m.timeline = append(m.timeline, result.Batch.Entries...)
+		m.committed = m.annotations.Interleave(result.Batch.Entries)
+		m.timeline = timeline.Merge(m.timeline, m.committed...)
 		m.rebuildRenderedContent()
 	}
+	if wait := WaitPageTitles(result); wait != nil {
+		m.titleWaits = append(m.titleWaits, wait)
+	}
 	m.updateSearchMatches()
 
 	// Update viewport based on whether there are pending tools
 	if result.HasPendingTools {
 		m.updateViewportWithPendingTools()
 	} else {
-		m.viewport.SetContent(m.content.String())
+		m.setViewportContent(m.content.String())
 	}
 	if m.followMode {
 		m.viewport.GotoBottom()
@@ -323,33 +484,96 @@ func (m Model) View() tea.View {
 	return v
 }
 
+// setViewportContent shows content in the viewport with the search matches
+// highlighted.
+func (m *Model) setViewportContent(content string) {
+	m.viewport.SetContent(highlightSearchMatches(content, m.search))
+}
+
 // updateViewportWithPendingTools updates the viewport content, rendering pending tools with spinner
 func (m *Model) updateViewportWithPendingTools() {
-	m.viewport.SetContent(m.visibleContent())
+	m.setViewportContent(m.visibleContent())
 }
 
 // visibleContent returns the complete text currently shown in the viewport,
 // including transient pending tool headers that have not yet resolved.
 func (m *Model) visibleContent() string {
 	content := m.content.String()
-	if !m.processor.HasPendingTools() {
+	activities := m.pendingActivities()
+	if len(activities) == 0 {
 		return content
 	}
 
 	var sb strings.Builder
 	sb.WriteString(content)
-	for _, activity := range m.processor.PendingActivities() {
+	for _, activity := range activities {
 		if activity.HeaderRendered {
 			continue
 		}
-		sb.WriteString(m.timelineRenderer.RenderActivity(activity, m.spinner.View()))
+		text := m.timelineRenderer.RenderActivity(activity, m.spinner.View())
+		if m.gutter {
+			col := renderpkg.StatusColumn{ErrorsOnly: !m.statusColumn}
+			text = col.Prefix(timeline.Entry{Kind: "user", Title: activity.Name}, text)
+		}
+		sb.WriteString(text)
 	}
 	return sb.String()
 }
 
+// pendingActivities returns the tools still running at the event shown: the
+// processor's, or those cached with an earlier event restored in step mode.
+func (m *Model) pendingActivities() []timeline.Activity {
+	if m.replay.live != nil {
+		return m.replayActivities
+	}
+	if !m.processor.HasPendingTools() {
+		return nil
+	}
+	return m.processor.PendingActivities()
+}
+
+// rebuildRenderedContent renders the timeline into the content cache. The
+// left column shows every block's kind when the status column is on, and
+// otherwise appears as an error gutter once the session has an error.
 func (m *Model) rebuildRenderedContent() {
 	m.content = &strings.Builder{}
-	m.content.WriteString(m.timelineRenderer.RenderEntries(m.timeline))
+	m.entryStarts = make([]int, 0, len(m.timeline))
+	hadGutter := m.gutter
+	m.gutter = m.statusColumn || slices.ContainsFunc(m.timeline, renderpkg.IsErrorEntry)
+	line := 0
+	col := renderpkg.StatusColumn{ErrorsOnly: !m.statusColumn}
+	selectedEnd, midLine := -1, false
+	if m.selection.Active && m.selection.Index < len(m.timeline) {
+		selectedEnd = blockEnd(m.timeline, m.selection.Index)
+	}
+	for i, entry := range m.timeline {
+		text := m.entryText(i, entry)
+		if i >= m.selection.Index && i < selectedEnd {
+			text, midLine = markSelected(text, midLine)
+		}
+		if m.gutter {
+			text = col.Prefix(entry, text)
+		}
+		m.entryStarts = append(m.entryStarts, line)
+		line += strings.Count(text, "\n")
+		m.content.WriteString(text)
+	}
+	if m.gutter != hadGutter {
+		// Blocks rendered from here on must leave room for the column.
+		m.updateViewportDimensions()
+	}
+}
+
+// focusedEntryID returns the ID of the timeline entry at the top of the
+// viewport, or "" when that entry has no ID.
+func (m Model) focusedEntryID() string {
+	top := m.viewport.YOffset()
+	for i := len(m.entryStarts) - 1; i >= 0; i-- {
+		if m.entryStarts[i] <= top {
+			return m.timeline[i].ID
+		}
+	}
+	return ""
 }
 
 // updateSearchMatches keeps the search status in sync as streamed content grows.
@@ -372,7 +596,7 @@ func (m *Model) appendStreamError(err error) {
 	m.timeline = append(m.timeline, timeline.Entry{Kind: "error", Body: body})
 	m.rebuildRenderedContent()
 	m.updateSearchMatches()
-	m.viewport.SetContent(m.content.String())
+	m.setViewportContent(m.content.String())
 	if m.followMode {
 		m.viewport.GotoBottom()
 	}
@@ -381,9 +605,10 @@ func (m *Model) appendStreamError(err error) {
 // scrollPosition returns the current scroll position from the viewport.
 func (m Model) scrollPosition() ScrollPosition {
 	return ScrollPosition{
-		AtTop:    m.viewport.AtTop(),
-		AtBottom: m.viewport.AtBottom(),
-		Percent:  m.viewport.ScrollPercent(),
+		AtTop:     m.viewport.AtTop(),
+		AtBottom:  m.viewport.AtBottom(),
+		Percent:   m.viewport.ScrollPercent(),
+		FocusedID: m.focusedEntryID(),
 	}
 }
 
@@ -403,24 +628,36 @@ func (m Model) quitCommand() (Model, tea.Cmd) {
 func (m Model) renderLayout() string {
 	// Help modal overlays both layout modes
 	if m.showHelpModal {
-		return RenderContextualHelpModal(m.width, m.height, m.headerStyles, m.autoExitRemaining > 0, m.layoutMode, m.canEditPrompt())
+		return RenderContextualHelpModal(m.width, m.height, m.headerStyles, m.autoExitRemaining > 0, m.layoutMode, m.canEditPrompt(), m.stepper.Enabled)
 	}
 
 	// Render search bar and prompt bar if active
 	searchBar := RenderSearchBar(m.search, m.viewport.Width())
+	selectionBar := RenderSelectionBar(m.selection, m.viewport.Width())
 	promptBar := RenderPromptBar(m.promptEditor, m.viewport.Width())
+	commandBar := RenderCommandLine(m.cmdline, m.viewport.Width())
+	toastBar := RenderToast(m.toast, m.viewport.Width())
+	rawPanel := RenderRawPanel(m.stepper, m.viewport.Width(), rawPanelHeight(m.stepper, m.height))
+	stepBar := RenderStepBar(m.stepper, m.state.TurnCount, m.viewport.Width())
 	scrollPos := m.scrollPosition()
+	transcript := m.viewport.View()
+	if paneWidth := m.toolPaneColumns(); paneWidth > 0 {
+		pane := RenderToolPane(m.state, m.spinner, paneWidth, m.viewport.Height())
+		transcript = lipgloss.JoinHorizontal(lipgloss.Top, transcript, pane)
+	}
+	if budgetBar := RenderBudgetBar(m.budget, m.state.CumulativeCost, m.contentWidth()); budgetBar != "" {
+		transcript = lipgloss.JoinVertical(lipgloss.Left, budgetBar, transcript)
+	}
 
 	switch m.layoutMode {
 	case LayoutHeader:
 		// Header mode: single-line header on top, content below at full width
 		header := RenderHeader(m.state, m.width, m.followMode, scrollPos, m.stdinDone, m.autoExitRemaining, m.streamErr)
-		parts := []string{header, m.viewport.View()}
-		if searchBar != "" {
-			parts = append(parts, searchBar)
-		}
-		if promptBar != "" {
-			parts = append(parts, promptBar)
+		parts := []string{header, transcript}
+		for _, bar := range []string{rawPanel, stepBar, searchBar, selectionBar, promptBar, commandBar, toastBar} {
+			if bar != "" {
+				parts = append(parts, bar)
+			}
 		}
 		layout := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
@@ -433,12 +670,11 @@ func (m Model) renderLayout() string {
 	default:
 		// Sidebar mode: content left, sidebar right
 		sidebar := RenderSidebar(m.state, m.spinner, m.height, m.sidebarStyles, m.followMode, scrollPos, m.stdinDone, m.autoExitRemaining, m.streamErr)
-		mainParts := []string{m.viewport.View()}
-		if searchBar != "" {
-			mainParts = append(mainParts, searchBar)
-		}
-		if promptBar != "" {
-			mainParts = append(mainParts, promptBar)
+		mainParts := []string{transcript}
+		for _, bar := range []string{rawPanel, stepBar, searchBar, selectionBar, promptBar, commandBar, toastBar} {
+			if bar != "" {
+				mainParts = append(mainParts, bar)
+			}
 		}
 		mainContent := lipgloss.JoinVertical(lipgloss.Left, mainParts...)
 		return lipgloss.JoinHorizontal(lipgloss.Top, mainContent, sidebar)
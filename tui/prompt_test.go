@@ -265,7 +265,7 @@ func TestRenderPromptBar(t *testing.T) {
 }
 
 func TestPromptEditorKeyHandling(t *testing.T) {
-	t.Run("e opens editor when stdin done in subprocess mode", func(t *testing.T) {
+	t.Run("p opens editor when stdin done in subprocess mode", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = true
 		m.agentProcess = &fakeAgentProcess{}
@@ -274,16 +274,16 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 		}
 		m.state.Prompt = "test prompt"
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if !m.promptEditor.Active {
-			t.Error("expected prompt editor to be active after pressing e with stdinDone")
+			t.Error("expected prompt editor to be active after pressing p with stdinDone")
 		}
 		if m.promptEditor.Value != "test prompt" {
 			t.Errorf("promptEditor.Value = %q, want %q", m.promptEditor.Value, "test prompt")
 		}
 	})
 
-	t.Run("e still opens editor after failed rerun clears process", func(t *testing.T) {
+	t.Run("p still opens editor after failed rerun clears process", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = true
 		m.state.Prompt = "failed prompt"
@@ -291,7 +291,7 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 			return &fakeAgentProcess{}, nil
 		}
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if !m.promptEditor.Active {
 			t.Error("expected prompt editor to stay available after rerun startup failure")
 		}
@@ -300,18 +300,18 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 		}
 	})
 
-	t.Run("e does nothing when TUI cannot re-run prompt", func(t *testing.T) {
+	t.Run("p does nothing when TUI cannot re-run prompt", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = true
 		m.state.Prompt = "test prompt"
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if m.promptEditor.Active {
 			t.Error("expected prompt editor to remain inactive outside subprocess mode")
 		}
 	})
 
-	t.Run("e does nothing when stdin not done", func(t *testing.T) {
+	t.Run("p does nothing when stdin not done", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = false
 		m.agentProcess = &fakeAgentProcess{}
@@ -319,13 +319,13 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 			return &fakeAgentProcess{}, nil
 		}
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if m.promptEditor.Active {
 			t.Error("expected prompt editor to remain inactive when stdin not done")
 		}
 	})
 
-	t.Run("e does nothing when help modal open", func(t *testing.T) {
+	t.Run("p does nothing when help modal open", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = true
 		m.agentProcess = &fakeAgentProcess{}
@@ -334,13 +334,13 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 		}
 		m.showHelpModal = true
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if m.promptEditor.Active {
 			t.Error("expected prompt editor to remain inactive when help modal is open")
 		}
 	})
 
-	t.Run("e does nothing when details modal open", func(t *testing.T) {
+	t.Run("p does nothing when details modal open", func(t *testing.T) {
 		m := newTestModel()
 		m.stdinDone = true
 		m.agentProcess = &fakeAgentProcess{}
@@ -349,7 +349,7 @@ func TestPromptEditorKeyHandling(t *testing.T) {
 		}
 		m.showDetailsModal = true
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 		if m.promptEditor.Active {
 			t.Error("expected prompt editor to remain inactive when details modal is open")
 		}
@@ -548,10 +548,10 @@ func TestPromptViewportHeight(t *testing.T) {
 		}
 		heightWithout := m.viewport.Height()
 
-		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "e"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "p"})
 
 		if heightWith := m.viewport.Height(); heightWith != heightWithout-1 {
-			t.Errorf("viewport height after e = %d, want %d", heightWith, heightWithout-1)
+			t.Errorf("viewport height after p = %d, want %d", heightWith, heightWithout-1)
 		}
 	})
 
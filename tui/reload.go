@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/fsnotify/fsnotify"
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/filter"
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// configSettle is how long a change to the -config file must be followed
+// by no other before the file is read, so the several writes of one save
+// reload it once. Tests shorten it.
+var configSettle = 100 * time.Millisecond
+
+// liveReloader reads the Live settings of the -config file, as
+// config.Config.ReloadLive does.
+type liveReloader func() (config.Live, error)
+
+// ConfigWatcher reports changes to the -config file, whose live settings a
+// running TUI applies. It watches the file's directory, as editors often
+// save by replacing the file, and goes by a hash of the file's content, so
+// a save that leaves the content as it was does not reload it. A nil
+// *ConfigWatcher watches nothing.
+type ConfigWatcher struct {
+	path    string
+	sum     [sha256.Size]byte // content of the file when watching started
+	watcher *fsnotify.Watcher
+}
+
+// WatchConfigFile starts watching the -config file at path. An empty path
+// watches nothing and returns a nil watcher.
+func WatchConfigFile(path string) (*ConfigWatcher, error) {
+	if path == "" {
+		return nil, nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	return &ConfigWatcher{path: path, sum: fileSum(path), watcher: watcher}, nil
+}
+
+// Close stops watching.
+func (w *ConfigWatcher) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.watcher.Close()
+}
+
+// fileSum returns the SHA-256 hash of the file at path, or that of no
+// content when it cannot be read.
+func fileSum(path string) [sha256.Size]byte {
+	data, _ := os.ReadFile(path)
+	return sha256.Sum256(data)
+}
+
+// Next returns a command that waits for the content of the file to differ
+// from sum and then reads it again with reload. A watch error is reported
+// in the same way, with sum unchanged.
+func (w *ConfigWatcher) Next(sum [sha256.Size]byte, reload liveReloader) tea.Cmd {
+	if w == nil || reload == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		var settled <-chan time.Time
+		for {
+			select {
+			case event, ok := <-w.watcher.Events:
+				if !ok {
+					return nil
+				}
+				if filepath.Base(event.Name) == filepath.Base(w.path) {
+					settled = time.After(configSettle)
+				}
+			case err, ok := <-w.watcher.Errors:
+				if !ok {
+					return nil
+				}
+				return ConfigChangedMsg{Sum: sum, Err: err}
+			case <-settled:
+				settled = nil
+				if changed := fileSum(w.path); changed != sum {
+					live, err := reload()
+					return ConfigChangedMsg{Sum: changed, Live: live, Err: err}
+				}
+			}
+		}
+	}
+}
+
+// WithConfigFile applies the settings reload reads from the -config file
+// each time w reports a change to it.
+func WithConfigFile(w *ConfigWatcher, reload func() (config.Live, error)) ModelOption {
+	return func(m *Model) {
+		if w == nil {
+			return
+		}
+		m.configWatcher = w
+		m.configSum = w.sum
+		m.reloadConfig = reload
+	}
+}
+
+// watchConfig waits for the next change to the -config file, or returns nil
+// when there is none.
+func (m Model) watchConfig() tea.Cmd {
+	return m.configWatcher.Next(m.configSum, m.reloadConfig)
+}
+
+// handleConfigChanged applies the settings of a changed -config file,
+// confirming the reload with a toast, and waits for the next change. A file
+// that fails to load leaves the settings as they were.
+func (m Model) handleConfigChanged(msg ConfigChangedMsg) (Model, tea.Cmd) {
+	m.configSum = msg.Sum
+	var toast tea.Cmd
+	if msg.Err != nil {
+		toast = m.toast.Show("Reload failed: "+msg.Err.Error(), true)
+	} else {
+		m.applyLive(msg.Live)
+		toast = m.toast.Show("Reloaded "+m.configWatcher.path, false)
+	}
+	m.updateViewportDimensions()
+	return m, tea.Batch(toast, m.watchConfig())
+}
+
+// applyLive makes the colors, verbosity and filters of live current and
+// renders the session again with them. In step mode, the events already shown keep
+// their rendering, and the events stepped to next use the new settings.
+func (m *Model) applyLive(live config.Live) {
+	style.SetBaseTheme(live.Theme)
+	style.SetOverrides(live.ColorOverrides)
+	style.Init(style.NoColor())
+	m.spinner.Style = lipgloss.NewStyle().Foreground(lipgloss.Color(string(style.CurrentTheme.Accent)))
+	m.sidebarStyles = NewSidebarStyles()
+	m.headerStyles = NewHeaderStyles()
+	config.Get().VerboseLevel = live.VerboseLevel
+	m.showParseErrors = live.VerboseLevel > 0
+	m.filter = filter.New(live.Only, live.Hide, filter.WithMutedTools(live.MuteTools), filter.WithFocusTools(live.FocusTools))
+	if !m.stepper.Enabled {
+		m.rerender()
+	}
+}
+
+// rerender renders the session again from the input lines read so far,
+// through the current filter, keeping the scroll position unless following
+// new output.
+func (m *Model) rerender() {
+	offset := m.viewport.YOffset()
+	lines := m.rawLines
+	m.resetSession(m.prompt)
+	m.updateViewportDimensions()
+	for _, line := range lines {
+		m.applyLine(line, false)
+	}
+	m.rebuildRenderedContent()
+	m.updateViewportDimensions()
+	if m.followMode {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.SetYOffset(offset)
+	}
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/filter"
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+func TestHandleConfigChanged(t *testing.T) {
+	defer func(level int) {
+		config.Get().VerboseLevel = level
+		style.SetBaseTheme(style.DefaultTheme)
+		style.SetOverrides(nil)
+		style.Init(style.NoColor())
+	}(config.Get().VerboseLevel)
+
+	f, err := os.Open("../testdata/bash_ls.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	path := filepath.Join(t.TempDir(), "viewscreen.conf")
+	watcher, err := WatchConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	reload := func() (config.Live, error) { return config.Live{}, nil }
+	h, err := NewHarness(f, 100, 30,
+		WithFilter(filter.New(nil, nil, filter.WithMutedTools([]string{"Bash"}))),
+		WithConfigFile(watcher, reload),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(h.Screen(), "Bash") {
+		t.Fatal("expected the muted Bash call to be hidden")
+	}
+
+	h.Send(ConfigChangedMsg{Err: errors.New("viewscreen.conf:1: unknown flag -x")})
+	if !strings.Contains(h.Screen(), "✗ Reload failed") || strings.Contains(h.Screen(), "Bash") {
+		t.Errorf("expected a failed reload to keep the filter and say so, got:\n%s", h.Screen())
+	}
+
+	h.Send(ConfigChangedMsg{Live: config.Live{Theme: style.HighContrastTheme}})
+	if !strings.Contains(h.Screen(), "✓ Reloaded "+filepath.Dir(path)[:8]) {
+		t.Errorf("expected the reload confirmed, got:\n%s", h.Screen())
+	}
+	if !strings.Contains(h.Screen(), "Bash") {
+		t.Errorf("expected the Bash call rendered once unmuted, got:\n%s", h.Screen())
+	}
+	if style.CurrentTheme != style.HighContrastTheme && !style.NoColor() {
+		t.Error("expected the reloaded theme to be current")
+	}
+	h.Send(ConfigChangedMsg{Live: config.Live{VerboseLevel: 1}})
+	if !config.Get().IsVerbose() {
+		t.Error("expected the reloaded verbosity to be current")
+	}
+
+	// Keys leave the toast; only its own expiry clears it.
+	h.Send(ToastExpiredMsg{Shown: 2})
+	if !strings.Contains(h.Screen(), "Reloaded") {
+		t.Errorf("expected an earlier toast's expiry to keep the toast, got:\n%s", h.Screen())
+	}
+	h.Send(ToastExpiredMsg{Shown: 3})
+	if strings.Contains(h.Screen(), "Reloaded") {
+		t.Errorf("expected the toast to expire, got:\n%s", h.Screen())
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	defer func(d time.Duration) { configSettle = d }(configSettle)
+	configSettle = 10 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "viewscreen.conf")
+	if err := os.WriteFile(path, []byte("-hide thinking\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := WatchConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	var reloads atomic.Int32
+	reload := func() (config.Live, error) {
+		reloads.Add(1)
+		return config.Live{Hide: []string{"tool_use"}}, nil
+	}
+
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- watcher.Next(watcher.sum, reload)() }()
+
+	// A save that leaves the content as it was is not a change.
+	if err := os.WriteFile(path, []byte("-hide thinking\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * configSettle)
+	if err := os.WriteFile(path, []byte("-hide tool_use\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-msgs:
+		changed, ok := msg.(ConfigChangedMsg)
+		if !ok || changed.Err != nil || changed.Sum != fileSum(path) || len(changed.Live.Hide) != 1 {
+			t.Errorf("got %+v, want the changed file read again", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	if n := reloads.Load(); n != 1 {
+		t.Errorf("got %d reloads, want 1", n)
+	}
+}
+
+func TestWatchConfigFile_NoPath(t *testing.T) {
+	watcher, err := WatchConfigFile("")
+	if watcher != nil || err != nil {
+		t.Errorf("WatchConfigFile(\"\") = %v, %v, want no watcher", watcher, err)
+	}
+	if watcher.Next([32]byte{}, nil) != nil || watcher.Close() != nil {
+		t.Error("expected a nil watcher to watch nothing")
+	}
+}
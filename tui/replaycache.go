@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+const (
+	replayCacheLimit = 64 << 20 // approximate bytes the replay cache may hold
+	entryOverhead    = 256      // approximate bytes of a cached entry besides its text
+)
+
+// ReplayCache keeps what each event of a step-mode replay rendered, grouped
+// by turn: the timeline as it stood before the turn, and the entries and
+// session state each of the turn's events produced. Stepping back to an
+// event, or forward again to one seen before, is restored from the cache
+// instead of re-rendering every event from the start; the event processor
+// stays at the furthest event processed. Once the cache grows past its limit
+// the least recently used turns are evicted, and reaching into an evicted
+// turn re-renders the session up to it.
+type ReplayCache struct {
+	turns  []*replayTurn // in order; nil once evicted
+	events []int         // index in turns of each processed event's turn
+	limit  int
+	size   int
+	clock  int
+	live   *replaySession // the session at the furthest event, while an earlier one is shown
+}
+
+// replayTurn holds the cached events of one turn.
+type replayTurn struct {
+	turn   int              // session turn count while its events were applied
+	first  int              // events processed before its first event
+	base   []timeline.Entry // timeline before its first event
+	before *state.State     // session state before its first event
+	raw    int              // input lines applied before its first event
+	frames []replayFrame
+	size   int
+	used   int // clock value when last recorded or restored
+}
+
+// replayFrame is the cached effect of a single event.
+type replayFrame struct {
+	entries    []timeline.Entry    // entries the event added to the timeline
+	state      *state.State        // session state after the event
+	activities []timeline.Activity // tools still running after the event
+	raw        int                 // input lines applied so far, for :w
+}
+
+// replaySession is the session an event left the model in.
+type replaySession struct {
+	timeline   []timeline.Entry
+	state      *state.State
+	rawLines   []string
+	activities []timeline.Activity
+}
+
+// NewReplayCache creates an empty cache holding about limit bytes.
+func NewReplayCache(limit int) ReplayCache {
+	return ReplayCache{limit: limit}
+}
+
+// Processed returns the number of events the event processor has applied.
+func (c *ReplayCache) Processed() int {
+	return len(c.events)
+}
+
+// Begin prepares to record the next event, which is applied to a session
+// with the given timeline, state and input lines. An event applied in a new
+// turn starts a new cached turn.
+func (c *ReplayCache) Begin(entries []timeline.Entry, st *state.State, raw int) {
+	if n := len(c.turns); n > 0 && c.turns[n-1] != nil && c.turns[n-1].turn == st.TurnCount {
+		return
+	}
+	t := &replayTurn{
+		turn:   st.TurnCount,
+		first:  len(c.events),
+		base:   slices.Clone(entries),
+		before: st.Clone(),
+		raw:    raw,
+	}
+	t.size = len(t.base) * entryOverhead
+	c.turns = append(c.turns, t)
+	c.size += t.size
+}
+
+// Record caches the effect of the event prepared for by Begin.
+func (c *ReplayCache) Record(f replayFrame) {
+	t := c.turns[len(c.turns)-1]
+	f.state = f.state.Clone()
+	size := entryOverhead
+	for _, e := range f.entries {
+		size += entryOverhead + len(e.Body) + len(e.Full)
+		for _, line := range e.Lines {
+			size += len(line)
+		}
+	}
+	t.frames = append(t.frames, f)
+	t.size += size
+	c.size += size
+	c.events = append(c.events, len(c.turns)-1)
+	c.touch(t)
+	c.evict()
+}
+
+// Turn returns the session turn count after the first n events, and whether
+// they are cached.
+func (c *ReplayCache) Turn(n int) (int, bool) {
+	t, frame, ok := c.lookup(n)
+	if !ok {
+		return 0, false
+	}
+	if frame < 0 {
+		return t.before.TurnCount, true
+	}
+	return t.frames[frame].state.TurnCount, true
+}
+
+// Restore returns the session as the first n events left it, and whether
+// they are cached. The session shares nothing mutable with the cache.
+func (c *ReplayCache) Restore(n int) (replaySession, bool) {
+	t, frame, ok := c.lookup(n)
+	if !ok {
+		return replaySession{}, false
+	}
+	c.touch(t)
+	s := replaySession{timeline: slices.Clone(t.base), state: t.before, activities: nil}
+	raw := t.raw
+	for _, f := range t.frames[:frame+1] {
+		s.timeline = timeline.Merge(s.timeline, f.entries...)
+		s.state, s.activities, raw = f.state, f.activities, f.raw
+	}
+	s.state = s.state.Clone()
+	if c.live != nil {
+		s.rawLines = c.live.rawLines[:raw]
+	}
+	return s, true
+}
+
+// lookup finds the cached turn holding the first n events, and the index
+// of the n-th event's frame in it, or -1 when n is the turn's start.
+func (c *ReplayCache) lookup(n int) (*replayTurn, int, bool) {
+	if n < 0 || n > len(c.events) || len(c.turns) == 0 {
+		return nil, 0, false
+	}
+	if n == 0 {
+		t := c.turns[0]
+		return t, -1, t != nil
+	}
+	t := c.turns[c.events[n-1]]
+	if t == nil {
+		return nil, 0, false
+	}
+	return t, n - 1 - t.first, true
+}
+
+func (c *ReplayCache) touch(t *replayTurn) {
+	c.clock++
+	t.used = c.clock
+}
+
+// evict drops the least recently used turns until the cache fits its limit,
+// keeping the turn being recorded.
+func (c *ReplayCache) evict() {
+	for c.size > c.limit {
+		oldest := -1
+		for i, t := range c.turns[:len(c.turns)-1] {
+			if t != nil && (oldest < 0 || t.used < c.turns[oldest].used) {
+				oldest = i
+			}
+		}
+		if oldest < 0 {
+			return
+		}
+		c.size -= c.turns[oldest].size
+		c.turns[oldest] = nil
+	}
+}
+
+// Reset empties the cache, as when the session is discarded.
+func (c *ReplayCache) Reset() {
+	*c = NewReplayCache(c.limit)
+}
+
+// applyStepLine applies a line in step mode, caching what it rendered.
+func (m *Model) applyStepLine(line string, fresh bool) {
+	m.replay.Begin(m.timeline, m.state, len(m.rawLines))
+	m.applyLine(line, fresh)
+	m.replay.Record(replayFrame{
+		entries:    m.committed,
+		state:      m.state,
+		activities: m.processor.PendingActivities(),
+		raw:        len(m.rawLines),
+	})
+}
+
+// showReplayEvent shows the session as the first n events left it, from the
+// replay cache, and reports whether they were cached.
+func (m *Model) showReplayEvent(n int) bool {
+	if n == m.replay.Processed() {
+		m.returnToLiveEvent()
+		return true
+	}
+	if m.replay.live == nil {
+		m.replay.live = &replaySession{timeline: m.timeline, state: m.state, rawLines: m.rawLines}
+	}
+	s, ok := m.replay.Restore(n)
+	if !ok {
+		m.returnToLiveEvent()
+		return false
+	}
+	m.showReplaySession(s)
+	return true
+}
+
+// returnToLiveEvent shows the session at the furthest event processed again,
+// so the event processor can apply the next one.
+func (m *Model) returnToLiveEvent() {
+	if m.replay.live == nil {
+		return
+	}
+	live := *m.replay.live
+	m.replay.live = nil
+	m.showReplaySession(live)
+}
+
+func (m *Model) showReplaySession(s replaySession) {
+	budgetLevel := m.budget.Level(m.state.CumulativeCost)
+	m.timeline, m.state, m.rawLines = s.timeline, s.state, s.rawLines
+	if m.budget.Level(m.state.CumulativeCost) != budgetLevel {
+		m.updateViewportDimensions() // the budget banner came or went
+	}
+	m.replayActivities = s.activities
+	m.selection = Selection{}
+	m.rebuildRenderedContent()
+	m.updateSearchMatches()
+	m.setViewportContent(m.visibleContent())
+	if m.followMode {
+		m.viewport.GotoBottom()
+	}
+}
+
+// replayFromStart re-renders the first n events with a fresh session, when
+// the turn holding the n-th has been evicted from the replay cache.
+func (m *Model) replayFromStart(n int) {
+	lines := m.stepper.lines[:n]
+	m.resetSession(m.prompt)
+	m.updateViewportDimensions()
+	for _, line := range lines {
+		m.applyStepLine(line, false)
+	}
+}
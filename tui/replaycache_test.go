@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// recordReplayEvents records one event per body into c, starting a new
+// turn at each body that begins with "turn".
+func recordReplayEvents(c *ReplayCache, bodies ...string) {
+	st := state.NewState()
+	var entries []timeline.Entry
+	for _, body := range bodies {
+		if strings.HasPrefix(body, "turn") {
+			st.TurnCount++
+		}
+		c.Begin(entries, st, len(entries))
+		entry := timeline.Entry{Kind: "assistant", Body: body}
+		entries = append(entries, entry)
+		c.Record(replayFrame{entries: []timeline.Entry{entry}, state: st, raw: len(entries)})
+	}
+}
+
+func TestReplayCache_Restore(t *testing.T) {
+	c := NewReplayCache(replayCacheLimit)
+	recordReplayEvents(&c, "init", "turn 1", "tool", "turn 2")
+	c.live = &replaySession{rawLines: []string{"a", "b", "c", "d"}}
+
+	if c.Processed() != 4 {
+		t.Fatalf("Processed() = %d, want 4", c.Processed())
+	}
+	s, ok := c.Restore(3)
+	if !ok {
+		t.Fatal("expected event 3 to be cached")
+	}
+	if len(s.timeline) != 3 || s.timeline[2].Body != "tool" || s.state.TurnCount != 1 || len(s.rawLines) != 3 {
+		t.Errorf("Restore(3) = %d entries, turn %d, %d raw lines", len(s.timeline), s.state.TurnCount, len(s.rawLines))
+	}
+	if turn, ok := c.Turn(4); !ok || turn != 2 {
+		t.Errorf("Turn(4) = %d, %v; want 2, true", turn, ok)
+	}
+	if s, ok := c.Restore(0); !ok || len(s.timeline) != 0 || s.state.TurnCount != 0 {
+		t.Errorf("expected Restore(0) to be the empty session, got %+v", s)
+	}
+
+	s.timeline = append(s.timeline, timeline.Entry{Body: "extra"})
+	s.state.TurnCount = 9
+	if again, _ := c.Restore(3); len(again.timeline) != 3 || again.state.TurnCount != 1 {
+		t.Error("expected changes to a restored session to leave the cache untouched")
+	}
+}
+
+func TestReplayCache_Evicts(t *testing.T) {
+	// Room for about two small turns.
+	c := NewReplayCache(8 * entryOverhead)
+	recordReplayEvents(&c, "turn 1", "turn 2", "turn 3", "turn 4")
+
+	if _, ok := c.Restore(1); ok {
+		t.Error("expected the oldest turn to be evicted")
+	}
+	if _, ok := c.Restore(4); !ok {
+		t.Error("expected the turn being recorded to be kept")
+	}
+	if c.size > c.limit {
+		t.Errorf("cache size %d exceeds its limit %d", c.size, c.limit)
+	}
+}
+
+func TestStepMode_RewindsFromCache(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"first reply"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"second reply"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"third reply"}]}}`,
+	}
+	jump := func(m Model, n string) Model {
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "g"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: n})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+		return m
+	}
+	newStepModel := func() Model {
+		m := NewModel(WithInitialSize(120, 40), WithStepMode(true))
+		for _, line := range lines {
+			m, _ = m.handleRawLine(RawLineMsg{Line: line})
+		}
+		return jump(m, "3")
+	}
+
+	t.Run("without re-rendering", func(t *testing.T) {
+		m := newStepModel()
+		processor, live := m.processor, m.state
+		m = jump(m, "1")
+		if m.processor != processor || m.replay.Processed() != 3 {
+			t.Fatal("expected the rewind to keep the processor at the furthest event")
+		}
+		if strings.Contains(m.content.String(), "second reply") || m.state.TurnCount != 1 {
+			t.Errorf("expected event 1 shown, got turn %d: %q", m.state.TurnCount, m.content.String())
+		}
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: " "})
+		if !strings.Contains(m.content.String(), "second reply") || strings.Contains(m.content.String(), "third reply") {
+			t.Errorf("expected stepping to restore event 2, got %q", m.content.String())
+		}
+		m = jump(m, "3")
+		if !strings.Contains(m.content.String(), "third reply") || m.state != live {
+			t.Errorf("expected the live session back at event 3, got %q", m.content.String())
+		}
+	})
+
+	t.Run("re-renders evicted turns", func(t *testing.T) {
+		m := newStepModel()
+		m.replay.turns[0] = nil
+		m = jump(m, "1")
+		if m.replay.Processed() != 1 || !strings.Contains(m.content.String(), "first reply") {
+			t.Errorf("expected event 1 re-rendered, got %d processed: %q", m.replay.Processed(), m.content.String())
+		}
+		m = jump(m, "3")
+		if !strings.Contains(m.content.String(), "third reply") || m.replay.Processed() != 3 {
+			t.Errorf("expected stepping on to render the rest, got %q", m.content.String())
+		}
+	})
+}
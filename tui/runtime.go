@@ -8,8 +8,19 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/johnnyfreeman/viewscreen/agent"
+	"github.com/johnnyfreeman/viewscreen/annotations"
+	"github.com/johnnyfreeman/viewscreen/budget"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/debugevents"
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"github.com/johnnyfreeman/viewscreen/filter"
+	"github.com/johnnyfreeman/viewscreen/live"
+	"github.com/johnnyfreeman/viewscreen/metrics"
+	"github.com/johnnyfreeman/viewscreen/notify"
+	"github.com/johnnyfreeman/viewscreen/redact"
 	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/source"
 	"golang.org/x/term"
 )
 
@@ -23,14 +34,55 @@ func agentStarter(name string) func(string) (managedAgentProcess, error) {
 
 // Run starts the TUI and returns the final rendered content for optional dumping.
 func Run() (string, error) {
+	stdinIsTTY := isatty(os.Stdin.Fd())
+	return runStream(streamInputReader(os.Stdin, stdinIsTTY), stdinIsTTY)
+}
+
+// RunWithInput starts the TUI on stream-json lines read from r instead of
+// stdin, e.g. a replayed session.
+func RunWithInput(r io.Reader) (string, error) {
+	return runStream(r, isatty(os.Stdin.Fd()))
+}
+
+func runStream(input io.Reader, stdinIsTTY bool) (string, error) {
 	// Initialize styles (needed for renderers)
 	cfg := config.Get()
 	render.NewMarkdownRenderer(cfg.NoColor(), 80)
+
+	notes, err := loadAnnotations(cfg.AnnotationsPath)
+	if err != nil {
+		return "", err
+	}
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return "", err
+	}
+	input, inputs, err := source.Open(cfg, input)
+	if err != nil {
+		return "", err
+	}
+	defer inputs.Close()
+	debugLog, err := debugevents.Create(cfg.DebugEventsPath)
+	if err != nil {
+		return "", err
+	}
+	defer debugLog.Close()
+	keys, err := CreateKeyLog(cfg.KeyLogPath)
+	if err != nil {
+		return "", err
+	}
+	defer keys.Close()
+	outputs, err := openSinks(cfg)
+	if err != nil {
+		return "", err
+	}
+	watcher := watchConfigFile(cfg.ConfigPath)
+	defer watcher.Close()
+
 	resetTerminalModes(os.Stdout)
 
-	var opts []tea.ProgramOption
+	opts := keys.ProgramOptions()
 	width, height := detectTerminalSize(os.Stdout)
-	stdinIsTTY := isatty(os.Stdin.Fd())
 
 	// When stdin is not a TTY (e.g., piped input), keyboard input must come
 	// from /dev/tty instead of the stream-json pipe.
@@ -43,22 +95,89 @@ func Run() (string, error) {
 	}
 
 	p := tea.NewProgram(NewModel(
-		WithInputReader(streamInputReader(os.Stdin, stdinIsTTY)),
+		WithInputReader(input),
 		WithInitialSize(width, height),
 		WithStartupInputGrace(startupInputGrace),
 		WithAutoExit(cfg.AutoExit),
 		WithVerboseParseErrors(cfg.IsVerbose()),
+		WithNoisePassthrough(cfg.PassNoise),
+		WithTurnDivider(cfg.TurnDivider),
+		WithAnnotations(notes),
+		WithFilter(newFilter(cfg)),
+		WithConfigFile(watcher, cfg.ReloadLive),
+		WithRedactor(redactor),
+		WithStatusColumn(cfg.StatusColumn),
+		WithSink(outputs),
+		WithDebugEvents(debugLog),
+		WithBudget(budget.Budget{Limit: cfg.Budget, Stop: cfg.BudgetStop}),
+		WithStepMode(cfg.Step),
 	), opts...)
 
 	finalModel, err := p.Run()
+	closeErr := errors.Join(inputs.Close(), outputs.Close(), keys.Close(), debugLog.Close())
 	if err != nil {
 		return "", err
 	}
 
 	if m, ok := finalModel.(Model); ok {
-		return m.content.String(), nil
+		if closeErr == nil {
+			closeErr = m.budgetErr()
+		}
+		return m.content.String(), closeErr
+	}
+	return "", closeErr
+}
+
+// newFilter builds the -only, -hide, -mute-tool and -focus-tool filter.
+func newFilter(cfg *config.Config) *filter.Filter {
+	return filter.New(cfg.Only, cfg.Hide, filter.WithMutedTools(cfg.MuteTools), filter.WithFocusTools(cfg.FocusTools))
+}
+
+// watchConfigFile watches the -config file for changes. The file was read
+// at startup all the same, so failing to watch it is only a warning.
+func watchConfigFile(path string) *ConfigWatcher {
+	watcher, err := WatchConfigFile(path)
+	if err != nil {
+		diag.Default().Warnf("-config: not watching %s for changes: %v", path, err)
+	}
+	return watcher
+}
+
+// newRedactor builds the -redact secret masker, or nil when -redact is off.
+func newRedactor(cfg *config.Config) (*redact.Redactor, error) {
+	if !cfg.Redact {
+		return nil, nil
+	}
+	return redact.New(cfg.RedactPatterns)
+}
+
+// loadAnnotations loads the -annotations sidecar file, if any.
+func loadAnnotations(path string) (annotations.Set, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return annotations.Load(path)
+}
+
+// openSinks creates the -text-log, -emit-json and -export outputs, the
+// -notify desktop notifications, the -metrics-listen counters and the
+// -serve live view, which receive every event in the same pass as the
+// viewport.
+func openSinks(cfg *config.Config) (sink.Sink, error) {
+	outputs, err := sink.Open(sink.Paths{Text: cfg.TextLogPath, JSON: cfg.EmitJSONPath, Export: cfg.ExportPath, ExportSplit: cfg.ExportSplit, Blame: cfg.BlamePath, Summary: cfg.SummaryJSONPath})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Notify {
+		outputs = sink.Multi(outputs, notify.Sink(notify.New(os.Stderr)))
+	}
+	if reg := metrics.Default(); reg != nil {
+		outputs = sink.Multi(outputs, reg.Sink())
 	}
-	return "", nil
+	if srv := live.Default(); srv != nil {
+		outputs = sink.Multi(outputs, srv.Sink())
+	}
+	return outputs, nil
 }
 
 func streamInputReader(stdin io.Reader, stdinIsTTY bool) io.Reader {
@@ -76,6 +195,15 @@ func RunWithPrompt(prompt string) (string, error) {
 	render.NewMarkdownRenderer(cfg.NoColor(), 80)
 	resetTerminalModes(os.Stdout)
 
+	notes, err := loadAnnotations(cfg.AnnotationsPath)
+	if err != nil {
+		return "", err
+	}
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return "", err
+	}
+
 	start := agentStarter(cfg.Agent)
 	proc, err := start(prompt)
 	if err != nil {
@@ -87,8 +215,37 @@ func RunWithPrompt(prompt string) (string, error) {
 		_ = proc.Wait()
 		return "", errors.New("agent stdout unavailable")
 	}
+	input, inputs, err := source.Open(cfg, stdout)
+	if err != nil {
+		_ = proc.Kill()
+		_ = proc.Wait()
+		return "", err
+	}
+	defer inputs.Close()
+	debugLog, err := debugevents.Create(cfg.DebugEventsPath)
+	if err != nil {
+		_ = proc.Kill()
+		_ = proc.Wait()
+		return "", err
+	}
+	defer debugLog.Close()
+	keys, err := CreateKeyLog(cfg.KeyLogPath)
+	if err != nil {
+		_ = proc.Kill()
+		_ = proc.Wait()
+		return "", err
+	}
+	defer keys.Close()
+	outputs, err := openSinks(cfg)
+	if err != nil {
+		_ = proc.Kill()
+		_ = proc.Wait()
+		return "", err
+	}
+	watcher := watchConfigFile(cfg.ConfigPath)
+	defer watcher.Close()
 
-	var teaOpts []tea.ProgramOption
+	teaOpts := keys.ProgramOptions()
 	width, height := detectTerminalSize(os.Stdout)
 	tty, err := os.Open("/dev/tty")
 	if err == nil {
@@ -97,7 +254,7 @@ func RunWithPrompt(prompt string) (string, error) {
 	}
 
 	model := NewModel(
-		WithInputReader(stdout),
+		WithInputReader(input),
 		WithAgentProcess(proc),
 		WithAgentStarter(start),
 		WithPrompt(prompt),
@@ -106,11 +263,22 @@ func RunWithPrompt(prompt string) (string, error) {
 		WithStartupInputGrace(startupInputGrace),
 		WithAutoExit(cfg.AutoExit),
 		WithVerboseParseErrors(cfg.IsVerbose()),
+		WithNoisePassthrough(cfg.PassNoise),
+		WithTurnDivider(cfg.TurnDivider),
+		WithAnnotations(notes),
+		WithFilter(newFilter(cfg)),
+		WithConfigFile(watcher, cfg.ReloadLive),
+		WithRedactor(redactor),
+		WithStatusColumn(cfg.StatusColumn),
+		WithSink(outputs),
+		WithDebugEvents(debugLog),
+		WithBudget(budget.Budget{Limit: cfg.Budget, Stop: cfg.BudgetStop}),
 	)
 
 	p := tea.NewProgram(model, teaOpts...)
 
 	finalModel, err := p.Run()
+	closeErr := errors.Join(inputs.Close(), outputs.Close(), keys.Close(), debugLog.Close())
 	if err != nil {
 		_ = proc.Kill()
 		_ = proc.Wait()
@@ -126,10 +294,13 @@ func RunWithPrompt(prompt string) (string, error) {
 		} else {
 			_ = proc.Wait()
 		}
-		return m.content.String(), nil
+		if closeErr == nil {
+			closeErr = m.budgetErr()
+		}
+		return m.content.String(), closeErr
 	}
 	_ = proc.Wait()
-	return "", nil
+	return "", closeErr
 }
 
 // isatty returns true if the file descriptor is a terminal.
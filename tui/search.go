@@ -183,6 +183,56 @@ func RenderSearchBar(s Search, width int) string {
 	return fitBarLine(prefix+query+cursor+status, width)
 }
 
+// highlightSearchMatches marks each occurrence of the query on the matching
+// lines of content, which must be the content the matches were found in. The
+// occurrences on the current match's line are marked more strongly.
+func highlightSearchMatches(content string, s Search) string {
+	if len(s.matchLines) == 0 {
+		return content
+	}
+	query := strings.ToLower(normalizeSearchQueryText(s.Query))
+	lines := strings.Split(content, "\n")
+	current := s.CurrentLine()
+	for _, i := range s.matchLines {
+		if i >= len(lines) {
+			continue
+		}
+		mark := style.SearchMatchText
+		if i == current {
+			mark = style.SearchCurrentMatchText
+		}
+		lines[i] = highlightLine(lines[i], query, mark)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightLine wraps each occurrence of the lowercase query in line with
+// mark. The line may be styled; styles around the occurrences are kept.
+func highlightLine(line, query string, mark func(string) string) string {
+	plain := ansi.Strip(line)
+	lower := strings.ToLower(plain)
+	if len(lower) != len(plain) {
+		// Case folding moved byte offsets; leave the line unmarked.
+		return line
+	}
+	var found [][2]int
+	for off := 0; off < len(lower); {
+		i := strings.Index(lower[off:], query)
+		if i < 0 {
+			break
+		}
+		found = append(found, [2]int{off + i, off + i + len(query)})
+		off += i + len(query)
+	}
+	// Mark from the right so the cell columns of earlier occurrences hold.
+	for j := len(found) - 1; j >= 0; j-- {
+		start, end := found[j][0], found[j][1]
+		left, right := ansi.StringWidth(plain[:start]), ansi.StringWidth(plain[:end])
+		line = ansi.Truncate(line, left, "") + ansi.ResetStyle + mark(plain[start:end]) + ansi.TruncateLeft(line, right, "")
+	}
+	return line
+}
+
 func normalizeSearchQueryText(s string) string {
 	return searchQueryLineBreakReplacer.Replace(s)
 }
@@ -296,6 +296,49 @@ func TestSearchUpdateMatchesPreservingSelection(t *testing.T) {
 	}
 }
 
+func TestHighlightLine(t *testing.T) {
+	mark := func(s string) string { return "<" + s + ">" }
+
+	line := "\x1b[31mFoo bar foo\x1b[m baz"
+	got := highlightLine(line, "foo", mark)
+	if plain := ansi.Strip(got); plain != "<Foo> bar <foo> baz" {
+		t.Errorf("highlightLine() = %q, want both occurrences marked", plain)
+	}
+	if !strings.Contains(got, "\x1b[31m") {
+		t.Errorf("expected the line's own styles to be kept, got %q", got)
+	}
+
+	if got := highlightLine("日本語 text", "text", mark); ansi.Strip(got) != "日本語 <text>" {
+		t.Errorf("highlightLine() with wide characters = %q", ansi.Strip(got))
+	}
+	if got := highlightLine("nothing here", "foo", mark); got != "nothing here" {
+		t.Errorf("expected lines without the query unchanged, got %q", got)
+	}
+}
+
+func TestHighlightSearchMatches(t *testing.T) {
+	content := "alpha\nbeta\nalphabet"
+	s := NewSearch()
+	s.Query = "alpha"
+	s.UpdateMatches(content)
+
+	got := strings.Split(highlightSearchMatches(content, s), "\n")
+	if got[1] != "beta" {
+		t.Errorf("expected lines without matches unchanged, got %q", got[1])
+	}
+	if got[0] == "alpha" || got[2] == "alphabet" {
+		t.Errorf("expected matching lines to be marked, got %q", got)
+	}
+	if got[0] == strings.Replace(got[2], "bet", "", 1) {
+		t.Error("expected the current match to be marked differently from the others")
+	}
+
+	s.Clear()
+	if highlightSearchMatches(content, s) != content {
+		t.Error("expected no highlights without a query")
+	}
+}
+
 func TestRenderSearchBar(t *testing.T) {
 	t.Run("no search active and no query", func(t *testing.T) {
 		s := NewSearch()
@@ -0,0 +1,195 @@
+package tui
+
+import (
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+// selectionMarker marks each line of the focused block in selection mode.
+const selectionMarker = "▌ "
+
+// Selection holds the state of block selection mode, where the reader moves
+// a focus between transcript blocks and copies one to the clipboard.
+type Selection struct {
+	Active bool   // whether selection mode is on
+	Index  int    // timeline index of the first entry of the focused block
+	Notice string // outcome of the last copy, shown until the next key
+}
+
+// Visible reports whether the selection bar is shown.
+func (s Selection) Visible() bool {
+	return s.Active || s.Notice != ""
+}
+
+// RenderSelectionBar renders the selection mode bar at the bottom of the
+// viewport, or the outcome of the last copy.
+func RenderSelectionBar(s Selection, width int) string {
+	if !s.Visible() || width <= 0 {
+		return ""
+	}
+	if !s.Active {
+		return fitBarLine(style.MutedText(s.Notice), width)
+	}
+	return fitBarLine(style.AccentText("SELECT")+style.MutedText("  y copy · j/k move · esc cancel"), width)
+}
+
+// blockEnd returns the timeline index just past the block starting at i.
+// Streamed text arrives as many entries; a run of them is one block.
+func blockEnd(entries []timeline.Entry, i int) int {
+	end := i + 1
+	if entries[i].Kind == "stream" {
+		for end < len(entries) && entries[end].Kind == "stream" {
+			end++
+		}
+	}
+	return end
+}
+
+// blockStart returns the timeline index of the first entry of the block
+// containing entry i.
+func blockStart(entries []timeline.Entry, i int) int {
+	for i > 0 && entries[i].Kind == "stream" && entries[i-1].Kind == "stream" {
+		i--
+	}
+	return i
+}
+
+// topEntry returns the timeline index of the entry at the top of the
+// viewport, or -1 when the timeline is empty.
+func (m Model) topEntry() int {
+	top := -1
+	for i, start := range m.entryStarts {
+		if start > m.viewport.YOffset() {
+			break
+		}
+		top = i
+	}
+	return top
+}
+
+// enterSelection turns on selection mode, focusing the block at the top of
+// the viewport.
+func (m *Model) enterSelection() {
+	top := m.topEntry()
+	if top < 0 {
+		return
+	}
+	m.selection.Active = true
+	m.selection.Index = blockStart(m.timeline, top)
+	m.followMode = false
+	m.rebuildRenderedContent()
+	m.updateViewportDimensions()
+}
+
+// exitSelection turns off selection mode.
+func (m *Model) exitSelection() {
+	m.selection.Active = false
+	m.rebuildRenderedContent()
+	m.updateViewportDimensions()
+}
+
+// moveSelection focuses the next (dir > 0) or previous (dir < 0) block with
+// text and scrolls it into view.
+func (m *Model) moveSelection(dir int) {
+	i := m.selection.Index
+	for {
+		if dir > 0 {
+			i = blockEnd(m.timeline, i)
+		} else {
+			if i == 0 {
+				return
+			}
+			i = blockStart(m.timeline, i-1)
+		}
+		if i < 0 || i >= len(m.timeline) {
+			return
+		}
+		if strings.TrimSpace(ansi.Strip(m.blockText(i))) != "" {
+			break
+		}
+	}
+	m.selection.Index = i
+	m.rebuildRenderedContent()
+	m.setViewportContent(m.content.String())
+	if start := m.entryStarts[i]; start < m.viewport.YOffset() || start >= m.viewport.YOffset()+m.viewport.Height() {
+		m.viewport.SetYOffset(start)
+	}
+}
+
+// blockText returns the rendered text of the block starting at timeline
+// index i.
+func (m *Model) blockText(i int) string {
+	var sb strings.Builder
+	for j := i; j < blockEnd(m.timeline, i); j++ {
+		sb.WriteString(m.entryText(j, m.timeline[j]))
+	}
+	return sb.String()
+}
+
+// selectedText returns the text to copy for the focused block: the command
+// of a Bash call, otherwise the block as shown, without styling.
+func (m *Model) selectedText() string {
+	entry := m.timeline[m.selection.Index]
+	if entry.Kind == "user" && entry.Title == "Bash" && entry.Arg != "" {
+		return entry.Arg
+	}
+	return strings.TrimRight(ansi.Strip(m.blockText(m.selection.Index)), "\n")
+}
+
+// copySelection copies the focused block and leaves selection mode.
+func (m *Model) copySelection() tea.Cmd {
+	text := m.selectedText()
+	m.exitSelection()
+	return CopyToClipboard(text)
+}
+
+// markSelected prefixes each line of the focused block's text with the
+// selection marker. midLine reports whether the text continues a line
+// begun by the previous entry; the returned value is the same for the
+// next entry.
+func markSelected(text string, midLine bool) (string, bool) {
+	marker := style.AccentText(selectionMarker)
+	var sb strings.Builder
+	for len(text) > 0 {
+		if !midLine {
+			sb.WriteString(marker)
+		}
+		line, rest, found := strings.Cut(text, "\n")
+		sb.WriteString(line)
+		if found {
+			sb.WriteByte('\n')
+		}
+		midLine = !found
+		text = rest
+	}
+	return sb.String(), midLine
+}
+
+// CopyToClipboard copies text to the system clipboard with OSC 52, and saves
+// it to a temporary file for terminals that do not support OSC 52. A
+// ClipboardCopiedMsg reports the file.
+func CopyToClipboard(text string) tea.Cmd {
+	return tea.Batch(tea.SetClipboard(text), func() tea.Msg {
+		f, err := os.CreateTemp("", "viewscreen-copy-*.txt")
+		if err != nil {
+			return ClipboardCopiedMsg{Lines: lineCount(text), Err: err}
+		}
+		_, err = f.WriteString(text)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return ClipboardCopiedMsg{Path: f.Name(), Lines: lineCount(text), Err: err}
+	})
+}
+
+func lineCount(text string) int {
+	if text == "" {
+		return 0
+	}
+	return strings.Count(text, "\n") + 1
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/timeline"
+)
+
+func newSelectionTestModel() Model {
+	m := newTestModel()
+	m.timeline = []timeline.Entry{
+		{Kind: "assistant", Body: "first message\n"},
+		{Kind: "user", Title: "Bash", Arg: "go test ./...", Body: "● Bash go test ./...\n  ⎿  ok\n"},
+		{Kind: "stream", Body: "streamed "},
+		{Kind: "stream", Body: "text\n"},
+	}
+	m.rebuildRenderedContent()
+	m.setViewportContent(m.content.String())
+	m.viewport.GotoTop()
+	return m
+}
+
+func TestHandleKeyMsg_SelectionMode(t *testing.T) {
+	m := newSelectionTestModel()
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "y"})
+	if !m.selection.Active || m.selection.Index != 0 {
+		t.Fatalf("expected y to focus the top block, got %+v", m.selection)
+	}
+	if !strings.Contains(m.content.String(), selectionMarker+"first message") {
+		t.Errorf("expected the focused block marked, got %q", m.content.String())
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "j"})
+	if m.selection.Index != 1 {
+		t.Errorf("after j, Index = %d, want 1", m.selection.Index)
+	}
+	if got := m.selectedText(); got != "go test ./..." {
+		t.Errorf("expected the Bash command to be copied, got %q", got)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "j"})
+	if m.selection.Index != 2 {
+		t.Errorf("after second j, Index = %d, want 2", m.selection.Index)
+	}
+	if got := m.selectedText(); got != "streamed text" {
+		t.Errorf("expected the streamed run copied as one block, got %q", got)
+	}
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "j"})
+	if m.selection.Index != 2 {
+		t.Errorf("expected j to stop at the last block, Index = %d", m.selection.Index)
+	}
+
+	m, cmd := m.handleKeyMsg(tea.KeyPressMsg{Text: "y"})
+	if cmd == nil {
+		t.Error("expected y to copy the focused block")
+	}
+	if m.selection.Active || strings.Contains(m.content.String(), selectionMarker) {
+		t.Error("expected copying to leave selection mode")
+	}
+}
+
+func TestHandleKeyMsg_SelectionEscCancels(t *testing.T) {
+	m := newSelectionTestModel()
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "y"})
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.selection.Active {
+		t.Error("expected esc to leave selection mode")
+	}
+}
+
+func TestHandleClipboardCopied(t *testing.T) {
+	m := newSelectionTestModel()
+	m.updateViewportDimensions()
+	height := m.viewport.Height()
+
+	m = m.handleClipboardCopied(ClipboardCopiedMsg{Path: "/tmp/copy.txt", Lines: 2})
+	if !strings.Contains(RenderSelectionBar(m.selection, 80), "Copied 2 lines") {
+		t.Errorf("expected the copy reported, got %q", m.selection.Notice)
+	}
+	if m.viewport.Height() != height-1 {
+		t.Errorf("expected the bar to take a row, height = %d, want %d", m.viewport.Height(), height-1)
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "j"})
+	if m.selection.Notice != "" {
+		t.Error("expected the next key to clear the notice")
+	}
+
+	m = m.handleClipboardCopied(ClipboardCopiedMsg{Lines: 1, Err: errors.New("disk full")})
+	if !strings.Contains(m.selection.Notice, "disk full") {
+		t.Errorf("expected the save error reported, got %q", m.selection.Notice)
+	}
+}
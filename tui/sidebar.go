@@ -14,9 +14,13 @@ import (
 	"charm.land/bubbles/v2/spinner"
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/ctxwindow"
+	"github.com/johnnyfreeman/viewscreen/numfmt"
+	"github.com/johnnyfreeman/viewscreen/perf"
 	"github.com/johnnyfreeman/viewscreen/state"
 	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/textutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
 )
 
 const (
@@ -119,10 +123,10 @@ func (r *SidebarRenderer) RenderSessionInfo(s *state.State) string {
 		sb.WriteString(r.RenderLabelValue("Model", modelName))
 	}
 
-	sb.WriteString(r.RenderLabelValue("Turns", fmt.Sprintf("%d", s.TurnCount)))
+	sb.WriteString(r.RenderLabelValue("Turns", numfmt.Default().Int(s.TurnCount)))
 
 	if s.ReportsCost() {
-		sb.WriteString(r.RenderLabelValue("Cost", fmt.Sprintf("$%.4f", s.TotalCost)))
+		sb.WriteString(r.RenderLabelValue("Cost", numfmt.Default().Cost(s.TotalCost, 4)))
 	}
 
 	return sb.String()
@@ -161,16 +165,32 @@ func (r *SidebarRenderer) RenderCacheUsage(cacheRead, cacheCreated int) string {
 	return r.RenderLabelValue("Cache", value)
 }
 
-// formatTokenCount formats a token count compactly (e.g., 1234 -> "1.2k", 1234567 -> "1.2M").
-func formatTokenCount(n int) string {
-	switch {
-	case n >= 1_000_000:
-		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
-	case n >= 1_000:
-		return fmt.Sprintf("%.1fk", float64(n)/1_000)
-	default:
-		return fmt.Sprintf("%d", n)
+// RenderContextUsage renders how full the context window is, in the warning
+// or error color past the ctxwindow thresholds. It returns an empty string
+// until the window is known (see state.ContextUsage).
+func (r *SidebarRenderer) RenderContextUsage(used, window int) string {
+	if window == 0 {
+		return ""
+	}
+	return style.SidebarHeaderText("Context") + "\n" +
+		contextText(used, window, ctxwindow.Format(used, window)) + "\n\n"
+}
+
+// contextText styles text describing context usage by its level.
+func contextText(used, window int, text string) string {
+	switch ctxwindow.Default().Level(ctxwindow.Percent(used, window)) {
+	case ctxwindow.Critical:
+		return style.ErrorText(text)
+	case ctxwindow.Warning:
+		return style.WarningText(text)
 	}
+	return style.SidebarValueText(text)
+}
+
+// formatTokenCount formats a token count compactly (e.g., 1234 -> "1.2k", 1234567 -> "1.2M"),
+// or in full when -spell-out is set.
+func formatTokenCount(n int) string {
+	return numfmt.Default().Tokens(n)
 }
 
 // RenderElapsed renders the session elapsed time.
@@ -187,15 +207,16 @@ func (r *SidebarRenderer) RenderCostRate(costRate float64) string {
 }
 
 // formatCostRate formats a cost-per-minute value as a compact string.
-// Uses different precision based on the magnitude.
+// Uses different precision based on the magnitude in the display currency.
 func formatCostRate(rate float64) string {
-	switch {
-	case rate >= 1.0:
-		return fmt.Sprintf("$%.2f/min", rate)
-	case rate >= 0.01:
-		return fmt.Sprintf("$%.3f/min", rate)
+	f := numfmt.Default()
+	switch converted := f.Convert(rate); {
+	case converted >= 1.0:
+		return f.Cost(rate, 2) + "/min"
+	case converted >= 0.01:
+		return f.Cost(rate, 3) + "/min"
 	default:
-		return fmt.Sprintf("$%.4f/min", rate)
+		return f.Cost(rate, 4) + "/min"
 	}
 }
 
@@ -217,6 +238,15 @@ func formatDuration(d time.Duration) string {
 	}
 }
 
+// minSidebarPathWidth is the narrowest space worth showing a shortened path
+// in.
+const minSidebarPathWidth = 12
+
+// isPath reports whether a tool argument looks like a file path.
+func isPath(s string) bool {
+	return strings.Contains(s, "/") && !strings.ContainsAny(s, " \t\n")
+}
+
 // RenderCurrentTool renders the currently running tool with spinner.
 func (r *SidebarRenderer) RenderCurrentTool(toolName, toolInput string) string {
 	if toolName == "" {
@@ -230,16 +260,51 @@ func (r *SidebarRenderer) RenderCurrentTool(toolName, toolInput string) string {
 	toolText := toolName
 	if toolInput != "" && len(toolInput) < 20 {
 		toolText += " " + toolInput
+	} else if isPath(toolInput) {
+		// Long paths lose directories from the middle to fit the sidebar
+		if room := r.width - 6 - len(toolName) - 1; room >= minSidebarPathWidth {
+			toolText += " " + textutil.ShortenPath(toolInput, room)
+		}
+	}
+
+	// A shortened path already fits; its "…" is one cell but three bytes.
+	if ansi.StringWidth(toolText) > r.width-6 {
+		toolText = textutil.Truncate(toolText, r.width-6)
 	}
 
 	sb.WriteString(r.spinner.View())
 	sb.WriteString(" ")
-	sb.WriteString(style.SidebarTodoActiveText(textutil.Truncate(toolText, r.width-6)))
+	sb.WriteString(style.SidebarTodoActiveText(toolText))
 	sb.WriteString("\n\n")
 
 	return sb.String()
 }
 
+// RenderLanes renders the lane of each tool call running alongside others,
+// the rule that marks its results in the timeline.
+func (r *SidebarRenderer) RenderLanes(lanes []tools.Lane) string {
+	if len(lanes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(style.SidebarHeaderText("Lanes"))
+	sb.WriteString("\n")
+	for _, lane := range lanes {
+		text := tools.DisplayName(lane.Name)
+		if lane.Input != "" {
+			text += " " + lane.Input
+		}
+		text = textutil.Truncate(text, r.width-6)
+		sb.WriteString(style.LaneRule(lane.Index))
+		sb.WriteString(style.SidebarValueText(text))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
 // RenderTodo delegates to the TodoRenderer.
 func (r *SidebarRenderer) RenderTodo(todo state.Todo) string {
 	return r.todo.RenderItem(todo)
@@ -280,6 +345,8 @@ type ScrollPosition struct {
 	AtTop    bool
 	AtBottom bool
 	Percent  float64 // 0.0 to 1.0
+	// FocusedID is the event UUID of the block at the top of the viewport.
+	FocusedID string
 }
 
 // FormatScrollPosition returns a compact scroll position string.
@@ -306,6 +373,66 @@ func (r *SidebarRenderer) RenderScrollPosition(pos ScrollPosition) string {
 	return r.RenderLabelValue("Position", FormatScrollPosition(pos))
 }
 
+// RenderFocusedEvent renders the event UUID of the focused block so it can be
+// cross-referenced with exports. Long IDs wrap to the sidebar width.
+func (r *SidebarRenderer) RenderFocusedEvent(id string) string {
+	if id == "" {
+		return ""
+	}
+	width := max(r.width-4, 8)
+	var lines []string
+	for len(id) > width {
+		lines = append(lines, id[:width])
+		id = id[width:]
+	}
+	lines = append(lines, id)
+	return r.RenderLabelValue("Event", strings.Join(lines, "\n"))
+}
+
+// RenderTimings renders the p50/p95 render time of each event kind seen so
+// far, one kind per line. It returns an empty string before any event has
+// been rendered.
+func (r *SidebarRenderer) RenderTimings(timings *perf.Timings) string {
+	if timings == nil {
+		return ""
+	}
+	stats := timings.Stats()
+	if len(stats) == 0 {
+		return ""
+	}
+	lines := make([]string, len(stats))
+	for i, s := range stats {
+		lines[i] = fmt.Sprintf("%-12s %8s %8s", s.Kind, perf.FormatDuration(s.P50), perf.FormatDuration(s.P95))
+	}
+	return r.RenderLabelValue("Render p50 / p95", strings.Join(lines, "\n"))
+}
+
+// RenderToolStats renders the session statistics of the details modal: each
+// tool's finished calls with their total and average time, longest total
+// first, then the average tokens per turn and the number of files changed.
+// Sections with nothing to report yet are left out.
+func (r *SidebarRenderer) RenderToolStats(s *state.State) string {
+	var sb strings.Builder
+	if s.ToolTimes != nil {
+		if totals := s.ToolTimes.Totals(); len(totals) > 0 {
+			lines := make([]string, len(totals))
+			for i, t := range totals {
+				avg := t.Total / time.Duration(t.Count)
+				lines[i] = fmt.Sprintf("%-12s %3d× %6s %6s", textutil.Truncate(t.Name, 12), t.Count,
+					tools.FormatElapsed(t.Total), tools.FormatElapsed(avg))
+			}
+			sb.WriteString(r.RenderLabelValue("Tool calls / total / avg", strings.Join(lines, "\n")))
+		}
+	}
+	if perTurn := s.TokensPerTurn(); perTurn > 0 {
+		sb.WriteString(r.RenderLabelValue("Tokens per turn", formatTokenCount(perTurn)))
+	}
+	if s.Stats != nil && s.Stats.FilesChanged() > 0 {
+		sb.WriteString(r.RenderLabelValue("Files touched", numfmt.Default().Int(s.Stats.FilesChanged())))
+	}
+	return sb.String()
+}
+
 // Render renders the complete sidebar by composing all sections.
 func (r *SidebarRenderer) Render(s *state.State, height int, followMode bool, scrollPos ScrollPosition, stdinDone bool, autoExitRemaining int, streamErrOpt ...error) string {
 	var sb strings.Builder
@@ -320,13 +447,18 @@ func (r *SidebarRenderer) Render(s *state.State, height int, followMode bool, sc
 	sb.WriteString(r.RenderCostRate(s.CostRate()))
 	sb.WriteString(r.RenderElapsed(s.Elapsed()))
 	sb.WriteString(r.RenderScrollPosition(scrollPos))
+	sb.WriteString(r.RenderFocusedEvent(scrollPos.FocusedID))
 	sb.WriteString(r.RenderTokenUsage(s.InputTokens, s.OutputTokens))
 	sb.WriteString(r.RenderReasoningTokens(s.ReasoningTokens))
 	sb.WriteString(r.RenderCacheUsage(s.CacheRead, s.CacheCreated))
+	sb.WriteString(r.RenderContextUsage(s.ContextUsage()))
 
 	if s.ToolInProgress {
 		sb.WriteString(r.RenderCurrentTool(s.CurrentTool, s.CurrentToolInput))
 	}
+	if s.Lanes != nil {
+		sb.WriteString(r.RenderLanes(s.Lanes.Active()))
+	}
 
 	sb.WriteString(r.RenderTodos(s.Todos))
 
@@ -359,7 +491,7 @@ func NewHeaderStyles() HeaderStyles {
 }
 
 // RenderHeader renders a single-line header for narrow terminals.
-// Format: ─── VIEWSCREEN claude ─── model │ 5 │ $0.12 │ 42% ─── [?] ───
+// Format: ─── VIEWSCREEN claude ─── model │ 5 │ $0.12 │ ctx 62% │ 42% ─── [?] ───
 //
 // The header adapts to the active agent: the model segment is dropped until a
 // model is known, and the cost segment is dropped for agents that do not report
@@ -372,7 +504,7 @@ func RenderHeader(s *state.State, width int, followMode bool, scrollPos ScrollPo
 	scrollStr := FormatScrollPosition(scrollPos)
 
 	// Build the info section from the segments the active agent reports:
-	// [model] │ turns │ [cost] │ elapsed │ scroll
+	// [model] │ turns │ [cost] │ [context] │ elapsed │ scroll
 	var modelLabel string
 	if s.Model != "" {
 		modelLabel = s.Model
@@ -386,9 +518,12 @@ func RenderHeader(s *state.State, width int, followMode bool, scrollPos ScrollPo
 	if modelLabel != "" {
 		segments = append(segments, modelLabel)
 	}
-	segments = append(segments, fmt.Sprintf("%d", s.TurnCount))
+	segments = append(segments, numfmt.Default().Int(s.TurnCount))
 	if s.ReportsCost() {
-		segments = append(segments, fmt.Sprintf("$%.2f", s.TotalCost))
+		segments = append(segments, numfmt.Default().Cost(s.TotalCost, 2))
+	}
+	if used, window := s.ContextUsage(); window > 0 {
+		segments = append(segments, contextText(used, window, fmt.Sprintf("ctx %d%%", ctxwindow.Percent(used, window))))
 	}
 	segments = append(segments, elapsed, scrollStr)
 	info := strings.Join(segments, " "+style.MutedText("│")+" ")
@@ -500,15 +635,16 @@ func RenderHelpModal(width, height int, styles HeaderStyles, autoExitActive bool
 	if len(canEditPromptOpt) > 0 {
 		canEditPrompt = canEditPromptOpt[0]
 	}
-	return renderHelpModal(width, height, styles, autoExitActive, true, canEditPrompt)
+	return renderHelpModal(width, height, styles, autoExitActive, true, canEditPrompt, false)
 }
 
 // RenderContextualHelpModal renders help for the active layout mode.
-func RenderContextualHelpModal(width, height int, styles HeaderStyles, autoExitActive bool, layoutMode LayoutMode, canEditPrompt bool) string {
-	return renderHelpModal(width, height, styles, autoExitActive, layoutMode == LayoutHeader, canEditPrompt)
+// When stepMode is true, it includes the replay step mode bindings.
+func RenderContextualHelpModal(width, height int, styles HeaderStyles, autoExitActive bool, layoutMode LayoutMode, canEditPrompt bool, stepMode bool) string {
+	return renderHelpModal(width, height, styles, autoExitActive, layoutMode == LayoutHeader, canEditPrompt, stepMode)
 }
 
-func renderHelpModal(width, height int, styles HeaderStyles, autoExitActive bool, showDetailsBinding bool, canEditPrompt bool) string {
+func renderHelpModal(width, height int, styles HeaderStyles, autoExitActive bool, showDetailsBinding bool, canEditPrompt bool, stepMode bool) string {
 	var sb strings.Builder
 
 	// Title
@@ -528,15 +664,35 @@ func renderHelpModal(width, height int, styles HeaderStyles, autoExitActive bool
 		{"G / End", "Go to bottom"},
 		{"/", "Search"},
 		{"n / N", "Next / prev match"},
+		{"e / E", "Next / prev error"},
 		{"f", "Toggle follow mode"},
+		{"s", "Toggle status column"},
+		{"O", "Toggle tool output pane"},
+		{"v / enter / za", "Toggle block verbosity"},
+		{"Z", "Expand all output"},
+		{"y", "Select block to copy"},
+		{"w / :w <file>", "Save session (.jsonl/.txt/.md/.html)"},
+		{"V", "View in $PAGER"},
+		{"o", "Open in $EDITOR"},
+	}
+	if stepMode {
+		// g jumps to an event instead of the top in step mode
+		bindings[4].key = "Home"
+		bindings = append(bindings,
+			struct{ key, desc string }{"space", "Step one event"},
+			struct{ key, desc string }{"t", "Step one turn"},
+			struct{ key, desc string }{"g", "Jump to event"},
+			struct{ key, desc string }{"r", "Toggle raw JSON"},
+		)
 	}
 	if showDetailsBinding {
 		bindings = append(bindings, struct{ key, desc string }{"d", "Toggle details"})
 	}
 	if canEditPrompt {
-		bindings = append(bindings, struct{ key, desc string }{"e", "Edit prompt & re-run"})
+		bindings = append(bindings, struct{ key, desc string }{"p", "Edit prompt & re-run"})
 	}
 	bindings = append(bindings,
+		struct{ key, desc string }{"ctrl+z", "Suspend"},
 		struct{ key, desc string }{"?", "Toggle help"},
 		struct{ key, desc string }{"q", "Quit"},
 	)
@@ -610,14 +766,21 @@ func RenderDetailsModal(s *state.State, sp spinner.Model, width, height int, sty
 	sb.WriteString(r.RenderCostRate(s.CostRate()))
 	sb.WriteString(r.RenderElapsed(s.Elapsed()))
 	sb.WriteString(r.RenderScrollPosition(scrollPos))
+	sb.WriteString(r.RenderFocusedEvent(scrollPos.FocusedID))
 	sb.WriteString(r.RenderTokenUsage(s.InputTokens, s.OutputTokens))
 	sb.WriteString(r.RenderReasoningTokens(s.ReasoningTokens))
 	sb.WriteString(r.RenderCacheUsage(s.CacheRead, s.CacheCreated))
+	sb.WriteString(r.RenderContextUsage(s.ContextUsage()))
+	sb.WriteString(r.RenderToolStats(s))
+	sb.WriteString(r.RenderTimings(s.RenderTimings))
 
 	// Current tool
 	if s.ToolInProgress {
 		sb.WriteString(r.RenderCurrentTool(s.CurrentTool, s.CurrentToolInput))
 	}
+	if s.Lanes != nil {
+		sb.WriteString(r.RenderLanes(s.Lanes.Active()))
+	}
 
 	// Todos
 	sb.WriteString(r.RenderTodos(s.Todos))
@@ -10,8 +10,11 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/perf"
+	"github.com/johnnyfreeman/viewscreen/result"
 	"github.com/johnnyfreeman/viewscreen/state"
 	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/tools"
 )
 
 func init() {
@@ -228,6 +231,14 @@ func TestSidebarRenderer_RenderCurrentTool(t *testing.T) {
 		}
 	})
 
+	t.Run("shortens long paths", func(t *testing.T) {
+		output := r.RenderCurrentTool("Read", "/home/u/projects/viewscreen/internal/parser/parser.go")
+
+		if !strings.Contains(output, "/…/") || !strings.Contains(output, "/parser.go") {
+			t.Errorf("expected the path shortened around its basename, got %q", output)
+		}
+	})
+
 	t.Run("excludes long input", func(t *testing.T) {
 		longInput := strings.Repeat("x", 30)
 		output := r.RenderCurrentTool("Read", longInput)
@@ -242,6 +253,25 @@ func TestSidebarRenderer_RenderCurrentTool(t *testing.T) {
 	})
 }
 
+func TestSidebarRenderer_RenderLanes(t *testing.T) {
+	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
+
+	if output := r.RenderLanes(nil); output != "" {
+		t.Errorf("expected nothing without concurrent calls, got %q", output)
+	}
+
+	output := ansi.Strip(r.RenderLanes([]tools.Lane{
+		{ID: "a", Name: "Bash", Input: "make", Index: 0},
+		{ID: "b", Name: "Read", Input: "main.go", Index: 1},
+	}))
+	if !strings.Contains(output, "Lanes") {
+		t.Error("expected 'Lanes' header in output")
+	}
+	if !strings.Contains(output, "Bash make") || !strings.Contains(output, "Read main.go") {
+		t.Errorf("expected each lane's tool and input, got %q", output)
+	}
+}
+
 func TestSidebarRenderer_RenderTodo(t *testing.T) {
 	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
 
@@ -659,6 +689,43 @@ func TestRenderDetailsModal(t *testing.T) {
 	}
 }
 
+func TestSidebarRenderer_RenderToolStats(t *testing.T) {
+	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
+
+	t.Run("empty before any tool finishes", func(t *testing.T) {
+		if got := r.RenderToolStats(state.NewState()); got != "" {
+			t.Errorf("expected no stats, got %q", got)
+		}
+	})
+
+	t.Run("per-tool calls, tokens per turn and files", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		timer := tools.NewToolTimer(func() time.Time { return now })
+		timer.Start("1", "Bash")
+		now = now.Add(3 * time.Second)
+		timer.Finish("1")
+		timer.Start("2", "Bash")
+		now = now.Add(time.Second)
+		timer.Finish("2")
+		stats := result.NewSessionStats()
+		stats.FileChanged("a.go")
+		stats.FileChanged("b.go")
+
+		s := state.NewState()
+		s.ToolTimes = timer
+		s.Stats = stats
+		s.TurnCount = 4
+		s.InputTokens, s.OutputTokens = 3000, 1000
+
+		output := r.RenderToolStats(s)
+		for _, want := range []string{"Bash", "2×", "4.0s", "2.0s", "Tokens per turn", "1.0k", "Files touched", "2"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("expected %q in stats, got:\n%s", want, output)
+			}
+		}
+	})
+}
+
 func TestRenderDetailsModalFitsNarrowTerminal(t *testing.T) {
 	s := state.NewState()
 	s.Model = "claude-opus"
@@ -728,7 +795,7 @@ func TestRenderHelpModal(t *testing.T) {
 	})
 
 	t.Run("contextual sidebar mode omits inactive details key", func(t *testing.T) {
-		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, false)
+		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, false, false)
 
 		if strings.Contains(output, "Toggle details") {
 			t.Error("expected sidebar help to omit details binding")
@@ -736,7 +803,7 @@ func TestRenderHelpModal(t *testing.T) {
 	})
 
 	t.Run("omits prompt editing when re-run is unavailable", func(t *testing.T) {
-		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, false)
+		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, false, false)
 
 		if strings.Contains(output, "Edit prompt") {
 			t.Error("expected help to omit inert prompt editing binding")
@@ -744,15 +811,26 @@ func TestRenderHelpModal(t *testing.T) {
 	})
 
 	t.Run("includes prompt editing when re-run is available", func(t *testing.T) {
-		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, true)
+		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, true, false)
 
 		if !strings.Contains(output, "Edit prompt & re-run") {
 			t.Error("expected help to include prompt edit/re-run binding")
 		}
 	})
 
+	t.Run("includes step bindings in step mode", func(t *testing.T) {
+		output := RenderContextualHelpModal(100, 40, styles, false, LayoutSidebar, false, true)
+
+		if !strings.Contains(output, "Step one turn") || !strings.Contains(output, "Jump to event") {
+			t.Error("expected help to include step mode bindings")
+		}
+		if strings.Contains(output, "g / Home") {
+			t.Error("expected g to be rebound away from go to top in step mode")
+		}
+	})
+
 	t.Run("contextual header mode includes details key", func(t *testing.T) {
-		output := RenderContextualHelpModal(100, 40, styles, false, LayoutHeader, false)
+		output := RenderContextualHelpModal(100, 40, styles, false, LayoutHeader, false, false)
 
 		if !strings.Contains(output, "Toggle details") {
 			t.Error("expected header help to include details binding")
@@ -760,7 +838,7 @@ func TestRenderHelpModal(t *testing.T) {
 	})
 
 	t.Run("auto-exit bindings match countdown key behavior", func(t *testing.T) {
-		output := RenderContextualHelpModal(100, 40, styles, true, LayoutSidebar, false)
+		output := RenderContextualHelpModal(100, 40, styles, true, LayoutSidebar, false, false)
 
 		if !strings.Contains(output, "Exit now") {
 			t.Error("expected auto-exit help to explain space exits immediately")
@@ -773,7 +851,7 @@ func TestRenderHelpModal(t *testing.T) {
 	t.Run("fits narrow terminal width", func(t *testing.T) {
 		width := 30
 
-		output := RenderContextualHelpModal(width, 40, styles, true, LayoutHeader, true)
+		output := RenderContextualHelpModal(width, 40, styles, true, LayoutHeader, true, false)
 
 		if got := maxRenderedLineWidth(output); got > width {
 			t.Fatalf("help modal line width = %d, want <= %d; output=%q", got, width, output)
@@ -1061,6 +1139,54 @@ func TestSidebarRenderer_RenderTokenUsage(t *testing.T) {
 	})
 }
 
+func TestSidebarRenderer_RenderContextUsage(t *testing.T) {
+	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
+
+	t.Run("unknown window returns empty string", func(t *testing.T) {
+		if output := r.RenderContextUsage(0, 0); output != "" {
+			t.Errorf("expected empty string without a window, got %q", output)
+		}
+	})
+
+	t.Run("renders percentage of window", func(t *testing.T) {
+		output := ansi.Strip(r.RenderContextUsage(124_000, 200_000))
+		if !strings.Contains(output, "Context") || !strings.Contains(output, "62% of 200.0k") {
+			t.Errorf("expected the context gauge, got %q", output)
+		}
+	})
+
+	t.Run("colors by threshold", func(t *testing.T) {
+		normal := r.RenderContextUsage(20_000, 200_000)
+		warning := r.RenderContextUsage(150_000, 200_000)
+		critical := r.RenderContextUsage(190_000, 200_000)
+		value := func(s string) string { return strings.Split(s, "\n")[1] }
+		if !strings.Contains(value(warning), style.WarningText("75% of 200.0k")) {
+			t.Errorf("expected the warning color at 75%%, got %q", warning)
+		}
+		if !strings.Contains(value(critical), style.ErrorText("95% of 200.0k")) {
+			t.Errorf("expected the error color at 95%%, got %q", critical)
+		}
+		if value(normal) != style.SidebarValueText("10% of 200.0k") {
+			t.Errorf("expected the plain value color at 10%%, got %q", normal)
+		}
+	})
+}
+
+func TestRenderHeader_ContextUsage(t *testing.T) {
+	s := state.NewState()
+	s.ContextTokens = 124_000
+	plain := ansi.Strip(RenderHeader(s, 120, true, ScrollPosition{AtTop: true}, false, 0))
+	if !strings.Contains(plain, "ctx 62%") {
+		t.Errorf("expected the context segment in header, got %q", plain)
+	}
+
+	s.Agent = config.AgentCodex
+	plain = ansi.Strip(RenderHeader(s, 120, true, ScrollPosition{AtTop: true}, false, 0))
+	if strings.Contains(plain, "ctx") {
+		t.Errorf("expected no context segment for codex, got %q", plain)
+	}
+}
+
 func TestSidebarRenderer_RenderReasoningTokens(t *testing.T) {
 	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
 
@@ -1548,6 +1674,64 @@ func TestSidebarRenderer_RenderScrollPosition(t *testing.T) {
 	})
 }
 
+func TestSidebarRenderer_RenderFocusedEvent(t *testing.T) {
+	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
+
+	t.Run("empty when no focused event", func(t *testing.T) {
+		if output := r.RenderFocusedEvent(""); output != "" {
+			t.Errorf("expected empty output, got %q", output)
+		}
+	})
+
+	t.Run("wraps long IDs to sidebar width", func(t *testing.T) {
+		id := "0b6d3a52-1c9e-4f0a-9d2b-7e5f8c1a2b3c"
+		output := ansi.Strip(r.RenderFocusedEvent(id))
+
+		if !strings.Contains(output, "Event") {
+			t.Error("expected 'Event' label in output")
+		}
+		if got := strings.ReplaceAll(strings.TrimPrefix(output, "Event\n"), "\n", ""); got != id {
+			t.Errorf("expected wrapped ID to rejoin to %q, got %q", id, got)
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if len(line) > sidebarWidth-4 {
+				t.Errorf("line %q exceeds sidebar width", line)
+			}
+		}
+	})
+}
+
+func TestSidebarRenderer_RenderTimings(t *testing.T) {
+	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
+
+	t.Run("empty without samples", func(t *testing.T) {
+		if output := r.RenderTimings(nil); output != "" {
+			t.Errorf("expected empty output for nil timings, got %q", output)
+		}
+		if output := r.RenderTimings(perf.NewTimings()); output != "" {
+			t.Errorf("expected empty output for no samples, got %q", output)
+		}
+	})
+
+	t.Run("one line per event kind", func(t *testing.T) {
+		timings := perf.NewTimings()
+		timings.Record("user", 2*time.Millisecond)
+		timings.Record("assistant", 400*time.Microsecond)
+
+		output := ansi.Strip(r.RenderTimings(timings))
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if len(lines) != 3 || lines[0] != "Render p50 / p95" {
+			t.Fatalf("unexpected output %q", output)
+		}
+		if !strings.HasPrefix(lines[1], "assistant") || !strings.Contains(lines[1], "400µs") {
+			t.Errorf("assistant line = %q", lines[1])
+		}
+		if !strings.HasPrefix(lines[2], "user") || !strings.Contains(lines[2], "2ms") {
+			t.Errorf("user line = %q", lines[2])
+		}
+	})
+}
+
 func TestSidebarRenderer_Render_WithScrollPosition(t *testing.T) {
 	r := NewSidebarRenderer(NewSidebarStyles(), newTestSpinner())
 
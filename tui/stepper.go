@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// Stepper holds the state of replay step mode, a debugger for rendering
+// issues: input lines are buffered as they arrive and only applied to the
+// session one event, one turn or one jump at a time.
+type Stepper struct {
+	Enabled   bool   // Whether step mode is on
+	ShowRaw   bool   // Whether the raw JSON panel is visible
+	Jumping   bool   // Whether the jump-to-event input is active
+	JumpInput string // Event number typed so far
+
+	lines     []string // every line read so far
+	applied   int      // number of lines applied to the session
+	seen      int      // most lines ever applied, across rewinds
+	replaying bool     // whether the last line from Next was applied before
+	target    int      // apply lines until this many are applied
+	turnFrom  int      // turn count to advance past, or -1
+}
+
+// NewStepper creates a Stepper; it is inert unless enabled.
+func NewStepper(enabled bool) Stepper {
+	return Stepper{Enabled: enabled, turnFrom: -1}
+}
+
+// Buffer queues a line read from the input.
+func (s *Stepper) Buffer(line string) {
+	s.lines = append(s.lines, line)
+}
+
+// Applied returns the number of events applied so far; it is also the
+// 1-based index of the current event.
+func (s Stepper) Applied() int {
+	return s.applied
+}
+
+// Total returns the number of events read so far.
+func (s Stepper) Total() int {
+	return len(s.lines)
+}
+
+// Current returns the raw line of the most recently applied event.
+func (s Stepper) Current() string {
+	if s.applied == 0 {
+		return ""
+	}
+	return s.lines[s.applied-1]
+}
+
+// StepEvent requests that one more event be applied.
+func (s *Stepper) StepEvent() {
+	s.turnFrom = -1
+	s.target = s.applied + 1
+}
+
+// StepTurn requests that events be applied until the turn count moves past
+// turn.
+func (s *Stepper) StepTurn(turn int) {
+	s.turnFrom = turn
+}
+
+// JumpTo requests that exactly n events be applied. It reports whether that
+// means rewinding, in which case the session must be reset and Rewind called
+// before applying events again.
+func (s *Stepper) JumpTo(n int) bool {
+	s.turnFrom = -1
+	s.target = max(n, 0)
+	return s.target < s.applied
+}
+
+// Rewind marks every buffered line as unapplied.
+func (s *Stepper) Rewind() {
+	s.applied = 0
+}
+
+// Next returns the next line to apply, if the pending request still needs
+// one. turn is the session's current turn count.
+func (s *Stepper) Next(turn int) (string, bool) {
+	if s.applied >= len(s.lines) {
+		return "", false
+	}
+	if s.turnFrom >= 0 {
+		if turn != s.turnFrom {
+			s.turnFrom = -1
+			s.target = s.applied
+			return "", false
+		}
+	} else if s.applied >= s.target {
+		return "", false
+	}
+	line := s.lines[s.applied]
+	s.replaying = s.applied < s.seen
+	s.applied++
+	s.seen = max(s.seen, s.applied)
+	return line, true
+}
+
+// Replaying reports whether the line last returned by Next had already been
+// applied before a rewind, so outputs that saw it once should skip it.
+func (s Stepper) Replaying() bool {
+	return s.replaying
+}
+
+// EnterJump activates the jump-to-event input.
+func (s *Stepper) EnterJump() {
+	s.Jumping = true
+	s.JumpInput = ""
+}
+
+// ExitJump deactivates the jump-to-event input and returns the event number
+// entered, or -1 if none was.
+func (s *Stepper) ExitJump() int {
+	s.Jumping = false
+	n, err := strconv.Atoi(s.JumpInput)
+	s.JumpInput = ""
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// TypeDigits appends the digits in text to the jump input.
+func (s *Stepper) TypeDigits(text string) {
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			s.JumpInput += string(r)
+		}
+	}
+}
+
+// Backspace removes the last digit from the jump input.
+func (s *Stepper) Backspace() {
+	if s.JumpInput != "" {
+		s.JumpInput = s.JumpInput[:len(s.JumpInput)-1]
+	}
+}
+
+// RenderStepBar renders the step mode status bar: the current position and
+// key hints, or the jump-to-event input while it is active.
+func RenderStepBar(s Stepper, turn, width int) string {
+	if !s.Enabled || width <= 0 {
+		return ""
+	}
+	if s.Jumping {
+		return fitBarLine(style.AccentText("go to event: ")+s.JumpInput+style.MutedText("█"), width)
+	}
+	position := fmt.Sprintf("event %d/%d · turn %d", s.applied, len(s.lines), turn)
+	hints := style.MutedText("  space step · t turn · g jump · r raw")
+	return fitBarLine(style.AccentText("step ")+position+hints, width)
+}
+
+// rawPanelHeight returns the number of rows the raw JSON panel takes in a
+// terminal of the given height, including its top border.
+func rawPanelHeight(s Stepper, height int) int {
+	if !s.Enabled || !s.ShowRaw {
+		return 0
+	}
+	return max(height/3, 3)
+}
+
+// RenderRawPanel renders the current event's JSON, indented, in a panel
+// exactly rows tall.
+func RenderRawPanel(s Stepper, width, rows int) string {
+	if rows <= 0 || width <= 0 {
+		return ""
+	}
+	raw := s.Current()
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(raw), "", "  ") == nil {
+		raw = indented.String()
+	}
+
+	lines := []string{fitBarLine(style.MutedText(strings.Repeat("─", width)), width)}
+	body := strings.Split(raw, "\n")
+	if raw == "" {
+		body = []string{style.MutedText("no event applied yet")}
+	}
+	if len(body) > rows-1 {
+		hidden := len(body) - (rows - 2)
+		body = append(body[:rows-2], style.MutedText(textutil.TruncationIndicator(hidden)))
+	}
+	for _, line := range body {
+		lines = append(lines, fitBarLine(ansi.Truncate(line, width, "…"), width))
+	}
+	for len(lines) < rows {
+		lines = append(lines, fitBarLine("", width))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStepper_StepEvent(t *testing.T) {
+	s := NewStepper(true)
+	s.Buffer("a")
+	s.Buffer("b")
+
+	if _, ok := s.Next(0); ok {
+		t.Fatal("expected no line before a step is requested")
+	}
+	s.StepEvent()
+	if line, ok := s.Next(0); !ok || line != "a" {
+		t.Fatalf("Next() = %q, %v; want a", line, ok)
+	}
+	if _, ok := s.Next(0); ok {
+		t.Error("expected a single step to apply a single line")
+	}
+	if s.Current() != "a" || s.Applied() != 1 || s.Total() != 2 {
+		t.Errorf("Current=%q Applied=%d Total=%d", s.Current(), s.Applied(), s.Total())
+	}
+}
+
+func TestStepper_StepEventWaitsForInput(t *testing.T) {
+	s := NewStepper(true)
+	s.StepEvent()
+	if _, ok := s.Next(0); ok {
+		t.Fatal("expected nothing to apply on an empty buffer")
+	}
+	s.Buffer("late")
+	if line, ok := s.Next(0); !ok || line != "late" {
+		t.Errorf("expected pending step to apply the next line to arrive, got %q, %v", line, ok)
+	}
+}
+
+func TestStepper_StepTurn(t *testing.T) {
+	s := NewStepper(true)
+	for _, line := range []string{"a", "b", "c"} {
+		s.Buffer(line)
+	}
+	s.StepTurn(1)
+	turn := 1
+	var applied []string
+	for {
+		line, ok := s.Next(turn)
+		if !ok {
+			break
+		}
+		applied = append(applied, line)
+		if line == "b" {
+			turn = 2
+		}
+	}
+	if strings.Join(applied, "") != "ab" {
+		t.Errorf("expected lines through the turn change, got %v", applied)
+	}
+	if _, ok := s.Next(turn); ok {
+		t.Error("expected the turn step to be complete")
+	}
+}
+
+func TestStepper_JumpTo(t *testing.T) {
+	s := NewStepper(true)
+	for _, line := range []string{"a", "b", "c", "d"} {
+		s.Buffer(line)
+	}
+	if s.JumpTo(3) {
+		t.Error("jumping forward should not rewind")
+	}
+	for _, ok := s.Next(0); ok; _, ok = s.Next(0) {
+	}
+	if s.Applied() != 3 {
+		t.Fatalf("Applied() = %d, want 3", s.Applied())
+	}
+
+	if !s.JumpTo(1) {
+		t.Fatal("jumping backward should rewind")
+	}
+	s.Rewind()
+	for _, ok := s.Next(0); ok; _, ok = s.Next(0) {
+	}
+	if s.Applied() != 1 || s.Current() != "a" {
+		t.Errorf("Applied=%d Current=%q, want 1 a", s.Applied(), s.Current())
+	}
+}
+
+func TestStepper_Replaying(t *testing.T) {
+	s := NewStepper(true)
+	for _, line := range []string{"a", "b", "c"} {
+		s.Buffer(line)
+	}
+	s.JumpTo(2)
+	for _, ok := s.Next(0); ok; _, ok = s.Next(0) {
+		if s.Replaying() {
+			t.Error("expected first application not to be a replay")
+		}
+	}
+
+	s.JumpTo(0)
+	s.Rewind()
+	s.JumpTo(3)
+	var replayed []bool
+	for _, ok := s.Next(0); ok; _, ok = s.Next(0) {
+		replayed = append(replayed, s.Replaying())
+	}
+	if len(replayed) != 3 || !replayed[0] || !replayed[1] || replayed[2] {
+		t.Errorf("Replaying() per line = %v, want [true true false]", replayed)
+	}
+}
+
+func TestStepper_JumpInput(t *testing.T) {
+	s := NewStepper(true)
+	s.EnterJump()
+	s.TypeDigits("1x2")
+	s.TypeDigits("3")
+	s.Backspace()
+	if got := s.ExitJump(); got != 12 || s.Jumping {
+		t.Errorf("ExitJump() = %d (jumping %v), want 12", got, s.Jumping)
+	}
+	s.EnterJump()
+	if got := s.ExitJump(); got != -1 {
+		t.Errorf("ExitJump() with no input = %d, want -1", got)
+	}
+}
+
+func TestRenderStepBar(t *testing.T) {
+	if RenderStepBar(NewStepper(false), 0, 80) != "" {
+		t.Error("expected no bar when step mode is off")
+	}
+
+	s := NewStepper(true)
+	s.Buffer("a")
+	s.Buffer("b")
+	s.StepEvent()
+	s.Next(0)
+	bar := ansi.Strip(RenderStepBar(s, 3, 80))
+	if !strings.Contains(bar, "event 1/2 · turn 3") || ansi.StringWidth(bar) != 80 {
+		t.Errorf("unexpected step bar %q", bar)
+	}
+
+	s.EnterJump()
+	s.TypeDigits("7")
+	if bar := ansi.Strip(RenderStepBar(s, 3, 80)); !strings.Contains(bar, "go to event: 7") {
+		t.Errorf("expected jump input, got %q", bar)
+	}
+}
+
+func TestRenderRawPanel(t *testing.T) {
+	s := NewStepper(true)
+	s.ShowRaw = true
+	if rows := rawPanelHeight(s, 30); rows != 10 {
+		t.Fatalf("rawPanelHeight = %d, want 10", rows)
+	}
+
+	panel := ansi.Strip(RenderRawPanel(s, 40, 4))
+	if !strings.Contains(panel, "no event applied yet") || strings.Count(panel, "\n") != 3 {
+		t.Errorf("unexpected empty panel:\n%s", panel)
+	}
+
+	s.Buffer(`{"type":"assistant","message":{"id":"m1","content":[]}}`)
+	s.StepEvent()
+	s.Next(0)
+	panel = ansi.Strip(RenderRawPanel(s, 40, 4))
+	lines := strings.Split(panel, "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "{") || !strings.Contains(lines[2], `"type": "assistant"`) {
+		t.Errorf("expected indented JSON, got:\n%s", panel)
+	}
+	if !strings.Contains(lines[3], "more lines") {
+		t.Errorf("expected truncation indicator, got %q", lines[3])
+	}
+}
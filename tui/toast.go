@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/style"
+)
+
+// toastDuration is how long a toast stays on screen.
+const toastDuration = 3 * time.Second
+
+// Toast is a short status message, such as the outcome of a -config reload,
+// shown on a bar beneath the transcript until it expires. Unlike the notices
+// of the command line and selection bars, keys do not clear it.
+type Toast struct {
+	Text  string
+	Error bool
+	shown int // toasts shown so far, so an expiry clears only its own
+}
+
+// Visible reports whether the toast bar is shown.
+func (t Toast) Visible() bool {
+	return t.Text != ""
+}
+
+// Show shows text, as an error when isError is set, and returns the command
+// that expires it after toastDuration.
+func (t *Toast) Show(text string, isError bool) tea.Cmd {
+	t.shown++
+	t.Text, t.Error = text, isError
+	shown := t.shown
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{Shown: shown}
+	})
+}
+
+// Expire hides the toast, unless another has been shown since the one msg
+// expires.
+func (t *Toast) Expire(msg ToastExpiredMsg) {
+	if msg.Shown == t.shown {
+		t.Text = ""
+	}
+}
+
+// RenderToast renders the toast bar, or "" when there is no toast.
+func RenderToast(t Toast, width int) string {
+	if !t.Visible() || width <= 0 {
+		return ""
+	}
+	if t.Error {
+		return fitBarLine(style.ErrorText("✗ "+t.Text), width)
+	}
+	return fitBarLine(style.SuccessText("✓ "+t.Text), width)
+}
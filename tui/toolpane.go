@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/spinner"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+const (
+	toolPanePercent    = 40 // share of the content width the tool pane takes
+	minToolPaneWidth   = 24
+	minTranscriptWidth = 40 // the pane is hidden when the transcript would be narrower
+)
+
+// toolPaneWidth returns the number of columns the tool output pane takes
+// from a content area of the given width, including its left border, or 0
+// when the pane is off or the area is too narrow to split.
+func toolPaneWidth(enabled bool, contentWidth int) int {
+	if !enabled {
+		return 0
+	}
+	width := max(contentWidth*toolPanePercent/100, minToolPaneWidth)
+	if contentWidth-width < minTranscriptWidth {
+		return 0
+	}
+	return width
+}
+
+// RenderToolPane renders the running tool and the output it has streamed so
+// far in a pane exactly width columns wide and rows tall. The newest output
+// lines are kept when it does not fit. Claude does not stream tool output,
+// so for Claude the pane shows the running tool until its result arrives.
+func RenderToolPane(s *state.State, sp spinner.Model, width, rows int) string {
+	if width <= 0 || rows <= 0 {
+		return ""
+	}
+	border := style.MutedText("│") + " "
+	inner := width - 2
+
+	var header string
+	var body []string
+	switch {
+	case s.CurrentTool == "":
+		header = style.SidebarHeaderText("Tool output")
+		body = []string{style.MutedText("no tool running")}
+	default:
+		toolText := s.CurrentTool
+		if s.CurrentToolInput != "" {
+			toolText += " " + s.CurrentToolInput
+		}
+		header = sp.View() + " " + style.SidebarTodoActiveText(toolText)
+		output := strings.TrimRight(textutil.StripTerminalControls(s.CurrentToolOutput), "\n")
+		output = strings.ReplaceAll(output, "\t", "    ")
+		if output == "" {
+			body = []string{style.MutedText("waiting for output…")}
+		} else {
+			body = strings.Split(output, "\n")
+		}
+	}
+
+	if room := rows - 1; len(body) > room {
+		body = body[len(body)-room:]
+	}
+	lines := []string{border + fitBarLine(ansi.Truncate(header, inner, "…"), inner)}
+	for _, line := range body {
+		lines = append(lines, border+fitBarLine(ansi.Truncate(line, inner, "…"), inner))
+	}
+	for len(lines) < rows {
+		lines = append(lines, border+fitBarLine("", inner))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/state"
+)
+
+func TestToolPaneWidth(t *testing.T) {
+	if got := toolPaneWidth(false, 120); got != 0 {
+		t.Errorf("toolPaneWidth(off) = %d, want 0", got)
+	}
+	if got := toolPaneWidth(true, 100); got != 40 {
+		t.Errorf("toolPaneWidth(100) = %d, want 40", got)
+	}
+	if got := toolPaneWidth(true, 60); got != 0 {
+		t.Errorf("toolPaneWidth(60) = %d, want 0 when the transcript would be too narrow", got)
+	}
+}
+
+func TestRenderToolPane(t *testing.T) {
+	m := newTestModel()
+
+	t.Run("idle", func(t *testing.T) {
+		pane := RenderToolPane(state.NewState(), m.spinner, 30, 4)
+		lines := strings.Split(pane, "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected 4 rows, got %d: %q", len(lines), pane)
+		}
+		for _, line := range lines {
+			if w := ansi.StringWidth(line); w != 30 {
+				t.Errorf("row %q is %d cells wide, want 30", ansi.Strip(line), w)
+			}
+		}
+		if !strings.Contains(ansi.Strip(pane), "no tool running") {
+			t.Errorf("expected the idle notice, got %q", ansi.Strip(pane))
+		}
+	})
+
+	t.Run("running tool keeps the newest output", func(t *testing.T) {
+		st := state.NewState()
+		st.SetCurrentTool("Shell", "go test ./...")
+		st.CurrentToolOutput = "one\ntwo\nthree\nfour\n"
+		pane := ansi.Strip(RenderToolPane(st, m.spinner, 30, 3))
+		if !strings.Contains(pane, "Shell go test ./...") {
+			t.Errorf("expected the running command in the header, got %q", pane)
+		}
+		if strings.Contains(pane, "two") || !strings.Contains(pane, "three") || !strings.Contains(pane, "four") {
+			t.Errorf("expected only the last two output lines, got %q", pane)
+		}
+	})
+
+	t.Run("running tool without output", func(t *testing.T) {
+		st := state.NewState()
+		st.SetCurrentTool("Bash", "sleep 5")
+		if pane := ansi.Strip(RenderToolPane(st, m.spinner, 30, 3)); !strings.Contains(pane, "waiting for output") {
+			t.Errorf("expected the waiting notice, got %q", pane)
+		}
+	})
+}
+
+func TestHandleKeyMsg_ToggleToolPane(t *testing.T) {
+	m := NewModel(WithInitialSize(140, 40))
+	full := m.viewport.Width()
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "O"})
+	if !m.showToolPane {
+		t.Fatal("expected O to show the tool pane")
+	}
+	if got, want := m.viewport.Width(), full-toolPaneWidth(true, full); got != want {
+		t.Errorf("viewport width with the pane = %d, want %d", got, want)
+	}
+	if !strings.Contains(ansi.Strip(m.renderLayout()), "no tool running") {
+		t.Error("expected the layout to include the tool pane")
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "O"})
+	if m.showToolPane || m.viewport.Width() != full {
+		t.Errorf("expected O to hide the pane and restore width %d, got %d", full, m.viewport.Width())
+	}
+}
@@ -2,15 +2,22 @@ package tui
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/budget"
 	"github.com/johnnyfreeman/viewscreen/events"
 	renderpkg "github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/sink"
 	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/style"
 	"github.com/johnnyfreeman/viewscreen/timeline"
 )
 
@@ -21,6 +28,9 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	if isCtrlCKey(msg) {
 		return m.quitCommand()
 	}
+	if msg.String() == "ctrl+z" {
+		return m, tea.Suspend
+	}
 	if m.shouldIgnoreKeyInputNoise(msg) {
 		return m, nil
 	}
@@ -40,6 +50,12 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		m.noteUserInteraction()
 	}
 
+	if m.selection.Notice != "" || m.cmdline.Notice != "" {
+		m.selection.Notice = ""
+		m.cmdline.Notice = ""
+		m.updateViewportDimensions()
+	}
+
 	if isEscKey(msg) {
 		return m.handleEscKey()
 	}
@@ -49,11 +65,26 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m.handlePromptEditorKeyMsg(msg)
 	}
 
+	// When the command line is active, capture all keys for the command
+	if m.cmdline.Active {
+		return m.handleCommandLineKeyMsg(msg)
+	}
+
 	// When search input is active, capture all keys for the search query
 	if m.search.Active {
 		return m.handleSearchKeyMsg(msg)
 	}
 
+	// When the jump-to-event input is active, capture all keys for it
+	if m.stepper.Jumping {
+		return m.handleJumpKeyMsg(msg)
+	}
+
+	// When selecting a block to copy, capture all keys for it
+	if m.selection.Active {
+		return m.handleSelectionKeyMsg(msg)
+	}
+
 	// Keys that work regardless of modal state
 	switch {
 	case isPlainTextKey(msg, "q"):
@@ -73,12 +104,41 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	pendingZ := m.folds.pendingZ
+	m.folds.pendingZ = false
+
 	switch {
+	case m.stepper.Enabled && isSpaceKey(msg):
+		m.stepper.StepEvent()
+		m.advanceStepper()
+	case m.stepper.Enabled && isPlainTextKey(msg, "t"):
+		m.stepper.StepTurn(m.state.TurnCount)
+		m.advanceStepper()
+	case m.stepper.Enabled && isPlainTextKey(msg, "g"):
+		m.stepper.EnterJump()
+		m.updateViewportDimensions()
+	case m.stepper.Enabled && isPlainTextKey(msg, "r"):
+		m.stepper.ShowRaw = !m.stepper.ShowRaw
+		m.updateViewportDimensions()
+	case isEnterKey(msg), isPlainTextKey(msg, "v"), pendingZ && isPlainTextKey(msg, "a"):
+		m.toggleFocusedFold()
+	case isPlainTextKey(msg, "z"):
+		m.folds.pendingZ = true
+	case isPlainTextKey(msg, "Z"):
+		m.expandAllFolds()
 	case isPlainTextKey(msg, "f"):
 		m.followMode = !m.followMode
 		if m.followMode {
 			m.viewport.GotoBottom()
 		}
+	case isPlainTextKey(msg, "s"):
+		m.statusColumn = !m.statusColumn
+		m.rebuildRenderedContent()
+		m.updateSearchMatches()
+		m.updateViewportDimensions()
+	case isPlainTextKey(msg, "O"):
+		m.showToolPane = !m.showToolPane
+		m.updateViewportDimensions()
 	case isPlainTextKey(msg, "/"):
 		m.search.Enter()
 		m.updateViewportDimensions()
@@ -92,7 +152,23 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 			m.search.PrevMatch()
 			m.scrollToSearchMatch()
 		}
+	case isPlainTextKey(msg, "V"):
+		return m, OpenInProgram(pagerCommand(os.Getenv), m.content.String())
+	case isPlainTextKey(msg, "o"):
+		return m, OpenInProgram(editorCommand(os.Getenv), ansi.Strip(m.content.String()))
+	case isPlainTextKey(msg, "y"):
+		m.enterSelection()
+	case isPlainTextKey(msg, ":"):
+		m.cmdline.Enter("")
+		m.updateViewportDimensions()
+	case isPlainTextKey(msg, "w"):
+		m.cmdline.Enter("w ")
+		m.updateViewportDimensions()
 	case isPlainTextKey(msg, "e"):
+		m.jumpToError(1)
+	case isPlainTextKey(msg, "E"):
+		m.jumpToError(-1)
+	case isPlainTextKey(msg, "p"):
 		if m.canEditPrompt() {
 			m.promptEditor.Enter(m.state.Prompt)
 			m.updateViewportDimensions()
@@ -126,6 +202,14 @@ func (m Model) handleEscKey() (Model, tea.Cmd) {
 	case m.promptEditor.Active:
 		m.promptEditor.Cancel(m.state.Prompt)
 		m.updateViewportDimensions()
+	case m.stepper.Jumping:
+		m.stepper.ExitJump()
+		m.updateViewportDimensions()
+	case m.cmdline.Active:
+		m.cmdline.Exit()
+		m.updateViewportDimensions()
+	case m.selection.Active:
+		m.exitSelection()
 	case m.search.Active || m.search.HasQuery():
 		m.search.Clear()
 		m.updateViewportDimensions()
@@ -268,6 +352,7 @@ func (m Model) shouldStartAutoExitCountdown() bool {
 		return false
 	}
 	return m.followMode &&
+		!m.stepper.Enabled &&
 		!m.search.Active &&
 		!m.search.HasQuery() &&
 		!m.promptEditor.Active &&
@@ -338,8 +423,84 @@ func (m Model) handleSearchKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
-// scrollToSearchMatch scrolls the viewport to show the current search match.
+// handleCommandLineKeyMsg processes keyboard input while the command line is
+// active. Enter runs the typed command.
+func (m Model) handleCommandLineKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case isEnterKey(msg):
+		input := m.cmdline.Input
+		m.cmdline.Exit()
+		cmd := m.runCommand(input)
+		m.updateViewportDimensions()
+		return m, cmd
+	case msg.String() == "backspace":
+		m.cmdline.Backspace()
+	default:
+		if text := keyInputText(msg); isPrintableInputText(text) {
+			m.cmdline.TypeText(text)
+		}
+	}
+	return m, nil
+}
+
+// handleJumpKeyMsg processes keyboard input while the jump-to-event input is
+// active. Enter jumps to the typed event number, rewinding the session when
+// it lies before the current event.
+func (m Model) handleJumpKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case isEnterKey(msg):
+		if n := m.stepper.ExitJump(); n >= 0 && m.stepper.JumpTo(n) {
+			m.stepper.Rewind()
+			if !m.showReplayEvent(0) {
+				m.resetSession(m.prompt)
+			}
+		}
+		m.updateViewportDimensions()
+		m.advanceStepper()
+	case msg.String() == "backspace":
+		m.stepper.Backspace()
+	default:
+		m.stepper.TypeDigits(keyInputText(msg))
+	}
+	return m, nil
+}
+
+// advanceStepper applies buffered lines until the pending step request is
+// satisfied or the buffer runs out; the rest apply as they arrive. Events
+// processed before a rewind are restored from the replay cache, once, where
+// stepping stops; only events never processed are rendered.
+func (m *Model) advanceStepper() {
+	restore := -1 // cached events to restore once stepping stops
+	turn := m.state.TurnCount
+	for {
+		line, ok := m.stepper.Next(turn)
+		if !ok {
+			break
+		}
+		n := m.stepper.Applied()
+		if n <= m.replay.Processed() {
+			if t, cached := m.replay.Turn(n); cached {
+				restore, turn = n, t
+				continue
+			}
+			m.replayFromStart(n)
+			restore, turn = -1, m.state.TurnCount
+			continue
+		}
+		restore = -1
+		m.returnToLiveEvent()
+		m.applyStepLine(line, !m.stepper.Replaying())
+		turn = m.state.TurnCount
+	}
+	if restore >= 0 {
+		m.showReplayEvent(restore)
+	}
+}
+
+// scrollToSearchMatch scrolls the viewport to show the current search match,
+// refreshing the match highlights.
 func (m *Model) scrollToSearchMatch() {
+	m.setViewportContent(m.visibleContent())
 	line := m.search.CurrentLine()
 	if line < 0 {
 		return
@@ -348,8 +509,13 @@ func (m *Model) scrollToSearchMatch() {
 	m.viewport.SetYOffset(line)
 }
 
+// resultHeightPercent is the share of the viewport height a single tool
+// result may fill before it is truncated.
+const resultHeightPercent = 40
+
 // updateViewportDimensions recalculates the viewport size for the current
-// terminal dimensions and active bottom bars.
+// terminal dimensions and active bottom bars, and rescales the truncation
+// limits of tool results to the new height.
 func (m *Model) updateViewportDimensions() {
 	if m.width == 0 || m.height == 0 {
 		return
@@ -357,37 +523,74 @@ func (m *Model) updateViewportDimensions() {
 
 	m.updateLayoutMode()
 
-	var contentWidth, contentHeight int
+	contentWidth := max(m.contentWidth()-m.toolPaneColumns(), 1)
+	var contentHeight int
 	switch m.layoutMode {
 	case LayoutHeader:
-		contentWidth = max(m.width-2, 1)
 		contentHeight = m.height - headerHeight - 1
 	default:
-		contentWidth = max(m.width-sidebarRenderedWidth(m.sidebarStyles), 1)
 		contentHeight = m.height - 2
 	}
 
 	if m.search.Active || m.search.HasQuery() {
 		contentHeight--
 	}
+	if m.selection.Visible() {
+		contentHeight--
+	}
+	if m.cmdline.Visible() {
+		contentHeight--
+	}
+	if m.toast.Visible() {
+		contentHeight--
+	}
 	if m.promptEditor.Active {
 		contentHeight--
 	}
+	if m.budget.Level(m.state.CumulativeCost) != budget.Under {
+		contentHeight--
+	}
+	if m.stepper.Enabled {
+		contentHeight -= 1 + rawPanelHeight(m.stepper, m.height)
+	}
 
+	if m.viewport.Width() != contentWidth {
+		m.folds.Rewrap()
+	}
 	m.viewport.SetWidth(contentWidth)
 	m.viewport.SetHeight(max(contentHeight, 1))
-	m.processor.SetWidth(contentWidth)
+	if m.gutter {
+		m.processor.SetWidth(max(contentWidth-renderpkg.StatusColumnWidth, 1))
+	} else {
+		m.processor.SetWidth(contentWidth)
+	}
+	m.processor.SetLineBudget(max(contentHeight*resultHeightPercent/100, 1))
 
 	if m.processor.HasPendingTools() {
 		m.updateViewportWithPendingTools()
 	} else {
-		m.viewport.SetContent(m.content.String())
+		m.setViewportContent(m.content.String())
 	}
 	if m.followMode {
 		m.viewport.GotoBottom()
 	}
 }
 
+// contentWidth returns the width of the area beside the sidebar, or below
+// the header, shared by the transcript and the tool output pane.
+func (m Model) contentWidth() int {
+	if m.layoutMode == LayoutHeader {
+		return max(m.width-2, 1)
+	}
+	return max(m.width-sidebarRenderedWidth(m.sidebarStyles), 1)
+}
+
+// toolPaneColumns returns the width of the tool output pane, or 0 when it is
+// hidden.
+func (m Model) toolPaneColumns() int {
+	return toolPaneWidth(m.showToolPane, m.contentWidth())
+}
+
 func (m *Model) updateLayoutMode() {
 	if m.width < breakpointWidth {
 		m.layoutMode = LayoutHeader
@@ -405,6 +608,89 @@ func (m Model) handleWindowSizeMsg(msg tea.WindowSizeMsg) Model {
 	return m
 }
 
+// handleResume repaints the whole TUI from the timeline once it has the
+// terminal back from a shell (after ctrl+z) or a pager or editor. The
+// terminal may have been resized meanwhile, and the other program may have
+// left mouse or paste modes on.
+func (m Model) handleResume() (Model, tea.Cmd) {
+	m.rebuildRenderedContent()
+	m.updateSearchMatches()
+	m.updateViewportDimensions()
+	return m, tea.Batch(tea.Raw(terminalModeResetSequence()), tea.RequestWindowSize, tea.ClearScreen)
+}
+
+// handleExternalProgramExited cleans up after the pager or editor opened on
+// the transcript, reporting it in the transcript if it failed.
+func (m Model) handleExternalProgramExited(msg ExternalProgramExitedMsg) (Model, tea.Cmd) {
+	if msg.Path != "" {
+		_ = os.Remove(msg.Path)
+	}
+	if msg.Err != nil {
+		m.timeline = append(m.timeline, timeline.Entry{Kind: "error", Body: style.ErrorText("External program error: ") + msg.Err.Error() + "\n"})
+	}
+	return m.handleResume()
+}
+
+// handleSelectionKeyMsg handles keys in selection mode: moving the focus
+// between blocks and copying the focused one.
+func (m Model) handleSelectionKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case isPlainTextKey(msg, "y"), isEnterKey(msg):
+		return m, m.copySelection()
+	case msg.String() == "down", isPlainTextKey(msg, "j"):
+		m.moveSelection(1)
+	case msg.String() == "up", isPlainTextKey(msg, "k"):
+		m.moveSelection(-1)
+	case isPlainTextKey(msg, "q"):
+		return m.quitCommand()
+	}
+	return m, nil
+}
+
+// handleClipboardCopied reports a copied block in the selection bar.
+func (m Model) handleClipboardCopied(msg ClipboardCopiedMsg) Model {
+	if msg.Err != nil {
+		m.selection.Notice = "Copied to clipboard; saving a copy failed: " + msg.Err.Error()
+	} else {
+		m.selection.Notice = fmt.Sprintf("Copied %d lines to clipboard (also saved to %s)", msg.Lines, msg.Path)
+	}
+	m.updateViewportDimensions()
+	return m
+}
+
+// handleSessionSaved reports the outcome of :w on the command line.
+func (m Model) handleSessionSaved(msg SessionSavedMsg) Model {
+	if msg.Err != nil {
+		m.cmdline.Notice = "Save failed: " + msg.Err.Error()
+	} else {
+		m.cmdline.Notice = fmt.Sprintf("Saved %d events to %s", msg.Events, msg.Path)
+	}
+	m.updateViewportDimensions()
+	return m
+}
+
+// handlePageTitle shows the page titles looked up for a tool result whose
+// WebFetch headers were rendered without them. Nothing changes when the
+// result is gone, as after a rerun.
+func (m Model) handlePageTitle(msg PageTitleMsg) Model {
+	for i := len(m.timeline) - 1; i >= 0; i-- {
+		if m.timeline[i].Body != msg.Untitled {
+			continue
+		}
+		retitled := msg.Retitle()
+		m.timeline[i].Body, m.timeline[i].Full = retitled.Body, retitled.Full
+		m.rebuildRenderedContent()
+		m.updateSearchMatches()
+		if m.processor.HasPendingTools() {
+			m.updateViewportWithPendingTools()
+		} else {
+			m.setViewportContent(m.content.String())
+		}
+		break
+	}
+	return m
+}
+
 // handleSpinnerTick processes spinner animation ticks.
 func (m Model) handleSpinnerTick(msg spinner.TickMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -418,29 +704,70 @@ func (m Model) handleSpinnerTick(msg spinner.TickMsg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
-// handleRawLine processes a line read from stdin.
+// handleRawLine processes a line read from stdin, with its secrets masked,
+// unless the -only/-hide filter drops it. In step mode the line is buffered
+// until the user steps to it.
 func (m Model) handleRawLine(msg RawLineMsg) (Model, tea.Cmd) {
-	var cmds []tea.Cmd
-
-	// Parse the line and dispatch appropriate message
-	parsedMsg := ParseEvent(msg.Line)
-	if parsedMsg != nil {
-		if parseErr, ok := parsedMsg.(events.ParseError); ok {
-			m = m.handleParseError(parseErr)
-		} else {
-			// Process the parsed event immediately
-			var cmd tea.Cmd
-			m, cmd = m.processEvent(parsedMsg)
-			if cmd != nil {
-				cmds = append(cmds, cmd)
-			}
-		}
+	line := m.redactor.Line(msg.Line)
+	if !m.stepper.Enabled {
+		m.applyLine(line, true)
+	} else if _, keep := m.filter.Apply(line); keep {
+		m.stepper.Buffer(line)
+		m.advanceStepper()
+	} else {
+		m.debugEvents.Filtered(line)
 	}
 
 	// Continue reading stdin
-	cmds = append(cmds, ReadStdinLine(m.scanner))
+	return m, ReadStdinLine(m.scanner)
+}
 
-	return m, tea.Batch(cmds...)
+// applyLine filters and parses an input line, with its secrets masked, and
+// renders the resulting event. A line the filter drops is still kept in
+// rawLines, so a -config reload that changes the filter can render it.
+// Unless the line is being replayed after a step-mode rewind or a reload
+// (fresh is false), the event is also written to the model's sink.
+func (m *Model) applyLine(raw string, fresh bool) {
+	m.committed = nil
+	if m.budgetStopped {
+		return
+	}
+	line, keep := m.filter.Apply(raw)
+	if !keep {
+		if fresh {
+			m.debugEvents.Filtered(raw)
+		}
+		m.rawLines = append(m.rawLines, raw)
+		return
+	}
+	parsedMsg := ParseEvent(line)
+	if parsedMsg == nil {
+		return
+	}
+	if event, ok := parsedMsg.(events.Event); ok && m.passNoise {
+		parsedMsg = events.PassNoise(event)
+	}
+	if parseErr, ok := parsedMsg.(events.ParseError); ok {
+		if fresh {
+			m.debugEvents.Event(line, parseErr, nil)
+		}
+		*m = m.handleParseError(parseErr)
+		if m.showParseErrors {
+			m.committed = slices.Clone(m.timeline[len(m.timeline)-1:])
+		}
+		return
+	}
+	m.rawLines = append(m.rawLines, raw)
+	level := m.budget.Level(m.state.CumulativeCost)
+	*m, _ = m.processEvent(line, parsedMsg)
+	m.checkBudget(level)
+	if event, ok := parsedMsg.(events.Event); ok && fresh {
+		m.debugEvents.Event(line, event, m.committed)
+	}
+	if m.outputs != nil && fresh {
+		// Write errors are reported when the caller closes the sink.
+		_ = m.outputs.Write(sink.Event{Raw: line, Entries: m.committed})
+	}
 }
 
 // handleStdinClosed processes the stdin closed signal.
@@ -481,17 +808,11 @@ func (m Model) handleRerun(msg RerunMsg) (Model, tea.Cmd) {
 	}
 
 	// Reset state
-	m.content = &strings.Builder{}
-	m.timeline = nil
+	m.resetSession(msg.Prompt)
 	m.stdinDone = false
 	m.streamErr = nil
 	m.autoExitRemaining = 0
 	m.autoExitCanceled = false
-	st := state.NewState()
-	st.Prompt = msg.Prompt
-	m.state = st
-	m.processor = events.NewEventProcessor(st)
-	m.timelineRenderer = renderpkg.NewTimelineRenderer()
 	m.prompt = msg.Prompt
 	m.followMode = true
 	m.agentProcess = nil
@@ -526,10 +847,30 @@ func (m Model) handleRerun(msg RerunMsg) (Model, tea.Cmd) {
 	return m, ReadStdinLine(m.scanner)
 }
 
+// resetSession discards all rendered content and session state, as if no
+// input had been read yet.
+func (m *Model) resetSession(prompt string) {
+	m.content = &strings.Builder{}
+	m.timeline = nil
+	m.entryStarts = nil
+	m.rawLines = nil
+	st := state.NewState()
+	st.Prompt = prompt
+	m.state = st
+	m.processor = newEventProcessor(st)
+	m.processor.SetTurnDivider(m.turnDivider)
+	m.folds.Reset()
+	m.selection = Selection{}
+	m.timelineRenderer = renderpkg.NewTimelineRenderer()
+	m.replay.Reset()
+	m.replayActivities = nil
+	m.budgetStopped = false
+}
+
 func (m *Model) failRerunStart(err error) {
 	m.timeline = append(m.timeline, timeline.Entry{Kind: "error", Body: "Error starting agent: " + err.Error() + "\n"})
 	m.rebuildRenderedContent()
-	m.viewport.SetContent(m.content.String())
+	m.setViewportContent(m.content.String())
 	m.stdinDone = true
 }
 
@@ -551,7 +892,7 @@ func (m Model) handleParseError(msg events.ParseError) Model {
 		m.timeline = append(m.timeline, timeline.Entry{Kind: "parse_error", Body: "Parse error: " + msg.Line + "\n"})
 		m.rebuildRenderedContent()
 		m.updateSearchMatches()
-		m.viewport.SetContent(m.content.String())
+		m.setViewportContent(m.content.String())
 		if m.followMode {
 			m.viewport.GotoBottom()
 		}
@@ -2,15 +2,21 @@ package tui
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
+	"github.com/johnnyfreeman/viewscreen/annotations"
 	"github.com/johnnyfreeman/viewscreen/assistant"
 	"github.com/johnnyfreeman/viewscreen/events"
+	renderpkg "github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/timeline"
 	"github.com/johnnyfreeman/viewscreen/types"
 )
 
@@ -1160,7 +1166,7 @@ func TestProcessEventRefreshesSearchMatches(t *testing.T) {
 	m.search.Query = "needle"
 	m.search.UpdateMatches(m.content.String())
 
-	m, _ = m.processEvent(events.AssistantEvent{
+	m, _ = m.processEvent("", events.AssistantEvent{
 		Data: assistant.Event{
 			Message: assistant.Message{
 				Content: []types.ContentBlock{
@@ -1175,10 +1181,41 @@ func TestProcessEventRefreshesSearchMatches(t *testing.T) {
 	}
 }
 
+func TestHandlePageTitle(t *testing.T) {
+	m := newTestModel()
+	m.timeline = []timeline.Entry{
+		{Kind: "user", Title: "WebFetch", Body: "● WebFetch https://go.dev\n"},
+		{Kind: "assistant", Body: "after\n"},
+	}
+	m.rebuildRenderedContent()
+
+	m = m.handlePageTitle(PageTitleMsg{
+		Untitled: "● WebFetch https://go.dev\n",
+		Retitle: func() timeline.Entry {
+			return timeline.Entry{Body: "● WebFetch https://go.dev — The Go Programming Language\n"}
+		},
+	})
+	if !strings.Contains(m.timeline[0].Body, "The Go Programming Language") || m.timeline[0].Title != "WebFetch" {
+		t.Errorf("timeline[0] = %+v, want the retitled body in the same entry", m.timeline[0])
+	}
+	if !strings.Contains(m.content.String(), "The Go Programming Language") {
+		t.Errorf("content = %q, want the retitled header shown", m.content.String())
+	}
+
+	// A result no longer shown, as after a rerun, is left alone.
+	m = m.handlePageTitle(PageTitleMsg{Untitled: "gone", Retitle: func() timeline.Entry {
+		t.Error("Retitle called for a result that is gone")
+		return timeline.Entry{}
+	}})
+	if len(m.timeline) != 2 {
+		t.Errorf("timeline entries = %d, want 2", len(m.timeline))
+	}
+}
+
 func TestProcessEventStoresTimelineEntries(t *testing.T) {
 	m := newTestModel()
 
-	m, _ = m.processEvent(events.AssistantEvent{
+	m, _ = m.processEvent("", events.AssistantEvent{
 		Data: assistant.Event{
 			Message: assistant.Message{
 				Content: []types.ContentBlock{{Type: "text", Text: "timeline primary"}},
@@ -1194,10 +1231,81 @@ func TestProcessEventStoresTimelineEntries(t *testing.T) {
 	}
 }
 
+func TestScrollPositionReportsFocusedEntryID(t *testing.T) {
+	m := newTestModel()
+	m.viewport.SetHeight(2)
+
+	for _, ev := range []struct{ uuid, text string }{
+		{"first-uuid", "one\n\ntwo\n\nthree"},
+		{"second-uuid", "four\n\nfive\n\nsix"},
+	} {
+		m, _ = m.processEvent("", events.AssistantEvent{
+			Data: assistant.Event{
+				BaseEvent: types.BaseEvent{UUID: ev.uuid},
+				Message:   assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: ev.text}}},
+			},
+		})
+	}
+
+	m.viewport.GotoTop()
+	if got := m.scrollPosition().FocusedID; got != "first-uuid" {
+		t.Errorf("FocusedID at top = %q, want first-uuid", got)
+	}
+	m.viewport.SetYOffset(m.entryStarts[1])
+	if got := m.scrollPosition().FocusedID; got != "second-uuid" {
+		t.Errorf("FocusedID at second entry = %q, want second-uuid", got)
+	}
+}
+
+func TestApplyLineStitchesReadChunks(t *testing.T) {
+	m := newTestModel()
+	for _, chunk := range []struct {
+		id    string
+		start int
+	}{{"t1", 1}, {"t2", 101}} {
+		m.applyLine(`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"`+chunk.id+`","name":"Read","input":{"file_path":"/src/foo.go"}}]}}`, true)
+		m.applyLine(fmt.Sprintf(`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":%q,"content":"lines"}]},`+
+			`"tool_use_result":{"type":"text","file":{"filePath":"/src/foo.go","content":"x","numLines":100,"startLine":%d,"totalLines":200}}}`, chunk.id, chunk.start), true)
+	}
+
+	var reads int
+	for _, entry := range m.timeline {
+		if entry.Title == "Read" {
+			reads++
+		}
+	}
+	if reads != 1 {
+		t.Errorf("expected the chunks merged into one Read block, got %d", reads)
+	}
+	if !strings.Contains(m.content.String(), "Read lines 1–200 of foo.go (2 chunks)") {
+		t.Errorf("expected the merged summary, got %q", m.content.String())
+	}
+}
+
+func TestProcessEventInterleavesAnnotations(t *testing.T) {
+	m := newTestModel()
+	m.annotations = annotations.Set{"turn-1": {"consider a table test"}}
+
+	m, _ = m.processEvent("", events.AssistantEvent{
+		Data: assistant.Event{
+			BaseEvent: types.BaseEvent{UUID: "turn-1"},
+			Message:   assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: "annotated block"}}},
+		},
+	})
+
+	if len(m.timeline) != 2 || m.timeline[1].Kind != annotations.Kind {
+		t.Fatalf("expected block followed by annotation, got %+v", m.timeline)
+	}
+	content := m.visibleContent()
+	if i, j := strings.Index(content, "annotated block"), strings.Index(content, "consider a table test"); i < 0 || j < i {
+		t.Errorf("expected annotation beneath block, got %q", content)
+	}
+}
+
 func TestSearchIncludesVisiblePendingTools(t *testing.T) {
 	m := newTestModel()
 
-	m, _ = m.processEvent(events.AssistantEvent{
+	m, _ = m.processEvent("", events.AssistantEvent{
 		Data: assistant.Event{
 			Message: assistant.Message{
 				Content: []types.ContentBlock{
@@ -1369,3 +1477,195 @@ func TestFollowMode(t *testing.T) {
 		}
 	})
 }
+
+// recordingSink collects the events written to it.
+type recordingSink struct {
+	events []sink.Event
+}
+
+func (r *recordingSink) Write(ev sink.Event) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func (r *recordingSink) Close() error { return nil }
+
+func TestApplyLine_WritesSink(t *testing.T) {
+	outputs := &recordingSink{}
+	m := NewModel(WithInitialSize(120, 40), WithSink(outputs))
+	line := `{"type":"assistant","uuid":"u-1","message":{"content":[{"type":"text","text":"hello"}]}}`
+
+	m, _ = m.handleRawLine(RawLineMsg{Line: line})
+	m, _ = m.handleRawLine(RawLineMsg{Line: "not json"})
+
+	if len(outputs.events) != 1 {
+		t.Fatalf("expected one event written, got %d", len(outputs.events))
+	}
+	ev := outputs.events[0]
+	if ev.Raw != line || len(ev.Entries) != 1 || ev.Entries[0].ID != "u-1" {
+		t.Errorf("unexpected sink event %+v", ev)
+	}
+}
+
+func TestStepMode(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"first reply"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"second reply"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"text","text":"third reply"}]}}`,
+	}
+	newStepModel := func() Model {
+		m := NewModel(WithInitialSize(120, 40), WithStepMode(true))
+		for _, line := range lines {
+			m, _ = m.handleRawLine(RawLineMsg{Line: line})
+		}
+		return m
+	}
+
+	t.Run("buffers input until stepped", func(t *testing.T) {
+		m := newStepModel()
+		if strings.Contains(m.content.String(), "first reply") {
+			t.Fatal("expected no events applied before stepping")
+		}
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: " "})
+		if !strings.Contains(m.content.String(), "first reply") || strings.Contains(m.content.String(), "second reply") {
+			t.Errorf("expected exactly one event after space, got %q", m.content.String())
+		}
+	})
+
+	t.Run("t advances one turn", func(t *testing.T) {
+		m := newStepModel()
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "t"})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "t"})
+		if m.state.TurnCount != 2 || strings.Contains(m.content.String(), "third reply") {
+			t.Errorf("expected two turns applied, got turn %d: %q", m.state.TurnCount, m.content.String())
+		}
+	})
+
+	t.Run("g jumps forward and back", func(t *testing.T) {
+		m := newStepModel()
+		jump := func(m Model, n string) Model {
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "g"})
+			if !m.stepper.Jumping {
+				t.Fatal("expected g to open the jump input")
+			}
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: n})
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+			return m
+		}
+
+		m = jump(m, "3")
+		if !strings.Contains(m.content.String(), "third reply") {
+			t.Fatalf("expected jump to event 3, got %q", m.content.String())
+		}
+		m = jump(m, "1")
+		if strings.Contains(m.content.String(), "second reply") || m.state.TurnCount != 1 {
+			t.Errorf("expected rewind to event 1, got turn %d: %q", m.state.TurnCount, m.content.String())
+		}
+	})
+
+	t.Run("r toggles the raw panel", func(t *testing.T) {
+		m := newStepModel()
+		height := m.viewport.Height()
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: " "})
+		m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "r"})
+		if !m.stepper.ShowRaw || m.viewport.Height() >= height {
+			t.Fatalf("expected raw panel to take viewport rows, height %d -> %d", height, m.viewport.Height())
+		}
+		if !strings.Contains(m.renderLayout(), `"text": "first reply"`) {
+			t.Error("expected raw JSON of the current event in the layout")
+		}
+	})
+
+	t.Run("rewinds do not repeat events in the sink", func(t *testing.T) {
+		outputs := &recordingSink{}
+		m := NewModel(WithInitialSize(120, 40), WithStepMode(true), WithSink(outputs))
+		for _, line := range lines {
+			m, _ = m.handleRawLine(RawLineMsg{Line: line})
+		}
+		for _, n := range []string{"2", "1", "3"} {
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "g"})
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: n})
+			m, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+		}
+		if len(outputs.events) != 3 {
+			t.Fatalf("expected each event written once, got %d writes", len(outputs.events))
+		}
+		for i, ev := range outputs.events {
+			if ev.Raw != lines[i] || len(ev.Entries) == 0 {
+				t.Errorf("write %d = %+v, want line %d with entries", i, ev, i)
+			}
+		}
+	})
+
+	t.Run("does not auto-exit", func(t *testing.T) {
+		m := NewModel(WithStepMode(true), WithAutoExit(true))
+		m, _ = m.handleStdinClosed(nil)
+		if m.autoExitRemaining != 0 {
+			t.Error("expected no auto-exit countdown in step mode")
+		}
+	})
+}
+
+func TestHandleKeyMsg_ToggleStatusColumn(t *testing.T) {
+	m := newTestModel()
+	m, _ = m.processEvent("", events.AssistantEvent{
+		Data: assistant.Event{Message: assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: "Hello"}}}},
+	})
+	if strings.Contains(m.content.String(), renderpkg.StatusIconAssistant) {
+		t.Fatal("expected no status column by default")
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "s"})
+	if !m.statusColumn || !strings.Contains(m.content.String(), renderpkg.StatusIconAssistant+" ") {
+		t.Errorf("expected s to show the status column, got %q", m.content.String())
+	}
+
+	m, _ = m.handleKeyMsg(tea.KeyPressMsg{Text: "s"})
+	if m.statusColumn || strings.Contains(m.content.String(), renderpkg.StatusIconAssistant) {
+		t.Error("expected a second s to hide the status column")
+	}
+}
+
+func TestHandleKeyMsg_CtrlZSuspends(t *testing.T) {
+	m := newTestModel()
+	_, cmd := m.handleKeyMsg(tea.KeyPressMsg{Code: 'z', Mod: tea.ModCtrl})
+	if cmd == nil {
+		t.Fatal("expected a command for ctrl+z")
+	}
+	if _, ok := cmd().(tea.SuspendMsg); !ok {
+		t.Error("expected ctrl+z to suspend the program")
+	}
+}
+
+func TestHandleResume_RepaintsFromTimeline(t *testing.T) {
+	m := newTestModel()
+	m, _ = m.processEvent("", events.AssistantEvent{
+		Data: assistant.Event{Message: assistant.Message{Content: []types.ContentBlock{{Type: "text", Text: "Hello"}}}},
+	})
+	m.content.Reset()
+
+	m, cmd := m.handleResume()
+	if !strings.Contains(m.content.String(), "Hello") {
+		t.Errorf("expected the transcript to be re-rendered, got %q", m.content.String())
+	}
+	if cmd == nil {
+		t.Error("expected commands to clear and repaint the screen")
+	}
+}
+
+func TestHandleExternalProgramExited(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "transcript-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	m := newTestModel()
+	m, _ = m.handleExternalProgramExited(ExternalProgramExitedMsg{Path: f.Name(), Err: errors.New("exit status 2")})
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Error("expected the temporary transcript to be removed")
+	}
+	if !strings.Contains(m.content.String(), "exit status 2") {
+		t.Errorf("expected the failure in the transcript, got %q", m.content.String())
+	}
+}
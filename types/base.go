@@ -10,12 +10,14 @@ type BaseEvent struct {
 	ParentToolUseID *string `json:"parent_tool_use_id"`
 }
 
-// ContentBlock represents a content block (text, thinking, or tool_use)
+// ContentBlock represents a content block (text, thinking, redacted_thinking,
+// or tool_use)
 type ContentBlock struct {
 	Type      string          `json:"type"`
 	Text      string          `json:"text,omitempty"`
 	Thinking  string          `json:"thinking,omitempty"`
 	Signature string          `json:"signature,omitempty"`
+	Data      string          `json:"data,omitempty"`
 	ID        string          `json:"id,omitempty"`
 	Name      string          `json:"name,omitempty"`
 	Input     json.RawMessage `json:"input,omitempty"`
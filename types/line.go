@@ -0,0 +1,54 @@
+package types
+
+import "encoding/json"
+
+// Line is the part of a raw stream-json line that the sinks and collectors
+// reading raw input share: the event envelope, its message and the
+// tool_use_result of a tool result.
+type Line struct {
+	BaseEvent
+	Subtype string `json:"subtype"`
+	Message struct {
+		ID      string          `json:"id"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+	ToolUseResult json.RawMessage `json:"tool_use_result"`
+}
+
+// LineBlock is a content block of a Line's message, with the fields of
+// tool_use and tool_result blocks.
+type LineBlock struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// ParseLine decodes raw as a Line. It reports false for input that is not
+// a JSON object.
+func ParseLine(raw []byte) (Line, bool) {
+	var l Line
+	if json.Unmarshal(raw, &l) != nil {
+		return Line{}, false
+	}
+	return l, true
+}
+
+// Blocks returns the content blocks of the line's message. User prompts
+// carry string content and have none.
+func (l Line) Blocks() []LineBlock {
+	var blocks []LineBlock
+	if json.Unmarshal(l.Message.Content, &blocks) != nil {
+		return nil
+	}
+	return blocks
+}
+
+// IsSubAgent reports whether the line belongs to a sub-agent's conversation
+// rather than the main agent's.
+func (l Line) IsSubAgent() bool {
+	return l.ParentToolUseID != nil
+}
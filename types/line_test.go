@@ -0,0 +1,25 @@
+package types
+
+import "testing"
+
+func TestParseLine(t *testing.T) {
+	l, ok := ParseLine([]byte(`{"type":"user","parent_tool_use_id":"task_1","message":{"content":[{"type":"tool_result","tool_use_id":"t1","is_error":true}]},"tool_use_result":{"filePath":"/a.go"}}`))
+	if !ok {
+		t.Fatal("expected the line to parse")
+	}
+	if l.Type != "user" || !l.IsSubAgent() || string(l.ToolUseResult) != `{"filePath":"/a.go"}` {
+		t.Errorf("unexpected line %+v", l)
+	}
+	blocks := l.Blocks()
+	if len(blocks) != 1 || blocks[0].ToolUseID != "t1" || !blocks[0].IsError {
+		t.Errorf("Blocks() = %+v", blocks)
+	}
+
+	prompt, _ := ParseLine([]byte(`{"type":"user","message":{"content":"hello"}}`))
+	if prompt.Blocks() != nil || prompt.IsSubAgent() {
+		t.Errorf("expected a prompt to have no blocks, got %+v", prompt.Blocks())
+	}
+	if _, ok := ParseLine([]byte("not json")); ok {
+		t.Error("expected non-JSON input not to parse")
+	}
+}
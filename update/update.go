@@ -0,0 +1,431 @@
+// Package update implements the opt-in "viewscreen update" command: it asks
+// GitHub for the latest release, downloads the binary for this platform,
+// verifies it against the release checksums (and their signature, when a
+// release key is built in), and replaces the running executable.
+//
+// Nothing here runs unless the user invokes the command; viewscreen never
+// checks for updates on its own.
+//
+// Releases are expected to carry, for each platform, a raw binary asset named
+// viewscreen_<GOOS>_<GOARCH> (with ".exe" on Windows), plus a checksums.txt
+// in sha256sum format and, optionally, checksums.txt.sig: an ed25519
+// signature of checksums.txt.
+//
+// Checksums fetched from the same release as the binary only catch a
+// corrupted download: whoever can replace the binary can replace
+// checksums.txt too. Only the signature check, in builds with a release
+// key, shows that the release is authentic.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRepo is the GitHub repository releases are fetched from.
+	DefaultRepo = "johnnyfreeman/viewscreen"
+	// DefaultAPIURL is the GitHub REST API root.
+	DefaultAPIURL = "https://api.github.com"
+
+	checksumsAsset = "checksums.txt"
+	signatureAsset = "checksums.txt.sig"
+
+	// maxAssetSize bounds downloads so a bad response cannot fill the disk.
+	maxAssetSize = 256 << 20
+
+	// clientTimeout bounds each API call and download, so a stalled
+	// connection cannot hang the command.
+	clientTimeout = 2 * time.Minute
+)
+
+// publicKey is the base64 ed25519 key release checksums are signed with. It
+// is set at build time for official releases:
+//
+//	-ldflags "-X github.com/johnnyfreeman/viewscreen/update.publicKey=<base64>"
+//
+// When set, a release without a valid signature is rejected. Development
+// builds leave it empty and verify checksums only, which does not
+// authenticate the release.
+var publicKey string
+
+// version is the release this binary was built from, set at build time with
+// -ldflags "-X github.com/johnnyfreeman/viewscreen/update.version=v1.2.3".
+var version string
+
+// CurrentVersion returns the running binary's version: the linked-in release
+// version, else the module version recorded by "go install", else "dev".
+func CurrentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+func (r Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater checks for and installs releases.
+type Updater struct {
+	client     *http.Client
+	apiURL     string
+	repo       string
+	executable string
+	publicKey  ed25519.PublicKey
+	goos       string
+	goarch     string
+}
+
+// Option is a functional option for configuring an Updater.
+type Option func(*Updater)
+
+// WithHTTPClient sets the HTTP client used for API calls and downloads.
+func WithHTTPClient(c *http.Client) Option {
+	return func(u *Updater) {
+		u.client = c
+	}
+}
+
+// WithAPIURL sets the GitHub API root (for tests or GitHub Enterprise).
+func WithAPIURL(url string) Option {
+	return func(u *Updater) {
+		u.apiURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithRepo sets the "owner/name" repository to fetch releases from.
+func WithRepo(repo string) Option {
+	return func(u *Updater) {
+		u.repo = repo
+	}
+}
+
+// WithExecutable sets the binary to replace (default: the running one).
+func WithExecutable(path string) Option {
+	return func(u *Updater) {
+		u.executable = path
+	}
+}
+
+// WithPublicKey sets the key release checksums must be signed with,
+// overriding the built-in key.
+func WithPublicKey(key ed25519.PublicKey) Option {
+	return func(u *Updater) {
+		u.publicKey = key
+	}
+}
+
+// WithPlatform overrides the GOOS/GOARCH used to pick a release asset.
+func WithPlatform(goos, goarch string) Option {
+	return func(u *Updater) {
+		u.goos, u.goarch = goos, goarch
+	}
+}
+
+// New creates an Updater with the given options.
+func New(opts ...Option) (*Updater, error) {
+	u := &Updater{
+		client: &http.Client{Timeout: clientTimeout},
+		apiURL: DefaultAPIURL,
+		repo:   DefaultRepo,
+		goos:   runtime.GOOS,
+		goarch: runtime.GOARCH,
+	}
+	if publicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(publicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("update: invalid built-in release key")
+		}
+		u.publicKey = key
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u, nil
+}
+
+// Signed reports whether releases must carry a valid signature.
+func (u *Updater) Signed() bool {
+	return len(u.publicKey) > 0
+}
+
+// Latest fetches the newest published release.
+func (u *Updater) Latest(ctx context.Context) (Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", u.apiURL, u.repo)
+	body, err := u.get(ctx, url, "application/vnd.github+json")
+	if err != nil {
+		return Release{}, fmt.Errorf("checking for updates: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return Release{}, fmt.Errorf("checking for updates: decoding release: %w", err)
+	}
+	if release.TagName == "" {
+		return Release{}, errors.New("checking for updates: release has no tag")
+	}
+	return release, nil
+}
+
+// AssetName is the release asset holding the binary for this platform.
+func (u *Updater) AssetName() string {
+	name := fmt.Sprintf("viewscreen_%s_%s", u.goos, u.goarch)
+	if u.goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Install downloads and verifies the release binary for this platform and
+// replaces the executable with it.
+func (u *Updater) Install(ctx context.Context, release Release) error {
+	name := u.AssetName()
+	binAsset, ok := release.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s", release.TagName, u.goos, u.goarch)
+	}
+	sumsAsset, ok := release.asset(checksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s", release.TagName, checksumsAsset)
+	}
+
+	sums, err := u.get(ctx, sumsAsset.URL, "")
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", checksumsAsset, err)
+	}
+	if u.Signed() {
+		if err := u.verifySignature(ctx, release, sums); err != nil {
+			return err
+		}
+	}
+	want, err := checksumFor(sums, name)
+	if err != nil {
+		return err
+	}
+
+	binary, err := u.get(ctx, binAsset.URL, "")
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", name, err)
+	}
+	got := sha256.Sum256(binary)
+	if !bytes.Equal(got[:], want) {
+		return fmt.Errorf("checksum mismatch for %s: got %x, want %x", name, got, want)
+	}
+
+	return u.replace(binary)
+}
+
+// verifySignature checks checksums.txt against its ed25519 signature. The
+// signature asset may be raw bytes or base64.
+func (u *Updater) verifySignature(ctx context.Context, release Release, sums []byte) error {
+	sigAsset, ok := release.asset(signatureAsset)
+	if !ok {
+		return fmt.Errorf("release %s is not signed (no %s)", release.TagName, signatureAsset)
+	}
+	sig, err := u.get(ctx, sigAsset.URL, "")
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", signatureAsset, err)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("decoding %s: %w", signatureAsset, err)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(u.publicKey, sums, sig) {
+		return fmt.Errorf("signature verification failed for release %s", release.TagName)
+	}
+	return nil
+}
+
+// checksumFor finds name's SHA-256 in sha256sum-formatted data
+// ("<hex>  <name>", optionally "*<name>" for binary mode).
+func checksumFor(sums []byte, name string) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum, err := hex.DecodeString(fields[0])
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("malformed checksum for %s", name)
+		}
+		return sum, nil
+	}
+	return nil, fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+}
+
+// replace writes binary next to the executable and renames it into place,
+// so a failed write never leaves a truncated binary behind.
+func (u *Updater) replace(binary []byte) error {
+	exe := u.executable
+	if exe == "" {
+		path, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("locating executable: %w", err)
+		}
+		exe = path
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".viewscreen-update-*")
+	if err != nil {
+		return fmt.Errorf("writing update: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing update: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("writing update: %w", err)
+	}
+
+	// Windows cannot replace a running executable, but it can rename it.
+	if u.goos == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("replacing %s: %w", exe, err)
+		}
+	}
+	if err := os.Rename(tmpPath, exe); err != nil {
+		return fmt.Errorf("replacing %s: %w", exe, err)
+	}
+	return nil
+}
+
+// get fetches url and returns the body, failing on non-2xx responses.
+func (u *Updater) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxAssetSize {
+		return nil, fmt.Errorf("GET %s: response too large", url)
+	}
+	return body, nil
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// "vMAJOR.MINOR.PATCH" with an optional "-prerelease" suffix. A current
+// version that does not parse (e.g. "dev") is treated as older than any
+// release.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range 3 {
+		if l.parts[i] != c.parts[i] {
+			return l.parts[i] > c.parts[i]
+		}
+	}
+	// A release outranks its prereleases; prereleases compare lexically.
+	switch {
+	case l.pre == c.pre:
+		return false
+	case l.pre == "":
+		return true
+	case c.pre == "":
+		return false
+	default:
+		return l.pre > c.pre
+	}
+}
+
+type semver struct {
+	parts [3]int
+	pre   string
+}
+
+func parseVersion(s string) (semver, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, pre, _ := strings.Cut(s, "-")
+	fields := strings.Split(core, ".")
+	if len(fields) != 3 {
+		return semver{}, false
+	}
+	var v semver
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		v.parts[i] = n
+	}
+	v.pre = pre
+	return v, true
+}
@@ -0,0 +1,224 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.10.0", false},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.2.0", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.2", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.1", "v1.2.0", false},
+		{"1.2.0", "v1.1.0+meta", true},
+		{"v1.0.0", "dev", true},
+		{"latest", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestChecksumFor(t *testing.T) {
+	sum := sha256.Sum256([]byte("bin"))
+	sums := fmt.Sprintf("%x  other\n%x *viewscreen_linux_amd64\n", sha256.Sum256(nil), sum)
+
+	got, err := checksumFor([]byte(sums), "viewscreen_linux_amd64")
+	if err != nil {
+		t.Fatalf("checksumFor: %v", err)
+	}
+	if string(got) != string(sum[:]) {
+		t.Errorf("checksumFor returned the wrong sum")
+	}
+	if _, err := checksumFor([]byte(sums), "missing"); err == nil {
+		t.Error("expected error for missing entry")
+	}
+	if _, err := checksumFor([]byte("zz  bad\n"), "bad"); err == nil {
+		t.Error("expected error for malformed entry")
+	}
+}
+
+func TestUpdater_AssetName(t *testing.T) {
+	u, _ := New(WithPlatform("windows", "arm64"))
+	if got := u.AssetName(); got != "viewscreen_windows_arm64.exe" {
+		t.Errorf("AssetName() = %q", got)
+	}
+	u, _ = New(WithPlatform("darwin", "arm64"))
+	if got := u.AssetName(); got != "viewscreen_darwin_arm64" {
+		t.Errorf("AssetName() = %q", got)
+	}
+}
+
+// releaseServer serves a fake GitHub API and release assets. sig, when
+// non-nil, is published as checksums.txt.sig.
+func releaseServer(t *testing.T, binary []byte, sums string, sig []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/repos/o/r/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		assets := fmt.Sprintf(`{"name":"viewscreen_linux_amd64","browser_download_url":"%[1]s/bin"},
+			{"name":"checksums.txt","browser_download_url":"%[1]s/sums"}`, srv.URL)
+		if sig != nil {
+			assets += fmt.Sprintf(`,{"name":"checksums.txt.sig","browser_download_url":"%s/sig"}`, srv.URL)
+		}
+		fmt.Fprintf(w, `{"tag_name":"v9.0.0","assets":[%s]}`, assets)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, sums) })
+	mux.HandleFunc("/sig", func(w http.ResponseWriter, r *http.Request) { w.Write(sig) })
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func fakeExecutable(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "viewscreen")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return exe
+}
+
+func TestUpdater_Install(t *testing.T) {
+	binary := []byte("new binary")
+	sums := fmt.Sprintf("%x  viewscreen_linux_amd64\n", sha256.Sum256(binary))
+	srv := releaseServer(t, binary, sums, nil)
+	exe := fakeExecutable(t)
+
+	u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithExecutable(exe), WithPlatform("linux", "amd64"))
+	release, err := u.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	if release.TagName != "v9.0.0" {
+		t.Errorf("TagName = %q", release.TagName)
+	}
+	if err := u.Install(context.Background(), release); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+
+	data, _ := os.ReadFile(exe)
+	if string(data) != "new binary" {
+		t.Errorf("executable = %q, want replaced", data)
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm() != 0o755 {
+		t.Errorf("mode = %v, want 0755 preserved", info.Mode().Perm())
+	}
+}
+
+func TestUpdater_Install_ChecksumMismatch(t *testing.T) {
+	sums := fmt.Sprintf("%x  viewscreen_linux_amd64\n", sha256.Sum256([]byte("something else")))
+	srv := releaseServer(t, []byte("tampered"), sums, nil)
+	exe := fakeExecutable(t)
+
+	u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithExecutable(exe), WithPlatform("linux", "amd64"))
+	release, _ := u.Latest(context.Background())
+	err := u.Install(context.Background(), release)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Errorf("executable changed despite failed verification: %q", data)
+	}
+}
+
+func TestUpdater_Install_Signature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("signed binary")
+	sums := fmt.Sprintf("%x  viewscreen_linux_amd64\n", sha256.Sum256(binary))
+
+	t.Run("valid base64 signature", func(t *testing.T) {
+		sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(sums))))
+		srv := releaseServer(t, binary, sums, sig)
+		exe := fakeExecutable(t)
+		u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithExecutable(exe), WithPlatform("linux", "amd64"), WithPublicKey(pub))
+		release, _ := u.Latest(context.Background())
+		if err := u.Install(context.Background(), release); err != nil {
+			t.Fatalf("Install: %v", err)
+		}
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		sig := ed25519.Sign(priv, []byte("different"))
+		srv := releaseServer(t, binary, sums, sig)
+		exe := fakeExecutable(t)
+		u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithExecutable(exe), WithPlatform("linux", "amd64"), WithPublicKey(pub))
+		release, _ := u.Latest(context.Background())
+		if err := u.Install(context.Background(), release); err == nil || !strings.Contains(err.Error(), "signature") {
+			t.Fatalf("expected signature failure, got %v", err)
+		}
+	})
+
+	t.Run("unsigned release", func(t *testing.T) {
+		srv := releaseServer(t, binary, sums, nil)
+		u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithExecutable(fakeExecutable(t)), WithPlatform("linux", "amd64"), WithPublicKey(pub))
+		release, _ := u.Latest(context.Background())
+		if err := u.Install(context.Background(), release); err == nil || !strings.Contains(err.Error(), "not signed") {
+			t.Fatalf("expected unsigned release to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestUpdater_Install_NoPlatformBuild(t *testing.T) {
+	srv := releaseServer(t, nil, "", nil)
+	u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"), WithPlatform("plan9", "386"))
+	release, _ := u.Latest(context.Background())
+	if err := u.Install(context.Background(), release); err == nil || !strings.Contains(err.Error(), "plan9/386") {
+		t.Fatalf("expected missing build error, got %v", err)
+	}
+}
+
+func TestUpdater_Latest_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	u, _ := New(WithAPIURL(srv.URL), WithRepo("o/r"))
+	if _, err := u.Latest(context.Background()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected 404 error, got %v", err)
+	}
+}
+
+func TestNew_ClientTimeout(t *testing.T) {
+	u, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.client.Timeout <= 0 {
+		t.Error("expected the default client to time out")
+	}
+}
+
+func TestCurrentVersion(t *testing.T) {
+	orig := version
+	t.Cleanup(func() { version = orig })
+
+	version = "v1.2.3"
+	if got := CurrentVersion(); got != "v1.2.3" {
+		t.Errorf("CurrentVersion() = %q, want linked version", got)
+	}
+	version = ""
+	if got := CurrentVersion(); got == "" {
+		t.Error("expected a fallback version")
+	}
+}
@@ -0,0 +1,181 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// BashResult represents the tool_use_result for Bash commands.
+type BashResult struct {
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	Interrupted bool   `json:"interrupted"`
+	IsImage     bool   `json:"isImage"`
+	// ExitCode is non-zero when the command failed. Claude reports failures
+	// as an "Error: Exit code N" string rather than an object, which
+	// ParseBashResult folds into this field.
+	ExitCode int `json:"exitCode"`
+}
+
+// bashExitPrefix starts the string tool_use_result of a failed Bash command.
+const bashExitPrefix = "Error: Exit code "
+
+// ParseBashResult parses a Bash tool_use_result. It accepts the object form
+// (stdout/stderr) and the "Error: Exit code N\n<output>" string reported for
+// failing commands. The bool is false when the result is neither.
+func ParseBashResult(toolUseResult json.RawMessage) (BashResult, bool) {
+	if len(toolUseResult) == 0 {
+		return BashResult{}, false
+	}
+
+	var text string
+	if err := json.Unmarshal(toolUseResult, &text); err == nil {
+		rest, ok := strings.CutPrefix(text, bashExitPrefix)
+		if !ok {
+			return BashResult{}, false
+		}
+		codeStr, output, _ := strings.Cut(rest, "\n")
+		code, err := strconv.Atoi(strings.TrimSpace(codeStr))
+		if err != nil {
+			return BashResult{}, false
+		}
+		return BashResult{Stdout: output, ExitCode: code}, true
+	}
+
+	// Pointer fields distinguish a Bash result with empty output from an
+	// unrelated object that merely lacks stdout.
+	var probe struct {
+		Stdout *string `json:"stdout"`
+		Stderr *string `json:"stderr"`
+	}
+	if err := json.Unmarshal(toolUseResult, &probe); err != nil || (probe.Stdout == nil && probe.Stderr == nil) {
+		return BashResult{}, false
+	}
+	var result BashResult
+	if err := json.Unmarshal(toolUseResult, &result); err != nil {
+		return BashResult{}, false
+	}
+	return result, true
+}
+
+// Failed reports whether the command exited non-zero.
+func (b BashResult) Failed() bool {
+	return b.ExitCode != 0
+}
+
+// BashRenderer handles rendering of Bash results, keeping stdout and stderr
+// apart and surfacing the exit status.
+type BashRenderer struct {
+	styleApplier render.StyleApplier
+	config       config.Provider
+}
+
+// NewBashRenderer creates a new BashRenderer with the given dependencies.
+func NewBashRenderer(styleApplier render.StyleApplier, cfg config.Provider) *BashRenderer {
+	return &BashRenderer{
+		styleApplier: styleApplier,
+		config:       cfg,
+	}
+}
+
+// TryRender implements ResultRenderer interface.
+// Bash output follows the tool output verbosity policy: a one-line summary
+// ("exit 1 · 42 lines") by default, and all of stdout and stderr from -v
+// up. Stderr is shown in warning style.
+// Returns true if it was a Bash result and was rendered, false otherwise.
+func (br *BashRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMessage) bool {
+	// Other tools' results can carry stdout and stderr too, and the
+	// error-string form is shared with their failures, so only known Bash
+	// calls are claimed.
+	if ctx.ToolName != "Bash" {
+		return false
+	}
+	result, ok := ParseBashResult(toolUseResult)
+	if !ok {
+		return false
+	}
+
+	maxLines := -1
+	if !br.config.IsVerbose() {
+		fmt.Fprintf(ctx.Output, "%s%s\n", ctx.OutputPrefix, br.summary(result))
+		return true
+	}
+
+	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
+	wrote := br.writeStream(pw, result.Stdout, maxLines, func(s string) string { return s })
+	wrote = br.writeStream(pw, result.Stderr, maxLines, br.styleApplier.WarningText) || wrote
+	if !wrote {
+		pw.WriteLine(br.summary(result))
+	} else if status := br.status(result); status != "" {
+		pw.WriteLine(status)
+	}
+	return true
+}
+
+// writeStream writes up to maxLines (-1 for no limit) of output styled by
+// styleFn, followed by a truncation indicator. It returns false when the
+// output is empty.
+func (br *BashRenderer) writeStream(pw *textutil.PrefixedWriter, output string, maxLines int, styleFn func(string) string) bool {
+	output = strings.TrimRight(output, "\n")
+	if strings.TrimSpace(output) == "" {
+		return false
+	}
+	truncated, remaining := textutil.TruncateLines(output, maxLines)
+	for _, line := range strings.Split(truncated, "\n") {
+		pw.WriteLine(styleFn(line))
+	}
+	if remaining > 0 {
+		pw.WriteLinef("%s", br.styleApplier.MutedText(textutil.TruncationIndicator(remaining)))
+	}
+	return true
+}
+
+// status renders the exit code and interruption markers, or "" for a
+// command that completed successfully.
+func (br *BashRenderer) status(result BashResult) string {
+	var parts []string
+	if result.Failed() {
+		parts = append(parts, br.styleApplier.ErrorText(fmt.Sprintf("exit %d", result.ExitCode)))
+	}
+	if result.Interrupted {
+		parts = append(parts, br.styleApplier.WarningText("interrupted"))
+	}
+	return strings.Join(parts, br.styleApplier.MutedText(" · "))
+}
+
+// summary renders the compact one-line form, e.g. "exit 1 · 42 lines · 3
+// stderr".
+func (br *BashRenderer) summary(result BashResult) string {
+	var parts []string
+	if status := br.status(result); status != "" {
+		parts = append(parts, status)
+	}
+	stdout, stderr := countLines(result.Stdout), countLines(result.Stderr)
+	switch {
+	case result.IsImage:
+		parts = append(parts, br.styleApplier.MutedText("image"))
+	case stdout == 0 && stderr == 0:
+		parts = append(parts, br.styleApplier.MutedText("no output"))
+	case stdout > 0:
+		parts = append(parts, br.styleApplier.MutedText(fmt.Sprintf("%d lines", stdout)))
+	}
+	if stderr > 0 {
+		parts = append(parts, br.styleApplier.WarningText(fmt.Sprintf("%d stderr", stderr)))
+	}
+	return strings.Join(parts, br.styleApplier.MutedText(" · "))
+}
+
+// countLines counts the lines of output, ignoring a trailing newline.
+func countLines(s string) int {
+	s = strings.TrimRight(s, "\n")
+	if strings.TrimSpace(s) == "" {
+		return 0
+	}
+	return strings.Count(s, "\n") + 1
+}
@@ -0,0 +1,160 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/testutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+func TestParseBashResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   BashResult
+		wantOK bool
+	}{
+		{
+			name:   "object form",
+			input:  `{"stdout":"a\nb","stderr":"warn","interrupted":false,"isImage":false}`,
+			want:   BashResult{Stdout: "a\nb", Stderr: "warn"},
+			wantOK: true,
+		},
+		{
+			name:   "empty output",
+			input:  `{"stdout":"","stderr":""}`,
+			want:   BashResult{},
+			wantOK: true,
+		},
+		{
+			name:   "exit code string",
+			input:  `"Error: Exit code 2\nno such file"`,
+			want:   BashResult{Stdout: "no such file", ExitCode: 2},
+			wantOK: true,
+		},
+		{name: "other error string", input: `"Error: File does not exist."`},
+		{name: "unrelated object", input: `{"filePath":"/a.go"}`},
+		{name: "empty", input: ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseBashResult(json.RawMessage(tt.input))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func renderBash(t *testing.T, level int, toolName, toolUseResult string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: level, NoColorVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(mockCodeHighlighter{}),
+	)
+	r.SetToolContext(tools.ToolContext{ToolName: toolName})
+	r.Render(Event{
+		Message: Message{Role: "user", Content: []ToolResultContent{{
+			Type:       "tool_result",
+			RawContent: json.RawMessage(`"fallback content"`),
+		}}},
+		ToolUseResult: json.RawMessage(toolUseResult),
+	})
+	return buf.String()
+}
+
+func TestRenderer_Render_BashResult_Summary(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   []string
+	}{
+		{
+			name:   "success",
+			result: `{"stdout":"a\nb\nc\n","stderr":""}`,
+			want:   []string{"[MUTED:3 lines]"},
+		},
+		{
+			name:   "stderr counted separately",
+			result: `{"stdout":"a","stderr":"w1\nw2"}`,
+			want:   []string{"[MUTED:1 lines]", "[WARNING:2 stderr]"},
+		},
+		{
+			name:   "failure",
+			result: `"Error: Exit code 1\nx\ny"`,
+			want:   []string{"[ERROR:exit 1][MUTED: · ][MUTED:2 lines]"},
+		},
+		{
+			name:   "interrupted with no output",
+			result: `{"stdout":"","stderr":"","interrupted":true}`,
+			want:   []string{"[WARNING:interrupted]", "[MUTED:no output]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := renderBash(t, 0, "Bash", tt.result)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected %q in output, got: %q", want, output)
+				}
+			}
+			if strings.Contains(output, "fallback content") {
+				t.Errorf("expected Bash renderer to replace generic output, got: %q", output)
+			}
+			if strings.Count(output, "\n") != 1 {
+				t.Errorf("expected a single summary line, got: %q", output)
+			}
+		})
+	}
+}
+
+func TestRenderer_Render_BashResult_Verbose(t *testing.T) {
+	output := renderBash(t, 1, "Bash", `{"stdout":"1\n2\n3\n4\n5\n6\n7","stderr":"oops"}`)
+
+	if !strings.Contains(output, "1\n") || !strings.Contains(output, "7\n") {
+		t.Errorf("expected all of stdout, got: %q", output)
+	}
+	if strings.Contains(output, "more lines") {
+		t.Errorf("expected no truncation, got: %q", output)
+	}
+	if !strings.Contains(output, "[WARNING:oops]") {
+		t.Errorf("expected stderr in warning style, got: %q", output)
+	}
+	if strings.Contains(output, "[ERROR:") {
+		t.Errorf("expected no exit status for a successful command, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_BashResult_VeryVerboseFailure(t *testing.T) {
+	output := renderBash(t, 2, "Bash", `"Error: Exit code 127\ncommand not found"`)
+
+	if !strings.Contains(output, "command not found") {
+		t.Errorf("expected output lines, got: %q", output)
+	}
+	if !strings.HasSuffix(strings.TrimRight(output, "\n"), "[ERROR:exit 127]") {
+		t.Errorf("expected exit status after output, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_BashResult_ErrorStringOtherTool(t *testing.T) {
+	output := renderBash(t, 0, "Read", `"Error: Exit code 1\nnot bash"`)
+	if strings.Contains(output, "exit 1") {
+		t.Errorf("expected error strings from other tools to fall through, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_BashResult_ObjectOtherTool(t *testing.T) {
+	output := renderBash(t, 0, "mcp__shell__run", `{"stdout": "a\nb\nc", "stderr": ""}`)
+	if strings.Contains(output, "3 lines") || !strings.Contains(output, "1 lines") {
+		t.Errorf("expected stdout objects from other tools to fall through, got: %q", output)
+	}
+}
@@ -3,7 +3,9 @@ package user
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/charmbracelet/x/ansi"
 	"github.com/johnnyfreeman/viewscreen/config"
 	"github.com/johnnyfreeman/viewscreen/render"
 	"github.com/johnnyfreeman/viewscreen/textutil"
@@ -72,26 +74,47 @@ func (er *EditRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMess
 	}
 	numWidth := len(fmt.Sprintf("%d", maxLine))
 
+	maxLines := ctx.MaxLines(er.maxLines())
+	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
+	if er.config.DiffLayout() == config.DiffLayoutSideBySide && ctx.Width >= SideBySideMinWidth {
+		er.renderSideBySide(pw, editResult, numWidth, ctx.Width-ansi.StringWidth(ctx.OutputPrefix), maxLines)
+	} else {
+		er.renderUnified(pw, editResult, numWidth, maxLines)
+	}
+	return true
+}
+
+// maxLines returns the diff line limit: 10 lines by default, unlimited from
+// -v up (same policy as Write results).
+func (er *EditRenderer) maxLines() int {
+	if er.config.IsVerbose() {
+		return -1
+	}
+	return 10
+}
+
+// renderUnified renders hunks as a single column of context, removed and
+// added lines, up to maxLines rows (-1 for no limit).
+func (er *EditRenderer) renderUnified(pw *textutil.PrefixedWriter, editResult EditResult, numWidth, maxLines int) {
 	// Separator character for line numbers
 	sep := er.styleApplier.LineNumberSepRender("│")
-
-	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
 	lineCount := 0
 
 	for _, hunk := range editResult.StructuredPatch {
 		oldLine := hunk.OldStart
 		newLine := hunk.NewStart
+		lines := er.hunkLines(hunk)
+		shown := lines
+		if maxLines >= 0 {
+			shown = shown[:visibleLines(shown, maxLines-lineCount)]
+		}
+		styled := er.highlightHunk(shown, wordDiffSpans(lines), editResult.FilePath)
 
-		for _, line := range hunk.Lines {
+		for i, line := range lines {
 			if len(line) == 0 {
 				continue
 			}
 
-			// Write tools: -v = 10 lines, -vv = no limit
-			maxLines := 10
-			if er.config.GetVerboseLevel() >= 2 {
-				maxLines = -1
-			}
 			if maxLines >= 0 && lineCount >= maxLines {
 				// Count remaining lines
 				remaining := 0
@@ -102,51 +125,135 @@ func (er *EditRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMess
 				if remaining > 0 {
 					pw.WriteLinef("%s", er.styleApplier.MutedText(textutil.TruncationIndicator(remaining)))
 				}
-				return true
+				return
 			}
 
 			prefix := line[0]
-			content := line[1:] // Strip the +/- prefix
 
 			// Format line number and operation indicator
 			var lineNum string
-			var op string
 			switch prefix {
 			case '+':
 				// Added line: show new line number with + indicator
 				lineNum = fmt.Sprintf("%*d", numWidth, newLine)
-				op = er.styleApplier.SuccessText("+")
 				newLine++
 			case '-':
 				// Removed line: show old line number with - indicator
 				lineNum = fmt.Sprintf("%*d", numWidth, oldLine)
-				op = er.styleApplier.ErrorText("-")
 				oldLine++
 			default:
 				// Context line: show new line number with space
 				lineNum = fmt.Sprintf("%*d", numWidth, newLine)
-				op = " "
 				oldLine++
 				newLine++
 			}
 			lineNums := er.styleApplier.LineNumberRender(lineNum)
 
-			// Syntax highlight with appropriate background for diff lines
-			// HighlightFileWithBg uses the filename for language detection (via chroma)
-			var styled string
-			switch prefix {
-			case '+':
-				styled = er.highlighter.HighlightFileWithBg(content, editResult.FilePath, er.styleApplier.DiffAddBg())
-			case '-':
-				styled = er.highlighter.HighlightFileWithBg(content, editResult.FilePath, er.styleApplier.DiffRemoveBg())
-			default:
-				styled = er.highlighter.HighlightFile(content, editResult.FilePath)
-			}
-
 			// Output with separators: ⎿ 123 │ + code
-			pw.WriteLinef("%s %s %s %s", lineNums, sep, op, styled)
+			pw.WriteLinef("%s %s %s %s", lineNums, sep, er.opMarker(prefix), styled[i])
 			lineCount++
 		}
 	}
-	return true
+}
+
+// opMarker returns the styled +/- indicator for a diff line prefix.
+func (er *EditRenderer) opMarker(prefix byte) string {
+	switch prefix {
+	case '+':
+		return er.styleApplier.SuccessText("+")
+	case '-':
+		return er.styleApplier.ErrorText("-")
+	default:
+		return " "
+	}
+}
+
+// hunkLines returns the lines of hunk to render. With -show-whitespace
+// their content is marked with render.MarkWhitespace, before word diffing,
+// so a change in whitespace alone is emphasized like any other.
+func (er *EditRenderer) hunkLines(hunk PatchHunk) []string {
+	if !er.config.ShowWhitespace() {
+		return hunk.Lines
+	}
+	lines := make([]string, len(hunk.Lines))
+	for i, line := range hunk.Lines {
+		if len(line) > 0 {
+			lines[i] = line[:1] + render.MarkWhitespace(line[1:])
+		}
+	}
+	return lines
+}
+
+// highlightHunk syntax highlights the content of a hunk's lines (without the
+// +/- marker), indexed like lines, with the background for each line's
+// prefix. Paired changed lines get a stronger background on the changed
+// words from emphasis. With -no-diff-highlight only backgrounds are applied.
+func (er *EditRenderer) highlightHunk(lines []string, emphasis map[int][]render.Span, filePath string) []string {
+	if er.config.NoDiffHighlight() {
+		filePath = ""
+	}
+	return render.HighlightHunk(er.highlighter, lines, filePath, func(i int) render.LineStyle {
+		return er.lineStyle(lines[i][0], emphasis[i])
+	})
+}
+
+// lineStyle returns the background for a diff line prefix.
+func (er *EditRenderer) lineStyle(prefix byte, spans []render.Span) render.LineStyle {
+	switch prefix {
+	case '+':
+		return render.LineStyle{Bg: er.styleApplier.DiffAddBg(), EmphBg: er.styleApplier.DiffAddEmphBg(), Spans: spans}
+	case '-':
+		return render.LineStyle{Bg: er.styleApplier.DiffRemoveBg(), EmphBg: er.styleApplier.DiffRemoveEmphBg(), Spans: spans}
+	default:
+		return render.LineStyle{}
+	}
+}
+
+// visibleLines returns how many of lines are needed to show budget non-empty
+// lines, so highlighting can stop at the truncation point.
+func visibleLines(lines []string, budget int) int {
+	shown := 0
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if shown >= budget {
+			return i
+		}
+		shown++
+	}
+	return len(lines)
+}
+
+// wordDiffSpans pairs each run of removed lines in a hunk with the run of
+// added lines that follows it, line by line, and returns the changed-word
+// spans (relative to the line content after the +/- marker) keyed by index
+// into lines. Lines without a similar counterpart are omitted.
+func wordDiffSpans(lines []string) map[int][]render.Span {
+	spans := make(map[int][]render.Span)
+	for i := 0; i < len(lines); {
+		if !strings.HasPrefix(lines[i], "-") {
+			i++
+			continue
+		}
+		removedStart := i
+		for i < len(lines) && strings.HasPrefix(lines[i], "-") {
+			i++
+		}
+		addedStart := i
+		for i < len(lines) && strings.HasPrefix(lines[i], "+") {
+			i++
+		}
+		pairs := min(addedStart-removedStart, i-addedStart)
+		for k := 0; k < pairs; k++ {
+			oldIdx, newIdx := removedStart+k, addedStart+k
+			oldSpans, newSpans := render.WordDiff(lines[oldIdx][1:], lines[newIdx][1:])
+			if oldSpans == nil && newSpans == nil {
+				continue
+			}
+			spans[oldIdx] = oldSpans
+			spans[newIdx] = newSpans
+		}
+	}
+	return spans
 }
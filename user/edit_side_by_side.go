@@ -0,0 +1,149 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// SideBySideMinWidth is the narrowest output width at which Edit diffs use
+// the side-by-side layout. Narrower terminals fall back to the unified view.
+const SideBySideMinWidth = 120
+
+// diffSide is one half of a side-by-side row. A zero num means the side is
+// blank (e.g. an added line with no removed counterpart).
+type diffSide struct {
+	num    int
+	prefix byte
+	idx    int // index into the hunk's lines, for word-diff emphasis
+}
+
+type diffRow struct {
+	old, new diffSide
+}
+
+// sideBySideRows aligns a hunk into rows: context lines appear on both sides,
+// and each run of removed lines is paired with the run of added lines that
+// follows it.
+func sideBySideRows(hunk PatchHunk) []diffRow {
+	var rows []diffRow
+	oldLine, newLine := hunk.OldStart, hunk.NewStart
+	lines := hunk.Lines
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if len(line) == 0 {
+			i++
+			continue
+		}
+		if line[0] != '-' && line[0] != '+' {
+			rows = append(rows, diffRow{
+				old: diffSide{num: oldLine, prefix: ' ', idx: i},
+				new: diffSide{num: newLine, prefix: ' ', idx: i},
+			})
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		var removed, added []int
+		for i < len(lines) && strings.HasPrefix(lines[i], "-") {
+			removed = append(removed, i)
+			i++
+		}
+		for i < len(lines) && strings.HasPrefix(lines[i], "+") {
+			added = append(added, i)
+			i++
+		}
+		for k := 0; k < max(len(removed), len(added)); k++ {
+			var row diffRow
+			if k < len(removed) {
+				row.old = diffSide{num: oldLine, prefix: '-', idx: removed[k]}
+				oldLine++
+			}
+			if k < len(added) {
+				row.new = diffSide{num: newLine, prefix: '+', idx: added[k]}
+				newLine++
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// renderSideBySide renders hunks with old lines on the left and new lines on
+// the right. width is the space available after the output prefix.
+func (er *EditRenderer) renderSideBySide(pw *textutil.PrefixedWriter, editResult EditResult, numWidth, width, maxLines int) {
+	sep := er.styleApplier.LineNumberSepRender("│")
+	// Each half is "123 │ + content"; halves are joined by " │ ".
+	halfWidth := (width - 3) / 2
+	contentWidth := halfWidth - numWidth - 5
+	if contentWidth < 10 {
+		er.renderUnified(pw, editResult, numWidth, maxLines)
+		return
+	}
+
+	var hunkRows [][]diffRow
+	totalRows := 0
+	for _, hunk := range editResult.StructuredPatch {
+		rows := sideBySideRows(hunk)
+		hunkRows = append(hunkRows, rows)
+		totalRows += len(rows)
+	}
+
+	rowCount := 0
+	for h, hunk := range editResult.StructuredPatch {
+		// Tabs are expanded so column widths are predictable, and word diffs
+		// are computed on the expanded text so emphasis spans line up.
+		// Only the lines of rows that will be shown are highlighted.
+		lines := er.hunkLines(hunk)
+		expanded := make([]string, len(lines))
+		truncated := make([]string, len(lines))
+		for i, line := range lines {
+			if len(line) > 0 {
+				expanded[i] = line[:1] + strings.ReplaceAll(line[1:], "\t", "    ")
+				truncated[i] = line[:1] + ansi.Truncate(expanded[i][1:], contentWidth, "…")
+			}
+		}
+		rows := hunkRows[h]
+		if maxLines >= 0 {
+			rows = rows[:min(len(rows), max(maxLines-rowCount, 0))]
+		}
+		shown := 0
+		for _, row := range rows {
+			shown = max(shown, row.old.idx+1, row.new.idx+1)
+		}
+		styled := er.highlightHunk(truncated[:shown], wordDiffSpans(expanded), editResult.FilePath)
+
+		half := func(side diffSide, padded bool) string {
+			if side.num == 0 {
+				return strings.Repeat(" ", halfWidth)
+			}
+			content := truncated[side.idx][1:]
+			pad := ""
+			if padded {
+				pad = strings.Repeat(" ", max(0, contentWidth-ansi.StringWidth(content)))
+			}
+			lineNum := er.styleApplier.LineNumberRender(fmt.Sprintf("%*d", numWidth, side.num))
+			return fmt.Sprintf("%s %s %s %s%s", lineNum, sep, er.opMarker(side.prefix), styled[side.idx], pad)
+		}
+
+		for _, row := range hunkRows[h] {
+			if maxLines >= 0 && rowCount >= maxLines {
+				if remaining := totalRows - rowCount; remaining > 0 {
+					pw.WriteLinef("%s", er.styleApplier.MutedText(textutil.TruncationIndicator(remaining)))
+				}
+				return
+			}
+			line := half(row.old, true) + " " + sep
+			if row.new.num != 0 {
+				line += " " + half(row.new, false)
+			}
+			pw.WriteLine(line)
+			rowCount++
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// GlobResult represents the tool_use_result for Glob searches.
+type GlobResult struct {
+	Filenames  []string `json:"filenames"`
+	NumFiles   int      `json:"numFiles"`
+	Truncated  bool     `json:"truncated"`
+	DurationMs int      `json:"durationMs"`
+}
+
+// globDir is a directory node in the file tree. Chains of directories with
+// a single subdirectory and no files are collapsed into one node ("a/b/c").
+type globDir struct {
+	name  string
+	dirs  map[string]*globDir
+	files []string
+	count int // files in this directory and below
+}
+
+func newGlobDir(name string) *globDir {
+	return &globDir{name: name, dirs: make(map[string]*globDir)}
+}
+
+// buildGlobTree groups filenames into a directory tree rooted at their
+// common parent directory.
+func buildGlobTree(filenames []string) *globDir {
+	root := newGlobDir(commonDir(filenames))
+	for _, name := range filenames {
+		rel := strings.TrimPrefix(name, root.name)
+		rel = strings.TrimPrefix(rel, "/")
+		dir, file := path.Split(rel)
+
+		node := root
+		node.count++
+		for _, part := range strings.Split(strings.TrimSuffix(dir, "/"), "/") {
+			if part == "" {
+				continue
+			}
+			child, ok := node.dirs[part]
+			if !ok {
+				child = newGlobDir(part)
+				node.dirs[part] = child
+			}
+			node = child
+			node.count++
+		}
+		node.files = append(node.files, file)
+	}
+	for _, child := range root.dirs {
+		child.collapse()
+	}
+	return root
+}
+
+// collapse merges single-child directory chains into their parent.
+func (d *globDir) collapse() {
+	for len(d.files) == 0 && len(d.dirs) == 1 {
+		for _, only := range d.dirs {
+			d.name = d.name + "/" + only.name
+			d.dirs = only.dirs
+			d.files = only.files
+		}
+	}
+	for _, child := range d.dirs {
+		child.collapse()
+	}
+}
+
+// commonDir returns the longest directory prefix shared by all filenames, or
+// "" if they share none.
+func commonDir(filenames []string) string {
+	if len(filenames) == 0 {
+		return ""
+	}
+	prefix := path.Dir(filenames[0])
+	for _, name := range filenames[1:] {
+		for prefix != "." && prefix != "/" && !strings.HasPrefix(name, prefix+"/") {
+			prefix = path.Dir(prefix)
+		}
+	}
+	if prefix == "." {
+		return ""
+	}
+	return prefix
+}
+
+// countGlobDirs returns the number of distinct directories holding files.
+func countGlobDirs(filenames []string) int {
+	dirs := make(map[string]bool)
+	for _, name := range filenames {
+		dirs[path.Dir(name)] = true
+	}
+	return len(dirs)
+}
+
+// GlobRenderer handles rendering of Glob results as a file tree grouped by
+// directory.
+type GlobRenderer struct {
+	styleApplier render.StyleApplier
+	config       config.Provider
+}
+
+// NewGlobRenderer creates a new GlobRenderer with the given dependencies.
+func NewGlobRenderer(styleApplier render.StyleApplier, cfg config.Provider) *GlobRenderer {
+	return &GlobRenderer{
+		styleApplier: styleApplier,
+		config:       cfg,
+	}
+}
+
+// TryRender implements ResultRenderer interface.
+// Always shows a summary ("83 files in 12 dirs"); from -v up the directory
+// tree of every file follows.
+// Returns true if it was a Glob result and was rendered, false otherwise.
+func (gr *GlobRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMessage) bool {
+	if len(toolUseResult) == 0 || ctx.ToolName != "Glob" {
+		return false
+	}
+
+	var globResult GlobResult
+	if err := json.Unmarshal(toolUseResult, &globResult); err != nil || globResult.Filenames == nil {
+		return false
+	}
+
+	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
+	pw.WriteLine(gr.styleApplier.MutedText(globSummary(globResult)))
+
+	if gr.config.IsVerbose() {
+		gr.writeTree(pw, buildGlobTree(globResult.Filenames))
+	}
+	return true
+}
+
+// writeTree writes the tree, directories first, each with its file count.
+func (gr *GlobRenderer) writeTree(pw *textutil.PrefixedWriter, root *globDir) {
+	var lines []string
+	if root.name != "" {
+		lines = append(lines, strings.TrimSuffix(root.name, "/")+"/")
+	}
+	var walk func(d *globDir, depth int)
+	walk = func(d *globDir, depth int) {
+		indent := strings.Repeat("  ", depth)
+		names := make([]string, 0, len(d.dirs))
+		for name := range d.dirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := d.dirs[name]
+			lines = append(lines, fmt.Sprintf("%s%s/ %s", indent, child.name,
+				gr.styleApplier.MutedText(fmt.Sprintf("(%d)", child.count))))
+			walk(child, depth+1)
+		}
+		files := append([]string(nil), d.files...)
+		sort.Strings(files)
+		for _, file := range files {
+			lines = append(lines, indent+file)
+		}
+	}
+	depth := 0
+	if root.name != "" {
+		depth = 1
+	}
+	walk(root, depth)
+
+	for _, line := range lines {
+		pw.WriteLine(line)
+	}
+}
+
+// globSummary describes the result, e.g. "83 files in 12 dirs" or, when
+// Glob truncated its output, "100 of 250 files in 12 dirs".
+func globSummary(result GlobResult) string {
+	shown := len(result.Filenames)
+	if shown == 0 {
+		return "No files found"
+	}
+	files := plural(shown, "file", "files")
+	if result.Truncated && result.NumFiles > shown {
+		files = fmt.Sprintf("%d of %d files", shown, result.NumFiles)
+	}
+	return fmt.Sprintf("%s in %s", files, plural(countGlobDirs(result.Filenames), "dir", "dirs"))
+}
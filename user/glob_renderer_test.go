@@ -0,0 +1,117 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/testutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+func TestCommonDir(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"/p/a/x.go", "/p/a/y.go"}, "/p/a"},
+		{[]string{"/p/a/x.go", "/p/b/y.go"}, "/p"},
+		{[]string{"/p/ab/x.go", "/p/a/y.go"}, "/p"},
+		{[]string{"/a.go", "/b/c.go"}, "/"},
+		{[]string{"main.go", "config/config.go"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := commonDir(tt.files); got != tt.want {
+			t.Errorf("commonDir(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+}
+
+func renderGlob(t *testing.T, level int, toolName, toolUseResult string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: level, NoColorVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(mockCodeHighlighter{}),
+	)
+	r.SetToolContext(tools.ToolContext{ToolName: toolName})
+	r.Render(Event{
+		Message:       Message{Role: "user", Content: []ToolResultContent{{Type: "tool_result", RawContent: json.RawMessage(`"raw list"`)}}},
+		ToolUseResult: json.RawMessage(toolUseResult),
+	})
+	return buf.String()
+}
+
+const globResultJSON = `{"filenames":[
+	"/p/main.go",
+	"/p/config/config.go",
+	"/p/internal/deep/nested/a.go",
+	"/p/internal/deep/nested/b.go",
+	"/p/user/user.go",
+	"/p/user/glob.go"
+],"numFiles":6,"truncated":false}`
+
+func TestRenderer_Render_GlobResult_Summary(t *testing.T) {
+	output := renderGlob(t, 0, "Glob", globResultJSON)
+	if !strings.Contains(output, "[MUTED:6 files in 4 dirs]") {
+		t.Errorf("expected file/dir summary, got: %q", output)
+	}
+	if strings.Contains(output, "main.go") || strings.Contains(output, "raw list") {
+		t.Errorf("expected only the summary by default, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_GlobResult_Tree(t *testing.T) {
+	output := renderGlob(t, 1, "Glob", globResultJSON)
+	for _, want := range []string{
+		"/p/\n",
+		"  config/ [MUTED:(1)]\n",
+		"    config.go\n",
+		"  internal/deep/nested/ [MUTED:(2)]\n",
+		"    a.go\n",
+		"  user/ [MUTED:(2)]\n",
+		"    glob.go\n",
+		"  main.go\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in tree, got:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "user/") > strings.Index(output, "main.go") {
+		t.Errorf("expected directories before files, got:\n%s", output)
+	}
+}
+
+func TestRenderer_Render_GlobResult_TruncatedAndEmpty(t *testing.T) {
+	output := renderGlob(t, 0, "Glob", `{"filenames":["a.go","b/c.go"],"numFiles":78,"truncated":true}`)
+	if !strings.Contains(output, "[MUTED:2 of 78 files in 2 dirs]") {
+		t.Errorf("expected truncated summary, got: %q", output)
+	}
+
+	output = renderGlob(t, 2, "Glob", `{"filenames":[],"numFiles":0,"truncated":false}`)
+	if !strings.Contains(output, "[MUTED:No files found]") {
+		t.Errorf("expected empty summary, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_GlobResult_LongTreeNotTruncated(t *testing.T) {
+	var files []string
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		files = append(files, `"/p/`+name+`.go"`)
+	}
+	output := renderGlob(t, 1, "Glob", `{"filenames":[`+strings.Join(files, ",")+`]}`)
+	if !strings.Contains(output, "l.go") || strings.Contains(output, "more lines") {
+		t.Errorf("expected the full tree, got:\n%s", output)
+	}
+}
+
+func TestRenderer_Render_GlobResult_OtherTool(t *testing.T) {
+	output := renderGlob(t, 0, "Read", globResultJSON)
+	if strings.Contains(output, "files in") {
+		t.Errorf("expected Glob renderer to skip other tools, got: %q", output)
+	}
+}
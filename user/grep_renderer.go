@@ -0,0 +1,321 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// Grep output modes reported in GrepResult.Mode.
+const (
+	GrepModeContent = "content"
+	GrepModeFiles   = "files_with_matches"
+	GrepModeCount   = "count"
+)
+
+// GrepResult represents the tool_use_result for Grep searches.
+type GrepResult struct {
+	Mode       string   `json:"mode"`
+	NumFiles   int      `json:"numFiles"`
+	Filenames  []string `json:"filenames"`
+	Content    string   `json:"content"`
+	NumLines   int      `json:"numLines"`
+	NumMatches int      `json:"numMatches"`
+}
+
+// grepLine is one parsed line of content-mode output. File and Num are empty
+// when the search did not report them (single-file searches, no -n).
+type grepLine struct {
+	file    string
+	num     int
+	text    string
+	context bool // a -A/-B/-C context line rather than a match
+	sep     bool // the "--" separator between context groups
+}
+
+var (
+	grepFileLine    = regexp.MustCompile(`^(.*?):(\d+):(.*)$`)
+	grepFileContext = regexp.MustCompile(`^(.*?)-(\d+)-(.*)$`)
+	grepLineOnly    = regexp.MustCompile(`^(\d+):(.*)$`)
+	grepContextOnly = regexp.MustCompile(`^(\d+)-(.*)$`)
+	// grepFileOnly matches "path:text" output without line numbers. The path
+	// must look like one so ordinary text containing a colon is not split.
+	grepFileOnly = regexp.MustCompile(`^([^\s:]*[/.][^\s:]*):(.*)$`)
+	grepCount    = regexp.MustCompile(`^(.*):(\d+)$`)
+)
+
+// parseGrepContent splits content-mode output into lines. Only the output of
+// a search with lineNumbers (-n) is split at line numbers, as plain text such
+// as "a-2024-01" or "time: 12:30:00" would look like a file and line number.
+// Numbered lines are tried without a file name first, for single-file
+// searches.
+func parseGrepContent(content string, lineNumbers bool) []grepLine {
+	var lines []grepLine
+	for _, raw := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		if raw == "--" {
+			lines = append(lines, grepLine{sep: true})
+			continue
+		}
+		var line grepLine
+		switch {
+		case lineNumbers && matchInto(grepLineOnly, raw, &line, false):
+		case lineNumbers && matchInto(grepContextOnly, raw, &line, true):
+		case lineNumbers && matchInto(grepFileLine, raw, &line, false):
+		case lineNumbers && matchInto(grepFileContext, raw, &line, true):
+		default:
+			if m := grepFileOnly.FindStringSubmatch(raw); m != nil {
+				line = grepLine{file: m[1], text: m[2]}
+			} else {
+				line = grepLine{text: raw}
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// matchInto fills line from a "[file<sep>]num<sep>text" match.
+func matchInto(re *regexp.Regexp, raw string, line *grepLine, context bool) bool {
+	m := re.FindStringSubmatch(raw)
+	if m == nil {
+		return false
+	}
+	if len(m) == 4 {
+		line.file, m = m[1], m[1:]
+	}
+	line.num, _ = strconv.Atoi(m[1])
+	line.text = m[2]
+	line.context = context
+	return true
+}
+
+// GrepRenderer handles rendering of Grep results, grouping matches by file
+// and highlighting the searched pattern.
+type GrepRenderer struct {
+	styleApplier render.StyleApplier
+	config       config.Provider
+}
+
+// NewGrepRenderer creates a new GrepRenderer with the given dependencies.
+func NewGrepRenderer(styleApplier render.StyleApplier, cfg config.Provider) *GrepRenderer {
+	return &GrepRenderer{
+		styleApplier: styleApplier,
+		config:       cfg,
+	}
+}
+
+// TryRender implements ResultRenderer interface.
+// Always shows a summary ("12 matches in 3 files"); from -v up the result
+// lines follow, grouped under their file with line numbers in the diff
+// gutter style.
+// Returns true if it was a Grep result and was rendered, false otherwise.
+func (gr *GrepRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMessage) bool {
+	if len(toolUseResult) == 0 {
+		return false
+	}
+
+	var grepResult GrepResult
+	if err := json.Unmarshal(toolUseResult, &grepResult); err != nil {
+		return false
+	}
+	if grepResult.Mode != GrepModeContent && grepResult.Mode != GrepModeFiles && grepResult.Mode != GrepModeCount {
+		return false
+	}
+
+	maxLines := 0 // 0 = summary only, -1 = no limit
+	if gr.config.IsVerbose() {
+		maxLines = -1
+	}
+
+	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
+	switch grepResult.Mode {
+	case GrepModeContent:
+		lines := parseGrepContent(grepResult.Content, ctx.LineNumbers)
+		if strings.TrimSpace(grepResult.Content) == "" {
+			lines = nil
+		}
+		pw.WriteLine(gr.styleApplier.MutedText(contentSummary(lines)))
+		if maxLines != 0 {
+			gr.writeContent(pw, lines, compilePattern(ctx.Pattern), maxLines)
+		}
+	case GrepModeFiles:
+		summary := "Found " + plural(len(grepResult.Filenames), "file", "files")
+		if len(grepResult.Filenames) == 0 {
+			summary = "No matches"
+		}
+		pw.WriteLine(gr.styleApplier.MutedText(summary))
+		if maxLines != 0 {
+			gr.writeList(pw, grepResult.Filenames, maxLines, func(name string) string { return name })
+		}
+	case GrepModeCount:
+		counts := strings.Split(strings.TrimRight(grepResult.Content, "\n"), "\n")
+		if strings.TrimSpace(grepResult.Content) == "" {
+			counts = nil
+		}
+		total := 0
+		for _, c := range counts {
+			if m := grepCount.FindStringSubmatch(c); m != nil {
+				n, _ := strconv.Atoi(m[2])
+				total += n
+			}
+		}
+		summary := fmt.Sprintf("%s in %s", plural(total, "match", "matches"), plural(len(counts), "file", "files"))
+		if len(counts) == 0 {
+			summary = "No matches"
+		}
+		pw.WriteLine(gr.styleApplier.MutedText(summary))
+		if maxLines != 0 {
+			gr.writeList(pw, counts, maxLines, func(c string) string {
+				if m := grepCount.FindStringSubmatch(c); m != nil {
+					return m[1] + " " + gr.styleApplier.MutedText("("+m[2]+")")
+				}
+				return c
+			})
+		}
+	}
+	return true
+}
+
+// writeContent writes up to maxLines (-1 for no limit) match and context
+// lines, with a file heading whenever the file changes.
+func (gr *GrepRenderer) writeContent(pw *textutil.PrefixedWriter, lines []grepLine, pattern *regexp.Regexp, maxLines int) {
+	numWidth := 0
+	for _, line := range lines {
+		numWidth = max(numWidth, len(strconv.Itoa(line.num)))
+	}
+	sep := gr.styleApplier.LineNumberSepRender("│")
+
+	file := ""
+	for i, line := range lines {
+		if maxLines >= 0 && i >= maxLines {
+			pw.WriteLinef("%s", gr.styleApplier.MutedText(textutil.TruncationIndicator(len(lines)-i)))
+			return
+		}
+		if line.sep {
+			pw.WriteLine(gr.styleApplier.LineNumberRender(fmt.Sprintf("%*s", numWidth, "⋮")))
+			continue
+		}
+		if line.file != "" && line.file != file {
+			file = line.file
+			pw.WriteLine(line.file)
+		}
+
+		text := gr.styleApplier.MutedText(line.text)
+		if !line.context {
+			text = highlightMatches(line.text, pattern, gr.styleApplier.MatchText)
+		}
+		if line.num == 0 {
+			pw.WriteLine(text)
+			continue
+		}
+		lineNum := gr.styleApplier.LineNumberRender(fmt.Sprintf("%*d", numWidth, line.num))
+		pw.WriteLinef("%s %s %s", lineNum, sep, text)
+	}
+}
+
+// writeList writes up to maxLines (-1 for no limit) entries formatted by
+// format.
+func (gr *GrepRenderer) writeList(pw *textutil.PrefixedWriter, items []string, maxLines int, format func(string) string) {
+	for i, item := range items {
+		if maxLines >= 0 && i >= maxLines {
+			pw.WriteLinef("%s", gr.styleApplier.MutedText(textutil.TruncationIndicator(len(items)-i)))
+			return
+		}
+		pw.WriteLine(format(item))
+	}
+}
+
+// EmptySearch reports whether toolUseResult is a Grep or Glob result that
+// found nothing. Repeated empty searches often mean the agent is lost, so
+// they are counted in the session stats.
+func EmptySearch(toolName string, toolUseResult json.RawMessage) bool {
+	if len(toolUseResult) == 0 {
+		return false
+	}
+	switch toolName {
+	case "Glob":
+		var globResult GlobResult
+		return json.Unmarshal(toolUseResult, &globResult) == nil && globResult.Filenames != nil && len(globResult.Filenames) == 0
+	case "Grep":
+		var grepResult GrepResult
+		if json.Unmarshal(toolUseResult, &grepResult) != nil {
+			return false
+		}
+		switch grepResult.Mode {
+		case GrepModeContent, GrepModeCount:
+			return strings.TrimSpace(grepResult.Content) == ""
+		case GrepModeFiles:
+			return len(grepResult.Filenames) == 0
+		}
+	}
+	return false
+}
+
+// contentSummary describes content-mode results, e.g. "12 matches in 3
+// files". Context lines and separators are not counted.
+func contentSummary(lines []grepLine) string {
+	matches := 0
+	files := map[string]bool{}
+	for _, line := range lines {
+		if line.sep || line.context {
+			continue
+		}
+		matches++
+		if line.file != "" {
+			files[line.file] = true
+		}
+	}
+	if matches == 0 {
+		return "No matches"
+	}
+	if len(files) == 0 {
+		return plural(matches, "match", "matches")
+	}
+	return fmt.Sprintf("%s in %s", plural(matches, "match", "matches"), plural(len(files), "file", "files"))
+}
+
+// compilePattern compiles a Grep pattern for highlighting. Patterns Go's
+// regexp cannot parse (e.g. ripgrep-only syntax) disable highlighting.
+func compilePattern(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// highlightMatches applies styleFn to each match of pattern within text.
+func highlightMatches(text string, pattern *regexp.Regexp, styleFn func(string) string) string {
+	if pattern == nil {
+		return text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		sb.WriteString(text[last:loc[0]])
+		sb.WriteString(styleFn(text[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// plural formats a count with the singular or plural noun.
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
@@ -0,0 +1,169 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/testutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+func TestParseGrepContent(t *testing.T) {
+	got := parseGrepContent("a/b.go:12:func Foo() {\na/b.go-13-\tbody\n--\n7:plain match\n8:time: 12:30:00\nmain.go:no numbers\njust text: with colon\n", true)
+	want := []grepLine{
+		{file: "a/b.go", num: 12, text: "func Foo() {"},
+		{file: "a/b.go", num: 13, text: "\tbody", context: true},
+		{sep: true},
+		{num: 7, text: "plain match"},
+		{num: 8, text: "time: 12:30:00"},
+		{file: "main.go", text: "no numbers"},
+		{text: "just text: with colon"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGrepContent =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseGrepContent_NoLineNumbers(t *testing.T) {
+	got := parseGrepContent("a-2024-01\ntime: 12:30:00\nmain.go:text\n", false)
+	want := []grepLine{
+		{text: "a-2024-01"},
+		{text: "time: 12:30:00"},
+		{file: "main.go", text: "text"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGrepContent =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	mark := func(s string) string { return "<" + s + ">" }
+	tests := []struct {
+		text    string
+		pattern string
+		want    string
+	}{
+		{"foo bar foo", "foo", "<foo> bar <foo>"},
+		{"FooBar", "(?i)foo", "<Foo>Bar"},
+		{"abc", "x*", "abc"},
+		{"abc", "", "abc"},
+	}
+	for _, tt := range tests {
+		var re *regexp.Regexp
+		if tt.pattern != "" {
+			re = regexp.MustCompile(tt.pattern)
+		}
+		if got := highlightMatches(tt.text, re, mark); got != tt.want {
+			t.Errorf("highlightMatches(%q, %q) = %q, want %q", tt.text, tt.pattern, got, tt.want)
+		}
+	}
+	if compilePattern("(unclosed") != nil {
+		t.Error("expected invalid pattern to disable highlighting")
+	}
+}
+
+func renderGrep(t *testing.T, level int, pattern, toolUseResult string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: level, NoColorVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(mockCodeHighlighter{}),
+	)
+	r.SetToolContext(tools.ToolContext{ToolName: "Grep", Pattern: pattern, LineNumbers: true})
+	r.Render(Event{
+		Message:       Message{Role: "user", Content: []ToolResultContent{{Type: "tool_result", RawContent: json.RawMessage(`"raw dump"`)}}},
+		ToolUseResult: json.RawMessage(toolUseResult),
+	})
+	return buf.String()
+}
+
+func TestRenderer_Render_GrepResult_Content(t *testing.T) {
+	result := `{"mode":"content","numFiles":0,"filenames":[],"content":"a.go:3:func Run() {\na.go:9:\tRun()\nb.go:1:// Run it","numLines":3}`
+
+	summary := renderGrep(t, 0, "Run", result)
+	if !strings.Contains(summary, "[MUTED:3 matches in 2 files]") {
+		t.Errorf("expected match summary, got: %q", summary)
+	}
+	if strings.Contains(summary, "a.go") || strings.Contains(summary, "raw dump") {
+		t.Errorf("expected only the summary by default, got: %q", summary)
+	}
+
+	output := renderGrep(t, 2, "Run", result)
+	if strings.Count(output, "a.go") != 1 || !strings.Contains(output, "b.go") {
+		t.Errorf("expected matches grouped under one heading per file, got: %q", output)
+	}
+	if !strings.Contains(output, "[LN:3] │ func [MATCH:Run]() {") {
+		t.Errorf("expected gutter line with highlighted match, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_GrepResult_NotTruncated(t *testing.T) {
+	content := strings.Repeat("x.go:1:hit\\n", 8)
+	output := renderGrep(t, 1, "hit", `{"mode":"content","content":"`+content+`"}`)
+	if strings.Count(output, "[MATCH:hit]") != 8 {
+		t.Errorf("expected all 8 lines at -v, got: %q", output)
+	}
+	if strings.Contains(output, "more lines") {
+		t.Errorf("expected no truncation indicator, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_GrepResult_FilesAndCount(t *testing.T) {
+	files := renderGrep(t, 2, "", `{"mode":"files_with_matches","filenames":["a.go","b.go"],"numFiles":2}`)
+	if !strings.Contains(files, "[MUTED:Found 2 files]") || !strings.Contains(files, "b.go") {
+		t.Errorf("expected file list, got: %q", files)
+	}
+
+	count := renderGrep(t, 2, "", `{"mode":"count","content":"a.go:4\nb.go:1","numFiles":2}`)
+	if !strings.Contains(count, "[MUTED:5 matches in 2 files]") || !strings.Contains(count, "a.go [MUTED:(4)]") {
+		t.Errorf("expected per-file counts, got: %q", count)
+	}
+
+	empty := renderGrep(t, 0, "", `{"mode":"content","content":"","numFiles":0}`)
+	if !strings.Contains(empty, "[MUTED:No matches]") {
+		t.Errorf("expected no-match summary, got: %q", empty)
+	}
+
+	for _, result := range []string{
+		`{"mode":"files_with_matches","filenames":[],"numFiles":0}`,
+		`{"mode":"count","content":"","numFiles":0}`,
+	} {
+		if got := renderGrep(t, 2, "", result); !strings.Contains(got, "[MUTED:No matches]") {
+			t.Errorf("expected no-match summary for %s, got: %q", result, got)
+		}
+	}
+}
+
+func TestEmptySearch(t *testing.T) {
+	tests := []struct {
+		tool, result string
+		want         bool
+	}{
+		{"Grep", `{"mode":"content","content":"","numFiles":0}`, true},
+		{"Grep", `{"mode":"files_with_matches","filenames":[],"numFiles":0}`, true},
+		{"Grep", `{"mode":"count","content":"\n","numFiles":0}`, true},
+		{"Grep", `{"mode":"files_with_matches","filenames":["a.go"],"numFiles":1}`, false},
+		{"Glob", `{"filenames":[],"numFiles":0}`, true},
+		{"Glob", `{"filenames":["a.go"],"numFiles":1}`, false},
+		{"Read", `{"filenames":[]}`, false},
+		{"Grep", ``, false},
+	}
+	for _, tt := range tests {
+		if got := EmptySearch(tt.tool, json.RawMessage(tt.result)); got != tt.want {
+			t.Errorf("EmptySearch(%s, %s) = %v, want %v", tt.tool, tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestRenderer_Render_GrepResult_NotGrep(t *testing.T) {
+	output := renderGrep(t, 0, "", `{"mode":"something-else"}`)
+	if !strings.Contains(output, "1 lines") {
+		t.Errorf("expected generic rendering for unknown modes, got: %q", output)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/textutil"
 )
 
 // RenderContext bundles the parameters needed for rendering tool results.
@@ -16,6 +17,22 @@ type RenderContext struct {
 	// renderers disambiguate tool results that share a JSON shape (e.g.
 	// TaskCreate and TaskGet both return a "task" object).
 	ToolName string
+	// Pattern is the search regex of the tool call (e.g. Grep), when known.
+	Pattern string
+	// LineNumbers is set when the search asked for line numbers, so its
+	// output lines carry them.
+	LineNumbers bool
+	// Width is the terminal width available for output, including the
+	// output prefix. Renderers use it to choose layouts.
+	Width int
+	// LineBudget is the number of lines a result may use, derived from the
+	// TUI viewport height. Zero means the fixed limits apply unscaled.
+	LineBudget int
+}
+
+// MaxLines scales a renderer's fixed line limit to the line budget.
+func (ctx *RenderContext) MaxLines(fixed int) int {
+	return textutil.ScaleMaxLines(fixed, ctx.LineBudget)
 }
 
 // ResultRenderer defines the interface for rendering specific tool result types.
@@ -59,6 +59,8 @@ type Renderer struct {
 	markdownRenderer types.MarkdownRenderer
 	toolContext      *tools.ToolContext
 	contentCleaner   *textutil.ContentCleaner
+	width            int
+	lineBudget       int
 	// Registry for result-specific renderers
 	resultRegistry *ResultRegistry
 }
@@ -115,6 +117,13 @@ func WithContentCleaner(cc *textutil.ContentCleaner) RendererOption {
 	}
 }
 
+// WithWidth sets the output width (defaults to the terminal width)
+func WithWidth(width int) RendererOption {
+	return func(r *Renderer) {
+		r.width = width
+	}
+}
+
 // NewRenderer creates a new user Renderer with the given options
 func NewRenderer(opts ...RendererOption) *Renderer {
 	cfg := config.Get()
@@ -129,6 +138,7 @@ func NewRenderer(opts ...RendererOption) *Renderer {
 		markdownRenderer: render.NewMarkdownRenderer(cfg.NoColor(), terminal.Width()),
 		toolContext:      &tools.ToolContext{},
 		contentCleaner:   textutil.DefaultContentCleaner(),
+		width:            terminal.Width(),
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -137,6 +147,10 @@ func NewRenderer(opts ...RendererOption) *Renderer {
 	r.resultRegistry = NewResultRegistry()
 	r.resultRegistry.Register(NewEditRenderer(r.styleApplier, r.highlighter, r.config))
 	r.resultRegistry.Register(NewWriteRenderer(r.styleApplier, r.highlighter, r.config))
+	r.resultRegistry.Register(NewBashRenderer(r.styleApplier, r.config))
+	r.resultRegistry.Register(NewGrepRenderer(r.styleApplier, r.config))
+	r.resultRegistry.Register(NewGlobRenderer(r.styleApplier, r.config))
+	r.resultRegistry.Register(NewWebSearchRenderer(r.styleApplier, r.config))
 	r.resultRegistry.Register(NewTodoRenderer(r.styleApplier))
 	r.resultRegistry.Register(NewTaskListRenderer(r.styleApplier))
 	r.resultRegistry.Register(NewTaskCreateRenderer(r.styleApplier))
@@ -144,6 +158,19 @@ func NewRenderer(opts ...RendererOption) *Renderer {
 	return r
 }
 
+// SetWidth updates the output width used to lay out results such as
+// side-by-side diffs. This is called when the viewport resizes.
+func (r *Renderer) SetWidth(width int) {
+	r.width = width
+}
+
+// SetLineBudget sets the number of lines a single result may use. Fixed
+// truncation limits are scaled to it; zero restores them. The TUI derives
+// it from the viewport height on every resize.
+func (r *Renderer) SetLineBudget(lines int) {
+	r.lineBudget = lines
+}
+
 // SetToolContext sets the tool context for syntax highlighting
 func (r *Renderer) SetToolContext(ctx tools.ToolContext) {
 	*r.toolContext = ctx
@@ -177,9 +204,13 @@ func (r *Renderer) renderTo(out *render.Output, event Event, outputPrefix, outpu
 		Output:         out,
 		OutputPrefix:   outputPrefix,
 		OutputContinue: outputContinue,
+		Width:          r.width,
+		LineBudget:     r.lineBudget,
 	}
 	if r.toolContext != nil {
 		ctx.ToolName = r.toolContext.ToolName
+		ctx.Pattern = r.toolContext.Pattern
+		ctx.LineNumbers = r.toolContext.LineNumbers
 	}
 	if r.resultRegistry.TryRender(ctx, event.ToolUseResult) {
 		return
@@ -195,36 +226,20 @@ func (r *Renderer) renderTo(out *render.Output, event Event, outputPrefix, outpu
 		} else if contentStr != "" {
 			// Clean up the content using the content cleaner pipeline
 			cleaned := r.contentCleaner.Clean(contentStr)
+			// Output that was only whitespace or system reminders has
+			// nothing to show
+			if strings.TrimSpace(cleaned) == "" {
+				continue
+			}
 
 			lines := strings.Split(cleaned, "\n")
 			lineCount := len(lines)
 
-			// Verbosity levels for tool output:
-			// Writes (Edit, Write, NotebookEdit):
-			//   -v:   truncated to 10 lines
-			//   -vv:  not truncated
-			// Reads (everything else):
-			//   -v:   no content shown (summary only)
-			//   -vv:  truncated to 5 lines
-			//   -vvv: truncated to 10 lines
-			isWriteTool := r.toolContext != nil && (r.toolContext.ToolName == "Edit" || r.toolContext.ToolName == "Write" || r.toolContext.ToolName == "NotebookEdit")
-			level := r.config.GetVerboseLevel()
-
-			var maxLines int // 0 = don't expand, -1 = no limit
-			if isWriteTool {
-				switch {
-				case level >= 2:
-					maxLines = -1
-				case level >= 1:
-					maxLines = 10
-				}
-			} else {
-				switch {
-				case level >= 3:
-					maxLines = 10
-				case level >= 2:
-					maxLines = 5
-				}
+			// Tool output is summarized as a line count by default and
+			// shown in full from -v up.
+			maxLines := 0 // 0 = don't expand, -1 = no limit
+			if r.config.IsVerbose() {
+				maxLines = -1
 			}
 
 			if maxLines != 0 {
@@ -274,7 +289,7 @@ func (r *Renderer) renderSyntheticMessageTo(out *render.Output, event Event) {
 				}
 			} else {
 				// Fallback to plain text with truncation
-				truncated, remaining := textutil.TruncateLines(cleaned, textutil.DefaultMaxLines)
+				truncated, remaining := textutil.TruncateLines(cleaned, textutil.ScaleMaxLines(textutil.DefaultMaxLines, r.lineBudget))
 				resultLines := strings.Split(truncated, "\n")
 
 				pw := textutil.NewPrefixedWriter(out, r.styleApplier.OutputPrefix(), r.styleApplier.OutputContinue())
@@ -3,6 +3,7 @@ package user
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -418,6 +419,12 @@ func (m mockCodeHighlighter) HighlightWithBg(code, language string, bgColor styl
 func (m mockCodeHighlighter) HighlightFileWithBg(code, filename string, bgColor style.Color) string {
 	return code
 }
+func (m mockCodeHighlighter) HighlightFileWithEmphasis(code, filename string, bgColor, emphBg style.Color, spans []render.Span) string {
+	return code
+}
+func (m mockCodeHighlighter) HighlightFileLines(lines []string, filename string, styles []render.LineStyle) []string {
+	return lines
+}
 
 func TestRenderer_SetToolContext(t *testing.T) {
 	r := NewRenderer(
@@ -570,6 +577,33 @@ func TestRenderer_Render_EmptyContent(t *testing.T) {
 	}
 }
 
+func TestRenderer_Render_BlankContent(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"whitespace only", `"  \n\t\n"`},
+		{"system reminder only", `"<system-reminder>Remember the rules</system-reminder>\n"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewRenderer(
+				WithOutput(&buf),
+				WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: 2, NoColorVal: true}),
+				WithStyleApplier(testutil.MockStyleApplier{}),
+				WithCodeHighlighter(mockCodeHighlighter{}),
+			)
+			r.Render(Event{Message: Message{Content: []ToolResultContent{
+				{Type: "tool_result", RawContent: json.RawMessage(tt.raw)},
+			}}})
+			if buf.String() != "" {
+				t.Errorf("expected no output, got %q", buf.String())
+			}
+		})
+	}
+}
+
 func TestRenderer_Render_MultipleContent(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewRenderer(
@@ -743,6 +777,63 @@ func TestRenderer_Render_EditResult_NonVerbose(t *testing.T) {
 	}
 }
 
+// batchHighlighter records the HighlightFileLines calls made per block.
+type batchHighlighter struct {
+	mockCodeHighlighter
+	filenames []string
+	blocks    [][]string
+}
+
+func (b *batchHighlighter) HighlightFileLines(lines []string, filename string, styles []render.LineStyle) []string {
+	b.filenames = append(b.filenames, filename)
+	b.blocks = append(b.blocks, lines)
+	return lines
+}
+
+func TestRenderer_Render_EditResult_BatchHighlighting(t *testing.T) {
+	lines := []string{" ctx", "-old", "+new"}
+	for i := 0; i < 30; i++ {
+		lines = append(lines, "+added")
+	}
+	toolUseResult, _ := json.Marshal(EditResult{
+		FilePath:        "/path/to/file.go",
+		StructuredPatch: []PatchHunk{{OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 32, Lines: lines}},
+	})
+
+	renderEdit := func(cfg testutil.MockConfigProvider) *batchHighlighter {
+		h := &batchHighlighter{}
+		r := NewRenderer(
+			WithOutput(&bytes.Buffer{}),
+			WithConfigProvider(cfg),
+			WithStyleApplier(testutil.MockStyleApplier{}),
+			WithCodeHighlighter(h),
+		)
+		r.Render(Event{Message: Message{Role: "user"}, ToolUseResult: toolUseResult})
+		return h
+	}
+
+	h := renderEdit(testutil.MockConfigProvider{NoColorVal: true})
+	if len(h.blocks) != 2 {
+		t.Fatalf("expected one block per side of the hunk, got %d", len(h.blocks))
+	}
+	if strings.Join(h.blocks[0], ",") != "ctx,old" {
+		t.Errorf("old side = %q, want context and removed lines", h.blocks[0])
+	}
+	if got := len(h.blocks[1]); got != 9 {
+		t.Errorf("new side has %d lines, want only the 9 shown before truncation", got)
+	}
+	if h.filenames[0] != "/path/to/file.go" {
+		t.Errorf("filename = %q, want file path for language detection", h.filenames[0])
+	}
+
+	h = renderEdit(testutil.MockConfigProvider{NoColorVal: true, NoDiffHighlightVal: true})
+	for _, name := range h.filenames {
+		if name != "" {
+			t.Errorf("expected no language detection with -no-diff-highlight, got %q", name)
+		}
+	}
+}
+
 func TestRenderer_Render_EditResult_EmptyPatch(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewRenderer(
@@ -846,6 +937,7 @@ func TestRenderer_highlightContent_WithToolContext(t *testing.T) {
 type trackingHighlighter struct {
 	highlightFunc     func(code, language string) string
 	highlightFileFunc func(code, filename string) string
+	emphasisFunc      func(code string, emphBg style.Color, spans []render.Span) string
 }
 
 func (t *trackingHighlighter) Highlight(code, language string) string {
@@ -870,6 +962,120 @@ func (t *trackingHighlighter) HighlightFileWithBg(code, filename string, bgColor
 	return code
 }
 
+func (t *trackingHighlighter) HighlightFileWithEmphasis(code, filename string, bgColor, emphBg style.Color, spans []render.Span) string {
+	if t.emphasisFunc != nil {
+		return t.emphasisFunc(code, emphBg, spans)
+	}
+	return code
+}
+
+func (t *trackingHighlighter) HighlightFileLines(lines []string, filename string, styles []render.LineStyle) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		switch {
+		case t.emphasisFunc != nil && styles[i].Spans != nil:
+			out[i] = t.emphasisFunc(line, styles[i].EmphBg, styles[i].Spans)
+		case t.highlightFileFunc != nil && styles[i].Bg == "":
+			out[i] = t.highlightFileFunc(line, filename)
+		default:
+			out[i] = line
+		}
+	}
+	return out
+}
+
+func TestRenderer_Render_EditResult_WordDiff(t *testing.T) {
+	var buf bytes.Buffer
+	emphasized := map[string]string{}
+	highlighter := &trackingHighlighter{
+		emphasisFunc: func(code string, emphBg style.Color, spans []render.Span) string {
+			for _, sp := range spans {
+				emphasized[code] += code[sp.Start:sp.End]
+			}
+			return code
+		},
+	}
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{NoColorVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(highlighter),
+	)
+
+	editResult := EditResult{
+		FilePath: "/path/to/file.go",
+		StructuredPatch: []PatchHunk{{
+			OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+			Lines: []string{
+				" func main() {",
+				"-\treturn count + 1",
+				"+\treturn total + 1",
+				"-completely different",
+				"+x",
+			},
+		}},
+	}
+	toolUseResult, _ := json.Marshal(editResult)
+	r.Render(Event{Message: Message{Role: "user"}, ToolUseResult: toolUseResult})
+
+	if got := emphasized["\treturn count + 1"]; got != "count" {
+		t.Errorf("removed line emphasis = %q, want %q", got, "count")
+	}
+	if got := emphasized["\treturn total + 1"]; got != "total" {
+		t.Errorf("added line emphasis = %q, want %q", got, "total")
+	}
+	if _, ok := emphasized["x"]; ok {
+		t.Error("expected dissimilar lines to fall back to whole-line highlighting")
+	}
+	if !strings.Contains(buf.String(), "completely different") {
+		t.Errorf("expected unpaired lines to still render, got %q", buf.String())
+	}
+}
+
+func TestRenderer_Render_EditResult_ShowWhitespace(t *testing.T) {
+	var buf bytes.Buffer
+	emphasized := map[string]string{}
+	highlighter := &trackingHighlighter{
+		emphasisFunc: func(code string, emphBg style.Color, spans []render.Span) string {
+			for _, sp := range spans {
+				emphasized[code] += code[sp.Start:sp.End]
+			}
+			return code
+		},
+	}
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{NoColorVal: true, ShowWhitespaceVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(highlighter),
+	)
+
+	editResult := EditResult{
+		FilePath: "/path/to/file.go",
+		StructuredPatch: []PatchHunk{{
+			OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2,
+			Lines: []string{
+				"-	return count  ",
+				"+    return count",
+				"-x := 1\r",
+				"+x := 1",
+			},
+		}},
+	}
+	toolUseResult, _ := json.Marshal(editResult)
+	r.Render(Event{Message: Message{Role: "user"}, ToolUseResult: toolUseResult})
+
+	out := buf.String()
+	for _, want := range []string{"⇥   return count··", "    return count", "x := 1␍"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %q", want, out)
+		}
+	}
+	if got := emphasized["⇥   return count··"]; got != "⇥   ··" {
+		t.Errorf("removed line emphasis = %q, want the whitespace markers", got)
+	}
+}
+
 func TestRenderer_highlightContent_WithUnknownExtension(t *testing.T) {
 	highlightFileCalled := false
 
@@ -1335,6 +1541,8 @@ func TestDefaultStyleApplier(t *testing.T) {
 	_ = sa.LineNumberSepRender("│")
 	_ = sa.DiffAddBg()
 	_ = sa.DiffRemoveBg()
+	_ = sa.DiffAddEmphBg()
+	_ = sa.DiffRemoveEmphBg()
 	_ = sa.SessionHeaderRender("test")
 	_ = sa.ApplyThemeBoldGradient("test")
 	_ = sa.ApplySuccessGradient("test")
@@ -1515,3 +1723,82 @@ func TestTodoResult_Unmarshaling(t *testing.T) {
 		t.Errorf("NewTodos[1].Status = %q, expected %q", result.NewTodos[1].Status, "in_progress")
 	}
 }
+
+func renderEditForLayout(t *testing.T, layout string, width int, lines []string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{NoColorVal: true, DiffLayoutVal: layout}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(mockCodeHighlighter{}),
+		WithWidth(width),
+	)
+	editResult := EditResult{
+		FilePath:        "/path/to/file.go",
+		StructuredPatch: []PatchHunk{{OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 4, Lines: lines}},
+	}
+	toolUseResult, _ := json.Marshal(editResult)
+	r.Render(Event{Message: Message{Role: "user"}, ToolUseResult: toolUseResult})
+	return buf.String()
+}
+
+func TestRenderer_Render_EditResult_SideBySide(t *testing.T) {
+	lines := []string{" context", "-old value", "+new value", "+extra line"}
+
+	t.Run("renders old and new in two columns", func(t *testing.T) {
+		output := renderEditForLayout(t, config.DiffLayoutSideBySide, 160, lines)
+		rows := strings.Split(strings.TrimRight(output, "\n"), "\n")
+		if len(rows) != 3 {
+			t.Fatalf("expected 3 rows (context, paired change, unpaired add), got %d:\n%s", len(rows), output)
+		}
+		if !strings.Contains(rows[0], "context") || strings.Count(rows[0], "context") != 2 {
+			t.Errorf("expected context on both sides, got %q", rows[0])
+		}
+		oldAt, newAt := strings.Index(rows[1], "old value"), strings.Index(rows[1], "new value")
+		if oldAt < 0 || newAt < oldAt {
+			t.Errorf("expected old value left of new value, got %q", rows[1])
+		}
+		if strings.Contains(rows[2], "[ERROR:-]") || !strings.Contains(rows[2], "extra line") {
+			t.Errorf("expected blank left side for unpaired add, got %q", rows[2])
+		}
+	})
+
+	t.Run("truncates long lines to the column width", func(t *testing.T) {
+		long := "-" + strings.Repeat("x", 200)
+		output := renderEditForLayout(t, config.DiffLayoutSideBySide, 120, []string{long, "+short"})
+		if !strings.Contains(output, "…") {
+			t.Errorf("expected truncated content, got %q", output)
+		}
+	})
+
+	t.Run("falls back to unified when narrow", func(t *testing.T) {
+		output := renderEditForLayout(t, config.DiffLayoutSideBySide, SideBySideMinWidth-1, lines)
+		if rows := strings.Count(output, "\n"); rows != 4 {
+			t.Errorf("expected 4 unified rows, got %d:\n%s", rows, output)
+		}
+	})
+
+	t.Run("unified layout ignores width", func(t *testing.T) {
+		output := renderEditForLayout(t, config.DiffLayoutUnified, 200, lines)
+		if rows := strings.Count(output, "\n"); rows != 4 {
+			t.Errorf("expected 4 unified rows, got %d:\n%s", rows, output)
+		}
+	})
+}
+
+func TestSideBySideRows(t *testing.T) {
+	rows := sideBySideRows(PatchHunk{
+		OldStart: 10, NewStart: 20,
+		Lines: []string{" a", "-b", "-c", "+d", "", " e"},
+	})
+	want := []diffRow{
+		{old: diffSide{10, ' ', 0}, new: diffSide{20, ' ', 0}},
+		{old: diffSide{11, '-', 1}, new: diffSide{21, '+', 3}},
+		{old: diffSide{12, '-', 2}},
+		{old: diffSide{13, ' ', 5}, new: diffSide{22, ' ', 5}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("sideBySideRows =\n%+v\nwant\n%+v", rows, want)
+	}
+}
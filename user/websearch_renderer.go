@@ -0,0 +1,127 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/render"
+	"github.com/johnnyfreeman/viewscreen/textutil"
+)
+
+// WebSearchResult represents the tool_use_result for WebSearch. Results mixes
+// search hit blocks with the model's text commentary on them.
+type WebSearchResult struct {
+	Query           string            `json:"query"`
+	Results         []json.RawMessage `json:"results"`
+	DurationSeconds float64           `json:"durationSeconds"`
+}
+
+// webSearchBlock is one block of search hits.
+type webSearchBlock struct {
+	ToolUseID string          `json:"tool_use_id"`
+	Content   []WebSearchLink `json:"content"`
+}
+
+// WebSearchLink is a single search hit.
+type WebSearchLink struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
+
+// maxSnippetLen bounds snippets and commentary so one result stays compact.
+const maxSnippetLen = 160
+
+// links returns every search hit in result order.
+func (w WebSearchResult) links() []WebSearchLink {
+	var links []WebSearchLink
+	for _, raw := range w.Results {
+		var block webSearchBlock
+		if json.Unmarshal(raw, &block) == nil {
+			links = append(links, block.Content...)
+		}
+	}
+	return links
+}
+
+// commentary returns the first text entry, the model's summary of the hits,
+// without the "(no content)" markers left between citation blocks.
+func (w WebSearchResult) commentary() string {
+	for _, raw := range w.Results {
+		var text string
+		if json.Unmarshal(raw, &text) == nil && strings.TrimSpace(text) != "" {
+			return strings.ReplaceAll(text, "(no content)", " ")
+		}
+	}
+	return ""
+}
+
+// WebSearchRenderer handles rendering of WebSearch results as a numbered
+// list of linked titles.
+type WebSearchRenderer struct {
+	styleApplier render.StyleApplier
+	config       config.Provider
+}
+
+// NewWebSearchRenderer creates a new WebSearchRenderer with the given
+// dependencies.
+func NewWebSearchRenderer(styleApplier render.StyleApplier, cfg config.Provider) *WebSearchRenderer {
+	return &WebSearchRenderer{
+		styleApplier: styleApplier,
+		config:       cfg,
+	}
+}
+
+// TryRender implements ResultRenderer interface.
+// Always shows a summary ("10 results for "query""); from -v up the results
+// follow as numbered titles with hyperlinked URLs, then a truncated snippet
+// of the search commentary.
+// Returns true if it was a WebSearch result and was rendered, false otherwise.
+func (wr *WebSearchRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMessage) bool {
+	if len(toolUseResult) == 0 {
+		return false
+	}
+
+	var result WebSearchResult
+	if err := json.Unmarshal(toolUseResult, &result); err != nil || result.Query == "" || result.Results == nil {
+		return false
+	}
+
+	links := result.links()
+	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
+	summary := fmt.Sprintf("%s for %q", plural(len(links), "result", "results"), result.Query)
+	if len(links) == 0 {
+		summary = fmt.Sprintf("No results for %q", result.Query)
+	}
+	pw.WriteLine(wr.styleApplier.MutedText(summary))
+	if !wr.config.IsVerbose() {
+		return true
+	}
+
+	numWidth := len(fmt.Sprint(len(links)))
+	indent := strings.Repeat(" ", numWidth+2)
+	for i, link := range links {
+		title := link.Title
+		if title == "" {
+			title = link.URL
+		}
+		pw.WriteLinef("%*d. %s", numWidth, i+1, title)
+		if link.URL != "" {
+			pw.WriteLine(indent + wr.styleApplier.Hyperlink(wr.styleApplier.MutedText(link.URL), link.URL))
+		}
+		if snippet := snippetLine(link.Snippet); snippet != "" {
+			pw.WriteLine(indent + wr.styleApplier.MutedText(snippet))
+		}
+	}
+	if commentary := snippetLine(result.commentary()); commentary != "" {
+		pw.WriteLine(wr.styleApplier.MutedText(commentary))
+	}
+	return true
+}
+
+// snippetLine collapses text onto one line and truncates it.
+func snippetLine(text string) string {
+	return textutil.Truncate(strings.Join(strings.Fields(text), " "), maxSnippetLen)
+}
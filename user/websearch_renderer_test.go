@@ -0,0 +1,83 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/testutil"
+	"github.com/johnnyfreeman/viewscreen/tools"
+)
+
+func renderWebSearch(t *testing.T, level int, toolUseResult string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	r := NewRenderer(
+		WithOutput(&buf),
+		WithConfigProvider(testutil.MockConfigProvider{VerboseLevelVal: level, NoColorVal: true}),
+		WithStyleApplier(testutil.MockStyleApplier{}),
+		WithCodeHighlighter(mockCodeHighlighter{}),
+	)
+	r.SetToolContext(tools.ToolContext{ToolName: "WebSearch"})
+	r.Render(Event{
+		Message:       Message{Role: "user", Content: []ToolResultContent{{Type: "tool_result", RawContent: json.RawMessage(`"raw blob"`)}}},
+		ToolUseResult: json.RawMessage(toolUseResult),
+	})
+	return buf.String()
+}
+
+const webSearchJSON = `{"query":"go 1.22","results":[
+	{"tool_use_id":"srvtoolu_1","content":[
+		{"title":"Go 1.22 Release Notes","url":"https://go.dev/doc/go1.22"},
+		{"title":"Go 1.22 is released!","url":"https://go.dev/blog/go1.22","snippet":"Today the Go team is thrilled\nto release Go 1.22"},
+		{"title":"","url":"https://example.com/untitled"}
+	]},
+	"Here's a summary:\n\n## Overview\n\nGo 1.22 fixes loop variables."
+],"durationSeconds":2.5}`
+
+func TestRenderer_Render_WebSearchResult_Summary(t *testing.T) {
+	output := renderWebSearch(t, 0, webSearchJSON)
+	if !strings.Contains(output, `[MUTED:3 results for "go 1.22"]`) {
+		t.Errorf("expected result count summary, got: %q", output)
+	}
+	if strings.Contains(output, "go.dev") || strings.Contains(output, "raw blob") {
+		t.Errorf("expected only the summary by default, got: %q", output)
+	}
+}
+
+func TestRenderer_Render_WebSearchResult_List(t *testing.T) {
+	output := renderWebSearch(t, 2, webSearchJSON)
+	for _, want := range []string{
+		"1. Go 1.22 Release Notes\n",
+		"   [LINK:https://go.dev/doc/go1.22|[MUTED:https://go.dev/doc/go1.22]]\n",
+		"   [MUTED:Today the Go team is thrilled to release Go 1.22]\n",
+		"3. https://example.com/untitled\n",
+		"[MUTED:Here's a summary: ## Overview Go 1.22 fixes loop variables.]",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderer_Render_WebSearchResult_AllResults(t *testing.T) {
+	var links []string
+	for range 7 {
+		links = append(links, `{"title":"hit","url":"https://e.com"}`)
+	}
+	output := renderWebSearch(t, 1, `{"query":"q","results":[{"content":[`+strings.Join(links, ",")+`]},"`+strings.Repeat("word ", 50)+`"]}`)
+	if strings.Count(output, ". hit") != 7 || strings.Contains(output, "more lines") {
+		t.Errorf("expected all 7 results, got:\n%s", output)
+	}
+	if !strings.Contains(output, "...]") {
+		t.Errorf("expected truncated commentary, got:\n%s", output)
+	}
+}
+
+func TestRenderer_Render_WebSearchResult_NoResults(t *testing.T) {
+	output := renderWebSearch(t, 2, `{"query":"nothing","results":[]}`)
+	if !strings.Contains(output, `[MUTED:No results for "nothing"]`) {
+		t.Errorf("expected empty summary, got: %q", output)
+	}
+}
@@ -54,15 +54,10 @@ func (wr *WriteRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMes
 	lines := strings.Split(writeResult.Content, "\n")
 	lineCount := len(lines)
 
-	level := wr.config.GetVerboseLevel()
-
-	// Write tools: -v = 10 lines, -vv = no limit
-	maxLines := 10
-	switch {
-	case level >= 2:
+	// Write tools: 10 lines by default, no limit from -v up
+	maxLines := ctx.MaxLines(10)
+	if wr.config.IsVerbose() {
 		maxLines = -1
-	case level >= 1:
-		maxLines = 10
 	}
 
 	// Always show the summary header
@@ -80,7 +75,22 @@ func (wr *WriteRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMes
 
 	pw := textutil.NewPrefixedWriter(ctx.Output, ctx.OutputPrefix, ctx.OutputContinue)
 
-	for i, line := range lines {
+	// Highlight the shown lines as one block, all on the added background
+	shown := lines
+	if maxLines >= 0 && len(shown) > maxLines {
+		shown = shown[:maxLines]
+	}
+	filePath := writeResult.FilePath
+	if wr.config.NoDiffHighlight() {
+		filePath = ""
+	}
+	styles := make([]render.LineStyle, len(shown))
+	for i := range styles {
+		styles[i] = render.LineStyle{Bg: wr.styleApplier.DiffAddBg()}
+	}
+	styled := wr.highlighter.HighlightFileLines(shown, filePath, styles)
+
+	for i := range lines {
 		if maxLines >= 0 && i >= maxLines {
 			remaining := lineCount - i
 			if remaining > 0 {
@@ -91,9 +101,8 @@ func (wr *WriteRenderer) TryRender(ctx *RenderContext, toolUseResult json.RawMes
 
 		lineNum := fmt.Sprintf("%*d", numWidth, i+1)
 		lineNums := wr.styleApplier.LineNumberRender(lineNum)
-		styled := wr.highlighter.HighlightFileWithBg(line, writeResult.FilePath, wr.styleApplier.DiffAddBg())
 
-		pw.WriteLinef("%s %s %s %s", lineNums, sep, op, styled)
+		pw.WriteLinef("%s %s %s %s", lineNums, sep, op, styled[i])
 	}
 
 	return true
@@ -0,0 +1,199 @@
+// Package viewscreen renders Claude Code stream-json and Codex JSONL
+// transcripts for other Go programs, such as CI bots or web backends, without
+// running the viewscreen binary.
+//
+//	err := viewscreen.RenderSession(transcript, os.Stdout, viewscreen.Options{NoColor: true})
+//
+// Each RenderSession call and Renderer renders with its own Options, so
+// renderers with different options can run side by side. Options.Sinks
+// receive each rendered event, e.g. a Transcript to present and query the
+// session from.
+package viewscreen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnnyfreeman/viewscreen/config"
+	"github.com/johnnyfreeman/viewscreen/events"
+	"github.com/johnnyfreeman/viewscreen/export"
+	"github.com/johnnyfreeman/viewscreen/parser"
+	"github.com/johnnyfreeman/viewscreen/sink"
+	"github.com/johnnyfreeman/viewscreen/state"
+	"github.com/johnnyfreeman/viewscreen/style"
+	"github.com/johnnyfreeman/viewscreen/transcript"
+)
+
+// Options configures rendering.
+type Options struct {
+	// Verbose is the verbosity level: 1, 2 or 3 for -v, -vv or -vvv.
+	Verbose int
+	// NoColor disables ANSI styling.
+	NoColor bool
+	// HideUsage omits token usage from the session result.
+	HideUsage bool
+	// DiffLayout is "unified" (the default) or "side-by-side".
+	DiffLayout string
+	// Width is the word-wrap width. Zero uses the terminal width, or 80
+	// when the output is not a terminal.
+	Width int
+	// Diagnostics receives warnings such as unparseable lines. Nil discards
+	// them.
+	Diagnostics io.Writer
+	// Sinks receive every rendered event alongside the output, with its
+	// styled timeline entries whatever NoColor is. A failing sink does not
+	// stop rendering; its Close reports the failure. The caller closes them.
+	Sinks []Sink
+}
+
+// config validates o and returns the configuration its renderers render
+// with. NoColor is not part of it: viewscreen's styles are process-wide, so
+// output is styled and NoColor strips the styling afterwards.
+func (o Options) config() (*config.Config, error) {
+	layout := o.DiffLayout
+	if layout == "" {
+		layout = config.DiffLayoutUnified
+	}
+	if layout != config.DiffLayoutUnified && layout != config.DiffLayoutSideBySide {
+		return nil, fmt.Errorf("unknown diff layout %q (want %q or %q)", o.DiffLayout, config.DiffLayoutUnified, config.DiffLayoutSideBySide)
+	}
+	initStyles.Do(func() { style.Init(false) })
+	return &config.Config{
+		VerboseLevel:   o.Verbose,
+		DisplayUsage:   !o.HideUsage,
+		DiffLayoutMode: layout,
+	}, nil
+}
+
+// initStyles initializes viewscreen's styles once, for the first renderer.
+var initStyles sync.Once
+
+func (o Options) output(w io.Writer) io.Writer {
+	if o.NoColor {
+		return stripWriter{w}
+	}
+	return w
+}
+
+func (o Options) diagnostics() io.Writer {
+	if o.Diagnostics == nil {
+		return io.Discard
+	}
+	return o.Diagnostics
+}
+
+// RenderSession renders every event read from r, one JSON object per line,
+// to w.
+func RenderSession(r io.Reader, w io.Writer, opts Options) error {
+	cfg, err := opts.config()
+	if err != nil {
+		return err
+	}
+	p := parser.NewParserWithOptions(
+		parser.WithInput(r),
+		parser.WithOutput(opts.output(w)),
+		parser.WithErrOutput(opts.diagnostics()),
+		parser.WithRendererSet(events.NewRendererSetWithConfig(cfg)),
+	)
+	if opts.Width > 0 {
+		p.Renderers().SetWidth(opts.Width)
+	}
+	if len(opts.Sinks) > 0 {
+		p.AddSink(sink.Multi(opts.Sinks...))
+	}
+	return p.Run()
+}
+
+// Renderer renders a session one event at a time. It keeps the state that
+// spans events, such as tool calls waiting for their results, so events must
+// be passed in stream order.
+type Renderer struct {
+	processor *events.EventProcessor
+	noColor   bool
+	sinks     Sink
+}
+
+// NewRenderer creates a Renderer for a new session.
+func NewRenderer(opts Options) (*Renderer, error) {
+	cfg, err := opts.config()
+	if err != nil {
+		return nil, err
+	}
+	processor := events.NewEventProcessorWithRenderers(state.NewState(), events.NewRendererSetWithConfig(cfg))
+	if opts.Width > 0 {
+		processor.SetWidth(opts.Width)
+	}
+	return &Renderer{processor: processor, noColor: opts.NoColor, sinks: sink.Multi(opts.Sinks...)}, nil
+}
+
+// RenderEvent renders one JSON event line. A tool call renders nothing until
+// the event carrying its result arrives. Recognized events that viewscreen
+// does not display render as "".
+func (r *Renderer) RenderEvent(line []byte) (string, error) {
+	parsed := events.Parse(string(line))
+	switch e := parsed.(type) {
+	case nil, events.IgnoredEvent:
+		return "", nil
+	case events.ParseError:
+		if e.Err != nil {
+			return "", fmt.Errorf("parsing event: %w", e.Err)
+		}
+		return "", errors.New(e.Line)
+	}
+	res := r.processor.ProcessLine(string(line), parsed)
+	_ = r.sinks.Write(sink.Event{Raw: string(line), Rendered: res.Rendered, Entries: res.Batch.Entries})
+	if r.noColor {
+		return ansi.Strip(res.Rendered), nil
+	}
+	return res.Rendered, nil
+}
+
+// stripWriter writes to w with ANSI styling removed.
+type stripWriter struct{ w io.Writer }
+
+func (s stripWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(s.w, ansi.Strip(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sink receives each rendered event. See Options.Sinks.
+type Sink = sink.Sink
+
+// Event is a rendered event as a Sink receives it: the raw line, its
+// terminal output and its timeline entries.
+type Event = sink.Event
+
+// Transcript is a Sink that accumulates the rendered blocks of a session
+// and can be read, and queried, from any goroutine while it renders.
+type Transcript = transcript.Transcript
+
+// Block is one rendered block of a Transcript.
+type Block = transcript.Block
+
+// NewTranscript returns an empty Transcript, to pass in Options.Sinks.
+func NewTranscript() *Transcript {
+	return transcript.New()
+}
+
+// Exporter writes a session in a custom format. See RegisterExporter.
+type Exporter = export.Exporter
+
+// ExporterFunc adapts a function to an Exporter.
+type ExporterFunc = export.ExporterFunc
+
+// ExportSession is what an Exporter receives: the raw input events of the
+// session and the transcript rendered from them.
+type ExportSession = export.Session
+
+// RegisterExporter makes exports to files with extension ext, such as
+// "-export session.audit" or ":w session.audit" in the TUI, use e. It must be
+// called before the export is written, typically from an init function of a
+// program that wraps viewscreen.
+func RegisterExporter(ext string, e Exporter) {
+	export.RegisterExporter(ext, e)
+}
@@ -0,0 +1,121 @@
+package viewscreen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/config"
+)
+
+func TestRenderSession(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "bash_ls.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var out bytes.Buffer
+	if err := RenderSession(f, &out, Options{NoColor: true, Width: 100}); err != nil {
+		t.Fatalf("RenderSession() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Bash") {
+		t.Errorf("expected the Bash tool in output, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Errorf("expected no ANSI styling with NoColor, got %q", out.String())
+	}
+}
+
+func TestRenderSession_Diagnostics(t *testing.T) {
+	var out, diag bytes.Buffer
+	if err := RenderSession(strings.NewReader("not json\n"), &out, Options{NoColor: true, Diagnostics: &diag}); err != nil {
+		t.Fatalf("RenderSession() error = %v", err)
+	}
+	if diag.Len() == 0 {
+		t.Error("expected a diagnostic for an unparseable line")
+	}
+}
+
+func TestRenderSession_InvalidOptions(t *testing.T) {
+	err := RenderSession(strings.NewReader(""), &bytes.Buffer{}, Options{DiffLayout: "stacked"})
+	if err == nil || !strings.Contains(err.Error(), "stacked") {
+		t.Errorf("expected an unknown diff layout error, got %v", err)
+	}
+}
+
+func TestRenderer_RenderEvent(t *testing.T) {
+	r, err := NewRenderer(Options{NoColor: true})
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	got, err := r.RenderEvent([]byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"Hello from the library"}]}}`))
+	if err != nil {
+		t.Fatalf("RenderEvent() error = %v", err)
+	}
+	if !strings.Contains(got, "Hello from the library") {
+		t.Errorf("RenderEvent() = %q, want the assistant text", got)
+	}
+
+	got, err = r.RenderEvent([]byte(`{"type":"rate_limit_event"}`))
+	if err != nil || got != "" {
+		t.Errorf("RenderEvent(ignored) = %q, %v; want empty, nil", got, err)
+	}
+
+	for _, line := range []string{`not json`, `{"type":"mystery"}`} {
+		if _, err := r.RenderEvent([]byte(line)); err == nil {
+			t.Errorf("RenderEvent(%s) expected an error", line)
+		}
+	}
+}
+
+func TestRenderer_OptionsPerInstance(t *testing.T) {
+	before := config.Get().VerbosityLevel()
+
+	plain, err := NewRenderer(Options{NoColor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verbose, err := NewRenderer(Options{Verbose: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Get().VerbosityLevel() != before || config.Get().NoColor() {
+		t.Error("NewRenderer changed the process-wide configuration")
+	}
+
+	line := []byte(`{"type":"assistant","message":{"content":[{"type":"text","text":"Hi"}]}}`)
+	got, err := plain.RenderEvent(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "\x1b[") || strings.Contains(got, `"type"`) {
+		t.Errorf("NoColor renderer = %q, want plain text without the raw event", got)
+	}
+	got, err = verbose.RenderEvent(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"type"`) {
+		t.Errorf("-vvv renderer = %q, want the raw event", got)
+	}
+}
+
+func TestRenderSession_Sinks(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "bash_ls.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tr := NewTranscript()
+	if err := RenderSession(f, &bytes.Buffer{}, Options{NoColor: true, Sinks: []Sink{tr}}); err != nil {
+		t.Fatalf("RenderSession() error = %v", err)
+	}
+	if len(tr.ByTool("Bash")) == 0 {
+		t.Errorf("expected the Bash result in the transcript, got %d blocks", tr.Len())
+	}
+}
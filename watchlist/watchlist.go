@@ -0,0 +1,264 @@
+// Package watchlist flags tool calls that read or write sensitive paths,
+// such as .env files and SSH keys, for -watch. It is a safety net for agents
+// run with broad permissions: each call that touches a watched path gets a
+// prominent alert, the summary counts them, and -watch-fail exits non-zero.
+package watchlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultPatterns are watched with -watch: secrets files and the
+// credentials of SSH, GPG and the common cloud and package tools.
+var DefaultPatterns = []string{
+	".env",
+	".env.*",
+	"*.pem",
+	"*.key",
+	"id_rsa*",
+	"id_ecdsa*",
+	"id_ed25519*",
+	".netrc",
+	".npmrc",
+	".pypirc",
+	"~/.ssh/**",
+	"~/.gnupg/**",
+	"~/.aws/**",
+	"~/.config/gcloud/**",
+	"~/.kube/config",
+	"~/.docker/config.json",
+}
+
+// Access is how a tool call touches a path.
+type Access string
+
+const (
+	Read  Access = "read"
+	Write Access = "write"
+	// Shell is a path named in a shell command, which may read or write it.
+	Shell Access = "shell"
+)
+
+// writeTools are the tools that change the file they are given.
+var writeTools = map[string]bool{"Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true}
+
+// Hit is a watched path a tool call touched.
+type Hit struct {
+	Path string `json:"path"`
+	// Pattern is the watchlist pattern the path matched.
+	Pattern string `json:"pattern"`
+	Access  Access `json:"access"`
+}
+
+// String describes the hit, e.g. "write .env (matches .env)".
+func (h Hit) String() string {
+	return fmt.Sprintf("%s %s (matches %s)", h.Access, h.Path, h.Pattern)
+}
+
+// Watchlist matches paths against glob patterns. A pattern without a slash
+// matches a file or directory name anywhere, as in .gitignore; one with a
+// slash matches the end of a path, or the whole path when it starts with /
+// or ~/ (the home directory). ** matches any number of directories.
+type Watchlist struct {
+	patterns []string
+	home     string
+}
+
+// New creates a Watchlist of patterns, checking their syntax.
+func New(patterns []string) (*Watchlist, error) {
+	home, _ := os.UserHomeDir()
+	for _, p := range patterns {
+		if err := Validate(p); err != nil {
+			return nil, err
+		}
+	}
+	return &Watchlist{patterns: patterns, home: filepath.ToSlash(home)}, nil
+}
+
+// Validate checks the syntax of a watchlist pattern.
+func Validate(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty watch pattern")
+	}
+	for _, seg := range strings.Split(pattern, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid watch pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the first pattern p matches. A nil Watchlist matches
+// nothing.
+func (w *Watchlist) Match(p string) (string, bool) {
+	if w == nil || p == "" {
+		return "", false
+	}
+	p = path.Clean(filepath.ToSlash(p))
+	if w.home != "" && (p == "~" || strings.HasPrefix(p, "~/")) {
+		p = w.home + p[1:]
+	}
+	segs := splitPath(p)
+	for _, pattern := range w.patterns {
+		if w.matches(pattern, segs) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+func (w *Watchlist) matches(pattern string, segs []string) bool {
+	switch {
+	case strings.HasPrefix(pattern, "~/"):
+		if w.home == "" {
+			return false
+		}
+		return matchSegments(splitPath(w.home+pattern[1:]), segs)
+	case strings.HasPrefix(pattern, "/"):
+		return matchSegments(splitPath(pattern), segs)
+	case !strings.Contains(pattern, "/"):
+		// A name: any segment, so a directory covers what is under it.
+		for _, seg := range segs {
+			if ok, _ := path.Match(pattern, seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+	patSegs := splitPath(pattern)
+	for i := range segs {
+		if matchSegments(patSegs, segs[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, with **
+// standing for any number of segments.
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
+
+func splitPath(p string) []string {
+	var segs []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg != "" && seg != "." {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// Check returns the watched paths the call of tool name with input
+// touches: the file a file tool is given (file_path or notebook_path), the
+// path searched, and the words of a Bash command that match. A nil Watchlist finds none.
+func (w *Watchlist) Check(name string, input json.RawMessage) []Hit {
+	if w == nil || len(input) == 0 {
+		return nil
+	}
+	var fields map[string]any
+	if json.Unmarshal(input, &fields) != nil {
+		return nil
+	}
+	var hits []Hit
+	seen := make(map[string]bool)
+	add := func(p string, access Access) {
+		if seen[p] {
+			return
+		}
+		if pattern, ok := w.Match(p); ok {
+			seen[p] = true
+			hits = append(hits, Hit{Path: p, Pattern: pattern, Access: access})
+		}
+	}
+	access := Read
+	if writeTools[name] {
+		access = Write
+	}
+	for _, key := range []string{"file_path", "notebook_path", "path"} {
+		if p, ok := fields[key].(string); ok {
+			add(p, access)
+		}
+	}
+	if command, ok := fields["command"].(string); ok && name == "Bash" {
+		for _, word := range commandWords(command) {
+			add(word, Shell)
+		}
+	}
+	return hits
+}
+
+// commandWords splits a shell command into the words that could be paths,
+// without quotes and redirections.
+func commandWords(command string) []string {
+	words := strings.FieldsFunc(command, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == ';' || r == '|' || r == '&' || r == '(' || r == ')'
+	})
+	out := words[:0]
+	for _, word := range words {
+		if i := strings.IndexAny(word, "<>"); i >= 0 && strings.Trim(word[:i], "0123456789") == "" {
+			word = strings.TrimLeft(word[i:], "<>")
+		}
+		word = strings.Trim(word, `"'`+"`")
+		if word != "" && !strings.HasPrefix(word, "-") && !strings.Contains(word, "=") {
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+// Error reports that tool calls touched watched paths, for -watch-fail.
+type Error struct {
+	Hits int
+}
+
+func (e *Error) Error() string {
+	if e.Hits == 1 {
+		return "a tool call touched a watched path"
+	}
+	return fmt.Sprintf("%d tool calls touched watched paths", e.Hits)
+}
+
+var (
+	mu               sync.RWMutex
+	defaultWatchlist *Watchlist
+)
+
+// Default returns the process-wide Watchlist, nil unless -watch or
+// -watch-path configured one.
+func Default() *Watchlist {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultWatchlist
+}
+
+// SetDefault replaces the process-wide Watchlist; nil turns watching off.
+func SetDefault(w *Watchlist) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultWatchlist = w
+}
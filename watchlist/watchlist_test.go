@@ -0,0 +1,92 @@
+package watchlist
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWatchlist_Match(t *testing.T) {
+	w, err := New([]string{".env", "*.pem", "~/.ssh/**", "config/secrets/**", "/etc/shadow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.home = "/home/dev"
+	tests := []struct {
+		path, want string
+	}{
+		{".env", ".env"},
+		{"./app/.env", ".env"},
+		{"/srv/tls/server.pem", "*.pem"},
+		{"/home/dev/.ssh/id_ed25519", "~/.ssh/**"},
+		{"~/.ssh/config", "~/.ssh/**"},
+		{"/repo/config/secrets/db.yaml", "config/secrets/**"},
+		{"/etc/shadow", "/etc/shadow"},
+		{"/srv/etc/shadow", ""},
+		{"/app/.envrc", ""},
+		{"/other/.ssh/id_rsa", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, _ := w.Match(tt.path)
+		if got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWatchlist_Check(t *testing.T) {
+	w, err := New(DefaultPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.home = "/home/dev"
+	tests := []struct {
+		name, tool, input string
+		want              []Hit
+	}{
+		{"write", "Edit", `{"file_path":"/app/.env.local","old_string":"a","new_string":"b"}`,
+			[]Hit{{Path: "/app/.env.local", Pattern: ".env.*", Access: Write}}},
+		{"read", "Read", `{"file_path":"/home/dev/.aws/credentials"}`,
+			[]Hit{{Path: "/home/dev/.aws/credentials", Pattern: "~/.aws/**", Access: Read}}},
+		{"search", "Grep", `{"pattern":"token","path":"/home/dev/.ssh"}`,
+			[]Hit{{Path: "/home/dev/.ssh", Pattern: "~/.ssh/**", Access: Read}}},
+		{"shell", "Bash", `{"command":"cat ~/.ssh/id_rsa | base64 > \"out.txt\" 2>.netrc"}`,
+			[]Hit{{Path: "~/.ssh/id_rsa", Pattern: "id_rsa*", Access: Shell}, {Path: ".netrc", Pattern: ".netrc", Access: Shell}}},
+		{"unwatched", "Read", `{"file_path":"/app/main.go"}`, nil},
+		{"not an object", "Read", `"x"`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := w.Check(tt.tool, json.RawMessage(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var none *Watchlist
+	if got := none.Check("Read", json.RawMessage(`{"file_path":".env"}`)); got != nil {
+		t.Errorf("nil watchlist found %+v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("secrets/[a-"); err == nil {
+		t.Error("expected a malformed pattern to be rejected")
+	}
+	if err := Validate(" "); err == nil {
+		t.Error("expected an empty pattern to be rejected")
+	}
+	if err := Validate("~/.ssh/**"); err != nil {
+		t.Errorf("Validate(~/.ssh/**) = %v", err)
+	}
+}
+
+func TestError(t *testing.T) {
+	if got := (&Error{Hits: 1}).Error(); got != "a tool call touched a watched path" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := (&Error{Hits: 3}).Error(); got != "3 tool calls touched watched paths" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Package worker owns viewscreen's background goroutines: page title
+// lookups, notification commands and the metrics server. Each runs in a
+// Group that hands it a context cancelled when the Group closes, waits for
+// it before viewscreen exits, and reports its error on the diagnostics
+// logger instead of dropping it.
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+	"golang.org/x/sync/errgroup"
+)
+
+// Group runs named background tasks under one cancellable context.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	g      errgroup.Group
+	logger *diag.Logger
+
+	mu  sync.Mutex
+	err error // first task error
+}
+
+// New creates a Group whose tasks stop when parent is done or the Group is
+// closed. Task errors are reported on logger; a nil logger uses
+// diag.Default().
+func New(parent context.Context, logger *diag.Logger) *Group {
+	ctx, cancel := context.WithCancel(parent)
+	return &Group{ctx: ctx, cancel: cancel, logger: logger}
+}
+
+// Context returns the context the Group's tasks run under.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// Go runs fn in the background. An error it returns, other than one caused
+// by the Group being closed, is logged as a warning naming the task. A
+// task must return promptly once its context is done.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.g.Go(func() error {
+		err := fn(g.ctx)
+		if err == nil || (g.ctx.Err() != nil && errors.Is(err, context.Canceled)) {
+			return nil
+		}
+		g.log().Warnf("%s: %v", name, err)
+		g.mu.Lock()
+		if g.err == nil {
+			g.err = err
+		}
+		g.mu.Unlock()
+		return err
+	})
+}
+
+// Close cancels the Group's context, waits for its tasks to return and
+// returns the first error one of them reported.
+func (g *Group) Close() error {
+	g.cancel()
+	_ = g.g.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+func (g *Group) log() *diag.Logger {
+	if g.logger != nil {
+		return g.logger
+	}
+	return diag.Default()
+}
+
+var (
+	mu         sync.RWMutex
+	defaultGrp *Group
+)
+
+// Default returns the process-wide Group, creating one that is never
+// closed if none has been set.
+func Default() *Group {
+	mu.RLock()
+	g := defaultGrp
+	mu.RUnlock()
+	if g != nil {
+		return g
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if defaultGrp == nil {
+		defaultGrp = New(context.Background(), nil)
+	}
+	return defaultGrp
+}
+
+// SetDefault replaces the process-wide Group.
+func SetDefault(g *Group) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultGrp = g
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnnyfreeman/viewscreen/diag"
+)
+
+func TestGroup_ReportsErrors(t *testing.T) {
+	var log bytes.Buffer
+	g := New(context.Background(), diag.New(&log, diag.LevelWarn))
+
+	g.Go("ok", func(context.Context) error { return nil })
+	g.Go("lookup", func(context.Context) error { return errors.New("connection refused") })
+
+	if err := g.Close(); err == nil || err.Error() != "connection refused" {
+		t.Errorf("Close() = %v, want the task's error", err)
+	}
+	if got := log.String(); !strings.Contains(got, "warn: lookup: connection refused") {
+		t.Errorf("expected the error on the diagnostics logger, got %q", got)
+	}
+}
+
+func TestGroup_CloseCancelsTasks(t *testing.T) {
+	var log bytes.Buffer
+	g := New(context.Background(), diag.New(&log, diag.LevelDebug))
+
+	started := make(chan struct{})
+	g.Go("server", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	if err := g.Close(); err != nil {
+		t.Errorf("Close() = %v, want no error for a task stopped by Close", err)
+	}
+	if log.Len() != 0 {
+		t.Errorf("expected nothing logged for cancellation, got %q", log.String())
+	}
+	if g.Context().Err() == nil {
+		t.Error("expected the context to be cancelled")
+	}
+}
+
+func TestGroup_ParentCancellation(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	g := New(parent, diag.New(&bytes.Buffer{}, diag.LevelOff))
+	cancel()
+	<-g.Context().Done()
+	g.Close()
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(nil)
+
+	SetDefault(nil)
+	if Default() == nil || Default() != Default() {
+		t.Fatal("expected a lazily created, stable default Group")
+	}
+	g := New(context.Background(), nil)
+	SetDefault(g)
+	if Default() != g {
+		t.Error("expected SetDefault to replace the default Group")
+	}
+}